@@ -1,9 +1,13 @@
 package config
 
 import (
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 )
 
 // Config holds all configuration for the application
@@ -14,21 +18,140 @@ type Config struct {
 	Environment string
 
 	// OpenAI Configuration
-	OpenAIAPIKey string
+	OpenAIAPIKey          string
+	AIUsageTagKeys        []string // Request metadata keys that OpenAI usage is attributed to
+	AIPromptCostPer1K     float64  // Estimated USD cost per 1K prompt tokens
+	AICompletionCostPer1K float64  // Estimated USD cost per 1K completion tokens
+	AIModelWeights        []string // Weighted models sampled per request, as "model=weight"; empty uses the default model
+	AIAllowedModels       []string // Models callers may request explicitly; empty allows any
+	AIMaxTokens           int      // Largest completion token budget a caller may request; 0 means no limit
+	AICacheTTLSeconds     int      // How long identical code suggestion requests are answered from cache; 0 disables caching
+	AICacheMaxEntries     int      // Most responses kept in the AI response cache; 0 disables caching
+	AIPatchValidators     []string // Syntax checks for patched code, as "language=command"; "go=builtin" parses Go in-process
+	AIQuotaDailyRequests  int      // OpenAI requests each API key may make per UTC day; 0 means unlimited
+	AIQuotaDailyTokens    int      // OpenAI tokens each API key may spend per UTC day; 0 means unlimited
+	AIQuotaAPIKeys        []string // X-API-Key values callers may be identified by for AI quotas; others fall back to the client IP
+	AISecondaryAPIKey     string   // API key of the provider requests fail over to while the primary's circuit is open; empty disables failover
+	AISecondaryBaseURL    string   // OpenAI-compatible API URL of the secondary provider; empty uses OpenAI's
+	AICircuitMaxFailures  int      // Failed requests that open a provider's circuit breaker
+	AICircuitOpenSeconds  int      // How long an open provider circuit rejects requests before trying one again
+	AIAnalysisMaxLogs     int      // Most logs sent to the AI by a log analysis, sampled one per error fingerprint
+
+	// Automatic AI analysis of error spikes, enabled by EnableAIAutoAnalysis
+	AIAutoAnalysisThreshold     int // Occurrences of one error fingerprint within the window that trigger an analysis
+	AIAutoAnalysisWindowSeconds int // Spike window, and how long further triggers for the same fingerprint are dropped
+	AIAutoAnalysisConcurrency   int // Auto analyses run at once
+	AIAutoAnalysisQueueSize     int // Auto analyses waiting for a free slot; further triggers are dropped
 
 	// CORS Configuration
 	FrontendURL string
 
 	// WebSocket Configuration
-	WSEndpoint string
+	WSEndpoint            string
+	WSMaxConcurrentWrites int      // Client messages encoded at once across the hub; 0 means unlimited
+	WSRetainMessages      []string // Message types whose latest broadcast is replayed to new clients, as "type=key_field"
+	WSRetainMaxMessages   int      // Most messages retained for replay across all types; 0 disables replay
+	WSAuthTokens          []string // Tokens accepted on WebSocket upgrades, as "user_id=token"; not required in development
+	WSPingInterval        int      // Seconds between pings sent to each WebSocket client
+	WSPongTimeout         int      // Seconds a WebSocket client may go without answering before it is disconnected
+	WSSendBufferSize      int      // Messages each WebSocket client can have waiting to be written
+	WSMaxConsecutiveDrops int      // Messages in a row a client with a full buffer may miss before it is evicted
+
+	// Audit Configuration
+	AuditEventTypes   []string // Broadcast types recorded in the audit store; empty records nothing
+	AuditMaxEvents    int      // Audit events kept before the oldest are evicted
+	AuditStoreFile    string   // JSON Lines file audit events are persisted to; empty keeps them in memory only
+	AuditTestStatuses []string // test_progress statuses recorded for audit; empty records every status
+
+	// Authentication Configuration
+	JWTSecret          string   // HMAC secret verifying HS256/HS384/HS512 bearer tokens
+	JWTPublicKeyFile   string   // PEM file with the RSA public key verifying RS256/RS384/RS512 bearer tokens
+	JWTProtectedRoutes []string // Routes requiring a bearer token when ENABLE_JWT_AUTH is true, as "METHOD /path"
+	JWTLeeway          int      // Seconds of clock skew tolerated when checking token expiry
+
+	// Server Limits
+	MaxConcurrentRequests   int
+	RateLimitPerMinute      int      // Requests per minute each client IP may make when rate limiting is enabled
+	RateLimitBurst          int      // Requests a client IP may make at once before the per-minute rate applies
+	RateLimitRouteOverrides []string // Per path prefix limits, as "/path_prefix=requests_per_minute:burst"
+
+	// Request Tracing
+	CorrelationIDHeader string // Response header echoing the request's trace ID
+
+	// API Schemas
+	SchemaModels []string // Request models whose JSON Schema is served at /api/schema/:model; empty serves all
 
 	// Logging Configuration
-	LogLevel  string
-	LogFormat string
+	LogLevel                     string
+	LogFormat                    string
+	LogOutput                    string   // Where server logs are written: stdout, stderr or a file path
+	LogMaxSizeMB                 int      // Size at which the LogOutput file is rotated; 0 never rotates it
+	LogMaxFiles                  int      // Rotated log files kept; 0 keeps every file
+	LogMaxAgeDays                int      // Days rotated log files are kept; 0 keeps them regardless of age
+	LogSampleFirst               int      // With EnableLogSampling, entries logged per level and source each interval before sampling
+	LogSampleThereafter          int      // With EnableLogSampling, every Nth entry logged after LogSampleFirst; 0 drops the rest
+	LogSampleInterval            int      // With EnableLogSampling, milliseconds after which the sampling counts start over
+	LogIngestLevels              []string // Accepted levels for submitted logs, most severe first
+	LogIngestErrorLevel          string   // Least severe submitted level counted as an error
+	LogAnalysisMaxRange          int      // Widest time range a log analysis may cover, in hours; 0 disables the limit
+	LogStore                     string   // Where submitted logs are kept: memory or sqlite
+	LogStorePath                 string   // SQLite database file used when LogStore is sqlite
+	LogStoreMaxEntries           int      // Submitted logs kept before the oldest are evicted; 0 keeps everything
+	LogStoreRetries              int      // Attempts per log store write before the batch is buffered in memory
+	LogStoreRetryDelay           int      // Delay before the first retry of a failed log store write, in milliseconds; doubles per retry
+	LogStoreBufferSize           int      // Entries buffered in memory while log store writes keep failing
+	LogStoreCompressAt           int      // Bytes at which the memory log store compresses messages and stack traces; 0 disables
+	LogIPEnrichmentDB            string   // CSV file of networks used to add geo/ASN context to submitted logs
+	LogIPContextKeys             []string // Log context keys checked for a client IP
+	LogIPCacheSize               int      // Distinct IPs whose lookups are cached
+	LogAlertKeywords             []string // Message keywords that mark a submitted log as critical
+	LogFingerprintLevel          string   // Least severe submitted level assigned an error fingerprint; empty uses LogIngestErrorLevel
+	LogIngestionPaused           bool     // Start with log ingestion paused; POST /api/logs/resume reopens it
+	LogMaxClockSkew              int      // Seconds a submitted timestamp may differ from server time before it is clamped; 0 disables clamping
+	LogAlertEscalation           int      // Seconds a critical log alert may go unacknowledged before it is escalated; 0 disables escalation
+	LogAlertEscalationWebhookURL string   // URL unacknowledged critical alerts are POSTed to when escalated; empty only re-broadcasts them
+
+	// Sync Configuration
+	SyncHealthPaths             []string // Candidate health paths tried in order when connecting environments
+	SyncAssertionTimeout        int      // Default per-assertion timeout for sync validation, in seconds
+	SyncAssertionWorkers        int      // Assertions of one sync validation run at once
+	SyncValidationMaxConcurrent int      // Validations in flight per environment; 0 disables the cap
+	SyncValidationLimits        []string // Per-environment overrides as environment=limit
+	SyncValidationQueueTimeout  int      // Seconds a validation waits for a free slot; 0 rejects immediately
+	SyncCompareMaxDepth         int      // Deepest JSON nesting compared between response bodies
+	SyncCompareMaxFields        int      // JSON values compared per validation before stopping
+	SyncCompareMaxDiffs         int      // Body differences reported per validation before stopping
+	SyncHealthCheckInterval     int      // Seconds between background health checks of connected environments; 0 disables them
+	SyncHealthCheckCooldown     int      // Seconds after an environment's last check before it may be checked on demand again
+	SyncHealthCheckAttempts     int      // Attempts per URL health check before an environment is marked unhealthy
+	SyncHealthCheckRetryDelay   int      // Delay before the first health check retry, in milliseconds; doubles per retry
+	SyncMaxEnvironments         int      // Environments that may be connected at once; 0 means unlimited
+	SyncEnvironmentsFile        string   // JSON file where connected environments are persisted; empty keeps them in memory
 
 	// Testing Configuration
-	CypressBaseURL    string
-	PlaywrightBaseURL string
+	CypressBaseURL           string
+	PlaywrightBaseURL        string
+	TestCleanupPatterns      []string
+	TestWorkDirRoot          string   // Directory every test run workDir must be inside; empty never cleans up workDirs
+	TestConfigAllowedKeys    []string // Glob patterns of test run Config keys passed to the test process; empty allows any key
+	TestHistoryDir           string   // Directory where completed test runs are persisted; empty keeps history in memory
+	TestHistoryMaxRuns       int      // Runs kept in TestHistoryDir; the oldest are deleted beyond it. 0 means unlimited
+	TestSchedulesFile        string   // JSON file where test schedules are persisted; empty keeps them in memory
+	TestMaxSchedules         int      // Test schedules that may exist at once; 0 means unlimited
+	TestScheduleMinInterval  int      // Shortest gap allowed between a schedule's runs, in seconds; 0 allows any
+	TestArtifactsDir         string   // Directory screenshots, videos and traces of test runs are copied to; empty doesn't keep them
+	TestArtifactsMaxRuns     int      // Runs whose artifacts are kept; older runs' artifacts are deleted. 0 means unlimited
+	MaxConcurrentTestRuns    int      // Test runs executed at once; further runs wait in the queue
+	TestRunTimeoutMultiplier int      // Default run timeout as a multiple of the framework's estimated duration
+	TestSyncRunTimeout       int      // Longest POST /api/testing/run-sync waits for a run to finish, in seconds
+	TestRunReaperInterval    int      // Seconds between checks for finished runs left in the active runs; 0 disables them
+	TestWatchdogInterval     int      // Seconds without a new test run before a test_watchdog alert; 0 disables the watchdog
+	TestLogStreamRate        int      // Output lines per second streamed per run as test_log_line messages; 0 is unlimited
+	TestLogStreamBuffer      int      // Output lines per run waiting to be streamed before newer ones are dropped
+	TestCallbackSecret       string   // Key of the HMAC-SHA256 signature sent with test run callbacks; empty rejects runs with a callback_url
+	TestCallbackAllowedHosts []string // Glob patterns of the hosts test run callbacks may be sent to; empty allows none
+	TestCallbackAttempts     int      // Attempts to deliver a test run callback before giving up
+	TestCallbackRetryDelay   int      // Delay before the first callback retry, in milliseconds; doubles per retry
 
 	// Feature Toggles
 	EnableAIFeatures            bool
@@ -38,6 +161,16 @@ type Config struct {
 	EnableCircuitBreaker        bool
 	EnableDetailedErrors        bool
 	EnableDebugEndpoints        bool
+	EnableChaosEndpoints        bool // Serve POST /api/admin/chaos; refused in production
+	EnableWSCorrelationID       bool
+	EnableStrictValidation      bool
+	EnableTestCleanup           bool
+	EnableCorrelationIDHeader   bool
+	EnableLogIPEnrichment       bool
+	EnableJWTAuth               bool
+	EnableLogFingerprints       bool
+	EnableAIAutoAnalysis        bool
+	EnableLogSampling           bool
 }
 
 // Load loads configuration from environment variables with defaults
@@ -49,21 +182,155 @@ func Load() *Config {
 		Environment: getEnv("ENVIRONMENT", "development"),
 
 		// OpenAI Configuration
-		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
+		OpenAIAPIKey:          getEnv("OPENAI_API_KEY", ""),
+		AIUsageTagKeys:        getEnvAsSlice("AI_USAGE_TAG_KEYS", []string{"project"}),
+		AIPromptCostPer1K:     getEnvAsFloat("AI_PROMPT_COST_PER_1K", 0.0005),
+		AICompletionCostPer1K: getEnvAsFloat("AI_COMPLETION_COST_PER_1K", 0.0015),
+		AIModelWeights:        getEnvAsSlice("AI_MODEL_WEIGHTS", nil),
+		AIAllowedModels:       getEnvAsSlice("AI_ALLOWED_MODELS", []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o", "gpt-4o-mini"}),
+		AIMaxTokens:           getEnvAsInt("AI_MAX_TOKENS", 4000),
+		AICacheTTLSeconds:     getEnvAsInt("AI_CACHE_TTL_SECONDS", 600),
+		AICacheMaxEntries:     getEnvAsInt("AI_CACHE_MAX_ENTRIES", 500),
+		AIPatchValidators:     getEnvAsSlice("AI_PATCH_VALIDATORS", []string{"go=builtin"}),
+		AIQuotaDailyRequests:  getEnvAsInt("AI_QUOTA_DAILY_REQUESTS", 0),
+		AIQuotaDailyTokens:    getEnvAsInt("AI_QUOTA_DAILY_TOKENS", 0),
+		AIQuotaAPIKeys:        getEnvAsSlice("AI_QUOTA_API_KEYS", nil),
+		AISecondaryAPIKey:     getEnv("AI_SECONDARY_API_KEY", ""),
+		AISecondaryBaseURL:    getEnv("AI_SECONDARY_BASE_URL", ""),
+		AICircuitMaxFailures:  getEnvAsInt("AI_CIRCUIT_MAX_FAILURES", 3),
+		AICircuitOpenSeconds:  getEnvAsInt("AI_CIRCUIT_OPEN_SECONDS", 60),
+		AIAnalysisMaxLogs:     getEnvAsInt("AI_ANALYSIS_MAX_LOGS", 20),
+
+		AIAutoAnalysisThreshold:     getEnvAsInt("AI_AUTO_ANALYSIS_THRESHOLD", 20),
+		AIAutoAnalysisWindowSeconds: getEnvAsInt("AI_AUTO_ANALYSIS_WINDOW_SECONDS", 300),
+		AIAutoAnalysisConcurrency:   getEnvAsInt("AI_AUTO_ANALYSIS_CONCURRENCY", 2),
+		AIAutoAnalysisQueueSize:     getEnvAsInt("AI_AUTO_ANALYSIS_QUEUE_SIZE", 10),
 
 		// CORS Configuration
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 
 		// WebSocket Configuration
-		WSEndpoint: getEnv("WS_ENDPOINT", "/ws"),
+		WSEndpoint:            getEnv("WS_ENDPOINT", "/ws"),
+		WSMaxConcurrentWrites: getEnvAsInt("WS_MAX_CONCURRENT_WRITES", 64),
+		WSRetainMessages:      getEnvAsSlice("WS_RETAIN_MESSAGES", []string{"test_progress=run_id"}),
+		WSRetainMaxMessages:   getEnvAsInt("WS_RETAIN_MAX_MESSAGES", 100),
+		WSAuthTokens:          getEnvAsSlice("WS_AUTH_TOKENS", nil),
+		WSPingInterval:        getEnvAsInt("WS_PING_INTERVAL", 54),
+		WSPongTimeout:         getEnvAsInt("WS_PONG_TIMEOUT", 60),
+		WSSendBufferSize:      getEnvAsInt("WS_SEND_BUFFER_SIZE", 256),
+		WSMaxConsecutiveDrops: getEnvAsInt("WS_MAX_CONSECUTIVE_DROPS", 50),
+
+		// Audit Configuration
+		AuditEventTypes:   getEnvAsSlice("AUDIT_EVENT_TYPES", []string{"log_alert", "test_progress"}),
+		AuditMaxEvents:    getEnvAsInt("AUDIT_MAX_EVENTS", 1000),
+		AuditStoreFile:    getEnv("AUDIT_STORE_FILE", "data/audit.jsonl"),
+		AuditTestStatuses: getEnvAsLowerSlice("AUDIT_TEST_STATUSES", []string{"completed", "failed", "cancelled"}),
+
+		// Authentication Configuration
+		JWTSecret:        getEnv("JWT_SECRET", ""),
+		JWTPublicKeyFile: getEnv("JWT_PUBLIC_KEY_FILE", ""),
+		JWTProtectedRoutes: getEnvAsSlice("JWT_PROTECTED_ROUTES", []string{
+			"POST /api/*",
+			"PUT /api/*",
+			"PATCH /api/*",
+			"DELETE /api/*",
+		}),
+		JWTLeeway: getEnvAsInt("JWT_LEEWAY_SECONDS", 30),
+
+		// Server Limits
+		MaxConcurrentRequests:   getEnvAsInt("MAX_CONCURRENT_REQUESTS", 1000),
+		RateLimitPerMinute:      getEnvAsInt("RATE_LIMIT_PER_MINUTE", 6000),
+		RateLimitBurst:          getEnvAsInt("RATE_LIMIT_BURST", 20),
+		RateLimitRouteOverrides: getEnvAsSlice("RATE_LIMIT_ROUTE_OVERRIDES", []string{"/api/ai=60:10"}),
+
+		// Request Tracing
+		CorrelationIDHeader: getEnv("CORRELATION_ID_HEADER", "X-Correlation-ID"),
+
+		// API Schemas
+		SchemaModels: getEnvAsSlice("SCHEMA_MODELS", nil),
 
 		// Logging Configuration
 		LogLevel:  strings.ToLower(getEnv("LOG_LEVEL", "info")),
 		LogFormat: strings.ToLower(getEnv("LOG_FORMAT", "json")),
+		LogOutput: getEnv("LOG_OUTPUT", "stdout"),
+
+		LogMaxSizeMB:  getEnvAsInt("LOG_MAX_SIZE_MB", 100),
+		LogMaxFiles:   getEnvAsInt("LOG_MAX_FILES", 5),
+		LogMaxAgeDays: getEnvAsInt("LOG_MAX_AGE_DAYS", 30),
+
+		LogSampleFirst:      getEnvAsInt("LOG_SAMPLE_FIRST", 100),
+		LogSampleThereafter: getEnvAsInt("LOG_SAMPLE_THEREAFTER", 100),
+		LogSampleInterval:   getEnvAsInt("LOG_SAMPLE_INTERVAL_MS", 1000),
+		LogIngestLevels: getEnvAsLowerSlice("LOG_INGEST_LEVELS", []string{
+			"error", "warn", "info", "debug", "trace",
+		}),
+		LogIngestErrorLevel: strings.ToLower(getEnv("LOG_INGEST_ERROR_LEVEL", "error")),
+		LogAnalysisMaxRange: getEnvAsInt("LOG_ANALYSIS_MAX_RANGE_HOURS", 168),
+		LogStore:            getEnv("LOG_STORE", "memory"),
+		LogStorePath:        getEnv("LOG_STORE_PATH", "data/logs.db"),
+		LogStoreMaxEntries:  getEnvAsInt("LOG_STORE_MAX_ENTRIES", 10000),
+		LogStoreRetries:     getEnvAsInt("LOG_STORE_RETRY_ATTEMPTS", 3),
+		LogStoreRetryDelay:  getEnvAsInt("LOG_STORE_RETRY_DELAY_MS", 50),
+		LogStoreBufferSize:  getEnvAsInt("LOG_STORE_BUFFER_SIZE", 1000),
+		LogStoreCompressAt:  getEnvAsInt("LOG_STORE_COMPRESS_THRESHOLD", 0),
+		LogIPEnrichmentDB:   getEnv("LOG_IP_ENRICHMENT_DB", ""),
+		LogIPContextKeys:    getEnvAsSlice("LOG_IP_CONTEXT_KEYS", []string{"client_ip", "ip", "ip_address", "remote_addr"}),
+		LogIPCacheSize:      getEnvAsInt("LOG_IP_CACHE_SIZE", 10000),
+		LogAlertKeywords: getEnvAsSlice("LOG_ALERT_KEYWORDS", []string{
+			"panic", "fatal", "crash", "security", "breach", "unauthorized",
+			"database connection", "out of memory", "disk full",
+		}),
+		LogFingerprintLevel:          strings.ToLower(getEnv("LOG_FINGERPRINT_LEVEL", "")),
+		LogIngestionPaused:           getEnvAsBool("LOG_INGESTION_PAUSED", false),
+		LogMaxClockSkew:              getEnvAsInt("LOG_MAX_CLOCK_SKEW_SECONDS", 86400),
+		LogAlertEscalation:           getEnvAsInt("LOG_ALERT_ESCALATION_SECONDS", 0),
+		LogAlertEscalationWebhookURL: getEnv("LOG_ALERT_ESCALATION_WEBHOOK_URL", ""),
+
+		// Sync Configuration
+		SyncHealthPaths: getEnvAsSlice("SYNC_HEALTH_PATHS", []string{
+			"/health", "/healthz", "/api/health", "/",
+		}),
+		SyncAssertionTimeout:        getEnvAsInt("SYNC_ASSERTION_TIMEOUT", 10),
+		SyncAssertionWorkers:        getEnvAsInt("SYNC_ASSERTION_WORKERS", 4),
+		SyncValidationMaxConcurrent: getEnvAsInt("SYNC_VALIDATION_MAX_CONCURRENT", 5),
+		SyncValidationLimits:        getEnvAsSlice("SYNC_VALIDATION_LIMITS", nil),
+		SyncValidationQueueTimeout:  getEnvAsInt("SYNC_VALIDATION_QUEUE_TIMEOUT", 10),
+		SyncCompareMaxDepth:         getEnvAsInt("SYNC_COMPARE_MAX_DEPTH", 32),
+		SyncCompareMaxFields:        getEnvAsInt("SYNC_COMPARE_MAX_FIELDS", 10000),
+		SyncCompareMaxDiffs:         getEnvAsInt("SYNC_COMPARE_MAX_DIFFS", 100),
+		SyncHealthCheckInterval:     getEnvAsInt("SYNC_HEALTH_CHECK_INTERVAL", 30),
+		SyncHealthCheckCooldown:     getEnvAsInt("SYNC_HEALTH_CHECK_COOLDOWN", 5),
+		SyncHealthCheckAttempts:     getEnvAsInt("SYNC_HEALTH_CHECK_ATTEMPTS", 3),
+		SyncHealthCheckRetryDelay:   getEnvAsInt("SYNC_HEALTH_CHECK_RETRY_DELAY_MS", 200),
+		SyncMaxEnvironments:         getEnvAsInt("SYNC_MAX_ENVIRONMENTS", 50),
+		SyncEnvironmentsFile:        getEnv("SYNC_ENVIRONMENTS_FILE", ""),
 
 		// Testing Configuration
 		CypressBaseURL:    getEnv("CYPRESS_BASE_URL", "http://localhost:3000"),
 		PlaywrightBaseURL: getEnv("PLAYWRIGHT_BASE_URL", "http://localhost:3000"),
+		TestCleanupPatterns: getEnvAsSlice("TEST_CLEANUP_PATTERNS", []string{
+			"cypress/videos", "cypress/screenshots", "test-results", "playwright-report", "node_modules/.cache", "*.tmp",
+		}),
+		TestConfigAllowedKeys:    getEnvAsSlice("TEST_CONFIG_ALLOWED_KEYS", nil),
+		TestWorkDirRoot:          getEnv("TEST_WORKDIR_ROOT", ""),
+		TestHistoryDir:           getEnv("TEST_HISTORY_DIR", ""),
+		TestHistoryMaxRuns:       getEnvAsInt("TEST_HISTORY_MAX_RUNS", 1000),
+		TestSchedulesFile:        getEnv("TEST_SCHEDULES_FILE", ""),
+		TestMaxSchedules:         getEnvAsInt("TEST_MAX_SCHEDULES", 100),
+		TestScheduleMinInterval:  getEnvAsInt("TEST_SCHEDULE_MIN_INTERVAL", 300),
+		TestArtifactsDir:         getEnv("TEST_ARTIFACTS_DIR", ""),
+		TestArtifactsMaxRuns:     getEnvAsInt("TEST_ARTIFACTS_MAX_RUNS", 100),
+		MaxConcurrentTestRuns:    getEnvAsInt("MAX_CONCURRENT_TEST_RUNS", 3),
+		TestRunTimeoutMultiplier: getEnvAsInt("TEST_RUN_TIMEOUT_MULTIPLIER", 3),
+		TestSyncRunTimeout:       getEnvAsInt("TEST_SYNC_RUN_TIMEOUT", 300),
+		TestRunReaperInterval:    getEnvAsInt("TEST_RUN_REAPER_INTERVAL", 60),
+		TestWatchdogInterval:     getEnvAsInt("TEST_WATCHDOG_INTERVAL", 0),
+		TestLogStreamRate:        getEnvAsInt("TEST_LOG_STREAM_RATE", 50),
+		TestLogStreamBuffer:      getEnvAsInt("TEST_LOG_STREAM_BUFFER", 100),
+		TestCallbackSecret:       getEnv("TEST_CALLBACK_SECRET", ""),
+		TestCallbackAllowedHosts: getEnvAsSlice("TEST_CALLBACK_ALLOWED_HOSTS", nil),
+		TestCallbackAttempts:     getEnvAsInt("TEST_CALLBACK_ATTEMPTS", 3),
+		TestCallbackRetryDelay:   getEnvAsInt("TEST_CALLBACK_RETRY_DELAY_MS", 1000),
 
 		// Feature Toggles (default to enabled)
 		EnableAIFeatures:            getEnvAsBool("ENABLE_AI_FEATURES", true),
@@ -73,6 +340,16 @@ func Load() *Config {
 		EnableCircuitBreaker:        getEnvAsBool("ENABLE_CIRCUIT_BREAKER", true),
 		EnableDetailedErrors:        getEnvAsBool("ENABLE_DETAILED_ERRORS", false),
 		EnableDebugEndpoints:        getEnvAsBool("ENABLE_DEBUG_ENDPOINTS", false),
+		EnableChaosEndpoints:        getEnvAsBool("ENABLE_CHAOS_ENDPOINTS", false),
+		EnableWSCorrelationID:       getEnvAsBool("ENABLE_WS_CORRELATION_ID", true),
+		EnableStrictValidation:      getEnvAsBool("ENABLE_STRICT_VALIDATION", true),
+		EnableTestCleanup:           getEnvAsBool("ENABLE_TEST_CLEANUP", false),
+		EnableCorrelationIDHeader:   getEnvAsBool("ENABLE_CORRELATION_ID_HEADER", true),
+		EnableLogIPEnrichment:       getEnvAsBool("ENABLE_LOG_IP_ENRICHMENT", false),
+		EnableJWTAuth:               getEnvAsBool("ENABLE_JWT_AUTH", false),
+		EnableLogFingerprints:       getEnvAsBool("ENABLE_LOG_FINGERPRINTS", true),
+		EnableAIAutoAnalysis:        getEnvAsBool("ENABLE_AI_AUTO_ANALYSIS", false),
+		EnableLogSampling:           getEnvAsBool("ENABLE_LOG_SAMPLING", false),
 	}
 }
 
@@ -94,6 +371,16 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat gets an environment variable as float with a fallback default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsBool gets an environment variable as boolean with a fallback default value
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -104,6 +391,31 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvAsSlice gets a comma-separated environment variable as a string slice with a fallback default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		parts := strings.Split(value, ",")
+		result := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				result = append(result, trimmed)
+			}
+		}
+		return result
+	}
+	return defaultValue
+}
+
+// getEnvAsLowerSlice gets a comma-separated environment variable as a lower-cased slice
+func getEnvAsLowerSlice(key string, defaultValue []string) []string {
+	values := getEnvAsSlice(key, defaultValue)
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
 // IsDevelopment returns true if the environment is development
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -114,6 +426,20 @@ func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
 
+// FeatureFlags returns the feature toggles by name, as exported in configuration snapshots
+func (c *Config) FeatureFlags() map[string]bool {
+	return map[string]bool{
+		"ai_features":            c.EnableAIFeatures,
+		"websocket":              c.EnableWebSocket,
+		"performance_monitoring": c.EnablePerformanceMonitoring,
+		"rate_limiting":          c.EnableRateLimiting,
+		"circuit_breaker":        c.EnableCircuitBreaker,
+		"detailed_errors":        c.EnableDetailedErrors,
+		"debug_endpoints":        c.EnableDebugEndpoints,
+		"chaos_endpoints":        c.EnableChaosEndpoints,
+	}
+}
+
 // GetServerAddress returns the full server address
 func (c *Config) GetServerAddress() string {
 	return c.Host + ":" + c.Port
@@ -138,10 +464,30 @@ func (c *Config) Validate() []string {
 		errors = append(errors, "LOG_LEVEL must be one of: debug, info, warn, error")
 	}
 
+	// Validate the ingest error level is one of the accepted levels
+	if len(c.LogIngestLevels) > 0 && !contains(c.LogIngestLevels, c.LogIngestErrorLevel) {
+		errors = append(errors, "LOG_INGEST_ERROR_LEVEL must be one of LOG_INGEST_LEVELS")
+	}
+	if c.LogFingerprintLevel != "" && len(c.LogIngestLevels) > 0 && !contains(c.LogIngestLevels, c.LogFingerprintLevel) {
+		errors = append(errors, "LOG_FINGERPRINT_LEVEL must be one of LOG_INGEST_LEVELS")
+	}
+
 	// Validate log format
-	validLogFormats := []string{"json", "text"}
+	validLogFormats := []string{"json", "text", "console"}
 	if !contains(validLogFormats, c.LogFormat) {
-		errors = append(errors, "LOG_FORMAT must be one of: json, text")
+		errors = append(errors, "LOG_FORMAT must be one of: json, text, console")
+	}
+
+	if c.LogMaxSizeMB < 0 || c.LogMaxFiles < 0 || c.LogMaxAgeDays < 0 {
+		errors = append(errors, "LOG_MAX_SIZE_MB, LOG_MAX_FILES and LOG_MAX_AGE_DAYS must not be negative")
+	}
+
+	if c.LogSampleFirst < 0 || c.LogSampleThereafter < 0 {
+		errors = append(errors, "LOG_SAMPLE_FIRST and LOG_SAMPLE_THEREAFTER must not be negative")
+	}
+
+	if c.LogSampleInterval <= 0 {
+		errors = append(errors, "LOG_SAMPLE_INTERVAL_MS must be positive")
 	}
 
 	// Validate environment
@@ -150,6 +496,290 @@ func (c *Config) Validate() []string {
 		errors = append(errors, "ENVIRONMENT must be one of: development, staging, production")
 	}
 
+	// Injecting failures must never be possible in production
+	if c.EnableChaosEndpoints && c.IsProduction() {
+		errors = append(errors, "ENABLE_CHAOS_ENDPOINTS must not be set in production")
+	}
+
+	// Validate sync health paths are absolute URL paths
+	for _, path := range c.SyncHealthPaths {
+		if !strings.HasPrefix(path, "/") {
+			errors = append(errors, "SYNC_HEALTH_PATHS entries must start with /")
+			break
+		}
+	}
+
+	if c.AIPromptCostPer1K < 0 || c.AICompletionCostPer1K < 0 {
+		errors = append(errors, "AI_PROMPT_COST_PER_1K and AI_COMPLETION_COST_PER_1K must not be negative")
+	}
+
+	if c.AIMaxTokens < 0 {
+		errors = append(errors, "AI_MAX_TOKENS must not be negative")
+	}
+
+	if c.AICacheTTLSeconds < 0 || c.AICacheMaxEntries < 0 {
+		errors = append(errors, "AI_CACHE_TTL_SECONDS and AI_CACHE_MAX_ENTRIES must not be negative")
+	}
+
+	if c.AIQuotaDailyRequests < 0 || c.AIQuotaDailyTokens < 0 {
+		errors = append(errors, "AI_QUOTA_DAILY_REQUESTS and AI_QUOTA_DAILY_TOKENS must not be negative")
+	}
+
+	if c.AICircuitMaxFailures <= 0 || c.AICircuitOpenSeconds <= 0 {
+		errors = append(errors, "AI_CIRCUIT_MAX_FAILURES and AI_CIRCUIT_OPEN_SECONDS must be positive")
+	}
+
+	if c.AIAnalysisMaxLogs <= 0 {
+		errors = append(errors, "AI_ANALYSIS_MAX_LOGS must be positive")
+	}
+
+	if c.AIAutoAnalysisThreshold <= 0 || c.AIAutoAnalysisWindowSeconds <= 0 || c.AIAutoAnalysisConcurrency <= 0 {
+		errors = append(errors, "AI_AUTO_ANALYSIS_THRESHOLD, AI_AUTO_ANALYSIS_WINDOW_SECONDS and AI_AUTO_ANALYSIS_CONCURRENCY must be positive")
+	}
+
+	if c.AIAutoAnalysisQueueSize < 0 {
+		errors = append(errors, "AI_AUTO_ANALYSIS_QUEUE_SIZE must not be negative")
+	}
+
+	if c.WSMaxConcurrentWrites < 0 {
+		errors = append(errors, "WS_MAX_CONCURRENT_WRITES must not be negative")
+	}
+
+	if c.WSRetainMaxMessages < 0 {
+		errors = append(errors, "WS_RETAIN_MAX_MESSAGES must not be negative")
+	}
+
+	if c.WSPingInterval <= 0 || c.WSPongTimeout <= c.WSPingInterval {
+		errors = append(errors, "WS_PING_INTERVAL must be positive and less than WS_PONG_TIMEOUT")
+	}
+
+	if c.WSSendBufferSize < 1 || c.WSMaxConsecutiveDrops < 1 {
+		errors = append(errors, "WS_SEND_BUFFER_SIZE and WS_MAX_CONSECUTIVE_DROPS must be at least 1")
+	}
+
+	if c.AuditMaxEvents < 1 {
+		errors = append(errors, "AUDIT_MAX_EVENTS must be at least 1")
+	}
+
+	if c.LogAnalysisMaxRange < 0 {
+		errors = append(errors, "LOG_ANALYSIS_MAX_RANGE_HOURS must not be negative")
+	}
+
+	if c.LogMaxClockSkew < 0 {
+		errors = append(errors, "LOG_MAX_CLOCK_SKEW_SECONDS must not be negative")
+	}
+
+	if c.LogAlertEscalation < 0 {
+		errors = append(errors, "LOG_ALERT_ESCALATION_SECONDS must not be negative")
+	}
+
+	if webhook := c.LogAlertEscalationWebhookURL; webhook != "" {
+		if parsed, err := url.Parse(webhook); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errors = append(errors, "LOG_ALERT_ESCALATION_WEBHOOK_URL must be an http or https URL")
+		}
+	}
+
+	validLogStores := []string{"memory", "sqlite"}
+	if !contains(validLogStores, c.LogStore) {
+		errors = append(errors, "LOG_STORE must be one of: memory, sqlite")
+	}
+
+	if c.LogStore == "sqlite" && c.LogStorePath == "" {
+		errors = append(errors, "LOG_STORE_PATH is required when LOG_STORE is sqlite")
+	}
+
+	if c.LogStoreMaxEntries < 0 {
+		errors = append(errors, "LOG_STORE_MAX_ENTRIES must not be negative")
+	}
+
+	if c.LogStoreRetries < 1 {
+		errors = append(errors, "LOG_STORE_RETRY_ATTEMPTS must be at least 1")
+	}
+
+	if c.LogStoreRetryDelay < 0 {
+		errors = append(errors, "LOG_STORE_RETRY_DELAY_MS must not be negative")
+	}
+
+	if c.LogStoreBufferSize < 1 {
+		errors = append(errors, "LOG_STORE_BUFFER_SIZE must be at least 1")
+	}
+
+	if c.LogStoreCompressAt < 0 {
+		errors = append(errors, "LOG_STORE_COMPRESS_THRESHOLD must not be negative")
+	}
+
+	if c.EnableLogIPEnrichment && c.LogIPEnrichmentDB == "" {
+		errors = append(errors, "LOG_IP_ENRICHMENT_DB is required when ENABLE_LOG_IP_ENRICHMENT is true")
+	}
+
+	if c.LogIPCacheSize < 0 {
+		errors = append(errors, "LOG_IP_CACHE_SIZE must not be negative")
+	}
+
+	if _, err := models.ParseAIModelWeights(c.AIModelWeights); err != nil {
+		errors = append(errors, "AI_MODEL_WEIGHTS: "+err.Error())
+	}
+
+	if _, err := models.ParseAIPatchValidators(c.AIPatchValidators); err != nil {
+		errors = append(errors, "AI_PATCH_VALIDATORS: "+err.Error())
+	}
+
+	if c.RateLimitPerMinute <= 0 || c.RateLimitBurst <= 0 {
+		errors = append(errors, "RATE_LIMIT_PER_MINUTE and RATE_LIMIT_BURST must be greater than 0")
+	}
+
+	if _, err := models.ParseRateLimitOverrides(c.RateLimitRouteOverrides); err != nil {
+		errors = append(errors, "RATE_LIMIT_ROUTE_OVERRIDES: "+err.Error())
+	}
+
+	if _, err := models.ParseWSRetainRules(c.WSRetainMessages); err != nil {
+		errors = append(errors, "WS_RETAIN_MESSAGES: "+err.Error())
+	}
+
+	// Error messages name the user, never the token
+	if _, err := models.ParseWSAuthTokens(c.WSAuthTokens); err != nil {
+		errors = append(errors, "WS_AUTH_TOKENS: "+err.Error())
+	}
+
+	if c.EnableJWTAuth && c.JWTSecret == "" && c.JWTPublicKeyFile == "" {
+		errors = append(errors, "JWT_SECRET or JWT_PUBLIC_KEY_FILE is required when ENABLE_JWT_AUTH is true")
+	}
+
+	requestModels := models.RequestModels()
+	for _, name := range c.SchemaModels {
+		if _, exists := requestModels[name]; !exists {
+			errors = append(errors, "SCHEMA_MODELS: unknown request model "+strconv.Quote(name))
+		}
+	}
+
+	if _, err := models.ParseProtectedRoutes(c.JWTProtectedRoutes); err != nil {
+		errors = append(errors, "JWT_PROTECTED_ROUTES: "+err.Error())
+	}
+
+	if c.JWTLeeway < 0 {
+		errors = append(errors, "JWT_LEEWAY_SECONDS must not be negative")
+	}
+
+	if c.SyncAssertionTimeout <= 0 {
+		errors = append(errors, "SYNC_ASSERTION_TIMEOUT must be greater than 0")
+	}
+
+	if c.SyncAssertionWorkers <= 0 {
+		errors = append(errors, "SYNC_ASSERTION_WORKERS must be greater than 0")
+	}
+
+	if c.SyncCompareMaxDepth <= 0 || c.SyncCompareMaxFields <= 0 || c.SyncCompareMaxDiffs <= 0 {
+		errors = append(errors, "SYNC_COMPARE_MAX_DEPTH, SYNC_COMPARE_MAX_FIELDS and SYNC_COMPARE_MAX_DIFFS must be greater than 0")
+	}
+
+	if c.SyncHealthCheckInterval < 0 {
+		errors = append(errors, "SYNC_HEALTH_CHECK_INTERVAL must not be negative")
+	}
+
+	if c.SyncHealthCheckCooldown < 0 {
+		errors = append(errors, "SYNC_HEALTH_CHECK_COOLDOWN must not be negative")
+	}
+
+	if c.SyncHealthCheckAttempts < 1 {
+		errors = append(errors, "SYNC_HEALTH_CHECK_ATTEMPTS must be at least 1")
+	}
+
+	if c.SyncHealthCheckRetryDelay < 0 {
+		errors = append(errors, "SYNC_HEALTH_CHECK_RETRY_DELAY_MS must not be negative")
+	}
+
+	if c.SyncMaxEnvironments < 0 {
+		errors = append(errors, "SYNC_MAX_ENVIRONMENTS must not be negative")
+	}
+
+	if c.SyncValidationMaxConcurrent < 0 {
+		errors = append(errors, "SYNC_VALIDATION_MAX_CONCURRENT must not be negative")
+	}
+
+	if _, err := models.ParseValidationLimits(c.SyncValidationLimits); err != nil {
+		errors = append(errors, "SYNC_VALIDATION_LIMITS: "+err.Error())
+	}
+
+	if c.SyncValidationQueueTimeout < 0 {
+		errors = append(errors, "SYNC_VALIDATION_QUEUE_TIMEOUT must not be negative")
+	}
+
+	if c.MaxConcurrentTestRuns <= 0 {
+		errors = append(errors, "MAX_CONCURRENT_TEST_RUNS must be greater than 0")
+	}
+
+	if c.TestRunTimeoutMultiplier <= 0 {
+		errors = append(errors, "TEST_RUN_TIMEOUT_MULTIPLIER must be greater than 0")
+	}
+
+	if c.TestSyncRunTimeout <= 0 {
+		errors = append(errors, "TEST_SYNC_RUN_TIMEOUT must be greater than 0")
+	}
+
+	if c.TestHistoryMaxRuns < 0 {
+		errors = append(errors, "TEST_HISTORY_MAX_RUNS must not be negative")
+	}
+
+	if c.TestMaxSchedules < 0 {
+		errors = append(errors, "TEST_MAX_SCHEDULES must not be negative")
+	}
+
+	if c.TestScheduleMinInterval < 0 {
+		errors = append(errors, "TEST_SCHEDULE_MIN_INTERVAL must not be negative")
+	}
+
+	if c.TestArtifactsMaxRuns < 0 {
+		errors = append(errors, "TEST_ARTIFACTS_MAX_RUNS must not be negative")
+	}
+
+	if c.TestRunReaperInterval < 0 {
+		errors = append(errors, "TEST_RUN_REAPER_INTERVAL must not be negative")
+	}
+
+	if c.TestWatchdogInterval < 0 {
+		errors = append(errors, "TEST_WATCHDOG_INTERVAL must not be negative")
+	}
+
+	if c.TestLogStreamRate < 0 {
+		errors = append(errors, "TEST_LOG_STREAM_RATE must not be negative")
+	}
+
+	if c.TestLogStreamBuffer < 1 {
+		errors = append(errors, "TEST_LOG_STREAM_BUFFER must be at least 1")
+	}
+
+	if c.TestCallbackAttempts < 1 {
+		errors = append(errors, "TEST_CALLBACK_ATTEMPTS must be at least 1")
+	}
+
+	if c.TestCallbackRetryDelay < 0 {
+		errors = append(errors, "TEST_CALLBACK_RETRY_DELAY_MS must not be negative")
+	}
+
+	// Validate test callback host patterns can be matched
+	for _, pattern := range c.TestCallbackAllowedHosts {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, "TEST_CALLBACK_ALLOWED_HOSTS must be valid glob patterns")
+			break
+		}
+	}
+
+	// Validate test cleanup patterns stay inside the work directory
+	for _, pattern := range c.TestCleanupPatterns {
+		if filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+			errors = append(errors, "TEST_CLEANUP_PATTERNS must be relative paths inside the test work directory")
+			break
+		}
+	}
+
+	// Validate test config key patterns can be matched
+	for _, pattern := range c.TestConfigAllowedKeys {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, "TEST_CONFIG_ALLOWED_KEYS must be valid glob patterns")
+			break
+		}
+	}
+
 	return errors
 }
 
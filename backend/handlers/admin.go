@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles server administration endpoints
+type AdminHandler struct {
+	snapshots *services.ConfigSnapshotService
+	logger    *utils.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(snapshots *services.ConfigSnapshotService) *AdminHandler {
+	return &AdminHandler{
+		snapshots: snapshots,
+		logger:    utils.GetLogger(),
+	}
+}
+
+// ExportConfig handles GET /api/admin/export-config - returns the runtime-mutable configuration
+// as a snapshot that POST /api/admin/import-config accepts (admin only)
+func (h *AdminHandler) ExportConfig(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "Configuration exported", h.snapshots.Export())
+}
+
+// ImportConfig handles POST /api/admin/import-config - applies a configuration snapshot exported
+// by this or another server (admin only)
+func (h *AdminHandler) ImportConfig(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var snapshot models.ServiceConfigSnapshot
+	if err := c.BodyParser(&snapshot); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to parse config snapshot", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+	}
+
+	if err := utils.ValidateStruct(&snapshot); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+
+	result, err := h.snapshots.Import(&snapshot)
+	switch {
+	case errors.Is(err, services.ErrUnsupportedConfigSnapshot):
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "UNSUPPORTED_SNAPSHOT_VERSION", "Unsupported config snapshot version", map[string]string{
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidConfigSnapshot):
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	case err != nil:
+		h.logger.WithTraceID(traceID).Error("Failed to import config snapshot", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to import configuration")
+	}
+
+	return utils.SuccessResponse(c, "Configuration imported", result)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_ExportImportConfig(t *testing.T) {
+	logService := services.NewLogService(nil, nil)
+	syncService := services.NewSyncService(nil)
+	snapshots := services.NewConfigSnapshotService(logService, syncService, services.NewTestService(&config.Config{}, nil), map[string]bool{"ai_features": true})
+
+	app := fiber.New()
+	handler := NewAdminHandler(snapshots)
+	app.Get("/api/admin/export-config", handler.ExportConfig)
+	app.Post("/api/admin/import-config", handler.ImportConfig)
+
+	importConfig := func(body string) (*http.Response, utils.StandardResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/import-config", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	t.Run("export", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/admin/export-config", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response struct {
+			Data models.ServiceConfigSnapshot `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, models.ConfigSnapshotVersion, response.Data.Version)
+		assert.Equal(t, logService.GetAlertRules(), response.Data.LogAlertRules)
+		assert.NotNil(t, response.Data.SyncContracts)
+		assert.Equal(t, map[string]bool{"ai_features": true}, response.Data.Features)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		resp, response := importConfig(`{
+			"version": 1,
+			"features": {"ai_features": false},
+			"log_alert_rules": [{"keyword": "checkout failed", "min_level": "warn"}],
+			"sync_contracts": [{"name": "user", "method": "GET", "fields": {"id": "number"}}]
+		}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, []interface{}{"log_alert_rules", "sync_contracts"}, data["applied"])
+		assert.Len(t, data["warnings"], 1)
+
+		assert.Equal(t, []models.LogAlertRule{{Keyword: "checkout failed", MinLevel: "warn"}}, logService.GetAlertRules())
+		_, err := syncService.GetContract("user")
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		resp, response := importConfig(`{"version":`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "INVALID_REQUEST", response.Error.Code)
+	})
+
+	t.Run("invalid sections", func(t *testing.T) {
+		resp, response := importConfig(`{"version": 1, "log_format": "xml"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+
+		resp, response = importConfig(`{"version": 1, "sync_contracts": [{"name": "user", "method": "GET", "fields": {}}]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+		assert.Contains(t, response.Error.Details["details"], "sync_contracts[0]")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		resp, response := importConfig(`{"version": 99}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "UNSUPPORTED_SNAPSHOT_VERSION", response.Error.Code)
+	})
+}
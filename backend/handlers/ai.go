@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/middleware"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
@@ -11,6 +16,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// aiQuotaKeyHeader carries the API key AI quotas are tracked per
+const aiQuotaKeyHeader = "X-API-Key"
+
 // AIHandler handles AI assistance API endpoints
 type AIHandler struct {
 	aiService *services.AIService
@@ -45,11 +53,31 @@ func (h *AIHandler) GetCodeSuggestions(c *fiber.Ctx) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(h.requestContext(c), 30*time.Second)
 	defer cancel()
 
 	// Get AI suggestions
 	response, err := h.aiService.GetCodeSuggestions(ctx, &req)
+	switch {
+	case errors.Is(err, services.ErrAIModelNotAllowed):
+		return h.modelNotAllowedResponse(c, req.Model)
+	case errors.Is(err, services.ErrAIMaxTokensExceeded):
+		return utils.ValidationErrorResponse(c, map[string]string{"max_tokens": err.Error()})
+	case errors.Is(err, services.ErrInvalidAIRetryHints):
+		return utils.ValidationErrorResponse(c, map[string]string{"retry": err.Error()})
+	case errors.Is(err, services.ErrAIQuotaExceeded):
+		return h.quotaExceededResponse(c, err)
+	case errors.Is(err, services.ErrAIRequestInFlight):
+		return utils.ErrorResponse(c, fiber.StatusConflict, "AI_REQUEST_IN_FLIGHT",
+			"A request with this ID is already in flight", map[string]string{
+				"request_id": req.RequestID,
+			})
+	case errors.Is(err, services.ErrAIRequestCancelled):
+		return utils.ErrorResponse(c, fiber.StatusConflict, "AI_REQUEST_CANCELLED",
+			"The request was cancelled before it completed", map[string]string{
+				"request_id": req.RequestID,
+			})
+	}
 	if err != nil {
 		// Check if it's a rate limit error
 		if err.Error() == "rate limit exceeded" {
@@ -102,11 +130,21 @@ func (h *AIHandler) AnalyzeLogs(c *fiber.Ctx) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	ctx, cancel := context.WithTimeout(h.requestContext(c), 45*time.Second)
 	defer cancel()
 
 	// Analyze logs
 	response, err := h.aiService.AnalyzeLogs(ctx, &req)
+	switch {
+	case errors.Is(err, services.ErrAIModelNotAllowed):
+		return h.modelNotAllowedResponse(c, req.Model)
+	case errors.Is(err, services.ErrAIMaxTokensExceeded):
+		return utils.ValidationErrorResponse(c, map[string]string{"max_tokens": err.Error()})
+	case errors.Is(err, services.ErrInvalidAIRetryHints):
+		return utils.ValidationErrorResponse(c, map[string]string{"retry": err.Error()})
+	case errors.Is(err, services.ErrAIQuotaExceeded):
+		return h.quotaExceededResponse(c, err)
+	}
 	if err != nil {
 		// Check if it's a rate limit error
 		if err.Error() == "rate limit exceeded" {
@@ -126,6 +164,54 @@ func (h *AIHandler) AnalyzeLogs(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Log analysis completed successfully", response)
 }
 
+// requestContext carries the trace ID and the caller's quota key to the AI service
+func (h *AIHandler) requestContext(c *fiber.Ctx) context.Context {
+	ctx := utils.ContextWithTraceID(context.Background(), utils.GetTraceID(c))
+	key, _ := h.aiQuotaKey(c)
+	return services.ContextWithAIQuotaKey(ctx, key)
+}
+
+// aiQuotaKey identifies the caller for AI quotas by the subject of its verified bearer token, then
+// by an X-API-Key header listed in AI_QUOTA_API_KEYS, falling back to the client IP like the rate
+// limiter does. Unlisted API keys are ignored, so a caller can't get a fresh quota by changing the
+// header. display is safe to return to the caller.
+func (h *AIHandler) aiQuotaKey(c *fiber.Ctx) (key, display string) {
+	if claims, ok := middleware.GetJWTClaims(c); ok && claims.Subject() != "" {
+		return "sub:" + claims.Subject(), claims.Subject()
+	}
+	if apiKey := c.Get(aiQuotaKeyHeader); apiKey != "" && h.aiService.IsQuotaAPIKey(apiKey) {
+		return "key:" + apiKey, maskSensitiveValue(apiKey)
+	}
+	return "ip:" + c.IP(), c.IP()
+}
+
+// quotaExceededResponse rejects a request from a caller over its daily AI quota
+func (h *AIHandler) quotaExceededResponse(c *fiber.Ctx, err error) error {
+	key, display := h.aiQuotaKey(c)
+	usage := h.aiService.QuotaUsage(key)
+
+	retryAfter := int(math.Ceil(time.Until(usage.ResetsAt).Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+	return utils.ErrorResponse(c, fiber.StatusTooManyRequests, "QUOTA_EXCEEDED",
+		"Daily AI quota exceeded", map[string]string{
+			"key":           display,
+			"reason":        strings.TrimPrefix(err.Error(), services.ErrAIQuotaExceeded.Error()+": "),
+			"requests_used": strconv.Itoa(usage.RequestsUsed),
+			"request_limit": strconv.Itoa(usage.RequestLimit),
+			"tokens_used":   strconv.Itoa(usage.TokensUsed),
+			"token_limit":   strconv.Itoa(usage.TokenLimit),
+			"resets_at":     usage.ResetsAt.Format(time.RFC3339),
+		})
+}
+
+// modelNotAllowedResponse rejects a request for a model outside AI_ALLOWED_MODELS
+func (h *AIHandler) modelNotAllowedResponse(c *fiber.Ctx, model string) error {
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, "AI_MODEL_NOT_ALLOWED",
+		"The requested model is not allowed", map[string]string{
+			"allowed_models": strings.Join(h.aiService.AllowedModels(), ","),
+		})
+}
+
 // GetAIStatus handles GET /api/ai/status
 func (h *AIHandler) GetAIStatus(c *fiber.Ctx) error {
 	status := h.aiService.GetStatus()
@@ -139,7 +225,12 @@ func (h *AIHandler) GetAIStatus(c *fiber.Ctx) error {
 			"POST /api/ai/suggestions - Get code suggestions",
 			"POST /api/ai/analyze-logs - Analyze logs",
 			"GET /api/ai/status - Get AI service status",
+			"GET /api/ai/usage - Get token usage and estimated cost",
+			"GET /api/ai/quota - Get daily quota usage for the caller's API key",
+			"POST /api/ai/reset-usage - Reset token usage counters",
+			"POST /api/ai/feedback - Rate a suggestion as helpful or unhelpful",
 		},
+		"feedback": h.aiService.GetFeedbackSummary(),
 		"supported_languages": []string{
 			"javascript", "typescript", "python", "go",
 			"java", "rust", "php", "swift", "kotlin", "dart",
@@ -160,6 +251,100 @@ func (h *AIHandler) GetAIStatus(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, message, statusInfo)
 }
 
+// GetUsage handles GET /api/ai/usage
+func (h *AIHandler) GetUsage(c *fiber.Ctx) error {
+	groupBy := c.Query("group_by")
+
+	report, err := h.aiService.GetUsage(groupBy)
+	if err != nil {
+		return utils.ValidationErrorResponse(c, map[string]string{
+			"group_by": "must be one of: " + strings.Join(h.aiService.UsageTagKeys(), ", "),
+		})
+	}
+
+	return utils.SuccessResponse(c, "AI usage retrieved successfully", report)
+}
+
+// GetQuota handles GET /api/ai/quota, returning the caller's usage against its daily AI quota
+func (h *AIHandler) GetQuota(c *fiber.Ctx) error {
+	key, display := h.aiQuotaKey(c)
+	usage := h.aiService.QuotaUsage(key)
+	usage.Key = display
+
+	return utils.SuccessResponse(c, "AI quota retrieved successfully", usage)
+}
+
+// ResetUsage handles POST /api/ai/reset-usage - zeroes the token usage counters (admin only)
+func (h *AIHandler) ResetUsage(c *fiber.Ctx) error {
+	// In a production system, you would check for admin permissions here
+	previous := h.aiService.ResetUsage()
+
+	return utils.SuccessResponse(c, "AI usage reset successfully", map[string]interface{}{
+		"previous": previous,
+		"reset_at": time.Now(),
+	})
+}
+
+// SubmitFeedback handles POST /api/ai/feedback
+func (h *AIHandler) SubmitFeedback(c *fiber.Ctx) error {
+	var req models.AIFeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		validationErrors := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors[err.Field()] = getValidationErrorMessage(err)
+		}
+		return utils.ValidationErrorResponse(c, validationErrors)
+	}
+
+	feedback, err := h.aiService.RecordFeedback(&req)
+	switch {
+	case errors.Is(err, services.ErrAIRequestNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "AI_REQUEST_NOT_FOUND",
+			"No recent suggestion response with this request ID", map[string]string{
+				"request_id": req.RequestID,
+			})
+	case errors.Is(err, services.ErrSuggestionIndexOutOfRange):
+		return utils.ValidationErrorResponse(c, map[string]string{
+			"SuggestionIndex": err.Error(),
+		})
+	case err != nil:
+		return utils.InternalServerErrorResponse(c, "Failed to record feedback")
+	}
+
+	return utils.SuccessResponse(c, "Feedback recorded successfully", feedback)
+}
+
+// CancelRequest handles DELETE /api/ai/requests/:requestId
+func (h *AIHandler) CancelRequest(c *fiber.Ctx) error {
+	requestID := c.Params("requestId")
+	if requestID == "" {
+		return utils.BadRequestResponse(c, "Request ID is required", nil)
+	}
+
+	ctx := utils.ContextWithTraceID(context.Background(), utils.GetTraceID(c))
+	err := h.aiService.CancelRequest(ctx, requestID)
+	switch {
+	case errors.Is(err, services.ErrAIRequestNotInFlight):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "AI_REQUEST_NOT_IN_FLIGHT",
+			"No in-flight AI request with this ID", map[string]string{
+				"request_id": requestID,
+			})
+	case err != nil:
+		return utils.InternalServerErrorResponse(c, "Failed to cancel AI request")
+	}
+
+	return utils.SuccessResponse(c, "AI request cancelled successfully", map[string]interface{}{
+		"request_id": requestID,
+		"status":     "cancelled",
+	})
+}
+
 // HealthCheck handles GET /api/ai/health
 func (h *AIHandler) HealthCheck(c *fiber.Ctx) error {
 	// Create context with timeout
@@ -196,6 +381,8 @@ func getValidationErrorMessage(err validator.FieldError) string {
 		return "Must be a valid URL"
 	case "email":
 		return "Must be a valid email address"
+	case "gte", "lte":
+		return "Value is out of range"
 	default:
 		return "Invalid value"
 	}
@@ -2,13 +2,19 @@ package handlers
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/middleware"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
@@ -147,6 +153,66 @@ func TestAIHandler_GetCodeSuggestions(t *testing.T) {
 	}
 }
 
+func TestAIHandler_CompletionOptions(t *testing.T) {
+	cfg := &config.Config{
+		AIAllowedModels: []string{"gpt-3.5-turbo", "gpt-4o"},
+		AIMaxTokens:     2000,
+	}
+	handler := NewAIHandler(services.NewAIService(cfg, nil, utils.NewLogger("debug", "json")))
+
+	app := fiber.New()
+	app.Post("/api/ai/suggestions", handler.GetCodeSuggestions)
+	app.Post("/api/ai/analyze-logs", handler.AnalyzeLogs)
+
+	suggestionBody := func(options string) string {
+		return `{"code": "var x = 1;", "language": "javascript", "request_type": "suggestion"` + options + `}`
+	}
+	analysisBody := func(options string) string {
+		return `{"logs": [{"level": "error", "source": "backend", "message": "Database timeout"}], "analysis_type": "error_detection", "time_range": {"start": "2024-01-15T10:00:00Z", "end": "2024-01-15T11:00:00Z"}` + options + `}`
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "allowed options", path: "/api/ai/suggestions", body: suggestionBody(`, "model": "gpt-4o", "temperature": 0, "max_tokens": 2000`), expectedStatus: http.StatusOK},
+		{name: "model not allowed", path: "/api/ai/suggestions", body: suggestionBody(`, "model": "gpt-4-32k"`), expectedStatus: http.StatusBadRequest, expectedCode: "AI_MODEL_NOT_ALLOWED"},
+		{name: "temperature out of range", path: "/api/ai/suggestions", body: suggestionBody(`, "temperature": 2.5`), expectedStatus: http.StatusBadRequest, expectedCode: "VALIDATION_ERROR"},
+		{name: "max tokens above limit", path: "/api/ai/suggestions", body: suggestionBody(`, "max_tokens": 2001`), expectedStatus: http.StatusBadRequest, expectedCode: "VALIDATION_ERROR"},
+		{name: "log analysis model not allowed", path: "/api/ai/analyze-logs", body: analysisBody(`, "model": "gpt-4-32k"`), expectedStatus: http.StatusBadRequest, expectedCode: "AI_MODEL_NOT_ALLOWED"},
+		{name: "log analysis negative temperature", path: "/api/ai/analyze-logs", body: analysisBody(`, "temperature": -0.1`), expectedStatus: http.StatusBadRequest, expectedCode: "VALIDATION_ERROR"},
+		{name: "retry hints", path: "/api/ai/suggestions", body: suggestionBody(`, "retry": {"max_attempts": 1, "initial_delay_ms": 100}`), expectedStatus: http.StatusOK},
+		{name: "too many retry attempts", path: "/api/ai/suggestions", body: suggestionBody(`, "retry": {"max_attempts": 6}`), expectedStatus: http.StatusBadRequest, expectedCode: "VALIDATION_ERROR"},
+		{name: "log analysis retry delay too long", path: "/api/ai/analyze-logs", body: analysisBody(`, "retry": {"initial_delay_ms": 60000}`), expectedStatus: http.StatusBadRequest, expectedCode: "VALIDATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response utils.StandardResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.expectedCode == "" {
+				assert.True(t, response.Success)
+				return
+			}
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+			if tt.expectedCode == "AI_MODEL_NOT_ALLOWED" {
+				assert.Equal(t, "gpt-3.5-turbo,gpt-4o", response.Error.Details["allowed_models"])
+			}
+		})
+	}
+}
+
 func TestAIHandler_AnalyzeLogs(t *testing.T) {
 	// Setup
 	cfg := &config.Config{
@@ -351,6 +417,308 @@ func TestAIHandler_GetAIStatus(t *testing.T) {
 	assert.Contains(t, statusData, "supported_languages")
 	assert.Contains(t, statusData, "supported_request_types")
 	assert.Contains(t, statusData, "supported_analysis_types")
+	assert.Contains(t, statusData, "feedback")
+}
+
+func TestAIHandler_GetUsage(t *testing.T) {
+	cfg := &config.Config{
+		OpenAIAPIKey:   "",
+		AIUsageTagKeys: []string{"project"},
+	}
+	aiService := services.NewAIService(cfg, nil, utils.NewLogger("debug", "json"))
+	handler := NewAIHandler(aiService)
+
+	app := fiber.New()
+	app.Get("/api/ai/usage", handler.GetUsage)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+	}{
+		{name: "totals only", query: "", expectedStatus: http.StatusOK},
+		{name: "grouped by project", query: "?group_by=project", expectedStatus: http.StatusOK},
+		{name: "unknown grouping", query: "?group_by=customer", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/ai/usage"+tt.query, nil)
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response utils.StandardResponse
+			err = json.NewDecoder(resp.Body).Decode(&response)
+			require.NoError(t, err)
+
+			if tt.expectedStatus == http.StatusOK {
+				data := response.Data.(map[string]interface{})
+				assert.Contains(t, data, "total")
+				assert.Contains(t, data, "since")
+			} else {
+				assert.False(t, response.Success)
+				require.NotNil(t, response.Error)
+				assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+			}
+		})
+	}
+}
+
+func TestAIHandler_GetQuota(t *testing.T) {
+	aiService := services.NewAIService(&config.Config{
+		AIQuotaDailyRequests: 100,
+		AIQuotaDailyTokens:   50000,
+		AIQuotaAPIKeys:       []string{"team-a-secret-key"},
+	}, nil, utils.NewLogger("debug", "json"))
+	handler := NewAIHandler(aiService)
+
+	app := fiber.New()
+	app.Get("/api/ai/quota", handler.GetQuota)
+	app.Get("/authenticated", middleware.JWTAuth(middleware.JWTAuthConfig{
+		Secret: []byte("secret"),
+		Routes: []models.ProtectedRoute{{Method: "*", Path: "/authenticated"}},
+	}), handler.GetQuota)
+	app.Get("/exceeded", func(c *fiber.Ctx) error {
+		return handler.quotaExceededResponse(c, fmt.Errorf("%w: daily limit of 100 requests reached", services.ErrAIQuotaExceeded))
+	})
+
+	get := func(path, apiKey string) (*http.Response, utils.StandardResponse) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	t.Run("usage for the caller's key", func(t *testing.T) {
+		resp, response := get("/api/ai/quota", "team-a-secret-key")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, "team****-key", data["key"], "the key is masked")
+		assert.Equal(t, float64(0), data["requests_used"])
+		assert.Equal(t, float64(100), data["request_limit"])
+		assert.Equal(t, float64(50000), data["token_limit"])
+		assert.Contains(t, data, "resets_at")
+	})
+
+	t.Run("requests without a key use the client IP", func(t *testing.T) {
+		_, response := get("/api/ai/quota", "")
+		assert.Equal(t, "0.0.0.0", response.Data.(map[string]interface{})["key"])
+	})
+
+	t.Run("unlisted keys use the client IP", func(t *testing.T) {
+		_, response := get("/api/ai/quota", "made-up-key-1")
+		assert.Equal(t, "0.0.0.0", response.Data.(map[string]interface{})["key"])
+	})
+
+	t.Run("token subject comes first", func(t *testing.T) {
+		signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(signingInput))
+		token := signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/authenticated", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-API-Key", "team-a-secret-key")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "alice", response.Data.(map[string]interface{})["key"])
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		resp, response := get("/exceeded", "team-a-secret-key")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+
+		require.NotNil(t, response.Error)
+		assert.Equal(t, "QUOTA_EXCEEDED", response.Error.Code)
+		assert.Equal(t, "daily limit of 100 requests reached", response.Error.Details["reason"])
+		assert.Equal(t, "team****-key", response.Error.Details["key"])
+		assert.Equal(t, "100", response.Error.Details["request_limit"])
+	})
+}
+
+func TestAIHandler_ResetUsage(t *testing.T) {
+	aiService := services.NewAIService(&config.Config{OpenAIAPIKey: ""}, nil, utils.NewLogger("debug", "json"))
+	handler := NewAIHandler(aiService)
+
+	app := fiber.New()
+	app.Post("/api/ai/reset-usage", handler.ResetUsage)
+	app.Get("/api/ai/status", handler.GetAIStatus)
+
+	since := aiService.UsageSummary().Since
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/ai/reset-usage", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response utils.StandardResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	data := response.Data.(map[string]interface{})
+	assert.Contains(t, data, "reset_at")
+	previous := data["previous"].(map[string]interface{})
+	assert.Contains(t, previous, "total")
+	assert.True(t, aiService.UsageSummary().Since.After(since))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/ai/status", nil), -1)
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	status := response.Data.(map[string]interface{})["status"].(map[string]interface{})
+	tokenUsage := status["token_usage"].(map[string]interface{})
+	assert.Contains(t, tokenUsage, "total")
+	assert.Contains(t, tokenUsage, "by_request_type")
+}
+
+func TestAIHandler_SubmitFeedback(t *testing.T) {
+	aiService := services.NewAIService(&config.Config{OpenAIAPIKey: ""}, nil, utils.NewLogger("debug", "json"))
+	handler := NewAIHandler(aiService)
+
+	app := fiber.New()
+	app.Post("/api/ai/suggestions", handler.GetCodeSuggestions)
+	app.Post("/api/ai/feedback", handler.SubmitFeedback)
+	app.Get("/api/ai/status", handler.GetAIStatus)
+
+	post := func(path string, body interface{}) (*http.Response, utils.StandardResponse) {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	// Get a response to rate
+	_, suggestions := post("/api/ai/suggestions", models.AIRequest{
+		Code: "var x = 1;", Language: "javascript", RequestType: "suggestion",
+	})
+	requestID := suggestions.Data.(map[string]interface{})["request_id"].(string)
+
+	tests := []struct {
+		name           string
+		body           map[string]interface{}
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "helpful vote",
+			body:           map[string]interface{}{"request_id": requestID, "suggestion_index": 0, "helpful": true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing helpful",
+			body:           map[string]interface{}{"request_id": requestID, "suggestion_index": 0},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "negative index",
+			body:           map[string]interface{}{"request_id": requestID, "suggestion_index": -1, "helpful": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "index out of range",
+			body:           map[string]interface{}{"request_id": requestID, "suggestion_index": 5, "helpful": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "unknown request",
+			body:           map[string]interface{}{"request_id": "missing", "suggestion_index": 0, "helpful": false},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "AI_REQUEST_NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := post("/api/ai/feedback", tt.body)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedCode == "" {
+				assert.True(t, response.Success)
+				data := response.Data.(map[string]interface{})
+				assert.Equal(t, requestID, data["request_id"])
+				assert.Equal(t, true, data["helpful"])
+			} else {
+				assert.False(t, response.Success)
+				require.NotNil(t, response.Error)
+				assert.Equal(t, tt.expectedCode, response.Error.Code)
+			}
+		})
+	}
+
+	t.Run("status reports helpful rate", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/ai/status", nil), -1)
+		require.NoError(t, err)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		feedback := response.Data.(map[string]interface{})["feedback"].(map[string]interface{})
+		assert.Equal(t, float64(1), feedback["total"])
+		assert.Equal(t, float64(100), feedback["helpful_rate"])
+	})
+}
+
+func TestAIHandler_CancelRequest(t *testing.T) {
+	aiService := services.NewAIService(&config.Config{OpenAIAPIKey: ""}, nil, utils.NewLogger("debug", "json"))
+	handler := NewAIHandler(aiService)
+
+	app := fiber.New()
+	app.Post("/api/ai/suggestions", handler.GetCodeSuggestions)
+	app.Delete("/api/ai/requests/:requestId", handler.CancelRequest)
+
+	t.Run("unknown request", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/api/ai/requests/missing", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, "AI_REQUEST_NOT_IN_FLIGHT", response.Error.Code)
+	})
+
+	t.Run("finished request can't be cancelled", func(t *testing.T) {
+		payload, _ := json.Marshal(models.AIRequest{
+			Code: "var x = 1;", Language: "javascript", RequestType: "suggestion", RequestID: "req-done",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/ai/suggestions", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "req-done", response.Data.(map[string]interface{})["request_id"])
+
+		resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api/ai/requests/req-done", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("request ID too long", func(t *testing.T) {
+		payload, _ := json.Marshal(models.AIRequest{
+			Code: "var x = 1;", Language: "javascript", RequestType: "suggestion", RequestID: strings.Repeat("a", 101),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/ai/suggestions", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
 func TestAIHandler_HealthCheck(t *testing.T) {
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxAuditEventsLimit caps how many audit events one request may return
+const maxAuditEventsLimit = 1000
+
+// AuditHandler handles audit trail endpoints
+type AuditHandler struct {
+	store *services.AuditStore
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(store *services.AuditStore) *AuditHandler {
+	return &AuditHandler{
+		store: store,
+	}
+}
+
+// GetEvents handles GET /api/audit/events, returning recorded broadcasts newest first.
+// Optional filters: types (comma separated), start_time and end_time (RFC3339) and limit.
+func (h *AuditHandler) GetEvents(c *fiber.Ctx) error {
+	filter := services.AuditFilter{Limit: 100}
+	validationErrors := make(map[string]string)
+
+	if types := c.Query("types"); types != "" {
+		filter.Types = utils.SplitAndTrim(types, ",")
+	}
+	if startTime := c.Query("start_time"); startTime != "" {
+		parsed, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			validationErrors["start_time"] = "start_time must be an RFC3339 timestamp"
+		}
+		filter.Start = parsed
+	}
+	if endTime := c.Query("end_time"); endTime != "" {
+		parsed, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			validationErrors["end_time"] = "end_time must be an RFC3339 timestamp"
+		}
+		filter.End = parsed
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.End.Before(filter.Start) {
+		validationErrors["end_time"] = "end_time must not be before start_time"
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > maxAuditEventsLimit {
+			validationErrors["limit"] = "limit must be between 1 and " + strconv.Itoa(maxAuditEventsLimit)
+		}
+		filter.Limit = parsed
+	}
+
+	if len(validationErrors) > 0 {
+		return utils.ValidationErrorResponse(c, validationErrors)
+	}
+
+	events, total := h.store.Query(filter)
+
+	return utils.SuccessResponse(c, "Audit events retrieved successfully", models.AuditEventsResponse{
+		Events: events,
+		Total:  total,
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHandler_GetEvents(t *testing.T) {
+	store := services.NewAuditStore([]string{"log_alert", "test_progress"}, 100)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: map[string]string{"level": "critical"}, Timestamp: base})
+	store.RecordBroadcast(models.WSMessage{Type: "test_progress", Data: map[string]string{"status": "completed"}, Timestamp: base.Add(time.Hour)})
+	store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: map[string]string{"level": "critical"}, Timestamp: base.Add(2 * time.Hour)})
+
+	app := fiber.New()
+	app.Get("/api/audit/events", NewAuditHandler(store).GetEvents)
+
+	get := func(query string) (*http.Response, utils.StandardResponse) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/audit/events"+query, nil), -1)
+		require.NoError(t, err)
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+	events := func(response utils.StandardResponse) []interface{} {
+		return response.Data.(map[string]interface{})["events"].([]interface{})
+	}
+
+	t.Run("all events newest first", func(t *testing.T) {
+		resp, response := get("")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, events(response), 3)
+		assert.Equal(t, float64(3), response.Data.(map[string]interface{})["total"])
+
+		newest := events(response)[0].(map[string]interface{})
+		assert.Equal(t, "log_alert", newest["type"])
+		assert.Equal(t, base.Add(2*time.Hour).Format(time.RFC3339), newest["timestamp"])
+		assert.Equal(t, map[string]interface{}{"level": "critical"}, newest["data"])
+	})
+
+	t.Run("type filter", func(t *testing.T) {
+		_, response := get("?types=test_progress")
+		require.Len(t, events(response), 1)
+		assert.Equal(t, "test_progress", events(response)[0].(map[string]interface{})["type"])
+	})
+
+	t.Run("time filter and limit", func(t *testing.T) {
+		_, response := get("?start_time=2024-05-01T12:30:00Z&end_time=2024-05-01T15:00:00Z&limit=1")
+		assert.Len(t, events(response), 1)
+		assert.Equal(t, float64(2), response.Data.(map[string]interface{})["total"])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"?start_time=yesterday",
+			"?end_time=2024-05-01",
+			"?start_time=2024-05-02T00:00:00Z&end_time=2024-05-01T00:00:00Z",
+			"?limit=0",
+			"?limit=5000",
+			"?limit=many",
+		} {
+			resp, response := get(query)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+			assert.False(t, response.Success, query)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChaosHandler handles the failure injection endpoint. Its route is only registered when
+// ENABLE_CHAOS_ENDPOINTS is set outside production.
+type ChaosHandler struct {
+	chaos  *services.ChaosService
+	logger *utils.Logger
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(chaos *services.ChaosService) *ChaosHandler {
+	return &ChaosHandler{
+		chaos:  chaos,
+		logger: utils.GetLogger(),
+	}
+}
+
+// InjectFailure handles POST /api/admin/chaos - injects a synthetic failure, such as an open AI
+// circuit breaker, an unhealthy environment or a critical log alert (admin only)
+func (h *ChaosHandler) InjectFailure(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var req models.ChaosRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to parse chaos request", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+
+	ctx := utils.ContextWithTraceID(c.Context(), traceID)
+	result, err := h.chaos.Inject(ctx, &req)
+	switch {
+	case errors.Is(err, services.ErrInvalidChaosRequest):
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	case errors.Is(err, services.ErrEnvironmentNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "ENVIRONMENT_NOT_FOUND", "Environment not found", map[string]string{
+			"environment": req.Target,
+		})
+	case errors.Is(err, services.ErrAIProviderNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "AI_PROVIDER_NOT_FOUND", "AI provider not configured", map[string]string{
+			"provider": req.Target,
+			"details":  err.Error(),
+		})
+	case err != nil:
+		h.logger.WithTraceID(traceID).Error("Failed to inject failure", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to inject failure")
+	}
+
+	return utils.SuccessResponse(c, "Synthetic failure injected", result)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosHandler_InjectFailure(t *testing.T) {
+	aiService := services.NewAIService(&config.Config{OpenAIAPIKey: "test-key"}, nil, utils.NewLogger("debug", "json"))
+	chaos := services.NewChaosService(aiService, services.NewSyncService(nil), services.NewLogService(nil, nil))
+
+	app := fiber.New()
+	app.Post("/api/admin/chaos", NewChaosHandler(chaos).InjectFailure)
+
+	inject := func(body string) (*http.Response, utils.StandardResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/chaos", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp, response
+	}
+
+	t.Run("opens the AI circuit breaker", func(t *testing.T) {
+		resp, response := inject(`{"action": "ai_circuit_open"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, []interface{}{services.AIProviderPrimary}, data["targets"])
+
+		providers := aiService.GetStatus()["providers"].([]map[string]interface{})
+		assert.Equal(t, "OPEN", providers[0]["circuit_state"])
+	})
+
+	t.Run("raises a log alert", func(t *testing.T) {
+		resp, response := inject(`{"action": "log_alert", "message": "payment provider down"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		data := response.Data.(map[string]interface{})
+		assert.NotEmpty(t, data["alert_id"])
+		assert.Equal(t, "payment provider down", data["message"])
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"invalid JSON", `{"action":`, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"unknown action", `{"action": "drop_tables"}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"environment without target", `{"action": "environment_unhealthy"}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unknown environment", `{"action": "environment_unhealthy", "target": "staging"}`, http.StatusNotFound, "ENVIRONMENT_NOT_FOUND"},
+		{"unknown AI provider", `{"action": "ai_circuit_open", "target": "secondary"}`, http.StatusNotFound, "AI_PROVIDER_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, response := inject(tt.body)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
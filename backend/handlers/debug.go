@@ -42,8 +42,12 @@ func (h *DebugHandler) GetConfig(c *fiber.Ctx) error {
 			"endpoint": h.config.WSEndpoint,
 		},
 		"logging": fiber.Map{
-			"level":  h.config.LogLevel,
-			"format": h.config.LogFormat,
+			"level":         h.config.LogLevel,
+			"format":        h.config.LogFormat,
+			"active_format": utils.GetLogger().Format(),
+			"output":        h.config.LogOutput,
+			"sampling":      h.config.EnableLogSampling,
+			"sampled":       utils.GetLogger().SampledEntries(),
 		},
 		"testing": fiber.Map{
 			"cypress_base_url":    h.config.CypressBaseURL,
@@ -187,6 +191,34 @@ func (h *DebugHandler) GetFeatureToggles(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Feature toggles retrieved", toggles)
 }
 
+// SetLogFormat switches the server log output format (json, text or console) without a restart
+func (h *DebugHandler) SetLogFormat(c *fiber.Ctx) error {
+	var req struct {
+		Format string `json:"format"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+	}
+
+	logger := utils.GetLogger()
+	previous := logger.Format()
+	if err := logger.SetFormat(strings.ToLower(strings.TrimSpace(req.Format))); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"allowed_values": "json text console",
+		})
+	}
+
+	logger.Info("Log format switched", map[string]interface{}{
+		"previous_format": previous,
+		"format":          logger.Format(),
+	})
+
+	return utils.SuccessResponse(c, "Log format updated", fiber.Map{
+		"format":          logger.Format(),
+		"previous_format": previous,
+	})
+}
+
 // GetHealthChecks returns detailed health check information
 func (h *DebugHandler) GetHealthChecks(c *fiber.Ctx) error {
 	checks := fiber.Map{
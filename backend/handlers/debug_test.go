@@ -5,9 +5,11 @@ import (
 	"io"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 )
@@ -253,3 +255,36 @@ func TestMaskSensitiveValue(t *testing.T) {
 		})
 	}
 }
+
+func TestDebugHandler_SetLogFormat(t *testing.T) {
+	logger := utils.GetLogger()
+	original := logger.Format()
+	defer logger.SetFormat(original)
+	logger.SetFormat(utils.LogFormatJSON)
+
+	handler := NewDebugHandler(&config.Config{})
+	app := fiber.New()
+	app.Put("/debug/log-format", handler.SetLogFormat)
+
+	req := httptest.NewRequest("PUT", "/debug/log-format", strings.NewReader(`{"format":"Console"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var result map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	data := result["data"].(map[string]interface{})
+	assert.Equal(t, "console", data["format"])
+	assert.Equal(t, "json", data["previous_format"])
+	assert.Equal(t, utils.LogFormatConsole, logger.Format())
+
+	t.Run("unknown format is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/debug/log-format", strings.NewReader(`{"format":"yaml"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		assert.Equal(t, utils.LogFormatConsole, logger.Format())
+	})
+}
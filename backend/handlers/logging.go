@@ -1,10 +1,21 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,10 +24,71 @@ import (
 type LogServiceInterface interface {
 	SubmitLogs(ctx context.Context, req *models.LogSubmissionRequest) (*models.LogSubmissionResponse, error)
 	AnalyzeLogs(ctx context.Context, req *models.LogAnalysisRequest) (*models.LogAnalysisResponse, error)
+	CreateAnalysisReport(ctx context.Context, req *models.LogAnalysisRequest) (*models.LogAnalysisReport, error)
+	GetAnalysisReport(reportID string) (*models.LogAnalysisReport, error)
 	GetLogCount() int
-	ClearLogs()
+	GetLogLevels() []string
+	GetStoreWriteStats() models.LogStoreWriteStats
+	GetClockSkewStats() models.LogClockSkewStats
+	GetFingerprints() ([]models.LogFingerprint, error)
+	ExportLogs(req *models.LogAnalysisRequest) (services.LogExportFunc, error)
+	ClearLogs() error
+	PauseIngestion(reason string, retryAfter time.Duration) models.LogIngestionState
+	ResumeIngestion() models.LogIngestionState
+	GetIngestionState() models.LogIngestionState
+	GetAlertRules() []models.LogAlertRule
+	AddAlertRule(rule models.LogAlertRule) error
+	RemoveAlertRule(keyword string) error
+	AcknowledgeAlert(alertID, acknowledgedBy string) (*models.CriticalLogAlert, error)
 }
 
+// analysisReportTemplate renders a stored analysis report as a self-contained HTML page
+var analysisReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Log Analysis Report {{.ID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #ccc; padding: 0.3rem 0.6rem; text-align: left; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>Log Analysis Report</h1>
+<p class="meta">Report {{.ID}} &middot; generated {{.CreatedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+
+<h2>Summary</h2>
+<p>{{.Analysis.Summary}}</p>
+
+<h2>Statistics</h2>
+<table>
+<tr><th>Total logs</th><td>{{.Analysis.Statistics.TotalLogs}}</td></tr>
+<tr><th>Error rate</th><td>{{printf "%.2f" .Analysis.Statistics.ErrorRate}}%</td></tr>
+{{range $level, $count := .Analysis.Statistics.LogsByLevel}}<tr><th>{{$level}}</th><td>{{$count}}</td></tr>
+{{end}}</table>
+
+<h2>Issues</h2>
+{{if .Analysis.Issues}}<table>
+<tr><th>Severity</th><th>Type</th><th>Count</th><th>Description</th><th>Solution</th></tr>
+{{range .Analysis.Issues}}<tr><td>{{.Severity}}</td><td>{{.Type}}</td><td>{{.Count}}</td><td>{{.Description}}</td><td>{{.Solution}}</td></tr>
+{{end}}</table>{{else}}<p>No issues detected.</p>{{end}}
+
+<h2>Patterns</h2>
+{{if .Analysis.Patterns}}<table>
+<tr><th>Pattern</th><th>Category</th><th>Frequency</th><th>Trend</th></tr>
+{{range .Analysis.Patterns}}<tr><td>{{.Pattern}}</td><td>{{.Category}}</td><td>{{.Frequency}}</td><td>{{.Trend}}</td></tr>
+{{end}}</table>{{else}}<p>No patterns detected.</p>{{end}}
+
+<h2>Suggestions</h2>
+{{if .Analysis.Suggestions}}<ul>
+{{range .Analysis.Suggestions}}<li>{{.}}</li>
+{{end}}</ul>{{else}}<p>No suggestions.</p>{{end}}
+</body>
+</html>
+`))
+
 // LoggingHandler handles logging and debugging endpoints
 type LoggingHandler struct {
 	logService LogServiceInterface
@@ -52,11 +124,14 @@ func (h *LoggingHandler) SubmitLogs(c *fiber.Ctx) error {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(utils.ContextWithTraceID(context.Background(), utils.GetTraceID(c)), 30*time.Second)
 	defer cancel()
 
 	// Submit logs to service
 	response, err := h.logService.SubmitLogs(ctx, &req)
+	if errors.Is(err, services.ErrIngestionPaused) {
+		return h.ingestionPausedResponse(c)
+	}
 	if err != nil {
 		h.logger.WithTraceID(traceID).Error("Failed to submit logs", err, map[string]interface{}{
 			"batch_id":  req.BatchID,
@@ -66,98 +141,352 @@ func (h *LoggingHandler) SubmitLogs(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "SUBMISSION_FAILED", "Failed to submit logs", nil)
 	}
 
-	h.logger.WithTraceID(traceID).Info("Log submission completed successfully", map[string]interface{}{
+	h.logger.WithTraceID(traceID).Info("Log submission completed", map[string]interface{}{
 		"batch_id": response.BatchID,
+		"status":   response.Status,
 		"accepted": response.Accepted,
 		"rejected": response.Rejected,
 	})
 
-	return utils.SuccessResponse(c, "Logs submitted successfully", response)
+	// A batch where nothing was usable is an error; clients get the per-entry errors to fix it
+	switch response.Status {
+	case models.LogSubmissionAllRejected:
+		return utils.ErrorResponseWithData(c, fiber.StatusUnprocessableEntity, "ALL_LOGS_REJECTED", "All submitted logs were rejected", response)
+	case models.LogSubmissionPartial:
+		return utils.SuccessResponse(c, "Logs partially accepted", response)
+	default:
+		return utils.SuccessResponse(c, "Logs submitted successfully", response)
+	}
+}
+
+// ingestionPausedResponse rejects a log submission while ingestion is paused, telling the client
+// why and, if the pause gave one, when to retry
+func (h *LoggingHandler) ingestionPausedResponse(c *fiber.Ctx) error {
+	state := h.logService.GetIngestionState()
+	details := map[string]string{}
+	if state.Reason != "" {
+		details["reason"] = state.Reason
+	}
+	if state.PausedAt != nil {
+		details["paused_at"] = state.PausedAt.Format(time.RFC3339)
+	}
+	if state.RetryAfterSeconds > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(state.RetryAfterSeconds))
+	}
+
+	return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "LOG_INGESTION_PAUSED", "Log ingestion is paused", details)
 }
 
-// AnalyzeLogs handles GET /api/logs/analyze - performs log analysis and pattern detection
+// worstSeverityHeader reports the worst issue severity of a log analysis, for monitors that
+// don't parse the body
+const worstSeverityHeader = "X-Log-Worst-Severity"
+
+// AnalyzeLogs handles GET /api/logs/analyze - performs log analysis and pattern detection.
+// With ?fail_on=<severity>, finding an issue at least that severe answers 503 instead of 200.
 func (h *LoggingHandler) AnalyzeLogs(c *fiber.Ctx) error {
 	traceID := utils.GetTraceID(c)
 	h.logger.WithTraceID(traceID).Info("Processing log analysis request", nil)
 
-	// Parse query parameters into analysis request
-	req := &models.LogAnalysisRequest{
-		Limit: 1000, // Default limit
+	failOn := c.Query("fail_on")
+	if failOn != "" && !slices.Contains(models.LogIssueSeverities, failOn) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"allowed_values": strings.Join(models.LogIssueSeverities, ","),
+		})
 	}
 
-	// Parse time range
-	if startTime := c.Query("start_time"); startTime != "" {
-		if parsed, err := time.Parse(time.RFC3339, startTime); err == nil {
-			req.TimeRange.Start = parsed
-		}
+	req, details := h.parseAnalysisRequest(c)
+	if details != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", details)
 	}
-	if endTime := c.Query("end_time"); endTime != "" {
-		if parsed, err := time.Parse(time.RFC3339, endTime); err == nil {
-			req.TimeRange.End = parsed
-		}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(utils.ContextWithTraceID(context.Background(), utils.GetTraceID(c)), 60*time.Second)
+	defer cancel()
+
+	// Perform log analysis
+	response, err := h.logService.AnalyzeLogs(ctx, req)
+	if errors.Is(err, services.ErrAnalysisRangeTooWide) {
+		return timeRangeTooWideResponse(c, err)
+	}
+	if errors.Is(err, services.ErrInvalidSearchQuery) {
+		return invalidSearchQueryResponse(c, err)
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to analyze logs", err, map[string]interface{}{
+			"time_range": req.TimeRange,
+			"filters":    req.Filters,
+		})
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "ANALYSIS_FAILED", "Failed to analyze logs", nil)
 	}
 
-	// Parse levels filter
-	if levels := c.Query("levels"); levels != "" {
-		req.Levels = utils.SplitAndTrim(levels, ",")
+	h.logger.WithTraceID(traceID).Info("Log analysis completed successfully", map[string]interface{}{
+		"issues_found":   len(response.Issues),
+		"worst_severity": response.WorstSeverity,
+		"patterns":       len(response.Patterns),
+		"suggestions":    len(response.Suggestions),
+	})
+
+	c.Set(worstSeverityHeader, response.WorstSeverity)
+	if failOn != "" && models.SeverityAtLeast(response.WorstSeverity, failOn) {
+		return utils.ErrorResponseWithData(c, fiber.StatusServiceUnavailable, "LOG_SEVERITY_THRESHOLD_EXCEEDED",
+			fmt.Sprintf("Log analysis found %s severity issues", response.WorstSeverity), response)
 	}
 
-	// Parse sources filter
-	if sources := c.Query("sources"); sources != "" {
-		req.Sources = utils.SplitAndTrim(sources, ",")
+	return utils.SuccessResponse(c, "Log analysis completed", response)
+}
+
+// CreateAnalysisReport handles POST /api/logs/reports - runs an analysis and stores it as a shareable report
+func (h *LoggingHandler) CreateAnalysisReport(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+	h.logger.WithTraceID(traceID).Info("Processing log analysis report request", nil)
+
+	req, details := h.parseAnalysisRequest(c)
+	if details != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", details)
 	}
 
-	// Parse components filter
-	if components := c.Query("components"); components != "" {
-		req.Components = utils.SplitAndTrim(components, ",")
+	ctx, cancel := context.WithTimeout(utils.ContextWithTraceID(context.Background(), traceID), 60*time.Second)
+	defer cancel()
+
+	report, err := h.logService.CreateAnalysisReport(ctx, req)
+	if errors.Is(err, services.ErrAnalysisRangeTooWide) {
+		return timeRangeTooWideResponse(c, err)
+	}
+	if errors.Is(err, services.ErrInvalidSearchQuery) {
+		return invalidSearchQueryResponse(c, err)
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to create log analysis report", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "ANALYSIS_FAILED", "Failed to analyze logs", nil)
 	}
 
-	// Parse search query
-	req.SearchQuery = c.Query("search")
+	return utils.SuccessResponse(c, "Log analysis report created", report)
+}
 
-	// Parse limit
-	if limit := c.QueryInt("limit", 1000); limit > 0 && limit <= 10000 {
-		req.Limit = limit
+// GetAnalysisReport handles GET /api/logs/reports/:id - returns a stored analysis report as JSON or HTML
+func (h *LoggingHandler) GetAnalysisReport(c *fiber.Ctx) error {
+	reportID := c.Params("id")
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "html" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"allowed_values": "json html",
+		})
 	}
 
-	// Parse custom filters
-	req.Filters = make(map[string]string)
-	if userID := c.Query("user_id"); userID != "" {
-		req.Filters["user_id"] = userID
+	report, err := h.logService.GetAnalysisReport(reportID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "REPORT_NOT_FOUND", "Analysis report not found", map[string]string{
+			"report_id": reportID,
+		})
 	}
-	if sessionID := c.Query("session_id"); sessionID != "" {
-		req.Filters["session_id"] = sessionID
+
+	if format == "html" {
+		var buf bytes.Buffer
+		if err := analysisReportTemplate.Execute(&buf, report); err != nil {
+			h.logger.WithTraceID(utils.GetTraceID(c)).Error("Failed to render analysis report", err, map[string]interface{}{
+				"report_id": reportID,
+			})
+			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "REPORT_RENDER_FAILED", "Failed to render analysis report", nil)
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(buf.Bytes())
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(req); err != nil {
-		h.logger.WithTraceID(traceID).Error("Log analysis request validation failed", err, nil)
+	return utils.SuccessResponse(c, "Log analysis report retrieved", report)
+}
+
+// logExportContentTypes maps each export format to its Content-Type
+var logExportContentTypes = map[string]string{
+	models.LogExportCSV:    "text/csv; charset=utf-8",
+	models.LogExportNDJSON: "application/x-ndjson",
+}
+
+// logExportGzip is the compress value that gzips an export
+const logExportGzip = "gzip"
+
+// ExportLogs handles GET /api/logs/export - downloads the logs matching the analysis filters as CSV or
+// NDJSON, gzip-compressed with ?compress=gzip
+func (h *LoggingHandler) ExportLogs(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	format := c.Query("format", models.LogExportNDJSON)
+	contentType, supported := logExportContentTypes[format]
+	if !supported {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
-			"details": err.Error(),
+			"allowed_values": "csv ndjson",
 		})
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	compress := c.Query("compress")
+	if compress != "" && compress != logExportGzip {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"allowed_values": logExportGzip,
+		})
+	}
 
-	// Perform log analysis
-	response, err := h.logService.AnalyzeLogs(ctx, req)
+	query, details := utils.ParseLogAnalysisQuery(c)
+	if details != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", details)
+	}
+	req := &models.LogAnalysisRequest{}
+	applyLogAnalysisQuery(req, query)
+
+	export, err := h.logService.ExportLogs(req)
+	if errors.Is(err, services.ErrInvalidSearchQuery) {
+		return invalidSearchQueryResponse(c, err)
+	}
 	if err != nil {
-		h.logger.WithTraceID(traceID).Error("Failed to analyze logs", err, map[string]interface{}{
-			"time_range": req.TimeRange,
-			"filters":    req.Filters,
-		})
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "ANALYSIS_FAILED", "Failed to analyze logs", nil)
+		h.logger.WithTraceID(traceID).Error("Failed to export logs", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to export logs")
 	}
 
-	h.logger.WithTraceID(traceID).Info("Log analysis completed successfully", map[string]interface{}{
-		"issues_found": len(response.Issues),
-		"patterns":     len(response.Patterns),
-		"suggestions":  len(response.Suggestions),
+	c.Attachment(fmt.Sprintf("logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format))
+	c.Set(fiber.HeaderContentType, contentType)
+	if compress == logExportGzip {
+		c.Set(fiber.HeaderContentEncoding, logExportGzip)
+	}
+
+	// Entries are written as they are read from the store, and compressed as they are written;
+	// once streaming has started the status can't change, so a store error ends the download
+	// early and is only logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var out io.Writer = w
+		var gz *gzip.Writer
+		if compress == logExportGzip {
+			gz = gzip.NewWriter(w)
+			out = gz
+		}
+
+		written, err := services.WriteLogExport(out, format, export)
+		if gz != nil {
+			if closeErr := gz.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		fields := map[string]interface{}{
+			"format":  format,
+			"entries": written,
+		}
+		if gz != nil {
+			fields["compress"] = compress
+		}
+		if err != nil {
+			h.logger.WithTraceID(traceID).Error("Log export stopped early", err, fields)
+			return
+		}
+		h.logger.WithTraceID(traceID).Info("Log export completed", fields)
 	})
+	return nil
+}
 
-	return utils.SuccessResponse(c, "Log analysis completed", response)
+// timeRangeTooWideResponse rejects an analysis whose time range exceeds the configured maximum
+func timeRangeTooWideResponse(c *fiber.Ctx, err error) error {
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, "TIME_RANGE_TOO_WIDE",
+		"Requested time range is too wide; narrow start_time and end_time", map[string]string{
+			"time_range": err.Error(),
+		})
+}
+
+// invalidSearchQueryResponse rejects an analysis whose search query can't be parsed
+func invalidSearchQueryResponse(c *fiber.Ctx, err error) error {
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+		"search": err.Error(),
+	})
+}
+
+// parseAnalysisRequest builds an analysis request from an optional JSON body and the query
+// parameters, returning validation error details when either is invalid. Query parameters take
+// precedence over the body field by field, see applyLogAnalysisQuery.
+func (h *LoggingHandler) parseAnalysisRequest(c *fiber.Ctx) (*models.LogAnalysisRequest, map[string]string) {
+	req := &models.LogAnalysisRequest{
+		Limit: 1000, // Default limit
+	}
+
+	// GET requests rarely carry a body, but clients that send one get it honored
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return nil, map[string]string{
+				"body": "must be a JSON log analysis request",
+			}
+		}
+	}
+
+	query, details := utils.ParseLogAnalysisQuery(c)
+	if details != nil {
+		return nil, details
+	}
+	applyLogAnalysisQuery(req, query)
+
+	// Parse grouping
+	if req.GroupBy != "" && req.GroupBy != models.LogGroupByComponent {
+		return nil, map[string]string{
+			"group_by":       req.GroupBy,
+			"allowed_values": models.LogGroupByComponent,
+		}
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		h.logger.WithTraceID(utils.GetTraceID(c)).Error("Log analysis request validation failed", err, nil)
+		return nil, map[string]string{
+			"details": err.Error(),
+		}
+	}
+
+	return req, nil
+}
+
+// applyLogAnalysisQuery overrides the fields of req whose query parameters were given. Custom
+// filters are merged instead, a query filter replacing a body filter with the same key.
+func applyLogAnalysisQuery(req *models.LogAnalysisRequest, query *utils.LogAnalysisQuery) {
+	if !query.StartTime.IsZero() {
+		req.TimeRange.Start = query.StartTime
+	}
+	if !query.EndTime.IsZero() {
+		req.TimeRange.End = query.EndTime
+	}
+	if query.Levels != nil {
+		req.Levels = query.Levels
+	}
+	if query.Sources != nil {
+		req.Sources = query.Sources
+	}
+	if query.Components != nil {
+		req.Components = query.Components
+	}
+	if query.SearchQuery != "" {
+		req.SearchQuery = query.SearchQuery
+	}
+	if query.SearchMode != "" {
+		req.SearchMode = query.SearchMode
+	}
+	if query.GroupBy != "" {
+		req.GroupBy = query.GroupBy
+	}
+
+	if req.Filters == nil {
+		req.Filters = make(map[string]string)
+	}
+	for key, value := range query.Filters {
+		req.Filters[key] = value
+	}
+
+	if query.Limit != nil {
+		req.Limit = *query.Limit
+	}
+	if query.Offset != nil {
+		req.Offset = *query.Offset
+	}
+	if query.SpikeThreshold != nil {
+		req.SpikeThreshold = *query.SpikeThreshold
+	}
+	if query.SpikeWindowMinutes != nil {
+		req.SpikeWindowMinutes = *query.SpikeWindowMinutes
+	}
 }
 
 // GetLogStats handles GET /api/logs/stats - returns log statistics
@@ -166,13 +495,53 @@ func (h *LoggingHandler) GetLogStats(c *fiber.Ctx) error {
 	h.logger.WithTraceID(traceID).Info("Processing log statistics request", nil)
 
 	stats := map[string]interface{}{
-		"total_logs": h.logService.GetLogCount(),
-		"timestamp":  time.Now(),
+		"total_logs":   h.logService.GetLogCount(),
+		"store_writes": h.logService.GetStoreWriteStats(),
+		"clock_skew":   h.logService.GetClockSkewStats(),
+		"timestamp":    time.Now(),
 	}
 
 	return utils.SuccessResponse(c, "Log statistics retrieved", stats)
 }
 
+// Fingerprints returned by GET /api/logs/fingerprints when no limit is given, and the most allowed
+const (
+	defaultFingerprintsLimit = 100
+	maxFingerprintsLimit     = 1000
+)
+
+// GetFingerprints handles GET /api/logs/fingerprints - lists error fingerprints, most frequent first
+func (h *LoggingHandler) GetFingerprints(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	limit := defaultFingerprintsLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxFingerprintsLimit {
+			return utils.ValidationErrorResponse(c, map[string]string{
+				"limit": "limit must be between 1 and " + strconv.Itoa(maxFingerprintsLimit),
+			})
+		}
+		limit = parsed
+	}
+
+	fingerprints, err := h.logService.GetFingerprints()
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to list log fingerprints", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to list log fingerprints")
+	}
+
+	total := len(fingerprints)
+	if total > limit {
+		fingerprints = fingerprints[:limit]
+	}
+
+	return utils.SuccessResponse(c, "Log fingerprints retrieved", models.LogFingerprintsResponse{
+		Fingerprints: fingerprints,
+		Total:        total,
+	})
+}
+
 // ClearLogs handles DELETE /api/logs/clear - clears all stored logs (admin only)
 func (h *LoggingHandler) ClearLogs(c *fiber.Ctx) error {
 	traceID := utils.GetTraceID(c)
@@ -181,7 +550,10 @@ func (h *LoggingHandler) ClearLogs(c *fiber.Ctx) error {
 	// In a production system, you would check for admin permissions here
 	// For now, we'll allow it for development/testing purposes
 
-	h.logService.ClearLogs()
+	if err := h.logService.ClearLogs(); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to clear logs", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to clear logs")
+	}
 
 	h.logger.WithTraceID(traceID).Info("All logs cleared successfully", nil)
 
@@ -190,17 +562,148 @@ func (h *LoggingHandler) ClearLogs(c *fiber.Ctx) error {
 	})
 }
 
+// PauseIngestion handles POST /api/logs/pause - rejects log submissions until ingestion is resumed
+// (admin only). The body is optional.
+func (h *LoggingHandler) PauseIngestion(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var req models.LogIngestionPauseRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			h.logger.WithTraceID(traceID).Error("Failed to parse ingestion pause request", err, nil)
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+		}
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+
+	state := h.logService.PauseIngestion(req.Reason, time.Duration(req.RetryAfterSeconds)*time.Second)
+	h.logger.WithTraceID(traceID).Info("Log ingestion paused", map[string]interface{}{
+		"reason": state.Reason,
+	})
+
+	return utils.SuccessResponse(c, "Log ingestion paused", state)
+}
+
+// ResumeIngestion handles POST /api/logs/resume - accepts log submissions again (admin only)
+func (h *LoggingHandler) ResumeIngestion(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	state := h.logService.ResumeIngestion()
+	h.logger.WithTraceID(traceID).Info("Log ingestion resumed", nil)
+
+	return utils.SuccessResponse(c, "Log ingestion resumed", state)
+}
+
+// UpdateAlertRules handles POST /api/logs/alert-rules - adds or removes a critical-log keyword rule
+func (h *LoggingHandler) UpdateAlertRules(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var req models.LogAlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to parse alert rule request", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+
+	var err error
+	message := "Alert rule added"
+	if req.Action == "remove" {
+		err = h.logService.RemoveAlertRule(req.Keyword)
+		message = "Alert rule removed"
+	} else {
+		err = h.logService.AddAlertRule(models.LogAlertRule{Keyword: req.Keyword, MinLevel: req.MinLevel})
+	}
+
+	if errors.Is(err, services.ErrAlertRuleNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "ALERT_RULE_NOT_FOUND", "Alert rule not found", map[string]string{
+			"keyword": req.Keyword,
+		})
+	}
+	if errors.Is(err, services.ErrInvalidAlertRule) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to update alert rules", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to update alert rules")
+	}
+
+	h.logger.WithTraceID(traceID).Info(message, map[string]interface{}{
+		"keyword":   req.Keyword,
+		"min_level": req.MinLevel,
+	})
+
+	return utils.SuccessResponse(c, message, map[string]interface{}{
+		"rules": h.logService.GetAlertRules(),
+	})
+}
+
+// AcknowledgeAlert handles POST /api/logs/alerts/:id/ack - acknowledges a critical log_alert so it
+// isn't escalated
+func (h *LoggingHandler) AcknowledgeAlert(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+	alertID := c.Params("id")
+
+	// The body is optional; an empty one acknowledges anonymously
+	var req models.LogAlertAckRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			h.logger.WithTraceID(traceID).Error("Failed to parse alert acknowledgement", err, nil)
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST", "Invalid request body", nil)
+		}
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"details": err.Error(),
+		})
+	}
+
+	alert, err := h.logService.AcknowledgeAlert(alertID, req.AcknowledgedBy)
+	if errors.Is(err, services.ErrAlertNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "ALERT_NOT_FOUND", "Alert not found", map[string]string{
+			"alert_id": alertID,
+		})
+	}
+	if errors.Is(err, services.ErrAlertAlreadyAcknowledged) {
+		return utils.ErrorResponse(c, fiber.StatusConflict, "ALERT_ALREADY_ACKNOWLEDGED", "Alert already acknowledged", map[string]string{
+			"alert_id": alertID,
+		})
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to acknowledge alert", err, nil)
+		return utils.InternalServerErrorResponse(c, "Failed to acknowledge alert")
+	}
+
+	return utils.SuccessResponse(c, "Alert acknowledged", alert)
+}
+
 // GetLoggingStatus handles GET /api/logs/status - returns logging service status
 func (h *LoggingHandler) GetLoggingStatus(c *fiber.Ctx) error {
 	traceID := utils.GetTraceID(c)
 	h.logger.WithTraceID(traceID).Info("Processing logging status request", nil)
 
 	status := map[string]interface{}{
-		"service":    "logging",
-		"status":     "healthy",
-		"total_logs": h.logService.GetLogCount(),
-		"timestamp":  time.Now(),
-		"version":    "1.0.0",
+		"service":      "logging",
+		"status":       "healthy",
+		"total_logs":   h.logService.GetLogCount(),
+		"log_levels":   h.logService.GetLogLevels(),
+		"store_writes": h.logService.GetStoreWriteStats(),
+		"clock_skew":   h.logService.GetClockSkewStats(),
+		"ingestion":    h.logService.GetIngestionState(),
+		"timestamp":    time.Now(),
+		"version":      "1.0.0",
 	}
 
 	return utils.SuccessResponse(c, "Logging service status", status)
@@ -2,18 +2,25 @@ package handlers
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Remove duplicate interface declaration - it's already in logging.go
@@ -48,13 +55,99 @@ func (m *MockLogService) AnalyzeLogs(ctx context.Context, req *models.LogAnalysi
 	return args.Get(0).(*models.LogAnalysisResponse), args.Error(1)
 }
 
+func (m *MockLogService) CreateAnalysisReport(ctx context.Context, req *models.LogAnalysisRequest) (*models.LogAnalysisReport, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LogAnalysisReport), args.Error(1)
+}
+
+func (m *MockLogService) GetAnalysisReport(reportID string) (*models.LogAnalysisReport, error) {
+	args := m.Called(reportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LogAnalysisReport), args.Error(1)
+}
+
 func (m *MockLogService) GetLogCount() int {
 	args := m.Called()
 	return args.Int(0)
 }
 
-func (m *MockLogService) ClearLogs() {
-	m.Called()
+func (m *MockLogService) GetStoreWriteStats() models.LogStoreWriteStats {
+	args := m.Called()
+	return args.Get(0).(models.LogStoreWriteStats)
+}
+
+func (m *MockLogService) GetClockSkewStats() models.LogClockSkewStats {
+	args := m.Called()
+	return args.Get(0).(models.LogClockSkewStats)
+}
+
+func (m *MockLogService) GetFingerprints() ([]models.LogFingerprint, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LogFingerprint), args.Error(1)
+}
+
+func (m *MockLogService) ExportLogs(req *models.LogAnalysisRequest) (services.LogExportFunc, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(services.LogExportFunc), args.Error(1)
+}
+
+func (m *MockLogService) GetLogLevels() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockLogService) ClearLogs() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockLogService) PauseIngestion(reason string, retryAfter time.Duration) models.LogIngestionState {
+	args := m.Called(reason, retryAfter)
+	return args.Get(0).(models.LogIngestionState)
+}
+
+func (m *MockLogService) ResumeIngestion() models.LogIngestionState {
+	args := m.Called()
+	return args.Get(0).(models.LogIngestionState)
+}
+
+func (m *MockLogService) GetIngestionState() models.LogIngestionState {
+	args := m.Called()
+	return args.Get(0).(models.LogIngestionState)
+}
+
+func (m *MockLogService) GetAlertRules() []models.LogAlertRule {
+	args := m.Called()
+	return args.Get(0).([]models.LogAlertRule)
+}
+
+func (m *MockLogService) AddAlertRule(rule models.LogAlertRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockLogService) RemoveAlertRule(keyword string) error {
+	args := m.Called(keyword)
+	return args.Error(0)
+}
+
+func (m *MockLogService) AcknowledgeAlert(alertID, acknowledgedBy string) (*models.CriticalLogAlert, error) {
+	args := m.Called(alertID, acknowledgedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CriticalLogAlert), args.Error(1)
 }
 
 func setupLoggingTestApp() (*fiber.App, *MockLogService) {
@@ -67,8 +160,16 @@ func setupLoggingTestApp() (*fiber.App, *MockLogService) {
 	logs := api.Group("/logs")
 	logs.Post("/submit", handler.SubmitLogs)
 	logs.Get("/analyze", handler.AnalyzeLogs)
+	logs.Get("/export", handler.ExportLogs)
+	logs.Post("/reports", handler.CreateAnalysisReport)
+	logs.Get("/reports/:id", handler.GetAnalysisReport)
 	logs.Get("/stats", handler.GetLogStats)
+	logs.Get("/fingerprints", handler.GetFingerprints)
 	logs.Delete("/clear", handler.ClearLogs)
+	logs.Post("/pause", handler.PauseIngestion)
+	logs.Post("/resume", handler.ResumeIngestion)
+	logs.Post("/alert-rules", handler.UpdateAlertRules)
+	logs.Post("/alerts/:id/ack", handler.AcknowledgeAlert)
 	logs.Get("/status", handler.GetLoggingStatus)
 	logs.Get("/health", handler.HealthCheck)
 
@@ -102,16 +203,62 @@ func TestLoggingHandler_SubmitLogs(t *testing.T) {
 			setupMock: func() {
 				mockService.On("SubmitLogs", mock.Anything, mock.Anything).Return(
 					&models.LogSubmissionResponse{
+						Status:      models.LogSubmissionAllAccepted,
 						Accepted:    1,
 						Rejected:    0,
 						BatchID:     "batch-123",
 						ProcessedAt: time.Now(),
-						Errors:      []string{},
 					}, nil)
 			},
 			expectedStatus: 200,
 			expectSuccess:  true,
 		},
+		{
+			name: "Partially accepted batch",
+			requestBody: models.LogSubmissionRequest{
+				Logs: []models.LogEntry{
+					{Level: "info", Source: "frontend", Message: "Test message"},
+					{Level: "verbose", Source: "frontend", Message: "Bad level"},
+				},
+				Source: "frontend",
+			},
+			setupMock: func() {
+				mockService.On("SubmitLogs", mock.Anything, mock.Anything).Return(
+					&models.LogSubmissionResponse{
+						Status:      models.LogSubmissionPartial,
+						Partial:     true,
+						Accepted:    1,
+						Rejected:    1,
+						BatchID:     "batch-124",
+						ProcessedAt: time.Now(),
+						Errors:      []models.LogEntryError{{Index: 1, Field: "level", Reason: "invalid level: verbose"}},
+					}, nil)
+			},
+			expectedStatus: 200,
+			expectSuccess:  true,
+		},
+		{
+			name: "All logs rejected",
+			requestBody: models.LogSubmissionRequest{
+				Logs: []models.LogEntry{
+					{Level: "verbose", Source: "frontend", Message: "Bad level"},
+				},
+				Source: "frontend",
+			},
+			setupMock: func() {
+				mockService.On("SubmitLogs", mock.Anything, mock.Anything).Return(
+					&models.LogSubmissionResponse{
+						Status:      models.LogSubmissionAllRejected,
+						Accepted:    0,
+						Rejected:    1,
+						BatchID:     "batch-125",
+						ProcessedAt: time.Now(),
+						Errors:      []models.LogEntryError{{Index: 0, Field: "level", Reason: "invalid level: verbose"}},
+					}, nil)
+			},
+			expectedStatus: 422,
+			expectSuccess:  false,
+		},
 		{
 			name:           "Invalid JSON body",
 			requestBody:    "invalid json",
@@ -166,6 +313,22 @@ func TestLoggingHandler_SubmitLogs(t *testing.T) {
 				assert.Equal(t, false, response["success"])
 			}
 
+			if tt.expectedStatus == 422 {
+				// Rejected batches still carry the per-entry errors
+				errorInfo := response["error"].(map[string]interface{})
+				assert.Equal(t, "ALL_LOGS_REJECTED", errorInfo["code"])
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, models.LogSubmissionAllRejected, data["status"])
+				assert.Equal(t, false, data["partial"])
+				assert.Equal(t, []interface{}{
+					map[string]interface{}{"index": float64(0), "field": "level", "reason": "invalid level: verbose"},
+				}, data["errors"])
+			}
+			if tt.expectedStatus == 200 && tt.expectSuccess {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, data["status"] == models.LogSubmissionPartial, data["partial"])
+			}
+
 			mockService.AssertExpectations(t)
 		})
 	}
@@ -265,6 +428,38 @@ func TestLoggingHandler_AnalyzeLogs(t *testing.T) {
 			expectedStatus: 200,
 			expectSuccess:  true,
 		},
+		{
+			name:        "Log analysis grouped by component",
+			queryParams: "?group_by=component",
+			setupMock: func() {
+				mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+					return req.GroupBy == models.LogGroupByComponent
+				})).Return(
+					&models.LogAnalysisResponse{
+						Summary:     "Grouped analysis complete",
+						Issues:      []models.LogIssue{},
+						Patterns:    []models.LogPattern{},
+						Suggestions: []string{},
+						Statistics: models.LogStatistics{
+							TotalLogs: 2,
+						},
+						GroupBy: models.LogGroupByComponent,
+						Groups: []models.LogGroupAnalysis{
+							{Group: "auth", TotalLogs: 2, ErrorCount: 1, ErrorRate: 50.0},
+						},
+						AnalyzedAt: time.Now(),
+					}, nil)
+			},
+			expectedStatus: 200,
+			expectSuccess:  true,
+		},
+		{
+			name:           "Log analysis with unsupported grouping",
+			queryParams:    "?group_by=level",
+			setupMock:      func() {},
+			expectedStatus: 400,
+			expectSuccess:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -307,10 +502,464 @@ func TestLoggingHandler_AnalyzeLogs(t *testing.T) {
 	}
 }
 
+func TestLoggingHandler_AnalyzeLogs_EnrichmentFilters(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return req.Filters["geo_country"] == "DE" && req.Filters["asn"] == "3320"
+	})).Return(&models.LogAnalysisResponse{AnalyzedAt: time.Now()}, nil)
+
+	req := httptest.NewRequest("GET", "/api/logs/analyze?geo_country=DE&asn=3320", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestLoggingHandler_AnalyzeLogs_FailOn(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+	mockService.On("AnalyzeLogs", mock.Anything, mock.Anything).Return(&models.LogAnalysisResponse{
+		Issues:        []models.LogIssue{{Type: "error_spike", Count: 12, Severity: "high"}},
+		WorstSeverity: "high",
+		AnalyzedAt:    time.Now(),
+	}, nil)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "without fail_on", query: "", expectedStatus: 200},
+		{name: "below the threshold", query: "?fail_on=critical", expectedStatus: 200},
+		{name: "at the threshold", query: "?fail_on=high", expectedStatus: 503, expectedError: "LOG_SEVERITY_THRESHOLD_EXCEEDED"},
+		{name: "above the threshold", query: "?fail_on=low", expectedStatus: 503, expectedError: "LOG_SEVERITY_THRESHOLD_EXCEEDED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, "high", resp.Header.Get("X-Log-Worst-Severity"))
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, "high", response["data"].(map[string]interface{})["worst_severity"])
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"].(map[string]interface{})["code"])
+			}
+		})
+	}
+
+	t.Run("unknown severity", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?fail_on=urgent", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockService.AssertNumberOfCalls(t, "AnalyzeLogs", len(tests))
+	})
+}
+
+func TestLoggingHandler_AnalyzeLogs_Pagination(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return req.Offset == 50 && req.Limit == 50
+	})).Return(&models.LogAnalysisResponse{TotalMatched: 4213, ReturnedCount: 50, AnalyzedAt: time.Now()}, nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?offset=50&limit=50", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(4213), data["total_matched"])
+	assert.Equal(t, float64(50), data["returned_count"])
+	mockService.AssertExpectations(t)
+
+	t.Run("negative offset is rejected", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?offset=-1", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestLoggingHandler_AnalyzeLogs_SpikeSettings(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return req.SpikeThreshold == 10 && req.SpikeWindowMinutes == 2
+	})).Return(&models.LogAnalysisResponse{AnalyzedAt: time.Now()}, nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?spike_threshold=10&spike_window_minutes=2", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+
+	t.Run("negative values are rejected", func(t *testing.T) {
+		for _, query := range []string{"spike_threshold=-1", "spike_window_minutes=-5"} {
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?"+query, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode, query)
+		}
+	})
+}
+
+func TestLoggingHandler_AnalyzeLogs_QueryFilters(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return req.TimeRange.Start.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) &&
+			req.TimeRange.End.Equal(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)) &&
+			assert.ObjectsAreEqual([]string{"auth", "payments"}, req.Components) &&
+			req.SearchQuery == "timeout.*database" && req.SearchMode == models.LogSearchRegex &&
+			assert.ObjectsAreEqual(map[string]string{"region": "eu", "user_id": "u1"}, req.Filters) &&
+			req.Limit == 20 && req.Offset == 40
+	})).Return(&models.LogAnalysisResponse{AnalyzedAt: time.Now()}, nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?start_time=2024-01-15T10:00:00Z&end_time=2024-01-15T12:00:00Z"+
+		"&components=auth,payments&search_query=timeout.*database&search_mode=regex&filter=region:eu&user_id=u1&limit=20&offset=40", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+
+	t.Run("invalid parameters are rejected", func(t *testing.T) {
+		for _, query := range []string{"start_time=yesterday", "limit=ten", "filter=region", "limit=5000", "search_mode=glob"} {
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?"+query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode, query)
+		}
+	})
+}
+
+func TestLoggingHandler_AnalyzeLogs_BodyAndQuery(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	// Query parameters win field by field; body fields without a query parameter are kept
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return assert.ObjectsAreEqual([]string{"error"}, req.Levels) &&
+			assert.ObjectsAreEqual([]string{"frontend"}, req.Sources) &&
+			req.SearchQuery == "checkout" &&
+			assert.ObjectsAreEqual(map[string]string{"user_id": "u2", "region": "eu"}, req.Filters) &&
+			req.Limit == 100
+	})).Return(&models.LogAnalysisResponse{AnalyzedAt: time.Now()}, nil)
+
+	body := `{"levels":["warn"],"sources":["frontend"],"search_query":"checkout","filters":{"user_id":"u1","region":"eu"},"limit":100}`
+	req := httptest.NewRequest("GET", "/api/logs/analyze?levels=error&filter=user_id:u2", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+
+	t.Run("malformed body", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/logs/analyze", bytes.NewBufferString(`{"levels":`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestLoggingHandler_ExportLogs(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	entries := []models.LogEntry{
+		{ID: "log-2", Timestamp: timestamp, Level: "error", Source: "backend", Component: "api", Message: "Timeout, retrying", Context: map[string]interface{}{"attempt": 2}},
+		{ID: "log-1", Timestamp: timestamp.Add(-time.Minute), Level: "info", Source: "frontend", Message: "=HYPERLINK(\"http://evil\")"},
+	}
+	export := services.LogExportFunc(func(fn func(models.LogEntry) error) error {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("ExportLogs", mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+			return assert.ObjectsAreEqual([]string{"error", "info"}, req.Levels) &&
+				req.SearchQuery == "component:api" && req.Filters["user_id"] == "u1" && req.Limit == 0
+		})).Return(export, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?format=csv&levels=error,info&search=component:api&user_id=u1", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+		assert.Regexp(t, `^attachment; filename="logs-\d{8}T\d{6}Z\.csv"$`, resp.Header.Get(fiber.HeaderContentDisposition))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "id,timestamp,level,source,component,function,line_number,message,stack_trace,user_id,session_id,fingerprint,context\n"+
+			"log-2,2024-01-15T10:30:00Z,error,backend,api,,,\"Timeout, retrying\",,,,,\"{\"\"attempt\"\":2}\"\n"+
+			"log-1,2024-01-15T10:29:00Z,info,frontend,,,,\"'=HYPERLINK(\"\"http://evil\"\")\",,,,,\n", string(body))
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("ExportLogs", mock.Anything).Return(export, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?format=ndjson", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get(fiber.HeaderContentType))
+		assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), ".ndjson")
+
+		decoder := json.NewDecoder(resp.Body)
+		var decoded []models.LogEntry
+		for decoder.More() {
+			var entry models.LogEntry
+			require.NoError(t, decoder.Decode(&entry))
+			decoded = append(decoded, entry)
+		}
+		require.Len(t, decoded, 2)
+		assert.Equal(t, "log-2", decoded[0].ID)
+		assert.Equal(t, "log-1", decoded[1].ID)
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("ExportLogs", mock.Anything).Return(export, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?format=ndjson&compress=gzip", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get(fiber.HeaderContentType))
+		assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), ".ndjson")
+
+		reader, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"id":"log-2"`)
+		assert.Contains(t, lines[1], `"id":"log-1"`)
+	})
+
+	t.Run("unknown compression", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?compress=brotli", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockService.AssertNotCalled(t, "ExportLogs", mock.Anything)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?format=xlsx", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "VALIDATION_ERROR", response["error"].(map[string]interface{})["code"])
+		mockService.AssertNotCalled(t, "ExportLogs", mock.Anything)
+	})
+
+	t.Run("invalid search query", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("ExportLogs", mock.Anything).Return(nil, fmt.Errorf("%w: unterminated quote", services.ErrInvalidSearchQuery))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/export?format=csv&search=%22oops", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestLoggingHandler_CreateAnalysisReport(t *testing.T) {
+	t.Run("creates report from query filters", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		report := &models.LogAnalysisReport{
+			ID:        "report-1",
+			CreatedAt: time.Now(),
+			Analysis:  models.LogAnalysisResponse{Summary: "2 errors found"},
+		}
+		mockService.On("CreateAnalysisReport", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+			return len(req.Levels) == 1 && req.Levels[0] == "error"
+		})).Return(report, nil)
+
+		req := httptest.NewRequest("POST", "/api/logs/reports?levels=error", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "report-1", data["id"])
+		analysis := data["analysis"].(map[string]interface{})
+		assert.Equal(t, "2 errors found", analysis["summary"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid group_by is rejected", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		req := httptest.NewRequest("POST", "/api/logs/reports?group_by=user", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockService.AssertNotCalled(t, "CreateAnalysisReport", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLoggingHandler_TimeRangeTooWide(t *testing.T) {
+	rangeErr := fmt.Errorf("%w: requested range spans 720h0m0s but at most 168h0m0s may be analyzed at once", services.ErrAnalysisRangeTooWide)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		setupMock func(m *MockLogService)
+	}{
+		{
+			name:   "analyze",
+			method: "GET",
+			path:   "/api/logs/analyze?start_time=2024-01-01T00:00:00Z&end_time=2024-01-31T00:00:00Z",
+			setupMock: func(m *MockLogService) {
+				m.On("AnalyzeLogs", mock.Anything, mock.Anything).Return((*models.LogAnalysisResponse)(nil), rangeErr)
+			},
+		},
+		{
+			name:   "create report",
+			method: "POST",
+			path:   "/api/logs/reports?start_time=2024-01-01T00:00:00Z&end_time=2024-01-31T00:00:00Z",
+			setupMock: func(m *MockLogService) {
+				m.On("CreateAnalysisReport", mock.Anything, mock.Anything).Return(nil, rangeErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockService := setupLoggingTestApp()
+			tt.setupMock(mockService)
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, 400, resp.StatusCode)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			errorData := response["error"].(map[string]interface{})
+			assert.Equal(t, "TIME_RANGE_TOO_WIDE", errorData["code"])
+			details := errorData["details"].(map[string]interface{})
+			assert.Contains(t, details["time_range"], "168h0m0s")
+		})
+	}
+}
+
+func TestLoggingHandler_InvalidSearchQuery(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+	queryErr := fmt.Errorf("%w: unterminated quote", services.ErrInvalidSearchQuery)
+	mockService.On("AnalyzeLogs", mock.Anything, mock.MatchedBy(func(req *models.LogAnalysisRequest) bool {
+		return req.SearchQuery == `component:auth AND "connection`
+	})).Return((*models.LogAnalysisResponse)(nil), queryErr)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/analyze?search=component%3Aauth+AND+%22connection", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	errorData := response["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errorData["code"])
+	details := errorData["details"].(map[string]interface{})
+	assert.Contains(t, details["search"], "unterminated quote")
+}
+
+func TestLoggingHandler_GetAnalysisReport(t *testing.T) {
+	report := &models.LogAnalysisReport{
+		ID:        "report-1",
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Analysis: models.LogAnalysisResponse{
+			Summary:     "Payment errors <spiking>",
+			Suggestions: []string{"Check the payment gateway"},
+			Issues: []models.LogIssue{
+				{Type: "error_spike", Count: 12, Severity: "high", Description: "High error rate detected"},
+			},
+			Statistics: models.LogStatistics{TotalLogs: 40, ErrorRate: 30},
+		},
+	}
+
+	t.Run("json report", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("GetAnalysisReport", "report-1").Return(report, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/reports/report-1", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "report-1", data["id"])
+	})
+
+	t.Run("html report", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("GetAnalysisReport", "report-1").Return(report, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/reports/report-1?format=html", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		html := string(body)
+		assert.Contains(t, html, "Report report-1")
+		assert.Contains(t, html, "Payment errors &lt;spiking&gt;")
+		assert.Contains(t, html, "High error rate detected")
+		assert.Contains(t, html, "Check the payment gateway")
+		assert.Contains(t, html, "30.00%")
+	})
+
+	t.Run("unknown report", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("GetAnalysisReport", "missing").Return(nil, errors.New("analysis report not found: missing"))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/reports/missing", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/reports/report-1?format=pdf", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockService.AssertNotCalled(t, "GetAnalysisReport", mock.Anything)
+	})
+}
+
 func TestLoggingHandler_GetLogStats(t *testing.T) {
 	app, mockService := setupLoggingTestApp()
 
 	mockService.On("GetLogCount").Return(42)
+	mockService.On("GetStoreWriteStats").Return(models.LogStoreWriteStats{Writes: 5, Attempts: 7, FailedAttempts: 2})
+	mockService.On("GetClockSkewStats").Return(models.LogClockSkewStats{Tolerance: "24h0m0s", Clamped: 3})
 
 	req := httptest.NewRequest("GET", "/api/logs/stats", nil)
 	resp, err := app.Test(req)
@@ -325,15 +974,68 @@ func TestLoggingHandler_GetLogStats(t *testing.T) {
 	assert.Equal(t, true, response["success"])
 	data := response["data"].(map[string]interface{})
 	assert.Equal(t, float64(42), data["total_logs"])
+	storeWrites := data["store_writes"].(map[string]interface{})
+	assert.Equal(t, float64(7), storeWrites["attempts"])
+	assert.Equal(t, float64(2), storeWrites["failed_attempts"])
+	clockSkew := data["clock_skew"].(map[string]interface{})
+	assert.Equal(t, float64(3), clockSkew["clamped"])
 	assert.Contains(t, data, "timestamp")
 
 	mockService.AssertExpectations(t)
 }
 
+func TestLoggingHandler_GetFingerprints(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	fingerprints := []models.LogFingerprint{
+		{Fingerprint: "a1b2c3d4e5f60718", Message: "timeout after [NUMBER]ms", Level: "error", Count: 3, FirstSeen: now.Add(-time.Hour), LastSeen: now},
+		{Fingerprint: "0f1e2d3c4b5a6978", Message: "user [NUMBER] not found", Level: "error", Count: 1, FirstSeen: now, LastSeen: now},
+	}
+
+	t.Run("lists fingerprints", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("GetFingerprints").Return(fingerprints, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/fingerprints?limit=1", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response struct {
+			Data models.LogFingerprintsResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, 2, response.Data.Total)
+		require.Len(t, response.Data.Fingerprints, 1)
+		assert.Equal(t, "a1b2c3d4e5f60718", response.Data.Fingerprints[0].Fingerprint)
+		assert.Equal(t, 3, response.Data.Fingerprints[0].Count)
+		assert.True(t, response.Data.Fingerprints[0].FirstSeen.Equal(now.Add(-time.Hour)))
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/fingerprints?limit=0", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+
+		mockService.AssertNotCalled(t, "GetFingerprints")
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		app, mockService := setupLoggingTestApp()
+		mockService.On("GetFingerprints").Return(nil, errors.New("database is locked"))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/logs/fingerprints", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 500, resp.StatusCode)
+	})
+}
+
 func TestLoggingHandler_ClearLogs(t *testing.T) {
 	app, mockService := setupLoggingTestApp()
 
-	mockService.On("ClearLogs").Return()
+	mockService.On("ClearLogs").Return(nil)
 
 	req := httptest.NewRequest("DELETE", "/api/logs/clear", nil)
 	resp, err := app.Test(req)
@@ -352,10 +1054,309 @@ func TestLoggingHandler_ClearLogs(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestLoggingHandler_ClearLogs_StoreError(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("ClearLogs").Return(errors.New("database is locked"))
+
+	req := httptest.NewRequest("DELETE", "/api/logs/clear", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLoggingHandler_SubmitLogs_IngestionPaused(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	pausedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	mockService.On("SubmitLogs", mock.Anything, mock.Anything).Return((*models.LogSubmissionResponse)(nil), services.ErrIngestionPaused)
+	mockService.On("GetIngestionState").Return(models.LogIngestionState{
+		Paused: true, Reason: "store maintenance", PausedAt: &pausedAt, RetryAfterSeconds: 120,
+	})
+
+	body := `{"source":"frontend","logs":[{"id":"1","timestamp":"2024-01-15T10:31:00Z","level":"info","source":"frontend","message":"hi"}]}`
+	req := httptest.NewRequest("POST", "/api/logs/submit", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, "120", resp.Header.Get("Retry-After"))
+
+	var response utils.StandardResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, "LOG_INGESTION_PAUSED", response.Error.Code)
+	assert.Equal(t, map[string]string{"reason": "store maintenance", "paused_at": "2024-01-15T10:30:00Z"}, response.Error.Details)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLoggingHandler_PauseIngestion(t *testing.T) {
+	pausedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	paused := models.LogIngestionState{Paused: true, Reason: "store maintenance", PausedAt: &pausedAt}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockLogService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "reason and retry hint",
+			requestBody: `{"reason":"store maintenance","retry_after_seconds":120}`,
+			setupMock: func(m *MockLogService) {
+				m.On("PauseIngestion", "store maintenance", 120*time.Second).Return(paused)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:        "empty body",
+			requestBody: "",
+			setupMock: func(m *MockLogService) {
+				m.On("PauseIngestion", "", time.Duration(0)).Return(paused)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:           "negative retry hint",
+			requestBody:    `{"retry_after_seconds":-1}`,
+			setupMock:      func(m *MockLogService) {},
+			expectedStatus: 400,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "malformed body",
+			requestBody:    `{"reason":`,
+			setupMock:      func(m *MockLogService) {},
+			expectedStatus: 400,
+			expectedCode:   "INVALID_REQUEST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockService := setupLoggingTestApp()
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest("POST", "/api/logs/pause", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			} else {
+				assert.Equal(t, true, response["data"].(map[string]interface{})["paused"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoggingHandler_ResumeIngestion(t *testing.T) {
+	app, mockService := setupLoggingTestApp()
+
+	mockService.On("ResumeIngestion").Return(models.LogIngestionState{})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/logs/resume", nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, map[string]interface{}{"paused": false}, response["data"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLoggingHandler_UpdateAlertRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockLogService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "add rule",
+			requestBody: `{"action":"add","keyword":"OOMKilled","min_level":"warn"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("AddAlertRule", models.LogAlertRule{Keyword: "OOMKilled", MinLevel: "warn"}).Return(nil)
+				m.On("GetAlertRules").Return([]models.LogAlertRule{{Keyword: "oomkilled", MinLevel: "warn"}})
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:        "remove rule",
+			requestBody: `{"action":"remove","keyword":"panic"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("RemoveAlertRule", "panic").Return(nil)
+				m.On("GetAlertRules").Return([]models.LogAlertRule{})
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:        "remove unknown rule",
+			requestBody: `{"action":"remove","keyword":"nope"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("RemoveAlertRule", "nope").Return(fmt.Errorf("%w: %q", services.ErrAlertRuleNotFound, "nope"))
+			},
+			expectedStatus: 404,
+			expectedCode:   "ALERT_RULE_NOT_FOUND",
+		},
+		{
+			name:        "unknown level",
+			requestBody: `{"action":"add","keyword":"timeout","min_level":"loud"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("AddAlertRule", models.LogAlertRule{Keyword: "timeout", MinLevel: "loud"}).
+					Return(fmt.Errorf("%w: unknown level %q", services.ErrInvalidAlertRule, "loud"))
+			},
+			expectedStatus: 400,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "invalid action",
+			requestBody:    `{"action":"toggle","keyword":"panic"}`,
+			setupMock:      func(m *MockLogService) {},
+			expectedStatus: 400,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+		{
+			name:           "missing keyword",
+			requestBody:    `{"action":"add"}`,
+			setupMock:      func(m *MockLogService) {},
+			expectedStatus: 400,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockService := setupLoggingTestApp()
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest("POST", "/api/logs/alert-rules", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			} else {
+				assert.Contains(t, response["data"], "rules")
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoggingHandler_AcknowledgeAlert(t *testing.T) {
+	acknowledgedAt := time.Now()
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockLogService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "acknowledged by someone",
+			requestBody: `{"acknowledged_by":"alice"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("AcknowledgeAlert", "alert-1", "alice").Return(&models.CriticalLogAlert{
+					ID: "alert-1", LogID: "log-1", AcknowledgedAt: &acknowledgedAt, AcknowledgedBy: "alice",
+				}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:        "empty body",
+			requestBody: ``,
+			setupMock: func(m *MockLogService) {
+				m.On("AcknowledgeAlert", "alert-1", "").Return(&models.CriticalLogAlert{
+					ID: "alert-1", LogID: "log-1", AcknowledgedAt: &acknowledgedAt,
+				}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:        "unknown alert",
+			requestBody: `{}`,
+			setupMock: func(m *MockLogService) {
+				m.On("AcknowledgeAlert", "alert-1", "").Return(nil, fmt.Errorf("%w: alert-1", services.ErrAlertNotFound))
+			},
+			expectedStatus: 404,
+			expectedCode:   "ALERT_NOT_FOUND",
+		},
+		{
+			name:        "already acknowledged",
+			requestBody: `{"acknowledged_by":"bob"}`,
+			setupMock: func(m *MockLogService) {
+				m.On("AcknowledgeAlert", "alert-1", "bob").Return(nil, fmt.Errorf("%w: alert-1", services.ErrAlertAlreadyAcknowledged))
+			},
+			expectedStatus: 409,
+			expectedCode:   "ALERT_ALREADY_ACKNOWLEDGED",
+		},
+		{
+			name:           "acknowledged_by too long",
+			requestBody:    `{"acknowledged_by":"` + strings.Repeat("a", 101) + `"}`,
+			setupMock:      func(m *MockLogService) {},
+			expectedStatus: 400,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockService := setupLoggingTestApp()
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest("POST", "/api/logs/alerts/alert-1/ack", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			} else {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "alert-1", data["id"])
+				assert.NotEmpty(t, data["acknowledged_at"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestLoggingHandler_GetLoggingStatus(t *testing.T) {
 	app, mockService := setupLoggingTestApp()
 
 	mockService.On("GetLogCount").Return(100)
+	mockService.On("GetLogLevels").Return([]string{"fatal", "error", "warn", "notice", "info"})
+	mockService.On("GetStoreWriteStats").Return(models.LogStoreWriteStats{})
+	mockService.On("GetClockSkewStats").Return(models.LogClockSkewStats{})
+	mockService.On("GetIngestionState").Return(models.LogIngestionState{Paused: true, Reason: "store maintenance"})
 
 	req := httptest.NewRequest("GET", "/api/logs/status", nil)
 	resp, err := app.Test(req)
@@ -372,6 +1373,9 @@ func TestLoggingHandler_GetLoggingStatus(t *testing.T) {
 	assert.Equal(t, "logging", data["service"])
 	assert.Equal(t, "healthy", data["status"])
 	assert.Equal(t, float64(100), data["total_logs"])
+	assert.Equal(t, []interface{}{"fatal", "error", "warn", "notice", "info"}, data["log_levels"])
+	assert.Contains(t, data, "store_writes")
+	assert.Equal(t, map[string]interface{}{"paused": true, "reason": "store maintenance"}, data["ingestion"])
 	assert.Contains(t, data, "timestamp")
 	assert.Contains(t, data, "version")
 
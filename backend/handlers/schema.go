@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// schemaContentType is the media type of JSON Schema documents
+const schemaContentType = "application/schema+json"
+
+// SchemaHandler serves JSON Schemas of the request models, generated from their json and validate
+// tags so they always match what the handlers accept
+type SchemaHandler struct {
+	schemas map[string][]byte // Encoded schema by lower-case model name
+	names   []string          // Served model names, sorted
+}
+
+// NewSchemaHandler creates a new schema handler serving the named request models, or every model in
+// models.RequestModels if none are named. Unknown names are ignored; config validation reports them.
+func NewSchemaHandler(modelNames []string) *SchemaHandler {
+	requestModels := models.RequestModels()
+	if len(modelNames) == 0 {
+		for name := range requestModels {
+			modelNames = append(modelNames, name)
+		}
+	}
+
+	h := &SchemaHandler{schemas: make(map[string][]byte)}
+	for _, name := range modelNames {
+		model, exists := requestModels[name]
+		if !exists {
+			continue
+		}
+		if _, duplicate := h.schemas[strings.ToLower(name)]; duplicate {
+			continue
+		}
+
+		encoded, err := json.Marshal(utils.GenerateJSONSchema(name, model))
+		if err != nil {
+			continue
+		}
+		h.schemas[strings.ToLower(name)] = encoded
+		h.names = append(h.names, name)
+	}
+	sort.Strings(h.names)
+
+	return h
+}
+
+// ListSchemas handles GET /api/schema, listing the models with a schema
+func (h *SchemaHandler) ListSchemas(c *fiber.Ctx) error {
+	schemas := make([]fiber.Map, 0, len(h.names))
+	for _, name := range h.names {
+		schemas = append(schemas, fiber.Map{
+			"model": name,
+			"url":   "/api/schema/" + name,
+		})
+	}
+
+	return utils.SuccessResponse(c, "Request model schemas retrieved", fiber.Map{
+		"schemas": schemas,
+		"total":   len(schemas),
+	})
+}
+
+// GetSchema handles GET /api/schema/:model, returning the model's JSON Schema document. Model names
+// match case-insensitively.
+func (h *SchemaHandler) GetSchema(c *fiber.Ctx) error {
+	model := c.Params("model")
+	schema, exists := h.schemas[strings.ToLower(model)]
+	if !exists {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "SCHEMA_NOT_FOUND",
+			"No schema for request model "+model, map[string]string{
+				"model":     model,
+				"available": strings.Join(h.names, ", "),
+			})
+	}
+
+	c.Set(fiber.HeaderContentType, schemaContentType)
+	return c.Send(schema)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSchemaTestApp(modelNames []string) *fiber.App {
+	handler := NewSchemaHandler(modelNames)
+	app := fiber.New()
+	app.Get("/api/schema", handler.ListSchemas)
+	app.Get("/api/schema/:model", handler.GetSchema)
+	return app
+}
+
+func TestSchemaHandler_GetSchema(t *testing.T) {
+	app := setupSchemaTestApp(nil)
+
+	get := func(model string) (*http.Response, []byte) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/schema/"+model, nil), -1)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	t.Run("log submission request", func(t *testing.T) {
+		resp, body := get("LogSubmissionRequest")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, schemaContentType, resp.Header.Get(fiber.HeaderContentType))
+
+		var schema utils.JSONSchema
+		require.NoError(t, json.Unmarshal(body, &schema))
+		assert.Equal(t, utils.JSONSchemaDraft, schema.Schema)
+		assert.Equal(t, "LogSubmissionRequest", schema.Title)
+		assert.ElementsMatch(t, []string{"logs", "source"}, schema.Required)
+		assert.Equal(t, []string{"frontend", "backend"}, schema.Properties["source"].Enum)
+
+		entry := schema.Properties["logs"].Items
+		require.NotNil(t, entry)
+		assert.ElementsMatch(t, []string{"id", "timestamp", "level", "source", "message"}, entry.Required)
+		assert.Equal(t, "date-time", entry.Properties["timestamp"].Format)
+		assert.Equal(t, []string{"error", "warn", "info", "debug", "trace"}, entry.Properties["level"].Enum)
+	})
+
+	t.Run("configured log levels", func(t *testing.T) {
+		require.NoError(t, utils.RegisterEnum("loglevel", []string{"fatal", "error", "notice"}))
+		t.Cleanup(func() {
+			require.NoError(t, utils.RegisterEnum("loglevel", []string{"error", "warn", "info", "debug", "trace"}))
+		})
+
+		resp, err := setupSchemaTestApp([]string{"LogSubmissionRequest"}).Test(
+			httptest.NewRequest(http.MethodGet, "/api/schema/LogSubmissionRequest", nil), -1)
+		require.NoError(t, err)
+		var schema utils.JSONSchema
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+		assert.Equal(t, []string{"fatal", "error", "notice"}, schema.Properties["logs"].Items.Properties["level"].Enum)
+	})
+
+	t.Run("test run request", func(t *testing.T) {
+		resp, body := get("TestRunRequest")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var schema utils.JSONSchema
+		require.NoError(t, json.Unmarshal(body, &schema))
+		assert.ElementsMatch(t, []string{"framework", "test_suite", "environment"}, schema.Required)
+		timeout := schema.Properties["timeout_seconds"]
+		require.NotNil(t, timeout)
+		assert.Equal(t, float64(86400), *timeout.Maximum)
+	})
+
+	t.Run("model names match case-insensitively", func(t *testing.T) {
+		resp, body := get("testrunrequest")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), `"title":"TestRunRequest"`)
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		resp, body := get("Nope")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, "SCHEMA_NOT_FOUND", response.Error.Code)
+		assert.Contains(t, response.Error.Details["available"], "LogSubmissionRequest")
+	})
+}
+
+func TestSchemaHandler_ListSchemas(t *testing.T) {
+	list := func(app *fiber.App) []string {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/schema", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		data := response.Data.(map[string]interface{})
+
+		var names []string
+		for _, schema := range data["schemas"].([]interface{}) {
+			entry := schema.(map[string]interface{})
+			assert.Equal(t, "/api/schema/"+entry["model"].(string), entry["url"])
+			names = append(names, entry["model"].(string))
+		}
+		assert.Equal(t, float64(len(names)), data["total"])
+		return names
+	}
+
+	t.Run("every request model by default", func(t *testing.T) {
+		names := list(setupSchemaTestApp(nil))
+		assert.Len(t, names, len(models.RequestModels()))
+		assert.IsIncreasing(t, names)
+	})
+
+	t.Run("configured models only", func(t *testing.T) {
+		app := setupSchemaTestApp([]string{"TestRunRequest", "LogSubmissionRequest", "Unknown"})
+		assert.Equal(t, []string{"LogSubmissionRequest", "TestRunRequest"}, list(app))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/schema/AIRequest", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
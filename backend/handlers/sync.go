@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +19,9 @@ type SyncServiceInterface interface {
 	ValidateEndpoint(req *models.SyncValidationRequest) (*models.SyncValidationResponse, error)
 	GetEnvironments() map[string]*models.SyncEnvironment
 	RemoveEnvironment(environmentName string) error
+	CheckEnvironment(environmentName string) (*models.SyncEnvironmentCheckResponse, error)
+	StoreContract(contract models.EndpointContract) (*models.EndpointContract, error)
+	ValidateContract(req *models.ContractValidationRequest) (*models.SyncValidationResponse, error)
 }
 
 // SyncHandler handles environment synchronization requests
@@ -70,6 +76,11 @@ func (h *SyncHandler) ConnectEnvironment(c *fiber.Ctx) error {
 			"frontend_url": req.FrontendURL,
 			"backend_url":  req.BackendURL,
 		})
+		if errors.Is(err, services.ErrEnvironmentLimitReached) {
+			return utils.ErrorResponse(c, fiber.StatusConflict, "ENVIRONMENT_LIMIT_REACHED", "Too many sync environments connected", map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "CONNECTION_FAILED", "Failed to connect to environment", map[string]string{
 			"error": err.Error(),
 		})
@@ -152,6 +163,9 @@ func (h *SyncHandler) ValidateEndpoint(c *fiber.Ctx) error {
 
 	// Validate endpoint compatibility
 	response, err := h.syncService.ValidateEndpoint(&req)
+	if errors.Is(err, services.ErrValidationLimitReached) {
+		return validationLimitResponse(c, err)
+	}
 	if err != nil {
 		h.logger.WithTraceID(traceID).Error("Failed to validate endpoint", err, map[string]interface{}{
 			"frontend_endpoint": req.FrontendEndpoint,
@@ -185,6 +199,98 @@ func (h *SyncHandler) ValidateEndpoint(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Endpoint validation completed", response)
 }
 
+// StoreContract handles POST /api/sync/contracts requests
+func (h *SyncHandler) StoreContract(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var req models.EndpointContract
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to parse contract request", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST_BODY", "Invalid request body format", map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"validation_error": err.Error(),
+		})
+	}
+
+	contract, err := h.syncService.StoreContract(req)
+	if errors.Is(err, services.ErrInvalidContract) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"validation_error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to store contract", err, map[string]interface{}{
+			"contract": req.Name,
+		})
+		return utils.InternalServerErrorResponse(c, "Failed to store contract")
+	}
+
+	return utils.SuccessResponse(c, "Contract stored successfully", contract)
+}
+
+// ValidateContract handles POST /api/sync/validate-contract requests
+func (h *SyncHandler) ValidateContract(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+
+	var req models.ContractValidationRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to parse contract validation request", err, nil)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST_BODY", "Invalid request body format", map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", map[string]string{
+			"validation_error": err.Error(),
+		})
+	}
+
+	response, err := h.syncService.ValidateContract(&req)
+	if errors.Is(err, services.ErrContractNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "CONTRACT_NOT_FOUND", "Contract not found", map[string]string{
+			"contract": req.Contract,
+		})
+	}
+	if errors.Is(err, services.ErrValidationLimitReached) {
+		return validationLimitResponse(c, err)
+	}
+	if err != nil {
+		h.logger.WithTraceID(traceID).Error("Failed to validate contract", err, map[string]interface{}{
+			"contract": req.Contract,
+			"endpoint": req.Endpoint,
+		})
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "VALIDATION_FAILED", "Failed to validate contract", map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if !response.IsCompatible {
+		websocket.BroadcastSyncUpdate(map[string]interface{}{
+			"action":        "contract_violation_detected",
+			"contract":      req.Contract,
+			"endpoint":      req.Endpoint,
+			"issues_count":  len(response.Issues),
+			"is_compatible": response.IsCompatible,
+			"timestamp":     response.ValidatedAt,
+		})
+	}
+
+	h.logger.WithTraceID(traceID).Info("Contract validation completed", map[string]interface{}{
+		"contract":      req.Contract,
+		"endpoint":      req.Endpoint,
+		"is_compatible": response.IsCompatible,
+		"issues_count":  len(response.Issues),
+	})
+
+	return utils.SuccessResponse(c, "Contract validation completed", response)
+}
+
 // GetEnvironments handles GET /api/sync/environments requests
 func (h *SyncHandler) GetEnvironments(c *fiber.Ctx) error {
 	traceID := utils.GetTraceID(c)
@@ -254,3 +360,60 @@ func (h *SyncHandler) RemoveEnvironment(c *fiber.Ctx) error {
 		"removed":     true,
 	})
 }
+
+// CheckEnvironment handles POST /api/sync/environments/:name/check requests, re-checking one
+// environment's health without waiting for the health monitor
+func (h *SyncHandler) CheckEnvironment(c *fiber.Ctx) error {
+	traceID := utils.GetTraceID(c)
+	environmentName := strings.TrimSpace(c.Params("name"))
+
+	h.logger.WithTraceID(traceID).Info("Received environment health check request", map[string]interface{}{
+		"method":      c.Method(),
+		"path":        c.Path(),
+		"environment": environmentName,
+		"ip":          c.IP(),
+	})
+
+	if environmentName == "" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "MISSING_PARAMETER", "Environment name is required", nil)
+	}
+
+	response, err := h.syncService.CheckEnvironment(environmentName)
+	if err != nil {
+		var cooldownErr *services.HealthCheckCooldownError
+		switch {
+		case errors.Is(err, services.ErrEnvironmentNotFound):
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "ENVIRONMENT_NOT_FOUND", "Environment not found", map[string]string{
+				"environment": environmentName,
+			})
+		case errors.As(err, &cooldownErr):
+			retryAfter := strconv.Itoa(cooldownErr.RetryAfterSeconds())
+			c.Set(fiber.HeaderRetryAfter, retryAfter)
+			return utils.ErrorResponse(c, fiber.StatusTooManyRequests, "HEALTH_CHECK_TOO_SOON", "Environment was checked too recently", map[string]string{
+				"environment": environmentName,
+				"retry_after": retryAfter,
+			})
+		}
+
+		h.logger.WithTraceID(traceID).Error("Failed to check environment health", err, map[string]interface{}{
+			"environment": environmentName,
+		})
+		return utils.InternalServerErrorResponse(c, "Failed to check environment health")
+	}
+
+	h.logger.WithTraceID(traceID).Info("Environment health checked", map[string]interface{}{
+		"environment":     environmentName,
+		"status":          response.Environment.Status,
+		"previous_status": response.PreviousStatus,
+	})
+
+	return utils.SuccessResponse(c, "Environment health checked", response)
+}
+
+// validationLimitResponse rejects a validation because its target environment is at its concurrency cap
+func validationLimitResponse(c *fiber.Ctx, err error) error {
+	return utils.ErrorResponse(c, fiber.StatusTooManyRequests, "VALIDATION_LIMIT_REACHED",
+		"Too many concurrent validations for the target environment, please retry later", map[string]string{
+			"error": err.Error(),
+		})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -56,6 +57,30 @@ func (m *MockSyncService) RemoveEnvironment(environmentName string) error {
 	return args.Error(0)
 }
 
+func (m *MockSyncService) CheckEnvironment(environmentName string) (*models.SyncEnvironmentCheckResponse, error) {
+	args := m.Called(environmentName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SyncEnvironmentCheckResponse), args.Error(1)
+}
+
+func (m *MockSyncService) StoreContract(contract models.EndpointContract) (*models.EndpointContract, error) {
+	args := m.Called(contract)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EndpointContract), args.Error(1)
+}
+
+func (m *MockSyncService) ValidateContract(req *models.ContractValidationRequest) (*models.SyncValidationResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SyncValidationResponse), args.Error(1)
+}
+
 func setupTestApp() *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -131,6 +156,18 @@ func TestSyncHandler_ConnectEnvironment(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  true,
 		},
+		{
+			name: "environment limit reached",
+			requestBody: models.SyncConnectionRequest{
+				Environment: "test",
+				FrontendURL: "http://frontend.test",
+				BackendURL:  "http://backend.test",
+			},
+			mockResponse:   nil,
+			mockError:      fmt.Errorf("%w: 2 of 2 environments connected", services.ErrEnvironmentLimitReached),
+			expectedStatus: http.StatusConflict,
+			expectedError:  true,
+		},
 		{
 			name: "validation error - missing environment",
 			requestBody: models.SyncConnectionRequest{
@@ -328,6 +365,18 @@ func TestSyncHandler_ValidateEndpoint(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  true,
 		},
+		{
+			name: "environment at its validation limit",
+			requestBody: models.SyncValidationRequest{
+				FrontendEndpoint: "http://frontend.test/api/test",
+				BackendEndpoint:  "http://backend.test/api/test",
+				Method:           "GET",
+			},
+			mockResponse:   nil,
+			mockError:      fmt.Errorf("%w staging (limit 2)", services.ErrValidationLimitReached),
+			expectedStatus: http.StatusTooManyRequests,
+			expectedError:  true,
+		},
 		{
 			name: "validation error - missing endpoint",
 			requestBody: models.SyncValidationRequest{
@@ -388,6 +437,169 @@ func TestSyncHandler_ValidateEndpoint(t *testing.T) {
 	}
 }
 
+func TestSyncHandler_StoreContract(t *testing.T) {
+	contract := models.EndpointContract{
+		Name:   "get-user",
+		Method: "GET",
+		Fields: map[string]string{"id": "number", "email": "string"},
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockError      error
+		callsService   bool
+		expectedStatus int
+	}{
+		{
+			name:           "stores contract",
+			requestBody:    contract,
+			callsService:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown field type",
+			requestBody:    contract,
+			mockError:      fmt.Errorf("%w: field \"id\" has unknown type \"int\"", services.ErrInvalidContract),
+			callsService:   true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing name",
+			requestBody:    models.EndpointContract{Method: "GET", Fields: contract.Fields},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid method",
+			requestBody:    models.EndpointContract{Name: "get-user", Method: "FETCH", Fields: contract.Fields},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := &MockSyncService{}
+			handler := NewSyncHandler(mockService)
+
+			if tt.callsService {
+				stored := contract
+				stored.UpdatedAt = time.Now()
+				if tt.mockError != nil {
+					mockService.On("StoreContract", mock.AnythingOfType("models.EndpointContract")).Return(nil, tt.mockError)
+				} else {
+					mockService.On("StoreContract", mock.AnythingOfType("models.EndpointContract")).Return(&stored, nil)
+				}
+			}
+
+			app.Post("/api/sync/contracts", handler.StoreContract)
+
+			requestBody, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest("POST", "/api/sync/contracts", bytes.NewReader(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSyncHandler_ValidateContract(t *testing.T) {
+	request := models.ContractValidationRequest{
+		Contract: "get-user",
+		Endpoint: "http://backend.test/api/users/1",
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockResponse   *models.SyncValidationResponse
+		mockError      error
+		callsService   bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "compatible",
+			requestBody: request,
+			mockResponse: &models.SyncValidationResponse{
+				IsCompatible: true,
+				ValidatedAt:  time.Now(),
+			},
+			callsService:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "contract violated",
+			requestBody: request,
+			mockResponse: &models.SyncValidationResponse{
+				IsCompatible: false,
+				Issues: []models.SyncCompatibilityIssue{
+					{Type: "schema_mismatch", Field: "id", Expected: "number", Actual: "string", Severity: "critical"},
+				},
+				ValidatedAt: time.Now(),
+			},
+			callsService:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown contract",
+			requestBody:    request,
+			mockError:      fmt.Errorf("%w: get-user", services.ErrContractNotFound),
+			callsService:   true,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "CONTRACT_NOT_FOUND",
+		},
+		{
+			name:           "environment at its validation limit",
+			requestBody:    request,
+			mockError:      fmt.Errorf("%w backend.test (limit 1)", services.ErrValidationLimitReached),
+			callsService:   true,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   "VALIDATION_LIMIT_REACHED",
+		},
+		{
+			name:           "missing endpoint",
+			requestBody:    models.ContractValidationRequest{Contract: "get-user"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := &MockSyncService{}
+			handler := NewSyncHandler(mockService)
+
+			if tt.callsService {
+				mockService.On("ValidateContract", mock.AnythingOfType("*models.ContractValidationRequest")).Return(tt.mockResponse, tt.mockError)
+			}
+
+			app.Post("/api/sync/validate-contract", handler.ValidateContract)
+
+			requestBody, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest("POST", "/api/sync/validate-contract", bytes.NewReader(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestSyncHandler_GetEnvironments(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -510,6 +722,68 @@ func TestSyncHandler_RemoveEnvironment(t *testing.T) {
 	}
 }
 
+func TestSyncHandler_CheckEnvironment(t *testing.T) {
+	checked := &models.SyncEnvironmentCheckResponse{
+		Environment:    models.SyncEnvironment{Name: "staging", Status: "active", LastChecked: time.Now()},
+		PreviousStatus: "error",
+		Health:         models.HealthStatus{Frontend: true, Backend: true, Database: true},
+	}
+
+	tests := []struct {
+		name               string
+		mockResponse       *models.SyncEnvironmentCheckResponse
+		mockError          error
+		expectedStatus     int
+		expectedCode       string
+		expectedRetryAfter string
+	}{
+		{name: "fresh status", mockResponse: checked, expectedStatus: http.StatusOK},
+		{
+			name:           "environment not found",
+			mockError:      fmt.Errorf("%w: staging", services.ErrEnvironmentNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "ENVIRONMENT_NOT_FOUND",
+		},
+		{
+			name:               "checked too recently",
+			mockError:          &services.HealthCheckCooldownError{Environment: "staging", RetryAfter: 2500 * time.Millisecond},
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedCode:       "HEALTH_CHECK_TOO_SOON",
+			expectedRetryAfter: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestApp()
+			mockService := &MockSyncService{}
+			handler := NewSyncHandler(mockService)
+			if tt.mockResponse != nil {
+				mockService.On("CheckEnvironment", "staging").Return(tt.mockResponse, nil)
+			} else {
+				mockService.On("CheckEnvironment", "staging").Return(nil, tt.mockError)
+			}
+			app.Post("/api/sync/environments/:name/check", handler.CheckEnvironment)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/api/sync/environments/staging/check", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedRetryAfter, resp.Header.Get(fiber.HeaderRetryAfter))
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, response["error"].(map[string]interface{})["code"])
+			} else {
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, "error", data["previous_status"])
+				assert.Equal(t, "active", data["environment"].(map[string]interface{})["status"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestSyncHandler_Integration(t *testing.T) {
 	// This test uses the real sync service to test the full integration
 	app := setupTestApp()
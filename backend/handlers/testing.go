@@ -1,31 +1,202 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/middleware"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
+// defaultSyncRunTimeout is how long RunTestsSync waits when no timeout is configured
+const defaultSyncRunTimeout = 5 * time.Minute
+
+// TestingHandlerConfig holds configuration for the testing handler
+type TestingHandlerConfig struct {
+	StrictValidation bool          // Reject unsupported frameworks before reaching the test service
+	SyncRunTimeout   time.Duration // Longest RunTestsSync waits for a run; 0 uses defaultSyncRunTimeout
+}
+
+// DefaultTestingHandlerConfig returns default testing handler configuration
+func DefaultTestingHandlerConfig() TestingHandlerConfig {
+	return TestingHandlerConfig{
+		StrictValidation: true,
+		SyncRunTimeout:   defaultSyncRunTimeout,
+	}
+}
+
 // TestingHandler handles E2E testing API endpoints
 type TestingHandler struct {
 	testService *services.TestService
+	config      TestingHandlerConfig
 }
 
 // NewTestingHandler creates a new testing handler instance
-func NewTestingHandler(testService *services.TestService) *TestingHandler {
+func NewTestingHandler(testService *services.TestService, config ...TestingHandlerConfig) *TestingHandler {
+	cfg := DefaultTestingHandlerConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.SyncRunTimeout <= 0 {
+		cfg.SyncRunTimeout = defaultSyncRunTimeout
+	}
+
 	return &TestingHandler{
 		testService: testService,
+		config:      cfg,
+	}
+}
+
+// runContext returns the context runs are started with, carrying the request's trace ID into
+// real-time updates and the subject of its verified bearer token, which owns the runs
+func runContext(c *fiber.Ctx) context.Context {
+	ctx := utils.ContextWithTraceID(c.Context(), utils.GetTraceID(c))
+	if claims, ok := middleware.GetJWTClaims(c); ok {
+		ctx = utils.ContextWithUserID(ctx, claims.Subject())
 	}
+	return ctx
 }
 
 // RunTests handles POST /api/testing/run - triggers test execution
 func (h *TestingHandler) RunTests(c *fiber.Ctx) error {
 	var req models.TestRunRequest
+	if ok, err := h.parseTestRunRequest(c, &req); !ok {
+		return err
+	}
+
+	// Start test run, carrying the trace ID into real-time updates
+	ctx := runContext(c)
+	response, err := h.testService.StartTestRun(ctx, &req)
+	if errors.Is(err, services.ErrInvalidTestConfig) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"config": err.Error(),
+			})
+	}
+	if errors.Is(err, services.ErrInvalidTestCallback) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"callback_url": err.Error(),
+			})
+	}
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_START_ERROR",
+			"Failed to start test run", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	return utils.SuccessResponse(c, "Test run started successfully", response)
+}
 
+// RunTestsSync handles POST /api/testing/run-sync - starts a test run and waits for its results
+func (h *TestingHandler) RunTestsSync(c *fiber.Ctx) error {
+	// An optional ?timeout= (seconds) shortens the wait, up to the configured maximum
+	timeout := h.config.SyncRunTimeout
+	if raw := c.Query("timeout"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > h.config.SyncRunTimeout {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+				fmt.Sprintf("timeout must be between 1 and %d seconds", int(h.config.SyncRunTimeout.Seconds())),
+				nil)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	var req models.TestRunRequest
+	if ok, err := h.parseTestRunRequest(c, &req); !ok {
+		return err
+	}
+
+	ctx := runContext(c)
+	response, err := h.testService.StartTestRun(ctx, &req)
+	if errors.Is(err, services.ErrInvalidTestConfig) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"config": err.Error(),
+			})
+	}
+	if errors.Is(err, services.ErrInvalidTestCallback) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"callback_url": err.Error(),
+			})
+	}
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_START_ERROR",
+			"Failed to start test run", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	results, err := h.testService.WaitForTestRun(ctx, response.RunID, timeout)
+	if errors.Is(err, services.ErrTestRunWaitTimeout) {
+		// The run keeps going; callers fall back to polling its results
+		return utils.ErrorResponse(c, fiber.StatusGatewayTimeout, "TEST_RUN_WAIT_TIMEOUT",
+			"Test run did not finish in time", map[string]string{
+				"run_id":      response.RunID,
+				"results_url": "/api/testing/results/" + response.RunID,
+				"timeout":     timeout.String(),
+			})
+	}
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_RESULTS_ERROR",
+			"Failed to wait for test run", map[string]string{
+				"run_id": response.RunID,
+				"error":  err.Error(),
+			})
+	}
+
+	return utils.SuccessResponse(c, "Test run finished", results)
+}
+
+// parseTestRunRequest binds and validates a test run request. When it is invalid, the error
+// response has already been written and ok is false.
+func (h *TestingHandler) parseTestRunRequest(c *fiber.Ctx, req *models.TestRunRequest) (bool, error) {
 	// Parse request body
+	if err := c.BodyParser(req); err != nil {
+		return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST",
+			"Invalid request body", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	// Validate request
+	if err := utils.ValidateStruct(req); err != nil {
+		return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	// Reject unsupported frameworks early when strict validation is enabled
+	if h.config.StrictValidation {
+		result := utils.NewValidator().ValidateValue("framework", req.Framework, models.FrameworkValidationRule())
+		if !result.IsValid {
+			return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+				"Unsupported test framework", map[string]string{
+					"allowed_values": strings.Join(models.SupportedFrameworks, ", "),
+				})
+		}
+	}
+
+	return true, nil
+}
+
+// StartWorkflow handles POST /api/testing/workflows - runs a sequence of dependent test runs
+func (h *TestingHandler) StartWorkflow(c *fiber.Ctx) error {
+	var req models.TestWorkflowRequest
 	if err := c.BodyParser(&req); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST",
 			"Invalid request body", map[string]string{
@@ -33,24 +204,88 @@ func (h *TestingHandler) RunTests(c *fiber.Ctx) error {
 			})
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
+	if ok, err := h.validateWorkflowRequest(c, &req); !ok {
+		return err
+	}
+
+	ctx := runContext(c)
+	response, err := h.testService.StartWorkflow(ctx, &req)
+	if errors.Is(err, services.ErrInvalidTestConfig) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"config": err.Error(),
+			})
+	}
+	if errors.Is(err, services.ErrInvalidTestCallback) {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
 			"Request validation failed", map[string]string{
+				"callback_url": err.Error(),
+			})
+	}
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_WORKFLOW_START_ERROR",
+			"Failed to start test workflow", map[string]string{
 				"error": err.Error(),
 			})
 	}
 
-	// Start test run
-	response, err := h.testService.StartTestRun(c.Context(), &req)
-	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_START_ERROR",
-			"Failed to start test run", map[string]string{
+	return utils.SuccessResponse(c, "Test workflow started successfully", response)
+}
+
+// validateWorkflowRequest validates a workflow and each of its steps' test run requests. When it
+// is invalid, the error response has already been written and ok is false.
+func (h *TestingHandler) validateWorkflowRequest(c *fiber.Ctx, req *models.TestWorkflowRequest) (bool, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
 				"error": err.Error(),
 			})
 	}
 
-	return utils.SuccessResponse(c, "Test run started successfully", response)
+	if len(req.Steps) == 0 || len(req.Steps) > models.TestWorkflowMaxSteps {
+		return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			fmt.Sprintf("A workflow must have between 1 and %d steps", models.TestWorkflowMaxSteps), map[string]string{
+				"steps": strconv.Itoa(len(req.Steps)),
+			})
+	}
+
+	for i := range req.Steps {
+		step := &req.Steps[i]
+		err := utils.ValidateStruct(step)
+		if err == nil && h.config.StrictValidation {
+			result := utils.NewValidator().ValidateValue("framework", step.Run.Framework, models.FrameworkValidationRule())
+			if !result.IsValid {
+				err = fmt.Errorf("unsupported test framework, allowed values: %s", strings.Join(models.SupportedFrameworks, ", "))
+			}
+		}
+		if err != nil {
+			return false, utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+				"Request validation failed", map[string]string{
+					"step":  strconv.Itoa(i + 1),
+					"error": err.Error(),
+				})
+		}
+	}
+
+	return true, nil
+}
+
+// GetWorkflow handles GET /api/testing/workflows/:workflowId - reports a workflow's progress and aggregated results
+func (h *TestingHandler) GetWorkflow(c *fiber.Ctx) error {
+	workflowID := c.Params("workflowId")
+
+	result, err := h.testService.GetWorkflow(workflowID)
+	if errors.Is(err, services.ErrWorkflowNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_WORKFLOW_NOT_FOUND",
+			"Test workflow not found", map[string]string{
+				"workflow_id": workflowID,
+			})
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to retrieve test workflow")
+	}
+
+	return utils.SuccessResponse(c, "Test workflow retrieved successfully", result)
 }
 
 // GetTestResults handles GET /api/testing/results/:runId - retrieves test results
@@ -74,6 +309,238 @@ func (h *TestingHandler) GetTestResults(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Test results retrieved successfully", results)
 }
 
+// GetTestArtifact handles GET /api/testing/results/:runId/artifacts/:name - downloads a screenshot,
+// video or trace kept from a run
+func (h *TestingHandler) GetTestArtifact(c *fiber.Ctx) error {
+	runID := c.Params("runId")
+	name := c.Params("name")
+
+	artifact, file, err := h.testService.OpenTestArtifact(runID, name)
+	switch {
+	case errors.Is(err, services.ErrTestRunNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_RUN_NOT_FOUND",
+			"Test run not found", map[string]string{
+				"run_id": runID,
+				"error":  err.Error(),
+			})
+	case errors.Is(err, services.ErrTestArtifactNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_ARTIFACT_NOT_FOUND",
+			"Test artifact not found", map[string]string{
+				"run_id": runID,
+				"name":   name,
+			})
+	case err != nil:
+		return utils.InternalServerErrorResponse(c, "Failed to open test artifact")
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return utils.InternalServerErrorResponse(c, "Failed to open test artifact")
+	}
+
+	// The file is closed once it has been sent
+	c.Attachment(artifact.Name)
+	c.Set(fiber.HeaderContentType, artifact.ContentType)
+	return c.SendStream(file, int(info.Size()))
+}
+
+// GetTestResultsJUnit handles GET /api/testing/results/:runId/junit - exports a finished run as JUnit XML
+func (h *TestingHandler) GetTestResultsJUnit(c *fiber.Ctx) error {
+	runID := c.Params("runId")
+
+	results, err := h.testService.GetTestResults(runID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_RUN_NOT_FOUND",
+			"Test run not found", map[string]string{
+				"run_id": runID,
+				"error":  err.Error(),
+			})
+	}
+
+	if results.Status == "running" || results.Status == "queued" {
+		return utils.ErrorResponse(c, fiber.StatusConflict, "TEST_RUN_NOT_FINISHED",
+			"Test run has not finished yet", map[string]string{
+				"run_id": runID,
+				"status": results.Status,
+			})
+	}
+
+	body, err := utils.MarshalJUnit(junitRun(results))
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to export test results")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Send(body)
+}
+
+// junitRun converts test results to the form rendered as JUnit XML
+func junitRun(results *models.TestResults) utils.JUnitRun {
+	run := utils.JUnitRun{
+		Name:      results.RunID,
+		Status:    results.Status,
+		Reason:    results.Reason,
+		Timestamp: results.StartTime,
+		Duration:  results.Duration,
+		Cases:     make([]utils.JUnitCase, 0, len(results.Results)),
+	}
+	for _, testCase := range results.Results {
+		run.Cases = append(run.Cases, utils.JUnitCase{
+			Name:       testCase.Name,
+			Status:     testCase.Status,
+			Duration:   testCase.Duration,
+			ErrorMsg:   testCase.ErrorMsg,
+			StackTrace: testCase.StackTrace,
+		})
+	}
+	return run
+}
+
+// ExportRunHistory handles GET /api/testing/export - downloads the finished runs started between
+// from and to, optionally only those of one workflow, as a zip of JSON and JUnit reports per run
+// plus a manifest
+func (h *TestingHandler) ExportRunHistory(c *fiber.Ctx) error {
+	var filter services.TestRunExportFilter
+	details := make(map[string]string)
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			details[param] = "must be an RFC3339 timestamp"
+			continue
+		}
+		*target = parsed
+	}
+	if len(details) == 0 && !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		details["to"] = "must not be before from"
+	}
+	if len(details) > 0 {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Request validation failed", details)
+	}
+	filter.WorkflowID = c.Query("workflow")
+
+	runs, err := h.testService.ExportRuns(filter)
+	if errors.Is(err, services.ErrWorkflowNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_WORKFLOW_NOT_FOUND",
+			"Test workflow not found", map[string]string{
+				"workflow_id": filter.WorkflowID,
+			})
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to export test runs")
+	}
+
+	manifest := testRunArchiveManifest(filter, runs)
+	traceID := utils.GetTraceID(c)
+
+	c.Attachment(fmt.Sprintf("test-runs-%s.zip", manifest.GeneratedAt.Format("20060102T150405Z")))
+	c.Set(fiber.HeaderContentType, "application/zip")
+
+	// The archive is written run by run; once streaming has started the status can't change, so an
+	// error ends the download early and is only logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		err := writeTestRunArchive(w, manifest, runs)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			utils.GetLogger().WithTraceID(traceID).Error("Test run export stopped early", err, map[string]interface{}{
+				"runs": len(runs),
+			})
+		}
+	})
+	return nil
+}
+
+// testRunArchiveManifest describes runs and the files each is stored under in the archive
+func testRunArchiveManifest(filter services.TestRunExportFilter, runs []models.TestResults) models.TestRunArchiveManifest {
+	manifest := models.TestRunArchiveManifest{
+		GeneratedAt: time.Now().UTC(),
+		WorkflowID:  filter.WorkflowID,
+		RunCount:    len(runs),
+		Runs:        make([]models.TestRunArchiveEntry, 0, len(runs)),
+	}
+	if !filter.From.IsZero() {
+		manifest.From = &filter.From
+	}
+	if !filter.To.IsZero() {
+		manifest.To = &filter.To
+	}
+
+	for _, run := range runs {
+		// Run IDs from a history store are only known to be file names, so keep them to a single
+		// path segment inside the archive
+		dir := "runs/" + strings.Map(func(r rune) rune {
+			if r == '/' || r == '\\' {
+				return '_'
+			}
+			return r
+		}, run.RunID)
+		manifest.Runs = append(manifest.Runs, models.TestRunArchiveEntry{
+			RunID:        run.RunID,
+			Status:       run.Status,
+			TotalTests:   run.TotalTests,
+			PassedTests:  run.PassedTests,
+			FailedTests:  run.FailedTests,
+			SkippedTests: run.SkippedTests,
+			StartTime:    run.StartTime,
+			EndTime:      run.EndTime,
+			ResultsFile:  dir + "/results.json",
+			JUnitFile:    dir + "/junit.xml",
+		})
+	}
+	return manifest
+}
+
+// writeTestRunArchive writes the manifest and the reports of each run as a zip to w
+func writeTestRunArchive(w io.Writer, manifest models.TestRunArchiveManifest, runs []models.TestResults) error {
+	archive := zip.NewWriter(w)
+
+	writeFile := func(name string, modified time.Time, body []byte) error {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(body)
+		return err
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile("manifest.json", manifest.GeneratedAt, body); err != nil {
+		return err
+	}
+
+	for i := range runs {
+		run := &runs[i]
+		entry := manifest.Runs[i]
+
+		body, err := json.MarshalIndent(run, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(entry.ResultsFile, run.EndTime, body); err != nil {
+			return err
+		}
+
+		body, err = utils.MarshalJUnit(junitRun(run))
+		if err != nil {
+			return err
+		}
+		if err := writeFile(entry.JUnitFile, run.EndTime, body); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
 // ValidateSync handles POST /api/testing/validate-sync - validates API-UI synchronization
 func (h *TestingHandler) ValidateSync(c *fiber.Ctx) error {
 	var req models.TestSyncValidationRequest
@@ -94,8 +561,21 @@ func (h *TestingHandler) ValidateSync(c *fiber.Ctx) error {
 			})
 	}
 
+	// Validate the optional per-assertion timeout
+	if value, ok := req.Config[models.SyncTimeoutConfigKey]; ok && value != "" {
+		if _, err := models.ParseSyncTimeout(value); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+				"Request validation failed", map[string]string{
+					"error": err.Error(),
+				})
+		}
+	}
+
 	// Validate sync
 	response, err := h.testService.ValidateSync(c.Context(), &req)
+	if errors.Is(err, services.ErrValidationLimitReached) {
+		return validationLimitResponse(c, err)
+	}
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "SYNC_VALIDATION_ERROR",
 			"Failed to validate synchronization", map[string]string{
@@ -149,12 +629,119 @@ func (h *TestingHandler) CancelTestRun(c *fiber.Ctx) error {
 	})
 }
 
+// RerunTestRun handles POST /api/testing/runs/:runId/rerun - starts a new run with the request a
+// previous run was started with. ?failedOnly=true runs only the test cases that failed.
+func (h *TestingHandler) RerunTestRun(c *fiber.Ctx) error {
+	runID := c.Params("runId")
+	failedOnly := c.QueryBool("failedOnly", false)
+
+	ctx := runContext(c)
+	response, err := h.testService.RerunTestRun(ctx, runID, failedOnly)
+	switch {
+	case errors.Is(err, services.ErrTestRunNotFound):
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_RUN_NOT_FOUND",
+			"Test run not found", map[string]string{
+				"run_id": runID,
+				"error":  err.Error(),
+			})
+	case errors.Is(err, services.ErrTestRunNotRerunnable):
+		return utils.ErrorResponse(c, fiber.StatusConflict, "TEST_RUN_NOT_RERUNNABLE",
+			"Test run cannot be re-run", map[string]string{
+				"run_id": runID,
+				"error":  err.Error(),
+			})
+	case errors.Is(err, services.ErrNoFailedTests):
+		return utils.ErrorResponse(c, fiber.StatusConflict, "NO_FAILED_TESTS",
+			"Test run has no failed test cases to re-run", map[string]string{
+				"run_id": runID,
+			})
+	case err != nil:
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "TEST_START_ERROR",
+			"Failed to start test run", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	return utils.SuccessResponse(c, "Test run re-run started successfully", response)
+}
+
+// CreateSchedule handles POST /api/testing/schedules - starts a test run whenever a cron expression is due
+func (h *TestingHandler) CreateSchedule(c *fiber.Ctx) error {
+	var req models.TestScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_REQUEST",
+			"Invalid request body", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR",
+			"Request validation failed", map[string]string{
+				"error": err.Error(),
+			})
+	}
+
+	schedule, err := h.testService.CreateSchedule(&req)
+	if errors.Is(err, services.ErrInvalidTestSchedule) {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_TEST_SCHEDULE",
+			"Invalid test schedule", map[string]string{
+				"error": err.Error(),
+			})
+	}
+	if errors.Is(err, services.ErrTestScheduleLimitReached) {
+		return utils.ErrorResponse(c, fiber.StatusConflict, "TEST_SCHEDULE_LIMIT_REACHED",
+			"Too many test schedules", map[string]string{
+				"error": err.Error(),
+			})
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to create test schedule")
+	}
+
+	return utils.SuccessResponse(c, "Test schedule created successfully", schedule)
+}
+
+// ListSchedules handles GET /api/testing/schedules - lists test schedules and when each runs next
+func (h *TestingHandler) ListSchedules(c *fiber.Ctx) error {
+	schedules := h.testService.ListSchedules()
+	return utils.SuccessResponse(c, "Test schedules retrieved successfully", schedules)
+}
+
+// DeleteSchedule handles DELETE /api/testing/schedules/:id - stops a schedule from starting further runs
+func (h *TestingHandler) DeleteSchedule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	err := h.testService.DeleteSchedule(id)
+	if errors.Is(err, services.ErrTestScheduleNotFound) {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "TEST_SCHEDULE_NOT_FOUND",
+			"Test schedule not found", map[string]string{
+				"schedule_id": id,
+			})
+	}
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to delete test schedule")
+	}
+
+	return utils.SuccessResponse(c, "Test schedule deleted successfully", fiber.Map{
+		"schedule_id": id,
+	})
+}
+
 // GetTestingStatus handles GET /api/testing/status - gets testing service status
 func (h *TestingHandler) GetTestingStatus(c *fiber.Ctx) error {
 	status := h.testService.GetStatus()
 	return utils.SuccessResponse(c, "Testing service status retrieved successfully", status)
 }
 
+// GetFrameworks handles GET /api/testing/frameworks - reports installed test frameworks and their versions
+func (h *TestingHandler) GetFrameworks(c *fiber.Ctx) error {
+	refresh := c.QueryBool("refresh", false)
+
+	frameworks := h.testService.GetFrameworkVersions(c.Context(), refresh)
+	return utils.SuccessResponse(c, "Test frameworks retrieved successfully", frameworks)
+}
+
 // HealthCheck handles GET /api/testing/health - testing service health check
 func (h *TestingHandler) HealthCheck(c *fiber.Ctx) error {
 	health := fiber.Map{
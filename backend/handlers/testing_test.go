@@ -1,21 +1,32 @@
 package handlers
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/middleware"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockWebSocketHub implements the WebSocketHub interface for testing
@@ -77,6 +88,19 @@ func TestTestingHandler_RunTests(t *testing.T) {
 			expectedStatus: 400,
 			expectedError:  "VALIDATION_ERROR",
 		},
+		{
+			name: "Config key naming a reserved environment variable",
+			requestBody: models.TestRunRequest{
+				Framework:   "cypress",
+				TestSuite:   "integration/api.spec.js",
+				Environment: "development",
+				Config: map[string]string{
+					"NODE_OPTIONS": "--require /tmp/evil.js",
+				},
+			},
+			expectedStatus: 400,
+			expectedError:  "VALIDATION_ERROR",
+		},
 	}
 
 	for _, tt := range tests {
@@ -124,6 +148,147 @@ func TestTestingHandler_RunTests(t *testing.T) {
 	}
 }
 
+// TestTestingHandler_RunTests_StrictValidation tests framework validation with and without strict mode
+func TestTestingHandler_RunTests_StrictValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         TestingHandlerConfig
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Strict mode rejects unknown framework in handler",
+			config:         TestingHandlerConfig{StrictValidation: true},
+			expectedStatus: 400,
+			expectedError:  "VALIDATION_ERROR",
+		},
+		{
+			name:           "Lenient mode defers to the test service",
+			config:         TestingHandlerConfig{StrictValidation: false},
+			expectedStatus: 500,
+			expectedError:  "TEST_START_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			cfg := &config.Config{Environment: "test"}
+			mockHub := &MockWebSocketHub{}
+			testService := services.NewTestService(cfg, mockHub)
+			handler := NewTestingHandler(testService, tt.config)
+
+			app := fiber.New()
+			app.Post("/api/testing/run", handler.RunTests)
+
+			body, _ := json.Marshal(models.TestRunRequest{
+				Framework:   "jasmine",
+				TestSuite:   "test.spec.js",
+				Environment: "development",
+			})
+			req := httptest.NewRequest("POST", "/api/testing/run", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Execute request
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			// Parse response body
+			respBody, _ := io.ReadAll(resp.Body)
+			var response map[string]interface{}
+			json.Unmarshal(respBody, &response)
+
+			errorInfo := response["error"].(map[string]interface{})
+			assert.Equal(t, tt.expectedError, errorInfo["code"])
+
+			if tt.config.StrictValidation {
+				details := errorInfo["details"].(map[string]interface{})
+				assert.NotContains(t, details, "framework", "rejected values aren't echoed")
+				assert.Equal(t, "cypress, playwright, jest, vitest, mocha, pytest, go", details["allowed_values"])
+			}
+		})
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	app := fiber.New()
+	app.Get("/run", middleware.JWTAuth(middleware.JWTAuthConfig{
+		Secret: []byte("secret"),
+		Routes: []models.ProtectedRoute{{Method: "*", Path: "/run"}},
+	}), func(c *fiber.Ctx) error {
+		return c.SendString(utils.UserIDFromContext(runContext(c)))
+	})
+	app.Get("/anonymous", func(c *fiber.Ctx) error {
+		return c.SendString(utils.UserIDFromContext(runContext(c)))
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(signingInput))
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/run", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "alice", string(body), "runs are owned by the token's subject")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/anonymous", nil), -1)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Empty(t, string(body), "runs started without a token have no owner")
+}
+
+// TestTestingHandler_RunTests_PropagatesTraceID tests that the request trace ID reaches WebSocket updates
+func TestTestingHandler_RunTests_PropagatesTraceID(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		Environment:           "test",
+		EnableWSCorrelationID: true,
+	}
+
+	var mu sync.Mutex
+	traceIDs := make([]interface{}, 0)
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "test_progress", mock.Anything).Run(func(args mock.Arguments) {
+		data := args.Get(1).(map[string]interface{})
+		mu.Lock()
+		traceIDs = append(traceIDs, data["trace_id"])
+		mu.Unlock()
+	}).Return()
+
+	testService := services.NewTestService(cfg, mockHub)
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Post("/api/testing/run", handler.RunTests)
+
+	body, _ := json.Marshal(models.TestRunRequest{
+		Framework:   "jest",
+		TestSuite:   "unit",
+		Environment: "test",
+	})
+	req := httptest.NewRequest("POST", "/api/testing/run", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trace-ID", "trace-from-client")
+
+	// Execute request
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// The queued update is broadcast before the handler returns
+	mu.Lock()
+	defer mu.Unlock()
+	assert.NotEmpty(t, traceIDs)
+	for _, traceID := range traceIDs {
+		assert.Equal(t, "trace-from-client", traceID)
+	}
+}
+
 // TestTestingHandler_GetTestResults tests the GetTestResults endpoint
 func TestTestingHandler_GetTestResults(t *testing.T) {
 	// Setup
@@ -207,6 +372,254 @@ func TestTestingHandler_GetTestResults(t *testing.T) {
 	}
 }
 
+// TestTestingHandler_GetTestResultsJUnit tests the JUnit XML export endpoint
+func TestTestingHandler_GetTestArtifact(t *testing.T) {
+	root := t.TempDir()
+	store, err := services.NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(models.TestResults{
+		RunID:  "run-1",
+		Status: "failed",
+		Artifacts: []models.Artifact{
+			{Name: "test-results_login-failed-1.png", Kind: models.ArtifactKindScreenshot, ContentType: "image/png", Size: 9},
+			{Name: "test-results_video.webm", Kind: models.ArtifactKindVideo, ContentType: "video/webm", Size: 10},
+		},
+	}))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "run-1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "run-1", "test-results_login-failed-1.png"), []byte("png bytes"), 0o644))
+
+	testService := services.NewTestService(&config.Config{TestArtifactsDir: root}, nil, services.TestServiceConfig{HistoryStore: store})
+	handler := NewTestingHandler(testService)
+	app := fiber.New()
+	app.Get("/api/testing/results/:runId/artifacts/:name", handler.GetTestArtifact)
+
+	t.Run("download", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/results/run-1/artifacts/test-results_login-failed-1.png", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="test-results_login-failed-1.png"`)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "png bytes", string(body))
+	})
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode string
+	}{
+		{"unknown run", "/api/testing/results/missing/artifacts/test-results_login-failed-1.png", "TEST_RUN_NOT_FOUND"},
+		{"unknown artifact", "/api/testing/results/run-1/artifacts/other.png", "TEST_ARTIFACT_NOT_FOUND"},
+		{"file removed", "/api/testing/results/run-1/artifacts/test-results_video.webm", "TEST_ARTIFACT_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+			var response utils.StandardResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
+
+func TestTestingHandler_GetTestResultsJUnit(t *testing.T) {
+	// Setup: finished runs come from a history store
+	store, err := services.NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	require.NoError(t, store.Save(models.TestResults{
+		RunID:       "run-done",
+		Status:      "failed",
+		TotalTests:  2,
+		PassedTests: 1,
+		FailedTests: 1,
+		Duration:    90 * time.Second,
+		StartTime:   start,
+		EndTime:     start.Add(90 * time.Second),
+		Results: []models.TestCase{
+			{Name: "login works", Status: "passed", Duration: time.Second},
+			{Name: "checkout works", Status: "failed", Duration: 2 * time.Second, ErrorMsg: "timeout waiting for #pay"},
+		},
+	}))
+	require.NoError(t, store.Save(models.TestResults{RunID: "run-busy", Status: "running", EndTime: start}))
+
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil, services.TestServiceConfig{HistoryStore: store})
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Get("/api/testing/results/:runId/junit", handler.GetTestResultsJUnit)
+
+	t.Run("finished run", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/results/run-done/junit", nil), -1)
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "application/xml")
+
+		body, _ := io.ReadAll(resp.Body)
+		output := string(body)
+		assert.Contains(t, output, `<testsuites name="run-done" tests="2" failures="1" errors="0" skipped="0" time="90.000">`)
+		assert.Contains(t, output, `<testcase name="login works" classname="run-done" time="1.000"></testcase>`)
+		assert.Contains(t, output, `<failure message="timeout waiting for #pay"></failure>`)
+	})
+
+	tests := []struct {
+		name           string
+		runID          string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "unknown run", runID: "missing", expectedStatus: 404, expectedError: "TEST_RUN_NOT_FOUND"},
+		{name: "unfinished run", runID: "run-busy", expectedStatus: 409, expectedError: "TEST_RUN_NOT_FINISHED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/results/"+tt.runID+"/junit", nil), -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, _ := io.ReadAll(resp.Body)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &response))
+			errorInfo := response["error"].(map[string]interface{})
+			assert.Equal(t, tt.expectedError, errorInfo["code"])
+		})
+	}
+}
+
+func TestTestingHandler_ExportRunHistory(t *testing.T) {
+	// Setup: three finished runs an hour apart plus one still running
+	store, err := services.NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, runID := range []string{"run-1", "run-2", "run-3"} {
+		start := base.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, store.Save(models.TestResults{
+			RunID:       runID,
+			Status:      "completed",
+			TotalTests:  1,
+			PassedTests: 1,
+			StartTime:   start,
+			EndTime:     start.Add(time.Minute),
+			Results:     []models.TestCase{{Name: "login works", Status: "passed", Duration: time.Second}},
+		}))
+	}
+	require.NoError(t, store.Save(models.TestResults{RunID: "run-busy", Status: "running", StartTime: base}))
+
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil, services.TestServiceConfig{HistoryStore: store})
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Get("/api/testing/export", handler.ExportRunHistory)
+
+	get := func(query string) (*http.Response, []byte) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/export"+query, nil), -1)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	t.Run("runs in range", func(t *testing.T) {
+		resp, body := get("?from=2024-01-15T11:00:00Z&to=2024-01-15T12:00:00Z")
+		require.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), `attachment; filename="test-runs-`)
+
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		files := make(map[string][]byte)
+		var names []string
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			files[file.Name], err = io.ReadAll(reader)
+			require.NoError(t, err)
+			reader.Close()
+			names = append(names, file.Name)
+		}
+		assert.Equal(t, []string{
+			"manifest.json",
+			"runs/run-2/results.json", "runs/run-2/junit.xml",
+			"runs/run-3/results.json", "runs/run-3/junit.xml",
+		}, names)
+
+		var manifest models.TestRunArchiveManifest
+		require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+		assert.Equal(t, 2, manifest.RunCount)
+		require.NotNil(t, manifest.From)
+		assert.True(t, manifest.From.Equal(base.Add(time.Hour)))
+		require.Len(t, manifest.Runs, 2)
+		assert.Equal(t, "run-2", manifest.Runs[0].RunID)
+		assert.Equal(t, 1, manifest.Runs[0].PassedTests)
+		assert.Equal(t, "runs/run-2/junit.xml", manifest.Runs[0].JUnitFile)
+
+		var results models.TestResults
+		require.NoError(t, json.Unmarshal(files["runs/run-3/results.json"], &results))
+		assert.Equal(t, "run-3", results.RunID)
+		assert.Len(t, results.Results, 1)
+		assert.Contains(t, string(files["runs/run-3/junit.xml"]), `<testcase name="login works" classname="run-3" time="1.000"></testcase>`)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		resp, body := get("?from=2025-01-01T00:00:00Z")
+		require.Equal(t, 200, resp.StatusCode)
+
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		require.Len(t, archive.File, 1)
+		assert.Equal(t, "manifest.json", archive.File[0].Name)
+	})
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedError   string
+		expectedDetails map[string]string
+	}{
+		{
+			name:            "invalid timestamp",
+			query:           "?from=yesterday",
+			expectedStatus:  400,
+			expectedError:   "VALIDATION_ERROR",
+			expectedDetails: map[string]string{"from": "must be an RFC3339 timestamp"},
+		},
+		{
+			name:            "reversed range",
+			query:           "?from=2024-01-16T00:00:00Z&to=2024-01-15T00:00:00Z",
+			expectedStatus:  400,
+			expectedError:   "VALIDATION_ERROR",
+			expectedDetails: map[string]string{"to": "must not be before from"},
+		},
+		{
+			name:            "unknown workflow",
+			query:           "?workflow=missing",
+			expectedStatus:  404,
+			expectedError:   "TEST_WORKFLOW_NOT_FOUND",
+			expectedDetails: map[string]string{"workflow_id": "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(tt.query)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var response utils.StandardResponse
+			require.NoError(t, json.Unmarshal(body, &response))
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.expectedError, response.Error.Code)
+			assert.Equal(t, tt.expectedDetails, response.Error.Details)
+		})
+	}
+}
+
 // TestTestingHandler_ValidateSync tests the ValidateSync endpoint
 func TestTestingHandler_ValidateSync(t *testing.T) {
 	// Setup
@@ -269,6 +682,24 @@ func TestTestingHandler_ValidateSync(t *testing.T) {
 			expectedStatus: 400,
 			expectedError:  "VALIDATION_ERROR",
 		},
+		{
+			name: "Invalid assertion timeout",
+			requestBody: models.TestSyncValidationRequest{
+				APIEndpoint: "http://localhost:8080/api/users",
+				UIComponent: "UserList",
+				Assertions: []models.SyncAssertion{
+					{
+						Type:     "data_match",
+						Field:    "name",
+						Expected: "John Doe",
+						Operator: "equals",
+					},
+				},
+				Config: map[string]string{"timeout": "forever"},
+			},
+			expectedStatus: 400,
+			expectedError:  "VALIDATION_ERROR",
+		},
 	}
 
 	for _, tt := range tests {
@@ -448,6 +879,48 @@ func TestTestingHandler_GetTestingStatus(t *testing.T) {
 	assert.Contains(t, data, "supported_frameworks")
 }
 
+// TestTestingHandler_GetFrameworks tests the GetFrameworks endpoint
+func TestTestingHandler_GetFrameworks(t *testing.T) {
+	// Setup
+	cfg := &config.Config{Environment: "test"}
+	mockHub := &MockWebSocketHub{}
+	testService := services.NewTestService(cfg, mockHub)
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Get("/api/testing/frameworks", handler.GetFrameworks)
+
+	// Create request
+	req := httptest.NewRequest("GET", "/api/testing/frameworks?refresh=true", nil)
+
+	// Execute request
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+
+	// Verify response
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Parse response body
+	respBody, _ := io.ReadAll(resp.Body)
+	var response map[string]interface{}
+	json.Unmarshal(respBody, &response)
+
+	assert.Equal(t, true, response["success"])
+
+	// Every supported framework should be reported
+	data := response["data"].([]interface{})
+	assert.Len(t, data, 7)
+
+	names := make([]string, 0, len(data))
+	for _, item := range data {
+		fw := item.(map[string]interface{})
+		assert.Contains(t, fw, "installed")
+		assert.Contains(t, fw, "checked_at")
+		names = append(names, fw["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"cypress", "playwright", "jest", "vitest", "mocha", "pytest", "go"}, names)
+}
+
 // TestTestingHandler_HealthCheck tests the HealthCheck endpoint
 func TestTestingHandler_HealthCheck(t *testing.T) {
 	// Setup
@@ -483,3 +956,301 @@ func TestTestingHandler_HealthCheck(t *testing.T) {
 	assert.Equal(t, "testing", data["service"])
 	assert.Contains(t, data, "details")
 }
+
+// writeGoTestModule creates a throwaway Go module with a quick ./fast package and a ./slow package whose test sleeps
+func writeGoTestModule(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":            "module example.com/synctest\n\ngo 1.21\n",
+		"fast/fast_test.go": "package fast\n\nimport \"testing\"\n\nfunc TestFast(t *testing.T) {}\n",
+		"slow/slow_test.go": "package slow\n\nimport (\n\t\"testing\"\n\t\"time\"\n)\n\nfunc TestSlow(t *testing.T) { time.Sleep(time.Minute) }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+// TestTestingHandler_RunTestsSync tests the blocking RunTestsSync endpoint
+func TestTestingHandler_RunTestsSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test in a temporary module")
+	}
+
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil)
+	handler := NewTestingHandler(testService, TestingHandlerConfig{StrictValidation: true, SyncRunTimeout: time.Minute})
+
+	app := fiber.New()
+	app.Post("/api/testing/run-sync", handler.RunTestsSync)
+
+	workDir := writeGoTestModule(t)
+	post := func(query, suite string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(models.TestRunRequest{
+			Framework:   "go",
+			TestSuite:   suite,
+			Environment: "development",
+			Config:      map[string]string{"workDir": workDir},
+		})
+		req := httptest.NewRequest("POST", "/api/testing/run-sync"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	t.Run("returns the finished results", func(t *testing.T) {
+		status, response := post("", "./fast")
+		assert.Equal(t, 200, status)
+
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "completed", data["status"])
+		assert.EqualValues(t, 1, data["passed_tests"])
+	})
+
+	t.Run("times out while the run keeps going", func(t *testing.T) {
+		status, response := post("?timeout=1", "./slow")
+		assert.Equal(t, 504, status)
+
+		errorData := response["error"].(map[string]interface{})
+		assert.Equal(t, "TEST_RUN_WAIT_TIMEOUT", errorData["code"])
+
+		details := errorData["details"].(map[string]interface{})
+		runID := details["run_id"].(string)
+		assert.Equal(t, "/api/testing/results/"+runID, details["results_url"])
+		assert.NoError(t, testService.CancelTestRun(runID))
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		for _, query := range []string{"?timeout=abc", "?timeout=0", "?timeout=61"} {
+			status, response := post(query, "./fast")
+			assert.Equal(t, 400, status, query)
+			assert.Equal(t, "VALIDATION_ERROR", response["error"].(map[string]interface{})["code"], query)
+		}
+	})
+}
+
+// TestTestingHandler_RerunTestRun tests re-running runs from history
+func TestTestingHandler_RerunTestRun(t *testing.T) {
+	workDir := writeGoTestModule(t)
+	store, err := services.NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(models.TestResults{
+		RunID:       "run-passed",
+		Status:      "completed",
+		TotalTests:  1,
+		PassedTests: 1,
+		Results:     []models.TestCase{{Name: "example.com/synctest/fast.TestFast", Status: "passed"}},
+		Request: &models.TestRunRequest{
+			Framework:   "go",
+			TestSuite:   "./fast",
+			Environment: "development",
+			Config:      map[string]string{"workDir": workDir},
+		},
+	}))
+	require.NoError(t, store.Save(models.TestResults{RunID: "run-legacy", Status: "failed"}))
+
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil, services.TestServiceConfig{HistoryStore: store})
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Post("/api/testing/runs/:runId/rerun", handler.RerunTestRun)
+
+	rerun := func(target string) (int, map[string]interface{}) {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/testing/runs/"+target, nil), -1)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	t.Run("starts a new run", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("runs go test in a temporary module")
+		}
+
+		status, response := rerun("run-passed/rerun")
+		require.Equal(t, 200, status)
+		runID := response["data"].(map[string]interface{})["run_id"].(string)
+		assert.NotEqual(t, "run-passed", runID)
+
+		results, err := testService.WaitForTestRun(context.Background(), runID, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "run-passed", results.RetryOf)
+		assert.Equal(t, "completed", results.Status)
+	})
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "unknown run", target: "missing/rerun", expectedStatus: 404, expectedError: "TEST_RUN_NOT_FOUND"},
+		{name: "request not recorded", target: "run-legacy/rerun", expectedStatus: 409, expectedError: "TEST_RUN_NOT_RERUNNABLE"},
+		{name: "no failed tests", target: "run-passed/rerun?failedOnly=true", expectedStatus: 409, expectedError: "NO_FAILED_TESTS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := rerun(tt.target)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedError, response["error"].(map[string]interface{})["code"])
+		})
+	}
+}
+
+// TestTestingHandler_Schedules tests creating, listing and deleting test schedules
+func TestTestingHandler_Schedules(t *testing.T) {
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil)
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Post("/api/testing/schedules", handler.CreateSchedule)
+	app.Get("/api/testing/schedules", handler.ListSchedules)
+	app.Delete("/api/testing/schedules/:id", handler.DeleteSchedule)
+
+	send := func(method, target string, body interface{}) (int, map[string]interface{}) {
+		var reader io.Reader
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req := httptest.NewRequest(method, target, reader)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+	run := models.TestRunRequest{Framework: "jest", TestSuite: "unit", Environment: "staging"}
+
+	status, response := send("POST", "/api/testing/schedules", models.TestScheduleRequest{
+		Name:     "nightly",
+		Cron:     "30 2 * * 1-5",
+		Timezone: "America/New_York",
+		Overlap:  models.TestScheduleOverlapQueue,
+		Run:      run,
+	})
+	require.Equal(t, 200, status, response)
+	created := response["data"].(map[string]interface{})
+	id := created["id"].(string)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, "queue", created["overlap"])
+	assert.NotEmpty(t, created["next_run"])
+
+	status, response = send("GET", "/api/testing/schedules", nil)
+	require.Equal(t, 200, status)
+	schedules := response["data"].([]interface{})
+	require.Len(t, schedules, 1)
+	assert.Equal(t, id, schedules[0].(map[string]interface{})["id"])
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			request       models.TestScheduleRequest
+			expectedError string
+		}{
+			{"missing cron", models.TestScheduleRequest{Name: "nightly", Run: run}, "VALIDATION_ERROR"},
+			{"unknown overlap", models.TestScheduleRequest{Name: "nightly", Cron: "@daily", Overlap: "cancel", Run: run}, "VALIDATION_ERROR"},
+			{"missing run", models.TestScheduleRequest{Name: "nightly", Cron: "@daily"}, "VALIDATION_ERROR"},
+			{"bad cron", models.TestScheduleRequest{Name: "nightly", Cron: "61 * * * *", Run: run}, "INVALID_TEST_SCHEDULE"},
+			{"unknown time zone", models.TestScheduleRequest{Name: "nightly", Cron: "@daily", Timezone: "Nowhere/City", Run: run}, "INVALID_TEST_SCHEDULE"},
+		}
+		for _, tt := range tests {
+			status, response := send("POST", "/api/testing/schedules", tt.request)
+			assert.Equal(t, 400, status, tt.name)
+			assert.Equal(t, tt.expectedError, response["error"].(map[string]interface{})["code"], tt.name)
+		}
+	})
+
+	status, _ = send("DELETE", "/api/testing/schedules/"+id, nil)
+	assert.Equal(t, 200, status)
+	assert.Empty(t, testService.ListSchedules())
+
+	status, response = send("DELETE", "/api/testing/schedules/"+id, nil)
+	assert.Equal(t, 404, status)
+	assert.Equal(t, "TEST_SCHEDULE_NOT_FOUND", response["error"].(map[string]interface{})["code"])
+}
+
+// TestTestingHandler_Workflows tests starting a workflow and polling its results
+func TestTestingHandler_Workflows(t *testing.T) {
+	testService := services.NewTestService(&config.Config{Environment: "test"}, nil)
+	handler := NewTestingHandler(testService)
+
+	app := fiber.New()
+	app.Post("/api/testing/workflows", handler.StartWorkflow)
+	app.Get("/api/testing/workflows/:workflowId", handler.GetWorkflow)
+
+	post := func(body interface{}) (int, utils.StandardResponse) {
+		encoded, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/testing/workflows", bytes.NewReader(encoded))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+
+		var response utils.StandardResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+	step := func(name, framework string) models.TestWorkflowStep {
+		return models.TestWorkflowStep{
+			Name: name,
+			Run:  models.TestRunRequest{Framework: framework, TestSuite: "./missing/...", Environment: "development"},
+		}
+	}
+
+	t.Run("starts the workflow", func(t *testing.T) {
+		status, response := post(models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{step("seed", "go"), step("api", "go")},
+		})
+		require.Equal(t, 200, status)
+
+		data := response.Data.(map[string]interface{})
+		workflowID := data["workflow_id"].(string)
+		assert.Equal(t, "running", data["status"])
+		assert.Len(t, data["steps"], 2)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/workflows/"+workflowID, nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+
+	t.Run("invalid workflows", func(t *testing.T) {
+		tests := map[string]models.TestWorkflowRequest{
+			"no name":               {Steps: []models.TestWorkflowStep{step("seed", "go")}},
+			"no steps":              {Name: "checkout"},
+			"unnamed step":          {Name: "checkout", Steps: []models.TestWorkflowStep{step("", "go")}},
+			"unsupported framework": {Name: "checkout", Steps: []models.TestWorkflowStep{step("seed", "go"), step("ui", "karma")}},
+			"too many steps":        {Name: "checkout", Steps: make([]models.TestWorkflowStep, models.TestWorkflowMaxSteps+1)},
+		}
+
+		for name, body := range tests {
+			t.Run(name, func(t *testing.T) {
+				status, response := post(body)
+				assert.Equal(t, 400, status)
+				require.NotNil(t, response.Error)
+				assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+			})
+		}
+
+		_, response := post(tests["unsupported framework"])
+		assert.Equal(t, "2", response.Error.Details["step"])
+	})
+
+	t.Run("unknown workflow", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/testing/workflows/missing", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -171,27 +172,18 @@ func TestLoggingAndAnalysisWorkflow(t *testing.T) {
 	})
 
 	t.Run("Log Analysis Workflow", func(t *testing.T) {
-		// Step 1: Analyze logs with filters
-		analysisRequest := models.LogAnalysisRequest{
-			TimeRange: models.TimeRange{
-				Start: time.Now().Add(-2 * time.Hour),
-				End:   time.Now(),
-			},
-			Levels:      []string{"error", "warn"},
-			Sources:     []string{"frontend", "backend"},
-			Components:  []string{"UserProfile", "APIClient"},
-			SearchQuery: "user profile",
-			Filters: map[string]string{
-				"userId": "123",
-			},
-			Limit: 100,
-		}
-
-		reqBody, err := json.Marshal(analysisRequest)
-		require.NoError(t, err)
-
-		req := httptest.NewRequest("GET", "/api/logs/analyze", bytes.NewReader(reqBody))
-		req.Header.Set("Content-Type", "application/json")
+		// Step 1: Analyze logs with filters given as query parameters, as browsers send them
+		query := url.Values{}
+		query.Set("start_time", time.Now().Add(-2*time.Hour).Format(time.RFC3339))
+		query.Set("end_time", time.Now().Format(time.RFC3339))
+		query.Set("levels", "error,warn")
+		query.Set("sources", "frontend,backend")
+		query.Set("components", "UserProfile,APIClient")
+		query.Set("search_query", "user profile")
+		query.Add("filter", "userId:123")
+		query.Set("limit", "100")
+
+		req := httptest.NewRequest("GET", "/api/logs/analyze?"+query.Encode(), nil)
 		resp, err := app.Test(req, 15000)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -12,6 +12,7 @@ import (
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/handlers"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/middleware"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/websocket"
@@ -35,8 +36,22 @@ func main() {
 	}
 
 	// Initialize logger
-	utils.InitLogger(cfg.LogLevel, cfg.LogFormat)
+	utils.InitLogger(cfg.LogLevel, cfg.LogFormat, utils.LogOutputConfig{
+		Output:     cfg.LogOutput,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxFiles:   cfg.LogMaxFiles,
+		MaxAgeDays: cfg.LogMaxAgeDays,
+	})
 	logger := utils.GetLogger()
+	defer logger.Close()
+	if cfg.EnableLogSampling {
+		logger.WithSampling(utils.LogSamplingPolicy{
+			First:      cfg.LogSampleFirst,
+			Thereafter: cfg.LogSampleThereafter,
+			Interval:   time.Duration(cfg.LogSampleInterval) * time.Millisecond,
+		})
+	}
+	utils.WatchFormatSignal(logger)
 	logger.Info("Starting Full Stack Master Sync Backend", map[string]interface{}{
 		"version":     "1.0.0",
 		"environment": cfg.Environment,
@@ -46,8 +61,45 @@ func main() {
 	// Initialize error recovery service
 	recoveryService := utils.NewErrorRecoveryService(logger)
 
+	// Initialize the audit store, which records designated broadcasts from the hub
+	auditStore := services.NewAuditStore(cfg.AuditEventTypes, cfg.AuditMaxEvents, services.AuditStoreConfig{
+		Path:         cfg.AuditStoreFile,
+		TestStatuses: cfg.AuditTestStatuses,
+	})
+
 	// Initialize WebSocket hub
-	websocket.InitializeHub()
+	retainKeys, err := models.ParseWSRetainRules(cfg.WSRetainMessages)
+	if err != nil {
+		logger.Warn("Ignoring invalid WS_RETAIN_MESSAGES", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	websocket.InitializeHub(websocket.HubConfig{
+		MaxConcurrentWrites: cfg.WSMaxConcurrentWrites,
+		Recorder:            auditStore,
+		RetainKeys:          retainKeys,
+		RetainMaxMessages:   cfg.WSRetainMaxMessages,
+		PingInterval:        time.Duration(cfg.WSPingInterval) * time.Second,
+		PongTimeout:         time.Duration(cfg.WSPongTimeout) * time.Second,
+		SendBufferSize:      cfg.WSSendBufferSize,
+		MaxConsecutiveDrops: cfg.WSMaxConsecutiveDrops,
+	})
+
+	// The audit file is closed after the hub, which records its last broadcasts there
+	recoveryService.RegisterShutdown(func(ctx context.Context) error {
+		logger.Info("Closing audit store...")
+		return auditStore.Close()
+	})
+
+	// Shutdown functions run in reverse order, so registering this early closes WebSocket
+	// connections last, once the services broadcasting over them have stopped
+	recoveryService.RegisterShutdown(func(ctx context.Context) error {
+		logger.Info("Closing WebSocket connections...")
+		hub := websocket.GetHub()
+		err := hub.Flush(ctx)
+		hub.Shutdown()
+		return err
+	})
 
 	// Create Fiber app with configuration
 	app := createFiberApp(cfg, logger, recoveryService)
@@ -56,7 +108,7 @@ func main() {
 	setupMiddleware(app, cfg, logger, recoveryService)
 
 	// Setup routes
-	setupRoutes(app, cfg, logger, recoveryService)
+	setupRoutes(app, cfg, logger, recoveryService, auditStore)
 
 	// Start server with graceful shutdown
 	startServerWithGracefulShutdown(app, cfg, logger, recoveryService)
@@ -89,7 +141,17 @@ func setupMiddleware(app *fiber.App, cfg *config.Config, logger *utils.Logger, r
 	app.Use(middleware.EnhancedErrorHandlingMiddleware(errorConfig))
 
 	// Correlation ID middleware
-	app.Use(middleware.CorrelationID())
+	correlationConfig := middleware.DefaultCorrelationIDConfig()
+	correlationConfig.ResponseHeader = cfg.CorrelationIDHeader
+	if !cfg.EnableCorrelationIDHeader {
+		correlationConfig.ResponseHeader = ""
+	}
+	app.Use(middleware.CorrelationID(correlationConfig))
+
+	// Global concurrency limit, applied before any heavier processing
+	concurrencyConfig := middleware.DefaultConcurrencyLimitConfig()
+	concurrencyConfig.MaxInFlight = int64(cfg.MaxConcurrentRequests)
+	app.Use(middleware.ConcurrencyLimit(concurrencyConfig))
 
 	// CORS middleware
 	corsOrigins := []string{cfg.FrontendURL}
@@ -125,18 +187,89 @@ func setupMiddleware(app *fiber.App, cfg *config.Config, logger *utils.Logger, r
 	// Rate limiting middleware (if enabled)
 	if cfg.EnableRateLimiting {
 		rateLimitConfig := middleware.RateLimitConfig{
-			RequestsPerSecond: 100, // 100 requests per second
-			BurstSize:         20,  // Allow bursts of up to 20 requests
-			SkipPaths:         []string{"/health", "/metrics", "/ws", "/debug"},
+			RequestsPerSecond: float64(cfg.RateLimitPerMinute) / 60,
+			BurstSize:         cfg.RateLimitBurst,
+			SkipPaths:         []string{"/health", "/health/live", "/health/ready", "/metrics", "/ws", "/debug"},
+		}
+		overrides, err := models.ParseRateLimitOverrides(cfg.RateLimitRouteOverrides)
+		if err != nil {
+			logger.Warn("Ignoring invalid RATE_LIMIT_ROUTE_OVERRIDES", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		rateLimitConfig.Overrides = make(map[string]middleware.RateLimitRule, len(overrides))
+		for prefix, rule := range overrides {
+			rateLimitConfig.Overrides[prefix] = middleware.RateLimitRule{
+				RequestsPerSecond: float64(rule.RequestsPerMinute) / 60,
+				BurstSize:         rule.Burst,
+			}
 		}
 		app.Use(middleware.RateLimiting(rateLimitConfig))
 	}
+
+	// JWT authentication of protected routes (if enabled)
+	if cfg.EnableJWTAuth {
+		app.Use(middleware.JWTAuth(jwtAuthConfig(cfg, logger)))
+	} else if !cfg.IsDevelopment() {
+		logger.Warn("ENABLE_JWT_AUTH is false; mutating endpoints can be called without a token")
+	}
+}
+
+// jwtAuthConfig builds the JWT middleware configuration. A public key that can't be loaded is
+// left out, so tokens it would have verified are rejected rather than the routes opened up.
+func jwtAuthConfig(cfg *config.Config, logger *utils.Logger) middleware.JWTAuthConfig {
+	jwtConfig := middleware.JWTAuthConfig{
+		Secret: []byte(cfg.JWTSecret),
+		Leeway: time.Duration(cfg.JWTLeeway) * time.Second,
+	}
+
+	routes, err := models.ParseProtectedRoutes(cfg.JWTProtectedRoutes)
+	if err != nil {
+		logger.Warn("Ignoring invalid JWT_PROTECTED_ROUTES", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	jwtConfig.Routes = routes
+
+	if cfg.JWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err == nil {
+			jwtConfig.PublicKey, err = middleware.ParseRSAPublicKey(data)
+		}
+		if err != nil {
+			logger.Error("Failed to load JWT public key, RS256 tokens will be rejected", err, map[string]interface{}{
+				"path": cfg.JWTPublicKeyFile,
+			})
+		}
+	}
+
+	return jwtConfig
+}
+
+// groupAuth returns the middleware requiring a bearer token on every route under prefix,
+// whatever JWT_PROTECTED_ROUTES lists. Without ENABLE_JWT_AUTH it lets requests through in
+// development and returns nil otherwise, in which case the routes must not be registered.
+func groupAuth(cfg *config.Config, logger *utils.Logger, prefix string) fiber.Handler {
+	switch {
+	case cfg.EnableJWTAuth:
+		jwtConfig := jwtAuthConfig(cfg, logger)
+		jwtConfig.Routes = []models.ProtectedRoute{{Method: "*", Path: prefix + "/*"}}
+		return middleware.JWTAuth(jwtConfig)
+	case cfg.IsDevelopment():
+		return func(c *fiber.Ctx) error { return c.Next() }
+	default:
+		return nil
+	}
 }
 
 // setupRoutes configures all routes for the application
-func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recoveryService *utils.ErrorRecoveryService) {
-	// Enhanced health check endpoint with error recovery
-	app.Get("/health", middleware.HealthCheckErrorHandler(recoveryService))
+func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recoveryService *utils.ErrorRecoveryService, auditStore *services.AuditStore) {
+	// Liveness only shows the process is serving; readiness runs the dependency checks registered
+	// with the recovery service. /health predates the split and stays an alias for readiness.
+	readiness := middleware.HealthCheckErrorHandler(recoveryService)
+	app.Get("/health/live", middleware.LivenessHandler(startTime))
+	app.Get("/health/ready", readiness)
+	app.Get("/health", readiness)
 
 	// Error recovery stats endpoint
 	app.Get("/error-recovery/stats", func(c *fiber.Ctx) error {
@@ -145,8 +278,22 @@ func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recov
 	})
 
 	// WebSocket endpoint
-	app.Use("/ws", websocket.WebSocketUpgrade)
-	app.Get("/ws", websocket2.New(websocket.WebSocketHandler))
+	wsTokens, err := models.ParseWSAuthTokens(cfg.WSAuthTokens)
+	if err != nil {
+		logger.Warn("Ignoring invalid WS_AUTH_TOKENS", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if len(wsTokens) == 0 && !cfg.IsDevelopment() {
+		logger.Warn("WS_AUTH_TOKENS is empty; all WebSocket connections will be rejected")
+	}
+	app.Use("/ws", websocket.WebSocketUpgrade(websocket.UpgradeConfig{
+		Tokens:         wsTokens,
+		AllowAnonymous: cfg.IsDevelopment(),
+	}))
+	app.Get("/ws", websocket2.New(websocket.WebSocketHandler, websocket2.Config{
+		Subprotocols: []string{websocket.TokenSubprotocol},
+	}))
 
 	// WebSocket stats endpoint
 	app.Get("/ws/stats", func(c *fiber.Ctx) error {
@@ -160,14 +307,195 @@ func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recov
 	// Initialize services with WebSocket hub integration and enhanced error handling
 	wsHub := websocket.GetHub()
 	aiService := services.NewAIService(cfg, wsHub, logger)
-	syncService := services.NewSyncService(wsHub)
-	testService := services.NewTestService(cfg, wsHub)
-	logService := services.NewLogService(aiService, wsHub)
+	// Shared so endpoint and sync validations count toward the same per-environment caps
+	validationLimits, err := models.ParseValidationLimits(cfg.SyncValidationLimits)
+	if err != nil {
+		logger.Warn("Ignoring invalid SYNC_VALIDATION_LIMITS", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	validationLimiter := services.NewValidationLimiter(services.ValidationLimiterConfig{
+		DefaultLimit:      cfg.SyncValidationMaxConcurrent,
+		EnvironmentLimits: validationLimits,
+		QueueTimeout:      time.Duration(cfg.SyncValidationQueueTimeout) * time.Second,
+	})
+	syncServiceConfig := services.SyncServiceConfig{
+		HealthPaths:       cfg.SyncHealthPaths,
+		ValidationLimiter: validationLimiter,
+		BodyComparison: services.BodyComparisonLimits{
+			MaxDepth:  cfg.SyncCompareMaxDepth,
+			MaxFields: cfg.SyncCompareMaxFields,
+			MaxDiffs:  cfg.SyncCompareMaxDiffs,
+		},
+		HealthCheckInterval: time.Duration(cfg.SyncHealthCheckInterval) * time.Second,
+		HealthCheckCooldown: time.Duration(cfg.SyncHealthCheckCooldown) * time.Second,
+		HealthCheckRetry:    services.DefaultSyncHealthRetryConfig(),
+		MaxEnvironments:     cfg.SyncMaxEnvironments,
+	}
+	syncServiceConfig.HealthCheckRetry.MaxAttempts = cfg.SyncHealthCheckAttempts
+	syncServiceConfig.HealthCheckRetry.InitialDelay = time.Duration(cfg.SyncHealthCheckRetryDelay) * time.Millisecond
+	if cfg.SyncEnvironmentsFile != "" {
+		environmentStore, err := services.NewFileEnvironmentStore(cfg.SyncEnvironmentsFile)
+		if err != nil {
+			logger.Warn("Sync environments will not be persisted", map[string]interface{}{
+				"file":  cfg.SyncEnvironmentsFile,
+				"error": err.Error(),
+			})
+		} else {
+			syncServiceConfig.Store = environmentStore
+		}
+	}
+	syncService := services.NewSyncService(wsHub, syncServiceConfig)
+	syncService.StartHealthMonitor(context.Background())
+	recoveryService.RegisterShutdown(func(ctx context.Context) error {
+		logger.Info("Stopping sync health monitor...")
+		syncService.Stop()
+		return nil
+	})
+	testServiceConfig := services.TestServiceConfig{
+		ValidationLimiter: validationLimiter,
+		ReaperInterval:    time.Duration(cfg.TestRunReaperInterval) * time.Second,
+
+		ExpectedRunInterval: time.Duration(cfg.TestWatchdogInterval) * time.Second,
+
+		LogStreamRate:   cfg.TestLogStreamRate,
+		LogStreamBuffer: cfg.TestLogStreamBuffer,
+
+		CallbackSecret: cfg.TestCallbackSecret,
+		CallbackRetry:  services.DefaultTestCallbackRetryConfig(),
+
+		MaxSchedules:        cfg.TestMaxSchedules,
+		MinScheduleInterval: time.Duration(cfg.TestScheduleMinInterval) * time.Second,
+
+		MaxArtifactRuns: cfg.TestArtifactsMaxRuns,
+	}
+	testServiceConfig.CallbackRetry.MaxAttempts = cfg.TestCallbackAttempts
+	testServiceConfig.CallbackRetry.InitialDelay = time.Duration(cfg.TestCallbackRetryDelay) * time.Millisecond
+	if cfg.TestHistoryDir != "" {
+		historyStore, err := services.NewFileHistoryStore(cfg.TestHistoryDir, services.FileHistoryStoreConfig{
+			MaxRuns: cfg.TestHistoryMaxRuns,
+		})
+		if err != nil {
+			logger.Warn("Test run history will not be persisted", map[string]interface{}{
+				"dir":   cfg.TestHistoryDir,
+				"error": err.Error(),
+			})
+		} else {
+			testServiceConfig.HistoryStore = historyStore
+		}
+	}
+	if cfg.TestSchedulesFile != "" {
+		scheduleStore, err := services.NewFileScheduleStore(cfg.TestSchedulesFile)
+		if err != nil {
+			logger.Warn("Test schedules will not be persisted", map[string]interface{}{
+				"file":  cfg.TestSchedulesFile,
+				"error": err.Error(),
+			})
+		} else {
+			testServiceConfig.ScheduleStore = scheduleStore
+		}
+	}
+	testService := services.NewTestService(cfg, wsHub, testServiceConfig)
+	// Only the user that started a run may stream its output
+	if wsHub != nil {
+		wsHub.SetTopicAuthorizer(testService.CanSubscribe)
+	}
+	testService.StartRunReaper(context.Background())
+	testService.StartRunWatchdog(context.Background())
+	testService.StartScheduler(context.Background())
+	recoveryService.RegisterShutdown(func(ctx context.Context) error {
+		logger.Info("Cancelling active test runs...")
+		return testService.Shutdown(ctx)
+	})
+	// Submitted levels are validated, and published in the schemas, as configured
+	if len(cfg.LogIngestLevels) > 0 {
+		if err := utils.RegisterEnum("loglevel", cfg.LogIngestLevels); err != nil {
+			logger.Warn("Using the default log levels for validation", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+	logServiceConfig := services.LogServiceConfig{
+		PropagateTraceID: cfg.EnableWSCorrelationID,
+		Levels:           cfg.LogIngestLevels,
+		ErrorLevel:       cfg.LogIngestErrorLevel,
+		MaxAnalysisRange: time.Duration(cfg.LogAnalysisMaxRange) * time.Hour,
+		AlertKeywords:    cfg.LogAlertKeywords,
+		Fingerprints:     cfg.EnableLogFingerprints,
+		FingerprintLevel: cfg.LogFingerprintLevel,
+		IngestionPaused:  cfg.LogIngestionPaused,
+		MaxClockSkew:     time.Duration(cfg.LogMaxClockSkew) * time.Second,
+		MaxAILogs:        cfg.AIAnalysisMaxLogs,
+
+		AlertEscalationAfter: time.Duration(cfg.LogAlertEscalation) * time.Second,
+
+		AutoAnalysis:          cfg.EnableAIAutoAnalysis && cfg.EnableAIFeatures,
+		AutoAnalysisThreshold: cfg.AIAutoAnalysisThreshold,
+		AutoAnalysisWindow:    time.Duration(cfg.AIAutoAnalysisWindowSeconds) * time.Second,
+		Store: services.NewMemoryLogStore(cfg.LogStoreMaxEntries, services.MemoryLogStoreConfig{
+			CompressThreshold: cfg.LogStoreCompressAt,
+		}),
+		StoreRetry: services.DefaultLogStoreRetryConfig(),
+
+		FallbackBufferSize: cfg.LogStoreBufferSize,
+	}
+	logServiceConfig.StoreRetry.MaxAttempts = cfg.LogStoreRetries
+	logServiceConfig.StoreRetry.InitialDelay = time.Duration(cfg.LogStoreRetryDelay) * time.Millisecond
+	if cfg.LogStore == "sqlite" {
+		logStore, err := services.NewSQLiteLogStore(cfg.LogStorePath, cfg.LogStoreMaxEntries)
+		if err != nil {
+			logger.Warn("Submitted logs will be kept in memory only", map[string]interface{}{
+				"path":  cfg.LogStorePath,
+				"error": err.Error(),
+			})
+		} else {
+			logServiceConfig.Store = logStore
+			recoveryService.RegisterShutdown(func(ctx context.Context) error {
+				logger.Info("Closing log store...")
+				return logStore.Close()
+			})
+		}
+	}
+	if cfg.EnableLogIPEnrichment {
+		ipLookup, err := services.LoadCIDRLookup(cfg.LogIPEnrichmentDB)
+		if err != nil {
+			logger.Warn("Log IP enrichment disabled", map[string]interface{}{
+				"path":  cfg.LogIPEnrichmentDB,
+				"error": err.Error(),
+			})
+		} else {
+			logServiceConfig.IPEnricher = services.NewIPEnricher(services.IPEnricherConfig{
+				Lookup:      ipLookup,
+				ContextKeys: cfg.LogIPContextKeys,
+				CacheSize:   cfg.LogIPCacheSize,
+			})
+		}
+	}
+	if cfg.LogAlertEscalationWebhookURL != "" {
+		logServiceConfig.AlertEscalator = services.NewWebhookAlertEscalator(cfg.LogAlertEscalationWebhookURL)
+	}
+	logService := services.NewLogService(aiService, wsHub, logServiceConfig)
+	recoveryService.RegisterShutdown(func(ctx context.Context) error {
+		logger.Info("Flushing buffered logs...")
+		return logService.Flush(ctx)
+	})
+
+	// Dependencies probed by /health/ready; the optional ones only report the server as degraded
+	if wsHub != nil {
+		recoveryService.RegisterHealthCheck("websocket", wsHub.HealthCheck)
+	}
+	recoveryService.RegisterHealthCheck("test_service", testService.HealthCheck)
+	recoveryService.RegisterNonCriticalHealthCheck("ai", aiService.CheckAvailability)
+	recoveryService.RegisterNonCriticalHealthCheck("sync_environments", syncService.HealthCheck)
+	recoveryService.RegisterNonCriticalHealthCheck("log_store", logService.HealthCheck)
 
 	// Initialize handlers
 	aiHandler := handlers.NewAIHandler(aiService)
 	syncHandler := handlers.NewSyncHandler(syncService)
-	testingHandler := handlers.NewTestingHandler(testService)
+	testingHandler := handlers.NewTestingHandler(testService, handlers.TestingHandlerConfig{
+		StrictValidation: cfg.EnableStrictValidation,
+		SyncRunTimeout:   time.Duration(cfg.TestSyncRunTimeout) * time.Second,
+	})
 	loggingHandler := handlers.NewLoggingHandler(logService)
 
 	// Setup AI routes
@@ -185,6 +513,31 @@ func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recov
 	// Setup Performance routes
 	setupPerformanceRoutes(api, logger)
 
+	// Setup Audit routes
+	if auditAuth := groupAuth(cfg, logger, "/api/audit"); auditAuth != nil {
+		setupAuditRoutes(api, auditAuth, handlers.NewAuditHandler(auditStore))
+	} else {
+		logger.Warn("ENABLE_JWT_AUTH is false; audit routes are disabled")
+	}
+
+	// Setup Schema routes
+	setupSchemaRoutes(api, handlers.NewSchemaHandler(cfg.SchemaModels))
+
+	// Setup Admin routes
+	configSnapshots := services.NewConfigSnapshotService(logService, syncService, testService, cfg.FeatureFlags())
+	var chaosHandler *handlers.ChaosHandler
+	if cfg.EnableChaosEndpoints && !cfg.IsProduction() {
+		chaosHandler = handlers.NewChaosHandler(services.NewChaosService(aiService, syncService, logService))
+		logger.Warn("Chaos endpoints enabled, synthetic failures can be injected", map[string]interface{}{
+			"environment": cfg.Environment,
+		})
+	}
+	if adminAuth := groupAuth(cfg, logger, "/api/admin"); adminAuth != nil {
+		setupAdminRoutes(api, adminAuth, handlers.NewAdminHandler(configSnapshots), chaosHandler)
+	} else {
+		logger.Warn("ENABLE_JWT_AUTH is false; admin routes are disabled")
+	}
+
 	// Setup Debug routes (if enabled)
 	if cfg.EnableDebugEndpoints || cfg.IsDevelopment() {
 		setupDebugRoutes(app, cfg, logger)
@@ -197,33 +550,68 @@ func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recov
 			"version":     "1.0.0",
 			"environment": cfg.Environment,
 			"endpoints": []string{
-				"GET /health - Health check",
+				"GET /health - Readiness check, kept for compatibility",
+				"GET /health/live - Liveness check",
+				"GET /health/ready - Readiness check of registered dependencies",
 				"GET /api - API information",
 				"GET /ws - WebSocket connection",
 				"GET /ws/stats - WebSocket statistics",
 				"POST /api/ai/suggestions - Get AI code suggestions",
 				"POST /api/ai/analyze-logs - Analyze logs with AI",
+				"GET /api/ai/usage - Get AI token usage and cost by metadata tag",
+				"GET /api/ai/quota - Get the caller's daily AI quota usage",
+				"POST /api/ai/reset-usage - Reset AI token usage counters",
+				"POST /api/ai/feedback - Rate an AI suggestion as helpful or unhelpful",
+				"DELETE /api/ai/requests/:requestId - Cancel an in-flight AI request",
 				"GET /api/ai/status - Get AI service status",
 				"GET /api/ai/health - AI service health check",
 				"POST /api/sync/connect - Connect to sync environment",
 				"GET /api/sync/status - Get sync status",
 				"POST /api/sync/validate - Validate endpoint compatibility",
+				"POST /api/sync/contracts - Store an expected response contract for an endpoint",
+				"POST /api/sync/validate-contract - Validate a live endpoint against a stored contract",
 				"GET /api/sync/environments - Get all environments",
 				"DELETE /api/sync/environments/:name - Remove environment",
+				"POST /api/sync/environments/:name/check - Re-check an environment's health now",
 				"POST /api/testing/run - Trigger test execution",
+				"POST /api/testing/run-sync - Run tests and wait for the results",
 				"GET /api/testing/results/:runId - Get test results",
+				"GET /api/testing/results/:runId/junit - Export test results as JUnit XML",
+				"GET /api/testing/results/:runId/artifacts/:name - Download a screenshot, video or trace kept from a test run",
+				"POST /api/testing/workflows - Run a workflow of dependent test runs",
+				"GET /api/testing/workflows/:workflowId - Get test workflow progress and results",
 				"POST /api/testing/validate-sync - Validate API-UI synchronization",
 				"GET /api/testing/active - Get active test runs",
 				"GET /api/testing/history - Get test run history",
+				"GET /api/testing/export - Download finished runs in a time range as a zip archive",
 				"DELETE /api/testing/runs/:runId - Cancel test run",
+				"POST /api/testing/runs/:runId/rerun - Re-run a test run",
+				"POST /api/testing/schedules - Schedule recurring test runs with a cron expression",
+				"GET /api/testing/schedules - List test schedules",
+				"DELETE /api/testing/schedules/:id - Delete a test schedule",
 				"GET /api/testing/status - Get testing service status",
+				"GET /api/testing/frameworks - Get installed test framework versions",
 				"GET /api/testing/health - Testing service health check",
 				"POST /api/logs/submit - Submit log entries",
 				"GET /api/logs/analyze - Analyze logs and detect patterns",
+				"GET /api/logs/export - Download filtered logs as CSV or NDJSON",
+				"POST /api/logs/reports - Save a log analysis as a shareable report",
+				"GET /api/logs/reports/:id - Get a saved log analysis report (JSON or HTML)",
 				"GET /api/logs/stats - Get log statistics",
+				"GET /api/logs/fingerprints - List error fingerprints with counts and first/last seen",
 				"DELETE /api/logs/clear - Clear all logs",
+				"POST /api/logs/pause - Pause log ingestion, e.g. for log store maintenance",
+				"POST /api/logs/resume - Resume paused log ingestion",
+				"POST /api/logs/alert-rules - Add or remove a critical-log keyword rule",
+				"POST /api/logs/alerts/:id/ack - Acknowledge a critical log alert so it isn't escalated",
 				"GET /api/logs/status - Get logging service status",
 				"GET /api/logs/health - Logging service health check",
+				"GET /api/audit/events - Get recorded broadcasts such as alerts and test results",
+				"GET /api/schema - List request models with a JSON Schema",
+				"GET /api/schema/:model - Get the JSON Schema of a request model",
+				"GET /api/admin/export-config - Export alert rules, contracts and other runtime settings",
+				"POST /api/admin/import-config - Apply a configuration snapshot from another server",
+				"POST /api/admin/chaos - Inject a synthetic failure (development/staging only)",
 				"GET /api/performance/metrics - Get performance metrics",
 				"GET /api/performance/memory - Get memory statistics",
 				"GET /api/performance/pools - Get connection pool statistics",
@@ -239,6 +627,8 @@ func setupRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger, recov
 
 	logger.Info("Routes configured successfully", map[string]interface{}{
 		"health_endpoint":    "/health",
+		"liveness_endpoint":  "/health/live",
+		"readiness_endpoint": "/health/ready",
 		"api_base":           "/api",
 		"websocket_endpoint": "/ws",
 	})
@@ -253,6 +643,11 @@ func setupAIRoutes(api fiber.Router, aiHandler *handlers.AIHandler) {
 	ai.Post("/suggestions", aiHandler.GetCodeSuggestions)
 	ai.Post("/analyze-logs", aiHandler.AnalyzeLogs)
 	ai.Get("/status", aiHandler.GetAIStatus)
+	ai.Get("/usage", aiHandler.GetUsage)
+	ai.Get("/quota", aiHandler.GetQuota)
+	ai.Post("/reset-usage", aiHandler.ResetUsage)
+	ai.Post("/feedback", aiHandler.SubmitFeedback)
+	ai.Delete("/requests/:requestId", aiHandler.CancelRequest)
 	ai.Get("/health", aiHandler.HealthCheck)
 }
 
@@ -265,8 +660,11 @@ func setupSyncRoutes(api fiber.Router, syncHandler *handlers.SyncHandler) {
 	sync.Post("/connect", syncHandler.ConnectEnvironment)
 	sync.Get("/status", syncHandler.GetSyncStatus)
 	sync.Post("/validate", syncHandler.ValidateEndpoint)
+	sync.Post("/contracts", syncHandler.StoreContract)
+	sync.Post("/validate-contract", syncHandler.ValidateContract)
 	sync.Get("/environments", syncHandler.GetEnvironments)
 	sync.Delete("/environments/:name", syncHandler.RemoveEnvironment)
+	sync.Post("/environments/:name/check", syncHandler.CheckEnvironment)
 }
 
 // setupTestingRoutes configures testing-related routes
@@ -276,14 +674,25 @@ func setupTestingRoutes(api fiber.Router, testingHandler *handlers.TestingHandle
 
 	// Core testing endpoints
 	testing.Post("/run", testingHandler.RunTests)
+	testing.Post("/run-sync", testingHandler.RunTestsSync)
 	testing.Get("/results/:runId", testingHandler.GetTestResults)
+	testing.Get("/results/:runId/junit", testingHandler.GetTestResultsJUnit)
+	testing.Get("/results/:runId/artifacts/:name", testingHandler.GetTestArtifact)
+	testing.Post("/workflows", testingHandler.StartWorkflow)
+	testing.Get("/workflows/:workflowId", testingHandler.GetWorkflow)
 	testing.Post("/validate-sync", testingHandler.ValidateSync)
 
 	// Additional testing endpoints
 	testing.Get("/active", testingHandler.GetActiveRuns)
 	testing.Get("/history", testingHandler.GetRunHistory)
+	testing.Get("/export", testingHandler.ExportRunHistory)
 	testing.Delete("/runs/:runId", testingHandler.CancelTestRun)
+	testing.Post("/runs/:runId/rerun", testingHandler.RerunTestRun)
+	testing.Post("/schedules", testingHandler.CreateSchedule)
+	testing.Get("/schedules", testingHandler.ListSchedules)
+	testing.Delete("/schedules/:id", testingHandler.DeleteSchedule)
 	testing.Get("/status", testingHandler.GetTestingStatus)
+	testing.Get("/frameworks", testingHandler.GetFrameworks)
 	testing.Get("/health", testingHandler.HealthCheck)
 }
 
@@ -295,12 +704,47 @@ func setupLoggingRoutes(api fiber.Router, loggingHandler *handlers.LoggingHandle
 	// Core logging endpoints
 	logs.Post("/submit", loggingHandler.SubmitLogs)
 	logs.Get("/analyze", loggingHandler.AnalyzeLogs)
+	logs.Get("/export", loggingHandler.ExportLogs)
+	logs.Post("/reports", loggingHandler.CreateAnalysisReport)
+	logs.Get("/reports/:id", loggingHandler.GetAnalysisReport)
 	logs.Get("/stats", loggingHandler.GetLogStats)
+	logs.Get("/fingerprints", loggingHandler.GetFingerprints)
 	logs.Delete("/clear", loggingHandler.ClearLogs)
+	logs.Post("/pause", loggingHandler.PauseIngestion)
+	logs.Post("/resume", loggingHandler.ResumeIngestion)
+	logs.Post("/alert-rules", loggingHandler.UpdateAlertRules)
+	logs.Post("/alerts/:id/ack", loggingHandler.AcknowledgeAlert)
 	logs.Get("/status", loggingHandler.GetLoggingStatus)
 	logs.Get("/health", loggingHandler.HealthCheck)
 }
 
+// setupAuditRoutes configures audit trail routes, each requiring auth
+func setupAuditRoutes(api fiber.Router, auth fiber.Handler, auditHandler *handlers.AuditHandler) {
+	audit := api.Group("/audit", auth)
+
+	audit.Get("/events", auditHandler.GetEvents)
+}
+
+// setupSchemaRoutes configures request model schema routes
+func setupSchemaRoutes(api fiber.Router, schemaHandler *handlers.SchemaHandler) {
+	schema := api.Group("/schema")
+
+	schema.Get("/", schemaHandler.ListSchemas)
+	schema.Get("/:model", schemaHandler.GetSchema)
+}
+
+// setupAdminRoutes configures server administration routes, each requiring auth. The chaos
+// route is only registered when chaosHandler is set.
+func setupAdminRoutes(api fiber.Router, auth fiber.Handler, adminHandler *handlers.AdminHandler, chaosHandler *handlers.ChaosHandler) {
+	admin := api.Group("/admin", auth)
+
+	admin.Get("/export-config", adminHandler.ExportConfig)
+	admin.Post("/import-config", adminHandler.ImportConfig)
+	if chaosHandler != nil {
+		admin.Post("/chaos", chaosHandler.InjectFailure)
+	}
+}
+
 // setupPerformanceRoutes configures performance monitoring routes
 func setupPerformanceRoutes(api fiber.Router, logger *utils.Logger) {
 	// Performance routes group
@@ -336,6 +780,7 @@ func setupDebugRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger)
 	debug.Get("/system", debugHandler.GetSystemInfo)
 	debug.Get("/features", debugHandler.GetFeatureToggles)
 	debug.Get("/health", debugHandler.GetHealthChecks)
+	debug.Put("/log-format", debugHandler.SetLogFormat)
 
 	logger.Info("Debug endpoints enabled", map[string]interface{}{
 		"base_path": "/debug",
@@ -346,6 +791,7 @@ func setupDebugRoutes(app *fiber.App, cfg *config.Config, logger *utils.Logger)
 			"/debug/system",
 			"/debug/features",
 			"/debug/health",
+			"/debug/log-format",
 		},
 	})
 }
@@ -443,12 +889,6 @@ func startServerWithGracefulShutdown(app *fiber.App, cfg *config.Config, logger
 		return app.ShutdownWithContext(ctx)
 	})
 
-	recoveryService.RegisterShutdown(func(ctx context.Context) error {
-		logger.Info("Closing WebSocket connections...")
-		websocket.GetHub().Shutdown()
-		return nil
-	})
-
 	// Register health checks
 	recoveryService.RegisterHealthCheck("server", func(ctx context.Context) error {
 		// Basic server health check
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/handlers"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/services"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -99,7 +103,7 @@ func TestSetupRoutes(t *testing.T) {
 	recoveryService := utils.NewErrorRecoveryService(logger)
 
 	app := fiber.New()
-	setupRoutes(app, cfg, logger, recoveryService)
+	setupRoutes(app, cfg, logger, recoveryService, services.NewAuditStore(nil, 0))
 
 	// Test health endpoint
 	req, err := http.NewRequest("GET", "/health", nil)
@@ -129,6 +133,37 @@ func TestSetupRoutes(t *testing.T) {
 	assert.Contains(t, string(body), "endpoints")
 }
 
+// TestSetupRoutes_HealthProbes tests that liveness ignores dependency checks while readiness and
+// its /health alias report them
+func TestSetupRoutes_HealthProbes(t *testing.T) {
+	cfg := &config.Config{
+		Environment: "test",
+		Port:        "8080",
+	}
+	logger := utils.GetLogger()
+	recoveryService := utils.NewErrorRecoveryService(logger)
+
+	app := fiber.New()
+	setupRoutes(app, cfg, logger, recoveryService, services.NewAuditStore(nil, 0))
+	recoveryService.RegisterHealthCheck("database", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	for path, expected := range map[string]int{
+		"/health/live":  http.StatusOK,
+		"/health/ready": http.StatusServiceUnavailable,
+		"/health":       http.StatusServiceUnavailable,
+	} {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, expected, resp.StatusCode, path)
+	}
+}
+
 // TestErrorHandler tests the custom error handler
 func TestErrorHandler(t *testing.T) {
 	logger := utils.GetLogger()
@@ -173,7 +208,7 @@ func TestServerConfiguration(t *testing.T) {
 
 	app := createFiberApp(cfg, logger, recoveryService)
 	setupMiddleware(app, cfg, logger, recoveryService)
-	setupRoutes(app, cfg, logger, recoveryService)
+	setupRoutes(app, cfg, logger, recoveryService, services.NewAuditStore(nil, 0))
 
 	// Test that the app is properly configured
 	assert.NotNil(t, app)
@@ -215,3 +250,45 @@ func BenchmarkHealthCheck(b *testing.B) {
 		resp.Body.Close()
 	}
 }
+
+// TestSetupAdminRoutes_RequireToken tests that admin routes need a bearer token whatever
+// JWT_PROTECTED_ROUTES lists, and are left out without JWT auth outside development
+func TestSetupAdminRoutes_RequireToken(t *testing.T) {
+	logger := utils.GetLogger()
+
+	cfg := &config.Config{Environment: "production", EnableJWTAuth: true, JWTSecret: "secret"}
+	app := fiber.New()
+	setupAdminRoutes(app.Group("/api"), groupAuth(cfg, logger, "/api/admin"), handlers.NewAdminHandler(nil), nil)
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/admin/export-config"},
+		{"POST", "/api/admin/import-config"},
+		{"POST", "/API/Admin/import-config"},
+	} {
+		req, err := http.NewRequest(route.method, route.path, nil)
+		require.NoError(t, err)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "%s %s", route.method, route.path)
+	}
+
+	assert.Nil(t, groupAuth(&config.Config{Environment: "production"}, logger, "/api/admin"))
+	assert.NotNil(t, groupAuth(&config.Config{Environment: "development"}, logger, "/api/admin"))
+}
+
+// TestSetupAuditRoutes_RequireToken tests that audit events need a bearer token
+func TestSetupAuditRoutes_RequireToken(t *testing.T) {
+	cfg := &config.Config{Environment: "production", EnableJWTAuth: true, JWTSecret: "secret"}
+	app := fiber.New()
+	setupAuditRoutes(app.Group("/api"), groupAuth(cfg, utils.GetLogger(), "/api/audit"), handlers.NewAuditHandler(services.NewAuditStore(nil, 0)))
+
+	for _, path := range []string{"/api/audit/events", "/API/Audit/events"} {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, path)
+	}
+}
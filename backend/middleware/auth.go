@@ -263,6 +263,3 @@ func NewServiceUnavailableError(service string) ApplicationError {
 	}
 	return NewCustomError("SERVICE_UNAVAILABLE", message, fiber.StatusServiceUnavailable, nil)
 }
-
-// Authentication middleware (future implementation)
-// This will be implemented in a later task when authentication is needed
@@ -38,11 +38,15 @@ func DefaultCORSConfig() CORSConfig {
 			"X-Requested-With",
 			"X-Trace-ID",
 			"X-Request-ID",
+			"X-Correlation-ID",
+			"X-API-Key",
 		},
 		AllowCredentials: true,
 		ExposeHeaders: []string{
 			"X-Trace-ID",
 			"X-Request-ID",
+			"X-Correlation-ID",
+			"X-Log-Worst-Severity",
 		},
 		MaxAge: 86400, // 24 hours
 	}
@@ -381,26 +381,35 @@ func createEnhancedErrorResponse(code, message, traceID string, config *Enhanced
 	}
 }
 
-// HealthCheckErrorHandler creates a specialized error handler for health checks
+// LivenessHandler answers the liveness probe. It touches no dependency, so it keeps answering
+// while they are down; only a process too stuck to serve requests fails it.
+func LivenessHandler(startedAt time.Time) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return utils.SuccessResponse(c, "Server is alive", map[string]interface{}{
+			"status": "alive",
+			"uptime": time.Since(startedAt).Round(time.Second).String(),
+		})
+	}
+}
+
+// HealthCheckErrorHandler creates a specialized error handler for health checks, serving the
+// readiness probe. Every registered check is run and reported; the response is a 503 only when a
+// critical check fails, so a failing optional dependency reports the server as degraded without
+// taking it out of rotation.
 func HealthCheckErrorHandler(recoveryService *utils.ErrorRecoveryService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Perform health checks
 		ctx := context.WithValue(c.Context(), "trace_id", utils.GetTraceID(c))
-		healthResults := recoveryService.PerformHealthChecks(ctx)
-
-		// Check if any health checks failed
-		hasFailures := false
-		for _, err := range healthResults {
-			if err != nil {
-				hasFailures = true
-				break
-			}
-		}
+		report := recoveryService.RunHealthChecks(ctx)
 
-		if hasFailures {
-			return utils.ServiceUnavailableResponse(c, "Health check failures detected")
+		if !report.Healthy() {
+			return utils.ErrorResponseWithData(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE",
+				"Health check failures detected", report)
+		}
+		if report.Status == utils.HealthStatusDegraded {
+			return utils.SuccessResponse(c, "Health checks passed with degraded dependencies", report)
 		}
 
-		return utils.SuccessResponse(c, "Health checks passed", healthResults)
+		return utils.SuccessResponse(c, "Health checks passed", report)
 	}
 }
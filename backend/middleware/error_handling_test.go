@@ -2,11 +2,13 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
@@ -304,6 +306,21 @@ func TestEnhancedErrorHandlingMiddleware_DisabledDetailedErrors(t *testing.T) {
 	assert.NotContains(t, bodyStr, "original_error")
 }
 
+func TestLivenessHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/health/live", LivenessHandler(time.Now().Add(-time.Minute)))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/live", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "alive", data["status"])
+	assert.Equal(t, "1m0s", data["uptime"])
+}
+
 func TestHealthCheckErrorHandler_Success(t *testing.T) {
 	recoveryService := utils.NewErrorRecoveryService(nil)
 	recoveryService.RegisterHealthCheck("test", func(ctx context.Context) error {
@@ -348,6 +365,51 @@ func TestHealthCheckErrorHandler_Failure(t *testing.T) {
 	assert.Contains(t, bodyStr, "Health check failures detected")
 }
 
+func TestHealthCheckErrorHandler_Report(t *testing.T) {
+	request := func(recoveryService *utils.ErrorRecoveryService) (int, map[string]interface{}) {
+		app := fiber.New()
+		app.Get("/health", HealthCheckErrorHandler(recoveryService))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/health", nil))
+		require.NoError(t, err)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body["data"].(map[string]interface{})
+	}
+
+	t.Run("failing non-critical check is reported as degraded", func(t *testing.T) {
+		recoveryService := utils.NewErrorRecoveryService(nil)
+		recoveryService.RegisterHealthCheck("websocket", func(ctx context.Context) error { return nil })
+		recoveryService.RegisterNonCriticalHealthCheck("ai", func(ctx context.Context) error {
+			return errors.New("AI service is unavailable: no_api_key")
+		})
+
+		status, report := request(recoveryService)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, utils.HealthStatusDegraded, report["status"])
+		checks := report["checks"].(map[string]interface{})
+		ai := checks["ai"].(map[string]interface{})
+		assert.Equal(t, utils.HealthCheckFailed, ai["status"])
+		assert.Equal(t, false, ai["critical"])
+		assert.Equal(t, "AI service is unavailable: no_api_key", ai["error"])
+		assert.Equal(t, utils.HealthCheckOK, checks["websocket"].(map[string]interface{})["status"])
+	})
+
+	t.Run("failing critical check returns the report with a 503", func(t *testing.T) {
+		recoveryService := utils.NewErrorRecoveryService(nil)
+		recoveryService.RegisterHealthCheck("websocket", func(ctx context.Context) error {
+			return errors.New("WebSocket hub is not responding")
+		})
+
+		status, report := request(recoveryService)
+		assert.Equal(t, fiber.StatusServiceUnavailable, status)
+		assert.Equal(t, utils.HealthStatusUnhealthy, report["status"])
+		websocket := report["checks"].(map[string]interface{})["websocket"].(map[string]interface{})
+		assert.Equal(t, true, websocket["critical"])
+		assert.Equal(t, "WebSocket hub is not responding", websocket["error"])
+	})
+}
+
 func TestCategorizeError_FiberErrors(t *testing.T) {
 	testCases := []struct {
 		statusCode   int
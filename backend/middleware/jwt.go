@@ -0,0 +1,279 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // Registers the hashes used by jwtAlgorithms
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	// ErrTokenMissing is returned when a protected request has no bearer token
+	ErrTokenMissing = errors.New("bearer token is missing")
+	// ErrTokenInvalid is returned for tokens that are malformed or whose signature doesn't verify
+	ErrTokenInvalid = errors.New("token is invalid")
+	// ErrTokenExpired is returned for tokens past their exp claim or before their nbf claim
+	ErrTokenExpired = errors.New("token is expired or not yet valid")
+)
+
+// jwtClaimsLocal is the c.Locals key holding the verified claims
+const jwtClaimsLocal = "jwt_claims"
+
+// JWTClaims are the claims of a verified token
+type JWTClaims map[string]interface{}
+
+// Subject returns the token's sub claim, or "" if it has none
+func (c JWTClaims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+// JWTAuthConfig holds JWT authentication middleware configuration. Tokens are verified with
+// Secret (HS256, HS384, HS512) or PublicKey (RS256, RS384, RS512); a token signed with any
+// other algorithm is rejected.
+type JWTAuthConfig struct {
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+	// Routes requiring a token; requests to any other route pass through untouched
+	Routes []models.ProtectedRoute
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+	// Now returns the current time; nil uses time.Now
+	Now func() time.Time
+}
+
+// jwtAlgorithms maps the supported alg header values to their hash
+var jwtAlgorithms = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+// JWTAuth creates a middleware requiring a valid bearer token on the configured routes. The
+// verified claims are stored in c.Locals, see GetJWTClaims. Requests with a missing or invalid
+// token are rejected with 401.
+func JWTAuth(config JWTAuthConfig) fiber.Handler {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !config.protects(c.Method(), c.Path()) {
+			return c.Next()
+		}
+
+		claims, err := config.verify(bearerToken(c))
+		if err != nil {
+			utils.GetLogger().WithTraceID(utils.GetTraceID(c)).Warn("Rejected unauthenticated request", map[string]interface{}{
+				"method": c.Method(),
+				"path":   c.Path(),
+				"ip":     c.IP(),
+				"error":  err.Error(),
+			})
+
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+			return utils.ErrorResponse(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "A valid bearer token is required", map[string]string{
+				"reason": tokenErrorReason(err),
+			})
+		}
+
+		c.Locals(jwtClaimsLocal, claims)
+		return c.Next()
+	}
+}
+
+// GetJWTClaims returns the claims of the token the request was authenticated with
+func GetJWTClaims(c *fiber.Ctx) (JWTClaims, bool) {
+	claims, ok := c.Locals(jwtClaimsLocal).(JWTClaims)
+	return claims, ok
+}
+
+// ParseRSAPublicKey parses a PEM encoded RSA public key, either PKIX or PKCS #1
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+	return key, nil
+}
+
+// protects reports whether a request to path with method requires a token
+func (config JWTAuthConfig) protects(method, path string) bool {
+	for _, route := range config.Routes {
+		if (route.Method == "*" || route.Method == method) && routeMatches(route.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeMatches reports whether path matches pattern, segment by segment. Segments are compared
+// case-insensitively, as Fiber routes them.
+func routeMatches(pattern, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range patternSegments {
+		if segment == "*" && i == len(patternSegments)-1 {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if !strings.EqualFold(segment, pathSegments[i]) {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// bearerToken returns the token from the Authorization header, or "" if there is none
+func bearerToken(c *fiber.Ctx) string {
+	scheme, token, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// verify checks a token's signature and time claims and returns its claims
+func (config JWTAuthConfig) verify(token string) (JWTClaims, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 segments, got %d", ErrTokenInvalid, len(parts))
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrTokenInvalid, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrTokenInvalid, err)
+	}
+	if err := config.verifySignature(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims JWTClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrTokenInvalid, err)
+	}
+
+	now := config.Now()
+	if exp, ok, err := numericDate(claims, "exp"); err != nil {
+		return nil, err
+	} else if ok && !now.Before(exp.Add(config.Leeway)) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrTokenExpired, exp.Format(time.RFC3339))
+	}
+	if nbf, ok, err := numericDate(claims, "nbf"); err != nil {
+		return nil, err
+	} else if ok && now.Add(config.Leeway).Before(nbf) {
+		return nil, fmt.Errorf("%w: not valid before %s", ErrTokenExpired, nbf.Format(time.RFC3339))
+	}
+
+	return claims, nil
+}
+
+// verifySignature checks signature against the signing input using the configured key for alg.
+// The algorithm must match the kind of key configured, so a token can't pick HMAC to have the
+// public key used as its secret.
+func (config JWTAuthConfig) verifySignature(alg, signingInput string, signature []byte) error {
+	hash, supported := jwtAlgorithms[alg]
+	if !supported {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrTokenInvalid, alg)
+	}
+
+	switch {
+	case strings.HasPrefix(alg, "HS") && len(config.Secret) > 0:
+		mac := hmac.New(hash.New, config.Secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("%w: signature mismatch", ErrTokenInvalid)
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS") && config.PublicKey != nil:
+		digest := hash.New()
+		digest.Write([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(config.PublicKey, hash, digest.Sum(nil), signature); err != nil {
+			return fmt.Errorf("%w: signature mismatch", ErrTokenInvalid)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: no key configured for algorithm %q", ErrTokenInvalid, alg)
+	}
+}
+
+// decodeSegment decodes a base64url encoded JSON segment into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericDate reads a NumericDate claim, reporting false if the claim is absent
+func numericDate(claims JWTClaims, name string) (time.Time, bool, error) {
+	value, exists := claims[name]
+	if !exists {
+		return time.Time{}, false, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %s claim must be a number", ErrTokenInvalid, name)
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true, nil
+}
+
+// tokenErrorReason is the machine-readable reason a token was rejected
+func tokenErrorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenMissing):
+		return "missing_token"
+	case errors.Is(err, ErrTokenExpired):
+		return "token_expired"
+	default:
+		return "invalid_token"
+	}
+}
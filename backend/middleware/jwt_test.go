@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+// signTestJWT builds a token with the given header algorithm, signing it with sign
+func signTestJWT(t *testing.T, alg string, claims map[string]interface{}, sign func(input []byte) []byte) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256Signer(secret []byte) func(input []byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+// newJWTTestApp protects POST /api/logs/submit and DELETE /api/sync/environments/:name, and
+// echoes the token's subject
+func newJWTTestApp(config JWTAuthConfig) *fiber.App {
+	config.Routes = []models.ProtectedRoute{
+		{Method: "POST", Path: "/api/logs/submit"},
+		{Method: "DELETE", Path: "/api/sync/environments/:name"},
+	}
+
+	app := fiber.New()
+	app.Use(JWTAuth(config))
+	handler := func(c *fiber.Ctx) error {
+		claims, ok := GetJWTClaims(c)
+		if !ok {
+			return c.SendString("anonymous")
+		}
+		return c.SendString(claims.Subject())
+	}
+	app.Post("/api/logs/submit", handler)
+	app.Get("/api/logs/stats", handler)
+	app.Delete("/api/sync/environments/:name", handler)
+	app.Get("/health", handler)
+	return app
+}
+
+// doJWTRequest sends a request with token as its bearer token, returning the status and either
+// the body or the error response's reason
+func doJWTRequest(t *testing.T, app *fiber.App, method, path, token string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	assert.Equal(t, `Bearer realm="api"`, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+	var response utils.StandardResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, "UNAUTHORIZED", response.Error.Code)
+	return resp.StatusCode, response.Error.Details["reason"]
+}
+
+func TestJWTAuth_HMAC(t *testing.T) {
+	now := time.Now()
+	app := newJWTTestApp(JWTAuthConfig{Secret: testJWTSecret, Now: func() time.Time { return now }})
+	sign := hs256Signer(testJWTSecret)
+	valid := signTestJWT(t, "HS256", map[string]interface{}{"sub": "alice", "exp": now.Add(time.Hour).Unix()}, sign)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+		expected       string
+	}{
+		{name: "valid token", method: "POST", path: "/api/logs/submit", token: valid, expectedStatus: 200, expected: "alice"},
+		{name: "route parameters match", method: "DELETE", path: "/api/sync/environments/staging", token: valid, expectedStatus: 200, expected: "alice"},
+		{name: "missing token", method: "POST", path: "/api/logs/submit", expectedStatus: 401, expected: "missing_token"},
+		{name: "path in another case", method: "POST", path: "/API/Logs/Submit", expectedStatus: 401, expected: "missing_token"},
+		{
+			name:           "expired token",
+			method:         "POST",
+			path:           "/api/logs/submit",
+			token:          signTestJWT(t, "HS256", map[string]interface{}{"sub": "alice", "exp": now.Add(-time.Minute).Unix()}, sign),
+			expectedStatus: 401,
+			expected:       "token_expired",
+		},
+		{
+			name:           "token not yet valid",
+			method:         "POST",
+			path:           "/api/logs/submit",
+			token:          signTestJWT(t, "HS256", map[string]interface{}{"sub": "alice", "nbf": now.Add(time.Minute).Unix()}, sign),
+			expectedStatus: 401,
+			expected:       "token_expired",
+		},
+		{
+			name:           "tampered claims",
+			method:         "POST",
+			path:           "/api/logs/submit",
+			token:          tamperClaims(t, valid, map[string]interface{}{"sub": "mallory", "exp": now.Add(time.Hour).Unix()}),
+			expectedStatus: 401,
+			expected:       "invalid_token",
+		},
+		{
+			name:           "wrong secret",
+			method:         "POST",
+			path:           "/api/logs/submit",
+			token:          signTestJWT(t, "HS256", map[string]interface{}{"sub": "alice"}, hs256Signer([]byte("other-secret"))),
+			expectedStatus: 401,
+			expected:       "invalid_token",
+		},
+		{
+			name:           "unsigned token",
+			method:         "POST",
+			path:           "/api/logs/submit",
+			token:          signTestJWT(t, "none", map[string]interface{}{"sub": "alice"}, func([]byte) []byte { return nil }),
+			expectedStatus: 401,
+			expected:       "invalid_token",
+		},
+		{name: "malformed token", method: "POST", path: "/api/logs/submit", token: "not-a-jwt", expectedStatus: 401, expected: "invalid_token"},
+		{name: "unprotected route", method: "GET", path: "/api/logs/stats", expectedStatus: 200, expected: "anonymous"},
+		{name: "unprotected method", method: "GET", path: "/health", expectedStatus: 200, expected: "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := doJWTRequest(t, app, tt.method, tt.path, tt.token)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestJWTAuth_Leeway(t *testing.T) {
+	now := time.Now()
+	token := signTestJWT(t, "HS256", map[string]interface{}{"sub": "alice", "exp": now.Add(-10 * time.Second).Unix()}, hs256Signer(testJWTSecret))
+
+	app := newJWTTestApp(JWTAuthConfig{Secret: testJWTSecret, Leeway: 30 * time.Second, Now: func() time.Time { return now }})
+	status, subject := doJWTRequest(t, app, "POST", "/api/logs/submit", token)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "alice", subject)
+}
+
+func TestJWTAuth_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	publicKey, err := ParseRSAPublicKey(publicPEM)
+	require.NoError(t, err)
+	app := newJWTTestApp(JWTAuthConfig{PublicKey: publicKey})
+
+	rs256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+
+	status, subject := doJWTRequest(t, app, "POST", "/api/logs/submit", signTestJWT(t, "RS256", map[string]interface{}{"sub": "ci"}, rs256))
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "ci", subject)
+
+	// A token must not be able to switch to HMAC and have the public key used as its secret
+	status, reason := doJWTRequest(t, app, "POST", "/api/logs/submit", signTestJWT(t, "HS256", map[string]interface{}{"sub": "ci"}, hs256Signer(publicPEM)))
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "invalid_token", reason)
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := ParseRSAPublicKey([]byte("not a key"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		expected      bool
+	}{
+		{"/api/logs/submit", "/api/logs/submit", true},
+		{"/api/logs/submit", "/api/logs/submit/", true},
+		{"/api/logs/submit", "/API/Logs/Submit", true},
+		{"/api/logs/submit", "/api/logs", false},
+		{"/api/logs/submit", "/api/logs/submit/extra", false},
+		{"/api/sync/environments/:name", "/api/sync/environments/staging", true},
+		{"/api/sync/environments/:name", "/api/sync/environments", false},
+		{"/api/testing/*", "/api/testing/runs/123", true},
+		{"/api/testing/*", "/api/logs/submit", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, routeMatches(tt.pattern, tt.path), "%s against %s", tt.path, tt.pattern)
+	}
+}
+
+// tamperClaims replaces a token's claims while keeping its original signature
+func tamperClaims(t *testing.T, token string, claims map[string]interface{}) string {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
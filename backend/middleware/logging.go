@@ -22,7 +22,7 @@ type LoggingConfig struct {
 func DefaultLoggingConfig() LoggingConfig {
 	return LoggingConfig{
 		Logger:          utils.GetLogger(),
-		SkipPaths:       []string{"/health", "/metrics"},
+		SkipPaths:       []string{"/health", "/health/live", "/health/ready", "/metrics"},
 		SkipSuccessLogs: false,
 		LogRequestBody:  false,
 		LogResponseBody: false,
@@ -43,8 +43,11 @@ func RequestLogging(config ...LoggingConfig) fiber.Handler {
 			return c.Next()
 		}
 
-		// Generate or get trace ID
-		traceID := c.Get("X-Trace-ID")
+		// Reuse the trace ID from CorrelationID when it ran first, so every header agrees
+		traceID, _ := c.Locals("trace_id").(string)
+		if traceID == "" {
+			traceID = c.Get("X-Trace-ID")
+		}
 		if traceID == "" {
 			traceID = uuid.New().String()
 		}
@@ -54,7 +57,10 @@ func RequestLogging(config ...LoggingConfig) fiber.Handler {
 		c.Set("X-Trace-ID", traceID)
 
 		// Generate request ID
-		requestID := uuid.New().String()
+		requestID, _ := c.Locals("request_id").(string)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
 		c.Locals("request_id", requestID)
 		c.Set("X-Request-ID", requestID)
 
@@ -77,16 +83,41 @@ func RequestLogging(config ...LoggingConfig) fiber.Handler {
 	}
 }
 
+// CorrelationIDConfig holds correlation ID middleware configuration
+type CorrelationIDConfig struct {
+	// ResponseHeader echoes the trace ID back to clients under this name (also accepted on
+	// incoming requests); empty disables it
+	ResponseHeader string
+}
+
+// DefaultCorrelationIDConfig returns default correlation ID configuration
+func DefaultCorrelationIDConfig() CorrelationIDConfig {
+	return CorrelationIDConfig{
+		ResponseHeader: "X-Correlation-ID",
+	}
+}
+
 // CorrelationID creates a middleware that ensures correlation IDs are present
-func CorrelationID() fiber.Handler {
+func CorrelationID(config ...CorrelationIDConfig) fiber.Handler {
+	cfg := DefaultCorrelationIDConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
 	return func(c *fiber.Ctx) error {
-		// Generate or get trace ID
+		// Generate or get trace ID, accepting the client's correlation ID if that's all it sent
 		traceID := c.Get("X-Trace-ID")
+		if traceID == "" && cfg.ResponseHeader != "" {
+			traceID = c.Get(cfg.ResponseHeader)
+		}
 		if traceID == "" {
 			traceID = uuid.New().String()
 		}
 		// Always set the response header
 		c.Set("X-Trace-ID", traceID)
+		if cfg.ResponseHeader != "" {
+			c.Set(cfg.ResponseHeader, traceID)
+		}
 
 		// Generate request ID
 		requestID := c.Get("X-Request-ID")
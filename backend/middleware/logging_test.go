@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -120,6 +121,117 @@ func TestCorrelationIDWithExistingHeaders(t *testing.T) {
 	assert.Equal(t, "existing-trace-id", resp.Header.Get("X-Trace-ID"))
 }
 
+func TestCorrelationID_ResponseHeader(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         []CorrelationIDConfig
+		requestHeaders map[string]string
+		headerName     string
+		expectedID     string
+	}{
+		{
+			name:       "generated trace ID is echoed by default",
+			headerName: "X-Correlation-ID",
+		},
+		{
+			name:           "client trace ID is echoed",
+			requestHeaders: map[string]string{"X-Trace-ID": "client-trace"},
+			headerName:     "X-Correlation-ID",
+			expectedID:     "client-trace",
+		},
+		{
+			name:           "client correlation ID is used as the trace ID",
+			requestHeaders: map[string]string{"X-Correlation-ID": "client-correlation"},
+			headerName:     "X-Correlation-ID",
+			expectedID:     "client-correlation",
+		},
+		{
+			name:           "custom header name",
+			config:         []CorrelationIDConfig{{ResponseHeader: "X-Amzn-Trace-Id"}},
+			requestHeaders: map[string]string{"X-Trace-ID": "client-trace"},
+			headerName:     "X-Amzn-Trace-Id",
+			expectedID:     "client-trace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(CorrelationID(tt.config...))
+
+			var handlerTraceID string
+			app.Get("/test", func(c *fiber.Ctx) error {
+				handlerTraceID = utils.GetTraceID(c)
+				return c.SendString("OK")
+			})
+
+			req, _ := http.NewRequest("GET", "/test", nil)
+			for key, value := range tt.requestHeaders {
+				req.Header.Set(key, value)
+			}
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			echoed := resp.Header.Get(tt.headerName)
+			assert.NotEmpty(t, echoed)
+			assert.Equal(t, handlerTraceID, echoed)
+			assert.Equal(t, resp.Header.Get("X-Trace-ID"), echoed)
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, echoed)
+			}
+		})
+	}
+
+	t.Run("disabled with empty header", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(CorrelationID(CorrelationIDConfig{}))
+		app.Get("/test", func(c *fiber.Ctx) error {
+			return c.SendString("OK")
+		})
+
+		req, _ := http.NewRequest("GET", "/test", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Header.Get("X-Correlation-ID"))
+		assert.NotEmpty(t, resp.Header.Get("X-Trace-ID"))
+	})
+
+	t.Run("request logging keeps the correlation ID", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(CorrelationID())
+		app.Use(RequestLogging())
+		app.Get("/test", func(c *fiber.Ctx) error {
+			return c.SendString("OK")
+		})
+
+		req, _ := http.NewRequest("GET", "/test", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Header.Get("X-Correlation-ID"))
+		assert.Equal(t, resp.Header.Get("X-Correlation-ID"), resp.Header.Get("X-Trace-ID"))
+	})
+
+	t.Run("error responses carry the same ID", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(CorrelationID())
+		app.Get("/test", func(c *fiber.Ctx) error {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "BAD", "bad request", nil)
+		})
+
+		req, _ := http.NewRequest("GET", "/test", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var body utils.StandardResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, resp.Header.Get("X-Correlation-ID"), body.TraceID)
+	})
+}
+
 func TestStructuredLogging(t *testing.T) {
 	logger := utils.NewLogger("info", "json")
 
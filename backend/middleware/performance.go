@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,6 +23,8 @@ type PerformanceMetrics struct {
 	MinResponseTime     int64                       `json:"min_response_time_ms"`
 	MaxResponseTime     int64                       `json:"max_response_time_ms"`
 	ActiveConnections   int64                       `json:"active_connections"`
+	InFlightRequests    int64                       `json:"in_flight_requests"`
+	RejectedRequests    int64                       `json:"rejected_requests"`
 	MemoryUsage         runtime.MemStats            `json:"memory_usage"`
 	EndpointMetrics     map[string]*EndpointMetrics `json:"endpoint_metrics"`
 	LastUpdated         time.Time                   `json:"last_updated"`
@@ -45,8 +50,34 @@ type RateLimitConfig struct {
 	RequestsPerSecond float64
 	BurstSize         int
 	SkipPaths         []string
-	KeyGenerator      func(*fiber.Ctx) string
-	OnLimitReached    func(*fiber.Ctx) error
+	// Overrides applies a different rate to requests under a path prefix, e.g. a stricter one
+	// for "/api/ai". The longest matching prefix wins, with its own bucket per client.
+	Overrides      map[string]RateLimitRule
+	KeyGenerator   func(*fiber.Ctx) string
+	OnLimitReached func(*fiber.Ctx) error // Called with the Retry-After header already set
+}
+
+// RateLimitRule is the token bucket rate for requests under a RateLimitConfig.Overrides prefix
+type RateLimitRule struct {
+	RequestsPerSecond float64
+	BurstSize         int
+}
+
+// ConcurrencyLimitConfig holds global in-flight request limit configuration
+type ConcurrencyLimitConfig struct {
+	MaxInFlight    int64
+	SkipPaths      []string
+	OnLimitReached func(*fiber.Ctx) error
+}
+
+// DefaultConcurrencyLimitConfig returns default concurrency limit configuration
+func DefaultConcurrencyLimitConfig() ConcurrencyLimitConfig {
+	return ConcurrencyLimitConfig{
+		MaxInFlight: 1000,
+		// Health probes must keep answering under load; WebSocket connections
+		// hold their request open for the connection lifetime
+		SkipPaths: []string{"/health", "/health/live", "/health/ready", "/ready", "/ws"},
+	}
 }
 
 // Global performance metrics instance
@@ -100,7 +131,8 @@ func MemoryMonitoring() fiber.Handler {
 	}
 }
 
-// RateLimiting creates a rate limiting middleware
+// RateLimiting creates a token bucket rate limiting middleware. Requests over the limit are
+// rejected with 429 and a Retry-After header holding the seconds until the next token.
 func RateLimiting(config RateLimitConfig) fiber.Handler {
 	// Create rate limiter map for different keys (IP addresses, user IDs, etc.)
 	limiters := make(map[string]*rate.Limiter)
@@ -118,7 +150,7 @@ func RateLimiting(config RateLimitConfig) fiber.Handler {
 		config.OnLimitReached = func(c *fiber.Ctx) error {
 			return utils.ErrorResponse(c, fiber.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED",
 				"Too many requests", map[string]string{
-					"retry_after": "60",
+					"retry_after": c.GetRespHeader(fiber.HeaderRetryAfter),
 				})
 		}
 	}
@@ -129,26 +161,40 @@ func RateLimiting(config RateLimitConfig) fiber.Handler {
 			return c.Next()
 		}
 
-		// Get rate limiter key
+		// Get rate limiter key; overridden prefixes have buckets of their own
+		rule, prefix := config.ruleFor(c.Path())
 		key := config.KeyGenerator(c)
+		limiterKey := prefix + "|" + key
 
 		// Get or create rate limiter for this key
 		mu.RLock()
-		limiter, exists := limiters[key]
+		limiter, exists := limiters[limiterKey]
 		mu.RUnlock()
 
 		if !exists {
 			mu.Lock()
 			// Double-check after acquiring write lock
-			if limiter, exists = limiters[key]; !exists {
-				limiter = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), config.BurstSize)
-				limiters[key] = limiter
+			if limiter, exists = limiters[limiterKey]; !exists {
+				limiter = rate.NewLimiter(rate.Limit(rule.RequestsPerSecond), rule.BurstSize)
+				limiters[limiterKey] = limiter
 			}
 			mu.Unlock()
 		}
 
 		// Check if request is allowed
-		if !limiter.Allow() {
+		reservation := limiter.Reserve()
+		if delay := reservation.Delay(); !reservation.OK() || delay > 0 {
+			reservation.Cancel()
+
+			retryAfter := int(math.Ceil(delay.Seconds()))
+			if !reservation.OK() {
+				retryAfter = 60 // A zero burst never admits a request
+			}
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
 			// Log rate limit exceeded
 			traceID := utils.GetTraceID(c)
 			utils.GetLogger().WithTraceID(traceID).WithSource("rate_limiter").Warn(
@@ -156,8 +202,10 @@ func RateLimiting(config RateLimitConfig) fiber.Handler {
 					"key":                 key,
 					"path":                c.Path(),
 					"method":              c.Method(),
-					"requests_per_second": config.RequestsPerSecond,
-					"burst_size":          config.BurstSize,
+					"path_prefix":         prefix,
+					"requests_per_second": rule.RequestsPerSecond,
+					"burst_size":          rule.BurstSize,
+					"retry_after":         retryAfter,
 				})
 
 			return config.OnLimitReached(c)
@@ -167,6 +215,61 @@ func RateLimiting(config RateLimitConfig) fiber.Handler {
 	}
 }
 
+// ruleFor returns the rate applied to path and the override prefix it comes from, which is
+// empty for the default rate
+func (config RateLimitConfig) ruleFor(path string) (RateLimitRule, string) {
+	rule := RateLimitRule{RequestsPerSecond: config.RequestsPerSecond, BurstSize: config.BurstSize}
+	matched := ""
+	for prefix, override := range config.Overrides {
+		if len(prefix) > len(matched) && (path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")) {
+			rule, matched = override, prefix
+		}
+	}
+	return rule, matched
+}
+
+// ConcurrencyLimit creates a middleware that caps the number of requests processed at once
+// across the whole server, rejecting requests beyond the cap with 503
+func ConcurrencyLimit(config ...ConcurrencyLimitConfig) fiber.Handler {
+	cfg := DefaultConcurrencyLimitConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	if cfg.OnLimitReached == nil {
+		cfg.OnLimitReached = func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "SERVER_OVERLOADED",
+				"Server is handling too many requests", map[string]string{
+					"retry_after": "1",
+				})
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.MaxInFlight <= 0 || shouldSkipPath(c.Path(), cfg.SkipPaths) {
+			return c.Next()
+		}
+
+		if atomic.AddInt64(&globalMetrics.InFlightRequests, 1) > cfg.MaxInFlight {
+			atomic.AddInt64(&globalMetrics.InFlightRequests, -1)
+			atomic.AddInt64(&globalMetrics.RejectedRequests, 1)
+
+			utils.GetLogger().WithTraceID(utils.GetTraceID(c)).WithSource("concurrency_limiter").Warn(
+				"Concurrency limit exceeded", map[string]interface{}{
+					"path":          c.Path(),
+					"method":        c.Method(),
+					"max_in_flight": cfg.MaxInFlight,
+				})
+
+			return cfg.OnLimitReached(c)
+		}
+		defer atomic.AddInt64(&globalMetrics.InFlightRequests, -1)
+
+		return c.Next()
+	}
+}
+
 // ConnectionPooling creates a middleware for monitoring connection pool usage
 func ConnectionPooling() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -195,6 +298,8 @@ func GetPerformanceMetrics() *PerformanceMetrics {
 		MinResponseTime:     atomic.LoadInt64(&globalMetrics.MinResponseTime),
 		MaxResponseTime:     atomic.LoadInt64(&globalMetrics.MaxResponseTime),
 		ActiveConnections:   atomic.LoadInt64(&globalMetrics.ActiveConnections),
+		InFlightRequests:    atomic.LoadInt64(&globalMetrics.InFlightRequests),
+		RejectedRequests:    atomic.LoadInt64(&globalMetrics.RejectedRequests),
 		MemoryUsage:         memStats,
 		EndpointMetrics:     make(map[string]*EndpointMetrics),
 		LastUpdated:         time.Now(),
@@ -230,6 +335,7 @@ func ResetPerformanceMetrics() {
 	atomic.StoreInt64(&globalMetrics.MinResponseTime, 0)
 	atomic.StoreInt64(&globalMetrics.MaxResponseTime, 0)
 	atomic.StoreInt64(&globalMetrics.ActiveConnections, 0)
+	atomic.StoreInt64(&globalMetrics.RejectedRequests, 0)
 	globalMetrics.EndpointMetrics = make(map[string]*EndpointMetrics)
 	globalMetrics.LastUpdated = time.Now()
 }
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -246,6 +249,82 @@ func TestRateLimitingSkipPaths(t *testing.T) {
 	assert.Equal(t, 429, resp.StatusCode)
 }
 
+func TestConcurrencyLimit(t *testing.T) {
+	ResetPerformanceMetrics()
+
+	config := DefaultConcurrencyLimitConfig()
+	config.MaxInFlight = 1
+
+	app := fiber.New()
+	app.Use(ConcurrencyLimit(config))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendString("OK")
+	})
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendString("Healthy")
+	})
+
+	// Occupy the only slot
+	done := make(chan int)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil), -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	assert.Equal(t, int64(1), GetPerformanceMetrics().InFlightRequests)
+
+	// Further requests are rejected
+	resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	// Health checks are exempt
+	resp, err = app.Test(httptest.NewRequest("GET", "/health", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	close(release)
+	assert.Equal(t, 200, <-done)
+
+	metrics := GetPerformanceMetrics()
+	assert.Equal(t, int64(0), metrics.InFlightRequests)
+	assert.Equal(t, int64(1), metrics.RejectedRequests)
+
+	// Slot is free again
+	resp, err = app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestConcurrencyLimitDisabled(t *testing.T) {
+	app := fiber.New()
+	app.Use(ConcurrencyLimit(ConcurrencyLimitConfig{MaxInFlight: 0}))
+
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	for i := 0; i < 3; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+	}
+}
+
 func BenchmarkPerformanceMonitoring(b *testing.B) {
 	app := fiber.New()
 	app.Use(PerformanceMonitoring())
@@ -290,3 +369,70 @@ func BenchmarkRateLimiting(b *testing.B) {
 		}
 	})
 }
+
+func TestRateLimitingRetryAfter(t *testing.T) {
+	app := fiber.New()
+	app.Use(RateLimiting(RateLimitConfig{RequestsPerSecond: 0.1, BurstSize: 1}))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+
+	// One token every 10 seconds
+	retryAfter, err := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter))
+	require.NoError(t, err)
+	assert.InDelta(t, 10, retryAfter, 1)
+
+	var response utils.StandardResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", response.Error.Code)
+	assert.Equal(t, strconv.Itoa(retryAfter), response.Error.Details["retry_after"])
+}
+
+func TestRateLimitingOverrides(t *testing.T) {
+	app := fiber.New()
+	app.Use(RateLimiting(RateLimitConfig{
+		RequestsPerSecond: 0.001,
+		BurstSize:         5,
+		SkipPaths:         []string{"/health"},
+		Overrides: map[string]RateLimitRule{
+			"/api/ai":             {RequestsPerSecond: 0.001, BurstSize: 2},
+			"/api/ai/suggestions": {RequestsPerSecond: 0.001, BurstSize: 1},
+		},
+	}))
+	for _, path := range []string{"/api/ai/suggestions", "/api/ai/analyze-logs", "/api/ai-status", "/api/sync/status", "/health"} {
+		app.Get(path, func(c *fiber.Ctx) error {
+			return c.SendString("OK")
+		})
+	}
+
+	burst := func(path string, n int) []int {
+		statuses := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+			require.NoError(t, err)
+			statuses = append(statuses, resp.StatusCode)
+		}
+		return statuses
+	}
+
+	// The longest matching prefix applies, and each prefix has its own bucket
+	assert.Equal(t, []int{200, 429}, burst("/api/ai/suggestions", 2))
+	assert.Equal(t, []int{200, 200, 429}, burst("/api/ai/analyze-logs", 3))
+
+	// Prefixes only match whole path segments; other paths share the default bucket
+	assert.Equal(t, []int{200, 200, 200}, burst("/api/ai-status", 3))
+	assert.Equal(t, []int{200, 200, 429}, burst("/api/sync/status", 3))
+
+	// Exempt paths are never limited
+	assert.Equal(t, []int{200, 200, 200, 200, 200, 200}, burst("/health", 6))
+}
@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRequestValidation(t *testing.T) {
@@ -257,6 +261,30 @@ func TestValidateQuery(t *testing.T) {
 	}
 }
 
+func TestValidateQuery_OmitsValidationValues(t *testing.T) {
+	app := fiber.New()
+	app.Use(ValidateQuery(map[string]string{"page": "required,numeric"}))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	req, _ := http.NewRequest("GET", "/test?page=hunter2", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var response utils.StandardResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.NotNil(t, response.Error)
+
+	// The rejected value is never echoed back
+	assert.Equal(t, "Field must contain only numbers", response.Error.Details["page"])
+	assert.NotContains(t, response.Error.Details, "page_value")
+	assert.NotContains(t, string(body), "hunter2")
+}
+
 func TestValidateParams(t *testing.T) {
 	rules := map[string]string{
 		"id": "required,numeric",
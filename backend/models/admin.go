@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ConfigSnapshotVersion is the format version of the ServiceConfigSnapshot this server exports
+const ConfigSnapshotVersion = 1
+
+// ServiceConfigSnapshot is the runtime-mutable configuration of a server, exported by
+// GET /api/admin/export-config and applied to another server by POST /api/admin/import-config.
+// On import, a section that is missing or null is left unchanged, and a present one replaces the
+// current settings; an empty list clears them.
+type ServiceConfigSnapshot struct {
+	Version            int                `json:"version" validate:"required,min=1"`
+	ExportedAt         time.Time          `json:"exported_at"`
+	Features           map[string]bool    `json:"features,omitempty"` // Set by environment variables, so only compared on import
+	LogFormat          string             `json:"log_format,omitempty" validate:"omitempty,oneof=json text console"`
+	LogIngestionPaused *bool              `json:"log_ingestion_paused,omitempty"`
+	LogAlertRules      []LogAlertRule     `json:"log_alert_rules"`
+	SyncContracts      []EndpointContract `json:"sync_contracts" validate:"dive"`
+	// TestSchedules are recreated with new IDs and without the state of their runs
+	TestSchedules []TestScheduleRequest `json:"test_schedules" validate:"dive"`
+}
+
+// ConfigImportResult reports what importing a ServiceConfigSnapshot changed
+type ConfigImportResult struct {
+	Applied  []string `json:"applied"`            // Sections of the snapshot that were applied
+	Warnings []string `json:"warnings,omitempty"` // Feature flags that differ from the snapshot, which an import can't change
+}
+
+// Synthetic failures POST /api/admin/chaos can inject
+const (
+	ChaosActionAICircuitOpen        = "ai_circuit_open"       // Open AI provider circuit breakers
+	ChaosActionEnvironmentUnhealthy = "environment_unhealthy" // Mark a sync environment unhealthy
+	ChaosActionLogAlert             = "log_alert"             // Raise a critical log alert
+)
+
+// ChaosRequest asks for a synthetic failure to be injected, to exercise dashboards and alerting
+type ChaosRequest struct {
+	Action string `json:"action" validate:"required,oneof=ai_circuit_open environment_unhealthy log_alert"`
+	// Target is the AI provider to trip, all when empty, or the sync environment to mark
+	// unhealthy, which is required. Unused by log_alert.
+	Target  string `json:"target,omitempty" validate:"max=100"`
+	Message string `json:"message,omitempty" validate:"max=1000"` // Reason or alert message; a default is used when empty
+}
+
+// ChaosResult reports the synthetic failure that was injected
+type ChaosResult struct {
+	Action     string    `json:"action"`
+	Targets    []string  `json:"targets,omitempty"`  // AI providers tripped or environment marked unhealthy
+	AlertID    string    `json:"alert_id,omitempty"` // ID of the log alert raised, for acknowledging it
+	Message    string    `json:"message"`
+	InjectedAt time.Time `json:"injected_at"`
+}
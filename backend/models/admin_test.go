@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+func TestServiceConfigSnapshotValidation(t *testing.T) {
+	validator := utils.NewValidator()
+
+	tests := []struct {
+		name      string
+		snapshot  ServiceConfigSnapshot
+		wantValid bool
+		wantError string
+	}{
+		{
+			name: "valid snapshot",
+			snapshot: ServiceConfigSnapshot{
+				Version:       ConfigSnapshotVersion,
+				LogFormat:     "text",
+				LogAlertRules: []LogAlertRule{{Keyword: "panic"}},
+				SyncContracts: []EndpointContract{{Name: "user", Method: "GET", Fields: map[string]string{"id": "number"}}},
+			},
+			wantValid: true,
+		},
+		{
+			name:      "only a version",
+			snapshot:  ServiceConfigSnapshot{Version: ConfigSnapshotVersion},
+			wantValid: true,
+		},
+		{
+			name:      "missing version",
+			snapshot:  ServiceConfigSnapshot{},
+			wantValid: false,
+			wantError: "version",
+		},
+		{
+			name:      "unknown log format",
+			snapshot:  ServiceConfigSnapshot{Version: ConfigSnapshotVersion, LogFormat: "xml"},
+			wantValid: false,
+			wantError: "log_format",
+		},
+		{
+			name: "invalid contract method",
+			snapshot: ServiceConfigSnapshot{
+				Version:       ConfigSnapshotVersion,
+				SyncContracts: []EndpointContract{{Name: "user", Method: "FETCH", Fields: map[string]string{"id": "number"}}},
+			},
+			wantValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.ValidateStruct(tt.snapshot)
+
+			if tt.wantValid && !result.IsValid {
+				t.Errorf("Expected valid snapshot, got errors: %v", result.Errors)
+			}
+
+			if !tt.wantValid && result.IsValid {
+				t.Errorf("Expected invalid snapshot, but validation passed")
+			}
+
+			if !tt.wantValid && tt.wantError != "" {
+				if _, exists := result.Errors[tt.wantError]; !exists {
+					t.Errorf("Expected error for field %s, got errors: %v", tt.wantError, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestChaosRequestValidation(t *testing.T) {
+	validator := utils.NewValidator()
+
+	for _, action := range []string{ChaosActionAICircuitOpen, ChaosActionEnvironmentUnhealthy, ChaosActionLogAlert} {
+		if result := validator.ValidateStruct(ChaosRequest{Action: action}); !result.IsValid {
+			t.Errorf("Expected action %s to be valid, got errors: %v", action, result.Errors)
+		}
+	}
+
+	for name, request := range map[string]ChaosRequest{
+		"missing action": {},
+		"unknown action": {Action: "delete_database"},
+	} {
+		result := validator.ValidateStruct(request)
+		if result.IsValid {
+			t.Errorf("%s: expected request to be invalid", name)
+			continue
+		}
+		if _, exists := result.Errors["action"]; !exists {
+			t.Errorf("%s: expected error for field action, got errors: %v", name, result.Errors)
+		}
+	}
+}
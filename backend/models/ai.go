@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // AIRequest represents a request for AI assistance
 type AIRequest struct {
@@ -9,15 +14,34 @@ type AIRequest struct {
 	Context     string            `json:"context" validate:"max=2000"`
 	RequestType string            `json:"request_type" validate:"required,oneof=suggestion debug optimize refactor explain"`
 	Metadata    map[string]string `json:"metadata"`
+	Model       string            `json:"model" validate:"omitempty,max=100"`                     // Explicit model from AI_ALLOWED_MODELS; empty samples AI_MODEL_WEIGHTS
+	Temperature *float32          `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=2"` // Sampling temperature; nil uses the default
+	MaxTokens   int               `json:"max_tokens" validate:"min=0"`                            // Completion token budget up to AI_MAX_TOKENS; 0 uses the default
+	RequestID   string            `json:"request_id" validate:"omitempty,max=100"`                // Caller-chosen ID for cancelling the request; empty generates one
+	NoCache     bool              `json:"no_cache"`                                               // Skip the response cache and always call OpenAI, e.g. when debugging prompts
+	Format      string            `json:"format" validate:"omitempty,oneof=code patch"`           // "patch" asks for unified diffs, which are applied and validated; empty means code
+	Retry       *AIRetryHints     `json:"retry,omitempty"`                                        // Overrides the retry policy for this request; nil uses the default
 }
 
+// AIRetryHints override how a single AI request retries failed OpenAI calls. Zero fields keep the
+// default of 3 attempts starting 500ms apart.
+type AIRetryHints struct {
+	MaxAttempts    int `json:"max_attempts" validate:"min=0,max=5"`         // Attempts including the first; 1 fails fast
+	InitialDelayMS int `json:"initial_delay_ms" validate:"min=0,max=10000"` // Delay before the first retry, doubling per retry
+}
+
+// AIFormatPatch asks for suggestions as unified diffs against the submitted code
+const AIFormatPatch = "patch"
+
 // AIResponse represents the response from AI assistance
 type AIResponse struct {
 	Suggestions []Suggestion `json:"suggestions"`
 	Analysis    string       `json:"analysis"`
 	Confidence  float64      `json:"confidence" validate:"min=0,max=1"`
 	RequestID   string       `json:"request_id" validate:"required"`
+	Model       string       `json:"model,omitempty"` // Model that generated the response; empty for fallbacks
 	ProcessedAt time.Time    `json:"processed_at"`
+	Cached      bool         `json:"cached"` // Served from the response cache of an earlier identical request
 }
 
 // Suggestion represents an AI-generated code suggestion
@@ -28,6 +52,17 @@ type Suggestion struct {
 	LineNumber  int    `json:"line_number" validate:"min=0"`
 	Priority    string `json:"priority" validate:"required,oneof=high medium low"`
 	Reasoning   string `json:"reasoning"`
+
+	Patch *SuggestionPatch `json:"patch,omitempty"` // Outcome of applying Code as a diff; set for the patch format only
+}
+
+// SuggestionPatch is the outcome of applying a suggested diff to the submitted code
+type SuggestionPatch struct {
+	Applied       bool   `json:"applied"`                // The diff applied cleanly
+	Valid         bool   `json:"valid"`                  // Applied, and the patched code passed the syntax check if there is one
+	SyntaxChecked bool   `json:"syntax_checked"`         // A syntax check is configured for the language and ran
+	PatchedCode   string `json:"patched_code,omitempty"` // The submitted code with the diff applied
+	Error         string `json:"error,omitempty"`        // Why the diff didn't apply or the patched code is invalid
 }
 
 // AILogAnalysisRequest represents a request for AI log analysis
@@ -36,6 +71,10 @@ type AILogAnalysisRequest struct {
 	TimeRange    TimeRange         `json:"time_range"`
 	Filters      map[string]string `json:"filters"`
 	AnalysisType string            `json:"analysis_type" validate:"required,oneof=error_detection pattern_analysis performance_issues security_scan"`
+	Model        string            `json:"model" validate:"omitempty,max=100"`                     // Explicit model from AI_ALLOWED_MODELS; empty samples AI_MODEL_WEIGHTS
+	Temperature  *float32          `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=2"` // Sampling temperature; nil uses the default
+	MaxTokens    int               `json:"max_tokens" validate:"min=0"`                            // Completion token budget up to AI_MAX_TOKENS; 0 uses the default
+	Retry        *AIRetryHints     `json:"retry,omitempty"`                                        // Overrides the retry policy for this analysis; nil uses the default
 }
 
 // AILogAnalysisResponse represents the response from AI log analysis
@@ -46,6 +85,160 @@ type AILogAnalysisResponse struct {
 	Suggestions []string     `json:"suggestions"`
 	AnalyzedAt  time.Time    `json:"analyzed_at"`
 	Confidence  float64      `json:"confidence" validate:"min=0,max=1"`
+	Model       string       `json:"model,omitempty"` // Model that generated the analysis; empty for fallbacks
+}
+
+// AIUsage represents accumulated OpenAI token usage and its estimated cost
+type AIUsage struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+}
+
+// AIUsageGroup represents usage attributed to a single metadata value
+type AIUsageGroup struct {
+	Group string `json:"group"`
+	AIUsage
+}
+
+// AIUsageReport represents usage totals, optionally broken down by a metadata key
+type AIUsageReport struct {
+	Total   AIUsage        `json:"total"`
+	GroupBy string         `json:"group_by,omitempty"`
+	Groups  []AIUsageGroup `json:"groups,omitempty"`
+	Since   time.Time      `json:"since"`
+}
+
+// AIUsageSummary represents cumulative usage broken down by the kind of request that spent it
+type AIUsageSummary struct {
+	Total         AIUsage            `json:"total"`
+	ByRequestType map[string]AIUsage `json:"by_request_type"` // Code request types, plus AIUsageLogAnalysis
+	Since         time.Time          `json:"since"`
+}
+
+// AIQuotaUsage is one caller's AI usage against the daily quota; a limit of 0 is unlimited
+type AIQuotaUsage struct {
+	Key          string    `json:"key"` // Masked API key, or the client IP for requests without one
+	RequestsUsed int       `json:"requests_used"`
+	RequestLimit int       `json:"request_limit"`
+	TokensUsed   int       `json:"tokens_used"`
+	TokenLimit   int       `json:"token_limit"`
+	ResetsAt     time.Time `json:"resets_at"`
+}
+
+// AIUsageLogAnalysis is the request type usage of log analyses is recorded under
+const AIUsageLogAnalysis = "log_analysis"
+
+// AIUsageUnattributed groups usage from requests without a value for the grouping key
+const AIUsageUnattributed = "unattributed"
+
+// AIUsageGroupByModel groups usage by the model that served each request
+const AIUsageGroupByModel = "model"
+
+// AIModelWeight is one entry in the weighted distribution of models sampled per AI request
+type AIModelWeight struct {
+	Model  string  `json:"model"`
+	Weight float64 `json:"weight"`
+}
+
+// ParseAIModelWeights parses "model=weight" entries (e.g. "gpt-4o-mini=50"). An entry
+// without a weight counts as weight 1.
+func ParseAIModelWeights(entries []string) ([]AIModelWeight, error) {
+	weights := make([]AIModelWeight, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		model, weightStr, hasWeight := strings.Cut(strings.TrimSpace(entry), "=")
+		model = strings.TrimSpace(model)
+		if model == "" {
+			return nil, fmt.Errorf("invalid model weight %q: model name is required", entry)
+		}
+		if seen[model] {
+			return nil, fmt.Errorf("invalid model weight %q: model listed more than once", entry)
+		}
+		seen[model] = true
+
+		weight := 1.0
+		if hasWeight {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid model weight %q: weight must be a number greater than zero", entry)
+			}
+			weight = parsed
+		}
+
+		weights = append(weights, AIModelWeight{Model: model, Weight: weight})
+	}
+
+	return weights, nil
+}
+
+// AIPatchValidatorBuiltin names the in-process syntax check of languages that have one (go)
+const AIPatchValidatorBuiltin = "builtin"
+
+// ParseAIPatchValidators parses "language=command" entries (e.g. "python=python3 -m py_compile")
+// into the syntax check command per language. The patched code's file path is appended to the
+// command. AIPatchValidatorBuiltin selects the in-process check.
+func ParseAIPatchValidators(entries []string) (map[string]string, error) {
+	validators := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		language, command, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		language, command = strings.ToLower(strings.TrimSpace(language)), strings.TrimSpace(command)
+		if !ok || language == "" || command == "" {
+			return nil, fmt.Errorf("invalid patch validator %q: expected language=command", entry)
+		}
+		if command == AIPatchValidatorBuiltin && language != "go" {
+			return nil, fmt.Errorf("invalid patch validator %q: there is no builtin check for %s", entry, language)
+		}
+		if _, exists := validators[language]; exists {
+			return nil, fmt.Errorf("invalid patch validator %q: language listed more than once", entry)
+		}
+		validators[language] = command
+	}
+	return validators, nil
+}
+
+// AIFeedbackRequest rates one suggestion from a previous code suggestion response
+type AIFeedbackRequest struct {
+	RequestID       string `json:"request_id" validate:"required"`
+	SuggestionIndex int    `json:"suggestion_index" validate:"min=0"`
+	Helpful         *bool  `json:"helpful" validate:"required"`
+	Comment         string `json:"comment" validate:"max=1000"`
+}
+
+// AIFeedback represents recorded feedback on a suggestion
+type AIFeedback struct {
+	RequestID       string    `json:"request_id"`
+	SuggestionIndex int       `json:"suggestion_index"`
+	Helpful         bool      `json:"helpful"`
+	Comment         string    `json:"comment,omitempty"`
+	Model           string    `json:"model"` // Model that produced the suggestion, or AIFeedbackFallbackModel
+	RecordedAt      time.Time `json:"recorded_at"`
+}
+
+// AIFeedbackFallbackModel groups feedback on fallback responses served while the AI was unavailable
+const AIFeedbackFallbackModel = "fallback"
+
+// AIFeedbackStats aggregates helpful/unhelpful votes
+type AIFeedbackStats struct {
+	Total       int     `json:"total"`
+	Helpful     int     `json:"helpful"`
+	Unhelpful   int     `json:"unhelpful"`
+	HelpfulRate float64 `json:"helpful_rate"` // Percentage of votes marked helpful
+}
+
+// AIFeedbackGroup represents feedback stats for a single model
+type AIFeedbackGroup struct {
+	Model string `json:"model"`
+	AIFeedbackStats
+}
+
+// AIFeedbackSummary represents aggregated suggestion feedback, overall and per model
+type AIFeedbackSummary struct {
+	AIFeedbackStats
+	ByModel []AIFeedbackGroup `json:"by_model"`
 }
 
 // TimeRange represents a time range for filtering
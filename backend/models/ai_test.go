@@ -52,6 +52,27 @@ func TestAIRequestValidation(t *testing.T) {
 			},
 			wantValid: true,
 		},
+		{
+			name: "valid patch format request",
+			request: AIRequest{
+				Code:        "x = 1",
+				Language:    "python",
+				RequestType: "refactor",
+				Format:      AIFormatPatch,
+			},
+			wantValid: true,
+		},
+		{
+			name: "unknown format",
+			request: AIRequest{
+				Code:        "x = 1",
+				Language:    "python",
+				RequestType: "refactor",
+				Format:      "diff",
+			},
+			wantValid: false,
+			wantError: "format",
+		},
 		{
 			name: "missing code",
 			request: AIRequest{
@@ -100,6 +121,17 @@ func TestAIRequestValidation(t *testing.T) {
 			wantValid: false,
 			wantError: "context",
 		},
+		{
+			name: "negative max tokens",
+			request: AIRequest{
+				Code:        "console.log('test');",
+				Language:    "javascript",
+				RequestType: "suggestion",
+				MaxTokens:   -1,
+			},
+			wantValid: false,
+			wantError: "max_tokens",
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,6 +155,55 @@ func TestAIRequestValidation(t *testing.T) {
 	}
 }
 
+func TestParseAIModelWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		expected []AIModelWeight
+		wantErr  bool
+	}{
+		{name: "empty", entries: nil, expected: []AIModelWeight{}},
+		{
+			name:     "weighted",
+			entries:  []string{"gpt-3.5-turbo=50", " gpt-4o-mini = 25.5 "},
+			expected: []AIModelWeight{{Model: "gpt-3.5-turbo", Weight: 50}, {Model: "gpt-4o-mini", Weight: 25.5}},
+		},
+		{
+			name:     "missing weight counts as one",
+			entries:  []string{"gpt-4o-mini"},
+			expected: []AIModelWeight{{Model: "gpt-4o-mini", Weight: 1}},
+		},
+		{name: "missing model", entries: []string{"=50"}, wantErr: true},
+		{name: "zero weight", entries: []string{"gpt-4o=0"}, wantErr: true},
+		{name: "negative weight", entries: []string{"gpt-4o=-1"}, wantErr: true},
+		{name: "non-numeric weight", entries: []string{"gpt-4o=half"}, wantErr: true},
+		{name: "duplicate model", entries: []string{"gpt-4o=1", "gpt-4o=2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := ParseAIModelWeights(tt.entries)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %v, got %v", tt.entries, weights)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %v: %v", tt.entries, err)
+			}
+			if len(weights) != len(tt.expected) {
+				t.Fatalf("Expected %d weights, got %d", len(tt.expected), len(weights))
+			}
+			for i := range weights {
+				if weights[i] != tt.expected[i] {
+					t.Errorf("Expected %+v, got %+v", tt.expected[i], weights[i])
+				}
+			}
+		})
+	}
+}
+
 func TestAIResponseValidation(t *testing.T) {
 	validator := utils.NewValidator()
 
@@ -390,3 +471,25 @@ func TestTimeRangeValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAIPatchValidators(t *testing.T) {
+	validators, err := ParseAIPatchValidators([]string{"go=builtin", " Python = python3 -m py_compile "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if validators["go"] != "builtin" || validators["python"] != "python3 -m py_compile" || len(validators) != 2 {
+		t.Errorf("Unexpected validators: %v", validators)
+	}
+
+	for _, entries := range [][]string{
+		{"python"},
+		{"=node --check"},
+		{"javascript="},
+		{"python=builtin"},
+		{"go=builtin", "go=gofmt -e"},
+	} {
+		if validators, err := ParseAIPatchValidators(entries); err == nil {
+			t.Errorf("Expected error for %v, got %v", entries, validators)
+		}
+	}
+}
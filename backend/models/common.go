@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/websocket/v2"
@@ -25,12 +29,132 @@ type HealthStatus struct {
 
 // WSMessage represents a WebSocket message structure
 type WSMessage struct {
-	Type      string      `json:"type" validate:"required,oneof=sync_status_update test_progress log_alert ai_suggestion_ready connect disconnect heartbeat"`
+	Type      string      `json:"type" validate:"required,oneof=sync_status_update test_progress test_log_line test_workflow_progress log_alert ai_suggestion_ready ai_request_cancelled connect disconnect heartbeat subscribe unsubscribe"`
 	Data      interface{} `json:"data"`
 	Timestamp time.Time   `json:"timestamp"`
 	ClientID  string      `json:"client_id" validate:"required"`
 }
 
+// ParseWSRetainRules parses "type=field" entries (e.g. "test_progress=run_id") into the data
+// field keying each retained WebSocket message type. An entry without a field, such as
+// "sync_status_update", retains only the latest message of that type.
+func ParseWSRetainRules(entries []string) (map[string]string, error) {
+	rules := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		msgType, field, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		msgType, field = strings.TrimSpace(msgType), strings.TrimSpace(field)
+		if msgType == "" {
+			return nil, fmt.Errorf("invalid retain rule %q: expected type or type=field", entry)
+		}
+		if _, exists := rules[msgType]; exists {
+			return nil, fmt.Errorf("invalid retain rule %q: type listed more than once", entry)
+		}
+		rules[msgType] = field
+	}
+	return rules, nil
+}
+
+// ParseWSAuthTokens parses "user_id=token" entries into the user ID each WebSocket token
+// authenticates as. Several tokens may share a user ID, but each token must be unique.
+func ParseWSAuthTokens(entries []string) (map[string]string, error) {
+	tokens := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		userID, token, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		userID, token = strings.TrimSpace(userID), strings.TrimSpace(token)
+		if !ok || userID == "" || token == "" {
+			return nil, fmt.Errorf("invalid WebSocket token for %q: expected user_id=token", userID)
+		}
+		if strings.ContainsAny(token, " \t") {
+			return nil, fmt.Errorf("invalid WebSocket token for %q: tokens must not contain whitespace", userID)
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("invalid WebSocket token for %q: token listed more than once", userID)
+		}
+		tokens[token] = userID
+	}
+	return tokens, nil
+}
+
+// RateLimitRule is the token bucket applied to one client: RequestsPerMinute refill the bucket,
+// which holds at most Burst requests
+type RateLimitRule struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// ParseRateLimitOverrides parses "path_prefix=requests_per_minute:burst" entries (e.g.
+// "/api/ai=60:10") into the rule applied to requests under each path prefix
+func ParseRateLimitOverrides(entries []string) (map[string]RateLimitRule, error) {
+	overrides := make(map[string]RateLimitRule, len(entries))
+	for _, entry := range entries {
+		prefix, limits, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid rate limit override %q: expected /path_prefix=requests_per_minute:burst", entry)
+		}
+
+		perMinute, burst, ok := strings.Cut(strings.TrimSpace(limits), ":")
+		rule := RateLimitRule{}
+		var err error
+		if rule.RequestsPerMinute, err = strconv.Atoi(strings.TrimSpace(perMinute)); !ok || err != nil || rule.RequestsPerMinute <= 0 {
+			return nil, fmt.Errorf("invalid rate limit override %q: requests per minute must be a positive integer", entry)
+		}
+		if rule.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || rule.Burst <= 0 {
+			return nil, fmt.Errorf("invalid rate limit override %q: burst must be a positive integer", entry)
+		}
+
+		if _, exists := overrides[prefix]; exists {
+			return nil, fmt.Errorf("invalid rate limit override %q: path prefix listed more than once", entry)
+		}
+		overrides[prefix] = rule
+	}
+	return overrides, nil
+}
+
+// ProtectedRoute is a route that requires authentication. Path segments starting with ":" match
+// any single segment and a final "*" segment matches the rest of the path; a Method of "*"
+// matches every method.
+type ProtectedRoute struct {
+	Method string
+	Path   string
+}
+
+// ParseProtectedRoutes parses "METHOD /path" entries (e.g. "DELETE /api/sync/environments/:name")
+// into the routes requiring authentication
+func ParseProtectedRoutes(entries []string) ([]ProtectedRoute, error) {
+	routes := make([]ProtectedRoute, 0, len(entries))
+	seen := make(map[ProtectedRoute]bool, len(entries))
+	for _, entry := range entries {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid protected route %q: expected METHOD /path", entry)
+		}
+
+		route := ProtectedRoute{Method: strings.ToUpper(fields[0]), Path: fields[1]}
+		if seen[route] {
+			return nil, fmt.Errorf("invalid protected route %q: route listed more than once", entry)
+		}
+		seen[route] = true
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// AuditEvent is a broadcast kept in the audit store. Data is the broadcast payload as it was
+// serialized when recorded, so later changes to the original value don't alter the record.
+type AuditEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// AuditEventsResponse represents the response for an audit event query
+type AuditEventsResponse struct {
+	Events []AuditEvent `json:"events"`
+	Total  int          `json:"total"` // Events matching the filters before the limit was applied
+}
+
 // WSClient represents a WebSocket client connection
 type WSClient struct {
 	ID       string
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -136,3 +137,106 @@ func TestHealthStatusValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestParseWSRetainRules(t *testing.T) {
+	rules, err := ParseWSRetainRules([]string{"test_progress=run_id", " sync_status_update "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rules["test_progress"] != "run_id" || rules["sync_status_update"] != "" || len(rules) != 2 {
+		t.Errorf("Unexpected rules: %v", rules)
+	}
+
+	for _, entries := range [][]string{
+		{"=run_id"},
+		{""},
+		{"test_progress=run_id", "test_progress=status"},
+	} {
+		if rules, err := ParseWSRetainRules(entries); err == nil {
+			t.Errorf("Expected error for %v, got %v", entries, rules)
+		}
+	}
+}
+
+func TestParseWSAuthTokens(t *testing.T) {
+	tokens, err := ParseWSAuthTokens([]string{"dashboard=secret-1", " ci = secret-2 ", "dashboard=secret-3"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tokens["secret-1"] != "dashboard" || tokens["secret-2"] != "ci" || tokens["secret-3"] != "dashboard" || len(tokens) != 3 {
+		t.Errorf("Unexpected tokens: %v", tokens)
+	}
+
+	for _, entries := range [][]string{
+		{"dashboard"},
+		{"=secret-1"},
+		{"dashboard="},
+		{"dashboard=two words"},
+		{"dashboard=secret-1", "ci=secret-1"},
+	} {
+		if tokens, err := ParseWSAuthTokens(entries); err == nil {
+			t.Errorf("Expected error for %v, got %v", entries, tokens)
+		}
+	}
+
+	if _, err := ParseWSAuthTokens([]string{"dashboard=two words"}); err != nil && strings.Contains(err.Error(), "two words") {
+		t.Errorf("Error exposes the token: %v", err)
+	}
+}
+
+func TestParseRateLimitOverrides(t *testing.T) {
+	overrides, err := ParseRateLimitOverrides([]string{"/api/ai=60:10", " /api/logs/submit = 600 : 50 "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if overrides["/api/ai"] != (RateLimitRule{RequestsPerMinute: 60, Burst: 10}) {
+		t.Errorf("Unexpected /api/ai rule: %+v", overrides["/api/ai"])
+	}
+	if overrides["/api/logs/submit"] != (RateLimitRule{RequestsPerMinute: 600, Burst: 50}) {
+		t.Errorf("Unexpected /api/logs/submit rule: %+v", overrides["/api/logs/submit"])
+	}
+
+	for _, entries := range [][]string{
+		{"/api/ai"},
+		{"api/ai=60:10"},
+		{"/api/ai=60"},
+		{"/api/ai=0:10"},
+		{"/api/ai=60:-1"},
+		{"/api/ai=fast:10"},
+		{"/api/ai=60:10", "/api/ai=30:5"},
+	} {
+		if overrides, err := ParseRateLimitOverrides(entries); err == nil {
+			t.Errorf("Expected error for %v, got %v", entries, overrides)
+		}
+	}
+}
+
+func TestParseProtectedRoutes(t *testing.T) {
+	routes, err := ParseProtectedRoutes([]string{"POST /api/logs/submit", " delete  /api/sync/environments/:name "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []ProtectedRoute{
+		{Method: "POST", Path: "/api/logs/submit"},
+		{Method: "DELETE", Path: "/api/sync/environments/:name"},
+	}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %+v", len(expected), routes)
+	}
+	for i, route := range routes {
+		if route != expected[i] {
+			t.Errorf("Expected route %d to be %+v, got %+v", i, expected[i], route)
+		}
+	}
+
+	for _, entries := range [][]string{
+		{"/api/logs/submit"},
+		{"POST api/logs/submit"},
+		{"POST /api/logs/submit extra"},
+		{"POST /api/logs/submit", "post /api/logs/submit"},
+	} {
+		if routes, err := ParseProtectedRoutes(entries); err == nil {
+			t.Errorf("Expected error for %v, got %v", entries, routes)
+		}
+	}
+}
@@ -4,18 +4,19 @@ import "time"
 
 // LogEntry represents a log entry from frontend or backend
 type LogEntry struct {
-	ID         string                 `json:"id" validate:"required"`
-	Timestamp  time.Time              `json:"timestamp" validate:"required"`
-	Level      string                 `json:"level" validate:"required,oneof=error warn info debug trace"`
-	Source     string                 `json:"source" validate:"required,oneof=frontend backend"`
-	Message    string                 `json:"message" validate:"required,min=1"`
-	Context    map[string]interface{} `json:"context"`
-	StackTrace string                 `json:"stack_trace,omitempty"`
-	UserID     string                 `json:"user_id,omitempty"`
-	SessionID  string                 `json:"session_id,omitempty"`
-	Component  string                 `json:"component,omitempty"`
-	Function   string                 `json:"function,omitempty"`
-	LineNumber int                    `json:"line_number,omitempty"`
+	ID          string                 `json:"id" validate:"required"`
+	Timestamp   time.Time              `json:"timestamp" validate:"required"`
+	Level       string                 `json:"level" validate:"required,enum=loglevel"` // One of LOG_INGEST_LEVELS
+	Source      string                 `json:"source" validate:"required,oneof=frontend backend"`
+	Message     string                 `json:"message" validate:"required,min=1"`
+	Context     map[string]interface{} `json:"context"`
+	StackTrace  string                 `json:"stack_trace,omitempty"`
+	UserID      string                 `json:"user_id,omitempty"`
+	SessionID   string                 `json:"session_id,omitempty"`
+	Component   string                 `json:"component,omitempty"`
+	Function    string                 `json:"function,omitempty"`
+	LineNumber  int                    `json:"line_number,omitempty"`
+	Fingerprint string                 `json:"fingerprint,omitempty"` // Assigned to errors on submission; shared by entries of the same error
 }
 
 // LogSubmissionRequest represents a request to submit logs
@@ -26,34 +27,172 @@ type LogSubmissionRequest struct {
 	Metadata map[string]string `json:"metadata"`
 }
 
+// Batch outcomes reported in LogSubmissionResponse.Status
+const (
+	LogSubmissionAllAccepted = "all_accepted"
+	LogSubmissionPartial     = "partial"
+	LogSubmissionAllRejected = "all_rejected"
+)
+
 // LogSubmissionResponse represents the response after log submission
 type LogSubmissionResponse struct {
-	Accepted    int       `json:"accepted"`
-	Rejected    int       `json:"rejected"`
-	BatchID     string    `json:"batch_id"`
-	ProcessedAt time.Time `json:"processed_at"`
-	Errors      []string  `json:"errors,omitempty"`
+	Status      string          `json:"status"`  // all_accepted, partial or all_rejected
+	Partial     bool            `json:"partial"` // Some entries were accepted and some rejected
+	Accepted    int             `json:"accepted"`
+	Rejected    int             `json:"rejected"`
+	Clamped     int             `json:"clamped,omitempty"` // Accepted entries whose timestamp was clamped to server time
+	BatchID     string          `json:"batch_id"`
+	ProcessedAt time.Time       `json:"processed_at"`
+	Errors      []LogEntryError `json:"errors,omitempty"`
+}
+
+// LogEntryError describes why one entry of a log submission was rejected
+type LogEntryError struct {
+	Index  int    `json:"index"`           // 0-based position of the entry in the submitted logs
+	Field  string `json:"field,omitempty"` // Entry field that failed validation
+	Reason string `json:"reason"`
+}
+
+// LogStoreWriteStats counts writes of submitted logs to the log store, including retries
+type LogStoreWriteStats struct {
+	Writes          int64 `json:"writes"`           // Batches written, counting each once however many attempts it took
+	Attempts        int64 `json:"attempts"`         // Store writes attempted, including retries
+	FailedAttempts  int64 `json:"failed_attempts"`  // Attempts the store returned an error for
+	Exhausted       int64 `json:"exhausted"`        // Batches whose retries ran out and were buffered in memory
+	BufferedEntries int   `json:"buffered_entries"` // Entries waiting in the in-memory buffer for the store to recover
+	DroppedEntries  int64 `json:"dropped_entries"`  // Buffered entries evicted because the buffer was full
+}
+
+// LogClockSkewStats counts submitted logs whose timestamp was further from server time than the skew tolerance
+type LogClockSkewStats struct {
+	Tolerance string `json:"tolerance,omitempty"` // Largest accepted difference from server time; empty when clamping is disabled
+	Clamped   int64  `json:"clamped"`             // Entries whose timestamp was replaced with server time
+}
+
+// CriticalLogAlert tracks whether a critical log_alert has been acknowledged or escalated
+type CriticalLogAlert struct {
+	ID             string     `json:"id"`
+	LogID          string     `json:"log_id"`
+	FiredAt        time.Time  `json:"fired_at"`
+	EscalatesAt    *time.Time `json:"escalates_at,omitempty"` // When it escalates unless acknowledged; unset when escalation is disabled
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	EscalatedAt    *time.Time `json:"escalated_at,omitempty"`
+}
+
+// LogAlertAckRequest acknowledges a critical log_alert, stopping its escalation
+type LogAlertAckRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by" validate:"max=100"`
+}
+
+// LogIngestionState reports whether submitted logs are being accepted
+type LogIngestionState struct {
+	Paused            bool       `json:"paused"`
+	Reason            string     `json:"reason,omitempty"`
+	PausedAt          *time.Time `json:"paused_at,omitempty"`
+	RetryAfterSeconds int        `json:"retry_after_seconds,omitempty"` // Hint sent to rejected clients as Retry-After
+}
+
+// LogIngestionPauseRequest pauses log ingestion, e.g. for maintenance on the log store
+type LogIngestionPauseRequest struct {
+	Reason            string `json:"reason" validate:"max=200"`
+	RetryAfterSeconds int    `json:"retry_after_seconds" validate:"min=0,max=86400"`
+}
+
+// LogFingerprint summarizes the stored error logs sharing a fingerprint
+type LogFingerprint struct {
+	Fingerprint string    `json:"fingerprint"`
+	Message     string    `json:"message"` // Normalized message the fingerprint was computed from
+	Component   string    `json:"component,omitempty"`
+	Frame       string    `json:"frame,omitempty"` // Top stack frame the fingerprint was computed from
+	Level       string    `json:"level"`           // Level of the most recent occurrence
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	SampleLogID string    `json:"sample_log_id"` // Most recent entry with this fingerprint
+}
+
+// LogFingerprintsResponse lists the most frequent fingerprints out of Total
+type LogFingerprintsResponse struct {
+	Fingerprints []LogFingerprint `json:"fingerprints"`
+	Total        int              `json:"total"`
 }
 
 // LogAnalysisRequest represents a request for log analysis
 type LogAnalysisRequest struct {
-	TimeRange   TimeRange         `json:"time_range"`
+	TimeRange   TimeRange         `json:"time_range" validate:"-"` // Either bound may be left open
 	Levels      []string          `json:"levels"`
 	Sources     []string          `json:"sources"`
 	Components  []string          `json:"components"`
 	SearchQuery string            `json:"search_query"`
+	SearchMode  string            `json:"search_mode" validate:"omitempty,oneof=contains regex"` // How SearchQuery is read; empty means contains
 	Filters     map[string]string `json:"filters"`
 	Limit       int               `json:"limit" validate:"min=1,max=1000"`
+	Offset      int               `json:"offset" validate:"min=0"` // Matching logs to skip, newest first, before applying Limit
+	GroupBy     string            `json:"group_by"`                // Optional grouping for per-group breakdowns ("component")
+
+	// Error spike detection: SpikeThreshold occurrences of the same error within SpikeWindowMinutes.
+	// A threshold of 0 uses the default of 5; a window of 0 counts across all analyzed logs.
+	SpikeThreshold     int `json:"spike_threshold" validate:"min=0"`
+	SpikeWindowMinutes int `json:"spike_window_minutes" validate:"min=0"`
 }
 
+// Search modes of LogAnalysisRequest.SearchMode
+const (
+	LogSearchContains = "contains" // The search query language, matching substrings
+	LogSearchRegex    = "regex"    // A regular expression matched against message, component and function
+)
+
+// LogGroupByComponent groups log analysis results by component
+const LogGroupByComponent = "component"
+
+// Formats of GET /api/logs/export
+const (
+	LogExportCSV    = "csv"
+	LogExportNDJSON = "ndjson"
+)
+
 // LogAnalysisResponse represents the response from log analysis
 type LogAnalysisResponse struct {
-	Summary     string        `json:"summary"`
-	Issues      []LogIssue    `json:"issues"`
-	Patterns    []LogPattern  `json:"patterns"`
-	Suggestions []string      `json:"suggestions"`
-	Statistics  LogStatistics `json:"statistics"`
-	AnalyzedAt  time.Time     `json:"analyzed_at"`
+	Summary       string             `json:"summary"`
+	Issues        []LogIssue         `json:"issues"`
+	Patterns      []LogPattern       `json:"patterns"`
+	Suggestions   []string           `json:"suggestions"`
+	Statistics    LogStatistics      `json:"statistics"`
+	GroupBy       string             `json:"group_by,omitempty"`
+	Groups        []LogGroupAnalysis `json:"groups,omitempty"`
+	TimeRange     *TimeRange         `json:"time_range,omitempty"` // Effective range when an open-ended request was narrowed
+	TotalMatched  int                `json:"total_matched"`        // Logs matching the filters before Offset and Limit
+	ReturnedCount int                `json:"returned_count"`       // Logs in the analyzed page
+	WorstSeverity string             `json:"worst_severity"`       // Most severe issue severity, or LogSeverityNone
+	AnalyzedAt    time.Time          `json:"analyzed_at"`
+}
+
+// LogAnalysisReport represents a frozen log analysis snapshot that can be shared by ID
+type LogAnalysisReport struct {
+	ID        string              `json:"id"`
+	CreatedAt time.Time           `json:"created_at"`
+	Request   LogAnalysisRequest  `json:"request"`
+	Analysis  LogAnalysisResponse `json:"analysis"`
+}
+
+// LogGroupAnalysis represents analysis results for logs sharing the same group key
+type LogGroupAnalysis struct {
+	Group       string              `json:"group"`
+	TotalLogs   int                 `json:"total_logs"`
+	ErrorCount  int                 `json:"error_count"`
+	ErrorRate   float64             `json:"error_rate"`
+	Summary     string              `json:"summary"`
+	TopMessages []LogMessageSummary `json:"top_messages"`
+	Issues      []LogIssue          `json:"issues"`
+}
+
+// LogMessageSummary represents how often a message occurred within a group
+type LogMessageSummary struct {
+	Message  string    `json:"message"`
+	Level    string    `json:"level"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
 }
 
 // LogIssue represents an issue identified in logs
@@ -67,6 +206,46 @@ type LogIssue struct {
 	Solution           string     `json:"solution"`
 	AffectedComponents []string   `json:"affected_components"`
 	SampleLogs         []LogEntry `json:"sample_logs,omitempty"`
+	PeakCount          int        `json:"peak_count,omitempty"`     // Most occurrences within one spike window
+	WindowMinutes      int        `json:"window_minutes,omitempty"` // Spike window used; 0 when counted across all analyzed logs
+	RatePerMinute      float64    `json:"rate_per_minute,omitempty"`
+	LogIDs             []string   `json:"log_ids,omitempty"`      // Analyzed logs showing the issue, for AI-detected issues
+	Fingerprints       []string   `json:"fingerprints,omitempty"` // Fingerprints of those logs
+}
+
+// LogIssueSeverities lists the severities of a LogIssue, most severe first
+var LogIssueSeverities = []string{"critical", "high", "medium", "low"}
+
+// LogSeverityNone is the worst severity of an analysis that found no issues
+const LogSeverityNone = "none"
+
+// WorstIssueSeverity returns the most severe of the issues' severities, or LogSeverityNone when
+// there are none. Severities not in LogIssueSeverities are ignored.
+func WorstIssueSeverity(issues []LogIssue) string {
+	worst := LogSeverityNone
+	for _, issue := range issues {
+		if SeverityAtLeast(issue.Severity, worst) {
+			worst = issue.Severity
+		}
+	}
+	return worst
+}
+
+// SeverityAtLeast reports whether severity is known and at least as severe as threshold.
+// Every known severity is more severe than LogSeverityNone.
+func SeverityAtLeast(severity, threshold string) bool {
+	return issueSeverityRank(severity) < len(LogIssueSeverities) &&
+		issueSeverityRank(severity) <= issueSeverityRank(threshold)
+}
+
+// issueSeverityRank returns the position of severity in LogIssueSeverities, or its length when unknown
+func issueSeverityRank(severity string) int {
+	for rank, known := range LogIssueSeverities {
+		if severity == known {
+			return rank
+		}
+	}
+	return len(LogIssueSeverities)
 }
 
 // LogPattern represents a pattern identified in logs
@@ -107,6 +286,19 @@ type LogComponentSummary struct {
 	ErrorRate  float64 `json:"error_rate"`
 }
 
+// LogAlertRule marks submitted logs whose message contains Keyword as critical
+type LogAlertRule struct {
+	Keyword  string `json:"keyword"`
+	MinLevel string `json:"min_level,omitempty"` // Least severe level the rule applies to; empty matches every level
+}
+
+// LogAlertRuleRequest adds or removes a critical-log keyword rule at runtime
+type LogAlertRuleRequest struct {
+	Action   string `json:"action" validate:"required,oneof=add remove"`
+	Keyword  string `json:"keyword" validate:"required,max=200"`
+	MinLevel string `json:"min_level"`
+}
+
 // LogAlertRequest represents a request to create a log alert
 type LogAlertRequest struct {
 	Name        string            `json:"name" validate:"required,min=1"`
@@ -488,3 +488,99 @@ func TestLogIssueValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestWorstIssueSeverity(t *testing.T) {
+	tests := []struct {
+		name   string
+		issues []LogIssue
+		want   string
+	}{
+		{name: "no issues", issues: nil, want: LogSeverityNone},
+		{name: "single issue", issues: []LogIssue{{Severity: "low"}}, want: "low"},
+		{name: "most severe wins", issues: []LogIssue{{Severity: "medium"}, {Severity: "critical"}, {Severity: "high"}}, want: "critical"},
+		{name: "unknown severities are ignored", issues: []LogIssue{{Severity: "urgent"}, {Severity: "medium"}}, want: "medium"},
+		{name: "only unknown severities", issues: []LogIssue{{Severity: ""}}, want: LogSeverityNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorstIssueSeverity(tt.issues); got != tt.want {
+				t.Errorf("WorstIssueSeverity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity, threshold string
+		want                bool
+	}{
+		{"critical", "high", true},
+		{"high", "high", true},
+		{"medium", "high", false},
+		{LogSeverityNone, "low", false},
+		{"low", LogSeverityNone, true},
+		{"urgent", "low", false},
+	}
+
+	for _, tt := range tests {
+		if got := SeverityAtLeast(tt.severity, tt.threshold); got != tt.want {
+			t.Errorf("SeverityAtLeast(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestLogAlertRuleRequestValidation(t *testing.T) {
+	validator := utils.NewValidator()
+
+	tests := []struct {
+		name      string
+		request   LogAlertRuleRequest
+		wantValid bool
+		wantError string
+	}{
+		{
+			name:      "valid add",
+			request:   LogAlertRuleRequest{Action: "add", Keyword: "OOMKilled", MinLevel: "warn"},
+			wantValid: true,
+		},
+		{
+			name:      "valid remove",
+			request:   LogAlertRuleRequest{Action: "remove", Keyword: "panic"},
+			wantValid: true,
+		},
+		{
+			name:      "invalid action",
+			request:   LogAlertRuleRequest{Action: "toggle", Keyword: "panic"},
+			wantValid: false,
+			wantError: "action",
+		},
+		{
+			name:      "missing keyword",
+			request:   LogAlertRuleRequest{Action: "add"},
+			wantValid: false,
+			wantError: "keyword",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.ValidateStruct(tt.request)
+
+			if tt.wantValid && !result.IsValid {
+				t.Errorf("Expected valid request, got errors: %v", result.Errors)
+			}
+
+			if !tt.wantValid && result.IsValid {
+				t.Errorf("Expected invalid request, but validation passed")
+			}
+
+			if !tt.wantValid && tt.wantError != "" {
+				if _, exists := result.Errors[tt.wantError]; !exists {
+					t.Errorf("Expected error for field %s, got errors: %v", tt.wantError, result.Errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+// RequestModels returns a zero value of every request body model, by type name. These are the
+// models GET /api/schema/:model can describe.
+func RequestModels() map[string]interface{} {
+	return map[string]interface{}{
+		"AIRequest":                 AIRequest{},
+		"AILogAnalysisRequest":      AILogAnalysisRequest{},
+		"AIFeedbackRequest":         AIFeedbackRequest{},
+		"LogSubmissionRequest":      LogSubmissionRequest{},
+		"LogAlertRuleRequest":       LogAlertRuleRequest{},
+		"LogIngestionPauseRequest":  LogIngestionPauseRequest{},
+		"SyncConnectionRequest":     SyncConnectionRequest{},
+		"SyncValidationRequest":     SyncValidationRequest{},
+		"EndpointContract":          EndpointContract{},
+		"ServiceConfigSnapshot":     ServiceConfigSnapshot{},
+		"ChaosRequest":              ChaosRequest{},
+		"ContractValidationRequest": ContractValidationRequest{},
+		"TestRunRequest":            TestRunRequest{},
+		"TestWorkflowRequest":       TestWorkflowRequest{},
+		"TestScheduleRequest":       TestScheduleRequest{},
+		"TestSyncValidationRequest": TestSyncValidationRequest{},
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequestModels(t *testing.T) {
+	for name, model := range RequestModels() {
+		modelType := reflect.TypeOf(model)
+		if modelType.Kind() != reflect.Struct {
+			t.Errorf("%s: expected a struct, got %s", name, modelType.Kind())
+			continue
+		}
+		if modelType.Name() != name {
+			t.Errorf("%s: registered under the wrong name, type is %s", name, modelType.Name())
+		}
+	}
+}
@@ -1,12 +1,20 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // SyncConnectionRequest represents a request to establish sync connection
 type SyncConnectionRequest struct {
 	FrontendURL string `json:"frontend_url" validate:"required,url"`
 	BackendURL  string `json:"backend_url" validate:"required,url"`
 	Environment string `json:"environment" validate:"required,min=1,max=50"`
+	// DefaultHeaders are sent with every validation request to the environment's URLs, e.g.
+	// Authorization; headers on the validation request itself take precedence
+	DefaultHeaders map[string]string `json:"default_headers,omitempty"`
 }
 
 // SyncStatusResponse represents the current sync status
@@ -16,6 +24,9 @@ type SyncStatusResponse struct {
 	LastSync     time.Time         `json:"last_sync"`
 	Environments map[string]string `json:"environments"`
 	Health       HealthStatus      `json:"health"`
+	// EnvironmentCount is how many environments are connected, out of at most MaxEnvironments (0 = unlimited)
+	EnvironmentCount int `json:"environment_count"`
+	MaxEnvironments  int `json:"max_environments"`
 }
 
 // SyncValidationRequest represents a request to validate endpoint compatibility
@@ -25,6 +36,20 @@ type SyncValidationRequest struct {
 	Method           string            `json:"method" validate:"required,oneof=GET POST PUT DELETE PATCH"`
 	Headers          map[string]string `json:"headers"`
 	Payload          interface{}       `json:"payload"`
+
+	// Response header checks, see services/sync_headers.go
+	HeaderAssertions []SyncHeaderAssertion `json:"header_assertions,omitempty" validate:"dive"`
+	CompareHeaders   []string              `json:"compare_headers,omitempty"` // Response headers that must have the same value on both sides
+}
+
+// SyncHeaderAssertion checks one response header of a sync validation. With none of Equals,
+// Contains or Absent set, the header only has to be present.
+type SyncHeaderAssertion struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Target   string `json:"target,omitempty" validate:"omitempty,oneof=frontend backend both"` // Responses checked; empty checks both
+	Equals   string `json:"equals,omitempty"`                                                  // Exact value the header must have
+	Contains string `json:"contains,omitempty"`                                                // Text the value must contain, e.g. "application/json"
+	Absent   bool   `json:"absent,omitempty"`                                                  // The header must not be sent
 }
 
 // SyncValidationResponse represents the result of endpoint validation
@@ -37,7 +62,7 @@ type SyncValidationResponse struct {
 
 // SyncCompatibilityIssue represents a compatibility issue found during validation
 type SyncCompatibilityIssue struct {
-	Type        string `json:"type" validate:"required,oneof=schema_mismatch status_code_mismatch header_mismatch timeout"`
+	Type        string `json:"type" validate:"required,oneof=schema_mismatch status_code_mismatch header_mismatch timeout comparison_truncated"`
 	Field       string `json:"field,omitempty"`
 	Expected    string `json:"expected,omitempty"`
 	Actual      string `json:"actual,omitempty"`
@@ -53,4 +78,59 @@ type SyncEnvironment struct {
 	Status      string            `json:"status" validate:"required,oneof=active inactive error"`
 	LastChecked time.Time         `json:"last_checked"`
 	Metadata    map[string]string `json:"metadata"`
+	// DefaultHeaders may hold credentials, so they are never serialized
+	DefaultHeaders map[string]string `json:"-"`
+}
+
+// SyncEnvironmentCheckResponse is the outcome of an on-demand health check of one environment
+type SyncEnvironmentCheckResponse struct {
+	Environment    SyncEnvironment `json:"environment"`
+	PreviousStatus string          `json:"previous_status"`
+	Health         HealthStatus    `json:"health"`
+}
+
+// ContractFieldTypes are the JSON types an endpoint contract field may declare
+var ContractFieldTypes = []string{"string", "number", "boolean", "object", "array", "null"}
+
+// EndpointContract is the agreed response shape an endpoint must honor. Field paths use dots
+// for nested objects and [] for array elements, e.g. "user.id" or "items[].price".
+type EndpointContract struct {
+	Name       string            `json:"name" validate:"required,min=1,max=100"`
+	Method     string            `json:"method" validate:"required,oneof=GET POST PUT DELETE PATCH"`
+	StatusCode int               `json:"status_code" validate:"min=0,max=599"` // Expected status; 0 accepts any 2xx
+	Fields     map[string]string `json:"fields"`                               // Field path to one of ContractFieldTypes
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// ContractValidationRequest represents a request to check a live endpoint against a stored contract
+type ContractValidationRequest struct {
+	Contract string            `json:"contract" validate:"required,min=1,max=100"`
+	Endpoint string            `json:"endpoint" validate:"required,url"`
+	Headers  map[string]string `json:"headers"`
+	Payload  interface{}       `json:"payload"`
+}
+
+// ParseValidationLimits parses "environment=limit" entries (e.g. "staging=2"). The key is a
+// connected environment name or a target host; a limit of 0 leaves that environment unlimited.
+func ParseValidationLimits(entries []string) (map[string]int, error) {
+	limits := make(map[string]int, len(entries))
+
+	for _, entry := range entries {
+		name, limitStr, hasLimit := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		if name == "" || !hasLimit {
+			return nil, fmt.Errorf("invalid validation limit %q: expected environment=limit", entry)
+		}
+		if _, exists := limits[name]; exists {
+			return nil, fmt.Errorf("invalid validation limit %q: environment listed more than once", entry)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid validation limit %q: limit must be a whole number of at least 0", entry)
+		}
+		limits[name] = limit
+	}
+
+	return limits, nil
 }
@@ -386,3 +386,47 @@ func TestSyncCompatibilityIssueValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestParseValidationLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		expected map[string]int
+		wantErr  bool
+	}{
+		{name: "empty", entries: nil, expected: map[string]int{}},
+		{
+			name:     "environments and hosts",
+			entries:  []string{"staging=2", " api.example.com:8443 = 1 ", "local=0"},
+			expected: map[string]int{"staging": 2, "api.example.com:8443": 1, "local": 0},
+		},
+		{name: "missing limit", entries: []string{"staging"}, wantErr: true},
+		{name: "missing environment", entries: []string{"=2"}, wantErr: true},
+		{name: "negative limit", entries: []string{"staging=-1"}, wantErr: true},
+		{name: "non-numeric limit", entries: []string{"staging=many"}, wantErr: true},
+		{name: "duplicate environment", entries: []string{"staging=1", "staging=2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := ParseValidationLimits(tt.entries)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %v, got %v", tt.entries, limits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %v: %v", tt.entries, err)
+			}
+			if len(limits) != len(tt.expected) {
+				t.Fatalf("Expected %d limits, got %d", len(tt.expected), len(limits))
+			}
+			for name, limit := range tt.expected {
+				if got, ok := limits[name]; !ok || got != limit {
+					t.Errorf("Expected %s=%d, got %d (present: %v)", name, limit, got, ok)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,36 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SupportedFrameworks lists the test frameworks the testing service can run.
+// Handler validation and service checks both derive from this list.
+var SupportedFrameworks = []string{"cypress", "playwright", "jest", "vitest", "mocha", "pytest", "go"}
+
+// FrameworkValidationRule returns the validation rule restricting a value to SupportedFrameworks
+func FrameworkValidationRule() string {
+	return "oneof=" + strings.Join(SupportedFrameworks, " ")
+}
 
 // TestRunRequest represents a request to run tests
 type TestRunRequest struct {
-	Framework   string            `json:"framework" validate:"required,oneof=cypress playwright jest vitest"`
-	TestSuite   string            `json:"test_suite" validate:"required,min=1"`
+	Framework   string            `json:"framework" validate:"required"`
+	TestSuite   string            `json:"test_suite" validate:"required,min=1,startsnotwith=-"`
 	Environment string            `json:"environment" validate:"required,min=1"`
 	Config      map[string]string `json:"config"`
 	Tags        []string          `json:"tags"`
+	SkipCleanup bool              `json:"skip_cleanup"` // Keep workDir artifacts after the run
+	// TimeoutSeconds kills the run if it takes longer; 0 uses the framework's default timeout
+	TimeoutSeconds int `json:"timeout_seconds" validate:"min=0,max=86400"`
+	// CallbackURL receives the final TestResults as a POST once the run completes, fails or is cancelled
+	CallbackURL string `json:"callback_url,omitempty" validate:"omitempty,url,max=2048"`
+	// TestNames limits the run to the test cases with these names, as reported in TestResults. Names
+	// are passed to the test runner as arguments, so they may not start with "-".
+	TestNames []string `json:"test_names,omitempty" validate:"omitempty,max=500,dive,required,max=1024,startsnotwith=-"`
 }
 
 // TestRunResponse represents the response when starting a test run
@@ -19,22 +41,133 @@ type TestRunResponse struct {
 	Framework         string        `json:"framework"`
 	Environment       string        `json:"environment"`
 	EstimatedDuration time.Duration `json:"estimated_duration"`
+	QueuePosition     int           `json:"queue_position,omitempty"`      // 1-based position while queued
+	IgnoredConfigKeys []string      `json:"ignored_config_keys,omitempty"` // Config keys dropped by TEST_CONFIG_ALLOWED_KEYS
 }
 
 // TestResults represents the complete results of a test run
 type TestResults struct {
-	RunID        string        `json:"run_id" validate:"required"`
-	Status       string        `json:"status" validate:"required,oneof=running completed failed cancelled"`
-	TotalTests   int           `json:"total_tests" validate:"min=0"`
-	PassedTests  int           `json:"passed_tests" validate:"min=0"`
-	FailedTests  int           `json:"failed_tests" validate:"min=0"`
-	SkippedTests int           `json:"skipped_tests" validate:"min=0"`
-	Duration     time.Duration `json:"duration"`
-	StartTime    time.Time     `json:"start_time"`
-	EndTime      time.Time     `json:"end_time"`
-	Results      []TestCase    `json:"results"`
-	SyncIssues   []SyncIssue   `json:"sync_issues"`
-	Coverage     *TestCoverage `json:"coverage,omitempty"`
+	RunID        string          `json:"run_id" validate:"required"`
+	Status       string          `json:"status" validate:"required,oneof=running completed failed cancelled"`
+	TotalTests   int             `json:"total_tests" validate:"min=0"`
+	PassedTests  int             `json:"passed_tests" validate:"min=0"`
+	FailedTests  int             `json:"failed_tests" validate:"min=0"`
+	SkippedTests int             `json:"skipped_tests" validate:"min=0"`
+	Duration     time.Duration   `json:"duration"`
+	StartTime    time.Time       `json:"start_time"`
+	EndTime      time.Time       `json:"end_time"`
+	Results      []TestCase      `json:"results"`
+	SyncIssues   []SyncIssue     `json:"sync_issues"`
+	Coverage     *TestCoverage   `json:"coverage,omitempty"`
+	Reason       string          `json:"reason,omitempty"`    // Why a failed or cancelled run stopped, e.g. TestRunReasonTimeout
+	Request      *TestRunRequest `json:"request,omitempty"`   // The request the run was started with; redacted outside the test service
+	RetryOf      string          `json:"retry_of,omitempty"`  // ID of the run this run re-runs
+	Artifacts    []Artifact      `json:"artifacts,omitempty"` // Screenshots, videos and traces kept from the run
+}
+
+// Artifact kinds
+const (
+	ArtifactKindScreenshot = "screenshot"
+	ArtifactKindVideo      = "video"
+	ArtifactKindTrace      = "trace"
+)
+
+// Artifact is a file a test run produced, such as a failure screenshot or video, copied to the
+// artifact directory so it can be downloaded after the run's workDir is cleaned up
+type Artifact struct {
+	Name        string    `json:"name"` // Unique within the run; used in the download URL
+	Kind        string    `json:"kind"` // ArtifactKindScreenshot, ArtifactKindVideo or ArtifactKindTrace
+	Path        string    `json:"path"` // Where the framework wrote it, relative to the run's working directory
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	URL         string    `json:"url"`
+}
+
+// TestRunReasonTimeout marks a run that was killed for exceeding its timeout
+const TestRunReasonTimeout = "timeout"
+
+// TestRunReasonShutdown marks a run that was cancelled because the server shut down
+const TestRunReasonShutdown = "shutdown"
+
+// TestWorkflowMaxSteps caps the number of steps in a single workflow
+const TestWorkflowMaxSteps = 20
+
+// TestWorkflowRequest defines a workflow of test runs executed one after another, e.g. seeding
+// data, then API tests, then UI tests against the seeded data
+type TestWorkflowRequest struct {
+	Name  string             `json:"name" validate:"required,min=1"`
+	Steps []TestWorkflowStep `json:"steps"`
+}
+
+// TestWorkflowStep is one test run of a workflow
+type TestWorkflowStep struct {
+	Name string         `json:"name" validate:"required,min=1"`
+	Run  TestRunRequest `json:"run" validate:"required"`
+	// ContinueOnFailure runs the following steps even if this one fails; its failure then
+	// doesn't fail the workflow
+	ContinueOnFailure bool `json:"continue_on_failure"`
+}
+
+// TestWorkflowResult represents the progress and aggregated results of a workflow
+type TestWorkflowResult struct {
+	WorkflowID   string                   `json:"workflow_id"`
+	Name         string                   `json:"name"`
+	Status       string                   `json:"status" validate:"oneof=running completed failed cancelled"`
+	CurrentStep  int                      `json:"current_step"` // 1-based step being run; 0 once finished
+	TotalTests   int                      `json:"total_tests"`
+	PassedTests  int                      `json:"passed_tests"`
+	FailedTests  int                      `json:"failed_tests"`
+	SkippedTests int                      `json:"skipped_tests"`
+	Duration     time.Duration            `json:"duration"`
+	StartTime    time.Time                `json:"start_time"`
+	EndTime      time.Time                `json:"end_time"`
+	Steps        []TestWorkflowStepResult `json:"steps"`
+}
+
+// TestWorkflowStepResult represents the outcome of one workflow step
+type TestWorkflowStepResult struct {
+	Name              string       `json:"name"`
+	Status            string       `json:"status" validate:"oneof=pending running completed failed cancelled skipped"`
+	RunID             string       `json:"run_id,omitempty"`
+	ContinueOnFailure bool         `json:"continue_on_failure"`
+	Error             string       `json:"error,omitempty"` // Why the step's run couldn't be started
+	Results           *TestResults `json:"results,omitempty"`
+}
+
+// Overlap policies of a test schedule, applied when it is due while its previous run is still going
+const (
+	TestScheduleOverlapSkip  = "skip"  // Don't start a run this time
+	TestScheduleOverlapQueue = "queue" // Start one run as soon as the previous one finishes
+)
+
+// TestScheduleRequest represents a request to start a test run whenever a cron expression is due
+type TestScheduleRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+	// Cron is a five-field cron expression, e.g. "0 2 * * 1-5", or a descriptor such as @daily
+	Cron     string `json:"cron" validate:"required,max=100"`
+	Timezone string `json:"timezone,omitempty"` // IANA time zone Cron is read in; empty is UTC
+	// Overlap decides what happens when a run is due while the previous one is still going;
+	// empty is TestScheduleOverlapSkip
+	Overlap string         `json:"overlap,omitempty" validate:"omitempty,oneof=skip queue"`
+	Run     TestRunRequest `json:"run" validate:"required"`
+}
+
+// TestSchedule is a stored test schedule and the state of its runs
+type TestSchedule struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Cron      string         `json:"cron"`
+	Timezone  string         `json:"timezone"`
+	Overlap   string         `json:"overlap"`
+	Run       TestRunRequest `json:"run"`
+	CreatedAt time.Time      `json:"created_at"`
+	NextRun   time.Time      `json:"next_run"` // Zero when the expression matches no time in the next five years
+
+	LastTriggered time.Time `json:"last_triggered"` // Zero until the schedule has started a run
+	LastRunID     string    `json:"last_run_id,omitempty"`
+	Pending       bool      `json:"pending"`      // A run was due and waits for the last one to finish
+	SkippedRuns   int       `json:"skipped_runs"` // Runs not started because the last one was still going
 }
 
 // TestCase represents an individual test case result
@@ -88,10 +221,34 @@ type TestSyncValidationRequest struct {
 	APIEndpoint string            `json:"api_endpoint" validate:"required,url"`
 	UIComponent string            `json:"ui_component" validate:"required,min=1"`
 	TestData    interface{}       `json:"test_data"`
-	Assertions  []SyncAssertion   `json:"assertions" validate:"required,min=1"`
+	Assertions  []SyncAssertion   `json:"assertions" validate:"required,min=1,dive"`
 	Config      map[string]string `json:"config"`
 }
 
+// SyncTimeoutConfigKey is the TestSyncValidationRequest.Config key holding the per-assertion timeout
+const SyncTimeoutConfigKey = "timeout"
+
+// ParseSyncTimeout parses a per-assertion timeout given either as a Go duration ("1500ms", "2s")
+// or as a whole number of seconds
+func ParseSyncTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid timeout %q: use a duration like \"5s\" or a number of seconds", value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be greater than zero", value)
+	}
+
+	return timeout, nil
+}
+
 // SyncAssertion represents an assertion for sync validation
 type SyncAssertion struct {
 	Type        string      `json:"type" validate:"required,oneof=data_match status_match timing_match ui_state"`
@@ -116,8 +273,12 @@ type SyncAssertionResult struct {
 	Passed    bool          `json:"passed"`
 	Actual    interface{}   `json:"actual"`
 	Message   string        `json:"message"`
+	Reason    string        `json:"reason,omitempty"` // Machine-readable failure reason, e.g. "timeout"
 }
 
+// SyncAssertionReasonTimeout marks an assertion that did not finish within its timeout
+const SyncAssertionReasonTimeout = "timeout"
+
 // PerformanceMetrics represents performance metrics for sync validation
 type PerformanceMetrics struct {
 	APIResponseTime  time.Duration `json:"api_response_time"`
@@ -125,3 +286,36 @@ type PerformanceMetrics struct {
 	TotalSyncTime    time.Duration `json:"total_sync_time"`
 	DataTransferSize int64         `json:"data_transfer_size"`
 }
+
+// FrameworkInfo represents the availability of a test framework on the host
+type FrameworkInfo struct {
+	Name      string    `json:"name" validate:"required"`
+	Installed bool      `json:"installed"`
+	Version   string    `json:"version,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// TestRunArchiveManifest describes the runs in a test history archive; it is stored as manifest.json
+type TestRunArchiveManifest struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	From        *time.Time            `json:"from,omitempty"`
+	To          *time.Time            `json:"to,omitempty"`
+	WorkflowID  string                `json:"workflow_id,omitempty"`
+	RunCount    int                   `json:"run_count"`
+	Runs        []TestRunArchiveEntry `json:"runs"`
+}
+
+// TestRunArchiveEntry summarizes one run of a test history archive and names its files
+type TestRunArchiveEntry struct {
+	RunID        string    `json:"run_id"`
+	Status       string    `json:"status"`
+	TotalTests   int       `json:"total_tests"`
+	PassedTests  int       `json:"passed_tests"`
+	FailedTests  int       `json:"failed_tests"`
+	SkippedTests int       `json:"skipped_tests"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	ResultsFile  string    `json:"results_file"` // JSON test results
+	JUnitFile    string    `json:"junit_file"`   // JUnit XML report
+}
@@ -44,9 +44,9 @@ func TestTestRunRequestValidation(t *testing.T) {
 			wantValid: true,
 		},
 		{
-			name: "invalid framework",
+			name: "missing framework",
 			request: TestRunRequest{
-				Framework:   "invalid_framework",
+				Framework:   "",
 				TestSuite:   "test.spec.js",
 				Environment: "development",
 				Config:      map[string]string{},
@@ -79,6 +79,112 @@ func TestTestRunRequestValidation(t *testing.T) {
 			wantValid: false,
 			wantError: "environment",
 		},
+		{
+			name: "valid timeout",
+			request: TestRunRequest{
+				Framework:      "cypress",
+				TestSuite:      "e2e/checkout.cy.js",
+				Environment:    "staging",
+				TimeoutSeconds: 900,
+			},
+			wantValid: true,
+		},
+		{
+			name: "negative timeout",
+			request: TestRunRequest{
+				Framework:      "cypress",
+				TestSuite:      "e2e/checkout.cy.js",
+				Environment:    "staging",
+				TimeoutSeconds: -5,
+			},
+			wantValid: false,
+			wantError: "timeout_seconds",
+		},
+		{
+			name: "timeout longer than a day",
+			request: TestRunRequest{
+				Framework:      "cypress",
+				TestSuite:      "e2e/checkout.cy.js",
+				Environment:    "staging",
+				TimeoutSeconds: 86401,
+			},
+			wantValid: false,
+			wantError: "timeout_seconds",
+		},
+		{
+			name: "valid request with callback URL",
+			request: TestRunRequest{
+				Framework:   "cypress",
+				TestSuite:   "e2e/checkout.cy.js",
+				Environment: "staging",
+				CallbackURL: "https://ci.example.com/hooks/tests",
+			},
+			wantValid: true,
+		},
+		{
+			name: "invalid callback URL",
+			request: TestRunRequest{
+				Framework:   "cypress",
+				TestSuite:   "e2e/checkout.cy.js",
+				Environment: "staging",
+				CallbackURL: "not a url",
+			},
+			wantValid: false,
+			wantError: "callback_url",
+		},
+		{
+			name: "valid test names",
+			request: TestRunRequest{
+				Framework:   "playwright",
+				TestSuite:   "e2e",
+				Environment: "staging",
+				TestNames:   []string{"checkout pays", "tests/test_cart.py::test_add"},
+			},
+			wantValid: true,
+		},
+		{
+			name: "test name passed as a flag",
+			request: TestRunRequest{
+				Framework:   "pytest",
+				TestSuite:   "tests/",
+				Environment: "staging",
+				TestNames:   []string{"--rootdir=/"},
+			},
+			wantValid: false,
+			wantError: "test_names[0]",
+		},
+		{
+			name: "test suite passed as a flag",
+			request: TestRunRequest{
+				Framework:   "mocha",
+				TestSuite:   "--require=/tmp/x.js",
+				Environment: "staging",
+			},
+			wantValid: false,
+			wantError: "test_suite",
+		},
+		{
+			name: "too many test names",
+			request: TestRunRequest{
+				Framework:   "jest",
+				TestSuite:   "cart",
+				Environment: "staging",
+				TestNames:   make([]string, 501),
+			},
+			wantValid: false,
+			wantError: "test_names",
+		},
+		{
+			name: "blank test name",
+			request: TestRunRequest{
+				Framework:   "jest",
+				TestSuite:   "cart",
+				Environment: "staging",
+				TestNames:   []string{""},
+			},
+			wantValid: false,
+			wantError: "test_names[0]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -102,6 +208,59 @@ func TestTestRunRequestValidation(t *testing.T) {
 	}
 }
 
+func TestFrameworkValidationRule(t *testing.T) {
+	validator := utils.NewValidator()
+
+	for _, framework := range SupportedFrameworks {
+		result := validator.ValidateValue("framework", framework, FrameworkValidationRule())
+		if !result.IsValid {
+			t.Errorf("Expected %s to be valid, got errors: %v", framework, result.Errors)
+		}
+	}
+
+	result := validator.ValidateValue("framework", "invalid_framework", FrameworkValidationRule())
+	if result.IsValid {
+		t.Errorf("Expected invalid framework to fail validation")
+	}
+	if _, exists := result.Errors["framework"]; !exists {
+		t.Errorf("Expected error for field framework, got errors: %v", result.Errors)
+	}
+}
+
+func TestParseSyncTimeout(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "5s", expected: 5 * time.Second},
+		{value: "1500ms", expected: 1500 * time.Millisecond},
+		{value: "3", expected: 3 * time.Second},
+		{value: " 2s ", expected: 2 * time.Second},
+		{value: "0", wantErr: true},
+		{value: "-1s", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			timeout, err := ParseSyncTimeout(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %v", tt.value, timeout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.value, err)
+			}
+			if timeout != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, timeout)
+			}
+		})
+	}
+}
+
 func TestTestRunResponseValidation(t *testing.T) {
 	validator := utils.NewValidator()
 
@@ -503,3 +662,93 @@ func TestSyncIssueValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestTestSyncValidationRequestValidation(t *testing.T) {
+	validator := utils.NewValidator()
+
+	validAssertion := SyncAssertion{Type: "status_match", Operator: "equals", Expected: 200}
+
+	tests := []struct {
+		name       string
+		assertions []SyncAssertion
+		wantErrors []string
+	}{
+		{
+			name:       "valid assertions",
+			assertions: []SyncAssertion{validAssertion, {Type: "ui_state", Operator: "exists", Field: "#list"}},
+		},
+		{
+			name:       "invalid assertion type",
+			assertions: []SyncAssertion{validAssertion, {Type: "eventually", Operator: "equals"}},
+			wantErrors: []string{"assertions[1].type"},
+		},
+		{
+			name:       "every invalid assertion is reported",
+			assertions: []SyncAssertion{{Type: "data_match"}, validAssertion, {Operator: "contains"}},
+			wantErrors: []string{"assertions[0].operator", "assertions[2].type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.ValidateStruct(TestSyncValidationRequest{
+				APIEndpoint: "https://api.example.com/users",
+				UIComponent: "UserList",
+				Assertions:  tt.assertions,
+			})
+
+			if len(result.Errors) != len(tt.wantErrors) {
+				t.Errorf("Expected errors for %v, got %v", tt.wantErrors, result.Errors)
+			}
+			for _, field := range tt.wantErrors {
+				if _, exists := result.Errors[field]; !exists {
+					t.Errorf("Expected error for field %s, got errors: %v", field, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestTestWorkflowRequestValidation(t *testing.T) {
+	step := TestWorkflowStep{Name: "seed"}
+
+	result := utils.NewValidator().ValidateStruct(step)
+	if result.IsValid {
+		t.Fatal("Expected a step without a run to be invalid")
+	}
+	for _, field := range []string{"run.framework", "run.test_suite", "run.environment"} {
+		if _, exists := result.Errors[field]; !exists {
+			t.Errorf("Expected error for field %s, got errors: %v", field, result.Errors)
+		}
+	}
+}
+
+func TestTestScheduleRequestValidation(t *testing.T) {
+	validator := utils.NewValidator()
+	run := TestRunRequest{Framework: "jest", TestSuite: "unit", Environment: "staging"}
+
+	valid := TestScheduleRequest{Name: "nightly", Cron: "0 2 * * *", Overlap: TestScheduleOverlapQueue, Run: run}
+	if result := validator.ValidateStruct(valid); !result.IsValid {
+		t.Fatalf("Expected schedule to be valid, got errors: %v", result.Errors)
+	}
+
+	tests := map[string]struct {
+		request   TestScheduleRequest
+		wantError string
+	}{
+		"missing name":    {TestScheduleRequest{Cron: "@daily", Run: run}, "name"},
+		"missing cron":    {TestScheduleRequest{Name: "nightly", Run: run}, "cron"},
+		"unknown overlap": {TestScheduleRequest{Name: "nightly", Cron: "@daily", Overlap: "cancel", Run: run}, "overlap"},
+		"missing run":     {TestScheduleRequest{Name: "nightly", Cron: "@daily"}, "run.framework"},
+	}
+	for name, tt := range tests {
+		result := validator.ValidateStruct(tt.request)
+		if result.IsValid {
+			t.Errorf("%s: expected schedule to be invalid", name)
+			continue
+		}
+		if _, exists := result.Errors[tt.wantError]; !exists {
+			t.Errorf("%s: expected error for field %s, got errors: %v", name, tt.wantError, result.Errors)
+		}
+	}
+}
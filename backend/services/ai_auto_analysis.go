@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// Auto analysis queue settings used when the AI_AUTO_ANALYSIS_* options are unset
+const (
+	defaultAutoAnalysisConcurrency = 1
+	defaultAutoAnalysisWindow      = 5 * time.Minute
+	autoAnalysisTimeout            = 2 * time.Minute
+)
+
+// ErrAutoAnalysisDuplicate is returned by QueueLogAnalysis for a key already queued within the dedupe window
+var ErrAutoAnalysisDuplicate = errors.New("log analysis already queued for this key")
+
+// ErrAutoAnalysisQueueFull is returned by QueueLogAnalysis while every worker is busy and the queue is full
+var ErrAutoAnalysisQueueFull = errors.New("log analysis queue is full")
+
+// autoAnalysisJob is a queued log analysis and the callback that receives its result
+type autoAnalysisJob struct {
+	key  string
+	req  *models.AILogAnalysisRequest
+	done func(*models.AILogAnalysisResponse, error)
+}
+
+// autoAnalysisQueue runs automatically triggered log analyses, at most maxRunning at once with up
+// to maxQueued waiting. Workers are started as jobs arrive and exit once the queue is empty.
+type autoAnalysisQueue struct {
+	mu           sync.Mutex
+	maxRunning   int
+	maxQueued    int
+	dedupeWindow time.Duration
+	analyze      func(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error)
+	now          func() time.Time
+
+	pending    []autoAnalysisJob
+	running    int
+	lastQueued map[string]time.Time // Key -> when its latest analysis was queued, within dedupeWindow
+
+	completed    int
+	deduplicated int
+	dropped      int
+}
+
+// newAutoAnalysisQueue returns a queue running analyze; maxRunning below 1 runs one at a time
+func newAutoAnalysisQueue(maxRunning, maxQueued int, dedupeWindow time.Duration,
+	analyze func(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error)) *autoAnalysisQueue {
+	if maxRunning <= 0 {
+		maxRunning = defaultAutoAnalysisConcurrency
+	}
+	if maxQueued < 0 {
+		maxQueued = 0
+	}
+	if dedupeWindow <= 0 {
+		dedupeWindow = defaultAutoAnalysisWindow
+	}
+	return &autoAnalysisQueue{
+		maxRunning:   maxRunning,
+		maxQueued:    maxQueued,
+		dedupeWindow: dedupeWindow,
+		analyze:      analyze,
+		now:          time.Now,
+		lastQueued:   make(map[string]time.Time),
+	}
+}
+
+// enqueue starts job, or queues it while every worker is busy
+func (q *autoAnalysisQueue) enqueue(job autoAnalysisJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	for key, queuedAt := range q.lastQueued {
+		if now.Sub(queuedAt) >= q.dedupeWindow {
+			delete(q.lastQueued, key)
+		}
+	}
+
+	if _, queued := q.lastQueued[job.key]; queued {
+		q.deduplicated++
+		return fmt.Errorf("%w: %s", ErrAutoAnalysisDuplicate, job.key)
+	}
+	if q.running >= q.maxRunning && len(q.pending) >= q.maxQueued {
+		q.dropped++
+		return fmt.Errorf("%w: %d waiting", ErrAutoAnalysisQueueFull, len(q.pending))
+	}
+
+	q.lastQueued[job.key] = now
+	if q.running < q.maxRunning {
+		q.running++
+		go q.work(job)
+	} else {
+		q.pending = append(q.pending, job)
+	}
+	return nil
+}
+
+// work runs job, then the queued jobs after it until none are left
+func (q *autoAnalysisQueue) work(job autoAnalysisJob) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), autoAnalysisTimeout)
+		response, err := q.analyze(ctx, job.req)
+		cancel()
+		if job.done != nil {
+			job.done(response, err)
+		}
+
+		q.mu.Lock()
+		q.completed++
+		if len(q.pending) == 0 {
+			q.running--
+			q.mu.Unlock()
+			return
+		}
+		job = q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+	}
+}
+
+// stats reports the queue depth and how many triggers were run, deduplicated or dropped
+func (q *autoAnalysisQueue) stats() map[string]interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return map[string]interface{}{
+		"queued":          len(q.pending),
+		"running":         q.running,
+		"max_concurrency": q.maxRunning,
+		"queue_size":      q.maxQueued,
+		"dedupe_window":   q.dedupeWindow.String(),
+		"completed":       q.completed,
+		"deduplicated":    q.deduplicated,
+		"dropped":         q.dropped,
+	}
+}
+
+// QueueLogAnalysis queues an automatically triggered log analysis, such as one for an error spike.
+// A key already queued within AI_AUTO_ANALYSIS_WINDOW_SECONDS returns ErrAutoAnalysisDuplicate, so
+// one spike produces one analysis. While AI_AUTO_ANALYSIS_CONCURRENCY analyses are running and
+// AI_AUTO_ANALYSIS_QUEUE_SIZE are waiting, ErrAutoAnalysisQueueFull is returned. done, when set,
+// receives the result once the analysis has run.
+func (s *AIService) QueueLogAnalysis(key string, req *models.AILogAnalysisRequest, done func(*models.AILogAnalysisResponse, error)) error {
+	return s.autoAnalysis.enqueue(autoAnalysisJob{key: key, req: req, done: done})
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoAnalysisQueue(t *testing.T) {
+	release := make(chan struct{})
+	queue := newAutoAnalysisQueue(1, 1, time.Minute, func(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error) {
+		<-release
+		return &models.AILogAnalysisResponse{Summary: req.Filters["fingerprint"]}, nil
+	})
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	queue.now = func() time.Time { return now }
+
+	results := make(chan string, 3)
+	job := func(key string) autoAnalysisJob {
+		return autoAnalysisJob{
+			key: key,
+			req: &models.AILogAnalysisRequest{Filters: map[string]string{"fingerprint": key}},
+			done: func(response *models.AILogAnalysisResponse, err error) {
+				require.NoError(t, err)
+				results <- response.Summary
+			},
+		}
+	}
+
+	require.NoError(t, queue.enqueue(job("a")))
+	require.NoError(t, queue.enqueue(job("b")), "waits for the running analysis")
+	assert.True(t, errors.Is(queue.enqueue(job("c")), ErrAutoAnalysisQueueFull))
+	assert.True(t, errors.Is(queue.enqueue(job("a")), ErrAutoAnalysisDuplicate))
+
+	stats := queue.stats()
+	assert.Equal(t, 1, stats["running"])
+	assert.Equal(t, 1, stats["queued"])
+	assert.Equal(t, 1, stats["dropped"])
+	assert.Equal(t, 1, stats["deduplicated"])
+
+	close(release)
+	assert.Equal(t, "a", <-results)
+	assert.Equal(t, "b", <-results)
+	assert.Eventually(t, func() bool { return queue.stats()["running"] == 0 }, time.Second, 10*time.Millisecond)
+
+	stats = queue.stats()
+	assert.Equal(t, 2, stats["completed"])
+	assert.Equal(t, 0, stats["queued"])
+
+	// Finished keys stay deduplicated until the window has passed
+	assert.True(t, errors.Is(queue.enqueue(job("a")), ErrAutoAnalysisDuplicate))
+	now = now.Add(time.Minute)
+	require.NoError(t, queue.enqueue(job("a")))
+	assert.Equal(t, "a", <-results)
+}
+
+func TestAutoAnalysisQueue_Concurrency(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	queue := newAutoAnalysisQueue(2, 0, time.Minute, func(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error) {
+		started <- struct{}{}
+		<-release
+		return &models.AILogAnalysisResponse{}, nil
+	})
+
+	require.NoError(t, queue.enqueue(autoAnalysisJob{key: "a"}))
+	require.NoError(t, queue.enqueue(autoAnalysisJob{key: "b"}))
+	assert.True(t, errors.Is(queue.enqueue(autoAnalysisJob{key: "c"}), ErrAutoAnalysisQueueFull),
+		"a zero queue size only runs analyses while a slot is free")
+
+	<-started
+	<-started
+	assert.Equal(t, 2, queue.stats()["running"])
+	close(release)
+	assert.Eventually(t, func() bool { return queue.stats()["completed"] == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestAIService_GetStatus_AutoAnalysis(t *testing.T) {
+	service := NewAIService(&config.Config{
+		AIAutoAnalysisConcurrency:   3,
+		AIAutoAnalysisQueueSize:     5,
+		AIAutoAnalysisWindowSeconds: 120,
+	}, nil, utils.NewLogger("debug", "json"))
+
+	stats := service.GetStatus()["auto_analysis"].(map[string]interface{})
+	assert.Equal(t, 3, stats["max_concurrency"])
+	assert.Equal(t, 5, stats["queue_size"])
+	assert.Equal(t, "2m0s", stats["dedupe_window"])
+	assert.Equal(t, 0, stats["queued"])
+	assert.Equal(t, 0, stats["running"])
+}
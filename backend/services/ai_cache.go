@@ -0,0 +1,131 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// aiResponseCache is a size-bounded LRU of code suggestion responses whose entries expire after a TTL
+type aiResponseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Front is the most recently used
+	now        func() time.Time
+}
+
+// aiCacheEntry is one cached response; the element value of aiResponseCache.order
+type aiCacheEntry struct {
+	key       string
+	response  models.AIResponse
+	expiresAt time.Time
+}
+
+// newAIResponseCache creates a cache, or returns nil when either limit disables caching
+func newAIResponseCache(ttl time.Duration, maxEntries int) *aiResponseCache {
+	if ttl <= 0 || maxEntries <= 0 {
+		return nil
+	}
+	return &aiResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// get returns a copy of the response cached under key, dropping it if it has expired
+func (c *aiResponseCache) get(key string) (*models.AIResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*aiCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	response := entry.response
+	response.Suggestions = append([]models.Suggestion(nil), entry.response.Suggestions...)
+	return &response, true
+}
+
+// put caches a copy of response under key, evicting the least recently used entry when full
+func (c *aiResponseCache) put(key string, response *models.AIResponse) {
+	if c == nil {
+		return
+	}
+
+	entry := &aiCacheEntry{key: key, response: *response, expiresAt: c.now().Add(c.ttl)}
+	entry.response.Suggestions = append([]models.Suggestion(nil), response.Suggestions...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*aiCacheEntry).key)
+	}
+}
+
+// len returns the number of cached entries, including expired ones not yet dropped
+func (c *aiResponseCache) len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// codeSuggestionCacheKey hashes everything that shapes a code suggestion reply: the normalized
+// request and the resolved completion options, so each model of an A/B split is cached separately
+func codeSuggestionCacheKey(req *models.AIRequest, options completionOptions) string {
+	normalize := func(s string) string {
+		return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	}
+
+	hash := sha256.New()
+	for _, part := range []string{
+		normalize(req.Code),
+		strings.ToLower(strings.TrimSpace(req.Language)),
+		strings.ToLower(strings.TrimSpace(req.RequestType)),
+		normalize(req.Context),
+		strings.ToLower(strings.TrimSpace(req.Format)),
+		options.model,
+		fmt.Sprintf("%g", options.temperature),
+		fmt.Sprintf("%d", options.maxTokens),
+	} {
+		// Length-prefix each part so adjacent fields can't run into each other
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
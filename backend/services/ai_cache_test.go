@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestAIResponseCache(t *testing.T) {
+	now := time.Now()
+	cache := newAIResponseCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", &models.AIResponse{RequestID: "a", Suggestions: []models.Suggestion{{Description: "first"}}})
+	cache.put("b", &models.AIResponse{RequestID: "b"})
+
+	t.Run("hits return copies", func(t *testing.T) {
+		response, hit := cache.get("a")
+		require.True(t, hit)
+		assert.Equal(t, "a", response.RequestID)
+
+		response.RequestID = "changed"
+		response.Suggestions[0].Description = "changed"
+		again, _ := cache.get("a")
+		assert.Equal(t, "a", again.RequestID)
+		assert.Equal(t, "first", again.Suggestions[0].Description)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		// "a" was just read, so "b" is the oldest
+		cache.put("c", &models.AIResponse{RequestID: "c"})
+		assert.Equal(t, 2, cache.len())
+
+		_, hit := cache.get("b")
+		assert.False(t, hit)
+		_, hit = cache.get("a")
+		assert.True(t, hit)
+		_, hit = cache.get("c")
+		assert.True(t, hit)
+	})
+
+	t.Run("entries expire after the TTL", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, hit := cache.get("a")
+		assert.False(t, hit)
+		assert.Equal(t, 1, cache.len())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, newAIResponseCache(0, 10))
+		assert.Nil(t, newAIResponseCache(time.Minute, 0))
+
+		var disabled *aiResponseCache
+		disabled.put("a", &models.AIResponse{})
+		_, hit := disabled.get("a")
+		assert.False(t, hit)
+		assert.Equal(t, 0, disabled.len())
+	})
+}
+
+func TestCodeSuggestionCacheKey(t *testing.T) {
+	req := &models.AIRequest{Code: "let x = 1;\r\n", Language: "javascript", RequestType: "suggestion"}
+	options := completionOptions{model: "gpt-4o", temperature: 0.3, maxTokens: 1000}
+	key := codeSuggestionCacheKey(req, options)
+
+	assert.Equal(t, key, codeSuggestionCacheKey(&models.AIRequest{
+		Code: "  let x = 1;\n", Language: "JavaScript", RequestType: "suggestion", RequestID: "other", NoCache: true,
+	}, options), "whitespace, case and per-call fields don't change the key")
+
+	assert.NotEqual(t, key, codeSuggestionCacheKey(&models.AIRequest{Code: "let x = 2;", Language: "javascript", RequestType: "suggestion"}, options))
+	assert.NotEqual(t, key, codeSuggestionCacheKey(&models.AIRequest{Code: "let x = 1;", Language: "typescript", RequestType: "suggestion"}, options))
+	assert.NotEqual(t, key, codeSuggestionCacheKey(&models.AIRequest{Code: "let x = 1;", Language: "javascript", RequestType: "debug"}, options))
+	assert.NotEqual(t, key, codeSuggestionCacheKey(req, completionOptions{model: "gpt-4", temperature: 0.3, maxTokens: 1000}))
+}
+
+func TestAIService_GetCodeSuggestions_Cache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": `[{"type": "fix", "description": "Use const", "priority": "low"}]`}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150},
+		})
+	}))
+	defer server.Close()
+
+	var notifications []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "ai_suggestion_ready", mock.Anything).Run(func(args mock.Arguments) {
+		notifications = append(notifications, args.Get(1).(map[string]interface{}))
+	}).Return()
+
+	cfg := &config.Config{OpenAIAPIKey: "test-key", AICacheTTLSeconds: 60, AICacheMaxEntries: 10}
+	service := NewAIService(cfg, mockHub, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	request := func(requestID string, noCache bool) *models.AIResponse {
+		response, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+			Code:        "var x = 1;",
+			Language:    "javascript",
+			RequestType: "suggestion",
+			Model:       "gpt-4o",
+			RequestID:   requestID,
+			NoCache:     noCache,
+		})
+		require.NoError(t, err)
+		return response
+	}
+
+	first := request("req-1", false)
+	assert.False(t, first.Cached)
+	assert.Equal(t, int32(1), calls.Load())
+
+	second := request("req-2", false)
+	assert.True(t, second.Cached)
+	assert.Equal(t, "req-2", second.RequestID)
+	assert.Equal(t, first.Suggestions, second.Suggestions)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Hits are still announced and can receive feedback under their own request ID
+	require.Len(t, notifications, 2)
+	assert.Equal(t, "req-2", notifications[1]["request_id"])
+	_, err := service.RecordFeedback(&models.AIFeedbackRequest{RequestID: "req-2", SuggestionIndex: 0, Helpful: boolPtr(true)})
+	assert.NoError(t, err)
+
+	bypassed := request("req-3", true)
+	assert.False(t, bypassed.Cached)
+	assert.Equal(t, int32(2), calls.Load())
+
+	assert.Equal(t, 1, service.GetStatus()["cached_responses"])
+}
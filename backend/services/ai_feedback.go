@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// maxTrackedSuggestionResponses caps how many suggestion responses can still receive feedback
+const maxTrackedSuggestionResponses = 1000
+
+// maxStoredFeedback caps how many feedback entries are kept and aggregated
+const maxStoredFeedback = 1000
+
+// ErrAIRequestNotFound is returned when feedback names a request that was never served or has aged out
+var ErrAIRequestNotFound = errors.New("AI request not found")
+
+// ErrSuggestionIndexOutOfRange is returned when feedback names a suggestion the response didn't contain
+var ErrSuggestionIndexOutOfRange = errors.New("suggestion index out of range")
+
+// servedSuggestions records what a suggestion response contained so feedback can be checked against it
+type servedSuggestions struct {
+	model string
+	count int
+}
+
+// feedbackKey identifies one rated suggestion; newer feedback on the same suggestion replaces older
+type feedbackKey struct {
+	requestID string
+	index     int
+}
+
+// trackSuggestionResponse remembers a served response so it can receive feedback
+func (s *AIService) trackSuggestionResponse(response *models.AIResponse) {
+	model := response.Model
+	if model == "" {
+		model = models.AIFeedbackFallbackModel
+	}
+
+	s.feedbackMu.Lock()
+	defer s.feedbackMu.Unlock()
+
+	if _, exists := s.servedResponses[response.RequestID]; !exists {
+		s.servedOrder = append(s.servedOrder, response.RequestID)
+	}
+	s.servedResponses[response.RequestID] = servedSuggestions{model: model, count: len(response.Suggestions)}
+
+	if len(s.servedOrder) > maxTrackedSuggestionResponses {
+		delete(s.servedResponses, s.servedOrder[0])
+		s.servedOrder = s.servedOrder[1:]
+	}
+}
+
+// RecordFeedback stores a helpful/unhelpful vote on one suggestion of a recent response
+func (s *AIService) RecordFeedback(req *models.AIFeedbackRequest) (*models.AIFeedback, error) {
+	if req.Helpful == nil {
+		return nil, fmt.Errorf("helpful is required")
+	}
+
+	s.feedbackMu.Lock()
+	defer s.feedbackMu.Unlock()
+
+	served, exists := s.servedResponses[req.RequestID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAIRequestNotFound, req.RequestID)
+	}
+	if req.SuggestionIndex < 0 || req.SuggestionIndex >= served.count {
+		return nil, fmt.Errorf("%w: request %s has %d suggestion(s)", ErrSuggestionIndexOutOfRange, req.RequestID, served.count)
+	}
+
+	feedback := &models.AIFeedback{
+		RequestID:       req.RequestID,
+		SuggestionIndex: req.SuggestionIndex,
+		Helpful:         *req.Helpful,
+		Comment:         req.Comment,
+		Model:           served.model,
+		RecordedAt:      time.Now(),
+	}
+
+	key := feedbackKey{requestID: req.RequestID, index: req.SuggestionIndex}
+	if _, exists := s.feedback[key]; !exists {
+		s.feedbackOrder = append(s.feedbackOrder, key)
+	}
+	s.feedback[key] = feedback
+
+	if len(s.feedbackOrder) > maxStoredFeedback {
+		delete(s.feedback, s.feedbackOrder[0])
+		s.feedbackOrder = s.feedbackOrder[1:]
+	}
+
+	s.logger.WithSource("ai_service").Info("AI suggestion feedback recorded", map[string]interface{}{
+		"request_id":       feedback.RequestID,
+		"suggestion_index": feedback.SuggestionIndex,
+		"helpful":          feedback.Helpful,
+		"model":            feedback.Model,
+	})
+
+	result := *feedback
+	return &result, nil
+}
+
+// GetFeedbackSummary aggregates stored feedback overall and per model
+func (s *AIService) GetFeedbackSummary() models.AIFeedbackSummary {
+	s.feedbackMu.Lock()
+	defer s.feedbackMu.Unlock()
+
+	var total models.AIFeedbackStats
+	byModel := make(map[string]*models.AIFeedbackStats)
+
+	for _, feedback := range s.feedback {
+		if byModel[feedback.Model] == nil {
+			byModel[feedback.Model] = &models.AIFeedbackStats{}
+		}
+		addFeedbackVote(&total, feedback.Helpful)
+		addFeedbackVote(byModel[feedback.Model], feedback.Helpful)
+	}
+
+	summary := models.AIFeedbackSummary{
+		AIFeedbackStats: total,
+		ByModel:         make([]models.AIFeedbackGroup, 0, len(byModel)),
+	}
+	for model, stats := range byModel {
+		summary.ByModel = append(summary.ByModel, models.AIFeedbackGroup{Model: model, AIFeedbackStats: *stats})
+	}
+	sort.Slice(summary.ByModel, func(i, j int) bool {
+		return summary.ByModel[i].Model < summary.ByModel[j].Model
+	})
+
+	return summary
+}
+
+// addFeedbackVote counts one vote and updates the helpful rate
+func addFeedbackVote(stats *models.AIFeedbackStats, helpful bool) {
+	stats.Total++
+	if helpful {
+		stats.Helpful++
+	} else {
+		stats.Unhelpful++
+	}
+	stats.HelpfulRate = float64(stats.Helpful) / float64(stats.Total) * 100
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestAIService_RecordFeedback(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	// Without an API key the service serves fallback responses, which can be rated too
+	response, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+		Code: "var x = 1;", Language: "javascript", RequestType: "suggestion",
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Suggestions, 1)
+
+	t.Run("records feedback", func(t *testing.T) {
+		feedback, err := service.RecordFeedback(&models.AIFeedbackRequest{
+			RequestID: response.RequestID, SuggestionIndex: 0, Helpful: boolPtr(false), Comment: "Not specific enough",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, response.RequestID, feedback.RequestID)
+		assert.False(t, feedback.Helpful)
+		assert.Equal(t, "Not specific enough", feedback.Comment)
+		assert.Equal(t, models.AIFeedbackFallbackModel, feedback.Model)
+		assert.False(t, feedback.RecordedAt.IsZero())
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		_, err := service.RecordFeedback(&models.AIFeedbackRequest{RequestID: "missing", Helpful: boolPtr(true)})
+		assert.ErrorIs(t, err, ErrAIRequestNotFound)
+	})
+
+	t.Run("suggestion index out of range", func(t *testing.T) {
+		_, err := service.RecordFeedback(&models.AIFeedbackRequest{
+			RequestID: response.RequestID, SuggestionIndex: 1, Helpful: boolPtr(true),
+		})
+		assert.ErrorIs(t, err, ErrSuggestionIndexOutOfRange)
+	})
+
+	t.Run("newer feedback replaces older", func(t *testing.T) {
+		_, err := service.RecordFeedback(&models.AIFeedbackRequest{
+			RequestID: response.RequestID, SuggestionIndex: 0, Helpful: boolPtr(true),
+		})
+		require.NoError(t, err)
+
+		summary := service.GetFeedbackSummary()
+		assert.Equal(t, 1, summary.Total)
+		assert.Equal(t, 1, summary.Helpful)
+	})
+}
+
+func TestAIService_GetFeedbackSummary(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	// Responses from two models, each with two suggestions
+	for i, model := range []string{"gpt-3.5-turbo", "gpt-3.5-turbo", "gpt-4o-mini"} {
+		service.trackSuggestionResponse(&models.AIResponse{
+			RequestID:   fmt.Sprintf("req-%d", i),
+			Model:       model,
+			Suggestions: make([]models.Suggestion, 2),
+		})
+	}
+
+	votes := []struct {
+		requestID string
+		index     int
+		helpful   bool
+	}{
+		{"req-0", 0, true},
+		{"req-0", 1, false},
+		{"req-1", 0, true},
+		{"req-2", 0, true},
+		{"req-2", 1, true},
+	}
+	for _, vote := range votes {
+		_, err := service.RecordFeedback(&models.AIFeedbackRequest{
+			RequestID: vote.requestID, SuggestionIndex: vote.index, Helpful: boolPtr(vote.helpful),
+		})
+		require.NoError(t, err)
+	}
+
+	summary := service.GetFeedbackSummary()
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 4, summary.Helpful)
+	assert.Equal(t, 1, summary.Unhelpful)
+	assert.InDelta(t, 80.0, summary.HelpfulRate, 1e-9)
+
+	require.Len(t, summary.ByModel, 2)
+	assert.Equal(t, "gpt-3.5-turbo", summary.ByModel[0].Model)
+	assert.Equal(t, 3, summary.ByModel[0].Total)
+	assert.InDelta(t, 200.0/3, summary.ByModel[0].HelpfulRate, 1e-9)
+	assert.Equal(t, "gpt-4o-mini", summary.ByModel[1].Model)
+	assert.InDelta(t, 100.0, summary.ByModel[1].HelpfulRate, 1e-9)
+}
+
+func TestAIService_FeedbackIsBounded(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	for i := 0; i < maxTrackedSuggestionResponses+5; i++ {
+		service.trackSuggestionResponse(&models.AIResponse{
+			RequestID:   fmt.Sprintf("req-%d", i),
+			Model:       "gpt-3.5-turbo",
+			Suggestions: make([]models.Suggestion, 2),
+		})
+	}
+
+	// The oldest responses can no longer be rated
+	_, err := service.RecordFeedback(&models.AIFeedbackRequest{RequestID: "req-0", Helpful: boolPtr(true)})
+	assert.ErrorIs(t, err, ErrAIRequestNotFound)
+
+	// Rate both suggestions of enough responses to overflow the feedback store
+	for i := 5; i < maxStoredFeedback/2+105; i++ {
+		for index := 0; index < 2; index++ {
+			_, err := service.RecordFeedback(&models.AIFeedbackRequest{
+				RequestID: fmt.Sprintf("req-%d", i), SuggestionIndex: index, Helpful: boolPtr(true),
+			})
+			require.NoError(t, err)
+		}
+	}
+	assert.Len(t, service.feedback, maxStoredFeedback)
+	assert.Len(t, service.feedbackOrder, maxStoredFeedback)
+	assert.NotContains(t, service.feedback, feedbackKey{requestID: "req-5", index: 0})
+	assert.Equal(t, maxStoredFeedback, service.GetFeedbackSummary().Total)
+}
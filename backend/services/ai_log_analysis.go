@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// logAnalysisSystemPrompt asks for an analysis as a JSON object that parseLogAnalysisJSON can read
+const logAnalysisSystemPrompt = `You are an expert log analyst. Analyze logs to identify issues, patterns, and provide actionable suggestions.
+Respond with a JSON object only, without Markdown or any other text. It has these fields:
+- "summary": a short summary of the main issues found
+- "issues": an array of objects, each with:
+  - "type": one of "error_spike", "performance_degradation", "security_concern", "data_inconsistency"
+  - "description": a one-sentence description of the issue
+  - "severity": one of "critical", "high", "medium", "low"
+  - "solution": how to resolve it
+  - "affected_components": the components involved
+  - "log_ids": the IDs of the logs showing the issue
+  - "fingerprints": the fingerprints of those logs, where they have one
+- "patterns": an array of objects with "pattern", "frequency" and "description"
+- "suggestions": an array of actionable suggestions
+Only use log IDs and fingerprints given with the logs.`
+
+// minMentionedReferenceLength is the shortest log ID or fingerprint looked for in an issue's text
+// when the model gave no references. Shorter IDs, e.g. "1", would match unrelated text.
+const minMentionedReferenceLength = 8
+
+var logIssueTypes = []string{"error_spike", "performance_degradation", "security_concern", "data_inconsistency"}
+
+// logAnalysisReply is the JSON object logAnalysisSystemPrompt asks for
+type logAnalysisReply struct {
+	Summary     string              `json:"summary"`
+	Issues      []models.LogIssue   `json:"issues"`
+	Patterns    []models.LogPattern `json:"patterns"`
+	Suggestions []string            `json:"suggestions"`
+}
+
+// parseLogAnalysisJSON reads a JSON analysis from a model reply, tolerating a surrounding
+// Markdown code fence. Issues without a description are dropped and unknown types or severities
+// are normalized. Each issue's references are resolved against logs by linkLogIssue. The bool is
+// false when the reply is not a JSON object with a summary or issues.
+func parseLogAnalysisJSON(content string, logs []models.LogEntry) (*models.AILogAnalysisResponse, bool) {
+	var reply logAnalysisReply
+	if err := json.Unmarshal([]byte(stripCodeFence(content)), &reply); err != nil {
+		return nil, false
+	}
+	reply.Summary = strings.TrimSpace(reply.Summary)
+	if reply.Summary == "" && reply.Issues == nil {
+		return nil, false
+	}
+
+	issues := make([]models.LogIssue, 0, len(reply.Issues))
+	for _, issue := range reply.Issues {
+		issue.Description = strings.TrimSpace(issue.Description)
+		if issue.Description == "" {
+			continue
+		}
+
+		issue.Type = strings.ToLower(strings.TrimSpace(issue.Type))
+		if !slices.Contains(logIssueTypes, issue.Type) {
+			issue.Type = "general"
+		}
+		issue.Severity = strings.ToLower(strings.TrimSpace(issue.Severity))
+		if !slices.Contains(models.LogIssueSeverities, issue.Severity) {
+			issue.Severity = "medium"
+		}
+
+		linkLogIssue(&issue, logs)
+		issues = append(issues, issue)
+	}
+
+	patterns := make([]models.LogPattern, 0, len(reply.Patterns))
+	for _, pattern := range reply.Patterns {
+		pattern.Pattern = strings.TrimSpace(pattern.Pattern)
+		if pattern.Pattern == "" {
+			continue
+		}
+		if pattern.Frequency < 1 {
+			pattern.Frequency = 1
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	suggestions := make([]string, 0, len(reply.Suggestions))
+	for _, suggestion := range reply.Suggestions {
+		if suggestion = strings.TrimSpace(suggestion); suggestion != "" {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return &models.AILogAnalysisResponse{
+		Summary:     reply.Summary,
+		Issues:      issues,
+		Patterns:    patterns,
+		Suggestions: suggestions,
+	}, true
+}
+
+// linkLogIssue points issue at the analyzed logs it is about. A log matches when the model
+// referenced its ID or fingerprint; without references, IDs and fingerprints mentioned in the
+// issue's text are used instead. References to logs that weren't analyzed are dropped. LogIDs
+// and Fingerprints are then set from the matching logs, in log order, and Count, FirstSeen and
+// LastSeen from their timestamps. An issue no log matches counts once, seen now.
+func linkLogIssue(issue *models.LogIssue, logs []models.LogEntry) {
+	matches := func(entry models.LogEntry) bool {
+		return slices.Contains(issue.LogIDs, entry.ID) ||
+			(entry.Fingerprint != "" && slices.Contains(issue.Fingerprints, entry.Fingerprint))
+	}
+	if len(issue.LogIDs) == 0 && len(issue.Fingerprints) == 0 {
+		text := issue.Description + "\n" + issue.Solution
+		mentioned := func(reference string) bool {
+			return len(reference) >= minMentionedReferenceLength && strings.Contains(text, reference)
+		}
+		matches = func(entry models.LogEntry) bool {
+			return mentioned(entry.ID) || mentioned(entry.Fingerprint)
+		}
+	}
+
+	var logIDs, fingerprints []string
+	var firstSeen, lastSeen time.Time
+	for _, entry := range logs {
+		if !matches(entry) {
+			continue
+		}
+		logIDs = append(logIDs, entry.ID)
+		if entry.Fingerprint != "" && !slices.Contains(fingerprints, entry.Fingerprint) {
+			fingerprints = append(fingerprints, entry.Fingerprint)
+		}
+		if firstSeen.IsZero() || entry.Timestamp.Before(firstSeen) {
+			firstSeen = entry.Timestamp
+		}
+		if entry.Timestamp.After(lastSeen) {
+			lastSeen = entry.Timestamp
+		}
+	}
+
+	issue.LogIDs = logIDs
+	issue.Fingerprints = fingerprints
+	if len(logIDs) == 0 {
+		now := time.Now()
+		issue.Count, issue.FirstSeen, issue.LastSeen = 1, now, now
+		return
+	}
+	issue.Count, issue.FirstSeen, issue.LastSeen = len(logIDs), firstSeen, lastSeen
+}
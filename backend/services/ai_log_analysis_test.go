@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyzedLogs() []models.LogEntry {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return []models.LogEntry{
+		{ID: "log-0001-aaaa", Timestamp: base, Level: "error", Message: "db timeout", Fingerprint: "3f2a9c1d7e6b5a40"},
+		{ID: "log-0002-bbbb", Timestamp: base.Add(time.Minute), Level: "info", Message: "user signed in"},
+		{ID: "log-0003-cccc", Timestamp: base.Add(2 * time.Minute), Level: "error", Message: "db timeout", Fingerprint: "3f2a9c1d7e6b5a40"},
+		{ID: "log-0004-dddd", Timestamp: base.Add(3 * time.Minute), Level: "error", Message: "token expired", Fingerprint: "9b8c7d6e5f4a3b2c"},
+	}
+}
+
+func TestParseLogAnalysisJSON(t *testing.T) {
+	logs := analyzedLogs()
+
+	t.Run("links issues to the referenced logs", func(t *testing.T) {
+		content := "```json\n" + `{
+			"summary": "Database timeouts and expired tokens",
+			"issues": [
+				{"type": "error_spike", "description": "Database queries time out", "severity": "high", "fingerprints": ["3f2a9c1d7e6b5a40"]},
+				{"type": "security_concern", "description": "Expired tokens are used", "severity": "medium", "log_ids": ["log-0004-dddd", "log-9999-made-up"]}
+			],
+			"patterns": [{"pattern": "db timeout", "frequency": 2, "description": "Repeated timeouts"}, {"pattern": ""}],
+			"suggestions": ["Raise the pool size", " "]
+		}` + "\n```"
+
+		analysis, ok := parseLogAnalysisJSON(content, logs)
+		require.True(t, ok)
+		assert.Equal(t, "Database timeouts and expired tokens", analysis.Summary)
+		require.Len(t, analysis.Issues, 2)
+
+		issue := analysis.Issues[0]
+		assert.Equal(t, []string{"log-0001-aaaa", "log-0003-cccc"}, issue.LogIDs, "a fingerprint links every log sharing it")
+		assert.Equal(t, []string{"3f2a9c1d7e6b5a40"}, issue.Fingerprints)
+		assert.Equal(t, 2, issue.Count)
+		assert.Equal(t, logs[0].Timestamp, issue.FirstSeen)
+		assert.Equal(t, logs[2].Timestamp, issue.LastSeen)
+
+		issue = analysis.Issues[1]
+		assert.Equal(t, []string{"log-0004-dddd"}, issue.LogIDs, "unknown log IDs are dropped")
+		assert.Equal(t, []string{"9b8c7d6e5f4a3b2c"}, issue.Fingerprints)
+		assert.Equal(t, 1, issue.Count)
+
+		assert.Equal(t, []models.LogPattern{{Pattern: "db timeout", Frequency: 2, Description: "Repeated timeouts"}}, analysis.Patterns)
+		assert.Equal(t, []string{"Raise the pool size"}, analysis.Suggestions)
+	})
+
+	t.Run("falls back to references mentioned in the text", func(t *testing.T) {
+		content := `{"summary": "Tokens", "issues": [
+			{"type": "Auth", "description": "Token expiry, see fingerprint 9b8c7d6e5f4a3b2c", "severity": "BLOCKER"},
+			{"type": "error_spike", "description": "Something is off", "severity": "low"}
+		]}`
+
+		analysis, ok := parseLogAnalysisJSON(content, logs)
+		require.True(t, ok)
+		require.Len(t, analysis.Issues, 2)
+
+		issue := analysis.Issues[0]
+		assert.Equal(t, "general", issue.Type)
+		assert.Equal(t, "medium", issue.Severity)
+		assert.Equal(t, []string{"log-0004-dddd"}, issue.LogIDs)
+		assert.Equal(t, []string{"9b8c7d6e5f4a3b2c"}, issue.Fingerprints)
+
+		issue = analysis.Issues[1]
+		assert.Empty(t, issue.LogIDs)
+		assert.Empty(t, issue.Fingerprints)
+		assert.Equal(t, 1, issue.Count)
+		assert.False(t, issue.FirstSeen.IsZero())
+	})
+
+	t.Run("not a JSON analysis", func(t *testing.T) {
+		for _, content := range []string{"Analysis of your logs shows several issues...", `[]`, `{}`} {
+			_, ok := parseLogAnalysisJSON(content, logs)
+			assert.False(t, ok, content)
+		}
+	})
+}
+
+func TestAIService_LogAnalysisReferences(t *testing.T) {
+	service := NewAIService(&config.Config{OpenAIAPIKey: "test-key"}, nil, utils.NewLogger("debug", "json"))
+	req := &models.AILogAnalysisRequest{Logs: analyzedLogs(), AnalysisType: "error_detection"}
+
+	prompt := service.buildLogAnalysisPrompt(req)
+	assert.Contains(t, prompt, "ID: log-0001-aaaa")
+	assert.Contains(t, prompt, "Fingerprint: 3f2a9c1d7e6b5a40")
+	assert.NotContains(t, prompt, "Fingerprint: \n")
+
+	t.Run("every sampled log is sent", func(t *testing.T) {
+		logService := NewLogService(nil, nil, LogServiceConfig{MaxAILogs: DefaultMaxAILogs})
+		logs := make([]models.LogEntry, DefaultMaxAILogs*2)
+		for i := range logs {
+			logs[i] = models.LogEntry{ID: fmt.Sprintf("log-%04d", i), Level: "error", Message: fmt.Sprintf("failure %d", i)}
+		}
+		sampled := logService.sampleAILogs(logs)
+		require.Len(t, sampled, DefaultMaxAILogs)
+
+		prompt := service.buildLogAnalysisPrompt(&models.AILogAnalysisRequest{Logs: sampled, AnalysisType: "error_detection"})
+		for _, entry := range sampled {
+			assert.Contains(t, prompt, "ID: "+entry.ID+"\n")
+		}
+	})
+
+	analysis := service.parseLogAnalysis(`{"summary": "Timeouts", "issues": [{"description": "DB timeouts", "log_ids": ["log-0003-cccc"]}]}`, req)
+	require.Len(t, analysis.Issues, 1)
+	assert.Equal(t, []string{"log-0003-cccc"}, analysis.Issues[0].LogIDs)
+	assert.Equal(t, []string{"3f2a9c1d7e6b5a40"}, analysis.Issues[0].Fingerprints)
+}
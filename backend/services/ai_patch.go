@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// patchFormatPrompt is added to the system prompt for the patch format
+const patchFormatPrompt = `
+The "code" field of each suggestion must be a unified diff against the submitted code, e.g.:
+@@ -3,2 +3,2 @@
+ unchanged line
+-removed line
++added line
+Include a few unchanged context lines around each change. Use "" when there is no change to make.`
+
+// patchValidationTimeout bounds how long an external syntax check may run
+const patchValidationTimeout = 10 * time.Second
+
+// PatchValidator checks that patched code is syntactically valid for its language
+type PatchValidator interface {
+	Validate(ctx context.Context, code string) error
+}
+
+// goSyntaxValidator parses Go code in-process. Snippets without a package clause are parsed
+// as the body of a file, so submitted functions validate on their own.
+type goSyntaxValidator struct{}
+
+func (goSyntaxValidator) Validate(ctx context.Context, code string) error {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "patched.go", code, parser.AllErrors); err == nil {
+		return nil
+	} else if strings.HasPrefix(strings.TrimSpace(code), "package ") {
+		return err
+	}
+
+	// Offer the snippet as top-level declarations, then as statements
+	if _, err := parser.ParseFile(fset, "patched.go", "package snippet\n"+code, parser.AllErrors); err == nil {
+		return nil
+	}
+	_, err := parser.ParseFile(fset, "patched.go", "package snippet\nfunc _() {\n"+code+"\n}", parser.AllErrors)
+	return err
+}
+
+// commandPatchValidator writes the code to a temporary file and runs a command on it; a
+// non-zero exit means the code is invalid
+type commandPatchValidator struct {
+	command   []string
+	extension string
+}
+
+func (v commandPatchValidator) Validate(ctx context.Context, code string) error {
+	dir, err := os.MkdirTemp("", "ai-patch-*")
+	if err != nil {
+		return fmt.Errorf("failed to prepare syntax check: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "patched"+v.extension)
+	if err := os.WriteFile(path, []byte(code), 0o600); err != nil {
+		return fmt.Errorf("failed to prepare syntax check: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, patchValidationTimeout)
+	defer cancel()
+
+	args := append(append([]string{}, v.command[1:]...), path)
+	output, err := exec.CommandContext(ctx, v.command[0], args...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(strings.ReplaceAll(string(output), path, "patched"+v.extension))
+		if message == "" {
+			message = err.Error()
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+// languageExtensions are the file extensions syntax check commands expect
+var languageExtensions = map[string]string{
+	"javascript": ".js",
+	"typescript": ".ts",
+	"python":     ".py",
+	"go":         ".go",
+	"java":       ".java",
+	"rust":       ".rs",
+	"php":        ".php",
+	"swift":      ".swift",
+	"kotlin":     ".kt",
+	"dart":       ".dart",
+}
+
+// newPatchValidators builds the per-language syntax checks from AI_PATCH_VALIDATORS entries
+func newPatchValidators(commands map[string]string) map[string]PatchValidator {
+	validators := make(map[string]PatchValidator, len(commands))
+	for language, command := range commands {
+		if command == models.AIPatchValidatorBuiltin {
+			validators[language] = goSyntaxValidator{}
+			continue
+		}
+		validators[language] = commandPatchValidator{
+			command:   strings.Fields(command),
+			extension: languageExtensions[language],
+		}
+	}
+	return validators
+}
+
+// applySuggestionPatches applies each suggestion's diff to the submitted code and runs the
+// language's syntax check, if one is configured, on the result
+func (s *AIService) applySuggestionPatches(ctx context.Context, req *models.AIRequest, suggestions []models.Suggestion) {
+	validator := s.patchValidators[strings.ToLower(req.Language)]
+
+	for i := range suggestions {
+		if strings.TrimSpace(suggestions[i].Code) == "" {
+			continue
+		}
+
+		patch := &models.SuggestionPatch{}
+		suggestions[i].Patch = patch
+
+		patched, err := applyUnifiedDiff(req.Code, suggestions[i].Code)
+		if err != nil {
+			patch.Error = err.Error()
+			continue
+		}
+		patch.Applied = true
+		patch.PatchedCode = patched
+
+		if validator != nil {
+			patch.SyntaxChecked = true
+			if err := validator.Validate(ctx, patched); err != nil {
+				patch.Error = "patched code is invalid: " + err.Error()
+				continue
+			}
+		}
+		patch.Valid = true
+	}
+
+	var applied, valid int
+	for _, suggestion := range suggestions {
+		if suggestion.Patch != nil && suggestion.Patch.Applied {
+			applied++
+		}
+		if suggestion.Patch != nil && suggestion.Patch.Valid {
+			valid++
+		}
+	}
+	s.logger.WithSource("ai_service").Debug("Applied suggestion patches", map[string]interface{}{
+		"language":       req.Language,
+		"suggestions":    len(suggestions),
+		"applied":        applied,
+		"valid":          valid,
+		"syntax_checked": validator != nil,
+	})
+}
+
+// diffHunk is one @@ section of a unified diff
+type diffHunk struct {
+	oldStart int      // 1-based line the hunk starts at in the original
+	lines    []string // Each prefixed with ' ', '-' or '+'
+}
+
+// applyUnifiedDiff applies a unified diff to code. Each hunk's context and removed lines must
+// match the code; a hunk is tried at its stated line first and then searched for below the
+// previous hunk, so diffs with slightly wrong line numbers still apply.
+func applyUnifiedDiff(code, diff string) (string, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	trailingNewline := strings.HasSuffix(code, "\n")
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	if code == "" {
+		lines = nil
+	}
+
+	var result []string
+	next := 0 // First original line not yet copied to result
+	for i, hunk := range hunks {
+		var before, after []string
+		for _, line := range hunk.lines {
+			switch line[0] {
+			case ' ':
+				before = append(before, line[1:])
+				after = append(after, line[1:])
+			case '-':
+				before = append(before, line[1:])
+			case '+':
+				after = append(after, line[1:])
+			}
+		}
+
+		at := findHunk(lines, before, hunk.oldStart-1, next)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d does not apply: its context doesn't match the submitted code", i+1)
+		}
+
+		result = append(result, lines[next:at]...)
+		result = append(result, after...)
+		next = at + len(before)
+	}
+	result = append(result, lines[next:]...)
+
+	patched := strings.Join(result, "\n")
+	if trailingNewline && len(result) > 0 {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// findHunk returns where before occurs in lines at or after from, preferring the stated line.
+// Trailing whitespace is ignored when comparing. Returns -1 when it doesn't occur.
+func findHunk(lines, before []string, stated, from int) int {
+	matchesAt := func(at int) bool {
+		if at < from || at+len(before) > len(lines) {
+			return false
+		}
+		for i, line := range before {
+			if strings.TrimRight(lines[at+i], " \t\r") != strings.TrimRight(line, " \t\r") {
+				return false
+			}
+		}
+		return true
+	}
+
+	if matchesAt(stated) {
+		return stated
+	}
+	for at := from; at+len(before) <= len(lines); at++ {
+		if matchesAt(at) {
+			return at
+		}
+	}
+	return -1
+}
+
+// parseUnifiedDiff reads the hunks of a unified diff, tolerating a surrounding code fence and
+// ---/+++ file headers. Lines of a hunk without a prefix are treated as context.
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	diff = strings.ReplaceAll(stripCodeFence(diff), "\r\n", "\n")
+
+	var hunks []diffHunk
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			oldStart, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, diffHunk{oldStart: oldStart})
+		case len(hunks) == 0:
+			// Headers such as diff --git, --- a/file and +++ b/file before the first hunk
+			continue
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+			continue
+		case line == "":
+			hunks[len(hunks)-1].lines = append(hunks[len(hunks)-1].lines, " ")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunks[len(hunks)-1].lines = append(hunks[len(hunks)-1].lines, line)
+		default:
+			hunks[len(hunks)-1].lines = append(hunks[len(hunks)-1].lines, " "+line)
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, errors.New("suggestion is not a unified diff: no @@ hunk header found")
+	}
+	for i := range hunks {
+		// A blank line closing the diff isn't part of the last hunk
+		for n := len(hunks[i].lines); n > 0 && hunks[i].lines[n-1] == " "; n-- {
+			hunks[i].lines = hunks[i].lines[:n-1]
+		}
+	}
+	return hunks, nil
+}
+
+// parseHunkHeader returns the original start line of a "@@ -start,count +start,count @@" header
+func parseHunkHeader(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	startText, _, _ := strings.Cut(strings.TrimPrefix(fields[1], "-"), ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start < 0 {
+		return 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	if start == 0 {
+		// "@@ -0,0 +1,3 @@" adds lines to empty code
+		start = 1
+	}
+	return start, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patchTestCode = `func total(items []int) int {
+	sum := 0
+	for i := 0; i <= len(items); i++ {
+		sum += items[i]
+	}
+	return sum
+}
+`
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		expected string
+	}{
+		{
+			name: "single hunk",
+			diff: "--- a/main.go\n+++ b/main.go\n@@ -2,3 +2,3 @@\n \tsum := 0\n-\tfor i := 0; i <= len(items); i++ {\n+\tfor i := 0; i < len(items); i++ {\n \t\tsum += items[i]\n",
+			expected: `func total(items []int) int {
+	sum := 0
+	for i := 0; i < len(items); i++ {
+		sum += items[i]
+	}
+	return sum
+}
+`,
+		},
+		{
+			name: "wrong line numbers and a code fence",
+			diff: "```diff\n@@ -40,2 +40,3 @@\n \t}\n+\t// Sum of all items\n \treturn sum\n```",
+			expected: `func total(items []int) int {
+	sum := 0
+	for i := 0; i <= len(items); i++ {
+		sum += items[i]
+	}
+	// Sum of all items
+	return sum
+}
+`,
+		},
+		{
+			name: "several hunks",
+			diff: "@@ -1,1 +1,1 @@\n-func total(items []int) int {\n+func Total(items []int) int {\n@@ -6,1 +6,1 @@\n-\treturn sum\n+\treturn sum // total\n",
+			expected: `func Total(items []int) int {
+	sum := 0
+	for i := 0; i <= len(items); i++ {
+		sum += items[i]
+	}
+	return sum // total
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := applyUnifiedDiff(patchTestCode, tt.diff)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, patched)
+		})
+	}
+
+	t.Run("adding to empty code", func(t *testing.T) {
+		patched, err := applyUnifiedDiff("", "@@ -0,0 +1,2 @@\n+package main\n+\n")
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n", patched)
+	})
+}
+
+func TestApplyUnifiedDiff_Errors(t *testing.T) {
+	tests := map[string]string{
+		"replacement code instead of a diff": "func total(items []int) int { return 0 }",
+		"context that isn't in the code":     "@@ -2,2 +2,2 @@\n \tsum := 1\n-\treturn sum\n+\treturn 0\n",
+		"hunks out of order":                 "@@ -6,1 +6,1 @@\n-\treturn sum\n+\treturn 0\n@@ -1,1 +1,1 @@\n-func total(items []int) int {\n+func Total(items []int) int {\n",
+		"bad hunk header":                    "@@ -x,1 +1,1 @@\n-a\n+b\n",
+	}
+
+	for name, diff := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := applyUnifiedDiff(patchTestCode, diff)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGoSyntaxValidator(t *testing.T) {
+	validator := goSyntaxValidator{}
+	ctx := context.Background()
+
+	assert.NoError(t, validator.Validate(ctx, "package main\n\nfunc main() {}\n"))
+	assert.NoError(t, validator.Validate(ctx, patchTestCode), "declarations without a package clause")
+	assert.NoError(t, validator.Validate(ctx, "x := 1\nfmt.Println(x)\n"), "statements")
+	assert.Error(t, validator.Validate(ctx, "func total(items []int) int {\n\treturn sum +\n"))
+	assert.Error(t, validator.Validate(ctx, "package main\n\nfunc main() {\n"))
+}
+
+func TestCommandPatchValidator(t *testing.T) {
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("true and false are not available")
+	}
+	validators := newPatchValidators(map[string]string{"python": "true", "php": "false", "go": models.AIPatchValidatorBuiltin})
+
+	assert.IsType(t, goSyntaxValidator{}, validators["go"])
+	assert.Equal(t, ".py", validators["python"].(commandPatchValidator).extension)
+	assert.NoError(t, validators["python"].Validate(context.Background(), "print('hi')\n"))
+	assert.Error(t, validators["php"].Validate(context.Background(), "<?php echo 'hi';\n"))
+}
+
+func TestAIService_ApplySuggestionPatches(t *testing.T) {
+	service := NewAIService(&config.Config{AIPatchValidators: []string{"go=builtin"}}, nil, utils.NewLogger("debug", "json"))
+	req := &models.AIRequest{Code: patchTestCode, Language: "go", RequestType: "debug", Format: models.AIFormatPatch}
+
+	suggestions := []models.Suggestion{
+		{Description: "Fix off-by-one", Code: "@@ -3,1 +3,1 @@\n-\tfor i := 0; i <= len(items); i++ {\n+\tfor i := 0; i < len(items); i++ {\n"},
+		{Description: "Breaks the syntax", Code: "@@ -6,1 +6,1 @@\n-\treturn sum\n+\treturn sum +\n"},
+		{Description: "Doesn't apply", Code: "@@ -6,1 +6,1 @@\n-\treturn total\n+\treturn 0\n"},
+		{Description: "No change"},
+	}
+	service.applySuggestionPatches(context.Background(), req, suggestions)
+
+	fixed := suggestions[0].Patch
+	require.NotNil(t, fixed)
+	assert.True(t, fixed.Applied)
+	assert.True(t, fixed.Valid)
+	assert.True(t, fixed.SyntaxChecked)
+	assert.Contains(t, fixed.PatchedCode, "i < len(items)")
+	assert.Empty(t, fixed.Error)
+
+	broken := suggestions[1].Patch
+	assert.True(t, broken.Applied)
+	assert.False(t, broken.Valid)
+	assert.Contains(t, broken.Error, "patched code is invalid")
+
+	unapplied := suggestions[2].Patch
+	assert.False(t, unapplied.Applied)
+	assert.False(t, unapplied.Valid)
+	assert.False(t, unapplied.SyntaxChecked)
+	assert.Contains(t, unapplied.Error, "hunk 1 does not apply")
+
+	assert.Nil(t, suggestions[3].Patch)
+
+	t.Run("languages without a syntax check are valid once applied", func(t *testing.T) {
+		python := &models.AIRequest{Code: "x = 1\n", Language: "python", Format: models.AIFormatPatch}
+		suggestions := []models.Suggestion{{Description: "Rename", Code: "@@ -1 +1 @@\n-x = 1\n+count = 1\n"}}
+		service.applySuggestionPatches(context.Background(), python, suggestions)
+
+		assert.True(t, suggestions[0].Patch.Valid)
+		assert.False(t, suggestions[0].Patch.SyntaxChecked)
+		assert.Equal(t, "count = 1\n", suggestions[0].Patch.PatchedCode)
+	})
+
+	t.Run("invalid settings disable syntax checks", func(t *testing.T) {
+		service := NewAIService(&config.Config{AIPatchValidators: []string{"python=builtin"}}, nil, utils.NewLogger("debug", "json"))
+		assert.Empty(t, service.patchValidators)
+	})
+}
+
+func TestCodeSuggestionCacheKey_Format(t *testing.T) {
+	code := &models.AIRequest{Code: "x = 1", Language: "python", RequestType: "debug"}
+	patch := &models.AIRequest{Code: "x = 1", Language: "python", RequestType: "debug", Format: models.AIFormatPatch}
+	assert.NotEqual(t, codeSuggestionCacheKey(code, completionOptions{}), codeSuggestionCacheKey(patch, completionOptions{}))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Names of the AI providers, in the order requests prefer them
+const (
+	AIProviderPrimary   = "primary"
+	AIProviderSecondary = "secondary"
+)
+
+// ErrAIProviderNotFound is returned for an AI provider name that has no API key configured
+var ErrAIProviderNotFound = errors.New("AI provider not configured")
+
+// Circuit breaker settings used when AI_CIRCUIT_MAX_FAILURES or AI_CIRCUIT_OPEN_SECONDS is unset
+const (
+	defaultAICircuitMaxFailures = 3
+	defaultAICircuitOpenTime    = 60 * time.Second
+)
+
+// aiProvider is an OpenAI-compatible API. Each provider has its own circuit breaker and retries, so
+// one provider's failures never trip another's. available, lastError and lastCheck are guarded by
+// AIService.mu.
+type aiProvider struct {
+	name           string
+	baseURL        string
+	client         *openai.Client
+	circuitBreaker *utils.CircuitBreaker
+	retryExecutor  *utils.RetryExecutor
+
+	available bool
+	lastError error
+	lastCheck time.Time
+}
+
+// newAIProviders returns the configured providers: the primary for OPENAI_API_KEY, then the
+// secondary for AI_SECONDARY_API_KEY. Providers without an API key are left out.
+func newAIProviders(cfg *config.Config, logger *utils.Logger) []*aiProvider {
+	providers := make([]*aiProvider, 0, 2)
+	if cfg.OpenAIAPIKey != "" {
+		providers = append(providers, newAIProvider(AIProviderPrimary, "openai_api", cfg.OpenAIAPIKey, "", cfg, logger))
+	}
+	if cfg.AISecondaryAPIKey != "" {
+		providers = append(providers, newAIProvider(AIProviderSecondary, "openai_api_secondary",
+			cfg.AISecondaryAPIKey, cfg.AISecondaryBaseURL, cfg, logger))
+	}
+	return providers
+}
+
+// newAIProvider creates a provider with its own client, circuit breaker and retry executor
+func newAIProvider(name, breakerName, apiKey, baseURL string, cfg *config.Config, logger *utils.Logger) *aiProvider {
+	// Use connection pooling for OpenAI API calls
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.HTTPClient = utils.OpenAIConnectionPool().GetClient()
+	if baseURL != "" {
+		clientConfig.BaseURL = baseURL
+	}
+
+	maxFailures := cfg.AICircuitMaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultAICircuitMaxFailures
+	}
+	openTime := time.Duration(cfg.AICircuitOpenSeconds) * time.Second
+	if openTime <= 0 {
+		openTime = defaultAICircuitOpenTime
+	}
+
+	cbConfig := &utils.CircuitBreakerConfig{
+		MaxFailures:      maxFailures,
+		Timeout:          openTime,
+		MaxRequests:      2,
+		SuccessThreshold: 2,
+		Name:             breakerName,
+	}
+
+	return &aiProvider{
+		name:           name,
+		baseURL:        clientConfig.BaseURL,
+		client:         openai.NewClientWithConfig(clientConfig),
+		circuitBreaker: utils.NewCircuitBreaker(cbConfig, logger),
+		retryExecutor:  utils.NewRetryExecutor(defaultAIRetryConfig(), logger),
+		available:      true,
+		lastCheck:      time.Now(),
+	}
+}
+
+// executeWithFailover runs call with retries and circuit breaking against the first provider whose
+// circuit isn't open. When the call leaves that provider's circuit open, the next provider is tried.
+// When every circuit is open, the primary's circuit breaker error is returned. retry overrides the
+// providers' retry policy for this call; nil keeps it.
+func (s *AIService) executeWithFailover(ctx context.Context, retry *utils.RetryExecutor,
+	call func(ctx context.Context, provider *aiProvider) error) error {
+	candidates := make([]*aiProvider, 0, len(s.providers))
+	for _, provider := range s.providers {
+		if !provider.circuitBreaker.IsOpen() {
+			candidates = append(candidates, provider)
+		}
+	}
+	if len(candidates) == 0 && len(s.providers) > 0 {
+		candidates = s.providers[:1]
+	}
+
+	var err error
+	for i, provider := range candidates {
+		if i > 0 {
+			s.logger.WithSource("ai_service").Warn("Failing over to another AI provider", map[string]interface{}{
+				"from":  candidates[i-1].name,
+				"to":    provider.name,
+				"error": err.Error(),
+			})
+		}
+
+		executor := provider.retryExecutor
+		if retry != nil {
+			executor = retry
+		}
+		err = executor.Execute(ctx, func(ctx context.Context) error {
+			return provider.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
+				// Apply rate limiting
+				if err := s.rateLimiter.Wait(ctx); err != nil {
+					return fmt.Errorf("rate limit exceeded: %w", err)
+				}
+				return call(ctx, provider)
+			})
+		})
+		if err == nil || ctx.Err() != nil || !provider.circuitBreaker.IsOpen() {
+			return err
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no AI provider configured")
+	}
+	return err
+}
+
+// TripCircuitBreakers opens the circuit breaker of the named provider, or of every provider when
+// name is empty, and returns the names of the providers it tripped. Requests then fail over or
+// fail as they would after real failures, until the breakers' open time has passed.
+func (s *AIService) TripCircuitBreakers(name string) ([]string, error) {
+	var tripped []string
+	for _, provider := range s.providers {
+		if name != "" && provider.name != name {
+			continue
+		}
+		provider.circuitBreaker.Trip()
+		tripped = append(tripped, provider.name)
+	}
+
+	if len(tripped) == 0 {
+		if name == "" {
+			return nil, fmt.Errorf("%w: no AI provider is configured", ErrAIProviderNotFound)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrAIProviderNotFound, name)
+	}
+	return tripped, nil
+}
+
+// status reports a provider's availability and circuit breaker state; the caller holds s.mu
+func (p *aiProvider) status() map[string]interface{} {
+	stats := p.circuitBreaker.GetStats()
+	status := map[string]interface{}{
+		"name":          p.name,
+		"base_url":      p.baseURL,
+		"available":     p.available,
+		"last_check":    p.lastCheck,
+		"circuit_state": stats["state"],
+		"failures":      stats["failures"],
+		"circuit":       p.circuitBreaker.Metrics(),
+	}
+	if p.lastError != nil {
+		status["last_error"] = p.lastError.Error()
+	}
+	return status
+}
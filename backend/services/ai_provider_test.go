@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// fakeAIProvider is an OpenAI-compatible server answering with content, or failing with a 500 (or
+// failStatus, when set) while failing is set
+type fakeAIProvider struct {
+	server     *httptest.Server
+	calls      atomic.Int32
+	failing    atomic.Bool
+	failStatus atomic.Int32
+}
+
+func newFakeAIProvider(t *testing.T, content string) *fakeAIProvider {
+	provider := &fakeAIProvider{}
+	provider.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider.calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if provider.failing.Load() {
+			status := int(provider.failStatus.Load())
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"message": "upstream failure", "type": "server_error"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": content}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		})
+	}))
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// newFailoverTestService returns a service whose primary and secondary providers are served by the given fakes
+func newFailoverTestService(primary, secondary *fakeAIProvider) *AIService {
+	cfg := &config.Config{
+		OpenAIAPIKey:         "primary-key",
+		AISecondaryAPIKey:    "secondary-key",
+		AICircuitMaxFailures: 1,
+		AICircuitOpenSeconds: 60,
+	}
+	service := NewAIService(cfg, nil, utils.NewLogger("debug", "json"))
+	for i, fake := range []*fakeAIProvider{primary, secondary} {
+		clientConfig := openai.DefaultConfig("test-key")
+		clientConfig.BaseURL = fake.server.URL + "/v1"
+		service.providers[i].client = openai.NewClientWithConfig(clientConfig)
+	}
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+	return service
+}
+
+func TestNewAIProviders(t *testing.T) {
+	assert.Empty(t, newAIProviders(&config.Config{}, nil))
+
+	providers := newAIProviders(&config.Config{
+		OpenAIAPIKey:       "primary-key",
+		AISecondaryAPIKey:  "secondary-key",
+		AISecondaryBaseURL: "https://llm.example.com/v1",
+	}, nil)
+	require.Len(t, providers, 2)
+	assert.Equal(t, AIProviderPrimary, providers[0].name)
+	assert.Equal(t, "https://api.openai.com/v1", providers[0].baseURL)
+	assert.Equal(t, AIProviderSecondary, providers[1].name)
+	assert.Equal(t, "https://llm.example.com/v1", providers[1].baseURL)
+	assert.NotSame(t, providers[0].circuitBreaker, providers[1].circuitBreaker)
+	assert.NotSame(t, providers[0].retryExecutor, providers[1].retryExecutor)
+
+	providers = newAIProviders(&config.Config{AISecondaryAPIKey: "secondary-key"}, nil)
+	require.Len(t, providers, 1)
+	assert.Equal(t, AIProviderSecondary, providers[0].name)
+}
+
+func TestAIService_GetStatus_NoAPIKey(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	status := service.GetStatus()
+	assert.Equal(t, false, status["available"])
+	assert.Equal(t, AIUnavailableNoAPIKey, status["unavailable_reason"])
+	assert.Empty(t, status["providers"])
+}
+
+func TestAIService_CheckAvailability(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+	assert.EqualError(t, service.CheckAvailability(context.Background()), "AI service is unavailable: "+AIUnavailableNoAPIKey)
+
+	primary, secondary := newFakeAIProvider(t, "ok"), newFakeAIProvider(t, "ok")
+	service = newFailoverTestService(primary, secondary)
+	assert.NoError(t, service.CheckAvailability(context.Background()))
+	assert.Zero(t, primary.calls.Load()+secondary.calls.Load(), "no provider is called")
+}
+
+func TestAIService_ProviderFailover(t *testing.T) {
+	request := func(service *AIService) *models.AIResponse {
+		response, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+			Code:        "let x = 1;",
+			Language:    "javascript",
+			RequestType: "suggestion",
+			Model:       "gpt-4o",
+			NoCache:     true,
+		})
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("healthy primary serves every request", func(t *testing.T) {
+		primary, secondary := newFakeAIProvider(t, "from primary"), newFakeAIProvider(t, "from secondary")
+		service := newFailoverTestService(primary, secondary)
+
+		assert.Equal(t, "from primary", request(service).Analysis)
+		assert.Equal(t, "from primary", request(service).Analysis)
+		assert.Equal(t, int32(2), primary.calls.Load())
+		assert.Zero(t, secondary.calls.Load())
+	})
+
+	t.Run("open primary circuit routes to the secondary", func(t *testing.T) {
+		primary, secondary := newFakeAIProvider(t, "from primary"), newFakeAIProvider(t, "from secondary")
+		primary.failing.Store(true)
+		service := newFailoverTestService(primary, secondary)
+
+		// The failure that opens the primary's circuit fails over within the same request
+		assert.Equal(t, "from secondary", request(service).Analysis)
+		assert.Equal(t, "from secondary", request(service).Analysis)
+		assert.Equal(t, int32(1), primary.calls.Load(), "an open circuit isn't called")
+		assert.Equal(t, int32(2), secondary.calls.Load())
+
+		assert.True(t, service.IsAvailable())
+		status := service.GetStatus()
+		providers := status["providers"].([]map[string]interface{})
+		require.Len(t, providers, 2)
+		assert.Equal(t, AIProviderPrimary, providers[0]["name"])
+		assert.Equal(t, "OPEN", providers[0]["circuit_state"])
+		assert.Equal(t, false, providers[0]["available"])
+		assert.Contains(t, providers[0]["last_error"], "upstream failure")
+		assert.Equal(t, AIProviderSecondary, providers[1]["name"])
+		assert.Equal(t, "CLOSED", providers[1]["circuit_state"])
+		assert.Equal(t, 0, providers[1]["failures"], "the primary's failures don't count against the secondary")
+
+		primaryCircuit := providers[0]["circuit"].(utils.CircuitBreakerMetrics)
+		assert.Equal(t, int64(1), primaryCircuit.TotalFailures)
+		require.NotNil(t, primaryCircuit.NextRetryAt)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *primaryCircuit.NextRetryAt, 5*time.Second)
+		secondaryCircuit := providers[1]["circuit"].(utils.CircuitBreakerMetrics)
+		assert.Equal(t, int64(2), secondaryCircuit.TotalSuccesses)
+		assert.Nil(t, secondaryCircuit.NextRetryAt)
+		assert.NotContains(t, status, "unavailable_reason")
+	})
+
+	t.Run("every circuit open", func(t *testing.T) {
+		primary, secondary := newFakeAIProvider(t, "from primary"), newFakeAIProvider(t, "from secondary")
+		primary.failing.Store(true)
+		secondary.failing.Store(true)
+		service := newFailoverTestService(primary, secondary)
+
+		assert.Less(t, request(service).Confidence, 0.8, "a fallback response is returned")
+		assert.Equal(t, int32(1), primary.calls.Load())
+		assert.Equal(t, int32(1), secondary.calls.Load())
+
+		// Both circuits are open now, so neither provider is called
+		assert.Less(t, request(service).Confidence, 0.8)
+		assert.Equal(t, int32(1), primary.calls.Load())
+		assert.Equal(t, int32(1), secondary.calls.Load())
+
+		status := service.GetStatus()
+		assert.Equal(t, false, status["available"])
+		assert.Equal(t, AIUnavailableCircuitOpen, status["unavailable_reason"])
+	})
+
+	t.Run("request errors that leave the circuit closed don't fail over", func(t *testing.T) {
+		primary, secondary := newFakeAIProvider(t, "from primary"), newFakeAIProvider(t, "from secondary")
+		primary.failing.Store(true)
+		service := newFailoverTestService(primary, secondary)
+		service.providers[0].circuitBreaker = utils.NewCircuitBreaker(&utils.CircuitBreakerConfig{
+			MaxFailures: 5, Timeout: time.Minute, MaxRequests: 1, SuccessThreshold: 1, Name: "primary",
+		}, nil)
+
+		assert.Less(t, request(service).Confidence, 0.8)
+		assert.Zero(t, secondary.calls.Load())
+
+		service.providers[1].available = false
+		assert.Equal(t, AIUnavailableProviderDown, service.GetStatus()["unavailable_reason"],
+			"providers that failed without opening their circuit")
+	})
+}
+
+func TestAIService_HealthCheck_Providers(t *testing.T) {
+	primary, secondary := newFakeAIProvider(t, "ok"), newFakeAIProvider(t, "ok")
+	primary.failing.Store(true)
+	service := newFailoverTestService(primary, secondary)
+
+	assert.NoError(t, service.HealthCheck(context.Background()), "a healthy secondary is enough")
+	assert.Equal(t, int32(1), secondary.calls.Load())
+
+	secondary.failing.Store(true)
+	service = newFailoverTestService(primary, secondary)
+	err := service.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), AIProviderPrimary+":")
+	assert.Contains(t, err.Error(), AIProviderSecondary+":")
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrAIQuotaExceeded is returned when a caller has used up its daily AI request or token quota
+var ErrAIQuotaExceeded = errors.New("AI quota exceeded")
+
+// Once the tracker holds maxAIQuotaKeys keys, keys unused for aiQuotaIdleTimeout are dropped before
+// another is added, so callers identified by client IP can't grow it without bound
+const (
+	maxAIQuotaKeys     = 10000
+	aiQuotaIdleTimeout = time.Hour
+)
+
+// aiQuotaKeyContext is the context key AI requests are attributed to a quota under
+type aiQuotaKeyContext struct{}
+
+// ContextWithAIQuotaKey attributes the AI requests made with ctx to the given quota key.
+// Requests without a key are not counted against any quota.
+func ContextWithAIQuotaKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, aiQuotaKeyContext{}, key)
+}
+
+// aiQuotaKeyFromContext returns the quota key set by ContextWithAIQuotaKey, or ""
+func aiQuotaKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(aiQuotaKeyContext{}).(string)
+	return key
+}
+
+// aiQuotaTracker counts OpenAI requests and tokens per quota key over the current UTC day.
+// All counters reset together at midnight UTC.
+type aiQuotaTracker struct {
+	mu            sync.Mutex
+	dailyRequests int // 0 means unlimited
+	dailyTokens   int // 0 means unlimited
+	day           time.Time
+	usage         map[string]*aiQuotaUsage
+	now           func() time.Time
+}
+
+// aiQuotaUsage is one key's usage during the current day
+type aiQuotaUsage struct {
+	requests int
+	tokens   int
+	lastUsed time.Time
+}
+
+// newAIQuotaTracker creates a tracker; usage is tracked even when both limits are 0
+func newAIQuotaTracker(dailyRequests, dailyTokens int) *aiQuotaTracker {
+	return &aiQuotaTracker{
+		dailyRequests: dailyRequests,
+		dailyTokens:   dailyTokens,
+		usage:         make(map[string]*aiQuotaUsage),
+		now:           time.Now,
+	}
+}
+
+// reserve counts a request against key's quota, failing once either daily limit is used up.
+// The token limit is checked before the request, so the request that crosses it still completes.
+func (q *aiQuotaTracker) reserve(key string) error {
+	if key == "" {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage := q.usageLocked(key)
+	if q.dailyRequests > 0 && usage.requests >= q.dailyRequests {
+		return fmt.Errorf("%w: daily limit of %d requests reached", ErrAIQuotaExceeded, q.dailyRequests)
+	}
+	if q.dailyTokens > 0 && usage.tokens >= q.dailyTokens {
+		return fmt.Errorf("%w: daily limit of %d tokens reached", ErrAIQuotaExceeded, q.dailyTokens)
+	}
+
+	usage.requests++
+	return nil
+}
+
+// release gives back a request reserved by a call that ended in a fallback response, so only
+// answers from OpenAI count. A key left without usage is dropped.
+func (q *aiQuotaTracker) release(key string) {
+	if key == "" {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollLocked()
+	usage, exists := q.usage[key]
+	if !exists || usage.requests == 0 {
+		return
+	}
+	usage.requests--
+	if usage.requests == 0 && usage.tokens == 0 {
+		delete(q.usage, key)
+	}
+}
+
+// recordTokens adds the tokens an OpenAI call spent to key's usage
+func (q *aiQuotaTracker) recordTokens(key string, tokens int) {
+	if key == "" {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.usageLocked(key).tokens += tokens
+}
+
+// report returns key's usage and limits for the current day
+func (q *aiQuotaTracker) report(key string) models.AIQuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollLocked()
+	var usage aiQuotaUsage
+	if counted, exists := q.usage[key]; exists {
+		usage = *counted
+	}
+
+	return models.AIQuotaUsage{
+		RequestsUsed: usage.requests,
+		RequestLimit: q.dailyRequests,
+		TokensUsed:   usage.tokens,
+		TokenLimit:   q.dailyTokens,
+		ResetsAt:     q.day.AddDate(0, 0, 1),
+	}
+}
+
+// rollLocked starts a new day, dropping all usage, once midnight UTC has passed
+func (q *aiQuotaTracker) rollLocked() {
+	now := q.now().UTC()
+	if today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); !today.Equal(q.day) {
+		q.day = today
+		q.usage = make(map[string]*aiQuotaUsage)
+	}
+}
+
+// usageLocked returns key's usage for the current day, marking it used now
+func (q *aiQuotaTracker) usageLocked(key string) *aiQuotaUsage {
+	q.rollLocked()
+	now := q.now()
+
+	usage, exists := q.usage[key]
+	if !exists {
+		if len(q.usage) >= maxAIQuotaKeys {
+			q.evictIdleLocked(now)
+		}
+		usage = &aiQuotaUsage{}
+		q.usage[key] = usage
+	}
+	usage.lastUsed = now
+	return usage
+}
+
+// evictIdleLocked drops the keys unused for aiQuotaIdleTimeout. Their usage is forgotten, so
+// they start the day afresh if they return.
+func (q *aiQuotaTracker) evictIdleLocked(now time.Time) {
+	for key, usage := range q.usage {
+		if now.Sub(usage.lastUsed) >= aiQuotaIdleTimeout {
+			delete(q.usage, key)
+		}
+	}
+}
+
+// QuotaUsage returns the daily AI quota usage of a quota key
+func (s *AIService) QuotaUsage(key string) models.AIQuotaUsage {
+	return s.quota.report(key)
+}
+
+// IsQuotaAPIKey reports whether apiKey is one of AI_QUOTA_API_KEYS, which callers may be
+// identified by for AI quotas
+func (s *AIService) IsQuotaAPIKey(apiKey string) bool {
+	if s.config == nil || apiKey == "" {
+		return false
+	}
+	for _, known := range s.config.AIQuotaAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(apiKey)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestAIQuotaTracker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	quota := newAIQuotaTracker(2, 500)
+	quota.now = func() time.Time { return now }
+
+	require.NoError(t, quota.reserve("key:team-a"))
+	quota.recordTokens("key:team-a", 200)
+	require.NoError(t, quota.reserve("key:team-a"))
+
+	err := quota.reserve("key:team-a")
+	assert.ErrorIs(t, err, ErrAIQuotaExceeded)
+	assert.ErrorContains(t, err, "daily limit of 2 requests reached")
+	assert.NoError(t, quota.reserve("key:team-b"), "keys have separate quotas")
+
+	assert.Equal(t, models.AIQuotaUsage{
+		RequestsUsed: 2,
+		RequestLimit: 2,
+		TokensUsed:   200,
+		TokenLimit:   500,
+		ResetsAt:     time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}, quota.report("key:team-a"))
+
+	t.Run("token limit", func(t *testing.T) {
+		quota.recordTokens("key:team-b", 600)
+		err := quota.reserve("key:team-b")
+		assert.ErrorIs(t, err, ErrAIQuotaExceeded)
+		assert.ErrorContains(t, err, "daily limit of 500 tokens reached")
+	})
+
+	t.Run("requests without a key are not limited", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, quota.reserve(""))
+		}
+		assert.Zero(t, quota.report("").RequestsUsed)
+	})
+
+	t.Run("usage resets at midnight UTC", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		assert.Zero(t, quota.report("key:team-a").RequestsUsed)
+		assert.NoError(t, quota.reserve("key:team-a"))
+		assert.Equal(t, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), quota.report("key:team-a").ResetsAt)
+	})
+
+	t.Run("released requests are not counted", func(t *testing.T) {
+		require.NoError(t, quota.reserve("ip:10.0.0.9"))
+		quota.release("ip:10.0.0.9")
+		assert.Zero(t, quota.report("ip:10.0.0.9").RequestsUsed)
+		_, tracked := quota.usage["ip:10.0.0.9"]
+		assert.False(t, tracked, "keys without usage are dropped")
+	})
+
+	t.Run("idle keys are evicted once the tracker is full", func(t *testing.T) {
+		full := newAIQuotaTracker(0, 0)
+		start := now
+		full.now = func() time.Time { return start }
+		for i := 0; i < maxAIQuotaKeys; i++ {
+			require.NoError(t, full.reserve(fmt.Sprintf("ip:%d", i)))
+		}
+
+		start = start.Add(aiQuotaIdleTimeout / 2)
+		require.NoError(t, full.reserve("ip:0"))
+		start = start.Add(aiQuotaIdleTimeout / 2)
+		require.NoError(t, full.reserve("ip:new"))
+
+		assert.Len(t, full.usage, 2, "keys idle for the timeout are dropped")
+		assert.Equal(t, 2, full.report("ip:0").RequestsUsed)
+	})
+
+	t.Run("no limits still tracks usage", func(t *testing.T) {
+		unlimited := newAIQuotaTracker(0, 0)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, unlimited.reserve("ip:10.0.0.1"))
+		}
+		assert.Equal(t, 5, unlimited.report("ip:10.0.0.1").RequestsUsed)
+	})
+}
+
+func TestAIService_Quota(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": `[{"type": "fix", "description": "Use const", "priority": "low"}]`}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150},
+		})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{OpenAIAPIKey: "test-key", AICacheTTLSeconds: 60, AICacheMaxEntries: 10, AIQuotaDailyRequests: 2}
+	service := NewAIService(cfg, nil, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	ctx := ContextWithAIQuotaKey(context.Background(), "key:team-a")
+	suggest := func(code string) error {
+		_, err := service.GetCodeSuggestions(ctx, &models.AIRequest{Code: code, Language: "javascript", RequestType: "suggestion"})
+		return err
+	}
+
+	require.NoError(t, suggest("var x = 1;"))
+	require.NoError(t, suggest("var x = 1;"), "cache hits don't count")
+	_, err := service.AnalyzeLogs(ctx, &models.AILogAnalysisRequest{Logs: []models.LogEntry{{Level: "error", Message: "boom", Source: "backend"}}, AnalysisType: "error_detection"})
+	require.NoError(t, err)
+
+	usage := service.QuotaUsage("key:team-a")
+	assert.Equal(t, 2, usage.RequestsUsed)
+	assert.Equal(t, 300, usage.TokensUsed)
+
+	err = suggest("var y = 2;")
+	assert.ErrorIs(t, err, ErrAIQuotaExceeded)
+	assert.Equal(t, int32(2), calls.Load(), "rejected requests never reach OpenAI")
+
+	_, err = service.GetCodeSuggestions(ContextWithAIQuotaKey(context.Background(), "key:team-b"), &models.AIRequest{Code: "var y = 2;", Language: "javascript", RequestType: "suggestion"})
+	assert.NoError(t, err, "other keys are unaffected")
+
+	t.Run("fallback responses are not counted", func(t *testing.T) {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		ctx := ContextWithAIQuotaKey(context.Background(), "key:team-c")
+		response, err := service.GetCodeSuggestions(ctx, &models.AIRequest{Code: "var z = 3;", Language: "javascript", RequestType: "suggestion"})
+		require.NoError(t, err)
+		assert.Contains(t, response.Analysis, "Failed to get suggestions")
+		assert.Zero(t, service.QuotaUsage("key:team-c").RequestsUsed)
+	})
+}
+
+func TestAIService_IsQuotaAPIKey(t *testing.T) {
+	service := NewAIService(&config.Config{AIQuotaAPIKeys: []string{"team-a-secret-key"}}, nil, utils.NewLogger("debug", "json"))
+	assert.True(t, service.IsQuotaAPIKey("team-a-secret-key"))
+	assert.False(t, service.IsQuotaAPIKey("team-b-secret-key"))
+	assert.False(t, service.IsQuotaAPIKey(""))
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAIRequestNotInFlight is returned when cancelling a request that has already finished or never started
+var ErrAIRequestNotInFlight = errors.New("AI request not in flight")
+
+// ErrAIRequestInFlight is returned when a new request reuses the ID of one that is still running
+var ErrAIRequestInFlight = errors.New("AI request already in flight")
+
+// ErrAIRequestCancelled is returned to the original caller of a request cancelled through CancelRequest
+var ErrAIRequestCancelled = errors.New("AI request cancelled")
+
+// startRequest registers an in-flight request under its ID and returns a context that
+// CancelRequest can cancel. The returned done func must be called once the request finishes.
+func (s *AIService) startRequest(ctx context.Context, requestID string) (context.Context, func(), error) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	if _, exists := s.inflight[requestID]; exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrAIRequestInFlight, requestID)
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	s.inflight[requestID] = cancel
+
+	done := func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, requestID)
+		s.inflightMu.Unlock()
+		cancel(nil)
+	}
+
+	return runCtx, done, nil
+}
+
+// CancelRequest aborts an in-flight suggestion request. The upstream OpenAI call is
+// cancelled and the original caller receives ErrAIRequestCancelled.
+func (s *AIService) CancelRequest(ctx context.Context, requestID string) error {
+	s.inflightMu.Lock()
+	cancel, exists := s.inflight[requestID]
+	if exists {
+		delete(s.inflight, requestID)
+	}
+	s.inflightMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrAIRequestNotInFlight, requestID)
+	}
+
+	cancel(ErrAIRequestCancelled)
+
+	s.logger.WithSource("ai_service").Info("AI request cancelled", map[string]interface{}{
+		"request_id": requestID,
+	})
+	s.broadcastAIRequestCancelled(ctx, requestID)
+
+	return nil
+}
+
+// InFlightRequests returns how many suggestion requests are currently running
+func (s *AIService) InFlightRequests() int {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	return len(s.inflight)
+}
+
+// broadcastAIRequestCancelled notifies clients that a request was cancelled
+func (s *AIService) broadcastAIRequestCancelled(ctx context.Context, requestID string) {
+	if s.wsHub == nil {
+		return
+	}
+
+	notificationData := map[string]interface{}{
+		"request_id": requestID,
+		"timestamp":  time.Now(),
+		"status":     "cancelled",
+	}
+	s.addTraceID(ctx, notificationData)
+
+	s.wsHub.BroadcastToAll("ai_request_cancelled", notificationData)
+}
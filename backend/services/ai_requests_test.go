@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIService_CancelRequest(t *testing.T) {
+	// The fake OpenAI server holds every request until the client gives up or the test ends
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var notification map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "ai_request_cancelled", mock.Anything).Run(func(args mock.Arguments) {
+		notification = args.Get(1).(map[string]interface{})
+	}).Return()
+
+	service := NewAIService(&config.Config{OpenAIAPIKey: "test-key"}, mockHub, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+
+	req := &models.AIRequest{
+		Code:        "var x = 1;",
+		Language:    "javascript",
+		RequestType: "suggestion",
+		RequestID:   "req-123",
+	}
+
+	type result struct {
+		response *models.AIResponse
+		err      error
+	}
+	results := make(chan result, 1)
+	go func() {
+		response, err := service.GetCodeSuggestions(context.Background(), req)
+		results <- result{response, err}
+	}()
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached OpenAI")
+	}
+	assert.Equal(t, 1, service.InFlightRequests())
+
+	// A second request can't reuse an in-flight ID
+	_, err := service.GetCodeSuggestions(context.Background(), req)
+	assert.ErrorIs(t, err, ErrAIRequestInFlight)
+
+	require.NoError(t, service.CancelRequest(context.Background(), "req-123"))
+
+	select {
+	case res := <-results:
+		assert.ErrorIs(t, res.err, ErrAIRequestCancelled)
+		assert.Nil(t, res.response)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled request did not return")
+	}
+
+	assert.Equal(t, 0, service.InFlightRequests())
+	assert.True(t, service.IsAvailable(), "cancelling must not mark the AI service unavailable")
+	require.NotNil(t, notification)
+	assert.Equal(t, "req-123", notification["request_id"])
+	assert.Equal(t, "cancelled", notification["status"])
+
+	// The request is gone once cancelled
+	assert.ErrorIs(t, service.CancelRequest(context.Background(), "req-123"), ErrAIRequestNotInFlight)
+}
+
+func TestAIService_CancelRequest_Unknown(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	err := service.CancelRequest(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrAIRequestNotInFlight)
+	assert.Equal(t, 0, service.GetStatus()["in_flight_requests"])
+}
+
+func TestAIService_FallbackKeepsRequestID(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	response, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+		Code:        "var x = 1;",
+		Language:    "javascript",
+		RequestType: "suggestion",
+		RequestID:   "req-456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "req-456", response.RequestID)
+}
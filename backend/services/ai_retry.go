@@ -0,0 +1,66 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// Limits on the retry hints a request may give, matching the validate tags of models.AIRetryHints
+const (
+	maxAIRetryAttempts     = 5
+	maxAIRetryInitialDelay = 10 * time.Second
+)
+
+// ErrInvalidAIRetryHints is returned when a request's retry hints are outside the allowed limits
+var ErrInvalidAIRetryHints = errors.New("invalid AI retry hints")
+
+// defaultAIRetryConfig is the retry policy of every AI provider: 3 attempts with exponential
+// backoff, retrying only rate limits, timeouts and temporary failures
+func defaultAIRetryConfig() *utils.RetryConfig {
+	return &utils.RetryConfig{
+		MaxAttempts:       3,
+		InitialDelay:      500 * time.Millisecond,
+		MaxDelay:          10 * time.Second,
+		BackoffMultiplier: 2.0,
+		Jitter:            true,
+		RetryCondition: func(err error) bool {
+			// Retry on rate limit and temporary errors
+			errStr := strings.ToLower(err.Error())
+			return strings.Contains(errStr, "rate limit") ||
+				strings.Contains(errStr, "timeout") ||
+				strings.Contains(errStr, "temporary") ||
+				strings.Contains(errStr, "service unavailable")
+		},
+	}
+}
+
+// retryExecutorFor returns the retry executor for a request's hints, or nil to keep the providers'
+// default policy. Hints only change how many attempts are made and how far apart; the retry
+// condition is unchanged, and retries never wait past the request context's deadline.
+func (s *AIService) retryExecutorFor(hints *models.AIRetryHints) (*utils.RetryExecutor, error) {
+	if hints == nil || (hints.MaxAttempts == 0 && hints.InitialDelayMS == 0) {
+		return nil, nil
+	}
+	if hints.MaxAttempts < 0 || hints.MaxAttempts > maxAIRetryAttempts {
+		return nil, fmt.Errorf("%w: max_attempts must be between 1 and %d", ErrInvalidAIRetryHints, maxAIRetryAttempts)
+	}
+	initialDelay := time.Duration(hints.InitialDelayMS) * time.Millisecond
+	if initialDelay < 0 || initialDelay > maxAIRetryInitialDelay {
+		return nil, fmt.Errorf("%w: initial_delay_ms must be between 0 and %d",
+			ErrInvalidAIRetryHints, maxAIRetryInitialDelay.Milliseconds())
+	}
+
+	config := defaultAIRetryConfig()
+	if hints.MaxAttempts > 0 {
+		config.MaxAttempts = hints.MaxAttempts
+	}
+	if initialDelay > 0 {
+		config.InitialDelay = initialDelay
+	}
+	return utils.NewRetryExecutor(config, s.logger), nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// newRetryTestService returns a service with a single provider served by fake, whose circuit stays
+// closed through a handful of failures so only retries are exercised
+func newRetryTestService(fake *fakeAIProvider) *AIService {
+	service := NewAIService(&config.Config{
+		OpenAIAPIKey:         "test-key",
+		AICircuitMaxFailures: 100,
+		AICircuitOpenSeconds: 60,
+	}, nil, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = fake.server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+	return service
+}
+
+func TestAIService_RetryExecutorFor(t *testing.T) {
+	service := NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json"))
+
+	for _, hints := range []*models.AIRetryHints{nil, {}} {
+		executor, err := service.retryExecutorFor(hints)
+		require.NoError(t, err)
+		assert.Nil(t, executor, "no hints keep the default policy")
+	}
+
+	executor, err := service.retryExecutorFor(&models.AIRetryHints{MaxAttempts: 1})
+	require.NoError(t, err)
+	assert.NotNil(t, executor)
+
+	for _, hints := range []*models.AIRetryHints{
+		{MaxAttempts: -1},
+		{MaxAttempts: maxAIRetryAttempts + 1},
+		{InitialDelayMS: -1},
+		{InitialDelayMS: int(maxAIRetryInitialDelay.Milliseconds()) + 1},
+	} {
+		_, err := service.retryExecutorFor(hints)
+		assert.ErrorIs(t, err, ErrInvalidAIRetryHints, "%+v", *hints)
+	}
+}
+
+func TestAIService_RetryHints(t *testing.T) {
+	suggest := func(service *AIService, ctx context.Context, hints *models.AIRetryHints) (*models.AIResponse, error) {
+		return service.GetCodeSuggestions(ctx, &models.AIRequest{
+			Code:        "let x = 1;",
+			Language:    "javascript",
+			RequestType: "suggestion",
+			Model:       "gpt-4o",
+			NoCache:     true,
+			Retry:       hints,
+		})
+	}
+	unavailable := func(t *testing.T) *fakeAIProvider {
+		fake := newFakeAIProvider(t, "ok")
+		fake.failing.Store(true)
+		fake.failStatus.Store(http.StatusServiceUnavailable)
+		return fake
+	}
+
+	t.Run("max attempts of 1 fails fast", func(t *testing.T) {
+		fake := unavailable(t)
+		response, err := suggest(newRetryTestService(fake), context.Background(), &models.AIRetryHints{MaxAttempts: 1})
+		require.NoError(t, err)
+		assert.Less(t, response.Confidence, 0.8, "a fallback response is returned")
+		assert.Equal(t, int32(1), fake.calls.Load())
+	})
+
+	t.Run("initial delay alone keeps the default attempts", func(t *testing.T) {
+		fake := unavailable(t)
+		_, err := suggest(newRetryTestService(fake), context.Background(), &models.AIRetryHints{InitialDelayMS: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), fake.calls.Load())
+	})
+
+	t.Run("log analysis can retry more", func(t *testing.T) {
+		fake := unavailable(t)
+		_, err := newRetryTestService(fake).AnalyzeLogs(context.Background(), &models.AILogAnalysisRequest{
+			Logs:         []models.LogEntry{{Level: "error", Message: "boom", Source: "backend"}},
+			AnalysisType: "error_detection",
+			Retry:        &models.AIRetryHints{MaxAttempts: 5, InitialDelayMS: 1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), fake.calls.Load())
+	})
+
+	t.Run("errors the retry condition rejects aren't retried", func(t *testing.T) {
+		fake := newFakeAIProvider(t, "ok")
+		fake.failing.Store(true)
+		_, err := suggest(newRetryTestService(fake), context.Background(), &models.AIRetryHints{MaxAttempts: 5, InitialDelayMS: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), fake.calls.Load())
+	})
+
+	t.Run("retries stop at the context deadline", func(t *testing.T) {
+		fake := unavailable(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := suggest(newRetryTestService(fake), ctx, &models.AIRetryHints{MaxAttempts: 5, InitialDelayMS: 10000})
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 2*time.Second)
+		assert.Equal(t, int32(1), fake.calls.Load())
+	})
+
+	t.Run("invalid hints are rejected", func(t *testing.T) {
+		fake := newFakeAIProvider(t, "ok")
+		_, err := suggest(newRetryTestService(fake), context.Background(), &models.AIRetryHints{MaxAttempts: 10})
+		assert.ErrorIs(t, err, ErrInvalidAIRetryHints)
+		assert.Zero(t, fake.calls.Load())
+	})
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,240 +22,368 @@ import (
 
 // AIService handles OpenAI integration with rate limiting and error handling
 type AIService struct {
-	client         *openai.Client
-	config         *config.Config
-	rateLimiter    *rate.Limiter
-	mu             sync.RWMutex
-	isAvailable    bool
-	lastError      error
-	lastCheck      time.Time
-	wsHub          WebSocketBroadcaster
-	circuitBreaker *utils.CircuitBreaker
-	retryExecutor  *utils.RetryExecutor
-	logger         *utils.Logger
+	config      *config.Config
+	rateLimiter *rate.Limiter
+	wsHub       WebSocketBroadcaster
+	logger      *utils.Logger
+
+	// OpenAI-compatible providers, primary first; see ai_provider.go. mu guards their availability
+	// and the most recent result of any provider.
+	mu        sync.RWMutex
+	providers []*aiProvider
+	lastError error
+	lastCheck time.Time
+
+	// Token usage accounting for cost attribution
+	usageMu     sync.Mutex
+	usageTotal  models.AIUsage
+	usageByTag  map[string]map[string]*models.AIUsage // metadata key -> metadata value -> usage
+	usageByType map[string]*models.AIUsage            // request type -> usage
+	usageSince  time.Time
+
+	// modelSelector picks the model for requests that don't name one
+	modelSelector *ModelSelector
+
+	// Suggestion feedback; both maps are bounded, see ai_feedback.go
+	feedbackMu      sync.Mutex
+	servedResponses map[string]servedSuggestions
+	servedOrder     []string
+	feedback        map[feedbackKey]*models.AIFeedback
+	feedbackOrder   []feedbackKey
+
+	// In-flight suggestion requests by ID, see ai_requests.go
+	inflightMu sync.Mutex
+	inflight   map[string]context.CancelCauseFunc
+
+	// Recent code suggestion responses; nil when caching is disabled, see ai_cache.go
+	responseCache *aiResponseCache
+
+	// Syntax checks run on patched code by language, see ai_patch.go
+	patchValidators map[string]PatchValidator
+
+	// Daily request and token usage per API key, see ai_quota.go
+	quota *aiQuotaTracker
+
+	// Bounded queue of automatically triggered log analyses, see ai_auto_analysis.go
+	autoAnalysis *autoAnalysisQueue
 }
 
-// NewAIService creates a new AI service instance
-func NewAIService(cfg *config.Config, wsHub WebSocketBroadcaster, logger *utils.Logger) *AIService {
-	var client *openai.Client
-	isAvailable := false
-
-	if cfg.OpenAIAPIKey != "" {
-		// Use connection pooling for OpenAI API calls
-		connectionPool := utils.OpenAIConnectionPool()
-		httpClient := connectionPool.GetClient()
-
-		clientConfig := openai.DefaultConfig(cfg.OpenAIAPIKey)
-		clientConfig.HTTPClient = httpClient
-
-		client = openai.NewClientWithConfig(clientConfig)
-		isAvailable = true
-	}
+// AIServiceConfig holds optional settings for the AI service
+type AIServiceConfig struct {
+	ModelSource     rand.Source               // Random source for weighted model selection; nil seeds from the clock
+	PatchValidators map[string]PatchValidator // Syntax checks by language; nil builds them from AI_PATCH_VALIDATORS
+}
 
+// NewAIService creates a new AI service instance
+func NewAIService(cfg *config.Config, wsHub WebSocketBroadcaster, logger *utils.Logger, serviceConfig ...AIServiceConfig) *AIService {
 	// Rate limiter: 60 requests per minute (1 per second with burst of 10)
 	limiter := rate.NewLimiter(rate.Every(time.Second), 10)
 
-	// Circuit breaker configuration for OpenAI API
-	cbConfig := &utils.CircuitBreakerConfig{
-		MaxFailures:      3,
-		Timeout:          60 * time.Second,
-		MaxRequests:      2,
-		SuccessThreshold: 2,
-		Name:             "openai_api",
-	}
-
-	// Retry configuration for OpenAI API
-	retryConfig := &utils.RetryConfig{
-		MaxAttempts:       3,
-		InitialDelay:      500 * time.Millisecond,
-		MaxDelay:          10 * time.Second,
-		BackoffMultiplier: 2.0,
-		Jitter:            true,
-		RetryCondition: func(err error) bool {
-			// Retry on rate limit and temporary errors
-			errStr := strings.ToLower(err.Error())
-			return strings.Contains(errStr, "rate limit") ||
-				strings.Contains(errStr, "timeout") ||
-				strings.Contains(errStr, "temporary") ||
-				strings.Contains(errStr, "service unavailable")
-		},
-	}
-
 	if logger == nil {
 		logger = utils.GetLogger()
 	}
 
-	return &AIService{
-		client:         client,
-		config:         cfg,
-		rateLimiter:    limiter,
-		isAvailable:    isAvailable,
-		lastCheck:      time.Now(),
-		wsHub:          wsHub,
-		circuitBreaker: utils.NewCircuitBreaker(cbConfig, logger),
-		retryExecutor:  utils.NewRetryExecutor(retryConfig, logger),
-		logger:         logger,
+	// Weighted model distribution for A/B testing; an invalid setting falls back to the default model
+	modelWeights, err := models.ParseAIModelWeights(cfg.AIModelWeights)
+	if err != nil {
+		logger.Warn("Ignoring invalid AI_MODEL_WEIGHTS", map[string]interface{}{
+			"error": err.Error(),
+		})
+		modelWeights = nil
+	}
+	var modelSource rand.Source
+	var patchValidators map[string]PatchValidator
+	if len(serviceConfig) > 0 {
+		modelSource = serviceConfig[0].ModelSource
+		patchValidators = serviceConfig[0].PatchValidators
 	}
+	if patchValidators == nil {
+		// An invalid setting disables syntax checks; patches are still applied
+		commands, err := models.ParseAIPatchValidators(cfg.AIPatchValidators)
+		if err != nil {
+			logger.Warn("Ignoring invalid AI_PATCH_VALIDATORS", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		patchValidators = newPatchValidators(commands)
+	}
+
+	service := &AIService{
+		config:        cfg,
+		rateLimiter:   limiter,
+		wsHub:         wsHub,
+		logger:        logger,
+		providers:     newAIProviders(cfg, logger),
+		lastCheck:     time.Now(),
+		usageByTag:    make(map[string]map[string]*models.AIUsage),
+		usageByType:   make(map[string]*models.AIUsage),
+		usageSince:    time.Now(),
+		modelSelector: NewModelSelector(modelWeights, modelSource),
+
+		servedResponses: make(map[string]servedSuggestions),
+		feedback:        make(map[feedbackKey]*models.AIFeedback),
+		inflight:        make(map[string]context.CancelCauseFunc),
+
+		responseCache:   newAIResponseCache(time.Duration(cfg.AICacheTTLSeconds)*time.Second, cfg.AICacheMaxEntries),
+		patchValidators: patchValidators,
+		quota:           newAIQuotaTracker(cfg.AIQuotaDailyRequests, cfg.AIQuotaDailyTokens),
+	}
+	service.autoAnalysis = newAutoAnalysisQueue(cfg.AIAutoAnalysisConcurrency, cfg.AIAutoAnalysisQueueSize,
+		time.Duration(cfg.AIAutoAnalysisWindowSeconds)*time.Second, service.AnalyzeLogs)
+	return service
 }
 
-// IsAvailable checks if the AI service is available
+// IsAvailable checks if the AI service is available, i.e. any provider is
 func (s *AIService) IsAvailable() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.isAvailable && s.client != nil
+
+	for _, provider := range s.providers {
+		if provider.available && provider.client != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAvailability fails while no provider is available, giving the reason GetStatus would. Unlike
+// HealthCheck it makes no provider calls, so the server health endpoint can poll it.
+func (s *AIService) CheckAvailability(ctx context.Context) error {
+	if s.IsAvailable() {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fmt.Errorf("AI service is unavailable: %s", s.unavailableReason())
 }
 
 // GetCodeSuggestions generates code suggestions using OpenAI
 func (s *AIService) GetCodeSuggestions(ctx context.Context, req *models.AIRequest) (*models.AIResponse, error) {
+	// Sample the model once so retries stay on the same arm of the comparison
+	options, err := s.resolveCompletionOptions(req.Model, req.Temperature, req.MaxTokens, completionOptions{
+		temperature: 0.3,
+		maxTokens:   1000,
+	})
+	if err != nil {
+		return nil, err
+	}
+	model := options.model
+	retry, err := s.retryExecutorFor(req.Retry)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := req.RequestID
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	// Identical requests get the same answer, so serve them without calling OpenAI
+	cacheKey := codeSuggestionCacheKey(req, options)
+	if !req.NoCache {
+		if cached, hit := s.responseCache.get(cacheKey); hit {
+			cached.RequestID = requestID
+			cached.Cached = true
+			s.broadcastAISuggestionReady(ctx, requestID, req.RequestType, len(cached.Suggestions))
+			s.trackSuggestionResponse(cached)
+			return cached, nil
+		}
+	}
+
 	if !s.IsAvailable() {
-		return s.getFallbackResponse(req, "AI service is currently unavailable")
+		return s.getFallbackResponse(ctx, req, "AI service is currently unavailable")
+	}
+
+	// Track the request so it can be cancelled by ID while it runs
+	ctx, done, err := s.startRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
 	}
+	defer done()
 
-	requestID := uuid.New().String()
+	// Cache hits and fallbacks above cost nothing, so only requests that reach OpenAI count
+	quotaKey := aiQuotaKeyFromContext(ctx)
+	if err := s.quota.reserve(quotaKey); err != nil {
+		return nil, err
+	}
 
-	// Execute with circuit breaker and retry logic
+	// Execute with circuit breaker and retry logic, failing over between providers
 	var response *models.AIResponse
-	err := s.retryExecutor.Execute(ctx, func(ctx context.Context) error {
-		return s.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
-			// Apply rate limiting
-			if err := s.rateLimiter.Wait(ctx); err != nil {
-				return fmt.Errorf("rate limit exceeded: %w", err)
-			}
+	err = s.executeWithFailover(ctx, retry, func(ctx context.Context, provider *aiProvider) error {
+		// Build the prompt based on request type
+		prompt := s.buildCodePrompt(req)
+		systemPrompt := codeSuggestionSystemPrompt
+		if req.Format == models.AIFormatPatch {
+			systemPrompt += patchFormatPrompt
+		}
 
-			// Build the prompt based on request type
-			prompt := s.buildCodePrompt(req)
-
-			// Make OpenAI API call
-			resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-				Model: openai.GPT3Dot5Turbo,
-				Messages: []openai.ChatCompletionMessage{
-					{
-						Role:    openai.ChatMessageRoleSystem,
-						Content: "You are an expert code assistant. Provide helpful, accurate code suggestions and improvements.",
-					},
-					{
-						Role:    openai.ChatMessageRoleUser,
-						Content: prompt,
-					},
+		// Make OpenAI API call
+		resp, err := provider.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: systemPrompt,
 				},
-				MaxTokens:   1000,
-				Temperature: 0.3,
-				TopP:        1.0,
-			})
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			MaxTokens:   options.maxTokens,
+			Temperature: options.temperature,
+			TopP:        1.0,
+		})
 
-			if err != nil {
-				s.updateAvailability(false, err)
-				return fmt.Errorf("OpenAI API error: %w", err)
+		if err != nil {
+			// A cancelled request says nothing about whether OpenAI is reachable
+			if !errors.Is(context.Cause(ctx), ErrAIRequestCancelled) {
+				s.updateAvailability(provider, false, err)
 			}
+			return fmt.Errorf("OpenAI API error: %w", err)
+		}
 
-			s.updateAvailability(true, nil)
+		s.updateAvailability(provider, true, nil)
+		s.recordUsage(req.Metadata, req.RequestType, model, resp.Usage)
+		s.quota.recordTokens(quotaKey, resp.Usage.TotalTokens)
 
-			// Parse the response
-			if len(resp.Choices) == 0 {
-				return fmt.Errorf("no suggestions generated")
-			}
+		// Parse the response
+		if len(resp.Choices) == 0 {
+			return fmt.Errorf("no suggestions generated")
+		}
 
-			suggestions := s.parseCodeSuggestions(resp.Choices[0].Message.Content, req)
+		suggestions := s.parseCodeSuggestions(resp.Choices[0].Message.Content, req)
 
-			response = &models.AIResponse{
-				Suggestions: suggestions,
-				Analysis:    resp.Choices[0].Message.Content,
-				Confidence:  0.8, // Default confidence for OpenAI responses
-				RequestID:   requestID,
-				ProcessedAt: time.Now(),
-			}
+		response = &models.AIResponse{
+			Suggestions: suggestions,
+			Analysis:    resp.Choices[0].Message.Content,
+			Confidence:  0.8, // Default confidence for OpenAI responses
+			RequestID:   requestID,
+			Model:       model,
+			ProcessedAt: time.Now(),
+		}
 
-			return nil
-		})
+		return nil
 	})
 
+	if errors.Is(context.Cause(ctx), ErrAIRequestCancelled) {
+		return nil, fmt.Errorf("%w: %s", ErrAIRequestCancelled, requestID)
+	}
 	if err != nil {
 		s.logger.WithSource("ai_service").Error("Failed to get code suggestions", err, map[string]interface{}{
 			"request_id":   requestID,
 			"request_type": req.RequestType,
+			"model":        model,
 		})
-		return s.getFallbackResponse(req, fmt.Sprintf("Failed to get suggestions: %v", err))
+		s.quota.release(quotaKey)
+		return s.getFallbackResponse(ctx, req, fmt.Sprintf("Failed to get suggestions: %v", err))
 	}
 
+	// Catch diffs that don't apply or break the code before anyone sees them
+	if req.Format == models.AIFormatPatch {
+		s.applySuggestionPatches(ctx, req, response.Suggestions)
+	}
+
+	// Fallbacks above are never cached, so a later request retries OpenAI
+	s.responseCache.put(cacheKey, response)
+
 	// Broadcast AI suggestion ready notification
-	s.broadcastAISuggestionReady(requestID, req.RequestType, len(response.Suggestions))
+	s.broadcastAISuggestionReady(ctx, requestID, req.RequestType, len(response.Suggestions))
+	s.trackSuggestionResponse(response)
 
 	return response, nil
 }
 
 // AnalyzeLogs analyzes logs using OpenAI
 func (s *AIService) AnalyzeLogs(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error) {
+	options, err := s.resolveCompletionOptions(req.Model, req.Temperature, req.MaxTokens, completionOptions{
+		temperature: 0.2,
+		maxTokens:   1500,
+	})
+	if err != nil {
+		return nil, err
+	}
+	model := options.model
+	retry, err := s.retryExecutorFor(req.Retry)
+	if err != nil {
+		return nil, err
+	}
+
 	if !s.IsAvailable() {
 		return s.getFallbackLogAnalysis(req, "AI service is currently unavailable")
 	}
 
-	// Execute with circuit breaker and retry logic
+	quotaKey := aiQuotaKeyFromContext(ctx)
+	if err := s.quota.reserve(quotaKey); err != nil {
+		return nil, err
+	}
+
+	// Execute with circuit breaker and retry logic, failing over between providers
 	var response *models.AILogAnalysisResponse
-	err := s.retryExecutor.Execute(ctx, func(ctx context.Context) error {
-		return s.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
-			// Apply rate limiting
-			if err := s.rateLimiter.Wait(ctx); err != nil {
-				return fmt.Errorf("rate limit exceeded: %w", err)
-			}
+	err = s.executeWithFailover(ctx, retry, func(ctx context.Context, provider *aiProvider) error {
+		// Build the log analysis prompt
+		prompt := s.buildLogAnalysisPrompt(req)
 
-			// Build the log analysis prompt
-			prompt := s.buildLogAnalysisPrompt(req)
-
-			// Make OpenAI API call
-			resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-				Model: openai.GPT3Dot5Turbo,
-				Messages: []openai.ChatCompletionMessage{
-					{
-						Role:    openai.ChatMessageRoleSystem,
-						Content: "You are an expert log analyst. Analyze logs to identify issues, patterns, and provide actionable suggestions.",
-					},
-					{
-						Role:    openai.ChatMessageRoleUser,
-						Content: prompt,
-					},
+		// Make OpenAI API call
+		resp, err := provider.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: logAnalysisSystemPrompt,
 				},
-				MaxTokens:   1500,
-				Temperature: 0.2,
-				TopP:        1.0,
-			})
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			MaxTokens:   options.maxTokens,
+			Temperature: options.temperature,
+			TopP:        1.0,
+		})
 
-			if err != nil {
-				s.updateAvailability(false, err)
-				return fmt.Errorf("OpenAI API error: %w", err)
-			}
+		if err != nil {
+			s.updateAvailability(provider, false, err)
+			return fmt.Errorf("OpenAI API error: %w", err)
+		}
 
-			s.updateAvailability(true, nil)
+		s.updateAvailability(provider, true, nil)
+		s.recordUsage(nil, models.AIUsageLogAnalysis, model, resp.Usage)
+		s.quota.recordTokens(quotaKey, resp.Usage.TotalTokens)
 
-			// Parse the response
-			if len(resp.Choices) == 0 {
-				return fmt.Errorf("no analysis generated")
-			}
+		// Parse the response
+		if len(resp.Choices) == 0 {
+			return fmt.Errorf("no analysis generated")
+		}
 
-			analysis := s.parseLogAnalysis(resp.Choices[0].Message.Content, req)
+		analysis := s.parseLogAnalysis(resp.Choices[0].Message.Content, req)
 
-			response = &models.AILogAnalysisResponse{
-				Summary:     analysis.Summary,
-				Issues:      analysis.Issues,
-				Patterns:    analysis.Patterns,
-				Suggestions: analysis.Suggestions,
-				AnalyzedAt:  time.Now(),
-				Confidence:  0.8,
-			}
+		response = &models.AILogAnalysisResponse{
+			Summary:     analysis.Summary,
+			Issues:      analysis.Issues,
+			Patterns:    analysis.Patterns,
+			Suggestions: analysis.Suggestions,
+			AnalyzedAt:  time.Now(),
+			Confidence:  0.8,
+			Model:       model,
+		}
 
-			return nil
-		})
+		return nil
 	})
 
 	if err != nil {
 		s.logger.WithSource("ai_service").Error("Failed to analyze logs", err, map[string]interface{}{
 			"log_count":     len(req.Logs),
 			"analysis_type": req.AnalysisType,
+			"model":         model,
 		})
+		s.quota.release(quotaKey)
 		return s.getFallbackLogAnalysis(req, fmt.Sprintf("Failed to analyze logs: %v", err))
 	}
 
 	// Broadcast AI log analysis ready notification
-	s.broadcastAILogAnalysisReady(len(req.Logs), len(response.Issues), len(response.Patterns))
+	s.broadcastAILogAnalysisReady(ctx, len(req.Logs), len(response.Issues), len(response.Patterns))
 
 	return response, nil
 }
@@ -282,26 +414,24 @@ func (s *AIService) buildCodePrompt(req *models.AIRequest) string {
 		prompt.WriteString(fmt.Sprintf("Context: %s\n\n", req.Context))
 	}
 
-	prompt.WriteString("Please provide your response in a structured format with specific suggestions, explanations, and priority levels.")
+	prompt.WriteString("Respond with the JSON array of suggestions only.")
 
 	return prompt.String()
 }
 
-// buildLogAnalysisPrompt creates a prompt for log analysis
+// buildLogAnalysisPrompt creates a prompt for log analysis. Every log in req is included; callers
+// limit them, see sampleAILogs.
 func (s *AIService) buildLogAnalysisPrompt(req *models.AILogAnalysisRequest) string {
 	var prompt strings.Builder
 
 	prompt.WriteString(fmt.Sprintf("Please analyze the following logs for %s:\n\n", req.AnalysisType))
 
-	// Add up to 10 log entries to avoid token limits
-	maxLogs := 10
-	if len(req.Logs) < maxLogs {
-		maxLogs = len(req.Logs)
-	}
-
-	for i := 0; i < maxLogs; i++ {
-		log := req.Logs[i]
+	for i, log := range req.Logs {
 		prompt.WriteString(fmt.Sprintf("Log %d:\n", i+1))
+		prompt.WriteString(fmt.Sprintf("  ID: %s\n", log.ID))
+		if log.Fingerprint != "" {
+			prompt.WriteString(fmt.Sprintf("  Fingerprint: %s\n", log.Fingerprint))
+		}
 		prompt.WriteString(fmt.Sprintf("  Timestamp: %s\n", log.Timestamp.Format(time.RFC3339)))
 		prompt.WriteString(fmt.Sprintf("  Level: %s\n", log.Level))
 		prompt.WriteString(fmt.Sprintf("  Source: %s\n", log.Source))
@@ -317,13 +447,21 @@ func (s *AIService) buildLogAnalysisPrompt(req *models.AILogAnalysisRequest) str
 	prompt.WriteString("2. Specific issues with severity levels\n")
 	prompt.WriteString("3. Patterns identified in the logs\n")
 	prompt.WriteString("4. Actionable suggestions for resolution\n")
+	prompt.WriteString("Reference each issue's logs by their ID and fingerprint.\n")
 
 	return prompt.String()
 }
 
 // parseCodeSuggestions parses OpenAI response into structured suggestions
 func (s *AIService) parseCodeSuggestions(content string, req *models.AIRequest) []models.Suggestion {
-	// This is a simplified parser - in production, you might want more sophisticated parsing
+	if suggestions, ok := parseSuggestionJSON(content); ok {
+		return suggestions
+	}
+
+	// The model didn't answer with the requested JSON, so return its reply as a single suggestion
+	s.logger.WithSource("ai_service").Debug("AI response is not a JSON suggestion array; returning it whole", map[string]interface{}{
+		"request_type": req.RequestType,
+	})
 	suggestions := []models.Suggestion{
 		{
 			Type:        "improvement",
@@ -340,7 +478,14 @@ func (s *AIService) parseCodeSuggestions(content string, req *models.AIRequest)
 
 // parseLogAnalysis parses OpenAI response into structured log analysis
 func (s *AIService) parseLogAnalysis(content string, req *models.AILogAnalysisRequest) *models.AILogAnalysisResponse {
-	// This is a simplified parser - in production, you might want more sophisticated parsing
+	if analysis, ok := parseLogAnalysisJSON(content, req.Logs); ok {
+		return analysis
+	}
+
+	// The model didn't answer with the requested JSON, so return its reply as the summary
+	s.logger.WithSource("ai_service").Debug("AI response is not a JSON log analysis; returning it as the summary", map[string]interface{}{
+		"analysis_type": req.AnalysisType,
+	})
 	return &models.AILogAnalysisResponse{
 		Summary: content,
 		Issues: []models.LogIssue{
@@ -366,8 +511,11 @@ func (s *AIService) parseLogAnalysis(content string, req *models.AILogAnalysisRe
 }
 
 // getFallbackResponse returns a fallback response when AI service is unavailable
-func (s *AIService) getFallbackResponse(req *models.AIRequest, reason string) (*models.AIResponse, error) {
-	requestID := uuid.New().String()
+func (s *AIService) getFallbackResponse(ctx context.Context, req *models.AIRequest, reason string) (*models.AIResponse, error) {
+	requestID := req.RequestID
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
 
 	var fallbackSuggestion models.Suggestion
 	switch req.RequestType {
@@ -409,7 +557,8 @@ func (s *AIService) getFallbackResponse(req *models.AIRequest, reason string) (*
 	}
 
 	// Broadcast AI suggestion ready notification even for fallback responses
-	s.broadcastAISuggestionReady(requestID, req.RequestType, len(response.Suggestions))
+	s.broadcastAISuggestionReady(ctx, requestID, req.RequestType, len(response.Suggestions))
+	s.trackSuggestionResponse(response)
 
 	return response, nil
 }
@@ -436,52 +585,299 @@ func (s *AIService) getFallbackLogAnalysis(req *models.AILogAnalysisRequest, rea
 	}, nil
 }
 
-// updateAvailability updates the service availability status
-func (s *AIService) updateAvailability(available bool, err error) {
+// updateAvailability records the result of a request to provider
+func (s *AIService) updateAvailability(provider *aiProvider, available bool, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.isAvailable = available
+	provider.available = available
+	provider.lastError = err
+	provider.lastCheck = time.Now()
 	s.lastError = err
-	s.lastCheck = time.Now()
+	s.lastCheck = provider.lastCheck
 
 	if err != nil {
-		log.Printf("AI service availability updated: %v, error: %v", available, err)
+		log.Printf("AI provider %s availability updated: %v, error: %v", provider.name, available, err)
 	}
 }
 
 // GetStatus returns the current status of the AI service
 func (s *AIService) GetStatus() map[string]interface{} {
+	available := s.IsAvailable()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	providers := make([]map[string]interface{}, 0, len(s.providers))
+	for _, provider := range s.providers {
+		providers = append(providers, provider.status())
+	}
+
 	status := map[string]interface{}{
-		"available":  s.isAvailable,
-		"last_check": s.lastCheck,
+		"available":          available,
+		"last_check":         s.lastCheck,
+		"providers":          providers,
+		"in_flight_requests": s.InFlightRequests(),
+		"cached_responses":   s.responseCache.len(),
+		"token_usage":        s.UsageSummary(),
+		"auto_analysis":      s.autoAnalysis.stats(),
 	}
 
 	if s.lastError != nil {
 		status["last_error"] = s.lastError.Error()
 	}
+	if !available {
+		status["unavailable_reason"] = s.unavailableReason()
+	}
+	if allowed := s.AllowedModels(); len(allowed) > 0 {
+		status["allowed_models"] = allowed
+	}
 
 	return status
 }
 
-// HealthCheck performs a health check on the AI service
+// Reasons GetStatus gives for the AI service being unavailable
+const (
+	AIUnavailableNoAPIKey     = "no_api_key"     // No provider is configured
+	AIUnavailableCircuitOpen  = "circuit_open"   // Every provider's circuit breaker is open after repeated failures
+	AIUnavailableProviderDown = "provider_error" // Providers failed their last request or health check
+)
+
+// unavailableReason explains why no provider is available; the caller holds s.mu
+func (s *AIService) unavailableReason() string {
+	if len(s.providers) == 0 {
+		return AIUnavailableNoAPIKey
+	}
+	for _, provider := range s.providers {
+		if !provider.circuitBreaker.IsOpen() {
+			return AIUnavailableProviderDown
+		}
+	}
+	return AIUnavailableCircuitOpen
+}
+
+// AllowedModels returns the models callers may request explicitly; empty allows any
+func (s *AIService) AllowedModels() []string {
+	if s.config == nil {
+		return nil
+	}
+	return append([]string(nil), s.config.AIAllowedModels...)
+}
+
+// selectModel returns the explicitly requested model, or samples one from AI_MODEL_WEIGHTS
+func (s *AIService) selectModel(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.modelSelector.Pick()
+}
+
+// ErrAIModelNotAllowed is returned when a request names a model outside AI_ALLOWED_MODELS
+var ErrAIModelNotAllowed = errors.New("AI model not allowed")
+
+// ErrAIMaxTokensExceeded is returned when a request asks for more tokens than AI_MAX_TOKENS
+var ErrAIMaxTokensExceeded = errors.New("AI max tokens exceeded")
+
+// completionOptions are the OpenAI parameters resolved for one request
+type completionOptions struct {
+	model       string
+	temperature float32
+	maxTokens   int
+}
+
+// resolveCompletionOptions applies a request's model, temperature and token budget over the
+// defaults. An explicit model must be in AI_ALLOWED_MODELS and the budget within AI_MAX_TOKENS.
+func (s *AIService) resolveCompletionOptions(model string, temperature *float32, maxTokens int, defaults completionOptions) (completionOptions, error) {
+	if model != "" && s.config != nil && !matchesAny(s.config.AIAllowedModels, model) {
+		return completionOptions{}, fmt.Errorf("%w: %s", ErrAIModelNotAllowed, model)
+	}
+	if s.config != nil && s.config.AIMaxTokens > 0 && maxTokens > s.config.AIMaxTokens {
+		return completionOptions{}, fmt.Errorf("%w: requested %d, at most %d allowed",
+			ErrAIMaxTokensExceeded, maxTokens, s.config.AIMaxTokens)
+	}
+
+	options := defaults
+	options.model = s.selectModel(model)
+	if temperature != nil {
+		options.temperature = *temperature
+	}
+	if maxTokens > 0 {
+		options.maxTokens = maxTokens
+	}
+
+	// go-openai omits a zero temperature, which OpenAI would treat as its default of 1
+	if options.temperature == 0 {
+		options.temperature = math.SmallestNonzeroFloat32
+	}
+	return options, nil
+}
+
+// UsageTagKeys returns the keys usage can be grouped by: the configured request metadata
+// keys plus the model that served each request
+func (s *AIService) UsageTagKeys() []string {
+	var keys []string
+	if s.config != nil {
+		for _, key := range s.config.AIUsageTagKeys {
+			if key != models.AIUsageGroupByModel {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return append(keys, models.AIUsageGroupByModel)
+}
+
+// GetUsage returns accumulated token usage and estimated cost, optionally grouped by a metadata key
+func (s *AIService) GetUsage(groupBy string) (*models.AIUsageReport, error) {
+	if groupBy != "" {
+		supported := false
+		for _, key := range s.UsageTagKeys() {
+			if key == groupBy {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported usage grouping: %s", groupBy)
+		}
+	}
+
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	report := &models.AIUsageReport{
+		Total: s.usageTotal,
+		Since: s.usageSince,
+	}
+
+	if groupBy != "" {
+		report.GroupBy = groupBy
+		report.Groups = make([]models.AIUsageGroup, 0, len(s.usageByTag[groupBy]))
+		for value, usage := range s.usageByTag[groupBy] {
+			report.Groups = append(report.Groups, models.AIUsageGroup{Group: value, AIUsage: *usage})
+		}
+
+		// Most expensive groups first
+		sort.Slice(report.Groups, func(i, j int) bool {
+			if report.Groups[i].EstimatedCost != report.Groups[j].EstimatedCost {
+				return report.Groups[i].EstimatedCost > report.Groups[j].EstimatedCost
+			}
+			return report.Groups[i].Group < report.Groups[j].Group
+		})
+	}
+
+	return report, nil
+}
+
+// UsageSummary returns cumulative token usage in total and per request type
+func (s *AIService) UsageSummary() models.AIUsageSummary {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	summary := models.AIUsageSummary{
+		Total:         s.usageTotal,
+		ByRequestType: make(map[string]models.AIUsage, len(s.usageByType)),
+		Since:         s.usageSince,
+	}
+	for requestType, usage := range s.usageByType {
+		summary.ByRequestType[requestType] = *usage
+	}
+	return summary
+}
+
+// ResetUsage zeroes all usage counters and returns the report they held, so the final totals
+// of the period that ended can still be recorded
+func (s *AIService) ResetUsage() *models.AIUsageReport {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	previous := &models.AIUsageReport{
+		Total: s.usageTotal,
+		Since: s.usageSince,
+	}
+
+	s.usageTotal = models.AIUsage{}
+	s.usageByTag = make(map[string]map[string]*models.AIUsage)
+	s.usageByType = make(map[string]*models.AIUsage)
+	s.usageSince = time.Now()
+
+	return previous
+}
+
+// recordUsage adds the tokens of one OpenAI call to the totals, to its request type, to each
+// configured metadata tag and to the model that served it
+func (s *AIService) recordUsage(metadata map[string]string, requestType, model string, usage openai.Usage) {
+	var promptCost, completionCost float64
+	if s.config != nil {
+		promptCost = s.config.AIPromptCostPer1K
+		completionCost = s.config.AICompletionCostPer1K
+	}
+	cost := float64(usage.PromptTokens)/1000*promptCost + float64(usage.CompletionTokens)/1000*completionCost
+
+	add := func(u *models.AIUsage) {
+		u.Requests++
+		u.PromptTokens += usage.PromptTokens
+		u.CompletionTokens += usage.CompletionTokens
+		u.TotalTokens += usage.TotalTokens
+		u.EstimatedCost += cost
+	}
+
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	add(&s.usageTotal)
+
+	if s.usageByType[requestType] == nil {
+		s.usageByType[requestType] = &models.AIUsage{}
+	}
+	add(s.usageByType[requestType])
+
+	for _, key := range s.UsageTagKeys() {
+		value := metadata[key]
+		if key == models.AIUsageGroupByModel {
+			value = model
+		}
+		if value == "" {
+			value = models.AIUsageUnattributed
+		}
+
+		if s.usageByTag[key] == nil {
+			s.usageByTag[key] = make(map[string]*models.AIUsage)
+		}
+		if s.usageByTag[key][value] == nil {
+			s.usageByTag[key][value] = &models.AIUsage{}
+		}
+		add(s.usageByTag[key][value])
+	}
+}
+
+// HealthCheck performs a health check on each AI provider. It passes when any provider is healthy,
+// since requests fail over to it.
 func (s *AIService) HealthCheck(ctx context.Context) error {
-	if !s.IsAvailable() {
+	if len(s.providers) == 0 {
 		return fmt.Errorf("AI service is not available: API key not configured")
 	}
 
-	// Execute health check with circuit breaker
-	return s.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
+	var errs []error
+	for _, provider := range s.providers {
+		err := s.checkProvider(ctx, provider)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.name, err))
+	}
+	return fmt.Errorf("AI service health check failed: %w", errors.Join(errs...))
+}
+
+// checkProvider sends provider a minimal request through its circuit breaker
+func (s *AIService) checkProvider(ctx context.Context, provider *aiProvider) error {
+	return provider.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
 		// Apply rate limiting for health check
 		if err := s.rateLimiter.Wait(ctx); err != nil {
 			return fmt.Errorf("rate limit exceeded during health check: %w", err)
 		}
 
 		// Simple test request to verify API connectivity
-		_, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		_, err := provider.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
 			Model: openai.GPT3Dot5Turbo,
 			Messages: []openai.ChatCompletionMessage{
 				{
@@ -493,17 +889,17 @@ func (s *AIService) HealthCheck(ctx context.Context) error {
 		})
 
 		if err != nil {
-			s.updateAvailability(false, err)
-			return fmt.Errorf("AI service health check failed: %w", err)
+			s.updateAvailability(provider, false, err)
+			return err
 		}
 
-		s.updateAvailability(true, nil)
+		s.updateAvailability(provider, true, nil)
 		return nil
 	})
 }
 
 // broadcastAISuggestionReady broadcasts AI suggestion ready notification
-func (s *AIService) broadcastAISuggestionReady(requestID, requestType string, suggestionCount int) {
+func (s *AIService) broadcastAISuggestionReady(ctx context.Context, requestID, requestType string, suggestionCount int) {
 	if s.wsHub == nil {
 		return
 	}
@@ -516,12 +912,13 @@ func (s *AIService) broadcastAISuggestionReady(requestID, requestType string, su
 		"timestamp":        time.Now(),
 		"status":           "ready",
 	}
+	s.addTraceID(ctx, notificationData)
 
 	s.wsHub.BroadcastToAll("ai_suggestion_ready", notificationData)
 }
 
 // broadcastAILogAnalysisReady broadcasts AI log analysis ready notification
-func (s *AIService) broadcastAILogAnalysisReady(logCount, issueCount, patternCount int) {
+func (s *AIService) broadcastAILogAnalysisReady(ctx context.Context, logCount, issueCount, patternCount int) {
 	if s.wsHub == nil {
 		return
 	}
@@ -534,6 +931,17 @@ func (s *AIService) broadcastAILogAnalysisReady(logCount, issueCount, patternCou
 		"timestamp":      time.Now(),
 		"status":         "ready",
 	}
+	s.addTraceID(ctx, notificationData)
 
 	s.wsHub.BroadcastToAll("ai_suggestion_ready", notificationData)
 }
+
+// addTraceID attaches the originating request's trace ID to broadcast data when enabled
+func (s *AIService) addTraceID(ctx context.Context, data map[string]interface{}) {
+	if s.config == nil || !s.config.EnableWSCorrelationID {
+		return
+	}
+	if traceID := utils.TraceIDFromContext(ctx); traceID != "" {
+		data["trace_id"] = traceID
+	}
+}
@@ -2,14 +2,22 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func TestNewAIService(t *testing.T) {
@@ -93,6 +101,380 @@ func TestAIService_GetCodeSuggestions_ServiceUnavailable(t *testing.T) {
 	assert.Contains(t, response.Analysis, "unavailable")
 }
 
+func TestAIService_GetCodeSuggestions_BroadcastsTraceID(t *testing.T) {
+	cfg := &config.Config{
+		OpenAIAPIKey:          "",
+		EnableWSCorrelationID: true,
+	}
+	logger := utils.NewLogger("debug", "json")
+
+	var notification map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "ai_suggestion_ready", mock.Anything).Run(func(args mock.Arguments) {
+		notification = args.Get(1).(map[string]interface{})
+	}).Return()
+
+	service := NewAIService(cfg, mockHub, logger)
+	ctx := utils.ContextWithTraceID(context.Background(), "trace-789")
+
+	_, err := service.GetCodeSuggestions(ctx, &models.AIRequest{
+		Code:        "console.log('hello world');",
+		Language:    "javascript",
+		RequestType: "suggestion",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, notification)
+	assert.Equal(t, "trace-789", notification["trace_id"])
+}
+
+func TestAIService_UsageByProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Use const instead of var"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 500, "total_tokens": 1500}
+		}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:          "test-key",
+		AIUsageTagKeys:        []string{"project", "team"},
+		AIPromptCostPer1K:     0.002,
+		AICompletionCostPer1K: 0.004,
+	}
+	service := NewAIService(cfg, nil, utils.NewLogger("debug", "json"))
+
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+
+	requests := []map[string]string{
+		{"project": "checkout", "team": "payments"},
+		{"project": "checkout"},
+		{"project": "search"},
+		nil,
+	}
+	for _, metadata := range requests {
+		_, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+			Code:        "var x = 1;",
+			Language:    "javascript",
+			RequestType: "suggestion",
+			Metadata:    metadata,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("totals", func(t *testing.T) {
+		report, err := service.GetUsage("")
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, report.Total.Requests)
+		assert.Equal(t, 6000, report.Total.TotalTokens)
+		assert.InDelta(t, 4*0.004, report.Total.EstimatedCost, 1e-9)
+		assert.Empty(t, report.Groups)
+	})
+
+	t.Run("grouped by project", func(t *testing.T) {
+		report, err := service.GetUsage("project")
+		require.NoError(t, err)
+
+		require.Len(t, report.Groups, 3)
+		assert.Equal(t, "project", report.GroupBy)
+		assert.Equal(t, "checkout", report.Groups[0].Group)
+		assert.Equal(t, 2, report.Groups[0].Requests)
+		assert.Equal(t, 2000, report.Groups[0].PromptTokens)
+		assert.Equal(t, 1000, report.Groups[0].CompletionTokens)
+		assert.InDelta(t, 2*0.004, report.Groups[0].EstimatedCost, 1e-9)
+
+		groups := []string{report.Groups[1].Group, report.Groups[2].Group}
+		assert.ElementsMatch(t, []string{"search", models.AIUsageUnattributed}, groups)
+	})
+
+	t.Run("grouped by secondary tag", func(t *testing.T) {
+		report, err := service.GetUsage("team")
+		require.NoError(t, err)
+
+		require.Len(t, report.Groups, 2)
+		assert.Equal(t, models.AIUsageUnattributed, report.Groups[0].Group)
+		assert.Equal(t, 3, report.Groups[0].Requests)
+		assert.Equal(t, "payments", report.Groups[1].Group)
+	})
+
+	t.Run("unknown grouping", func(t *testing.T) {
+		_, err := service.GetUsage("customer")
+		assert.Error(t, err)
+	})
+}
+
+func TestAIService_UsageByRequestType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Looks fine"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120}
+		}`))
+	}))
+	defer server.Close()
+
+	service := NewAIService(&config.Config{OpenAIAPIKey: "test-key"}, nil, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	for _, requestType := range []string{"suggestion", "debug", "suggestion"} {
+		_, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+			Code:        "var x = 1;",
+			Language:    "javascript",
+			RequestType: requestType,
+		})
+		require.NoError(t, err)
+	}
+	_, err := service.AnalyzeLogs(context.Background(), &models.AILogAnalysisRequest{
+		Logs:         []models.LogEntry{{Level: "error", Source: "backend", Message: "Database timeout"}},
+		AnalysisType: "error_detection",
+	})
+	require.NoError(t, err)
+
+	summary := service.UsageSummary()
+	assert.Equal(t, 4, summary.Total.Requests)
+	assert.Equal(t, 480, summary.Total.TotalTokens)
+	require.Len(t, summary.ByRequestType, 3)
+	assert.Equal(t, models.AIUsage{Requests: 2, PromptTokens: 200, CompletionTokens: 40, TotalTokens: 240}, summary.ByRequestType["suggestion"])
+	assert.Equal(t, 120, summary.ByRequestType["debug"].TotalTokens)
+	assert.Equal(t, 120, summary.ByRequestType[models.AIUsageLogAnalysis].TotalTokens)
+
+	assert.Equal(t, summary, service.GetStatus()["token_usage"])
+
+	t.Run("reset", func(t *testing.T) {
+		previous := service.ResetUsage()
+		assert.Equal(t, 480, previous.Total.TotalTokens)
+		assert.Equal(t, summary.Since, previous.Since)
+
+		after := service.UsageSummary()
+		assert.Equal(t, models.AIUsage{}, after.Total)
+		assert.Empty(t, after.ByRequestType)
+		assert.True(t, after.Since.After(summary.Since))
+
+		report, err := service.GetUsage(models.AIUsageGroupByModel)
+		require.NoError(t, err)
+		assert.Empty(t, report.Groups)
+	})
+}
+
+func TestAIService_WeightedModelSelection(t *testing.T) {
+	var requestedMu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		requestedMu.Lock()
+		requested = append(requested, body.Model)
+		requestedMu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Use const instead of var"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150}
+		}`))
+	}))
+	defer server.Close()
+
+	newService := func(seed int64) *AIService {
+		cfg := &config.Config{
+			OpenAIAPIKey:   "test-key",
+			AIUsageTagKeys: []string{"project"},
+			AIModelWeights: []string{"gpt-3.5-turbo=50", "gpt-4o-mini=50"},
+		}
+		service := NewAIService(cfg, nil, utils.NewLogger("debug", "json"), AIServiceConfig{
+			ModelSource: rand.NewSource(seed),
+		})
+
+		clientConfig := openai.DefaultConfig("test-key")
+		clientConfig.BaseURL = server.URL + "/v1"
+		service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+		service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+		return service
+	}
+
+	request := func(model string) *models.AIRequest {
+		return &models.AIRequest{Code: "var x = 1;", Language: "javascript", RequestType: "suggestion", Model: model}
+	}
+
+	service := newService(1)
+	served := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		response, err := service.GetCodeSuggestions(context.Background(), request(""))
+		require.NoError(t, err)
+		served = append(served, response.Model)
+	}
+
+	// Each response records the model that was actually requested from OpenAI
+	requestedMu.Lock()
+	assert.Equal(t, served, requested)
+	requestedMu.Unlock()
+	assert.Contains(t, served, "gpt-3.5-turbo")
+	assert.Contains(t, served, "gpt-4o-mini")
+
+	t.Run("seeded selection is reproducible", func(t *testing.T) {
+		replay := newService(1)
+		for _, expected := range served {
+			response, err := replay.GetCodeSuggestions(context.Background(), request(""))
+			require.NoError(t, err)
+			assert.Equal(t, expected, response.Model)
+		}
+	})
+
+	t.Run("explicit model bypasses sampling", func(t *testing.T) {
+		response, err := service.GetCodeSuggestions(context.Background(), request("gpt-4o"))
+		require.NoError(t, err)
+		assert.Equal(t, "gpt-4o", response.Model)
+	})
+
+	t.Run("usage grouped by model", func(t *testing.T) {
+		report, err := service.GetUsage(models.AIUsageGroupByModel)
+		require.NoError(t, err)
+
+		counts := make(map[string]int)
+		for _, group := range report.Groups {
+			counts[group.Group] = group.Requests
+		}
+		assert.Equal(t, 21, counts["gpt-3.5-turbo"]+counts["gpt-4o-mini"]+counts["gpt-4o"])
+		assert.Equal(t, 1, counts["gpt-4o"])
+	})
+}
+
+func TestAIService_CompletionOptions(t *testing.T) {
+	type chatRequest struct {
+		Model       string  `json:"model"`
+		Temperature float32 `json:"temperature"`
+		MaxTokens   int     `json:"max_tokens"`
+	}
+	var lastMu sync.Mutex
+	var last chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastMu.Lock()
+		json.NewDecoder(r.Body).Decode(&last)
+		lastMu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Use const instead of var"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150}
+		}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		OpenAIAPIKey:    "test-key",
+		AIAllowedModels: []string{"gpt-3.5-turbo", "gpt-4o"},
+		AIMaxTokens:     2000,
+	}
+	service := NewAIService(cfg, nil, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	sent := func() chatRequest {
+		lastMu.Lock()
+		defer lastMu.Unlock()
+		return last
+	}
+	temperature := func(value float32) *float32 { return &value }
+	codeRequest := func() *models.AIRequest {
+		return &models.AIRequest{Code: "var x = 1;", Language: "javascript", RequestType: "suggestion"}
+	}
+
+	t.Run("defaults when omitted", func(t *testing.T) {
+		response, err := service.GetCodeSuggestions(context.Background(), codeRequest())
+		require.NoError(t, err)
+		assert.Equal(t, chatRequest{Model: openai.GPT3Dot5Turbo, Temperature: 0.3, MaxTokens: 1000}, sent())
+		assert.Equal(t, openai.GPT3Dot5Turbo, response.Model)
+	})
+
+	t.Run("request overrides", func(t *testing.T) {
+		req := codeRequest()
+		req.Model = "gpt-4o"
+		req.Temperature = temperature(0.9)
+		req.MaxTokens = 2000
+
+		response, err := service.GetCodeSuggestions(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, chatRequest{Model: "gpt-4o", Temperature: 0.9, MaxTokens: 2000}, sent())
+		assert.Equal(t, "gpt-4o", response.Model)
+	})
+
+	t.Run("zero temperature is sent", func(t *testing.T) {
+		req := codeRequest()
+		req.Temperature = temperature(0)
+
+		_, err := service.GetCodeSuggestions(context.Background(), req)
+		require.NoError(t, err)
+		assert.InDelta(t, 0, sent().Temperature, 1e-6)
+		assert.NotZero(t, sent().Temperature)
+	})
+
+	t.Run("log analysis", func(t *testing.T) {
+		req := &models.AILogAnalysisRequest{
+			Logs:         []models.LogEntry{{Level: "error", Source: "backend", Message: "Database timeout"}},
+			AnalysisType: "error_detection",
+		}
+		response, err := service.AnalyzeLogs(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, chatRequest{Model: openai.GPT3Dot5Turbo, Temperature: 0.2, MaxTokens: 1500}, sent())
+
+		req.Model = "gpt-4o"
+		req.Temperature = temperature(0.5)
+		req.MaxTokens = 500
+		response, err = service.AnalyzeLogs(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, chatRequest{Model: "gpt-4o", Temperature: 0.5, MaxTokens: 500}, sent())
+		assert.Equal(t, "gpt-4o", response.Model)
+	})
+
+	t.Run("model outside the allow-list", func(t *testing.T) {
+		req := codeRequest()
+		req.Model = "gpt-4-32k"
+		_, err := service.GetCodeSuggestions(context.Background(), req)
+		assert.ErrorIs(t, err, ErrAIModelNotAllowed)
+
+		_, err = service.AnalyzeLogs(context.Background(), &models.AILogAnalysisRequest{
+			Logs:         []models.LogEntry{{Level: "error", Source: "backend", Message: "Database timeout"}},
+			AnalysisType: "error_detection",
+			Model:        "gpt-4-32k",
+		})
+		assert.ErrorIs(t, err, ErrAIModelNotAllowed)
+	})
+
+	t.Run("max tokens above the limit", func(t *testing.T) {
+		req := codeRequest()
+		req.MaxTokens = 2001
+		_, err := service.GetCodeSuggestions(context.Background(), req)
+		assert.ErrorIs(t, err, ErrAIMaxTokensExceeded)
+	})
+
+	t.Run("options are checked before falling back", func(t *testing.T) {
+		unavailable := NewAIService(&config.Config{AIAllowedModels: []string{"gpt-4o"}}, nil, utils.NewLogger("debug", "json"))
+		req := codeRequest()
+		req.Model = "gpt-4-32k"
+		_, err := unavailable.GetCodeSuggestions(context.Background(), req)
+		assert.ErrorIs(t, err, ErrAIModelNotAllowed)
+	})
+}
+
 func TestAIService_GetCodeSuggestions_DifferentRequestTypes(t *testing.T) {
 	cfg := &config.Config{
 		OpenAIAPIKey: "", // No API key to test fallback responses
@@ -333,7 +715,7 @@ func TestAIService_updateAvailability(t *testing.T) {
 
 	// Update to unavailable
 	testErr := assert.AnError
-	service.updateAvailability(false, testErr)
+	service.updateAvailability(service.providers[0], false, testErr)
 
 	assert.False(t, service.IsAvailable())
 
@@ -343,7 +725,7 @@ func TestAIService_updateAvailability(t *testing.T) {
 	assert.NotNil(t, status["last_check"])
 
 	// Update back to available
-	service.updateAvailability(true, nil)
+	service.updateAvailability(service.providers[0], true, nil)
 	assert.True(t, service.IsAvailable())
 
 	status = service.GetStatus()
@@ -367,7 +749,7 @@ func TestAIService_GetStatus(t *testing.T) {
 
 	// Test with error
 	testErr := assert.AnError
-	service.updateAvailability(false, testErr)
+	service.updateAvailability(service.providers[0], false, testErr)
 
 	status = service.GetStatus()
 	assert.False(t, status["available"].(bool))
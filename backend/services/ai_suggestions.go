@@ -0,0 +1,81 @@
+package services
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// codeSuggestionSystemPrompt asks for suggestions as a JSON array that parseSuggestionJSON can read
+const codeSuggestionSystemPrompt = `You are an expert code assistant. Provide helpful, accurate code suggestions and improvements.
+Respond with a JSON array only, without Markdown or any other text. Each element is an object with these fields:
+- "type": one of "improvement", "fix", "optimization", "refactor"
+- "description": a one-sentence summary of the suggestion
+- "code": the suggested replacement code, or "" if there is none
+- "line_number": the 1-based line of the submitted code the suggestion applies to, or 0 if it applies to the whole snippet
+- "priority": one of "high", "medium", "low"
+- "reasoning": why the change helps
+Return [] when there is nothing to suggest.`
+
+var (
+	suggestionTypes      = []string{"improvement", "fix", "optimization", "refactor"}
+	suggestionPriorities = []string{"high", "medium", "low"}
+)
+
+// parseSuggestionJSON reads a JSON array of suggestions from a model reply, tolerating a
+// surrounding Markdown code fence. Entries without a description are dropped and unknown
+// types or priorities are normalized. The bool is false when the reply is not a JSON array
+// or it has entries but none are usable; an empty array means there is nothing to suggest.
+func parseSuggestionJSON(content string) ([]models.Suggestion, bool) {
+	var parsed []models.Suggestion
+	if err := json.Unmarshal([]byte(stripCodeFence(content)), &parsed); err != nil || parsed == nil {
+		return nil, false
+	}
+	if len(parsed) == 0 {
+		return []models.Suggestion{}, true
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(parsed))
+	for _, suggestion := range parsed {
+		suggestion.Description = strings.TrimSpace(suggestion.Description)
+		if suggestion.Description == "" {
+			continue
+		}
+
+		suggestion.Type = strings.ToLower(strings.TrimSpace(suggestion.Type))
+		if !slices.Contains(suggestionTypes, suggestion.Type) {
+			suggestion.Type = "improvement"
+		}
+		suggestion.Priority = strings.ToLower(strings.TrimSpace(suggestion.Priority))
+		if !slices.Contains(suggestionPriorities, suggestion.Priority) {
+			suggestion.Priority = "medium"
+		}
+		if suggestion.LineNumber < 0 {
+			suggestion.LineNumber = 0
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if len(suggestions) == 0 {
+		return nil, false
+	}
+	return suggestions, true
+}
+
+// stripCodeFence removes a Markdown code fence (``` or ```json) wrapped around a reply
+func stripCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") || !strings.HasSuffix(content, "```") || len(content) < 6 {
+		return content
+	}
+
+	content = strings.TrimSuffix(strings.TrimPrefix(content, "```"), "```")
+	// Drop the language tag on the opening fence line
+	if newline := strings.IndexByte(content, '\n'); newline >= 0 && !strings.ContainsAny(content[:newline], "[{") {
+		content = content[newline+1:]
+	}
+	return strings.TrimSpace(content)
+}
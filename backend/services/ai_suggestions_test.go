@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestParseSuggestionJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []models.Suggestion
+		ok       bool
+	}{
+		{
+			name: "array",
+			content: `[
+				{"type": "fix", "description": "Guard against undefined", "code": "if (!x) return;", "line_number": 3, "priority": "high", "reasoning": "x may be undefined"},
+				{"type": "refactor", "description": "Extract a helper", "line_number": 7, "priority": "low"}
+			]`,
+			expected: []models.Suggestion{
+				{Type: "fix", Description: "Guard against undefined", Code: "if (!x) return;", LineNumber: 3, Priority: "high", Reasoning: "x may be undefined"},
+				{Type: "refactor", Description: "Extract a helper", LineNumber: 7, Priority: "low"},
+			},
+			ok: true,
+		},
+		{
+			name:     "fenced",
+			content:  "```json\n[{\"type\": \"optimization\", \"description\": \"Cache the length\", \"line_number\": 1, \"priority\": \"medium\"}]\n```",
+			expected: []models.Suggestion{{Type: "optimization", Description: "Cache the length", LineNumber: 1, Priority: "medium"}},
+			ok:       true,
+		},
+		{
+			name:     "fence without language tag",
+			content:  "```\n[{\"type\": \"fix\", \"description\": \"Close the file\", \"priority\": \"high\"}]\n```",
+			expected: []models.Suggestion{{Type: "fix", Description: "Close the file", Priority: "high"}},
+			ok:       true,
+		},
+		{
+			name: "normalizes unknown values and drops entries without a description",
+			content: `[
+				{"type": "Security", "description": " Escape user input ", "line_number": -4, "priority": "URGENT"},
+				{"type": "fix", "description": ""}
+			]`,
+			expected: []models.Suggestion{{Type: "improvement", Description: "Escape user input", Priority: "medium"}},
+			ok:       true,
+		},
+		{name: "empty array", content: `[]`, expected: []models.Suggestion{}, ok: true},
+		{name: "prose", content: "Here are some suggestions for your code...", ok: false},
+		{name: "object instead of array", content: `{"type": "fix", "description": "Close the file"}`, ok: false},
+		{name: "null", content: `null`, ok: false},
+		{name: "no usable entries", content: `[{"type": "fix"}]`, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, ok := parseSuggestionJSON(tt.content)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, suggestions)
+		})
+	}
+}
+
+func TestAIService_GetCodeSuggestions_StructuredSuggestions(t *testing.T) {
+	reply := "```json\n" + `[
+		{"type": "fix", "description": "Use strict equality", "code": "if (a === b) {}", "line_number": 2, "priority": "high", "reasoning": "== coerces types"},
+		{"type": "improvement", "description": "Prefer const", "code": "const x = 1;", "line_number": 1, "priority": "low", "reasoning": "x is never reassigned"}
+	]` + "\n```"
+
+	var systemPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		systemPrompt = body.Messages[0].Content
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": reply}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150},
+		})
+	}))
+	defer server.Close()
+
+	var notification map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "ai_suggestion_ready", mock.Anything).Run(func(args mock.Arguments) {
+		notification = args.Get(1).(map[string]interface{})
+	}).Return()
+
+	service := NewAIService(&config.Config{OpenAIAPIKey: "test-key"}, mockHub, utils.NewLogger("debug", "json"))
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	service.providers[0].client = openai.NewClientWithConfig(clientConfig)
+	service.rateLimiter = rate.NewLimiter(rate.Inf, 1)
+
+	response, err := service.GetCodeSuggestions(context.Background(), &models.AIRequest{
+		Code:        "var x = 1;\nif (a == b) {}",
+		Language:    "javascript",
+		RequestType: "suggestion",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, systemPrompt, "JSON array")
+	require.Len(t, response.Suggestions, 2)
+	assert.Equal(t, models.Suggestion{
+		Type: "fix", Description: "Use strict equality", Code: "if (a === b) {}", LineNumber: 2, Priority: "high", Reasoning: "== coerces types",
+	}, response.Suggestions[0])
+	assert.Equal(t, 1, response.Suggestions[1].LineNumber)
+
+	require.NotNil(t, notification)
+	assert.Equal(t, 2, notification["suggestion_count"])
+}
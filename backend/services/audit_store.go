@@ -0,0 +1,343 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/google/uuid"
+)
+
+// DefaultAuditMaxEvents is how many events an AuditStore keeps when no limit is given
+const DefaultAuditMaxEvents = 1000
+
+// AuditStore keeps an append-only record of designated hub broadcasts, such as alerts and test
+// results, so they can be reviewed after the fact. It is bounded: once full, the oldest events
+// are evicted. Events are never modified once recorded.
+type AuditStore struct {
+	mu           sync.RWMutex
+	types        map[string]bool
+	testStatuses map[string]bool // test_progress statuses recorded; empty records every status
+	maxEvents    int
+	events       []models.AuditEvent // Oldest first
+	evicted      int64
+	logger       *utils.Logger
+
+	// Events are appended to a JSON Lines file, one per line, which is rewritten with just the
+	// held events once it has grown to twice maxEvents lines. fileMu serializes file writes so
+	// s.mu is never held across I/O.
+	fileMu     sync.Mutex
+	path       string
+	file       *os.File
+	fileEvents int
+}
+
+// AuditStoreConfig holds optional settings for an AuditStore
+type AuditStoreConfig struct {
+	// Path of the file events are appended to and reloaded from on startup; empty keeps them in
+	// memory only
+	Path string
+	// TestStatuses limits recorded test_progress broadcasts to these run statuses; empty records
+	// every status
+	TestStatuses []string
+}
+
+// AuditFilter narrows an audit event query. Zero values match everything.
+type AuditFilter struct {
+	Types []string
+	Start time.Time // Inclusive
+	End   time.Time // Inclusive
+	Limit int       // Newest events returned; 0 returns every match
+}
+
+// NewAuditStore creates a store recording broadcasts of the given types, keeping at most
+// maxEvents of them (DefaultAuditMaxEvents when maxEvents is 0 or less). With a config Path, the
+// newest events in the file are loaded back; if it can't be used, events are kept in memory only.
+func NewAuditStore(types []string, maxEvents int, config ...AuditStoreConfig) *AuditStore {
+	if maxEvents <= 0 {
+		maxEvents = DefaultAuditMaxEvents
+	}
+
+	store := &AuditStore{
+		types:        make(map[string]bool, len(types)),
+		testStatuses: make(map[string]bool),
+		maxEvents:    maxEvents,
+		logger:       utils.GetLogger(),
+	}
+	for _, msgType := range types {
+		store.types[msgType] = true
+	}
+
+	if len(config) > 0 {
+		for _, status := range config[0].TestStatuses {
+			store.testStatuses[status] = true
+		}
+		if config[0].Path != "" {
+			if err := store.openFile(config[0].Path); err != nil {
+				store.logger.Warn("Audit events will be kept in memory only", map[string]interface{}{
+					"path":  config[0].Path,
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+
+	return store
+}
+
+// openFile loads the newest events from path and opens it for appending, compacting it first
+func (s *AuditStore) openFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	events, err := readAuditFile(path)
+	if err != nil {
+		return err
+	}
+	if len(events) > s.maxEvents {
+		events = events[len(events)-s.maxEvents:]
+	}
+	s.events = events
+	s.path = path
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if err := s.compactFileLocked(events); err != nil {
+		s.path = ""
+		return err
+	}
+	return nil
+}
+
+// readAuditFile decodes every event in a JSON Lines file. A missing file holds no events, and a
+// line cut short by a crash ends the file.
+func readAuditFile(path string) ([]models.AuditEvent, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	var events []models.AuditEvent
+	decoder := json.NewDecoder(file)
+	for {
+		var event models.AuditEvent
+		if err := decoder.Decode(&event); err != nil {
+			if !errors.Is(err, io.EOF) {
+				utils.GetLogger().Warn("Ignoring unreadable audit events", map[string]interface{}{
+					"path":  path,
+					"error": err.Error(),
+				})
+			}
+			return events, nil
+		}
+		events = append(events, event)
+	}
+}
+
+// compactFileLocked replaces the file with events and reopens it for appending. The caller
+// holds s.fileMu.
+func (s *AuditStore) compactFileLocked(events []models.AuditEvent) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".audit-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact audit file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact audit file: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact audit file: %w", err)
+	}
+	// Audit payloads may hold run details, so the file is only readable by its owner
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return fmt.Errorf("failed to compact audit file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to compact audit file: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	s.file = file
+	s.fileEvents = len(events)
+	return nil
+}
+
+// persist appends event to the file, compacting it once it holds twice maxEvents events
+func (s *AuditStore) persist(event models.AuditEvent) {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(event)
+	if err == nil {
+		_, err = s.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		s.logger.Warn("Failed to persist audit event", map[string]interface{}{
+			"type":  event.Type,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.fileEvents++
+	if s.fileEvents >= 2*s.maxEvents {
+		s.mu.RLock()
+		events := append([]models.AuditEvent(nil), s.events...)
+		s.mu.RUnlock()
+		if err := s.compactFileLocked(events); err != nil {
+			s.logger.Warn("Failed to compact audit file", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// Close closes the audit file, after which events are kept in memory only
+func (s *AuditStore) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// RecordBroadcast records message if its type is audited. The payload is serialized
+// immediately, so the record reflects the message as it was broadcast.
+func (s *AuditStore) RecordBroadcast(message models.WSMessage) {
+	if s == nil || !s.types[message.Type] {
+		return
+	}
+	if message.Type == "test_progress" && len(s.testStatuses) > 0 {
+		if data, ok := message.Data.(map[string]interface{}); ok {
+			if status, _ := data["status"].(string); !s.testStatuses[status] {
+				return
+			}
+		}
+	}
+
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		s.logger.Warn("Failed to serialize broadcast for audit", map[string]interface{}{
+			"type":  message.Type,
+			"error": err.Error(),
+		})
+		data, _ = json.Marshal(map[string]string{"error": fmt.Sprintf("unserializable payload: %v", err)})
+	}
+
+	event := models.AuditEvent{
+		ID:        uuid.New().String(),
+		Type:      message.Type,
+		Timestamp: message.Timestamp,
+		Data:      data,
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	s.mu.Lock()
+	if len(s.events) >= s.maxEvents {
+		dropped := len(s.events) - s.maxEvents + 1
+		s.events = append(s.events[:0:0], s.events[dropped:]...)
+		s.evicted += int64(dropped)
+	}
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+
+	s.persist(event)
+}
+
+// Query returns the newest events matching filter, newest first, along with how many events
+// matched before the limit was applied
+func (s *AuditStore) Query(filter AuditFilter) ([]models.AuditEvent, int) {
+	types := make(map[string]bool, len(filter.Types))
+	for _, msgType := range filter.Types {
+		types[msgType] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]models.AuditEvent, 0)
+	for i := len(s.events) - 1; i >= 0; i-- {
+		event := s.events[i]
+		if len(types) > 0 && !types[event.Type] {
+			continue
+		}
+		if !filter.Start.IsZero() && event.Timestamp.Before(filter.Start) {
+			continue
+		}
+		if !filter.End.IsZero() && event.Timestamp.After(filter.End) {
+			continue
+		}
+		matched = append(matched, copyAuditEvent(event))
+	}
+
+	total := len(matched)
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total
+}
+
+// Types returns the broadcast types being recorded, sorted
+func (s *AuditStore) Types() []string {
+	types := make([]string, 0, len(s.types))
+	for msgType := range s.types {
+		types = append(types, msgType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Stats reports how many events are held and how many have been evicted to stay in bounds
+func (s *AuditStore) Stats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return map[string]interface{}{
+		"events":     len(s.events),
+		"max_events": s.maxEvents,
+		"evicted":    s.evicted,
+	}
+}
+
+// copyAuditEvent returns event with its own copy of the payload, so callers can't alter the record
+func copyAuditEvent(event models.AuditEvent) models.AuditEvent {
+	event.Data = append(json.RawMessage(nil), event.Data...)
+	return event
+}
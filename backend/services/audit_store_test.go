@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditStore_RecordBroadcast(t *testing.T) {
+	store := NewAuditStore([]string{"log_alert", "test_progress"}, 10)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	payload := map[string]interface{}{"level": "critical", "message": "disk full"}
+	store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: payload, Timestamp: base})
+	store.RecordBroadcast(models.WSMessage{Type: "ai_suggestion_ready", Data: "ignored", Timestamp: base})
+
+	events, total := store.Query(AuditFilter{})
+	require.Len(t, events, 1)
+	assert.Equal(t, 1, total)
+	assert.NotEmpty(t, events[0].ID)
+	assert.Equal(t, "log_alert", events[0].Type)
+	assert.Equal(t, base, events[0].Timestamp)
+	assert.JSONEq(t, `{"level":"critical","message":"disk full"}`, string(events[0].Data))
+
+	t.Run("records are immutable", func(t *testing.T) {
+		// Neither the broadcaster changing its payload nor a caller changing a result alters the record
+		payload["level"] = "info"
+		events[0].Data[2] = 'X'
+
+		again, _ := store.Query(AuditFilter{})
+		assert.JSONEq(t, `{"level":"critical","message":"disk full"}`, string(again[0].Data))
+	})
+
+	t.Run("unserializable payloads are still recorded", func(t *testing.T) {
+		store.RecordBroadcast(models.WSMessage{Type: "test_progress", Data: func() {}})
+
+		events, _ := store.Query(AuditFilter{Types: []string{"test_progress"}})
+		require.Len(t, events, 1)
+		assert.False(t, events[0].Timestamp.IsZero())
+		assert.Contains(t, string(events[0].Data), "unserializable payload")
+	})
+
+	t.Run("nil store", func(t *testing.T) {
+		var disabled *AuditStore
+		disabled.RecordBroadcast(models.WSMessage{Type: "log_alert"})
+	})
+}
+
+func TestAuditStore_Query(t *testing.T) {
+	store := NewAuditStore([]string{"log_alert", "test_progress"}, 10)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, msgType := range []string{"log_alert", "test_progress", "log_alert", "test_progress"} {
+		store.RecordBroadcast(models.WSMessage{Type: msgType, Data: i, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	payloads := func(events []models.AuditEvent) []int {
+		values := make([]int, len(events))
+		for i, event := range events {
+			require.NoError(t, json.Unmarshal(event.Data, &values[i]))
+		}
+		return values
+	}
+
+	events, total := store.Query(AuditFilter{})
+	assert.Equal(t, []int{3, 2, 1, 0}, payloads(events), "newest first")
+	assert.Equal(t, 4, total)
+
+	events, total = store.Query(AuditFilter{Types: []string{"log_alert"}})
+	assert.Equal(t, []int{2, 0}, payloads(events))
+	assert.Equal(t, 2, total)
+
+	events, _ = store.Query(AuditFilter{Start: base.Add(time.Minute), End: base.Add(2 * time.Minute)})
+	assert.Equal(t, []int{2, 1}, payloads(events))
+
+	events, total = store.Query(AuditFilter{Limit: 3})
+	assert.Equal(t, []int{3, 2, 1}, payloads(events))
+	assert.Equal(t, 4, total)
+
+	events, total = store.Query(AuditFilter{Types: []string{"sync_status_update"}})
+	assert.Empty(t, events)
+	assert.NotNil(t, events)
+	assert.Equal(t, 0, total)
+}
+
+func TestAuditStore_EvictsOldest(t *testing.T) {
+	store := NewAuditStore([]string{"log_alert"}, 3)
+	for i := 0; i < 5; i++ {
+		store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: i})
+	}
+
+	events, total := store.Query(AuditFilter{})
+	assert.Equal(t, 3, total)
+	assert.JSONEq(t, "4", string(events[0].Data))
+	assert.JSONEq(t, "2", string(events[2].Data))
+	assert.Equal(t, map[string]interface{}{"events": 3, "max_events": 3, "evicted": int64(2)}, store.Stats())
+
+	assert.Equal(t, DefaultAuditMaxEvents, NewAuditStore(nil, 0).maxEvents)
+	assert.Equal(t, []string{"a", "b"}, NewAuditStore([]string{"b", "a"}, 1).Types())
+}
+
+func TestAuditStore_TestStatuses(t *testing.T) {
+	store := NewAuditStore([]string{"test_progress", "log_alert"}, 10, AuditStoreConfig{TestStatuses: []string{"completed", "failed"}})
+
+	for _, status := range []string{"running", "completed", "running", "failed"} {
+		store.RecordBroadcast(models.WSMessage{Type: "test_progress", Data: map[string]interface{}{"status": status}})
+	}
+	store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: map[string]interface{}{"status": "running"}})
+
+	events, _ := store.Query(AuditFilter{Types: []string{"test_progress"}})
+	require.Len(t, events, 2)
+	assert.JSONEq(t, `{"status":"failed"}`, string(events[0].Data))
+	assert.JSONEq(t, `{"status":"completed"}`, string(events[1].Data))
+
+	// Other types aren't filtered by status
+	alerts, _ := store.Query(AuditFilter{Types: []string{"log_alert"}})
+	assert.Len(t, alerts, 1)
+}
+
+func TestAuditStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	store := NewAuditStore([]string{"log_alert"}, 3, AuditStoreConfig{Path: path})
+	for i := 0; i < 5; i++ {
+		store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: i, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	require.NoError(t, store.Close())
+
+	// A restarted store serves the newest events it held
+	reopened := NewAuditStore([]string{"log_alert"}, 3, AuditStoreConfig{Path: path})
+	defer reopened.Close()
+	events, total := reopened.Query(AuditFilter{})
+	assert.Equal(t, 3, total)
+	for i, event := range events {
+		assert.Equal(t, json.RawMessage(strconv.Itoa(4-i)), event.Data)
+	}
+
+	t.Run("the file is compacted", func(t *testing.T) {
+		for i := 5; i < 12; i++ {
+			reopened.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: i, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Less(t, strings.Count(string(data), "\n"), 6)
+	})
+
+	t.Run("a truncated last line is ignored", func(t *testing.T) {
+		truncated := filepath.Join(t.TempDir(), "audit.jsonl")
+		line, err := json.Marshal(models.AuditEvent{ID: "event-1", Type: "log_alert", Timestamp: base, Data: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(truncated, append(append(line, '\n'), `{"id":"event-2","ty`...), 0o600))
+
+		store := NewAuditStore([]string{"log_alert"}, 3, AuditStoreConfig{Path: truncated})
+		defer store.Close()
+		events, _ := store.Query(AuditFilter{})
+		require.Len(t, events, 1)
+		assert.Equal(t, "event-1", events[0].ID)
+
+		// New events are appended after the readable ones
+		store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: 1, Timestamp: base})
+		events, err = readAuditFile(truncated)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("an unusable path keeps events in memory", func(t *testing.T) {
+		blocker := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+		store := NewAuditStore([]string{"log_alert"}, 3, AuditStoreConfig{Path: filepath.Join(blocker, "audit.jsonl")})
+		store.RecordBroadcast(models.WSMessage{Type: "log_alert", Data: 1})
+		_, total := store.Query(AuditFilter{})
+		assert.Equal(t, 1, total)
+		assert.NoError(t, store.Close())
+	})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// ErrInvalidChaosRequest is returned by Inject for a request missing what its action needs
+var ErrInvalidChaosRequest = errors.New("invalid chaos request")
+
+// Messages used when a ChaosRequest doesn't give one
+const (
+	defaultChaosReason       = "synthetic failure injected for resilience testing"
+	defaultChaosAlertMessage = "Synthetic critical alert injected for resilience testing"
+)
+
+// ChaosService injects synthetic failures into the other services, so dashboards and alerting
+// can be tested without waiting for real failures. Each failure goes through the same code paths
+// and broadcasts as a real one and wears off the same way. Only meant for development and staging.
+type ChaosService struct {
+	aiService   *AIService
+	syncService *SyncService
+	logService  *LogService
+	logger      *utils.Logger
+}
+
+// NewChaosService creates a chaos service acting on the given services
+func NewChaosService(aiService *AIService, syncService *SyncService, logService *LogService) *ChaosService {
+	return &ChaosService{
+		aiService:   aiService,
+		syncService: syncService,
+		logService:  logService,
+		logger:      utils.GetLogger(),
+	}
+}
+
+// Inject injects the failure req asks for
+func (s *ChaosService) Inject(ctx context.Context, req *models.ChaosRequest) (*models.ChaosResult, error) {
+	result := &models.ChaosResult{
+		Action:     req.Action,
+		Message:    req.Message,
+		InjectedAt: time.Now(),
+	}
+
+	switch req.Action {
+	case models.ChaosActionAICircuitOpen:
+		tripped, err := s.aiService.TripCircuitBreakers(req.Target)
+		if err != nil {
+			return nil, err
+		}
+		result.Targets = tripped
+
+	case models.ChaosActionEnvironmentUnhealthy:
+		if req.Target == "" {
+			return nil, fmt.Errorf("%w: target must name the environment to mark unhealthy", ErrInvalidChaosRequest)
+		}
+		if result.Message == "" {
+			result.Message = defaultChaosReason
+		}
+		if _, err := s.syncService.MarkEnvironmentUnhealthy(req.Target, result.Message); err != nil {
+			return nil, err
+		}
+		result.Targets = []string{req.Target}
+
+	case models.ChaosActionLogAlert:
+		if result.Message == "" {
+			result.Message = defaultChaosAlertMessage
+		}
+		result.AlertID = s.logService.SendSyntheticAlert(ctx, result.Message)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidChaosRequest, req.Action)
+	}
+
+	s.logger.WithTraceID(utils.TraceIDFromContext(ctx)).Warn("Injected synthetic failure", map[string]interface{}{
+		"action":  result.Action,
+		"targets": result.Targets,
+	})
+
+	return result, nil
+}
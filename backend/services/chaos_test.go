@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosService_Inject(t *testing.T) {
+	hub := &MockWebSocketHub{}
+	hub.On("BroadcastToAll", mock.Anything, mock.Anything).Return()
+
+	aiService := NewAIService(&config.Config{OpenAIAPIKey: "primary-key", AISecondaryAPIKey: "secondary-key"}, nil, utils.NewLogger("debug", "json"))
+	syncService := NewSyncService(hub)
+	syncService.environments["staging"] = &models.SyncEnvironment{Name: "staging", Status: "active"}
+	logService := NewLogService(nil, hub)
+	chaos := NewChaosService(aiService, syncService, logService)
+
+	t.Run("opens one AI circuit breaker", func(t *testing.T) {
+		result, err := chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionAICircuitOpen, Target: AIProviderSecondary})
+		require.NoError(t, err)
+		assert.Equal(t, []string{AIProviderSecondary}, result.Targets)
+		assert.False(t, aiService.providers[0].circuitBreaker.IsOpen())
+		assert.True(t, aiService.providers[1].circuitBreaker.IsOpen())
+	})
+
+	t.Run("opens every AI circuit breaker", func(t *testing.T) {
+		result, err := chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionAICircuitOpen})
+		require.NoError(t, err)
+		assert.Equal(t, []string{AIProviderPrimary, AIProviderSecondary}, result.Targets)
+		assert.True(t, aiService.providers[0].circuitBreaker.IsOpen())
+
+		_, err = chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionAICircuitOpen, Target: "tertiary"})
+		assert.ErrorIs(t, err, ErrAIProviderNotFound)
+	})
+
+	t.Run("marks an environment unhealthy", func(t *testing.T) {
+		result, err := chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionEnvironmentUnhealthy, Target: "staging"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"staging"}, result.Targets)
+		assert.Equal(t, defaultChaosReason, result.Message)
+
+		syncService.mutex.RLock()
+		env := syncService.environments["staging"]
+		syncService.mutex.RUnlock()
+		assert.Equal(t, "error", env.Status)
+		assert.Equal(t, defaultChaosReason, env.Metadata["backend_error"])
+		hub.AssertCalled(t, "BroadcastToAll", "sync_status_update", mock.MatchedBy(func(data map[string]interface{}) bool {
+			return data["type"] == "environment_health_change" && data["environment"] == "staging" &&
+				data["status"] == "error" && data["previous_status"] == "active"
+		}))
+
+		_, err = chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionEnvironmentUnhealthy, Target: "missing"})
+		assert.ErrorIs(t, err, ErrEnvironmentNotFound)
+		_, err = chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionEnvironmentUnhealthy})
+		assert.ErrorIs(t, err, ErrInvalidChaosRequest)
+	})
+
+	t.Run("raises a critical log alert", func(t *testing.T) {
+		result, err := chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionLogAlert, Message: "disk full"})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.AlertID)
+
+		hub.AssertCalled(t, "BroadcastToAll", "log_alert", mock.MatchedBy(func(data map[string]interface{}) bool {
+			return data["alert_id"] == result.AlertID && data["message"] == "disk full" && data["level"] == "error"
+		}))
+		assert.Zero(t, logService.GetLogCount(), "the synthetic entry isn't stored")
+
+		alert, err := logService.AcknowledgeAlert(result.AlertID, "on-call")
+		require.NoError(t, err)
+		assert.Equal(t, "on-call", alert.AcknowledgedBy)
+	})
+
+	t.Run("without AI providers", func(t *testing.T) {
+		chaos := NewChaosService(NewAIService(&config.Config{}, nil, utils.NewLogger("debug", "json")), syncService, logService)
+		_, err := chaos.Inject(context.Background(), &models.ChaosRequest{Action: models.ChaosActionAICircuitOpen})
+		assert.ErrorIs(t, err, ErrAIProviderNotFound)
+	})
+}
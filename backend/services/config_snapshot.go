@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// ErrInvalidConfigSnapshot is returned when importing a snapshot with an invalid section; nothing
+// is applied
+var ErrInvalidConfigSnapshot = errors.New("invalid config snapshot")
+
+// ErrUnsupportedConfigSnapshot is returned when importing a snapshot written by a newer server
+var ErrUnsupportedConfigSnapshot = errors.New("unsupported config snapshot version")
+
+// Sections of a ServiceConfigSnapshot, as reported in ConfigImportResult.Applied
+const (
+	configSectionLogFormat          = "log_format"
+	configSectionLogIngestionPaused = "log_ingestion_paused"
+	configSectionLogAlertRules      = "log_alert_rules"
+	configSectionSyncContracts      = "sync_contracts"
+	configSectionTestSchedules      = "test_schedules"
+)
+
+// configImportPauseReason is the ingestion pause reason set when an import pauses ingestion
+const configImportPauseReason = "paused by configuration import"
+
+// ConfigSnapshotService exports the runtime-mutable configuration of the log, sync and test
+// services as one document, and imports such a document to reproduce the setup on another server
+type ConfigSnapshotService struct {
+	logService  *LogService
+	syncService *SyncService
+	testService *TestService
+	features    map[string]bool
+	logger      *utils.Logger
+}
+
+// NewConfigSnapshotService creates a snapshot service. features are the server's feature flags,
+// which are exported for reference but can't be changed by an import.
+func NewConfigSnapshotService(logService *LogService, syncService *SyncService, testService *TestService, features map[string]bool) *ConfigSnapshotService {
+	return &ConfigSnapshotService{
+		logService:  logService,
+		syncService: syncService,
+		testService: testService,
+		features:    features,
+		logger:      utils.GetLogger(),
+	}
+}
+
+// Export returns the current runtime-mutable configuration
+func (s *ConfigSnapshotService) Export() *models.ServiceConfigSnapshot {
+	paused := s.logService.GetIngestionState().Paused
+	return &models.ServiceConfigSnapshot{
+		Version:            models.ConfigSnapshotVersion,
+		ExportedAt:         time.Now(),
+		Features:           s.features,
+		LogFormat:          utils.GetLogger().Format(),
+		LogIngestionPaused: &paused,
+		LogAlertRules:      append([]models.LogAlertRule{}, s.logService.GetAlertRules()...),
+		SyncContracts:      s.syncService.ListContracts(),
+		TestSchedules:      s.testService.ScheduleRequests(),
+	}
+}
+
+// Import applies the sections present in snapshot. Every section is validated before any is
+// applied, so an invalid snapshot changes nothing. Feature flags that differ from the snapshot
+// are reported as warnings.
+func (s *ConfigSnapshotService) Import(snapshot *models.ServiceConfigSnapshot) (*models.ConfigImportResult, error) {
+	if snapshot.Version > models.ConfigSnapshotVersion {
+		return nil, fmt.Errorf("%w: %d (this server reads up to %d)",
+			ErrUnsupportedConfigSnapshot, snapshot.Version, models.ConfigSnapshotVersion)
+	}
+
+	switch snapshot.LogFormat {
+	case "", utils.LogFormatJSON, utils.LogFormatText, utils.LogFormatConsole:
+	default:
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfigSnapshot, configSectionLogFormat, utils.ErrUnknownLogFormat)
+	}
+	for i, rule := range snapshot.LogAlertRules {
+		if _, err := s.logService.normalizeAlertRule(rule); err != nil {
+			return nil, fmt.Errorf("%w: %s[%d]: %w", ErrInvalidConfigSnapshot, configSectionLogAlertRules, i, err)
+		}
+	}
+	for i, contract := range snapshot.SyncContracts {
+		if _, err := normalizeContract(contract); err != nil {
+			return nil, fmt.Errorf("%w: %s[%d]: %w", ErrInvalidConfigSnapshot, configSectionSyncContracts, i, err)
+		}
+	}
+	if err := s.testService.checkScheduleLimit(len(snapshot.TestSchedules)); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfigSnapshot, configSectionTestSchedules, err)
+	}
+	for i := range snapshot.TestSchedules {
+		if _, err := s.testService.newSchedule(&snapshot.TestSchedules[i], time.Now()); err != nil {
+			return nil, fmt.Errorf("%w: %s[%d]: %w", ErrInvalidConfigSnapshot, configSectionTestSchedules, i, err)
+		}
+	}
+
+	result := &models.ConfigImportResult{Applied: []string{}}
+	if snapshot.LogFormat != "" {
+		if err := utils.GetLogger().SetFormat(snapshot.LogFormat); err != nil {
+			return nil, err
+		}
+		result.Applied = append(result.Applied, configSectionLogFormat)
+	}
+	if snapshot.LogIngestionPaused != nil {
+		paused := *snapshot.LogIngestionPaused
+		if paused != s.logService.GetIngestionState().Paused {
+			if paused {
+				s.logService.PauseIngestion(configImportPauseReason, 0)
+			} else {
+				s.logService.ResumeIngestion()
+			}
+		}
+		result.Applied = append(result.Applied, configSectionLogIngestionPaused)
+	}
+	if snapshot.LogAlertRules != nil {
+		if err := s.logService.ReplaceAlertRules(snapshot.LogAlertRules); err != nil {
+			return nil, err
+		}
+		result.Applied = append(result.Applied, configSectionLogAlertRules)
+	}
+	if snapshot.SyncContracts != nil {
+		if err := s.syncService.ReplaceContracts(snapshot.SyncContracts); err != nil {
+			return nil, err
+		}
+		result.Applied = append(result.Applied, configSectionSyncContracts)
+	}
+	if snapshot.TestSchedules != nil {
+		if err := s.testService.ReplaceSchedules(snapshot.TestSchedules); err != nil {
+			return nil, err
+		}
+		result.Applied = append(result.Applied, configSectionTestSchedules)
+	}
+
+	result.Warnings = s.featureMismatches(snapshot.Features)
+
+	s.logger.Info("Configuration snapshot imported", map[string]interface{}{
+		"version":  snapshot.Version,
+		"applied":  result.Applied,
+		"warnings": len(result.Warnings),
+	})
+	return result, nil
+}
+
+// featureMismatches describes each feature flag whose value in the snapshot differs from this
+// server's, ordered by name
+func (s *ConfigSnapshotService) featureMismatches(features map[string]bool) []string {
+	var names []string
+	for name, enabled := range features {
+		if current, exists := s.features[name]; exists && current != enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		warnings = append(warnings, fmt.Sprintf(
+			"feature %s is %t in the snapshot but %t on this server; feature flags are set by environment variables",
+			name, features[name], s.features[name]))
+	}
+	return warnings
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreLogFormat puts the global logger's format back after a test imports another one
+func restoreLogFormat(t *testing.T) {
+	format := utils.GetLogger().Format()
+	t.Cleanup(func() { utils.GetLogger().SetFormat(format) })
+}
+
+func TestConfigSnapshotService_ExportImport(t *testing.T) {
+	restoreLogFormat(t)
+	features := map[string]bool{"ai_features": true, "rate_limiting": false}
+
+	staging := NewConfigSnapshotService(NewLogService(nil, nil), NewSyncService(nil), NewTestService(&config.Config{}, nil), features)
+	require.NoError(t, staging.logService.ReplaceAlertRules([]models.LogAlertRule{
+		{Keyword: "payment declined", MinLevel: "warn"},
+	}))
+	_, err := staging.syncService.StoreContract(models.EndpointContract{
+		Name: "user", Method: "GET", Fields: map[string]string{"id": "number"},
+	})
+	require.NoError(t, err)
+	staging.logService.PauseIngestion("maintenance", 0)
+	_, err = staging.testService.CreateSchedule(scheduleRequest(models.TestScheduleOverlapQueue))
+	require.NoError(t, err)
+
+	snapshot := staging.Export()
+	assert.Equal(t, models.ConfigSnapshotVersion, snapshot.Version)
+	assert.Equal(t, features, snapshot.Features)
+	require.NotNil(t, snapshot.LogIngestionPaused)
+	assert.True(t, *snapshot.LogIngestionPaused)
+	require.Len(t, snapshot.SyncContracts, 1)
+	require.Len(t, snapshot.TestSchedules, 1)
+	snapshot.LogFormat = utils.LogFormatText
+
+	production := NewConfigSnapshotService(NewLogService(nil, nil), NewSyncService(nil), NewTestService(&config.Config{}, nil),
+		map[string]bool{"ai_features": false, "rate_limiting": false})
+	result, err := production.Import(snapshot)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"log_format", "log_ingestion_paused", "log_alert_rules", "sync_contracts", "test_schedules"}, result.Applied)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "feature ai_features is true in the snapshot but false on this server")
+
+	assert.Equal(t, utils.LogFormatText, utils.GetLogger().Format())
+	assert.True(t, production.logService.GetIngestionState().Paused)
+	assert.Equal(t, []models.LogAlertRule{{Keyword: "payment declined", MinLevel: "warn"}}, production.logService.GetAlertRules())
+	contract, err := production.syncService.GetContract("user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"id": "number"}, contract.Fields)
+	schedules := production.testService.ListSchedules()
+	require.Len(t, schedules, 1)
+	assert.Equal(t, "nightly", schedules[0].Name)
+	assert.Equal(t, models.TestScheduleOverlapQueue, schedules[0].Overlap)
+}
+
+func TestConfigSnapshotService_Import(t *testing.T) {
+	newService := func() *ConfigSnapshotService {
+		return NewConfigSnapshotService(NewLogService(nil, nil), NewSyncService(nil), NewTestService(&config.Config{}, nil), nil)
+	}
+
+	t.Run("missing sections are left unchanged", func(t *testing.T) {
+		service := newService()
+		rules := service.logService.GetAlertRules()
+
+		result, err := service.Import(&models.ServiceConfigSnapshot{Version: 1, SyncContracts: []models.EndpointContract{}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sync_contracts"}, result.Applied)
+		assert.Equal(t, rules, service.logService.GetAlertRules())
+	})
+
+	t.Run("empty lists clear a section", func(t *testing.T) {
+		service := newService()
+		require.NotEmpty(t, service.logService.GetAlertRules())
+
+		_, err := service.Import(&models.ServiceConfigSnapshot{Version: 1, LogAlertRules: []models.LogAlertRule{}})
+		require.NoError(t, err)
+		assert.Empty(t, service.logService.GetAlertRules())
+	})
+
+	t.Run("an invalid section changes nothing", func(t *testing.T) {
+		service := newService()
+		rules := service.logService.GetAlertRules()
+
+		_, err := service.Import(&models.ServiceConfigSnapshot{
+			Version:       1,
+			LogAlertRules: []models.LogAlertRule{{Keyword: "oom"}},
+			SyncContracts: []models.EndpointContract{{Name: "user", Method: "GET", Fields: map[string]string{"id": "uuid"}}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidConfigSnapshot)
+		assert.ErrorIs(t, err, ErrInvalidContract)
+		assert.Contains(t, err.Error(), "sync_contracts[0]")
+		assert.Equal(t, rules, service.logService.GetAlertRules())
+
+		_, err = service.Import(&models.ServiceConfigSnapshot{Version: 1, LogAlertRules: []models.LogAlertRule{{Keyword: "oom", MinLevel: "fatal"}}})
+		assert.ErrorIs(t, err, ErrInvalidAlertRule)
+
+		_, err = service.Import(&models.ServiceConfigSnapshot{Version: 1, TestSchedules: []models.TestScheduleRequest{
+			*scheduleRequest(""),
+			{Name: "never", Cron: "0 0 30 2 *", Run: scheduleRequest("").Run},
+		}})
+		assert.ErrorIs(t, err, ErrInvalidTestSchedule)
+		assert.Contains(t, err.Error(), "test_schedules[1]")
+		assert.Empty(t, service.testService.ListSchedules())
+	})
+
+	t.Run("snapshots from newer servers are rejected", func(t *testing.T) {
+		_, err := newService().Import(&models.ServiceConfigSnapshot{Version: models.ConfigSnapshotVersion + 1})
+		assert.ErrorIs(t, err, ErrUnsupportedConfigSnapshot)
+	})
+
+	t.Run("resuming ingestion", func(t *testing.T) {
+		service := newService()
+		service.logService.PauseIngestion("maintenance", 0)
+
+		paused := false
+		_, err := service.Import(&models.ServiceConfigSnapshot{Version: 1, LogIngestionPaused: &paused})
+		require.NoError(t, err)
+		assert.False(t, service.logService.GetIngestionState().Paused)
+	})
+}
+
+func TestLogService_ReplaceAlertRules(t *testing.T) {
+	service := NewLogService(nil, nil)
+
+	require.NoError(t, service.ReplaceAlertRules([]models.LogAlertRule{
+		{Keyword: " Timeout "},
+		{Keyword: "oom", MinLevel: "error"},
+		{Keyword: "timeout", MinLevel: "warn"},
+	}))
+	assert.Equal(t, []models.LogAlertRule{
+		{Keyword: "timeout", MinLevel: "warn"},
+		{Keyword: "oom", MinLevel: "error"},
+	}, service.GetAlertRules())
+
+	err := service.ReplaceAlertRules([]models.LogAlertRule{{Keyword: "disk"}, {Keyword: ""}})
+	assert.ErrorIs(t, err, ErrInvalidAlertRule)
+	assert.Len(t, service.GetAlertRules(), 2, "an invalid rule changes nothing")
+}
+
+func TestSyncService_ReplaceContracts(t *testing.T) {
+	service := NewSyncService(nil)
+	_, err := service.StoreContract(models.EndpointContract{Name: "old", Method: "GET", Fields: map[string]string{"id": "number"}})
+	require.NoError(t, err)
+
+	require.NoError(t, service.ReplaceContracts([]models.EndpointContract{
+		{Name: "users", Method: "GET", Fields: map[string]string{"users": "array"}},
+		{Name: "order", Method: "POST", Fields: map[string]string{" id ": "string"}},
+	}))
+	contracts := service.ListContracts()
+	require.Len(t, contracts, 2)
+	assert.Equal(t, "order", contracts[0].Name)
+	assert.Equal(t, map[string]string{"id": "string"}, contracts[0].Fields)
+	assert.Equal(t, "users", contracts[1].Name)
+	_, err = service.GetContract("old")
+	assert.ErrorIs(t, err, ErrContractNotFound)
+
+	err = service.ReplaceContracts([]models.EndpointContract{{Name: "empty", Method: "GET"}})
+	assert.ErrorIs(t, err, ErrInvalidContract)
+	assert.Len(t, service.ListContracts(), 2)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// EnvironmentStore persists connected sync environments so they survive restarts
+type EnvironmentStore interface {
+	// Save persists an environment, replacing any previous one with the same name
+	Save(env models.SyncEnvironment) error
+	// Delete removes a persisted environment; deleting an unknown one is not an error
+	Delete(name string) error
+	// List returns every persisted environment ordered by name
+	List() ([]models.SyncEnvironment, error)
+}
+
+// storedEnvironment is the persisted form of a SyncEnvironment. Only connection settings are
+// kept; health is re-checked after loading. Unlike SyncEnvironment it includes DefaultHeaders.
+type storedEnvironment struct {
+	Name           string            `json:"name"`
+	FrontendURL    string            `json:"frontend_url"`
+	BackendURL     string            `json:"backend_url"`
+	DefaultHeaders map[string]string `json:"default_headers,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+// FileEnvironmentStore keeps every environment in a single JSON file. The file may contain
+// credentials from default headers, so it is only readable by its owner.
+type FileEnvironmentStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileEnvironmentStore creates a file-backed environment store, creating the file's directory if needed
+func NewFileEnvironmentStore(path string) (*FileEnvironmentStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sync environments directory: %w", err)
+	}
+	return &FileEnvironmentStore{path: path}, nil
+}
+
+// Save adds or replaces the environment and rewrites the file
+func (f *FileEnvironmentStore) Save(env models.SyncEnvironment) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return err
+	}
+	stored[env.Name] = storedEnvironment{
+		Name:           env.Name,
+		FrontendURL:    env.FrontendURL,
+		BackendURL:     env.BackendURL,
+		DefaultHeaders: env.DefaultHeaders,
+		Metadata:       env.Metadata,
+	}
+	return f.write(stored)
+}
+
+// Delete removes the environment and rewrites the file
+func (f *FileEnvironmentStore) Delete(name string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := stored[name]; !exists {
+		return nil
+	}
+	delete(stored, name)
+	return f.write(stored)
+}
+
+// List reads the file; a missing file means no environments have been saved yet
+func (f *FileEnvironmentStore) List() ([]models.SyncEnvironment, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	environments := make([]models.SyncEnvironment, 0, len(stored))
+	for _, env := range stored {
+		environments = append(environments, models.SyncEnvironment{
+			Name:           env.Name,
+			FrontendURL:    env.FrontendURL,
+			BackendURL:     env.BackendURL,
+			DefaultHeaders: env.DefaultHeaders,
+			Metadata:       env.Metadata,
+		})
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Name < environments[j].Name
+	})
+
+	return environments, nil
+}
+
+// read decodes the file into environments keyed by name
+func (f *FileEnvironmentStore) read() (map[string]storedEnvironment, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]storedEnvironment), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync environments: %w", err)
+	}
+
+	var list []storedEnvironment
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(f.path), err)
+	}
+
+	stored := make(map[string]storedEnvironment, len(list))
+	for _, env := range list {
+		stored[env.Name] = env
+	}
+	return stored, nil
+}
+
+// write replaces the file with the given environments, ordered by name
+func (f *FileEnvironmentStore) write(stored map[string]storedEnvironment) error {
+	list := make([]storedEnvironment, 0, len(stored))
+	for _, env := range stored {
+		list = append(list, env)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync environments: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file; CreateTemp
+	// creates it with 0600 permissions
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".environments-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save sync environments: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save sync environments: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save sync environments: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to save sync environments: %w", err)
+	}
+
+	return nil
+}
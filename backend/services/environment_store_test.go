@@ -0,0 +1,76 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEnvironmentStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "environments.json")
+	store, err := NewFileEnvironmentStore(path)
+	require.NoError(t, err)
+
+	environments, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, environments, "a missing file has no environments")
+
+	require.NoError(t, store.Save(models.SyncEnvironment{
+		Name:           "staging",
+		FrontendURL:    "http://staging.example.com",
+		BackendURL:     "http://api.staging.example.com",
+		Status:         "active",
+		DefaultHeaders: map[string]string{"Authorization": "Bearer staging-token"},
+	}))
+	require.NoError(t, store.Save(models.SyncEnvironment{Name: "dev", FrontendURL: "http://localhost:3000", BackendURL: "http://localhost:8080"}))
+
+	environments, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, environments, 2)
+	assert.Equal(t, "dev", environments[0].Name)
+	assert.Equal(t, "staging", environments[1].Name)
+	assert.Equal(t, "http://api.staging.example.com", environments[1].BackendURL)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer staging-token"}, environments[1].DefaultHeaders)
+	assert.Empty(t, environments[1].Status, "health is not persisted")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "default headers may hold credentials")
+
+	t.Run("saving again replaces the environment", func(t *testing.T) {
+		require.NoError(t, store.Save(models.SyncEnvironment{Name: "dev", FrontendURL: "http://localhost:5173", BackendURL: "http://localhost:8080"}))
+
+		environments, err := store.List()
+		require.NoError(t, err)
+		require.Len(t, environments, 2)
+		assert.Equal(t, "http://localhost:5173", environments[0].FrontendURL)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete("dev"))
+		require.NoError(t, store.Delete("unknown"))
+
+		// A fresh store over the same file sees the change
+		reopened, err := NewFileEnvironmentStore(path)
+		require.NoError(t, err)
+		environments, err := reopened.List()
+		require.NoError(t, err)
+		require.Len(t, environments, 1)
+		assert.Equal(t, "staging", environments[0].Name)
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "environments.json")
+		require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0o600))
+		store, err := NewFileEnvironmentStore(corrupt)
+		require.NoError(t, err)
+
+		_, err = store.List()
+		assert.Error(t, err)
+		assert.Error(t, store.Save(models.SyncEnvironment{Name: "dev"}), "a corrupt file is not overwritten")
+	})
+}
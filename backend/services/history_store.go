@@ -0,0 +1,248 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrRunNotInHistory is returned by a HistoryStore when a run has not been persisted
+var ErrRunNotInHistory = errors.New("test run not found in history store")
+
+// HistoryStore persists completed test runs so history survives restarts
+type HistoryStore interface {
+	// Save persists a completed test run
+	Save(result models.TestResults) error
+	// Load returns a single persisted run, or ErrRunNotInHistory
+	Load(runID string) (*models.TestResults, error)
+	// List returns up to limit of the most recent runs, oldest first; limit <= 0 returns all
+	List(limit int) ([]models.TestResults, error)
+}
+
+// FileHistoryStore stores each completed test run as a JSON file in a directory. An in-memory
+// index of the persisted runs saves List and Load from rescanning the directory.
+type FileHistoryStore struct {
+	dir     string
+	maxRuns int // 0 means unlimited
+
+	mu    sync.RWMutex
+	index map[string]historyIndexEntry
+}
+
+// FileHistoryStoreConfig holds optional settings for a FileHistoryStore
+type FileHistoryStoreConfig struct {
+	// MaxRuns caps the persisted runs; once exceeded, the files of the oldest runs are deleted.
+	// 0 keeps every run.
+	MaxRuns int
+}
+
+// historyIndexEntry records when a persisted run started and ended, for ordering
+type historyIndexEntry struct {
+	startTime time.Time
+	endTime   time.Time
+}
+
+// NewFileHistoryStore creates a file-backed history store, creating dir if needed and indexing
+// the runs already in it. Runs beyond MaxRuns are deleted right away.
+func NewFileHistoryStore(dir string, config ...FileHistoryStoreConfig) (*FileHistoryStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create test history directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test history directory: %w", err)
+	}
+
+	store := &FileHistoryStore{dir: dir, index: make(map[string]historyIndexEntry, len(entries))}
+	if len(config) > 0 {
+		store.maxRuns = config[0].MaxRuns
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		result, err := readHistoryFile(filepath.Join(dir, entry.Name()))
+		if err != nil || result.RunID != strings.TrimSuffix(entry.Name(), ".json") {
+			// Skip unreadable files rather than losing the whole history
+			continue
+		}
+		store.index[result.RunID] = historyIndexEntry{startTime: result.StartTime, endTime: result.EndTime}
+	}
+	store.prune()
+
+	return store, nil
+}
+
+// Save writes the run to <dir>/<run_id>.json, replacing any previous copy, then deletes the
+// oldest runs beyond MaxRuns
+func (f *FileHistoryStore) Save(result models.TestResults) error {
+	path, err := f.pathFor(result.RunID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode test run %s: %w", result.RunID, err)
+	}
+
+	// Write to a temporary file first so readers never see a partial run
+	tmp, err := os.CreateTemp(f.dir, ".run-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save test run %s: %w", result.RunID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save test run %s: %w", result.RunID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save test run %s: %w", result.RunID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save test run %s: %w", result.RunID, err)
+	}
+
+	f.mu.Lock()
+	f.index[result.RunID] = historyIndexEntry{startTime: result.StartTime, endTime: result.EndTime}
+	f.mu.Unlock()
+	f.prune()
+
+	return nil
+}
+
+// prune drops the oldest runs beyond maxRuns from the index, then deletes their files. A failed
+// delete leaves an unindexed file behind, which is retried when the store is reopened.
+func (f *FileHistoryStore) prune() {
+	if f.maxRuns <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	var pruned []indexedRun
+	if len(f.index) > f.maxRuns {
+		runs := f.sortedRuns()
+		pruned = runs[:len(runs)-f.maxRuns]
+		for _, run := range pruned {
+			delete(f.index, run.runID)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, run := range pruned {
+		if err := os.Remove(filepath.Join(f.dir, run.runID+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to delete test run %s from history: %v", run.runID, err)
+		}
+	}
+}
+
+// Load reads a single run from disk
+func (f *FileHistoryStore) Load(runID string) (*models.TestResults, error) {
+	path, err := f.pathFor(runID)
+	if err != nil {
+		return nil, ErrRunNotInHistory
+	}
+
+	f.mu.RLock()
+	_, indexed := f.index[runID]
+	f.mu.RUnlock()
+	if !indexed {
+		return nil, ErrRunNotInHistory
+	}
+
+	result, err := readHistoryFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRunNotInHistory
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// List returns the most recent persisted runs, ordered by end time. Only the files of the runs
+// returned are read.
+func (f *FileHistoryStore) List(limit int) ([]models.TestResults, error) {
+	f.mu.RLock()
+	runs := f.sortedRuns()
+	f.mu.RUnlock()
+
+	if limit > 0 && len(runs) > limit {
+		runs = runs[len(runs)-limit:]
+	}
+
+	results := make([]models.TestResults, 0, len(runs))
+	for _, run := range runs {
+		result, err := readHistoryFile(filepath.Join(f.dir, run.runID+".json"))
+		if err != nil {
+			// Skip unreadable files rather than losing the whole history
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// indexedRun is a persisted run's index entry together with its ID
+type indexedRun struct {
+	runID string
+	historyIndexEntry
+}
+
+// sortedRuns returns the indexed runs oldest first, ordered by end time, then start time, then
+// run ID. The caller holds mu.
+func (f *FileHistoryStore) sortedRuns() []indexedRun {
+	runs := make([]indexedRun, 0, len(f.index))
+	for runID, entry := range f.index {
+		runs = append(runs, indexedRun{runID: runID, historyIndexEntry: entry})
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].endTime.Equal(runs[j].endTime) {
+			return runs[i].endTime.Before(runs[j].endTime)
+		}
+		if !runs[i].startTime.Equal(runs[j].startTime) {
+			return runs[i].startTime.Before(runs[j].startTime)
+		}
+		return runs[i].runID < runs[j].runID
+	})
+	return runs
+}
+
+// pathFor maps a run ID to its file, rejecting IDs that would escape the directory
+func (f *FileHistoryStore) pathFor(runID string) (string, error) {
+	if runID == "" || runID == "." || runID == ".." || strings.ContainsAny(runID, `/\`) {
+		return "", fmt.Errorf("invalid run ID: %q", runID)
+	}
+	return filepath.Join(f.dir, runID+".json"), nil
+}
+
+// readHistoryFile decodes a single persisted run
+func readHistoryFile(path string) (*models.TestResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.TestResults
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileHistoryStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "history")
+
+	store, err := NewFileHistoryStore(dir)
+
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestFileHistoryStore_SaveAndLoad(t *testing.T) {
+	store, err := NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	result := models.TestResults{
+		RunID:       "run-1",
+		Status:      "completed",
+		TotalTests:  2,
+		PassedTests: 1,
+		FailedTests: 1,
+		Duration:    90 * time.Second,
+		StartTime:   start,
+		EndTime:     start.Add(90 * time.Second),
+		Results: []models.TestCase{
+			{Name: "login works", Status: "passed"},
+			{Name: "checkout works", Status: "failed", ErrorMsg: "timeout"},
+		},
+	}
+
+	require.NoError(t, store.Save(result))
+
+	loaded, err := store.Load("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, result.RunID, loaded.RunID)
+	assert.Equal(t, result.Duration, loaded.Duration)
+	assert.True(t, result.EndTime.Equal(loaded.EndTime))
+	assert.Len(t, loaded.Results, 2)
+	assert.Equal(t, "timeout", loaded.Results[1].ErrorMsg)
+
+	// Saving again replaces the previous copy
+	result.Status = "failed"
+	require.NoError(t, store.Save(result))
+	loaded, err = store.Load("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", loaded.Status)
+}
+
+func TestFileHistoryStore_Load_Missing(t *testing.T) {
+	store, err := NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+
+	tests := []string{"missing", "", "..", "../etc/passwd", `..\windows`}
+	for _, runID := range tests {
+		_, err := store.Load(runID)
+		assert.ErrorIs(t, err, ErrRunNotInHistory, "run ID %q", runID)
+	}
+
+	assert.Error(t, store.Save(models.TestResults{RunID: "../escape"}))
+}
+
+func TestFileHistoryStore_List(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileHistoryStore(dir)
+	require.NoError(t, err)
+
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	// Saved out of order to check sorting by end time
+	for _, r := range []struct {
+		id     string
+		offset time.Duration
+	}{
+		{"run-b", 2 * time.Minute},
+		{"run-c", 3 * time.Minute},
+		{"run-a", 1 * time.Minute},
+	} {
+		require.NoError(t, store.Save(models.TestResults{RunID: r.id, Status: "completed", EndTime: base.Add(r.offset)}))
+	}
+
+	// Unrelated and corrupt files are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+
+	all, err := store.List(0)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"run-a", "run-b", "run-c"}, []string{all[0].RunID, all[1].RunID, all[2].RunID})
+
+	recent, err := store.List(2)
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, "run-b", recent[0].RunID)
+	assert.Equal(t, "run-c", recent[1].RunID)
+}
+
+func TestFileHistoryStore_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	service := NewTestService(createTestService().config, nil, TestServiceConfig{HistoryStore: store})
+
+	run := &TestRun{ID: "run-1", Results: &models.TestResults{RunID: "run-1", Status: "completed", EndTime: time.Now()}}
+	service.activeRuns["run-1"] = run
+	service.moveToHistory(run)
+
+	// A new service over the same directory sees the earlier run
+	reopened, err := NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	restarted := NewTestService(createTestService().config, nil, TestServiceConfig{HistoryStore: reopened})
+
+	history := restarted.GetRunHistory(10)
+	require.Len(t, history, 1)
+	assert.Equal(t, "run-1", history[0].RunID)
+
+	result, err := restarted.GetTestResults("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", result.Status)
+}
+
+func TestFileHistoryStore_Index(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileHistoryStore(dir)
+	require.NoError(t, err)
+
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(models.TestResults{RunID: "run-1", StartTime: base, EndTime: base.Add(time.Minute)}))
+	require.NoError(t, store.Save(models.TestResults{RunID: "run-2", StartTime: base, EndTime: base.Add(time.Minute)}))
+
+	// Files written behind the store's back aren't indexed until it is reopened
+	data, err := os.ReadFile(filepath.Join(dir, "run-1.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "run-0.json"), []byte(strings.Replace(string(data), "run-1", "run-0", 1)), 0o644))
+	_, err = store.Load("run-0")
+	assert.ErrorIs(t, err, ErrRunNotInHistory)
+
+	reopened, err := NewFileHistoryStore(dir)
+	require.NoError(t, err)
+	all, err := reopened.List(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"run-0", "run-1", "run-2"}, []string{all[0].RunID, all[1].RunID, all[2].RunID}, "ties are broken by run ID")
+
+	// A file removed after indexing is skipped
+	require.NoError(t, os.Remove(filepath.Join(dir, "run-1.json")))
+	all, err = reopened.List(0)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestFileHistoryStore_MaxRuns(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileHistoryStore(dir, FileHistoryStoreConfig{MaxRuns: 2})
+	require.NoError(t, err)
+
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, id := range []string{"run-1", "run-2", "run-3"} {
+		end := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.Save(models.TestResults{RunID: id, StartTime: base, EndTime: end}))
+	}
+
+	_, err = store.Load("run-1")
+	assert.ErrorIs(t, err, ErrRunNotInHistory, "the oldest run is pruned")
+	assert.NoFileExists(t, filepath.Join(dir, "run-1.json"))
+	all, err := store.List(0)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "run-2", all[0].RunID)
+	assert.Equal(t, "run-3", all[1].RunID)
+
+	t.Run("applied when reopened", func(t *testing.T) {
+		reopened, err := NewFileHistoryStore(dir, FileHistoryStoreConfig{MaxRuns: 1})
+		require.NoError(t, err)
+		all, err := reopened.List(0)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, "run-3", all[0].RunID)
+		assert.NoFileExists(t, filepath.Join(dir, "run-2.json"))
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		unlimited, err := NewFileHistoryStore(t.TempDir())
+		require.NoError(t, err)
+		for _, id := range []string{"run-1", "run-2", "run-3"} {
+			require.NoError(t, unlimited.Save(models.TestResults{RunID: id}))
+		}
+		all, err := unlimited.List(0)
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+	})
+}
@@ -4,3 +4,10 @@ package services
 type WebSocketBroadcaster interface {
 	BroadcastToAll(msgType string, data interface{})
 }
+
+// TopicBroadcaster is implemented by WebSocket hubs that can send to the clients subscribed to a
+// topic. When the hub passed to NewTestService implements it, test output is only sent to the
+// subscribers of each run's log topic.
+type TopicBroadcaster interface {
+	BroadcastToTopic(topic, msgType string, data interface{})
+}
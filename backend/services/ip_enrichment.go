@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// DefaultIPContextKeys are the log context keys checked for a client IP, in order
+var DefaultIPContextKeys = []string{"client_ip", "ip", "ip_address", "remote_addr"}
+
+// IPInfo is the geo/network information known for an IP address
+type IPInfo struct {
+	Country      string
+	Region       string
+	City         string
+	ASN          int
+	Organization string
+}
+
+// IPLookup resolves IP addresses to geo/ASN information. Lookup returns nil info when
+// the address is unknown.
+type IPLookup interface {
+	Lookup(addr netip.Addr) (*IPInfo, error)
+}
+
+// IPEnricherConfig holds the settings for log IP enrichment
+type IPEnricherConfig struct {
+	Lookup      IPLookup
+	ContextKeys []string // Context keys checked for a client IP; empty uses DefaultIPContextKeys
+	CacheSize   int      // Distinct IPs whose lookups are cached; 0 disables caching
+}
+
+// IPEnricher attaches geo/ASN information to log entries that carry a client IP
+type IPEnricher struct {
+	config IPEnricherConfig
+
+	mu         sync.Mutex
+	cache      map[netip.Addr]*IPInfo // nil values cache unknown addresses
+	cacheOrder []netip.Addr           // Oldest first, used for eviction
+}
+
+// NewIPEnricher creates an enricher backed by the given lookup
+func NewIPEnricher(config IPEnricherConfig) *IPEnricher {
+	if len(config.ContextKeys) == 0 {
+		config.ContextKeys = DefaultIPContextKeys
+	}
+	return &IPEnricher{
+		config: config,
+		cache:  make(map[netip.Addr]*IPInfo),
+	}
+}
+
+// Enrich looks up the entry's client IP and adds geo_country, geo_region, geo_city, asn and
+// asn_org to its context. Keys the entry already has are left alone, and private or
+// loopback addresses are skipped.
+func (e *IPEnricher) Enrich(entry *models.LogEntry) error {
+	addr, ok := e.clientIP(entry)
+	if !ok {
+		return nil
+	}
+
+	info, err := e.lookup(addr)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", addr, err)
+	}
+	if info == nil {
+		return nil
+	}
+
+	setContextIfEmpty(entry, "geo_country", info.Country)
+	setContextIfEmpty(entry, "geo_region", info.Region)
+	setContextIfEmpty(entry, "geo_city", info.City)
+	if info.ASN > 0 {
+		setContextIfEmpty(entry, "asn", info.ASN)
+	}
+	setContextIfEmpty(entry, "asn_org", info.Organization)
+	return nil
+}
+
+// clientIP returns the first public IP found under the configured context keys
+func (e *IPEnricher) clientIP(entry *models.LogEntry) (netip.Addr, bool) {
+	for _, key := range e.config.ContextKeys {
+		value, ok := entry.Context[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			// Accept host:port values such as remote_addr
+			addrPort, portErr := netip.ParseAddrPort(value)
+			if portErr != nil {
+				continue
+			}
+			addr = addrPort.Addr()
+		}
+		addr = addr.Unmap()
+
+		if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+			return netip.Addr{}, false
+		}
+		return addr, true
+	}
+	return netip.Addr{}, false
+}
+
+// lookup resolves an address through the cache
+func (e *IPEnricher) lookup(addr netip.Addr) (*IPInfo, error) {
+	e.mu.Lock()
+	info, cached := e.cache[addr]
+	e.mu.Unlock()
+	if cached {
+		return info, nil
+	}
+
+	info, err := e.config.Lookup.Lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.config.CacheSize > 0 {
+		e.mu.Lock()
+		if _, exists := e.cache[addr]; !exists {
+			e.cacheOrder = append(e.cacheOrder, addr)
+		}
+		e.cache[addr] = info
+		if len(e.cacheOrder) > e.config.CacheSize {
+			delete(e.cache, e.cacheOrder[0])
+			e.cacheOrder = e.cacheOrder[1:]
+		}
+		e.mu.Unlock()
+	}
+
+	return info, nil
+}
+
+// setContextIfEmpty sets a context value unless it is empty or the entry already has the key
+func setContextIfEmpty(entry *models.LogEntry, key string, value interface{}) {
+	if value == "" {
+		return
+	}
+	if entry.Context == nil {
+		entry.Context = make(map[string]interface{})
+	}
+	if _, exists := entry.Context[key]; !exists {
+		entry.Context[key] = value
+	}
+}
+
+// cidrRange is one network of a CIDRLookup
+type cidrRange struct {
+	prefix netip.Prefix
+	info   IPInfo
+}
+
+// CIDRLookup resolves IPs against a fixed list of networks, preferring the most specific match
+type CIDRLookup struct {
+	ranges []cidrRange // Longest prefixes first
+}
+
+// LoadCIDRLookup reads networks from a CSV file with one network per line:
+//
+//	cidr,country,region,city,asn,organization
+//
+// Blank lines and lines starting with # are ignored; trailing columns may be omitted.
+func LoadCIDRLookup(path string) (*CIDRLookup, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IP database: %w", err)
+	}
+	defer file.Close()
+
+	lookup := &CIDRLookup{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		for len(fields) < 6 {
+			fields = append(fields, "")
+		}
+
+		prefix, err := netip.ParsePrefix(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("IP database line %d: invalid network %q", lineNumber, fields[0])
+		}
+
+		var asn int
+		if fields[4] != "" {
+			asn, err = strconv.Atoi(strings.TrimPrefix(strings.ToUpper(fields[4]), "AS"))
+			if err != nil || asn < 0 {
+				return nil, fmt.Errorf("IP database line %d: invalid ASN %q", lineNumber, fields[4])
+			}
+		}
+
+		lookup.ranges = append(lookup.ranges, cidrRange{
+			prefix: prefix.Masked(),
+			info: IPInfo{
+				Country:      fields[1],
+				Region:       fields[2],
+				City:         fields[3],
+				ASN:          asn,
+				Organization: fields[5],
+			},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IP database: %w", err)
+	}
+
+	sort.SliceStable(lookup.ranges, func(i, j int) bool {
+		return lookup.ranges[i].prefix.Bits() > lookup.ranges[j].prefix.Bits()
+	})
+	return lookup, nil
+}
+
+// Lookup returns the most specific network containing addr, or nil if none does
+func (l *CIDRLookup) Lookup(addr netip.Addr) (*IPInfo, error) {
+	for _, r := range l.ranges {
+		if r.prefix.Contains(addr) {
+			info := r.info
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
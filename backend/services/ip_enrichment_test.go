@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingIPLookup records how often each address is looked up
+type countingIPLookup struct {
+	info  map[string]*IPInfo
+	calls map[string]int
+	err   error
+}
+
+func (l *countingIPLookup) Lookup(addr netip.Addr) (*IPInfo, error) {
+	if l.calls == nil {
+		l.calls = make(map[string]int)
+	}
+	l.calls[addr.String()]++
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.info[addr.String()], nil
+}
+
+func TestIPEnricher_Enrich(t *testing.T) {
+	lookup := &countingIPLookup{info: map[string]*IPInfo{
+		"203.0.113.7": {Country: "DE", Region: "Berlin", City: "Berlin", ASN: 3320, Organization: "Deutsche Telekom AG"},
+	}}
+	enricher := NewIPEnricher(IPEnricherConfig{Lookup: lookup, CacheSize: 10})
+
+	t.Run("adds geo and ASN context", func(t *testing.T) {
+		entry := models.LogEntry{Context: map[string]interface{}{"client_ip": "203.0.113.7"}}
+		require.NoError(t, enricher.Enrich(&entry))
+
+		assert.Equal(t, "DE", entry.Context["geo_country"])
+		assert.Equal(t, "Berlin", entry.Context["geo_region"])
+		assert.Equal(t, "Berlin", entry.Context["geo_city"])
+		assert.Equal(t, 3320, entry.Context["asn"])
+		assert.Equal(t, "Deutsche Telekom AG", entry.Context["asn_org"])
+	})
+
+	t.Run("lookups are cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			entry := models.LogEntry{Context: map[string]interface{}{"ip": "203.0.113.7"}}
+			require.NoError(t, enricher.Enrich(&entry))
+		}
+		assert.Equal(t, 1, lookup.calls["203.0.113.7"])
+
+		// Unknown addresses are cached too
+		for i := 0; i < 2; i++ {
+			entry := models.LogEntry{Context: map[string]interface{}{"ip": "198.51.100.1"}}
+			require.NoError(t, enricher.Enrich(&entry))
+			assert.NotContains(t, entry.Context, "geo_country")
+		}
+		assert.Equal(t, 1, lookup.calls["198.51.100.1"])
+	})
+
+	t.Run("host:port values", func(t *testing.T) {
+		entry := models.LogEntry{Context: map[string]interface{}{"remote_addr": "203.0.113.7:54321"}}
+		require.NoError(t, enricher.Enrich(&entry))
+		assert.Equal(t, "DE", entry.Context["geo_country"])
+	})
+
+	t.Run("existing keys are kept", func(t *testing.T) {
+		entry := models.LogEntry{Context: map[string]interface{}{"client_ip": "203.0.113.7", "geo_country": "FR"}}
+		require.NoError(t, enricher.Enrich(&entry))
+		assert.Equal(t, "FR", entry.Context["geo_country"])
+		assert.Equal(t, "Berlin", entry.Context["geo_city"])
+	})
+
+	t.Run("private and invalid addresses are skipped", func(t *testing.T) {
+		for _, ip := range []interface{}{"10.0.0.4", "127.0.0.1", "::1", "not-an-ip", 42} {
+			entry := models.LogEntry{Context: map[string]interface{}{"client_ip": ip}}
+			require.NoError(t, enricher.Enrich(&entry))
+			assert.NotContains(t, entry.Context, "geo_country")
+		}
+		assert.NotContains(t, lookup.calls, "10.0.0.4")
+		assert.NotContains(t, lookup.calls, "127.0.0.1")
+	})
+
+	t.Run("entries without an IP are untouched", func(t *testing.T) {
+		entry := models.LogEntry{Message: "no context"}
+		require.NoError(t, enricher.Enrich(&entry))
+		assert.Nil(t, entry.Context)
+	})
+}
+
+func TestIPEnricher_CacheIsBounded(t *testing.T) {
+	lookup := &countingIPLookup{}
+	enricher := NewIPEnricher(IPEnricherConfig{Lookup: lookup, CacheSize: 2})
+
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3", "198.51.100.1"} {
+		entry := models.LogEntry{Context: map[string]interface{}{"client_ip": ip}}
+		require.NoError(t, enricher.Enrich(&entry))
+	}
+
+	assert.Len(t, enricher.cache, 2)
+	// The first address was evicted and had to be looked up again
+	assert.Equal(t, 2, lookup.calls["198.51.100.1"])
+}
+
+func TestLoadCIDRLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "networks.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`# cidr,country,region,city,asn,organization
+203.0.113.0/24,DE,Berlin,Berlin,AS3320,Deutsche Telekom AG
+203.0.113.128/25,DE,Hamburg,Hamburg,3320,Deutsche Telekom AG
+
+2001:db8::/32,US
+`), 0o644))
+
+	lookup, err := LoadCIDRLookup(path)
+	require.NoError(t, err)
+
+	info, err := lookup.Lookup(netip.MustParseAddr("203.0.113.7"))
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "Berlin", info.City)
+	assert.Equal(t, 3320, info.ASN)
+
+	// The most specific network wins
+	info, err = lookup.Lookup(netip.MustParseAddr("203.0.113.200"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hamburg", info.City)
+
+	info, err = lookup.Lookup(netip.MustParseAddr("2001:db8::1"))
+	require.NoError(t, err)
+	assert.Equal(t, IPInfo{Country: "US"}, *info)
+
+	info, err = lookup.Lookup(netip.MustParseAddr("198.51.100.1"))
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	t.Run("invalid lines are rejected", func(t *testing.T) {
+		for _, content := range []string{"203.0.113.0/33,DE\n", "203.0.113.0/24,DE,,,ASX\n"} {
+			bad := filepath.Join(t.TempDir(), "bad.csv")
+			require.NoError(t, os.WriteFile(bad, []byte(content), 0o644))
+			_, err := LoadCIDRLookup(bad)
+			assert.ErrorContains(t, err, "line 1")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadCIDRLookup(filepath.Join(t.TempDir(), "missing.csv"))
+		assert.Error(t, err)
+	})
+}
+
+func TestLogService_SubmitLogs_IPEnrichment(t *testing.T) {
+	lookup := &countingIPLookup{info: map[string]*IPInfo{
+		"203.0.113.7": {Country: "DE", ASN: 3320},
+	}}
+	config := DefaultLogServiceConfig()
+	config.IPEnricher = NewIPEnricher(IPEnricherConfig{Lookup: lookup, CacheSize: 10})
+	service := NewLogService(nil, nil, config)
+
+	response, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "frontend",
+		Logs: []models.LogEntry{
+			{Level: "info", Source: "frontend", Message: "Page loaded", Context: map[string]interface{}{"client_ip": "203.0.113.7"}},
+			{Level: "info", Source: "frontend", Message: "No IP"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Accepted)
+
+	filtered, _, err := service.filterLogs(&models.LogAnalysisRequest{Filters: map[string]string{"geo_country": "DE", "asn": "3320"}})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Page loaded", filtered[0].Message)
+
+	t.Run("lookup failures still store the entry", func(t *testing.T) {
+		config.IPEnricher = NewIPEnricher(IPEnricherConfig{Lookup: &countingIPLookup{err: errors.New("lookup unavailable")}})
+		failing := NewLogService(nil, nil, config)
+
+		response, err := failing.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+			Source: "frontend",
+			Logs: []models.LogEntry{
+				{Level: "info", Source: "frontend", Message: "Page loaded", Context: map[string]interface{}{"client_ip": "203.0.113.7"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Accepted)
+		assert.Equal(t, 1, failing.GetLogCount())
+	})
+}
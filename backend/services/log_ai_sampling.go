@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// DefaultMaxAILogs is how many logs an AI analysis is sent when MaxAILogs is unset
+const DefaultMaxAILogs = 20
+
+// aiLogCluster is the logs of one error fingerprint, or of one distinct message for logs without
+// a fingerprint
+type aiLogCluster struct {
+	entries []int // Indexes into the sampled logs, most severe first
+	rank    int   // Severity rank of the most severe entry
+}
+
+// sampleAILogs picks up to MaxAILogs logs to send to the AI. Rather than the first logs, it takes
+// one representative per cluster, most severe clusters first and more frequent ones breaking ties.
+// Repeats of a cluster only fill slots left over once every cluster is represented. The sample
+// keeps the logs' original order, and representatives of clusters with several logs get an
+// "occurrences" context entry so the AI still sees how often an error happened.
+func (s *LogService) sampleAILogs(logs []models.LogEntry) []models.LogEntry {
+	limit := s.config.MaxAILogs
+	if limit <= 0 {
+		limit = DefaultMaxAILogs
+	}
+	if len(logs) <= limit {
+		return logs
+	}
+
+	clusters := make(map[string]*aiLogCluster)
+	var order []*aiLogCluster
+	for i := range logs {
+		key := logs[i].Fingerprint
+		if key == "" {
+			key = logs[i].Level + "\x00" + s.fingerprint(&logs[i])
+		}
+		cluster, exists := clusters[key]
+		if !exists {
+			cluster = &aiLogCluster{rank: len(s.levelRank)}
+			clusters[key] = cluster
+			order = append(order, cluster)
+		}
+		cluster.entries = append(cluster.entries, i)
+	}
+
+	for _, cluster := range order {
+		sort.SliceStable(cluster.entries, func(a, b int) bool {
+			return s.severityRank(logs[cluster.entries[a]].Level) < s.severityRank(logs[cluster.entries[b]].Level)
+		})
+		cluster.rank = s.severityRank(logs[cluster.entries[0]].Level)
+	}
+	// order starts out by first appearance, which stays the final tie-breaker
+	sort.SliceStable(order, func(a, b int) bool {
+		if order[a].rank != order[b].rank {
+			return order[a].rank < order[b].rank
+		}
+		return len(order[a].entries) > len(order[b].entries)
+	})
+
+	representatives := make(map[int]int, len(order)) // Log index to its cluster's size
+	selected := make([]int, 0, limit)
+	for round := 0; len(selected) < limit; round++ {
+		for _, cluster := range order {
+			if round >= len(cluster.entries) || len(selected) == limit {
+				continue
+			}
+			index := cluster.entries[round]
+			if round == 0 {
+				representatives[index] = len(cluster.entries)
+			}
+			selected = append(selected, index)
+		}
+	}
+	sort.Ints(selected)
+
+	sample := make([]models.LogEntry, len(selected))
+	for i, index := range selected {
+		sample[i] = logs[index]
+		if occurrences := representatives[index]; occurrences > 1 {
+			context := make(map[string]interface{}, len(sample[i].Context)+1)
+			for key, value := range sample[i].Context {
+				context[key] = value
+			}
+			context["occurrences"] = occurrences
+			sample[i].Context = context
+		}
+	}
+	return sample
+}
+
+// severityRank returns the rank of level, 0 being most severe. Unknown levels rank below every
+// configured one.
+func (s *LogService) severityRank(level string) int {
+	if rank, exists := s.levelRank[level]; exists {
+		return rank
+	}
+	return len(s.levelRank)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogService_SampleAILogs(t *testing.T) {
+	base := time.Now()
+	entry := func(i int, level, message string) models.LogEntry {
+		return models.LogEntry{
+			ID:        fmt.Sprintf("log-%d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Level:     level,
+			Source:    "backend",
+			Message:   message,
+		}
+	}
+
+	t.Run("one representative per cluster, most severe first", func(t *testing.T) {
+		cfg := DefaultLogServiceConfig()
+		cfg.MaxAILogs = 3
+		service := NewLogService(nil, nil, cfg)
+
+		var logs []models.LogEntry
+		for i := 0; i < 10; i++ {
+			logs = append(logs, entry(i, "info", fmt.Sprintf("request %d served", i)))
+		}
+		logs = append(logs,
+			entry(10, "warn", "slow query"),
+			entry(11, "error", "database connection refused"),
+			entry(12, "error", "database connection refused"),
+			entry(13, "debug", "cache miss"),
+		)
+
+		sample := service.sampleAILogs(logs)
+		require.Len(t, sample, 3)
+		// Chronological order is kept, and the repeated info message counts as one cluster
+		assert.Equal(t, "log-0", sample[0].ID)
+		assert.Equal(t, 10, sample[0].Context["occurrences"])
+		assert.Equal(t, "log-10", sample[1].ID)
+		assert.NotContains(t, sample[1].Context, "occurrences")
+		assert.Equal(t, "log-11", sample[2].ID)
+		assert.Equal(t, 2, sample[2].Context["occurrences"])
+		assert.Nil(t, logs[11].Context, "the stored logs are not annotated")
+	})
+
+	t.Run("fingerprints cluster differently worded messages", func(t *testing.T) {
+		cfg := DefaultLogServiceConfig()
+		cfg.MaxAILogs = 2
+		service := NewLogService(nil, nil, cfg)
+
+		logs := []models.LogEntry{entry(0, "error", "timeout A"), entry(1, "error", "timeout B"), entry(2, "error", "oom")}
+		logs[0].Fingerprint = "aaaa"
+		logs[1].Fingerprint = "aaaa"
+		logs[2].Fingerprint = "bbbb"
+
+		sample := service.sampleAILogs(logs)
+		require.Len(t, sample, 2)
+		assert.Equal(t, "log-0", sample[0].ID)
+		assert.Equal(t, "log-2", sample[1].ID)
+	})
+
+	t.Run("repeats fill slots left after every cluster is represented", func(t *testing.T) {
+		cfg := DefaultLogServiceConfig()
+		cfg.MaxAILogs = 4
+		service := NewLogService(nil, nil, cfg)
+
+		logs := []models.LogEntry{
+			entry(0, "info", "user logged in"),
+			entry(1, "info", "user logged in"),
+			entry(2, "info", "user logged in"),
+			entry(3, "error", "payment failed"),
+			entry(4, "error", "payment failed"),
+			entry(5, "error", "payment failed"),
+		}
+
+		sample := service.sampleAILogs(logs)
+		ids := make([]string, len(sample))
+		for i, log := range sample {
+			ids[i] = log.ID
+		}
+		assert.Equal(t, []string{"log-0", "log-1", "log-3", "log-4"}, ids)
+	})
+
+	t.Run("small batches are sent as is", func(t *testing.T) {
+		service := NewLogService(nil, nil)
+		logs := make([]models.LogEntry, DefaultMaxAILogs)
+		for i := range logs {
+			logs[i] = entry(i, "error", "same error")
+		}
+		assert.Equal(t, logs, service.sampleAILogs(logs))
+	})
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// Critical alert tracking limits
+const (
+	maxTrackedAlerts       = 1000             // Critical alerts kept for acknowledgement, oldest evicted first
+	alertEscalationTimeout = 10 * time.Second // Bounds one call to the AlertEscalator
+)
+
+// ErrAlertNotFound is returned when acknowledging an alert that was never raised or has been evicted
+var ErrAlertNotFound = errors.New("alert not found")
+
+// ErrAlertAlreadyAcknowledged is returned when acknowledging an alert a second time
+var ErrAlertAlreadyAcknowledged = errors.New("alert already acknowledged")
+
+// AlertEscalator notifies a higher-severity channel, such as a pager, about a critical log_alert
+// nobody acknowledged within AlertEscalationAfter
+type AlertEscalator interface {
+	Escalate(ctx context.Context, alert map[string]interface{}) error
+}
+
+// WebhookAlertEscalator escalates alerts by POSTing them as JSON to a URL
+type WebhookAlertEscalator struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlertEscalator returns an escalator posting to url
+func NewWebhookAlertEscalator(url string) *WebhookAlertEscalator {
+	return &WebhookAlertEscalator{
+		url:    url,
+		client: &http.Client{Timeout: alertEscalationTimeout},
+	}
+}
+
+// Escalate posts alert to the webhook, failing unless it answers with a 2xx status
+func (w *WebhookAlertEscalator) Escalate(ctx context.Context, alert map[string]interface{}) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create escalation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("escalation webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("escalation webhook answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// trackedAlert is a critical alert awaiting acknowledgement
+type trackedAlert struct {
+	status models.CriticalLogAlert
+	event  map[string]interface{} // The log_alert event as broadcast
+	timer  *time.Timer            // Fires the escalation; nil when escalation is disabled
+}
+
+// trackCriticalAlert records a critical alert so it can be acknowledged. When AlertEscalationAfter
+// is set it starts the escalation timer and adds escalates_at to event, which is then read-only.
+func (s *LogService) trackCriticalAlert(alertID, logID string, event map[string]interface{}) {
+	now := time.Now()
+	tracked := &trackedAlert{
+		status: models.CriticalLogAlert{ID: alertID, LogID: logID, FiredAt: now},
+		event:  event,
+	}
+
+	s.criticalAlertsMu.Lock()
+	defer s.criticalAlertsMu.Unlock()
+
+	if after := s.config.AlertEscalationAfter; after > 0 {
+		escalatesAt := now.Add(after)
+		tracked.status.EscalatesAt = &escalatesAt
+		event["escalates_at"] = escalatesAt
+		tracked.timer = time.AfterFunc(after, func() { s.escalateAlert(alertID) })
+	}
+
+	s.criticalAlerts[alertID] = tracked
+	s.criticalAlertOrder = append(s.criticalAlertOrder, alertID)
+	for len(s.criticalAlertOrder) > maxTrackedAlerts {
+		evicted := s.criticalAlertOrder[0]
+		s.criticalAlertOrder = s.criticalAlertOrder[1:]
+		if old, exists := s.criticalAlerts[evicted]; exists && old.timer != nil {
+			old.timer.Stop()
+		}
+		delete(s.criticalAlerts, evicted)
+	}
+}
+
+// AcknowledgeAlert marks a critical alert as handled, so it doesn't escalate. A log_alert event
+// of type critical_log_acknowledged tells other clients someone is on it.
+func (s *LogService) AcknowledgeAlert(alertID, acknowledgedBy string) (*models.CriticalLogAlert, error) {
+	s.criticalAlertsMu.Lock()
+	tracked, exists := s.criticalAlerts[alertID]
+	if !exists {
+		s.criticalAlertsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAlertNotFound, alertID)
+	}
+	if tracked.status.AcknowledgedAt != nil {
+		s.criticalAlertsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAlertAlreadyAcknowledged, alertID)
+	}
+
+	if tracked.timer != nil {
+		tracked.timer.Stop()
+	}
+	now := time.Now()
+	tracked.status.AcknowledgedAt = &now
+	tracked.status.AcknowledgedBy = acknowledgedBy
+	status := tracked.status
+	s.criticalAlertsMu.Unlock()
+
+	s.logger.Info("Critical log alert acknowledged", map[string]interface{}{
+		"alert_id":        alertID,
+		"log_id":          status.LogID,
+		"acknowledged_by": acknowledgedBy,
+		"escalated":       status.EscalatedAt != nil,
+	})
+
+	if s.wsHub != nil {
+		event := map[string]interface{}{
+			"type":            "critical_log_acknowledged",
+			"alert_id":        alertID,
+			"log_id":          status.LogID,
+			"acknowledged_at": now,
+		}
+		if acknowledgedBy != "" {
+			event["acknowledged_by"] = acknowledgedBy
+		}
+		s.wsHub.BroadcastToAll("log_alert", event)
+	}
+
+	return &status, nil
+}
+
+// escalateAlert re-sends a critical alert nobody acknowledged, as a critical_log_escalation
+// log_alert event and through the AlertEscalator when one is configured
+func (s *LogService) escalateAlert(alertID string) {
+	s.criticalAlertsMu.Lock()
+	tracked, exists := s.criticalAlerts[alertID]
+	if !exists || tracked.status.AcknowledgedAt != nil || tracked.status.EscalatedAt != nil {
+		s.criticalAlertsMu.Unlock()
+		return
+	}
+	now := time.Now()
+	tracked.status.EscalatedAt = &now
+
+	event := make(map[string]interface{}, len(tracked.event)+3)
+	for key, value := range tracked.event {
+		event[key] = value
+	}
+	event["type"] = "critical_log_escalation"
+	event["fired_at"] = tracked.status.FiredAt
+	event["unacknowledged_for"] = now.Sub(tracked.status.FiredAt).Round(time.Second).String()
+	s.criticalAlertsMu.Unlock()
+
+	s.logger.Warn("Critical log alert unacknowledged, escalating", map[string]interface{}{
+		"alert_id": alertID,
+		"log_id":   event["log_id"],
+	})
+
+	if s.wsHub != nil {
+		s.wsHub.BroadcastToAll("log_alert", event)
+	}
+
+	if s.config.AlertEscalator == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), alertEscalationTimeout)
+	defer cancel()
+	if err := s.config.AlertEscalator.Escalate(ctx, event); err != nil {
+		s.logger.Error("Failed to escalate critical log alert", err, map[string]interface{}{
+			"alert_id": alertID,
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedAlerts collects the log_alert events broadcast to a MockWebSocketHub
+type recordedAlerts struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func newAlertRecordingHub() (*MockWebSocketHub, *recordedAlerts) {
+	recorded := &recordedAlerts{}
+	hub := &MockWebSocketHub{}
+	hub.On("BroadcastToAll", "log_alert", mock.Anything).Run(func(args mock.Arguments) {
+		recorded.mu.Lock()
+		defer recorded.mu.Unlock()
+		recorded.events = append(recorded.events, args.Get(1).(map[string]interface{}))
+	}).Return()
+	hub.On("BroadcastToAll", mock.Anything, mock.Anything).Return()
+	return hub, recorded
+}
+
+// ofType returns the recorded events of the given type
+func (r *recordedAlerts) ofType(eventType string) []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []map[string]interface{}
+	for _, event := range r.events {
+		if event["type"] == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// escalatorFunc adapts a function to AlertEscalator
+type escalatorFunc func(ctx context.Context, alert map[string]interface{}) error
+
+func (f escalatorFunc) Escalate(ctx context.Context, alert map[string]interface{}) error {
+	return f(ctx, alert)
+}
+
+func submitCriticalLog(t *testing.T, service *LogService, message string) {
+	t.Helper()
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs:   []models.LogEntry{{Level: "error", Source: "backend", Message: message}},
+	})
+	require.NoError(t, err)
+}
+
+func TestLogService_AlertEscalation(t *testing.T) {
+	t.Run("unacknowledged alerts escalate once", func(t *testing.T) {
+		hub, recorded := newAlertRecordingHub()
+		escalated := make(chan map[string]interface{}, 2)
+		cfg := DefaultLogServiceConfig()
+		cfg.AlertEscalationAfter = 20 * time.Millisecond
+		cfg.AlertEscalator = escalatorFunc(func(ctx context.Context, alert map[string]interface{}) error {
+			escalated <- alert
+			return nil
+		})
+		service := NewLogService(nil, hub, cfg)
+
+		submitCriticalLog(t, service, "panic: nil map")
+		fired := recorded.ofType("critical_log_event")
+		require.Len(t, fired, 1)
+		alertID := fired[0]["alert_id"].(string)
+		assert.NotEmpty(t, alertID)
+		assert.Contains(t, fired[0], "escalates_at")
+
+		select {
+		case alert := <-escalated:
+			assert.Equal(t, "critical_log_escalation", alert["type"])
+			assert.Equal(t, alertID, alert["alert_id"])
+			assert.Equal(t, "panic: nil map", alert["message"])
+		case <-time.After(time.Second):
+			t.Fatal("alert was not escalated")
+		}
+		assert.Len(t, recorded.ofType("critical_log_escalation"), 1)
+
+		// Acknowledging after the escalation still records who picked it up
+		alert, err := service.AcknowledgeAlert(alertID, "alice")
+		require.NoError(t, err)
+		assert.NotNil(t, alert.EscalatedAt)
+		assert.Equal(t, "alice", alert.AcknowledgedBy)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, escalated, "an alert escalates once")
+	})
+
+	t.Run("acknowledged alerts don't escalate", func(t *testing.T) {
+		hub, recorded := newAlertRecordingHub()
+		cfg := DefaultLogServiceConfig()
+		cfg.AlertEscalationAfter = 30 * time.Millisecond
+		service := NewLogService(nil, hub, cfg)
+
+		submitCriticalLog(t, service, "fatal: disk full")
+		alertID := recorded.ofType("critical_log_event")[0]["alert_id"].(string)
+
+		alert, err := service.AcknowledgeAlert(alertID, "")
+		require.NoError(t, err)
+		assert.Equal(t, alertID, alert.ID)
+		assert.NotNil(t, alert.AcknowledgedAt)
+		assert.Nil(t, alert.EscalatedAt)
+		require.Len(t, recorded.ofType("critical_log_acknowledged"), 1)
+
+		_, err = service.AcknowledgeAlert(alertID, "bob")
+		assert.ErrorIs(t, err, ErrAlertAlreadyAcknowledged)
+
+		time.Sleep(60 * time.Millisecond)
+		assert.Empty(t, recorded.ofType("critical_log_escalation"))
+	})
+
+	t.Run("escalation disabled", func(t *testing.T) {
+		hub, recorded := newAlertRecordingHub()
+		service := NewLogService(nil, hub)
+
+		submitCriticalLog(t, service, "security breach detected")
+		fired := recorded.ofType("critical_log_event")
+		require.Len(t, fired, 1)
+		assert.NotContains(t, fired[0], "escalates_at")
+
+		alert, err := service.AcknowledgeAlert(fired[0]["alert_id"].(string), "alice")
+		require.NoError(t, err)
+		assert.Nil(t, alert.EscalatesAt)
+	})
+
+	t.Run("unknown alert", func(t *testing.T) {
+		service := NewLogService(nil, nil)
+		_, err := service.AcknowledgeAlert("missing", "")
+		assert.ErrorIs(t, err, ErrAlertNotFound)
+	})
+}
+
+func TestWebhookAlertEscalator(t *testing.T) {
+	var received map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	escalator := NewWebhookAlertEscalator(server.URL)
+	alert := map[string]interface{}{"type": "critical_log_escalation", "alert_id": "alert-1"}
+	require.NoError(t, escalator.Escalate(context.Background(), alert))
+	assert.Equal(t, "alert-1", received["alert_id"])
+
+	status = http.StatusBadGateway
+	err := escalator.Escalate(context.Background(), alert)
+	assert.EqualError(t, err, "escalation webhook answered 502")
+
+	err = NewWebhookAlertEscalator("http://127.0.0.1:1").Escalate(context.Background(), alert)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "escalation webhook failed")
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// Spike settings used when AutoAnalysisThreshold or AutoAnalysisWindow is unset
+const (
+	defaultAutoAnalysisThreshold = 20
+	maxAutoAnalysisSample        = 20 // Occurrences of a spiking error sent to the AI service
+)
+
+// LogAnalysisQueue is implemented by AI services that run log analyses in the background. When the
+// AI service passed to NewLogService implements it, error spikes are analysed automatically.
+type LogAnalysisQueue interface {
+	QueueLogAnalysis(key string, req *models.AILogAnalysisRequest, done func(*models.AILogAnalysisResponse, error)) error
+}
+
+// errorSpike tracks the recent occurrences of one error fingerprint
+type errorSpike struct {
+	arrivals []time.Time       // When each occurrence within the window was submitted, oldest first
+	sample   []models.LogEntry // Up to maxAutoAnalysisSample of those occurrences
+}
+
+// trackErrorSpikes records the fingerprinted entries of a submitted batch and queues an AI analysis
+// for every fingerprint reaching AutoAnalysisThreshold occurrences within AutoAnalysisWindow. The
+// caller holds s.mu.
+func (s *LogService) trackErrorSpikes(ctx context.Context, entries []models.LogEntry) {
+	if !s.config.AutoAnalysis || s.aiService == nil {
+		return
+	}
+	queue, ok := s.aiService.(LogAnalysisQueue)
+	if !ok {
+		return
+	}
+
+	threshold, window := s.autoAnalysisSettings()
+	now := time.Now()
+	for fingerprint, spike := range s.spikes {
+		if len(spike.arrivals) == 0 || now.Sub(spike.arrivals[len(spike.arrivals)-1]) >= window {
+			delete(s.spikes, fingerprint)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Fingerprint == "" {
+			continue
+		}
+
+		spike, exists := s.spikes[entry.Fingerprint]
+		if !exists {
+			spike = &errorSpike{}
+			s.spikes[entry.Fingerprint] = spike
+		}
+		spike.arrivals = append(spike.arrivals, now)
+		for len(spike.arrivals) > 0 && now.Sub(spike.arrivals[0]) >= window {
+			spike.arrivals = spike.arrivals[1:]
+		}
+		if len(spike.sample) < maxAutoAnalysisSample {
+			spike.sample = append(spike.sample, entry)
+		}
+
+		if len(spike.arrivals) >= threshold {
+			delete(s.spikes, entry.Fingerprint)
+			s.queueSpikeAnalysis(ctx, queue, entry.Fingerprint, spike, window)
+		}
+	}
+}
+
+// queueSpikeAnalysis queues the analysis of a spiking error. The result is broadcast as a
+// log_auto_analysis WebSocket event.
+func (s *LogService) queueSpikeAnalysis(ctx context.Context, queue LogAnalysisQueue, fingerprint string, spike *errorSpike, window time.Duration) {
+	if !s.aiService.IsAvailable() {
+		return
+	}
+
+	occurrences := len(spike.arrivals)
+	req := &models.AILogAnalysisRequest{
+		Logs: spike.sample,
+		TimeRange: models.TimeRange{
+			Start: spike.arrivals[0],
+			End:   spike.arrivals[occurrences-1],
+		},
+		Filters:      map[string]string{"fingerprint": fingerprint},
+		AnalysisType: "error_detection",
+	}
+
+	traceID := ""
+	if s.config.PropagateTraceID {
+		traceID = utils.TraceIDFromContext(ctx)
+	}
+
+	err := queue.QueueLogAnalysis(fingerprint, req, func(response *models.AILogAnalysisResponse, err error) {
+		s.broadcastSpikeAnalysis(fingerprint, occurrences, window, traceID, response, err)
+	})
+	switch {
+	case errors.Is(err, ErrAutoAnalysisDuplicate):
+		s.logger.Debug("Error spike already queued for analysis", map[string]interface{}{
+			"fingerprint": fingerprint,
+		})
+	case err != nil:
+		s.logger.Warn("Error spike analysis not queued", map[string]interface{}{
+			"fingerprint": fingerprint,
+			"occurrences": occurrences,
+			"error":       err.Error(),
+		})
+	default:
+		s.logger.Info("Error spike queued for analysis", map[string]interface{}{
+			"fingerprint": fingerprint,
+			"occurrences": occurrences,
+		})
+	}
+}
+
+// broadcastSpikeAnalysis sends the result of an error spike analysis to every WebSocket client
+func (s *LogService) broadcastSpikeAnalysis(fingerprint string, occurrences int, window time.Duration, traceID string,
+	response *models.AILogAnalysisResponse, err error) {
+	if s.wsHub == nil {
+		return
+	}
+
+	event := map[string]interface{}{
+		"type":        "error_spike_analysis",
+		"fingerprint": fingerprint,
+		"occurrences": occurrences,
+		"window":      window.String(),
+		"timestamp":   time.Now(),
+	}
+	if err != nil {
+		event["error"] = err.Error()
+	} else if response != nil {
+		event["summary"] = response.Summary
+		event["issues"] = response.Issues
+		event["suggestions"] = response.Suggestions
+	}
+	if traceID != "" {
+		event["trace_id"] = traceID
+	}
+
+	s.wsHub.BroadcastToAll("log_auto_analysis", event)
+}
+
+// autoAnalysisSettings returns the spike threshold and window, defaulting unset values
+func (s *LogService) autoAnalysisSettings() (int, time.Duration) {
+	threshold := s.config.AutoAnalysisThreshold
+	if threshold <= 0 {
+		threshold = defaultAutoAnalysisThreshold
+	}
+	window := s.config.AutoAnalysisWindow
+	if window <= 0 {
+		window = defaultAutoAnalysisWindow
+	}
+	return threshold, window
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// queueingAIService is an AI service recording the log analyses queued on it
+type queueingAIService struct {
+	MockAIService
+	mu     sync.Mutex
+	keys   []string
+	reqs   []*models.AILogAnalysisRequest
+	dones  []func(*models.AILogAnalysisResponse, error)
+	queued map[string]bool
+}
+
+func (q *queueingAIService) QueueLogAnalysis(key string, req *models.AILogAnalysisRequest, done func(*models.AILogAnalysisResponse, error)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.queued[key] {
+		return ErrAutoAnalysisDuplicate
+	}
+	if q.queued == nil {
+		q.queued = make(map[string]bool)
+	}
+	q.queued[key] = true
+	q.keys = append(q.keys, key)
+	q.reqs = append(q.reqs, req)
+	q.dones = append(q.dones, done)
+	return nil
+}
+
+func TestLogService_AutoAnalysis(t *testing.T) {
+	newService := func(ai AIServiceInterface, hub WebSocketBroadcaster, enabled bool) *LogService {
+		return NewLogService(ai, hub, LogServiceConfig{
+			Levels:                []string{"error", "warn", "info"},
+			ErrorLevel:            "error",
+			Fingerprints:          true,
+			AutoAnalysis:          enabled,
+			AutoAnalysisThreshold: 3,
+			AutoAnalysisWindow:    time.Minute,
+		})
+	}
+	submit := func(t *testing.T, service *LogService, messages ...string) {
+		logs := make([]models.LogEntry, len(messages))
+		for i, message := range messages {
+			logs[i] = models.LogEntry{Level: "error", Message: message, Source: "backend", Component: "orders"}
+		}
+		_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{Logs: logs, Source: "backend"})
+		require.NoError(t, err)
+	}
+
+	t.Run("spike queues one analysis", func(t *testing.T) {
+		ai := &queueingAIService{}
+		ai.On("IsAvailable").Return(true)
+		hub := &MockWebSocketHub{}
+		hub.On("BroadcastToAll", "log_alert", mock.Anything).Return()
+		service := newService(ai, hub, true)
+
+		submit(t, service, "order 1 failed", "order 2 failed", "payment declined")
+		assert.Empty(t, ai.keys, "below the threshold")
+
+		submit(t, service, "order 3 failed", "order 4 failed", "order 5 failed")
+		require.Len(t, ai.keys, 1)
+		req := ai.reqs[0]
+		assert.Len(t, req.Logs, 3)
+		assert.Equal(t, "error_detection", req.AnalysisType)
+		assert.Equal(t, ai.keys[0], req.Filters["fingerprint"])
+		assert.Equal(t, req.Logs[0].Fingerprint, ai.keys[0])
+
+		// Tracking restarts after a trigger, so the next spike needs the threshold again
+		submit(t, service, "order 6 failed")
+		assert.Len(t, ai.keys, 1)
+
+		hub.On("BroadcastToAll", "log_auto_analysis", mock.MatchedBy(func(event map[string]interface{}) bool {
+			return event["fingerprint"] == ai.keys[0] && event["summary"] == "orders failing" && event["occurrences"] == 3
+		})).Return().Once()
+		ai.dones[0](&models.AILogAnalysisResponse{Summary: "orders failing"}, nil)
+		hub.AssertExpectations(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ai := &queueingAIService{}
+		ai.On("IsAvailable").Return(true)
+		service := newService(ai, nil, false)
+
+		submit(t, service, "order 1 failed", "order 2 failed", "order 3 failed")
+		assert.Empty(t, ai.keys)
+	})
+
+	t.Run("AI service unavailable", func(t *testing.T) {
+		ai := &queueingAIService{}
+		ai.On("IsAvailable").Return(false)
+		service := newService(ai, nil, true)
+
+		submit(t, service, "order 1 failed", "order 2 failed", "order 3 failed")
+		assert.Empty(t, ai.keys)
+	})
+
+	t.Run("AI service without a queue", func(t *testing.T) {
+		ai := &MockAIService{}
+		service := newService(ai, nil, true)
+
+		submit(t, service, "order 1 failed", "order 2 failed", "order 3 failed")
+		ai.AssertNotCalled(t, "IsAvailable")
+	})
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// originalTimestampKey is the context key keeping the submitted timestamp of a clamped entry
+const originalTimestampKey = "original_timestamp"
+
+// clampClockSkew replaces a timestamp further than MaxClockSkew from now, in either direction,
+// with now, so a client with a broken clock can't push its logs outside time-range filters or
+// into distant hourly buckets. The submitted timestamp is kept in the entry context. It reports
+// whether the entry was clamped.
+func (s *LogService) clampClockSkew(entry *models.LogEntry, now time.Time) bool {
+	tolerance := s.config.MaxClockSkew
+	if tolerance <= 0 {
+		return false
+	}
+
+	skew := entry.Timestamp.Sub(now)
+	if skew >= -tolerance && skew <= tolerance {
+		return false
+	}
+
+	if entry.Context == nil {
+		entry.Context = make(map[string]interface{})
+	}
+	if _, exists := entry.Context[originalTimestampKey]; !exists {
+		entry.Context[originalTimestampKey] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	entry.Timestamp = now
+	s.clockSkewClamped.Add(1)
+	return true
+}
+
+// GetClockSkewStats returns how many submitted timestamps were clamped to server time
+func (s *LogService) GetClockSkewStats() models.LogClockSkewStats {
+	stats := models.LogClockSkewStats{Clamped: s.clockSkewClamped.Load()}
+	if s.config.MaxClockSkew > 0 {
+		stats.Tolerance = s.config.MaxClockSkew.String()
+	}
+	return stats
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogService_ClockSkew(t *testing.T) {
+	now := time.Now()
+	future := now.Add(48 * time.Hour)
+	ancient := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := now.Add(-30 * time.Minute)
+
+	submit := func(service *LogService, logs ...models.LogEntry) *models.LogSubmissionResponse {
+		response, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{Source: "frontend", Logs: logs})
+		require.NoError(t, err)
+		return response
+	}
+	entry := func(message string, timestamp time.Time) models.LogEntry {
+		return models.LogEntry{Level: "info", Source: "frontend", Message: message, Timestamp: timestamp}
+	}
+
+	t.Run("timestamps beyond the tolerance are clamped", func(t *testing.T) {
+		store := NewMemoryLogStore(0)
+		service := NewLogService(nil, nil, LogServiceConfig{Store: store, MaxClockSkew: time.Hour})
+
+		response := submit(service,
+			entry("future", future),
+			entry("ancient", ancient),
+			entry("recent", recent),
+			models.LogEntry{Level: "info", Source: "frontend", Message: "kept context", Timestamp: future,
+				Context: map[string]interface{}{"user_id": "42"}},
+		)
+		assert.Equal(t, 4, response.Accepted)
+		assert.Equal(t, 3, response.Clamped)
+
+		entries, err := store.Query(LogFilter{})
+		require.NoError(t, err)
+		byMessage := make(map[string]models.LogEntry, len(entries))
+		for _, stored := range entries {
+			byMessage[stored.Message] = stored
+		}
+
+		for message, original := range map[string]time.Time{"future": future, "ancient": ancient} {
+			clamped := byMessage[message]
+			assert.WithinDuration(t, time.Now(), clamped.Timestamp, 5*time.Second, message)
+			assert.Equal(t, original.Format(time.RFC3339Nano), clamped.Context[originalTimestampKey], message)
+		}
+		assert.True(t, recent.Equal(byMessage["recent"].Timestamp), "timestamps within the tolerance are kept")
+		assert.NotContains(t, byMessage["recent"].Context, originalTimestampKey)
+		assert.Equal(t, "42", byMessage["kept context"].Context["user_id"])
+		assert.Contains(t, byMessage["kept context"].Context, originalTimestampKey)
+
+		// Clamped entries land in the current time range instead of decades ago
+		inRange, err := store.Query(LogFilter{Start: now.Add(-time.Hour), End: time.Now().Add(time.Minute)})
+		require.NoError(t, err)
+		assert.Len(t, inRange, 4)
+
+		assert.Equal(t, models.LogClockSkewStats{Tolerance: "1h0m0s", Clamped: 3}, service.GetClockSkewStats())
+	})
+
+	t.Run("rejected entries aren't counted", func(t *testing.T) {
+		service := NewLogService(nil, nil, LogServiceConfig{MaxClockSkew: time.Hour})
+
+		response := submit(service, models.LogEntry{Level: "verbose", Source: "frontend", Message: "bad level", Timestamp: future})
+		assert.Equal(t, 1, response.Rejected)
+		assert.Zero(t, response.Clamped)
+		assert.Zero(t, service.GetClockSkewStats().Clamped)
+	})
+
+	t.Run("zero tolerance disables clamping", func(t *testing.T) {
+		store := NewMemoryLogStore(0)
+		service := NewLogService(nil, nil, LogServiceConfig{Store: store})
+
+		response := submit(service, entry("ancient", ancient))
+		assert.Zero(t, response.Clamped)
+
+		entries, err := store.Query(LogFilter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, ancient.Equal(entries[0].Timestamp))
+		assert.Equal(t, models.LogClockSkewStats{}, service.GetClockSkewStats())
+	})
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// LogExportFunc streams the entries selected for an export to fn, newest first, stopping at the
+// first error fn returns
+type LogExportFunc func(fn func(models.LogEntry) error) error
+
+// ExportLogs checks an export's filters and returns the function streaming its entries. The
+// time range, levels, sources, components, search query and custom filters of req apply; limit,
+// offset and MaxAnalysisRange don't, so an export covers every matching entry. Entries are read
+// from the store as they are written out rather than loaded all at once.
+func (s *LogService) ExportLogs(req *models.LogAnalysisRequest) (LogExportFunc, error) {
+	search, err := parseLogSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := LogFilter{
+		Start:      req.TimeRange.Start,
+		End:        req.TimeRange.End,
+		Levels:     req.Levels,
+		Sources:    req.Sources,
+		Components: req.Components,
+	}
+	matches := func(entry *models.LogEntry) bool {
+		return (search == nil || search.matches(entry)) && matchesCustomFilters(entry, req.Filters)
+	}
+
+	return func(fn func(models.LogEntry) error) error {
+		// Entries still waiting in the fallback buffer are merged in by timestamp
+		buffered, _ := s.fallback.Query(filter)
+		emit := func(entry models.LogEntry) error {
+			if !matches(&entry) {
+				return nil
+			}
+			return fn(entry)
+		}
+
+		err := s.store.Each(filter, func(entry models.LogEntry) error {
+			for len(buffered) > 0 && buffered[0].Timestamp.After(entry.Timestamp) {
+				if err := emit(buffered[0]); err != nil {
+					return err
+				}
+				buffered = buffered[1:]
+			}
+			return emit(entry)
+		})
+		if err != nil {
+			return err
+		}
+		for _, entry := range buffered {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// logCSVHeader is the header row of CSV exports; context is written as a JSON object
+var logCSVHeader = []string{
+	"id", "timestamp", "level", "source", "component", "function", "line_number",
+	"message", "stack_trace", "user_id", "session_id", "fingerprint", "context",
+}
+
+// WriteLogExport writes the entries of export to w in format (models.LogExportCSV or
+// models.LogExportNDJSON) and returns how many were written
+func WriteLogExport(w io.Writer, format string, export LogExportFunc) (int, error) {
+	written := 0
+	switch format {
+	case models.LogExportNDJSON:
+		encoder := json.NewEncoder(w)
+		err := export(func(entry models.LogEntry) error {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			written++
+			return nil
+		})
+		return written, err
+	case models.LogExportCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(logCSVHeader); err != nil {
+			return 0, err
+		}
+		err := export(func(entry models.LogEntry) error {
+			record, err := logCSVRecord(&entry)
+			if err != nil {
+				return err
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+			written++
+			return nil
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		return written, err
+	default:
+		return 0, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// logCSVRecord returns an entry's CSV row in logCSVHeader order
+func logCSVRecord(entry *models.LogEntry) ([]string, error) {
+	context := ""
+	if len(entry.Context) > 0 {
+		data, err := json.Marshal(entry.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode context of log %s: %w", entry.ID, err)
+		}
+		context = string(data)
+	}
+
+	lineNumber := ""
+	if entry.LineNumber != 0 {
+		lineNumber = strconv.Itoa(entry.LineNumber)
+	}
+
+	return []string{
+		csvCell(entry.ID), entry.Timestamp.Format(time.RFC3339Nano), entry.Level, entry.Source,
+		csvCell(entry.Component), csvCell(entry.Function), lineNumber, csvCell(entry.Message),
+		csvCell(entry.StackTrace), csvCell(entry.UserID), csvCell(entry.SessionID), entry.Fingerprint, context,
+	}, nil
+}
+
+// csvCell prefixes submitted text that a spreadsheet would evaluate as a formula with a quote, so
+// opening an export can't run formulas injected through log messages
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectExport runs export, returning the IDs of the entries it streamed
+func collectExport(t *testing.T, export LogExportFunc) []string {
+	t.Helper()
+
+	var entries []models.LogEntry
+	require.NoError(t, export(func(entry models.LogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	return ids(entries)
+}
+
+func TestLogService_ExportLogs(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+	now := time.Now()
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs: []models.LogEntry{
+			{ID: "1", Timestamp: now.Add(-3 * time.Hour), Level: "error", Source: "backend", Component: "payments", Message: "Payment declined", UserID: "u1"},
+			{ID: "2", Timestamp: now.Add(-2 * time.Hour), Level: "info", Source: "frontend", Component: "auth", Message: "User logged in", UserID: "u1"},
+			{ID: "3", Timestamp: now.Add(-1 * time.Hour), Level: "error", Source: "backend", Component: "payments", Message: "Card expired", UserID: "u2"},
+			{ID: "4", Timestamp: now, Level: "warn", Source: "backend", Component: "auth", Message: "Slow login", Context: map[string]interface{}{"region": "eu"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		req      models.LogAnalysisRequest
+		expected []string
+	}{
+		{name: "everything newest first", req: models.LogAnalysisRequest{}, expected: []string{"4", "3", "2", "1"}},
+		{name: "levels", req: models.LogAnalysisRequest{Levels: []string{"error"}}, expected: []string{"3", "1"}},
+		{name: "time range", req: models.LogAnalysisRequest{TimeRange: models.TimeRange{Start: now.Add(-150 * time.Minute)}}, expected: []string{"4", "3", "2"}},
+		{name: "search", req: models.LogAnalysisRequest{SearchQuery: "component:payments card"}, expected: []string{"3"}},
+		{name: "custom filters", req: models.LogAnalysisRequest{Filters: map[string]string{"user_id": "u1"}}, expected: []string{"2", "1"}},
+		{name: "context filters", req: models.LogAnalysisRequest{Filters: map[string]string{"region": "eu"}}, expected: []string{"4"}},
+		{name: "limit and offset are ignored", req: models.LogAnalysisRequest{Limit: 1, Offset: 1}, expected: []string{"4", "3", "2", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export, err := service.ExportLogs(&tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, collectExport(t, export))
+		})
+	}
+
+	t.Run("invalid search query", func(t *testing.T) {
+		_, err := service.ExportLogs(&models.LogAnalysisRequest{SearchQuery: `"unterminated`})
+		assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+	})
+}
+
+func TestLogService_ExportLogs_IncludesBufferedLogs(t *testing.T) {
+	store := &flakyLogStore{MemoryLogStore: NewMemoryLogStore(100)}
+	retry := DefaultLogStoreRetryConfig()
+	retry.MaxAttempts = 1
+	service := NewLogService(&MockAIService{}, nil, LogServiceConfig{Store: store, StoreRetry: retry})
+	now := time.Now()
+
+	submit := func(entries ...models.LogEntry) {
+		_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{Source: "backend", Logs: entries})
+		require.NoError(t, err)
+	}
+	submit(models.LogEntry{ID: "stored-old", Timestamp: now.Add(-2 * time.Hour), Level: "info", Source: "backend", Message: "old"})
+	store.failNext(1)
+	submit(
+		models.LogEntry{ID: "buffered-new", Timestamp: now, Level: "info", Source: "backend", Message: "new"},
+		models.LogEntry{ID: "buffered-oldest", Timestamp: now.Add(-3 * time.Hour), Level: "info", Source: "backend", Message: "oldest"},
+	)
+
+	export, err := service.ExportLogs(&models.LogAnalysisRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"buffered-new", "stored-old", "buffered-oldest"}, collectExport(t, export))
+}
+
+func TestWriteLogExport(t *testing.T) {
+	entries := []models.LogEntry{
+		{
+			ID: "log-1", Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), Level: "error", Source: "backend",
+			Component: "api", Function: "handle", LineNumber: 42, Message: "Timeout\nafter retry", UserID: "u1",
+			Context: map[string]interface{}{"attempt": 2},
+		},
+		{ID: "log-2", Timestamp: time.Date(2024, 1, 15, 10, 29, 0, 0, time.UTC), Level: "info", Source: "frontend", Message: "-1 items"},
+	}
+	export := LogExportFunc(func(fn func(models.LogEntry) error) error {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var out strings.Builder
+		written, err := WriteLogExport(&out, models.LogExportCSV, export)
+		require.NoError(t, err)
+		assert.Equal(t, 2, written)
+		assert.Equal(t, strings.Join([]string{
+			"id,timestamp,level,source,component,function,line_number,message,stack_trace,user_id,session_id,fingerprint,context",
+			`log-1,2024-01-15T10:30:00Z,error,backend,api,handle,42,"Timeout` + "\n" + `after retry",,u1,,,"{""attempt"":2}"`,
+			"log-2,2024-01-15T10:29:00Z,info,frontend,,,,'-1 items,,,,,",
+		}, "\n")+"\n", out.String())
+	})
+
+	t.Run("csv without entries keeps the header", func(t *testing.T) {
+		var out strings.Builder
+		written, err := WriteLogExport(&out, models.LogExportCSV, func(func(models.LogEntry) error) error { return nil })
+		require.NoError(t, err)
+		assert.Equal(t, 0, written)
+		assert.Equal(t, strings.Join(logCSVHeader, ",")+"\n", out.String())
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		var out strings.Builder
+		written, err := WriteLogExport(&out, models.LogExportNDJSON, export)
+		require.NoError(t, err)
+		assert.Equal(t, 2, written)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], `{"id":"log-1",`))
+		assert.Contains(t, lines[0], `"message":"Timeout\nafter retry"`)
+		assert.True(t, strings.HasPrefix(lines[1], `{"id":"log-2",`))
+	})
+
+	t.Run("store errors are returned", func(t *testing.T) {
+		errRead := errors.New("database is locked")
+		var out strings.Builder
+		_, err := WriteLogExport(&out, models.LogExportNDJSON, func(func(models.LogEntry) error) error { return errRead })
+		assert.ErrorIs(t, err, errRead)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var out strings.Builder
+		_, err := WriteLogExport(&out, "xlsx", export)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// fingerprintLength is the number of hex characters kept from a fingerprint's hash
+const fingerprintLength = 16
+
+// frameLocationSuffix matches the line and column numbers ending a JavaScript frame location, so a
+// frame keeps its fingerprint when unrelated code above it moves
+var frameLocationSuffix = regexp.MustCompile(`(:\d+)+$`)
+
+// fingerprintInputs returns the parts of an error its fingerprint is computed from: the message
+// with variable parts replaced by placeholders, the component and the top stack frame
+func (s *LogService) fingerprintInputs(entry *models.LogEntry) (message, component, frame string) {
+	frame = topStackFrame(entry.StackTrace)
+	if frame == "" {
+		frame = entry.Function
+	}
+	return s.extractPattern(entry.Message), entry.Component, frame
+}
+
+// fingerprint computes the fingerprint shared by every occurrence of the same error
+func (s *LogService) fingerprint(entry *models.LogEntry) string {
+	message, component, frame := s.fingerprintInputs(entry)
+	sum := sha256.Sum256([]byte(message + "\x00" + component + "\x00" + frame))
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+// assignFingerprint sets the fingerprint of an entry at or above the fingerprint level. Any
+// fingerprint sent by the client is dropped, so only fingerprints computed here are stored.
+func (s *LogService) assignFingerprint(entry *models.LogEntry) {
+	entry.Fingerprint = ""
+	if s.config.Fingerprints && s.isAtLeastLevel(entry.Level, s.config.FingerprintLevel) {
+		entry.Fingerprint = s.fingerprint(entry)
+	}
+}
+
+// topStackFrame returns the innermost frame of a Go or JavaScript stack trace with its arguments,
+// line and column removed, or "" if no frame is found
+func topStackFrame(stackTrace string) string {
+	for _, line := range strings.Split(stackTrace, "\n") {
+		line = strings.TrimSpace(line)
+
+		// JavaScript: "at handler (https://app/main.js:10:5)" or "at https://app/main.js:10:5"
+		if frame, ok := strings.CutPrefix(line, "at "); ok {
+			function, location, hasFunction := strings.Cut(frame, " (")
+			if !hasFunction {
+				return stripFrameLocation(function)
+			}
+			return function + " (" + stripFrameLocation(strings.TrimSuffix(location, ")")) + ")"
+		}
+
+		// Go: "main.(*Server).handle(0xc000010000, ...)"; the goroutine header and file lines are skipped
+		open := strings.LastIndex(line, "(")
+		if open > 0 && strings.HasSuffix(line, ")") && !strings.HasPrefix(line, "goroutine ") &&
+			!strings.ContainsAny(line[:open], " \t:/") {
+			return line[:open]
+		}
+	}
+	return ""
+}
+
+// stripFrameLocation removes the query string and line and column numbers from a frame location
+func stripFrameLocation(location string) string {
+	if i := strings.Index(location, "?"); i >= 0 {
+		location = location[:i]
+	}
+	return frameLocationSuffix.ReplaceAllString(location, "")
+}
+
+// GetFingerprints summarizes the stored fingerprinted errors, most frequent first
+func (s *LogService) GetFingerprints() ([]models.LogFingerprint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, err := s.store.Query(LogFilter{})
+	if err != nil {
+		return nil, err
+	}
+	stored = s.withBufferedLogs(stored, LogFilter{})
+
+	summaries := make(map[string]*models.LogFingerprint)
+	for i := range stored {
+		entry := &stored[i]
+		if entry.Fingerprint == "" {
+			continue
+		}
+
+		summary, exists := summaries[entry.Fingerprint]
+		if !exists {
+			summary = &models.LogFingerprint{
+				Fingerprint: entry.Fingerprint,
+				FirstSeen:   entry.Timestamp,
+			}
+			summaries[entry.Fingerprint] = summary
+		}
+		summary.Count++
+		if entry.Timestamp.Before(summary.FirstSeen) {
+			summary.FirstSeen = entry.Timestamp
+		}
+		if !exists || entry.Timestamp.After(summary.LastSeen) {
+			summary.Message, summary.Component, summary.Frame = s.fingerprintInputs(entry)
+			summary.Level = entry.Level
+			summary.LastSeen = entry.Timestamp
+			summary.SampleLogID = entry.ID
+		}
+	}
+
+	fingerprints := make([]models.LogFingerprint, 0, len(summaries))
+	for _, summary := range summaries {
+		fingerprints = append(fingerprints, *summary)
+	}
+	sort.Slice(fingerprints, func(i, j int) bool {
+		if fingerprints[i].Count != fingerprints[j].Count {
+			return fingerprints[i].Count > fingerprints[j].Count
+		}
+		if !fingerprints[i].LastSeen.Equal(fingerprints[j].LastSeen) {
+			return fingerprints[i].LastSeen.After(fingerprints[j].LastSeen)
+		}
+		return fingerprints[i].Fingerprint < fingerprints[j].Fingerprint
+	})
+	return fingerprints, nil
+}
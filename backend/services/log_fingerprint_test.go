@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	goStackTrace = "goroutine 12 [running]:\n" +
+		"main.(*Server).handle(0xc000010000, 0x1f)\n" +
+		"\t/app/server.go:42 +0x1d\n" +
+		"main.main()\n" +
+		"\t/app/main.go:10 +0x25"
+	jsStackTrace = "TypeError: Cannot read properties of undefined (reading 'id')\n" +
+		"    at renderUser (https://app.example.com/static/main.js?v=3:120:17)\n" +
+		"    at App (https://app.example.com/static/main.js?v=3:88:5)"
+)
+
+func TestTopStackFrame(t *testing.T) {
+	tests := []struct {
+		name       string
+		stackTrace string
+		expected   string
+	}{
+		{name: "go", stackTrace: goStackTrace, expected: "main.(*Server).handle"},
+		{name: "javascript", stackTrace: jsStackTrace, expected: "renderUser (https://app.example.com/static/main.js)"},
+		{name: "javascript without function", stackTrace: "Error: boom\n    at https://app.example.com/main.js:1:200", expected: "https://app.example.com/main.js"},
+		{name: "no frames", stackTrace: "something failed (see logs)", expected: ""},
+		{name: "empty", stackTrace: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, topStackFrame(tt.stackTrace))
+		})
+	}
+}
+
+func TestLogService_Fingerprint(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+	base := models.LogEntry{
+		Level:      "error",
+		Message:    "Timeout after 3000ms calling 10.0.0.12",
+		Component:  "api",
+		StackTrace: goStackTrace,
+	}
+	fingerprint := service.fingerprint(&base)
+	assert.Len(t, fingerprint, fingerprintLength)
+
+	same := base
+	same.Message = "Timeout after 5000ms calling 10.0.0.7"
+	same.StackTrace = "goroutine 99 [running]:\nmain.(*Server).handle(0xc0000aa000, 0x3)\n\t/app/server.go:57 +0x1d"
+	assert.Equal(t, fingerprint, service.fingerprint(&same), "variable parts and line numbers must not change the fingerprint")
+
+	otherComponent := base
+	otherComponent.Component = "worker"
+	assert.NotEqual(t, fingerprint, service.fingerprint(&otherComponent))
+
+	otherFrame := base
+	otherFrame.StackTrace = "goroutine 1 [running]:\nmain.(*Server).close()\n\t/app/server.go:80 +0x1d"
+	assert.NotEqual(t, fingerprint, service.fingerprint(&otherFrame))
+
+	otherMessage := base
+	otherMessage.Message = "Connection refused calling 10.0.0.12"
+	assert.NotEqual(t, fingerprint, service.fingerprint(&otherMessage))
+
+	// Without a stack trace the reported function stands in for the top frame
+	withFunction := base
+	withFunction.StackTrace = ""
+	withFunction.Function = "handle"
+	otherFunction := withFunction
+	otherFunction.Function = "close"
+	assert.NotEqual(t, service.fingerprint(&withFunction), service.fingerprint(&otherFunction))
+}
+
+func TestLogService_SubmitLogs_AssignsFingerprints(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   LogServiceConfig
+		expected map[string]bool // Whether each level gets a fingerprint
+	}{
+		{
+			name:     "errors by default",
+			config:   DefaultLogServiceConfig(),
+			expected: map[string]bool{"error": true, "warn": false, "info": false},
+		},
+		{
+			name: "fingerprint level",
+			config: LogServiceConfig{
+				Levels:           []string{"error", "warn", "info"},
+				ErrorLevel:       "error",
+				Fingerprints:     true,
+				FingerprintLevel: "warn",
+			},
+			expected: map[string]bool{"error": true, "warn": true, "info": false},
+		},
+		{
+			name:     "disabled",
+			config:   LogServiceConfig{Levels: []string{"error", "warn", "info"}, ErrorLevel: "error"},
+			expected: map[string]bool{"error": false, "warn": false, "info": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewLogService(&MockAIService{}, nil, tt.config)
+
+			var logs []models.LogEntry
+			for level := range tt.expected {
+				// A fingerprint sent by the client is never kept
+				logs = append(logs, models.LogEntry{Level: level, Source: "frontend", Message: "failed " + level, Fingerprint: "client"})
+			}
+			_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{Source: "frontend", Logs: logs})
+			require.NoError(t, err)
+
+			stored, err := service.store.Query(LogFilter{})
+			require.NoError(t, err)
+			require.Len(t, stored, len(tt.expected))
+			for _, entry := range stored {
+				if tt.expected[entry.Level] {
+					assert.Len(t, entry.Fingerprint, fingerprintLength, entry.Level)
+				} else {
+					assert.Empty(t, entry.Fingerprint, entry.Level)
+				}
+			}
+		})
+	}
+}
+
+func TestLogService_GetFingerprints(t *testing.T) {
+	sqliteStore, err := NewSQLiteLogStore(filepath.Join(t.TempDir(), "logs.db"), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	stores := map[string]LogStore{
+		"memory": NewMemoryLogStore(100),
+		"sqlite": sqliteStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			config := DefaultLogServiceConfig()
+			config.Store = store
+			service := NewLogService(&MockAIService{}, nil, config)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+				Source: "backend",
+				Logs: []models.LogEntry{
+					{ID: "t1", Timestamp: now.Add(-2 * time.Hour), Level: "error", Source: "backend", Component: "api", Message: "Timeout after 3000ms"},
+					{ID: "t2", Timestamp: now, Level: "error", Source: "backend", Component: "api", Message: "Timeout after 4500ms"},
+					{ID: "t3", Timestamp: now.Add(-time.Hour), Level: "error", Source: "backend", Component: "api", Message: "Timeout after 120ms"},
+					{ID: "n1", Timestamp: now.Add(-30 * time.Minute), Level: "error", Source: "backend", Component: "db", Message: "User 42 not found"},
+					{ID: "i1", Timestamp: now, Level: "info", Source: "backend", Component: "api", Message: "Request served"},
+				},
+			})
+			require.NoError(t, err)
+
+			fingerprints, err := service.GetFingerprints()
+			require.NoError(t, err)
+			require.Len(t, fingerprints, 2)
+
+			timeout := fingerprints[0]
+			assert.Equal(t, 3, timeout.Count)
+			assert.Equal(t, "Timeout after [NUMBER]ms", timeout.Message)
+			assert.Equal(t, "api", timeout.Component)
+			assert.Equal(t, "error", timeout.Level)
+			assert.True(t, timeout.FirstSeen.Equal(now.Add(-2*time.Hour)))
+			assert.True(t, timeout.LastSeen.Equal(now))
+			assert.Equal(t, "t2", timeout.SampleLogID)
+
+			notFound := fingerprints[1]
+			assert.Equal(t, 1, notFound.Count)
+			assert.Equal(t, "db", notFound.Component)
+			assert.NotEqual(t, timeout.Fingerprint, notFound.Fingerprint)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogService_PauseIngestion(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+	submit := func() (*models.LogSubmissionResponse, error) {
+		return service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+			Source: "backend",
+			Logs:   []models.LogEntry{{Level: "info", Source: "backend", Message: "hello"}},
+		})
+	}
+
+	assert.Equal(t, models.LogIngestionState{}, service.GetIngestionState())
+
+	state := service.PauseIngestion("store maintenance", 90*time.Second+time.Millisecond)
+	assert.True(t, state.Paused)
+	assert.Equal(t, "store maintenance", state.Reason)
+	assert.Equal(t, 91, state.RetryAfterSeconds, "the retry hint is rounded up to whole seconds")
+	require.NotNil(t, state.PausedAt)
+	pausedAt := *state.PausedAt
+
+	response, err := submit()
+	assert.ErrorIs(t, err, ErrIngestionPaused)
+	assert.Nil(t, response)
+	assert.Equal(t, 0, service.GetLogCount(), "paused submissions must not reach the store")
+
+	// Pausing again keeps the pause time and anything not given
+	state = service.PauseIngestion("", 0)
+	assert.Equal(t, "store maintenance", state.Reason)
+	assert.Equal(t, 91, state.RetryAfterSeconds)
+	assert.True(t, state.PausedAt.Equal(pausedAt))
+	state = service.PauseIngestion("migrating to sqlite", 0)
+	assert.Equal(t, "migrating to sqlite", state.Reason)
+
+	assert.Equal(t, models.LogIngestionState{}, service.ResumeIngestion())
+	response, err = submit()
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Accepted)
+	assert.Equal(t, 1, service.GetLogCount())
+
+	// Resuming when not paused is a no-op
+	assert.Equal(t, models.LogIngestionState{}, service.ResumeIngestion())
+}
+
+func TestLogService_IngestionPausedAtStartup(t *testing.T) {
+	config := DefaultLogServiceConfig()
+	config.IngestionPaused = true
+	service := NewLogService(&MockAIService{}, nil, config)
+
+	state := service.GetIngestionState()
+	assert.True(t, state.Paused)
+	assert.NotNil(t, state.PausedAt)
+
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "frontend",
+		Logs:   []models.LogEntry{{Level: "error", Source: "frontend", Message: "boom"}},
+	})
+	assert.ErrorIs(t, err, ErrIngestionPaused)
+
+	service.ResumeIngestion()
+	assert.False(t, service.GetIngestionState().Paused)
+}
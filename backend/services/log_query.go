@@ -0,0 +1,358 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrInvalidSearchQuery is returned when a log search query can't be parsed
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// logQuery is a parsed log search query. The syntax is:
+//
+//	term                unscoped word, matched against message, component and function
+//	"quoted phrase"     matched as a whole, spaces included
+//	field:value         matched against one field, e.g. component:auth or message:"timed out"
+//	a AND b, a b        both must match; adjacent terms are joined with AND
+//	a OR b              either must match; AND binds tighter than OR
+//	( ... )             grouping
+//
+// Matching is a case-insensitive substring match. Fields other than the named LogEntry fields
+// are looked up in the entry's context.
+type logQuery interface {
+	matches(entry *models.LogEntry) bool
+}
+
+// logQueryTerm matches value in field, or in the default fields when field is empty
+type logQueryTerm struct {
+	field string
+	value string // Lowercased
+}
+
+// logQueryAnd matches when every clause matches
+type logQueryAnd []logQuery
+
+// logQueryOr matches when any clause matches
+type logQueryOr []logQuery
+
+func (t logQueryTerm) matches(entry *models.LogEntry) bool {
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), t.value)
+	}
+
+	switch t.field {
+	case "":
+		return contains(entry.Message) || contains(entry.Component) || contains(entry.Function)
+	case "message":
+		return contains(entry.Message)
+	case "component":
+		return contains(entry.Component)
+	case "function":
+		return contains(entry.Function)
+	case "level":
+		return contains(entry.Level)
+	case "source":
+		return contains(entry.Source)
+	case "user_id":
+		return contains(entry.UserID)
+	case "session_id":
+		return contains(entry.SessionID)
+	case "stack_trace":
+		return contains(entry.StackTrace)
+	default:
+		value, exists := entry.Context[t.field]
+		return exists && contains(fmt.Sprintf("%v", value))
+	}
+}
+
+func (a logQueryAnd) matches(entry *models.LogEntry) bool {
+	for _, clause := range a {
+		if !clause.matches(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o logQueryOr) matches(entry *models.LogEntry) bool {
+	for _, clause := range o {
+		if clause.matches(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSearchRegexLength caps the length of a regex search. Go's regexp runs in time linear in the
+// input, so there is no catastrophic backtracking to fear, but a long pattern still compiles to a
+// large program that is run against every analyzed entry.
+const maxSearchRegexLength = 256
+
+// logQueryRegex matches a regular expression against message, component and function
+type logQueryRegex struct {
+	pattern *regexp.Regexp
+}
+
+func (r logQueryRegex) matches(entry *models.LogEntry) bool {
+	return r.pattern.MatchString(entry.Message) || r.pattern.MatchString(entry.Component) ||
+		r.pattern.MatchString(entry.Function)
+}
+
+// parseLogSearch parses the search query of req as its SearchMode says. A blank query returns nil,
+// which callers treat as matching everything.
+func parseLogSearch(req *models.LogAnalysisRequest) (logQuery, error) {
+	switch req.SearchMode {
+	case "", models.LogSearchContains:
+		return parseLogQuery(req.SearchQuery)
+	case models.LogSearchRegex:
+		return parseLogRegex(req.SearchQuery)
+	default:
+		return nil, fmt.Errorf("%w: unknown search mode %q", ErrInvalidSearchQuery, req.SearchMode)
+	}
+}
+
+// parseLogRegex compiles a regex search. Like the query language it is case-insensitive, unless
+// the pattern turns that off with (?-i).
+func parseLogRegex(pattern string) (logQuery, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, nil
+	}
+	if len(pattern) > maxSearchRegexLength {
+		return nil, fmt.Errorf("%w: regex longer than %d characters", ErrInvalidSearchQuery, maxSearchRegexLength)
+	}
+
+	compiled, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+	return logQueryRegex{pattern: compiled}, nil
+}
+
+// parseLogQuery parses a search query. A blank query returns nil, which callers treat as
+// matching everything.
+func parseLogQuery(input string) (logQuery, error) {
+	tokens, err := tokenizeLogQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	parser := &logQueryParser{tokens: tokens}
+	query, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("%w: unexpected %s", ErrInvalidSearchQuery, tokens[parser.pos])
+	}
+	return query, nil
+}
+
+type logQueryTokenKind int
+
+const (
+	logQueryTokenTerm logQueryTokenKind = iota
+	logQueryTokenAnd
+	logQueryTokenOr
+	logQueryTokenOpen
+	logQueryTokenClose
+)
+
+type logQueryToken struct {
+	kind  logQueryTokenKind
+	field string
+	value string
+}
+
+// String describes the token for error messages
+func (t logQueryToken) String() string {
+	switch t.kind {
+	case logQueryTokenAnd:
+		return "AND"
+	case logQueryTokenOr:
+		return "OR"
+	case logQueryTokenOpen:
+		return `"("`
+	case logQueryTokenClose:
+		return `")"`
+	}
+	if t.field != "" {
+		return fmt.Sprintf("%q", t.field+":"+t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+// tokenizeLogQuery splits a query into terms, operators and parentheses. AND and OR are
+// only operators in upper case, so "and" is an ordinary search word.
+func tokenizeLogQuery(input string) ([]logQueryToken, error) {
+	var tokens []logQueryToken
+
+	readPhrase := func(start int) (string, int, error) {
+		end := strings.IndexByte(input[start+1:], '"')
+		if end < 0 {
+			return "", 0, fmt.Errorf("%w: unterminated quote", ErrInvalidSearchQuery)
+		}
+		return input[start+1 : start+1+end], start + end + 2, nil
+	}
+
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, logQueryToken{kind: logQueryTokenOpen})
+			i++
+		case c == ')':
+			tokens = append(tokens, logQueryToken{kind: logQueryTokenClose})
+			i++
+		case c == '"':
+			phrase, next, err := readPhrase(i)
+			if err != nil {
+				return nil, err
+			}
+			if phrase = strings.TrimSpace(phrase); phrase != "" {
+				tokens = append(tokens, logQueryToken{kind: logQueryTokenTerm, value: strings.ToLower(phrase)})
+			}
+			i = next
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t\n\r()\"", rune(input[i])) {
+				i++
+			}
+			word := input[start:i]
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, logQueryToken{kind: logQueryTokenAnd})
+				continue
+			case "OR":
+				tokens = append(tokens, logQueryToken{kind: logQueryTokenOr})
+				continue
+			}
+
+			field, value, scoped := strings.Cut(word, ":")
+			if !scoped || field == "" {
+				tokens = append(tokens, logQueryToken{kind: logQueryTokenTerm, value: strings.ToLower(word)})
+				continue
+			}
+			// field:"quoted value"
+			if value == "" && i < len(input) && input[i] == '"' {
+				phrase, next, err := readPhrase(i)
+				if err != nil {
+					return nil, err
+				}
+				value, i = phrase, next
+			}
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%w: missing value for field %q", ErrInvalidSearchQuery, field)
+			}
+			tokens = append(tokens, logQueryToken{
+				kind:  logQueryTokenTerm,
+				field: strings.ToLower(field),
+				value: strings.ToLower(value),
+			})
+		}
+	}
+
+	return tokens, nil
+}
+
+// logQueryParser builds a logQuery from tokens by recursive descent
+type logQueryParser struct {
+	tokens []logQueryToken
+	pos    int
+}
+
+// peek returns the kind of the next token; ok is false at the end of the query
+func (p *logQueryParser) peek() (logQueryTokenKind, bool) {
+	if p.pos >= len(p.tokens) {
+		return 0, false
+	}
+	return p.tokens[p.pos].kind, true
+}
+
+// parseOr parses clauses separated by OR
+func (p *logQueryParser) parseOr() (logQuery, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := logQueryOr{first}
+	for kind, ok := p.peek(); ok && kind == logQueryTokenOr; kind, ok = p.peek() {
+		p.pos++
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 1 {
+		return first, nil
+	}
+	return clauses, nil
+}
+
+// parseAnd parses terms joined by AND or by being adjacent
+func (p *logQueryParser) parseAnd() (logQuery, error) {
+	first, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := logQueryAnd{first}
+	for {
+		kind, ok := p.peek()
+		if !ok || (kind != logQueryTokenAnd && kind != logQueryTokenTerm && kind != logQueryTokenOpen) {
+			break
+		}
+		if kind == logQueryTokenAnd {
+			p.pos++
+		}
+
+		clause, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 1 {
+		return first, nil
+	}
+	return clauses, nil
+}
+
+// parseTerm parses a single term or a parenthesized group
+func (p *logQueryParser) parseTerm() (logQuery, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: query ends where a search term was expected", ErrInvalidSearchQuery)
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case logQueryTokenTerm:
+		p.pos++
+		return logQueryTerm{field: token.field, value: token.value}, nil
+	case logQueryTokenOpen:
+		p.pos++
+		group, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if kind, ok := p.peek(); !ok || kind != logQueryTokenClose {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidSearchQuery)
+		}
+		p.pos++
+		return group, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %s", ErrInvalidSearchQuery, token)
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected logQuery
+	}{
+		{query: "timeout", expected: logQueryTerm{value: "timeout"}},
+		{query: `"Connection Failed"`, expected: logQueryTerm{value: "connection failed"}},
+		{query: "Component:Auth", expected: logQueryTerm{field: "component", value: "auth"}},
+		{query: `message:"timed out"`, expected: logQueryTerm{field: "message", value: "timed out"}},
+		{
+			query: `component:auth AND "connection failed"`,
+			expected: logQueryAnd{
+				logQueryTerm{field: "component", value: "auth"},
+				logQueryTerm{value: "connection failed"},
+			},
+		},
+		{
+			query:    "disk full",
+			expected: logQueryAnd{logQueryTerm{value: "disk"}, logQueryTerm{value: "full"}},
+		},
+		{
+			query: "a OR b c",
+			expected: logQueryOr{
+				logQueryTerm{value: "a"},
+				logQueryAnd{logQueryTerm{value: "b"}, logQueryTerm{value: "c"}},
+			},
+		},
+		{
+			query: "(a OR b) AND c",
+			expected: logQueryAnd{
+				logQueryOr{logQueryTerm{value: "a"}, logQueryTerm{value: "b"}},
+				logQueryTerm{value: "c"},
+			},
+		},
+		{
+			query:    "read and write",
+			expected: logQueryAnd{logQueryTerm{value: "read"}, logQueryTerm{value: "and"}, logQueryTerm{value: "write"}},
+		},
+		{query: "  ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseLogQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestParseLogQuery_Errors(t *testing.T) {
+	tests := map[string]string{
+		`"connection failed`:  "unterminated quote",
+		`message:"timed out`:  "unterminated quote",
+		"component:":          `missing value for field "component"`,
+		"(a OR b":             "missing closing parenthesis",
+		"a OR b)":             `unexpected ")"`,
+		"a AND":               "query ends where a search term was expected",
+		"OR a":                "unexpected OR",
+		"a AND OR b":          "unexpected OR",
+		"()":                  `unexpected ")"`,
+		`component:"" AND db`: `missing value for field "component"`,
+	}
+
+	for query, message := range tests {
+		t.Run(query, func(t *testing.T) {
+			_, err := parseLogQuery(query)
+			assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+			assert.ErrorContains(t, err, message)
+		})
+	}
+}
+
+func TestLogQuery_Matches(t *testing.T) {
+	entry := &models.LogEntry{
+		Level:     "error",
+		Source:    "backend",
+		Message:   "Database connection failed after 3 retries",
+		Component: "auth",
+		Function:  "Login",
+		UserID:    "user-42",
+		Context:   map[string]interface{}{"region": "eu-west-1", "attempt": 3},
+	}
+
+	tests := map[string]bool{
+		"connection":                             true,
+		"LOGIN":                                  true,
+		`"connection failed"`:                    true,
+		`"failed connection"`:                    false,
+		`component:auth AND "connection failed"`: true,
+		"component:payments AND connection":      false,
+		"component:payments OR connection":       true,
+		"level:error source:backend":             true,
+		"message:auth":                           false,
+		"user_id:42":                             true,
+		"region:eu-west":                         true,
+		"attempt:3":                              true,
+		"missing_key:anything":                   false,
+		"(component:payments OR component:auth) AND retries": true,
+	}
+
+	for query, expected := range tests {
+		t.Run(query, func(t *testing.T) {
+			parsed, err := parseLogQuery(query)
+			require.NoError(t, err)
+			assert.Equal(t, expected, parsed.matches(entry))
+		})
+	}
+}
+
+func TestLogService_FilterLogs_SearchQuery(t *testing.T) {
+	service := NewLogService(nil, nil)
+	require.NoError(t, service.store.Append(
+		models.LogEntry{ID: "1", Level: "error", Source: "backend", Component: "auth", Message: "Connection failed to identity provider"},
+		models.LogEntry{ID: "2", Level: "error", Source: "backend", Component: "payments", Message: "Connection failed to card processor"},
+		models.LogEntry{ID: "3", Level: "info", Source: "frontend", Component: "auth", Message: "User logged in"},
+	))
+
+	ids := func(query string) []string {
+		filtered, _, err := service.filterLogs(&models.LogAnalysisRequest{SearchQuery: query, Limit: 10})
+		require.NoError(t, err)
+		matched := make([]string, len(filtered))
+		for i, entry := range filtered {
+			matched[i] = entry.ID
+		}
+		return matched
+	}
+
+	assert.ElementsMatch(t, []string{"1"}, ids(`component:auth AND "connection failed"`))
+	assert.ElementsMatch(t, []string{"1", "3"}, ids("component:auth"))
+	assert.ElementsMatch(t, []string{"2", "3"}, ids(`"card processor" OR "logged in"`))
+
+	_, _, err := service.filterLogs(&models.LogAnalysisRequest{SearchQuery: `"unterminated`, Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+}
+
+func TestParseLogSearch_Regex(t *testing.T) {
+	entry := &models.LogEntry{Component: "db", Function: "connectPool", Message: "Timeout while opening Database connection"}
+
+	tests := []struct {
+		pattern  string
+		expected bool
+	}{
+		{pattern: "timeout.*database", expected: true},
+		{pattern: "^Timeout", expected: true},
+		{pattern: "^database", expected: false},
+		{pattern: "^db$", expected: true},
+		{pattern: `connect[A-Z]\w+`, expected: true},
+		{pattern: "(?-i)^timeout", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			query, err := parseLogSearch(&models.LogAnalysisRequest{SearchQuery: tt.pattern, SearchMode: models.LogSearchRegex})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query.matches(entry))
+		})
+	}
+
+	t.Run("blank pattern matches everything", func(t *testing.T) {
+		query, err := parseLogSearch(&models.LogAnalysisRequest{SearchQuery: " ", SearchMode: models.LogSearchRegex})
+		require.NoError(t, err)
+		assert.Nil(t, query)
+	})
+}
+
+func TestParseLogSearch_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.LogAnalysisRequest
+	}{
+		{name: "invalid regex", req: models.LogAnalysisRequest{SearchQuery: "timeout(", SearchMode: models.LogSearchRegex}},
+		{name: "regex too long", req: models.LogAnalysisRequest{SearchQuery: strings.Repeat("a", maxSearchRegexLength+1), SearchMode: models.LogSearchRegex}},
+		{name: "unknown mode", req: models.LogAnalysisRequest{SearchQuery: "timeout", SearchMode: "glob"}},
+		{name: "invalid query in contains mode", req: models.LogAnalysisRequest{SearchQuery: `"unterminated`, SearchMode: models.LogSearchContains}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseLogSearch(&tt.req)
+			assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+		})
+	}
+
+	_, err := parseLogSearch(&models.LogAnalysisRequest{SearchQuery: strings.Repeat("a", maxSearchRegexLength), SearchMode: models.LogSearchRegex})
+	assert.NoError(t, err, "a pattern at the length limit is accepted")
+}
+
+func TestLogService_FilterLogs_SearchModes(t *testing.T) {
+	service := NewLogService(nil, nil)
+	require.NoError(t, service.store.Append(
+		models.LogEntry{ID: "1", Level: "error", Source: "backend", Component: "api", Message: "Timeout waiting for database"},
+		models.LogEntry{ID: "2", Level: "error", Source: "backend", Component: "api", Message: "database timeout"},
+		models.LogEntry{ID: "3", Level: "info", Source: "backend", Component: "api", Message: "timeout.*database"},
+	))
+
+	search := func(query, mode string) []string {
+		filtered, _, err := service.filterLogs(&models.LogAnalysisRequest{SearchQuery: query, SearchMode: mode, Limit: 10})
+		require.NoError(t, err)
+		return ids(filtered)
+	}
+
+	// Contains mode reads the pattern as text, regex mode as an expression
+	assert.ElementsMatch(t, []string{"3"}, search("timeout.*database", ""))
+	assert.ElementsMatch(t, []string{"3"}, search("timeout.*database", models.LogSearchContains))
+	assert.ElementsMatch(t, []string{"1", "3"}, search("timeout.*database", models.LogSearchRegex))
+	assert.ElementsMatch(t, []string{"2"}, search("^database", models.LogSearchRegex))
+
+	_, _, err := service.filterLogs(&models.LogAnalysisRequest{SearchQuery: "[unclosed", SearchMode: models.LogSearchRegex, Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+}
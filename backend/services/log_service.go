@@ -2,10 +2,15 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
@@ -19,24 +24,176 @@ type AIServiceInterface interface {
 	AnalyzeLogs(ctx context.Context, req *models.AILogAnalysisRequest) (*models.AILogAnalysisResponse, error)
 }
 
+// LogServiceConfig holds configuration for the log service
+type LogServiceConfig struct {
+	PropagateTraceID bool          // Include the originating request trace ID in WebSocket alerts
+	Levels           []string      // Accepted log levels, ordered from most to least severe
+	ErrorLevel       string        // Least severe level that still counts as an error
+	MaxAnalysisRange time.Duration // Widest time range a single analysis may cover; 0 disables the limit
+	Store            LogStore      // Where submitted logs are kept; nil keeps the last DefaultMaxStoredLogs in memory
+	IPEnricher       *IPEnricher   // Adds geo/ASN context to logs carrying a client IP; nil disables enrichment
+	AlertKeywords    []string      // Message keywords that make a log critical; empty uses DefaultAlertKeywords
+
+	// Critical alerts not acknowledged within AlertEscalationAfter are re-sent as escalations and
+	// passed to AlertEscalator when set, see log_alert_escalation.go; 0 disables escalation
+	AlertEscalationAfter time.Duration
+	AlertEscalator       AlertEscalator
+
+	Fingerprints     bool          // Assign error fingerprints to submitted logs, see log_fingerprint.go
+	FingerprintLevel string        // Least severe level assigned a fingerprint; empty uses ErrorLevel
+	IngestionPaused  bool          // Start with log ingestion paused, see PauseIngestion
+	MaxClockSkew     time.Duration // Timestamps further from server time are clamped to it, see log_clock_skew.go; 0 disables clamping
+	MaxAILogs        int           // Most logs an AI analysis is sent, see log_ai_sampling.go; 0 uses DefaultMaxAILogs
+
+	// Queue an AI analysis when one fingerprint is submitted AutoAnalysisThreshold times within
+	// AutoAnalysisWindow, see log_auto_analysis.go. Needs Fingerprints and an AI service
+	// implementing LogAnalysisQueue.
+	AutoAnalysis          bool
+	AutoAnalysisThreshold int
+	AutoAnalysisWindow    time.Duration
+
+	StoreRetry         *utils.RetryConfig // Retries of failed store writes; nil uses DefaultLogStoreRetryConfig
+	FallbackBufferSize int                // Entries kept in memory once store writes exhaust their retries; 0 uses DefaultLogFallbackBufferSize
+}
+
+// DefaultAlertKeywords are the message keywords that make a submitted log critical by default
+var DefaultAlertKeywords = []string{
+	"panic", "fatal", "crash", "security", "breach", "unauthorized",
+	"database connection", "out of memory", "disk full",
+}
+
+// ErrAlertRuleNotFound is returned when removing a keyword rule that does not exist
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// ErrInvalidAlertRule is returned when a keyword rule is empty or names an unknown level
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+// ErrIngestionPaused is returned by SubmitLogs while log ingestion is paused
+var ErrIngestionPaused = errors.New("log ingestion paused")
+
+// ErrAnalysisRangeTooWide is returned when an analysis request spans more than MaxAnalysisRange
+var ErrAnalysisRangeTooWide = errors.New("analysis time range too wide")
+
+// DefaultLogServiceConfig returns default log service configuration
+func DefaultLogServiceConfig() LogServiceConfig {
+	return LogServiceConfig{
+		PropagateTraceID: true,
+		Levels:           []string{"error", "warn", "info", "debug", "trace"},
+		ErrorLevel:       "error",
+		AlertKeywords:    DefaultAlertKeywords,
+		Fingerprints:     true,
+	}
+}
+
 // LogService handles log storage, analysis, and alerting
 type LogService struct {
-	logs      []models.LogEntry
+	store     LogStore
 	alerts    []models.LogAlert
 	aiService AIServiceInterface
 	wsHub     WebSocketBroadcaster
+	config    LogServiceConfig
+	levelRank map[string]int // Severity rank per level, 0 being most severe
 	mu        sync.RWMutex
 	logger    *utils.Logger
+
+	reports     map[string]*models.LogAnalysisReport
+	reportOrder []string // Report IDs oldest first, used for eviction
+	reportsMu   sync.RWMutex
+
+	alertRules   []models.LogAlertRule // Keyword rules in the order they were added
+	alertRulesMu sync.RWMutex
+
+	criticalAlerts     map[string]*trackedAlert // Critical alerts by ID, see log_alert_escalation.go
+	criticalAlertOrder []string                 // Alert IDs oldest first, used for eviction
+	criticalAlertsMu   sync.Mutex
+
+	ingestion models.LogIngestionState // Guarded by mu, so pausing waits for in-flight submissions
+	spikes    map[string]*errorSpike   // Recent occurrences per fingerprint, guarded by mu
+
+	// Store write retries and the buffer used once they run out, see log_store_retry.go
+	storeRetry  *utils.RetryExecutor
+	fallback    *MemoryLogStore
+	storeWrites logStoreWriteCounters
+
+	clockSkewClamped atomic.Int64 // Entries whose timestamp was clamped to server time
 }
 
+// maxStoredReports caps how many analysis reports are kept in memory
+const maxStoredReports = 100
+
 // NewLogService creates a new log service instance
-func NewLogService(aiService AIServiceInterface, wsHub WebSocketBroadcaster) *LogService {
+func NewLogService(aiService AIServiceInterface, wsHub WebSocketBroadcaster, config ...LogServiceConfig) *LogService {
+	cfg := DefaultLogServiceConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if len(cfg.Levels) == 0 {
+		cfg.Levels = DefaultLogServiceConfig().Levels
+	}
+
+	levelRank := make(map[string]int, len(cfg.Levels))
+	for i, level := range cfg.Levels {
+		if _, exists := levelRank[level]; !exists {
+			levelRank[level] = i
+		}
+	}
+
+	// Fall back to the most severe level if the error level is not configured
+	if _, exists := levelRank[cfg.ErrorLevel]; !exists {
+		cfg.ErrorLevel = cfg.Levels[0]
+	}
+	if _, exists := levelRank[cfg.FingerprintLevel]; !exists {
+		cfg.FingerprintLevel = cfg.ErrorLevel
+	}
+
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryLogStore(DefaultMaxStoredLogs)
+	}
+	retryConfig := cfg.StoreRetry
+	if retryConfig == nil {
+		retryConfig = DefaultLogStoreRetryConfig()
+	}
+	fallbackSize := cfg.FallbackBufferSize
+	if fallbackSize <= 0 {
+		fallbackSize = DefaultLogFallbackBufferSize
+	}
+	logger := utils.GetLogger()
+
+	keywords := cfg.AlertKeywords
+	if len(keywords) == 0 {
+		keywords = DefaultAlertKeywords
+	}
+	alertRules := make([]models.LogAlertRule, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = normalizeAlertKeyword(keyword); keyword != "" {
+			alertRules = append(alertRules, models.LogAlertRule{Keyword: keyword})
+		}
+	}
+
+	var ingestion models.LogIngestionState
+	if cfg.IngestionPaused {
+		pausedAt := time.Now()
+		ingestion = models.LogIngestionState{Paused: true, Reason: "paused at startup", PausedAt: &pausedAt}
+	}
+
 	return &LogService{
-		logs:      make([]models.LogEntry, 0),
+		store:     store,
 		alerts:    make([]models.LogAlert, 0),
 		aiService: aiService,
 		wsHub:     wsHub,
-		logger:    utils.GetLogger(),
+		config:    cfg,
+		levelRank: levelRank,
+		logger:    logger,
+		reports:   make(map[string]*models.LogAnalysisReport),
+
+		alertRules:     alertRules,
+		criticalAlerts: make(map[string]*trackedAlert),
+		ingestion:      ingestion,
+		spikes:         make(map[string]*errorSpike),
+
+		storeRetry: utils.NewRetryExecutor(retryConfig, logger),
+		fallback:   NewMemoryLogStore(fallbackSize),
 	}
 }
 
@@ -45,9 +202,14 @@ func (s *LogService) SubmitLogs(ctx context.Context, req *models.LogSubmissionRe
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.ingestion.Paused {
+		return nil, ErrIngestionPaused
+	}
+
 	accepted := 0
 	rejected := 0
-	errors := make([]string, 0)
+	clamped := 0
+	entryErrors := make([]models.LogEntryError, 0)
 	batchID := req.BatchID
 	if batchID == "" {
 		batchID = uuid.New().String()
@@ -60,12 +222,18 @@ func (s *LogService) SubmitLogs(ctx context.Context, req *models.LogSubmissionRe
 		"metadata":  req.Metadata,
 	})
 
-	// Process each log entry
+	// Validate every entry first so the batch is stored in one write
+	valid := make([]models.LogEntry, 0, len(req.Logs))
 	for i, logEntry := range req.Logs {
 		// Validate log entry
 		if err := s.validateLogEntry(&logEntry); err != nil {
 			rejected++
-			errors = append(errors, fmt.Sprintf("Log %d: %v", i+1, err))
+			entryError := models.LogEntryError{Index: i, Reason: err.Error()}
+			var invalid *logEntryError
+			if errors.As(err, &invalid) {
+				entryError.Field = invalid.field
+			}
+			entryErrors = append(entryErrors, entryError)
 			continue
 		}
 
@@ -75,35 +243,60 @@ func (s *LogService) SubmitLogs(ctx context.Context, req *models.LogSubmissionRe
 		}
 		if logEntry.Timestamp.IsZero() {
 			logEntry.Timestamp = time.Now()
+		} else if s.clampClockSkew(&logEntry, time.Now()) {
+			clamped++
+		}
+
+		// Enrichment is best effort; the entry is stored either way
+		if s.config.IPEnricher != nil {
+			if err := s.config.IPEnricher.Enrich(&logEntry); err != nil {
+				s.logger.Warn("Log IP enrichment failed", map[string]interface{}{
+					"log_id": logEntry.ID,
+					"error":  err.Error(),
+				})
+			}
 		}
 
-		// Store the log entry
-		s.logs = append(s.logs, logEntry)
-		accepted++
+		s.assignFingerprint(&logEntry)
 
-		// Check for critical log events and send WebSocket notifications
-		if s.isCriticalLogEvent(&logEntry) {
-			s.sendCriticalLogAlert(&logEntry)
+		valid = append(valid, logEntry)
+	}
+
+	s.appendToStore(ctx, valid)
+	accepted = len(valid)
+	s.trackErrorSpikes(ctx, valid)
+
+	// Check for critical log events and send WebSocket notifications
+	for i := range valid {
+		if match, critical := s.matchCriticalRule(&valid[i]); critical {
+			s.sendCriticalLogAlert(ctx, &valid[i], match)
 		}
 	}
 
-	// Trim logs if we have too many (keep last 10000)
-	if len(s.logs) > 10000 {
-		s.logs = s.logs[len(s.logs)-10000:]
+	status := models.LogSubmissionAllAccepted
+	if accepted == 0 && rejected > 0 {
+		status = models.LogSubmissionAllRejected
+	} else if rejected > 0 {
+		status = models.LogSubmissionPartial
 	}
 
 	response := &models.LogSubmissionResponse{
+		Status:      status,
+		Partial:     status == models.LogSubmissionPartial,
 		Accepted:    accepted,
 		Rejected:    rejected,
+		Clamped:     clamped,
 		BatchID:     batchID,
 		ProcessedAt: time.Now(),
-		Errors:      errors,
+		Errors:      entryErrors,
 	}
 
 	s.logger.Info("Log submission processed", map[string]interface{}{
 		"batch_id": batchID,
+		"status":   status,
 		"accepted": accepted,
 		"rejected": rejected,
+		"clamped":  clamped,
 	})
 
 	return response, nil
@@ -111,6 +304,17 @@ func (s *LogService) SubmitLogs(ctx context.Context, req *models.LogSubmissionRe
 
 // AnalyzeLogs performs analysis on stored logs with optional AI integration
 func (s *LogService) AnalyzeLogs(ctx context.Context, req *models.LogAnalysisRequest) (*models.LogAnalysisResponse, error) {
+	timeRange, err := s.boundTimeRange(req.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+	clamped := timeRange != req.TimeRange
+	if clamped {
+		bounded := *req
+		bounded.TimeRange = timeRange
+		req = &bounded
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -121,18 +325,18 @@ func (s *LogService) AnalyzeLogs(ctx context.Context, req *models.LogAnalysisReq
 		"components":   req.Components,
 		"search_query": req.SearchQuery,
 		"limit":        req.Limit,
+		"offset":       req.Offset,
 	})
 
-	// Filter logs based on request criteria
-	filteredLogs := s.filterLogs(req)
-
-	// Apply limit
-	if req.Limit > 0 && len(filteredLogs) > req.Limit {
-		filteredLogs = filteredLogs[:req.Limit]
+	// Filter logs based on request criteria; only the requested page is analyzed
+	filteredLogs, totalMatched, err := s.filterLogs(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Perform basic analysis
-	issues := s.detectIssues(filteredLogs)
+	spikes := spikeRuleFor(req)
+	issues := s.detectIssues(filteredLogs, spikes)
 	patterns := s.detectPatterns(filteredLogs)
 	statistics := s.calculateStatistics(filteredLogs)
 
@@ -171,36 +375,128 @@ func (s *LogService) AnalyzeLogs(ctx context.Context, req *models.LogAnalysisReq
 		Suggestions: suggestions,
 		Statistics:  statistics,
 		AnalyzedAt:  time.Now(),
+
+		TotalMatched:  totalMatched,
+		ReturnedCount: len(filteredLogs),
+		WorstSeverity: models.WorstIssueSeverity(issues),
+	}
+
+	if req.GroupBy == models.LogGroupByComponent {
+		response.GroupBy = req.GroupBy
+		response.Groups = s.analyzeByComponent(filteredLogs, spikes)
+	}
+
+	if clamped {
+		response.TimeRange = &req.TimeRange
 	}
 
 	s.logger.Info("Log analysis completed", map[string]interface{}{
-		"analyzed_logs": len(filteredLogs),
-		"issues_found":  len(issues),
-		"patterns":      len(patterns),
-		"suggestions":   len(suggestions),
+		"analyzed_logs":  len(filteredLogs),
+		"issues_found":   len(issues),
+		"worst_severity": response.WorstSeverity,
+		"patterns":       len(patterns),
+		"suggestions":    len(suggestions),
 	})
 
 	return response, nil
 }
 
+// boundTimeRange applies MaxAnalysisRange. A range without a start is narrowed to the
+// maximum span ending at its end (or now); an explicit range wider than the maximum is rejected.
+func (s *LogService) boundTimeRange(timeRange models.TimeRange) (models.TimeRange, error) {
+	maxRange := s.config.MaxAnalysisRange
+	if maxRange <= 0 {
+		return timeRange, nil
+	}
+
+	end := timeRange.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	if timeRange.Start.IsZero() {
+		timeRange.Start = end.Add(-maxRange)
+		return timeRange, nil
+	}
+
+	if span := end.Sub(timeRange.Start); span > maxRange {
+		return timeRange, fmt.Errorf("%w: requested range spans %s but at most %s may be analyzed at once",
+			ErrAnalysisRangeTooWide, span.Round(time.Second), maxRange)
+	}
+
+	return timeRange, nil
+}
+
+// CreateAnalysisReport runs a log analysis and stores the result as a report that can be retrieved later
+func (s *LogService) CreateAnalysisReport(ctx context.Context, req *models.LogAnalysisRequest) (*models.LogAnalysisReport, error) {
+	analysis, err := s.AnalyzeLogs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.LogAnalysisReport{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+		Request:   *req,
+		Analysis:  *analysis,
+	}
+
+	s.reportsMu.Lock()
+	s.reports[report.ID] = report
+	s.reportOrder = append(s.reportOrder, report.ID)
+	if len(s.reportOrder) > maxStoredReports {
+		oldest := s.reportOrder[0]
+		s.reportOrder = s.reportOrder[1:]
+		delete(s.reports, oldest)
+	}
+	s.reportsMu.Unlock()
+
+	s.logger.Info("Log analysis report created", map[string]interface{}{
+		"report_id":    report.ID,
+		"issues_found": len(analysis.Issues),
+	})
+
+	return report, nil
+}
+
+// GetAnalysisReport returns a previously created analysis report
+func (s *LogService) GetAnalysisReport(reportID string) (*models.LogAnalysisReport, error) {
+	s.reportsMu.RLock()
+	defer s.reportsMu.RUnlock()
+
+	report, exists := s.reports[reportID]
+	if !exists {
+		return nil, fmt.Errorf("analysis report not found: %s", reportID)
+	}
+
+	return report, nil
+}
+
+// logEntryError is returned by validateLogEntry, naming the field an entry was rejected for
+type logEntryError struct {
+	field  string
+	reason string
+}
+
+func (e *logEntryError) Error() string {
+	return e.reason
+}
+
 // validateLogEntry validates a log entry
 func (s *LogService) validateLogEntry(entry *models.LogEntry) error {
 	if entry.Message == "" {
-		return fmt.Errorf("message is required")
+		return &logEntryError{field: "message", reason: "message is required"}
 	}
 	if entry.Level == "" {
-		return fmt.Errorf("level is required")
+		return &logEntryError{field: "level", reason: "level is required"}
 	}
 	if entry.Source == "" {
-		return fmt.Errorf("source is required")
+		return &logEntryError{field: "source", reason: "source is required"}
 	}
 
 	// Validate level
-	validLevels := map[string]bool{
-		"error": true, "warn": true, "info": true, "debug": true, "trace": true,
-	}
-	if !validLevels[entry.Level] {
-		return fmt.Errorf("invalid level: %s", entry.Level)
+	if _, valid := s.levelRank[entry.Level]; !valid {
+		return &logEntryError{field: "level", reason: fmt.Sprintf("invalid level: %s", entry.Level)}
 	}
 
 	// Validate source
@@ -208,126 +504,142 @@ func (s *LogService) validateLogEntry(entry *models.LogEntry) error {
 		"frontend": true, "backend": true,
 	}
 	if !validSources[entry.Source] {
-		return fmt.Errorf("invalid source: %s", entry.Source)
+		return &logEntryError{field: "source", reason: fmt.Sprintf("invalid source: %s", entry.Source)}
 	}
 
 	return nil
 }
 
-// filterLogs filters logs based on analysis request criteria
-func (s *LogService) filterLogs(req *models.LogAnalysisRequest) []models.LogEntry {
-	filtered := make([]models.LogEntry, 0)
+// filterLogs filters logs based on analysis request criteria and returns the page selected by
+// Offset and Limit along with the number of logs matched in total. Time range, level, source and
+// component are matched by the store; search and custom filters are applied here.
+func (s *LogService) filterLogs(req *models.LogAnalysisRequest) ([]models.LogEntry, int, error) {
+	search, err := parseLogSearch(req)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	for _, log := range s.logs {
-		// Time range filter
-		if !req.TimeRange.Start.IsZero() && log.Timestamp.Before(req.TimeRange.Start) {
+	filter := LogFilter{
+		Start:      req.TimeRange.Start,
+		End:        req.TimeRange.End,
+		Levels:     req.Levels,
+		Sources:    req.Sources,
+		Components: req.Components,
+	}
+	stored, err := s.store.Query(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	stored = s.withBufferedLogs(stored, filter)
+
+	filtered := make([]models.LogEntry, 0, len(stored))
+	for _, log := range stored {
+		// Search query filter, see log_query.go for the syntax
+		if search != nil && !search.matches(&log) {
 			continue
 		}
-		if !req.TimeRange.End.IsZero() && log.Timestamp.After(req.TimeRange.End) {
+
+		// Custom filters
+		if !matchesCustomFilters(&log, req.Filters) {
 			continue
 		}
 
-		// Level filter
-		if len(req.Levels) > 0 {
-			found := false
-			for _, level := range req.Levels {
-				if log.Level == level {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
+		filtered = append(filtered, log)
+	}
 
-		// Source filter
-		if len(req.Sources) > 0 {
-			found := false
-			for _, source := range req.Sources {
-				if log.Source == source {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
+	total := len(filtered)
+	if req.Offset > 0 {
+		filtered = filtered[min(req.Offset, total):]
+	}
+	if req.Limit > 0 && len(filtered) > req.Limit {
+		filtered = filtered[:req.Limit]
+	}
 
-		// Component filter
-		if len(req.Components) > 0 {
-			found := false
-			for _, component := range req.Components {
-				if log.Component == component {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
+	return filtered, total, nil
+}
 
-		// Search query filter
-		if req.SearchQuery != "" {
-			query := strings.ToLower(req.SearchQuery)
-			if !strings.Contains(strings.ToLower(log.Message), query) &&
-				!strings.Contains(strings.ToLower(log.Component), query) &&
-				!strings.Contains(strings.ToLower(log.Function), query) {
-				continue
+// matchesCustomFilters reports whether a log has every user_id, session_id or context value in filters
+func matchesCustomFilters(log *models.LogEntry, filters map[string]string) bool {
+	for key, value := range filters {
+		switch key {
+		case "user_id":
+			if log.UserID != value {
+				return false
 			}
-		}
-
-		// Custom filters
-		if len(req.Filters) > 0 {
-			match := true
-			for key, value := range req.Filters {
-				switch key {
-				case "user_id":
-					if log.UserID != value {
-						match = false
-					}
-				case "session_id":
-					if log.SessionID != value {
-						match = false
-					}
-				default:
-					// Check in context
-					if contextValue, exists := log.Context[key]; exists {
-						if fmt.Sprintf("%v", contextValue) != value {
-							match = false
-						}
-					} else {
-						match = false
-					}
-				}
+		case "session_id":
+			if log.SessionID != value {
+				return false
 			}
-			if !match {
-				continue
+		default:
+			// Check in context
+			contextValue, exists := log.Context[key]
+			if !exists || fmt.Sprintf("%v", contextValue) != value {
+				return false
 			}
 		}
+	}
+	return true
+}
 
-		filtered = append(filtered, log)
+// DefaultSpikeThreshold is how many occurrences of the same error count as a spike when an
+// analysis request doesn't set one
+const DefaultSpikeThreshold = 5
+
+// spikeRule decides when repeated errors count as a spike
+type spikeRule struct {
+	threshold int
+	window    time.Duration // 0 counts occurrences across all analyzed logs
+}
+
+// spikeRuleFor returns the spike rule requested, falling back to DefaultSpikeThreshold
+func spikeRuleFor(req *models.LogAnalysisRequest) spikeRule {
+	rule := spikeRule{
+		threshold: req.SpikeThreshold,
+		window:    time.Duration(req.SpikeWindowMinutes) * time.Minute,
 	}
+	if rule.threshold <= 0 {
+		rule.threshold = DefaultSpikeThreshold
+	}
+	return rule
+}
 
-	// Sort by timestamp (newest first)
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].Timestamp.After(filtered[j].Timestamp)
-	})
+// peak returns the most timestamps falling within one window, given timestamps sorted oldest first
+func (r spikeRule) peak(times []time.Time) int {
+	if r.window <= 0 {
+		return len(times)
+	}
 
-	return filtered
+	peak, start := 0, 0
+	for end := range times {
+		for times[end].Sub(times[start]) > r.window {
+			start++
+		}
+		if count := end - start + 1; count > peak {
+			peak = count
+		}
+	}
+	return peak
+}
+
+// ratePerMinute returns how often an error occurred: over the spike window when one is set,
+// otherwise over the span between its first and last occurrence (at least a minute)
+func (r spikeRule) ratePerMinute(peak int, times []time.Time) float64 {
+	minutes := r.window.Minutes()
+	if r.window <= 0 {
+		minutes = math.Max(times[len(times)-1].Sub(times[0]).Minutes(), 1)
+	}
+	return math.Round(float64(peak)/minutes*100) / 100
 }
 
 // detectIssues identifies issues in the filtered logs
-func (s *LogService) detectIssues(logs []models.LogEntry) []models.LogIssue {
+func (s *LogService) detectIssues(logs []models.LogEntry, spikes spikeRule) []models.LogIssue {
 	issues := make([]models.LogIssue, 0)
 	errorCounts := make(map[string]int)
 	errorTimes := make(map[string][]time.Time)
 
 	// Count errors and track timing
 	for _, log := range logs {
-		if log.Level == "error" {
+		if s.isErrorLevel(log.Level) {
 			key := log.Message
 			if log.Component != "" {
 				key = fmt.Sprintf("%s:%s", log.Component, log.Message)
@@ -339,23 +651,39 @@ func (s *LogService) detectIssues(logs []models.LogEntry) []models.LogIssue {
 
 	// Detect error spikes
 	for errorMsg, count := range errorCounts {
-		if count >= 5 { // Threshold for error spike
-			times := errorTimes[errorMsg]
-			sort.Slice(times, func(i, j int) bool {
-				return times[i].Before(times[j])
-			})
+		if count < spikes.threshold {
+			continue
+		}
 
-			issue := models.LogIssue{
-				Type:        "error_spike",
-				Count:       count,
-				FirstSeen:   times[0],
-				LastSeen:    times[len(times)-1],
-				Description: fmt.Sprintf("High frequency of error: %s", errorMsg),
-				Severity:    s.determineSeverity(count),
-				Solution:    "Investigate the root cause of this recurring error",
-			}
-			issues = append(issues, issue)
+		times := errorTimes[errorMsg]
+		sort.Slice(times, func(i, j int) bool {
+			return times[i].Before(times[j])
+		})
+
+		// A slow trickle never reaches the threshold within one window
+		peak := spikes.peak(times)
+		if peak < spikes.threshold {
+			continue
+		}
+
+		description := fmt.Sprintf("High frequency of error: %s", errorMsg)
+		if spikes.window > 0 {
+			description += fmt.Sprintf(" (%d within %d minutes)", peak, int(spikes.window.Minutes()))
+		}
+
+		issue := models.LogIssue{
+			Type:          "error_spike",
+			Count:         count,
+			FirstSeen:     times[0],
+			LastSeen:      times[len(times)-1],
+			Description:   description,
+			Severity:      s.determineSeverity(peak),
+			Solution:      "Investigate the root cause of this recurring error",
+			PeakCount:     peak,
+			WindowMinutes: int(spikes.window.Minutes()),
+			RatePerMinute: spikes.ratePerMinute(peak, times),
 		}
+		issues = append(issues, issue)
 	}
 
 	return issues
@@ -410,6 +738,7 @@ func (s *LogService) calculateStatistics(logs []models.LogEntry) models.LogStati
 		TopComponents: make([]models.LogComponentSummary, 0),
 	}
 
+	totalErrors := 0
 	errorCounts := make(map[string]int)
 	componentCounts := make(map[string]int)
 	componentErrors := make(map[string]int)
@@ -426,14 +755,16 @@ func (s *LogService) calculateStatistics(logs []models.LogEntry) models.LogStati
 		stats.LogsByHour[hour]++
 
 		// Count errors
-		if log.Level == "error" {
+		isError := s.isErrorLevel(log.Level)
+		if isError {
+			totalErrors++
 			errorCounts[log.Message]++
 		}
 
 		// Count by component
 		if log.Component != "" {
 			componentCounts[log.Component]++
-			if log.Level == "error" {
+			if isError {
 				componentErrors[log.Component]++
 			}
 		}
@@ -441,8 +772,7 @@ func (s *LogService) calculateStatistics(logs []models.LogEntry) models.LogStati
 
 	// Calculate error rate
 	if stats.TotalLogs > 0 {
-		errorCount := stats.LogsByLevel["error"]
-		stats.ErrorRate = float64(errorCount) / float64(stats.TotalLogs) * 100
+		stats.ErrorRate = float64(totalErrors) / float64(stats.TotalLogs) * 100
 	}
 
 	// Create top errors list
@@ -499,6 +829,87 @@ func (s *LogService) calculateStatistics(logs []models.LogEntry) models.LogStati
 	return stats
 }
 
+// analyzeByComponent produces a breakdown of the logs for each component.
+// Logs without a component are grouped under "unknown".
+func (s *LogService) analyzeByComponent(logs []models.LogEntry, spikes spikeRule) []models.LogGroupAnalysis {
+	grouped := make(map[string][]models.LogEntry)
+	for _, log := range logs {
+		component := log.Component
+		if component == "" {
+			component = "unknown"
+		}
+		grouped[component] = append(grouped[component], log)
+	}
+
+	groups := make([]models.LogGroupAnalysis, 0, len(grouped))
+	for component, componentLogs := range grouped {
+		stats := s.calculateStatistics(componentLogs)
+		issues := s.detectIssues(componentLogs, spikes)
+		patterns := s.detectPatterns(componentLogs)
+
+		groups = append(groups, models.LogGroupAnalysis{
+			Group:       component,
+			TotalLogs:   stats.TotalLogs,
+			ErrorCount:  s.countErrors(componentLogs),
+			ErrorRate:   stats.ErrorRate,
+			Summary:     s.generateSummary(componentLogs, issues, patterns),
+			TopMessages: s.topMessages(componentLogs, 5),
+			Issues:      issues,
+		})
+	}
+
+	// Most problematic components first
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].ErrorCount != groups[j].ErrorCount {
+			return groups[i].ErrorCount > groups[j].ErrorCount
+		}
+		if groups[i].TotalLogs != groups[j].TotalLogs {
+			return groups[i].TotalLogs > groups[j].TotalLogs
+		}
+		return groups[i].Group < groups[j].Group
+	})
+
+	return groups
+}
+
+// topMessages returns the most frequent messages in the logs, up to limit entries
+func (s *LogService) topMessages(logs []models.LogEntry, limit int) []models.LogMessageSummary {
+	summaries := make(map[string]*models.LogMessageSummary)
+	for _, log := range logs {
+		key := log.Level + ":" + log.Message
+		summary, exists := summaries[key]
+		if !exists {
+			summary = &models.LogMessageSummary{
+				Message: log.Message,
+				Level:   log.Level,
+			}
+			summaries[key] = summary
+		}
+		summary.Count++
+		if log.Timestamp.After(summary.LastSeen) {
+			summary.LastSeen = log.Timestamp
+		}
+	}
+
+	messages := make([]models.LogMessageSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		messages = append(messages, *summary)
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Count != messages[j].Count {
+			return messages[i].Count > messages[j].Count
+		}
+		return messages[i].Message < messages[j].Message
+	})
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages
+}
+
 // generateSummary creates a summary of the log analysis
 func (s *LogService) generateSummary(logs []models.LogEntry, issues []models.LogIssue, patterns []models.LogPattern) string {
 	if len(logs) == 0 {
@@ -510,7 +921,7 @@ func (s *LogService) generateSummary(logs []models.LogEntry, issues []models.Log
 	errorCount := 0
 	warnCount := 0
 	for _, log := range logs {
-		if log.Level == "error" {
+		if s.isErrorLevel(log.Level) {
 			errorCount++
 		} else if log.Level == "warn" {
 			warnCount++
@@ -560,13 +971,8 @@ func (s *LogService) generateSuggestions(issues []models.LogIssue, patterns []mo
 // performAIAnalysis performs AI-enhanced log analysis
 func (s *LogService) performAIAnalysis(ctx context.Context, logs []models.LogEntry) (*models.AILogAnalysisResponse, error) {
 	// Limit logs for AI analysis to avoid token limits
-	analysisLogs := logs
-	if len(logs) > 20 {
-		analysisLogs = logs[:20]
-	}
-
 	aiReq := &models.AILogAnalysisRequest{
-		Logs:         analysisLogs,
+		Logs:         s.sampleAILogs(logs),
 		AnalysisType: "error_detection",
 	}
 
@@ -575,44 +981,201 @@ func (s *LogService) performAIAnalysis(ctx context.Context, logs []models.LogEnt
 
 // isCriticalLogEvent determines if a log event is critical
 func (s *LogService) isCriticalLogEvent(log *models.LogEntry) bool {
-	// Critical conditions
-	if log.Level == "error" {
-		return true
-	}
+	_, critical := s.matchCriticalRule(log)
+	return critical
+}
 
-	// Check for critical keywords in message
-	criticalKeywords := []string{
-		"panic", "fatal", "crash", "security", "breach", "unauthorized",
-		"database connection", "out of memory", "disk full",
+// matchCriticalRule returns the rule that makes a log critical: the error level itself,
+// or the first keyword rule whose keyword the message contains
+func (s *LogService) matchCriticalRule(log *models.LogEntry) (map[string]interface{}, bool) {
+	if s.isErrorLevel(log.Level) {
+		return map[string]interface{}{
+			"type":  "level",
+			"level": s.config.ErrorLevel,
+		}, true
 	}
 
 	message := strings.ToLower(log.Message)
-	for _, keyword := range criticalKeywords {
-		if strings.Contains(message, keyword) {
-			return true
+
+	s.alertRulesMu.RLock()
+	defer s.alertRulesMu.RUnlock()
+
+	for _, rule := range s.alertRules {
+		if rule.MinLevel != "" && !s.isAtLeastLevel(log.Level, rule.MinLevel) {
+			continue
+		}
+		if strings.Contains(message, rule.Keyword) {
+			match := map[string]interface{}{
+				"type":    "keyword",
+				"keyword": rule.Keyword,
+			}
+			if rule.MinLevel != "" {
+				match["min_level"] = rule.MinLevel
+			}
+			return match, true
 		}
 	}
 
-	return false
+	return nil, false
 }
 
-// sendCriticalLogAlert sends a WebSocket notification for critical log events
-func (s *LogService) sendCriticalLogAlert(log *models.LogEntry) {
-	if s.wsHub == nil {
-		return
+// GetAlertRules returns the current critical-log keyword rules
+func (s *LogService) GetAlertRules() []models.LogAlertRule {
+	s.alertRulesMu.RLock()
+	defer s.alertRulesMu.RUnlock()
+
+	return append([]models.LogAlertRule(nil), s.alertRules...)
+}
+
+// AddAlertRule adds a keyword rule, replacing the minimum level of an existing rule for the same keyword
+func (s *LogService) AddAlertRule(rule models.LogAlertRule) error {
+	rule, err := s.normalizeAlertRule(rule)
+	if err != nil {
+		return err
+	}
+
+	s.alertRulesMu.Lock()
+	defer s.alertRulesMu.Unlock()
+
+	for i := range s.alertRules {
+		if s.alertRules[i].Keyword == rule.Keyword {
+			s.alertRules[i] = rule
+			return nil
+		}
+	}
+	s.alertRules = append(s.alertRules, rule)
+	return nil
+}
+
+// ReplaceAlertRules replaces every keyword rule with rules. Nothing changes if any rule is invalid.
+// A later rule for the same keyword replaces an earlier one, as with AddAlertRule.
+func (s *LogService) ReplaceAlertRules(rules []models.LogAlertRule) error {
+	replaced := make([]models.LogAlertRule, 0, len(rules))
+	positions := make(map[string]int, len(rules))
+	for _, rule := range rules {
+		rule, err := s.normalizeAlertRule(rule)
+		if err != nil {
+			return err
+		}
+		if i, exists := positions[rule.Keyword]; exists {
+			replaced[i] = rule
+			continue
+		}
+		positions[rule.Keyword] = len(replaced)
+		replaced = append(replaced, rule)
 	}
 
+	s.alertRulesMu.Lock()
+	defer s.alertRulesMu.Unlock()
+	s.alertRules = replaced
+	return nil
+}
+
+// normalizeAlertRule validates a keyword rule and normalizes its keyword
+func (s *LogService) normalizeAlertRule(rule models.LogAlertRule) (models.LogAlertRule, error) {
+	rule.Keyword = normalizeAlertKeyword(rule.Keyword)
+	if rule.Keyword == "" {
+		return rule, fmt.Errorf("%w: keyword is required", ErrInvalidAlertRule)
+	}
+	if rule.MinLevel != "" {
+		if _, exists := s.levelRank[rule.MinLevel]; !exists {
+			return rule, fmt.Errorf("%w: unknown level %q", ErrInvalidAlertRule, rule.MinLevel)
+		}
+	}
+	return rule, nil
+}
+
+// RemoveAlertRule removes the keyword rule for keyword
+func (s *LogService) RemoveAlertRule(keyword string) error {
+	keyword = normalizeAlertKeyword(keyword)
+
+	s.alertRulesMu.Lock()
+	defer s.alertRulesMu.Unlock()
+
+	for i := range s.alertRules {
+		if s.alertRules[i].Keyword == keyword {
+			s.alertRules = append(s.alertRules[:i], s.alertRules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrAlertRuleNotFound, keyword)
+}
+
+// normalizeAlertKeyword lowercases a keyword so it matches messages case-insensitively
+func normalizeAlertKeyword(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
+
+// isErrorLevel reports whether a level is at least as severe as the configured error level
+func (s *LogService) isErrorLevel(level string) bool {
+	return s.isAtLeastLevel(level, s.config.ErrorLevel)
+}
+
+// isAtLeastLevel reports whether level is at least as severe as minLevel
+func (s *LogService) isAtLeastLevel(level, minLevel string) bool {
+	rank, exists := s.levelRank[level]
+	return exists && rank <= s.levelRank[minLevel]
+}
+
+// countErrors returns the number of logs at error severity or above
+func (s *LogService) countErrors(logs []models.LogEntry) int {
+	count := 0
+	for _, log := range logs {
+		if s.isErrorLevel(log.Level) {
+			count++
+		}
+	}
+	return count
+}
+
+// GetLogLevels returns the accepted log levels ordered from most to least severe
+func (s *LogService) GetLogLevels() []string {
+	return append([]string(nil), s.config.Levels...)
+}
+
+// SendSyntheticAlert raises a critical log alert for a made-up log entry with the given message,
+// without storing the entry, and returns the alert's ID. The alert is broadcast, tracked and
+// escalated like a real one, so alerting can be tested end to end.
+func (s *LogService) SendSyntheticAlert(ctx context.Context, message string) string {
+	entry := &models.LogEntry{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now(),
+		Level:     s.config.ErrorLevel,
+		Message:   message,
+		Source:    "backend",
+		Component: "chaos",
+	}
+	return s.sendCriticalLogAlert(ctx, entry, map[string]interface{}{"type": "synthetic"})
+}
+
+// sendCriticalLogAlert sends a WebSocket notification for critical log events and tracks the alert
+// until it is acknowledged. It returns the alert's ID.
+func (s *LogService) sendCriticalLogAlert(ctx context.Context, log *models.LogEntry, matchedRule map[string]interface{}) string {
+	alertID := uuid.New().String()
 	alert := map[string]interface{}{
-		"type":        "critical_log_event",
-		"log_id":      log.ID,
-		"level":       log.Level,
-		"source":      log.Source,
-		"message":     log.Message,
-		"component":   log.Component,
-		"timestamp":   log.Timestamp,
-		"stack_trace": log.StackTrace,
+		"type":         "critical_log_event",
+		"alert_id":     alertID,
+		"log_id":       log.ID,
+		"level":        log.Level,
+		"source":       log.Source,
+		"message":      log.Message,
+		"component":    log.Component,
+		"timestamp":    log.Timestamp,
+		"stack_trace":  log.StackTrace,
+		"matched_rule": matchedRule,
 	}
 
+	if s.config.PropagateTraceID {
+		if traceID := utils.TraceIDFromContext(ctx); traceID != "" {
+			alert["trace_id"] = traceID
+		}
+	}
+
+	s.trackCriticalAlert(alertID, log.ID, alert)
+
+	if s.wsHub == nil {
+		return alertID
+	}
 	s.wsHub.BroadcastToAll("log_alert", alert)
 
 	s.logger.Warn("Critical log event detected and broadcasted", map[string]interface{}{
@@ -621,25 +1184,45 @@ func (s *LogService) sendCriticalLogAlert(log *models.LogEntry) {
 		"source":    log.Source,
 		"component": log.Component,
 	})
-}
 
-// extractPattern extracts a pattern from a log message (simplified)
-func (s *LogService) extractPattern(message string) string {
-	// Simple pattern extraction - replace numbers and IDs with placeholders
-	pattern := message
+	return alertID
+}
 
-	// Replace UUIDs
-	pattern = strings.ReplaceAll(pattern, uuid.New().String(), "[UUID]")
+// Log message tokens collapsed by extractPattern, applied in order so that e.g. the digits
+// of a timestamp are not first turned into separate numbers
+var (
+	timestampToken = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?\b|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`)
+	uuidToken      = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	ipv4Token      = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Token      = regexp.MustCompile(`[0-9a-fA-F]*:[0-9a-fA-F:.]*[0-9a-fA-F]`)
+	hexToken       = regexp.MustCompile(`\b(?:0[xX][0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+	numberToken    = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
 
-	// Replace numbers (simplified)
-	words := strings.Fields(pattern)
-	for i, word := range words {
-		if len(word) > 0 && (word[0] >= '0' && word[0] <= '9') {
-			words[i] = "[NUMBER]"
+// extractPattern normalizes a log message into a pattern by replacing variable parts
+// (timestamps, UUIDs, IP addresses, hex IDs and numbers) with placeholders, so that
+// "user 123 logged in" and "user 456 logged in" share a pattern
+func (s *LogService) extractPattern(message string) string {
+	pattern := timestampToken.ReplaceAllString(message, "[TIMESTAMP]")
+	pattern = uuidToken.ReplaceAllString(pattern, "[UUID]")
+	pattern = ipv4Token.ReplaceAllString(pattern, "[IP]")
+	pattern = ipv6Token.ReplaceAllStringFunc(pattern, func(token string) string {
+		if addr, err := netip.ParseAddr(token); err == nil && addr.Is6() {
+			return "[IP]"
 		}
-	}
+		return token
+	})
+	pattern = hexToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		// Long runs of only digits are numbers, and runs of only letters are words
+		if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") ||
+			(strings.ContainsAny(token, "0123456789") && strings.ContainsAny(token, "abcdefABCDEF")) {
+			return "[HEX]"
+		}
+		return token
+	})
+	pattern = numberToken.ReplaceAllString(pattern, "[NUMBER]")
 
-	return strings.Join(words, " ")
+	return strings.Join(strings.Fields(pattern), " ")
 }
 
 // determineSeverity determines the severity based on error count
@@ -654,17 +1237,23 @@ func (s *LogService) determineSeverity(count int) string {
 	return "low"
 }
 
-// GetLogCount returns the total number of stored logs
+// GetLogCount returns the total number of stored and buffered logs, or 0 if the store can't be read
 func (s *LogService) GetLogCount() int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return len(s.logs)
+	count, err := s.store.Count()
+	if err != nil {
+		s.logger.Error("Failed to count stored logs", err, nil)
+		return 0
+	}
+	buffered, _ := s.fallback.Count()
+	return count + buffered
 }
 
 // ClearLogs clears all stored logs (for testing or maintenance)
-func (s *LogService) ClearLogs() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.logs = make([]models.LogEntry, 0)
+func (s *LogService) ClearLogs() error {
+	if err := s.store.Clear(); err != nil {
+		return err
+	}
+	s.fallback.Clear()
 	s.logger.Info("All logs cleared", nil)
+	return nil
 }
@@ -2,13 +2,16 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockAIService is a mock implementation of AIServiceInterface for testing
@@ -35,7 +38,7 @@ func TestNewLogService(t *testing.T) {
 	assert.NotNil(t, service)
 	assert.Equal(t, mockAI, service.aiService)
 	assert.Equal(t, hub, service.wsHub)
-	assert.NotNil(t, service.logs)
+	assert.NotNil(t, service.store)
 	assert.NotNil(t, service.alerts)
 	assert.NotNil(t, service.logger)
 }
@@ -50,6 +53,8 @@ func TestLogService_SubmitLogs(t *testing.T) {
 		request        *models.LogSubmissionRequest
 		expectedAccept int
 		expectedReject int
+		expectedStatus string
+		expectedErrors []models.LogEntryError
 		expectError    bool
 	}{
 		{
@@ -68,6 +73,7 @@ func TestLogService_SubmitLogs(t *testing.T) {
 			},
 			expectedAccept: 1,
 			expectedReject: 0,
+			expectedStatus: models.LogSubmissionAllAccepted,
 			expectError:    false,
 		},
 		{
@@ -86,6 +92,8 @@ func TestLogService_SubmitLogs(t *testing.T) {
 			},
 			expectedAccept: 0,
 			expectedReject: 1,
+			expectedStatus: models.LogSubmissionAllRejected,
+			expectedErrors: []models.LogEntryError{{Index: 0, Field: "message", Reason: "message is required"}},
 			expectError:    false,
 		},
 		{
@@ -111,6 +119,8 @@ func TestLogService_SubmitLogs(t *testing.T) {
 			},
 			expectedAccept: 1,
 			expectedReject: 1,
+			expectedStatus: models.LogSubmissionPartial,
+			expectedErrors: []models.LogEntryError{{Index: 1, Field: "level", Reason: "invalid level: invalid"}},
 			expectError:    false,
 		},
 	}
@@ -128,6 +138,9 @@ func TestLogService_SubmitLogs(t *testing.T) {
 				assert.NotNil(t, response)
 				assert.Equal(t, tt.expectedAccept, response.Accepted)
 				assert.Equal(t, tt.expectedReject, response.Rejected)
+				assert.Equal(t, tt.expectedStatus, response.Status)
+				assert.Equal(t, tt.expectedStatus == models.LogSubmissionPartial, response.Partial)
+				assert.ElementsMatch(t, tt.expectedErrors, response.Errors)
 				assert.NotEmpty(t, response.BatchID)
 				assert.NotZero(t, response.ProcessedAt)
 			}
@@ -267,6 +280,184 @@ func TestLogService_AnalyzeLogs(t *testing.T) {
 	}
 }
 
+func TestLogService_AnalyzeLogs_GroupByComponent(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+	hub := websocket.NewHub()
+	service := NewLogService(mockAI, hub)
+
+	now := time.Now()
+	seedLogs(t, service, []models.LogEntry{
+		{ID: "1", Timestamp: now.Add(-5 * time.Minute), Level: "error", Source: "backend", Message: "Payment declined", Component: "payments"},
+		{ID: "2", Timestamp: now.Add(-4 * time.Minute), Level: "error", Source: "backend", Message: "Payment declined", Component: "payments"},
+		{ID: "3", Timestamp: now.Add(-3 * time.Minute), Level: "info", Source: "backend", Message: "Payment processed", Component: "payments"},
+		{ID: "4", Timestamp: now.Add(-2 * time.Minute), Level: "info", Source: "frontend", Message: "User logged in", Component: "auth"},
+		{ID: "5", Timestamp: now.Add(-1 * time.Minute), Level: "warn", Source: "frontend", Message: "Slow render"},
+	})
+
+	t.Run("groups are omitted by default", func(t *testing.T) {
+		response, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+		assert.NoError(t, err)
+		assert.Empty(t, response.GroupBy)
+		assert.Nil(t, response.Groups)
+	})
+
+	t.Run("per-component breakdown", func(t *testing.T) {
+		response, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{
+			Limit:   100,
+			GroupBy: models.LogGroupByComponent,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, models.LogGroupByComponent, response.GroupBy)
+		assert.Len(t, response.Groups, 3)
+
+		// Component with the most errors comes first
+		payments := response.Groups[0]
+		assert.Equal(t, "payments", payments.Group)
+		assert.Equal(t, 3, payments.TotalLogs)
+		assert.Equal(t, 2, payments.ErrorCount)
+		assert.InDelta(t, 66.67, payments.ErrorRate, 0.01)
+		assert.NotEmpty(t, payments.Summary)
+		assert.Equal(t, "Payment declined", payments.TopMessages[0].Message)
+		assert.Equal(t, 2, payments.TopMessages[0].Count)
+		assert.Equal(t, now.Add(-4*time.Minute), payments.TopMessages[0].LastSeen)
+
+		groups := make([]string, 0, len(response.Groups))
+		for _, group := range response.Groups {
+			groups = append(groups, group.Group)
+		}
+		assert.ElementsMatch(t, []string{"payments", "auth", "unknown"}, groups)
+	})
+}
+
+func TestLogService_AnalyzeLogs_MaxAnalysisRange(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+	config := DefaultLogServiceConfig()
+	config.MaxAnalysisRange = 24 * time.Hour
+	service := NewLogService(mockAI, websocket.NewHub(), config)
+
+	now := time.Now()
+	seedLogs(t, service, []models.LogEntry{
+		{ID: "old", Timestamp: now.Add(-72 * time.Hour), Level: "error", Source: "backend", Message: "Old failure"},
+		{ID: "recent", Timestamp: now.Add(-1 * time.Hour), Level: "error", Source: "backend", Message: "Recent failure"},
+	})
+
+	t.Run("open-ended range is narrowed", func(t *testing.T) {
+		response, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, response.Statistics.TotalLogs)
+		require.NotNil(t, response.TimeRange)
+		assert.WithinDuration(t, now.Add(-24*time.Hour), response.TimeRange.Start, time.Minute)
+	})
+
+	t.Run("range within the limit is unchanged", func(t *testing.T) {
+		response, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{
+			TimeRange: models.TimeRange{Start: now.Add(-2 * time.Hour), End: now},
+			Limit:     100,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, response.Statistics.TotalLogs)
+		assert.Nil(t, response.TimeRange)
+	})
+
+	t.Run("range wider than the limit is rejected", func(t *testing.T) {
+		req := &models.LogAnalysisRequest{TimeRange: models.TimeRange{Start: now.Add(-96 * time.Hour)}, Limit: 100}
+
+		response, err := service.AnalyzeLogs(context.Background(), req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, ErrAnalysisRangeTooWide)
+		assert.Contains(t, err.Error(), "24h0m0s")
+
+		_, err = service.CreateAnalysisReport(context.Background(), req)
+		assert.ErrorIs(t, err, ErrAnalysisRangeTooWide)
+	})
+
+	t.Run("no limit by default", func(t *testing.T) {
+		unlimited := NewLogService(mockAI, websocket.NewHub())
+		stored, err := service.store.Query(LogFilter{})
+		require.NoError(t, err)
+		seedLogs(t, unlimited, stored)
+
+		response, err := unlimited.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{
+			TimeRange: models.TimeRange{Start: now.Add(-96 * time.Hour)},
+			Limit:     100,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Statistics.TotalLogs)
+	})
+}
+
+func TestLogService_AnalysisReports(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+	service := NewLogService(mockAI, nil)
+
+	seedLogs(t, service, []models.LogEntry{
+		{ID: "1", Timestamp: time.Now(), Level: "error", Source: "backend", Message: "Payment declined", Component: "payments"},
+		{ID: "2", Timestamp: time.Now(), Level: "info", Source: "backend", Message: "Payment processed", Component: "payments"},
+	})
+
+	t.Run("report is frozen at creation time", func(t *testing.T) {
+		report, err := service.CreateAnalysisReport(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, report.ID)
+		assert.Equal(t, 2, report.Analysis.Statistics.TotalLogs)
+		assert.Equal(t, 100, report.Request.Limit)
+
+		require.NoError(t, service.store.Append(models.LogEntry{
+			ID: "3", Timestamp: time.Now(), Level: "error", Source: "frontend", Message: "Render failed",
+		}))
+
+		stored, err := service.GetAnalysisReport(report.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, stored.Analysis.Statistics.TotalLogs)
+		assert.Equal(t, report.CreatedAt, stored.CreatedAt)
+	})
+
+	t.Run("unknown report", func(t *testing.T) {
+		report, err := service.GetAnalysisReport("missing")
+		assert.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("oldest reports are evicted", func(t *testing.T) {
+		first, err := service.CreateAnalysisReport(context.Background(), &models.LogAnalysisRequest{Limit: 10})
+		assert.NoError(t, err)
+
+		for i := 0; i < maxStoredReports; i++ {
+			_, err := service.CreateAnalysisReport(context.Background(), &models.LogAnalysisRequest{Limit: 10})
+			assert.NoError(t, err)
+		}
+
+		_, err = service.GetAnalysisReport(first.ID)
+		assert.Error(t, err)
+		assert.Len(t, service.reports, maxStoredReports)
+		assert.Len(t, service.reportOrder, maxStoredReports)
+	})
+}
+
+func TestLogService_TopMessages(t *testing.T) {
+	service := NewLogService(&MockAIService{}, websocket.NewHub())
+
+	logs := []models.LogEntry{
+		{Level: "error", Message: "Timeout"},
+		{Level: "error", Message: "Timeout"},
+		{Level: "warn", Message: "Timeout"},
+		{Level: "info", Message: "Started"},
+		{Level: "info", Message: "Stopped"},
+	}
+
+	messages := service.topMessages(logs, 2)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "Timeout", messages[0].Message)
+	assert.Equal(t, "error", messages[0].Level)
+	assert.Equal(t, 2, messages[0].Count)
+}
+
 func TestLogService_FilterLogs(t *testing.T) {
 	mockAI := &MockAIService{}
 	hub := websocket.NewHub()
@@ -301,7 +492,7 @@ func TestLogService_FilterLogs(t *testing.T) {
 		},
 	}
 
-	service.logs = testLogs
+	seedLogs(t, service, testLogs)
 
 	tests := []struct {
 		name           string
@@ -369,7 +560,8 @@ func TestLogService_FilterLogs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filtered := service.filterLogs(tt.request)
+			filtered, _, err := service.filterLogs(tt.request)
+			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedCount, len(filtered))
 
@@ -384,6 +576,111 @@ func TestLogService_FilterLogs(t *testing.T) {
 	}
 }
 
+func TestLogService_AnalyzeLogs_WorstSeverity(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+	service := NewLogService(mockAI, nil)
+
+	response, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, models.LogSeverityNone, response.WorstSeverity)
+
+	now := time.Now()
+	entries := make([]models.LogEntry, 0, 20)
+	for i := 0; i < 20; i++ {
+		entries = append(entries, models.LogEntry{
+			ID: fmt.Sprintf("log-%d", i), Timestamp: now.Add(-time.Duration(i) * time.Second), Level: "error", Source: "backend", Message: "Database connection failed",
+		})
+	}
+	seedLogs(t, service, entries)
+
+	response, err = service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Issues)
+	assert.Equal(t, "critical", response.WorstSeverity)
+
+	response, err = service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "medium", response.WorstSeverity)
+}
+
+func TestLogService_AnalyzeLogs_Pagination(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+	service := NewLogService(mockAI, nil)
+
+	now := time.Now()
+	entries := make([]models.LogEntry, 0, 10)
+	for i := 0; i < 10; i++ {
+		level := "info"
+		if i%2 == 0 {
+			level = "error"
+		}
+		entries = append(entries, models.LogEntry{
+			ID: fmt.Sprintf("log-%d", i), Timestamp: now.Add(time.Duration(i) * time.Minute), Level: level, Source: "backend", Message: "Message",
+		})
+	}
+	seedLogs(t, service, entries)
+
+	tests := []struct {
+		name          string
+		request       *models.LogAnalysisRequest
+		expectedTotal int
+		expectedIDs   []string
+	}{
+		{
+			name:          "first page",
+			request:       &models.LogAnalysisRequest{Limit: 3},
+			expectedTotal: 10,
+			expectedIDs:   []string{"log-9", "log-8", "log-7"},
+		},
+		{
+			name:          "second page",
+			request:       &models.LogAnalysisRequest{Limit: 3, Offset: 3},
+			expectedTotal: 10,
+			expectedIDs:   []string{"log-6", "log-5", "log-4"},
+		},
+		{
+			name:          "last partial page",
+			request:       &models.LogAnalysisRequest{Limit: 3, Offset: 9},
+			expectedTotal: 10,
+			expectedIDs:   []string{"log-0"},
+		},
+		{
+			name:          "offset past the end",
+			request:       &models.LogAnalysisRequest{Limit: 3, Offset: 20},
+			expectedTotal: 10,
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "total counts filtered matches",
+			request:       &models.LogAnalysisRequest{Levels: []string{"error"}, Limit: 2, Offset: 1},
+			expectedTotal: 5,
+			expectedIDs:   []string{"log-6", "log-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := service.filterLogs(tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, total)
+
+			ids := make([]string, len(page))
+			for i, entry := range page {
+				ids[i] = entry.ID
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+
+			response, err := service.AnalyzeLogs(context.Background(), tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, response.TotalMatched)
+			assert.Equal(t, len(tt.expectedIDs), response.ReturnedCount)
+			assert.Equal(t, len(tt.expectedIDs), response.Statistics.TotalLogs)
+		})
+	}
+}
+
 func TestLogService_DetectIssues(t *testing.T) {
 	mockAI := &MockAIService{}
 	hub := websocket.NewHub()
@@ -400,12 +697,98 @@ func TestLogService_DetectIssues(t *testing.T) {
 		{ID: "6", Level: "info", Message: "Normal operation", Timestamp: now},
 	}
 
-	issues := service.detectIssues(testLogs)
+	issues := service.detectIssues(testLogs, spikeRuleFor(&models.LogAnalysisRequest{}))
 
 	assert.Len(t, issues, 1)
 	assert.Equal(t, "error_spike", issues[0].Type)
 	assert.Equal(t, 5, issues[0].Count)
 	assert.Contains(t, issues[0].Description, "Database error")
+	assert.Equal(t, 5, issues[0].PeakCount)
+	assert.Zero(t, issues[0].WindowMinutes)
+	assert.Equal(t, 1.25, issues[0].RatePerMinute) // 5 errors over 4 minutes
+}
+
+func TestLogService_DetectIssues_SpikeWindow(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+	now := time.Now()
+
+	// errorsEvery returns count "Database error" logs spaced interval apart, ending now
+	errorsEvery := func(count int, interval time.Duration) []models.LogEntry {
+		logs := make([]models.LogEntry, count)
+		for i := range logs {
+			logs[i] = models.LogEntry{
+				ID:        fmt.Sprintf("%d", i),
+				Level:     "error",
+				Message:   "Database error",
+				Timestamp: now.Add(-time.Duration(count-1-i) * interval),
+			}
+		}
+		return logs
+	}
+
+	tests := []struct {
+		name          string
+		logs          []models.LogEntry
+		request       models.LogAnalysisRequest
+		expectSpike   bool
+		expectedPeak  int
+		expectedRate  float64
+		expectedCount int
+	}{
+		{
+			name:          "burst within the window triggers",
+			logs:          errorsEvery(6, 10*time.Second),
+			request:       models.LogAnalysisRequest{SpikeThreshold: 5, SpikeWindowMinutes: 1},
+			expectSpike:   true,
+			expectedPeak:  6,
+			expectedRate:  6,
+			expectedCount: 6,
+		},
+		{
+			name:    "slow trickle does not trigger",
+			logs:    errorsEvery(20, 2*time.Hour),
+			request: models.LogAnalysisRequest{SpikeThreshold: 5, SpikeWindowMinutes: 10},
+		},
+		{
+			name:          "trickle followed by a burst triggers on the burst",
+			logs:          append(errorsEvery(4, time.Hour)[:3], errorsEvery(5, 30*time.Second)...),
+			request:       models.LogAnalysisRequest{SpikeThreshold: 5, SpikeWindowMinutes: 5},
+			expectSpike:   true,
+			expectedPeak:  5,
+			expectedRate:  1,
+			expectedCount: 8,
+		},
+		{
+			name:    "higher threshold",
+			logs:    errorsEvery(6, 10*time.Second),
+			request: models.LogAnalysisRequest{SpikeThreshold: 10, SpikeWindowMinutes: 1},
+		},
+		{
+			name:          "no window keeps counting across all logs",
+			logs:          errorsEvery(5, 2*time.Hour),
+			request:       models.LogAnalysisRequest{},
+			expectSpike:   true,
+			expectedPeak:  5,
+			expectedRate:  0.01, // 5 errors over 8 hours
+			expectedCount: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := service.detectIssues(tt.logs, spikeRuleFor(&tt.request))
+
+			if !tt.expectSpike {
+				assert.Empty(t, issues)
+				return
+			}
+			require.Len(t, issues, 1)
+			assert.Equal(t, tt.expectedCount, issues[0].Count)
+			assert.Equal(t, tt.expectedPeak, issues[0].PeakCount)
+			assert.Equal(t, tt.request.SpikeWindowMinutes, issues[0].WindowMinutes)
+			assert.Equal(t, tt.expectedRate, issues[0].RatePerMinute)
+		})
+	}
 }
 
 func TestLogService_DetectPatterns(t *testing.T) {
@@ -429,6 +812,54 @@ func TestLogService_DetectPatterns(t *testing.T) {
 	assert.Equal(t, 3, patterns[0].Frequency)
 }
 
+func TestLogService_ExtractPattern(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{name: "plain message", message: "User login attempt", expected: "User login attempt"},
+		{name: "number", message: "user 123 logged in", expected: "user [NUMBER] logged in"},
+		{name: "embedded number", message: "retry3 failed after 1.5s", expected: "retry[NUMBER] failed after [NUMBER]s"},
+		{name: "UUID", message: "order 550e8400-e29b-41d4-a716-446655440000 shipped", expected: "order [UUID] shipped"},
+		{name: "IPv4 address", message: "connection from 203.0.113.7:54321 refused", expected: "connection from [IP]:[NUMBER] refused"},
+		{name: "IPv6 address", message: "connection from 2001:db8::1 refused", expected: "connection from [IP] refused"},
+		{name: "hex ID", message: "commit 9fceb02d0ae598e9 deployed", expected: "commit [HEX] deployed"},
+		{name: "0x-prefixed hex", message: "segfault at 0x7ffd", expected: "segfault at [HEX]"},
+		{name: "hex-like words are kept", message: "feedface cafebabe", expected: "feedface cafebabe"},
+		{name: "ISO timestamp", message: "job started at 2024-01-15T10:30:00.123Z", expected: "job started at [TIMESTAMP]"},
+		{name: "timestamp with offset", message: "since 2024-01-15 10:30:00+02:00 ok", expected: "since [TIMESTAMP] ok"},
+		{name: "date", message: "report for 2024-01-15", expected: "report for [TIMESTAMP]"},
+		{name: "time of day", message: "cron ran at 04:00:00", expected: "cron ran at [TIMESTAMP]"},
+		{name: "whitespace is collapsed", message: "  too   many\tspaces ", expected: "too many spaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.extractPattern(tt.message))
+		})
+	}
+}
+
+func TestLogService_DetectPatterns_NormalizesVariableParts(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil)
+
+	now := time.Now()
+	testLogs := []models.LogEntry{
+		{ID: "1", Message: "user 123 logged in from 203.0.113.7", Timestamp: now.Add(-3 * time.Minute)},
+		{ID: "2", Message: "user 456 logged in from 198.51.100.20", Timestamp: now.Add(-2 * time.Minute)},
+		{ID: "3", Message: "user 789 logged in from 203.0.113.9", Timestamp: now.Add(-1 * time.Minute)},
+	}
+
+	patterns := service.detectPatterns(testLogs)
+
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "user [NUMBER] logged in from [IP]", patterns[0].Pattern)
+	assert.Equal(t, 3, patterns[0].Frequency)
+}
+
 func TestLogService_CalculateStatistics(t *testing.T) {
 	mockAI := &MockAIService{}
 	hub := websocket.NewHub()
@@ -508,6 +939,107 @@ func TestLogService_IsCriticalLogEvent(t *testing.T) {
 	}
 }
 
+func TestLogService_SendCriticalLogAlert_TraceID(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        []LogServiceConfig
+		expectTraceID bool
+	}{
+		{"default config propagates trace ID", nil, true},
+		{"propagation disabled", []LogServiceConfig{{PropagateTraceID: false}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var alert map[string]interface{}
+			mockHub := &MockWebSocketHub{}
+			mockHub.On("BroadcastToAll", "log_alert", mock.Anything).Run(func(args mock.Arguments) {
+				alert = args.Get(1).(map[string]interface{})
+			}).Return()
+
+			service := NewLogService(&MockAIService{}, mockHub, tt.config...)
+
+			ctx := utils.ContextWithTraceID(context.Background(), "trace-456")
+			_, err := service.SubmitLogs(ctx, &models.LogSubmissionRequest{
+				Source: "backend",
+				Logs: []models.LogEntry{
+					{Level: "error", Message: "Fatal error in payment flow", Source: "backend"},
+				},
+			})
+			assert.NoError(t, err)
+
+			assert.NotNil(t, alert)
+			if tt.expectTraceID {
+				assert.Equal(t, "trace-456", alert["trace_id"])
+			} else {
+				assert.NotContains(t, alert, "trace_id")
+			}
+		})
+	}
+}
+
+func TestLogService_AlertRules(t *testing.T) {
+	service := NewLogService(&MockAIService{}, nil, LogServiceConfig{
+		PropagateTraceID: true,
+		AlertKeywords:    []string{"Disk Full", " panic "},
+	})
+
+	assert.Equal(t, []models.LogAlertRule{{Keyword: "disk full"}, {Keyword: "panic"}}, service.GetAlertRules())
+	assert.False(t, service.isCriticalLogEvent(&models.LogEntry{Level: "info", Message: "Security breach detected"}))
+	assert.True(t, service.isCriticalLogEvent(&models.LogEntry{Level: "debug", Message: "DISK FULL on /var"}))
+
+	t.Run("added rules respect the minimum level", func(t *testing.T) {
+		require.NoError(t, service.AddAlertRule(models.LogAlertRule{Keyword: "Retry Exhausted", MinLevel: "warn"}))
+
+		assert.True(t, service.isCriticalLogEvent(&models.LogEntry{Level: "warn", Message: "retry exhausted for job 7"}))
+		assert.False(t, service.isCriticalLogEvent(&models.LogEntry{Level: "info", Message: "retry exhausted for job 7"}))
+	})
+
+	t.Run("adding an existing keyword replaces its level", func(t *testing.T) {
+		require.NoError(t, service.AddAlertRule(models.LogAlertRule{Keyword: "retry exhausted"}))
+
+		assert.Len(t, service.GetAlertRules(), 3)
+		assert.True(t, service.isCriticalLogEvent(&models.LogEntry{Level: "info", Message: "retry exhausted for job 7"}))
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		assert.ErrorIs(t, service.AddAlertRule(models.LogAlertRule{Keyword: "  "}), ErrInvalidAlertRule)
+		assert.ErrorIs(t, service.AddAlertRule(models.LogAlertRule{Keyword: "timeout", MinLevel: "loud"}), ErrInvalidAlertRule)
+	})
+
+	t.Run("removed rules stop matching", func(t *testing.T) {
+		require.NoError(t, service.RemoveAlertRule("PANIC"))
+
+		assert.False(t, service.isCriticalLogEvent(&models.LogEntry{Level: "info", Message: "Application panic occurred"}))
+		assert.ErrorIs(t, service.RemoveAlertRule("panic"), ErrAlertRuleNotFound)
+	})
+}
+
+func TestLogService_SendCriticalLogAlert_MatchedRule(t *testing.T) {
+	var alerts []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "log_alert", mock.Anything).Run(func(args mock.Arguments) {
+		alerts = append(alerts, args.Get(1).(map[string]interface{}))
+	}).Return()
+
+	service := NewLogService(&MockAIService{}, mockHub)
+	require.NoError(t, service.AddAlertRule(models.LogAlertRule{Keyword: "quota", MinLevel: "warn"}))
+
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs: []models.LogEntry{
+			{Level: "error", Message: "Payment failed", Source: "backend"},
+			{Level: "warn", Message: "Quota nearly used", Source: "backend"},
+			{Level: "info", Message: "Quota checked", Source: "backend"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, alerts, 2)
+	assert.Equal(t, map[string]interface{}{"type": "level", "level": "error"}, alerts[0]["matched_rule"])
+	assert.Equal(t, map[string]interface{}{"type": "keyword", "keyword": "quota", "min_level": "warn"}, alerts[1]["matched_rule"])
+}
+
 func TestLogService_ValidateLogEntry(t *testing.T) {
 	mockAI := &MockAIService{}
 	hub := websocket.NewHub()
@@ -577,6 +1109,65 @@ func TestLogService_ValidateLogEntry(t *testing.T) {
 	}
 }
 
+func TestLogService_CustomLevels(t *testing.T) {
+	mockAI := &MockAIService{}
+	mockAI.On("IsAvailable").Return(false)
+
+	var alerts []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "log_alert", mock.Anything).Run(func(args mock.Arguments) {
+		alerts = append(alerts, args.Get(1).(map[string]interface{}))
+	}).Return()
+
+	service := NewLogService(mockAI, mockHub, LogServiceConfig{
+		Levels:     []string{"fatal", "error", "warn", "notice", "info", "debug"},
+		ErrorLevel: "error",
+	})
+
+	response, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs: []models.LogEntry{
+			{Level: "fatal", Message: "Worker stopped", Source: "backend", Component: "jobs"},
+			{Level: "error", Message: "Retry failed", Source: "backend", Component: "jobs"},
+			{Level: "notice", Message: "Config reloaded", Source: "backend", Component: "jobs"},
+			{Level: "info", Message: "Job started", Source: "backend", Component: "jobs"},
+			{Level: "trace", Message: "Not configured", Source: "backend"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, response.Accepted)
+	assert.Equal(t, 1, response.Rejected)
+
+	// Both fatal and error are at or above the error level
+	assert.Len(t, alerts, 2)
+	assert.True(t, service.isErrorLevel("fatal"))
+	assert.True(t, service.isErrorLevel("error"))
+	assert.False(t, service.isErrorLevel("notice"))
+	assert.False(t, service.isErrorLevel("unknown"))
+
+	analysis, err := service.AnalyzeLogs(context.Background(), &models.LogAnalysisRequest{Limit: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analysis.Statistics.LogsByLevel["fatal"])
+	assert.Equal(t, 1, analysis.Statistics.LogsByLevel["notice"])
+	assert.Equal(t, 50.0, analysis.Statistics.ErrorRate)
+	assert.Equal(t, []string{"fatal", "error", "warn", "notice", "info", "debug"}, service.GetLogLevels())
+}
+
+func TestNewLogService_LevelDefaults(t *testing.T) {
+	service := NewLogService(&MockAIService{}, websocket.NewHub(), LogServiceConfig{
+		Levels:     []string{"critical", "warning", "info"},
+		ErrorLevel: "error", // not configured, falls back to the most severe level
+	})
+
+	assert.Equal(t, "critical", service.config.ErrorLevel)
+	assert.True(t, service.isErrorLevel("critical"))
+	assert.False(t, service.isErrorLevel("warning"))
+
+	service = NewLogService(&MockAIService{}, websocket.NewHub(), LogServiceConfig{})
+	assert.Equal(t, DefaultLogServiceConfig().Levels, service.GetLogLevels())
+	assert.Equal(t, "error", service.config.ErrorLevel)
+}
+
 func TestLogService_GetLogCount(t *testing.T) {
 	mockAI := &MockAIService{}
 	hub := websocket.NewHub()
@@ -586,10 +1177,10 @@ func TestLogService_GetLogCount(t *testing.T) {
 	assert.Equal(t, 0, service.GetLogCount())
 
 	// Add some logs
-	service.logs = []models.LogEntry{
+	seedLogs(t, service, []models.LogEntry{
 		{ID: "1", Message: "Test 1"},
 		{ID: "2", Message: "Test 2"},
-	}
+	})
 
 	assert.Equal(t, 2, service.GetLogCount())
 }
@@ -600,16 +1191,24 @@ func TestLogService_ClearLogs(t *testing.T) {
 	service := NewLogService(mockAI, hub)
 
 	// Add some logs
-	service.logs = []models.LogEntry{
+	seedLogs(t, service, []models.LogEntry{
 		{ID: "1", Message: "Test 1"},
 		{ID: "2", Message: "Test 2"},
-	}
+	})
 
 	assert.Equal(t, 2, service.GetLogCount())
 
 	// Clear logs
-	service.ClearLogs()
+	require.NoError(t, service.ClearLogs())
 
 	assert.Equal(t, 0, service.GetLogCount())
-	assert.Empty(t, service.logs)
+	stored, err := service.store.Query(LogFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+// seedLogs stores entries directly, bypassing submission validation
+func seedLogs(t *testing.T, service *LogService, entries []models.LogEntry) {
+	t.Helper()
+	require.NoError(t, service.store.Append(entries...))
 }
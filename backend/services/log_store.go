@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// DefaultMaxStoredLogs is how many submitted logs are kept when no limit is configured
+const DefaultMaxStoredLogs = 10000
+
+// LogFilter selects stored logs. Empty fields match everything.
+type LogFilter struct {
+	Start      time.Time // Earliest timestamp, inclusive
+	End        time.Time // Latest timestamp, inclusive
+	Levels     []string
+	Sources    []string
+	Components []string
+}
+
+// LogStore stores submitted logs for analysis
+type LogStore interface {
+	// Append stores entries, evicting the oldest ones if the store is full
+	Append(entries ...models.LogEntry) error
+	// Query returns the entries matching filter, newest first
+	Query(filter LogFilter) ([]models.LogEntry, error)
+	// Each calls fn with the entries matching filter, newest first, without loading them all at
+	// once. It stops at the first error fn returns and returns it.
+	Each(filter LogFilter, fn func(models.LogEntry) error) error
+	// Count returns the number of stored entries
+	Count() (int, error)
+	// Clear removes all stored entries
+	Clear() error
+}
+
+// MemoryLogStoreConfig tunes a MemoryLogStore
+type MemoryLogStoreConfig struct {
+	// CompressThreshold compresses messages and stack traces of at least this many bytes while
+	// they are stored, decompressing them when they are read; 0 stores everything as-is
+	CompressThreshold int
+}
+
+// MemoryLogStore keeps the most recent logs in memory; they are lost on restart
+type MemoryLogStore struct {
+	mu                sync.RWMutex
+	entries           []storedLogEntry
+	maxEntries        int
+	compressThreshold int
+}
+
+// storedLogEntry is an entry as kept by MemoryLogStore. When message or stackTrace is set, the
+// entry's Message or StackTrace field is empty and its text is held there compressed instead.
+type storedLogEntry struct {
+	entry      models.LogEntry
+	message    []byte
+	stackTrace []byte
+}
+
+// flateWriters reuses compressors, which are expensive to allocate per entry
+var flateWriters = sync.Pool{
+	New: func() interface{} {
+		writer, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return writer
+	},
+}
+
+// NewMemoryLogStore creates an in-memory store holding up to maxEntries logs; 0 means unlimited
+func NewMemoryLogStore(maxEntries int, config ...MemoryLogStoreConfig) *MemoryLogStore {
+	store := &MemoryLogStore{
+		entries:    make([]storedLogEntry, 0),
+		maxEntries: maxEntries,
+	}
+	if len(config) > 0 && config[0].CompressThreshold > 0 {
+		store.compressThreshold = config[0].CompressThreshold
+	}
+	return store
+}
+
+// Append stores entries, keeping only the newest maxEntries
+func (m *MemoryLogStore) Append(entries ...models.LogEntry) error {
+	// Compress before taking the lock so readers aren't held up by it
+	stored := make([]storedLogEntry, len(entries))
+	for i, entry := range entries {
+		stored[i] = m.pack(entry)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = append(m.entries, stored...)
+	if m.maxEntries > 0 && len(m.entries) > m.maxEntries {
+		m.entries = m.entries[len(m.entries)-m.maxEntries:]
+	}
+	return nil
+}
+
+// Query scans the stored entries for matches. Only matching entries are decompressed; the
+// filter never looks at messages or stack traces.
+func (m *MemoryLogStore) Query(filter LogFilter) ([]models.LogEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := make([]models.LogEntry, 0)
+	for i := range m.entries {
+		if !filter.matches(&m.entries[i].entry) {
+			continue
+		}
+		entry, err := m.entries[i].unpack()
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, entry)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+	return matched, nil
+}
+
+// Each copies the matching entries while holding the lock, still compressed, and decompresses
+// them one at a time while calling fn, so a slow fn doesn't block writers
+func (m *MemoryLogStore) Each(filter LogFilter, fn func(models.LogEntry) error) error {
+	m.mu.RLock()
+	matched := make([]storedLogEntry, 0)
+	for i := range m.entries {
+		if filter.matches(&m.entries[i].entry) {
+			matched = append(matched, m.entries[i])
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].entry.Timestamp.After(matched[j].entry.Timestamp)
+	})
+	for i := range matched {
+		entry, err := matched[i].unpack()
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the number of stored entries
+func (m *MemoryLogStore) Count() (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.entries), nil
+}
+
+// Clear removes all stored entries
+func (m *MemoryLogStore) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make([]storedLogEntry, 0)
+	return nil
+}
+
+// drain removes and returns all entries, oldest first
+func (m *MemoryLogStore) drain() []models.LogEntry {
+	m.mu.Lock()
+	stored := m.entries
+	m.entries = make([]storedLogEntry, 0)
+	m.mu.Unlock()
+
+	entries := make([]models.LogEntry, 0, len(stored))
+	for i := range stored {
+		entry, err := stored[i].unpack()
+		if err != nil {
+			log.Printf("Dropping stored log %s: %v", stored[i].entry.ID, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// pack compresses the entry's message and stack trace if they reach the threshold
+func (m *MemoryLogStore) pack(entry models.LogEntry) storedLogEntry {
+	stored := storedLogEntry{entry: entry}
+	if m.compressThreshold <= 0 {
+		return stored
+	}
+
+	if compressed, ok := compressLogText(entry.Message, m.compressThreshold); ok {
+		stored.message = compressed
+		stored.entry.Message = ""
+	}
+	if compressed, ok := compressLogText(entry.StackTrace, m.compressThreshold); ok {
+		stored.stackTrace = compressed
+		stored.entry.StackTrace = ""
+	}
+	return stored
+}
+
+// unpack returns the entry with its message and stack trace decompressed
+func (s *storedLogEntry) unpack() (models.LogEntry, error) {
+	entry := s.entry
+	if s.message != nil {
+		message, err := decompressLogText(s.message)
+		if err != nil {
+			return models.LogEntry{}, fmt.Errorf("failed to decompress message of log %s: %w", entry.ID, err)
+		}
+		entry.Message = message
+	}
+	if s.stackTrace != nil {
+		stackTrace, err := decompressLogText(s.stackTrace)
+		if err != nil {
+			return models.LogEntry{}, fmt.Errorf("failed to decompress stack trace of log %s: %w", entry.ID, err)
+		}
+		entry.StackTrace = stackTrace
+	}
+	return entry, nil
+}
+
+// compressLogText compresses text of at least threshold bytes. It reports false for shorter
+// text and for text that doesn't get any smaller, which is then stored as-is.
+func compressLogText(text string, threshold int) ([]byte, bool) {
+	if len(text) < threshold {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	writer := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(writer)
+	writer.Reset(&buf)
+
+	if _, err := io.WriteString(writer, text); err != nil {
+		return nil, false
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false
+	}
+	if buf.Len() >= len(text) {
+		return nil, false
+	}
+	return bytes.Clone(buf.Bytes()), true
+}
+
+// decompressLogText reverses compressLogText
+func decompressLogText(compressed []byte) (string, error) {
+	reader := flate.NewReader(bytes.NewReader(compressed))
+	defer reader.Close()
+
+	text, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+// matches reports whether an entry passes the filter
+func (f LogFilter) matches(entry *models.LogEntry) bool {
+	if !f.Start.IsZero() && entry.Timestamp.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && entry.Timestamp.After(f.End) {
+		return false
+	}
+	return matchesAny(f.Levels, entry.Level) &&
+		matchesAny(f.Sources, entry.Source) &&
+		matchesAny(f.Components, entry.Component)
+}
+
+// matchesAny reports whether value is in allowed; an empty allowed list matches everything
+func matchesAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, candidate := range allowed {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// DefaultLogFallbackBufferSize is how many entries are buffered in memory while the log store is failing
+const DefaultLogFallbackBufferSize = 1000
+
+// DefaultLogStoreRetryConfig returns the retry settings for log store writes. The store can't
+// tell transient failures from permanent ones, so every error is retried.
+func DefaultLogStoreRetryConfig() *utils.RetryConfig {
+	return &utils.RetryConfig{
+		MaxAttempts:       3,
+		InitialDelay:      50 * time.Millisecond,
+		MaxDelay:          time.Second,
+		BackoffMultiplier: 2.0,
+		Jitter:            true,
+		RetryCondition:    func(error) bool { return true },
+	}
+}
+
+// logStoreWriteCounters are the running totals behind models.LogStoreWriteStats
+type logStoreWriteCounters struct {
+	writes         atomic.Int64
+	attempts       atomic.Int64
+	failedAttempts atomic.Int64
+	exhausted      atomic.Int64
+	dropped        atomic.Int64
+}
+
+// appendToStore writes entries to the store, retrying failed writes. Entries buffered by earlier
+// failures are written first. If every attempt fails, the whole batch is kept in the fallback
+// buffer until a later write succeeds, so the submission itself never fails on a store hiccup.
+func (s *LogService) appendToStore(ctx context.Context, entries []models.LogEntry) {
+	batch := append(s.fallback.drain(), entries...)
+	if len(batch) == 0 {
+		return
+	}
+
+	s.storeWrites.writes.Add(1)
+	err := s.storeRetry.Execute(ctx, func(ctx context.Context) error {
+		s.storeWrites.attempts.Add(1)
+		if err := s.store.Append(batch...); err != nil {
+			s.storeWrites.failedAttempts.Add(1)
+			return err
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	s.storeWrites.exhausted.Add(1)
+	before, _ := s.fallback.Count()
+	s.fallback.Append(batch...)
+	after, _ := s.fallback.Count()
+	if dropped := before + len(batch) - after; dropped > 0 {
+		s.storeWrites.dropped.Add(int64(dropped))
+	}
+
+	s.logger.Error("Log store write failed, buffering logs in memory", err, map[string]interface{}{
+		"entries":  len(batch),
+		"buffered": after,
+	})
+}
+
+// Flush writes the logs buffered by failed store writes to the store, retrying as a submission
+// would, after waiting for submissions in progress. Buffered logs are lost on exit, so it returns
+// an error when any are left, e.g. because the store is still failing.
+func (s *LogService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appendToStore(ctx, nil)
+	if buffered, _ := s.fallback.Count(); buffered > 0 {
+		return fmt.Errorf("%d buffered logs not written to the log store", buffered)
+	}
+	return nil
+}
+
+// withBufferedLogs adds buffered entries matching filter to entries read from the store, newest first
+func (s *LogService) withBufferedLogs(stored []models.LogEntry, filter LogFilter) []models.LogEntry {
+	buffered, _ := s.fallback.Query(filter)
+	if len(buffered) == 0 {
+		return stored
+	}
+
+	merged := append(stored, buffered...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.After(merged[j].Timestamp)
+	})
+	return merged
+}
+
+// GetStoreWriteStats returns the log store write and retry counters
+func (s *LogService) GetStoreWriteStats() models.LogStoreWriteStats {
+	buffered, _ := s.fallback.Count()
+	return models.LogStoreWriteStats{
+		Writes:          s.storeWrites.writes.Load(),
+		Attempts:        s.storeWrites.attempts.Load(),
+		FailedAttempts:  s.storeWrites.failedAttempts.Load(),
+		Exhausted:       s.storeWrites.exhausted.Load(),
+		BufferedEntries: buffered,
+		DroppedEntries:  s.storeWrites.dropped.Load(),
+	}
+}
+
+// HealthCheck fails when the log store can't be read, or while logs are buffered in memory
+// because writes to it keep failing
+func (s *LogService) HealthCheck(ctx context.Context) error {
+	if _, err := s.store.Count(); err != nil {
+		return fmt.Errorf("log store is unreachable: %w", err)
+	}
+	if buffered, _ := s.fallback.Count(); buffered > 0 {
+		return fmt.Errorf("%d logs buffered in memory while log store writes fail", buffered)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyLogStore fails the next failures Append calls before passing writes to an in-memory store
+type flakyLogStore struct {
+	*MemoryLogStore
+	mu       sync.Mutex
+	failures int
+	appends  int
+}
+
+func (f *flakyLogStore) Append(entries ...models.LogEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.appends++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("database is locked")
+	}
+	return f.MemoryLogStore.Append(entries...)
+}
+
+func (f *flakyLogStore) failNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+}
+
+func TestLogService_StoreWriteRetries(t *testing.T) {
+	store := &flakyLogStore{MemoryLogStore: NewMemoryLogStore(0)}
+	retry := DefaultLogStoreRetryConfig()
+	retry.InitialDelay = time.Millisecond
+	service := NewLogService(nil, nil, LogServiceConfig{Store: store, StoreRetry: retry, FallbackBufferSize: 3})
+
+	submit := func(messages ...string) *models.LogSubmissionResponse {
+		logs := make([]models.LogEntry, len(messages))
+		for i, message := range messages {
+			logs[i] = models.LogEntry{Level: "info", Source: "backend", Message: message}
+		}
+		response, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{Source: "backend", Logs: logs})
+		require.NoError(t, err)
+		return response
+	}
+	stored := func() int {
+		count, err := store.Count()
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("transient failures are retried", func(t *testing.T) {
+		store.failNext(2)
+		response := submit("first")
+
+		assert.Equal(t, 1, response.Accepted)
+		assert.Equal(t, 1, stored())
+		assert.Equal(t, models.LogStoreWriteStats{Writes: 1, Attempts: 3, FailedAttempts: 2}, service.GetStoreWriteStats())
+	})
+
+	t.Run("exhausted retries buffer the batch in memory", func(t *testing.T) {
+		store.failNext(3)
+		response := submit("second", "third")
+
+		assert.Equal(t, 2, response.Accepted)
+		assert.Equal(t, 1, stored())
+		stats := service.GetStoreWriteStats()
+		assert.Equal(t, int64(1), stats.Exhausted)
+		assert.Equal(t, 2, stats.BufferedEntries)
+
+		// Buffered entries stay visible to analysis while they wait
+		assert.Equal(t, 3, service.GetLogCount())
+		filtered, total, err := service.filterLogs(&models.LogAnalysisRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Len(t, filtered, 3)
+	})
+
+	t.Run("a full buffer drops the oldest entries", func(t *testing.T) {
+		store.failNext(3)
+		submit("fourth", "fifth")
+
+		stats := service.GetStoreWriteStats()
+		assert.Equal(t, 3, stats.BufferedEntries)
+		assert.Equal(t, int64(1), stats.DroppedEntries)
+	})
+
+	t.Run("the next successful write flushes the buffer", func(t *testing.T) {
+		submit("sixth")
+
+		assert.Equal(t, 5, stored())
+		matched, err := store.Query(LogFilter{})
+		require.NoError(t, err)
+		messages := make([]string, 0, len(matched))
+		for _, entry := range matched {
+			messages = append(messages, entry.Message)
+		}
+		assert.ElementsMatch(t, []string{"first", "third", "fourth", "fifth", "sixth"}, messages)
+
+		stats := service.GetStoreWriteStats()
+		assert.Equal(t, 0, stats.BufferedEntries)
+		assert.Equal(t, int64(4), stats.Writes)
+		assert.Equal(t, int64(10), stats.Attempts)
+		assert.Equal(t, int64(8), stats.FailedAttempts)
+	})
+
+	t.Run("every store error is retried", func(t *testing.T) {
+		config := DefaultLogStoreRetryConfig()
+		assert.Equal(t, 3, config.MaxAttempts)
+		assert.True(t, config.RetryCondition(errors.New("disk I/O error")))
+	})
+}
+
+func TestLogService_Flush(t *testing.T) {
+	store := &flakyLogStore{MemoryLogStore: NewMemoryLogStore(0)}
+	retry := DefaultLogStoreRetryConfig()
+	retry.InitialDelay = time.Millisecond
+	service := NewLogService(nil, nil, LogServiceConfig{Store: store, StoreRetry: retry})
+
+	assert.NoError(t, service.Flush(context.Background()), "nothing buffered")
+	assert.Zero(t, store.appends)
+
+	store.failNext(3)
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs:   []models.LogEntry{{Level: "info", Source: "backend", Message: "buffered"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, service.GetStoreWriteStats().BufferedEntries)
+
+	t.Run("a failing store keeps the buffer", func(t *testing.T) {
+		store.failNext(3)
+		err := service.Flush(context.Background())
+		assert.EqualError(t, err, "1 buffered logs not written to the log store")
+		assert.Equal(t, 1, service.GetStoreWriteStats().BufferedEntries)
+	})
+
+	t.Run("buffered logs are written", func(t *testing.T) {
+		require.NoError(t, service.Flush(context.Background()))
+		assert.Equal(t, 0, service.GetStoreWriteStats().BufferedEntries)
+		count, err := store.Count()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+// unreachableLogStore fails every read, as a database that has gone away would
+type unreachableLogStore struct {
+	*MemoryLogStore
+}
+
+func (unreachableLogStore) Count() (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestLogService_HealthCheck(t *testing.T) {
+	store := &flakyLogStore{MemoryLogStore: NewMemoryLogStore(0)}
+	retry := DefaultLogStoreRetryConfig()
+	retry.InitialDelay = time.Millisecond
+	service := NewLogService(nil, nil, LogServiceConfig{Store: store, StoreRetry: retry})
+	assert.NoError(t, service.HealthCheck(context.Background()))
+
+	store.failNext(3)
+	_, err := service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs:   []models.LogEntry{{Level: "info", Source: "backend", Message: "buffered"}},
+	})
+	require.NoError(t, err)
+	assert.EqualError(t, service.HealthCheck(context.Background()), "1 logs buffered in memory while log store writes fail")
+
+	require.NoError(t, service.Flush(context.Background()))
+	assert.NoError(t, service.HealthCheck(context.Background()), "the buffer was written")
+
+	service = NewLogService(nil, nil, LogServiceConfig{Store: unreachableLogStore{NewMemoryLogStore(0)}})
+	assert.EqualError(t, service.HealthCheck(context.Background()), "log store is unreachable: connection refused")
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver
+)
+
+// sqliteLogSchema creates the logs table. Filterable fields get their own indexed columns;
+// the full entry is kept as JSON.
+const sqliteLogSchema = `
+CREATE TABLE IF NOT EXISTS logs (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp INTEGER NOT NULL,
+	level     TEXT NOT NULL,
+	source    TEXT NOT NULL,
+	component TEXT NOT NULL,
+	entry     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON logs (timestamp);
+CREATE INDEX IF NOT EXISTS idx_logs_level ON logs (level);
+CREATE INDEX IF NOT EXISTS idx_logs_source ON logs (source);
+`
+
+// SQLiteLogStore stores logs in a SQLite database so they survive restarts
+type SQLiteLogStore struct {
+	db         *sql.DB
+	maxEntries int
+}
+
+// NewSQLiteLogStore opens or creates the database at path, keeping up to maxEntries logs; 0 means unlimited
+func NewSQLiteLogStore(path string, maxEntries int) (*SQLiteLogStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create log store directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log store: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteLogSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create log store schema: %w", err)
+	}
+
+	return &SQLiteLogStore{db: db, maxEntries: maxEntries}, nil
+}
+
+// Append inserts entries in one transaction, then evicts the oldest beyond maxEntries
+func (s *SQLiteLogStore) Append(entries ...models.LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to store logs: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO logs (timestamp, level, source, component, entry) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to store logs: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode log %s: %w", entry.ID, err)
+		}
+		if _, err := stmt.Exec(entry.Timestamp.UnixNano(), entry.Level, entry.Source, entry.Component, string(data)); err != nil {
+			return fmt.Errorf("failed to store log %s: %w", entry.ID, err)
+		}
+	}
+
+	if s.maxEntries > 0 {
+		if _, err := tx.Exec(`DELETE FROM logs WHERE seq <= (SELECT seq FROM logs ORDER BY seq DESC LIMIT 1 OFFSET ?)`, s.maxEntries); err != nil {
+			return fmt.Errorf("failed to evict old logs: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store logs: %w", err)
+	}
+	return nil
+}
+
+// sqliteEachPageSize is how many rows Each reads per query. The connection is released between
+// pages so writers aren't blocked while the caller processes them.
+const sqliteEachPageSize = 500
+
+// sqlConditions returns the WHERE conditions and arguments applying filter
+func (f LogFilter) sqlConditions() ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if !f.Start.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, f.Start.UnixNano())
+	}
+	if !f.End.IsZero() {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, f.End.UnixNano())
+	}
+	conditions, args = appendInCondition(conditions, args, "level", f.Levels)
+	conditions, args = appendInCondition(conditions, args, "source", f.Sources)
+	conditions, args = appendInCondition(conditions, args, "component", f.Components)
+	return conditions, args
+}
+
+// Query selects matching entries with the filter applied in SQL
+func (s *SQLiteLogStore) Query(filter LogFilter) ([]models.LogEntry, error) {
+	conditions, args := filter.sqlConditions()
+
+	query := "SELECT entry FROM logs"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY timestamp DESC, seq ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.LogEntry, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read log: %w", err)
+		}
+
+		var entry models.LogEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Each reads matching entries a page at a time, continuing each page after the last row of the
+// previous one in the same order as Query
+func (s *SQLiteLogStore) Each(filter LogFilter, fn func(models.LogEntry) error) error {
+	conditions, args := filter.sqlConditions()
+
+	var lastTimestamp, lastSeq int64
+	for page := 0; ; page++ {
+		pageConditions, pageArgs := conditions, args
+		if page > 0 {
+			pageConditions = append(pageConditions[:len(pageConditions):len(pageConditions)],
+				"(timestamp < ? OR (timestamp = ? AND seq > ?))")
+			pageArgs = append(pageArgs[:len(pageArgs):len(pageArgs)], lastTimestamp, lastTimestamp, lastSeq)
+		}
+
+		query := "SELECT seq, timestamp, entry FROM logs"
+		if len(pageConditions) > 0 {
+			query += " WHERE " + strings.Join(pageConditions, " AND ")
+		}
+		query += fmt.Sprintf(" ORDER BY timestamp DESC, seq ASC LIMIT %d", sqliteEachPageSize)
+
+		entries, err := s.readPage(query, pageArgs, &lastTimestamp, &lastSeq)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(entries) < sqliteEachPageSize {
+			return nil
+		}
+	}
+}
+
+// readPage runs one page query of Each, recording the position of its last row
+func (s *SQLiteLogStore) readPage(query string, args []interface{}, lastTimestamp, lastSeq *int64) ([]models.LogEntry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.LogEntry, 0, sqliteEachPageSize)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(lastSeq, lastTimestamp, &data); err != nil {
+			return nil, fmt.Errorf("failed to read log: %w", err)
+		}
+
+		var entry models.LogEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query logs: %w", err)
+	}
+	return entries, nil
+}
+
+// Count returns the number of stored entries
+func (s *SQLiteLogStore) Count() (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM logs`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count logs: %w", err)
+	}
+	return count, nil
+}
+
+// Clear removes all stored entries
+func (s *SQLiteLogStore) Clear() error {
+	if _, err := s.db.Exec(`DELETE FROM logs`); err != nil {
+		return fmt.Errorf("failed to clear logs: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteLogStore) Close() error {
+	return s.db.Close()
+}
+
+// appendInCondition adds a "column IN (...)" condition when values is non-empty
+func appendInCondition(conditions []string, args []interface{}, column string, values []string) ([]string, []interface{}) {
+	if len(values) == 0 {
+		return conditions, args
+	}
+
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		args = append(args, value)
+	}
+	return append(conditions, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))), args
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logStoreFactories builds each LogStore implementation with the given capacity
+var logStoreFactories = map[string]func(t *testing.T, maxEntries int) LogStore{
+	"memory": func(t *testing.T, maxEntries int) LogStore {
+		return NewMemoryLogStore(maxEntries)
+	},
+	"compressed memory": func(t *testing.T, maxEntries int) LogStore {
+		return NewMemoryLogStore(maxEntries, MemoryLogStoreConfig{CompressThreshold: 1})
+	},
+	"sqlite": func(t *testing.T, maxEntries int) LogStore {
+		store, err := NewSQLiteLogStore(filepath.Join(t.TempDir(), "logs.db"), maxEntries)
+		require.NoError(t, err)
+		t.Cleanup(func() { store.Close() })
+		return store
+	},
+}
+
+func TestLogStore_Query(t *testing.T) {
+	now := time.Now()
+	entries := []models.LogEntry{
+		{ID: "1", Timestamp: now.Add(-3 * time.Hour), Level: "error", Source: "backend", Component: "payments", Message: "Payment declined"},
+		{ID: "2", Timestamp: now.Add(-2 * time.Hour), Level: "info", Source: "frontend", Component: "auth", Message: "User logged in"},
+		{ID: "3", Timestamp: now.Add(-1 * time.Hour), Level: "warn", Source: "backend", Message: "Slow query", Context: map[string]interface{}{"duration_ms": 1200}},
+		{ID: "4", Timestamp: now, Level: "error", Source: "frontend", Component: "auth", Message: "Render failed"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   LogFilter
+		expected []string
+	}{
+		{name: "no filter returns newest first", filter: LogFilter{}, expected: []string{"4", "3", "2", "1"}},
+		{name: "levels", filter: LogFilter{Levels: []string{"error", "warn"}}, expected: []string{"4", "3", "1"}},
+		{name: "sources", filter: LogFilter{Sources: []string{"frontend"}}, expected: []string{"4", "2"}},
+		{name: "components", filter: LogFilter{Components: []string{"auth"}}, expected: []string{"4", "2"}},
+		{
+			name:     "time range is inclusive",
+			filter:   LogFilter{Start: now.Add(-2 * time.Hour), End: now.Add(-1 * time.Hour)},
+			expected: []string{"3", "2"},
+		},
+		{
+			name:     "combined",
+			filter:   LogFilter{Start: now.Add(-150 * time.Minute), Levels: []string{"error"}, Sources: []string{"frontend"}},
+			expected: []string{"4"},
+		},
+		{name: "no match", filter: LogFilter{Levels: []string{"debug"}}, expected: []string{}},
+	}
+
+	for name, newStore := range logStoreFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t, 0)
+			require.NoError(t, store.Append(entries...))
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					matched, err := store.Query(tt.filter)
+					require.NoError(t, err)
+
+					ids := make([]string, len(matched))
+					for i, entry := range matched {
+						ids[i] = entry.ID
+					}
+					assert.Equal(t, tt.expected, ids)
+				})
+			}
+
+			t.Run("entries round-trip", func(t *testing.T) {
+				matched, err := store.Query(LogFilter{Levels: []string{"warn"}})
+				require.NoError(t, err)
+				require.Len(t, matched, 1)
+				assert.Equal(t, "Slow query", matched[0].Message)
+				assert.True(t, matched[0].Timestamp.Equal(entries[2].Timestamp))
+				assert.EqualValues(t, 1200, matched[0].Context["duration_ms"])
+			})
+		})
+	}
+}
+
+func TestLogStore_Each(t *testing.T) {
+	// More entries than one SQLite page, with timestamps shared across page boundaries
+	now := time.Now()
+	entries := make([]models.LogEntry, 0, 2*sqliteEachPageSize+50)
+	for i := 0; i < cap(entries); i++ {
+		level := "info"
+		if i%3 == 0 {
+			level = "error"
+		}
+		entries = append(entries, models.LogEntry{
+			ID:        fmt.Sprintf("log-%d", i),
+			Timestamp: now.Add(time.Duration(i/7) * time.Second),
+			Level:     level,
+			Source:    "backend",
+			Message:   fmt.Sprintf("message %d", i),
+		})
+	}
+	errStop := errors.New("stop")
+
+	for name, newStore := range logStoreFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t, 0)
+			require.NoError(t, store.Append(entries...))
+
+			for _, filter := range []LogFilter{{}, {Levels: []string{"error"}}, {Start: now.Add(30 * time.Second)}} {
+				queried, err := store.Query(filter)
+				require.NoError(t, err)
+
+				var each []models.LogEntry
+				require.NoError(t, store.Each(filter, func(entry models.LogEntry) error {
+					each = append(each, entry)
+					return nil
+				}))
+				assert.Equal(t, ids(queried), ids(each), "Each must return the same entries in the same order as Query")
+			}
+
+			visited := 0
+			err := store.Each(LogFilter{}, func(entry models.LogEntry) error {
+				visited++
+				if visited == 3 {
+					return errStop
+				}
+				return nil
+			})
+			assert.ErrorIs(t, err, errStop)
+			assert.Equal(t, 3, visited)
+		})
+	}
+}
+
+// ids returns the IDs of entries in order
+func ids(entries []models.LogEntry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.ID
+	}
+	return result
+}
+
+func TestLogStore_CountClearAndEviction(t *testing.T) {
+	for name, newStore := range logStoreFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t, 3)
+
+			count, err := store.Count()
+			require.NoError(t, err)
+			assert.Equal(t, 0, count)
+
+			base := time.Now()
+			for i, id := range []string{"1", "2", "3", "4", "5"} {
+				require.NoError(t, store.Append(models.LogEntry{
+					ID: id, Timestamp: base.Add(time.Duration(i) * time.Second), Level: "info", Source: "backend", Message: "Message " + id,
+				}))
+			}
+
+			// Only the newest three are kept
+			count, err = store.Count()
+			require.NoError(t, err)
+			assert.Equal(t, 3, count)
+
+			matched, err := store.Query(LogFilter{})
+			require.NoError(t, err)
+			require.Len(t, matched, 3)
+			assert.Equal(t, "5", matched[0].ID)
+			assert.Equal(t, "3", matched[2].ID)
+
+			require.NoError(t, store.Clear())
+			count, err = store.Count()
+			require.NoError(t, err)
+			assert.Equal(t, 0, count)
+		})
+	}
+}
+
+func TestMemoryLogStore_Compression(t *testing.T) {
+	stackTrace := strings.Repeat("at processPayment (payments.js:42:13)\n", 50)
+	store := NewMemoryLogStore(0, MemoryLogStoreConfig{CompressThreshold: 256})
+	require.NoError(t, store.Append(
+		models.LogEntry{ID: "1", Timestamp: time.Now(), Level: "error", Source: "frontend", Message: "Payment declined", StackTrace: stackTrace},
+		models.LogEntry{ID: "2", Timestamp: time.Now(), Level: "info", Source: "frontend", Message: "Short"},
+	))
+
+	store.mu.RLock()
+	stored := append([]storedLogEntry(nil), store.entries...)
+	store.mu.RUnlock()
+
+	// Only text over the threshold is compressed
+	assert.Nil(t, stored[0].message)
+	assert.Equal(t, "Payment declined", stored[0].entry.Message)
+	require.NotNil(t, stored[0].stackTrace)
+	assert.Empty(t, stored[0].entry.StackTrace)
+	assert.Less(t, len(stored[0].stackTrace), len(stackTrace))
+	assert.Nil(t, stored[1].message)
+
+	matched, err := store.Query(LogFilter{Levels: []string{"error"}})
+	require.NoError(t, err)
+	require.Len(t, matched, 1)
+	assert.Equal(t, stackTrace, matched[0].StackTrace)
+	assert.Equal(t, "Payment declined", matched[0].Message)
+
+	drained := store.drain()
+	require.Len(t, drained, 2)
+	assert.Equal(t, stackTrace, drained[0].StackTrace)
+
+	t.Run("incompressible text is stored as-is", func(t *testing.T) {
+		_, ok := compressLogText("a7#Kq", 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestSQLiteLogStore_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "logs.db")
+
+	store, err := NewSQLiteLogStore(path, 0)
+	require.NoError(t, err)
+	service := NewLogService(nil, nil, LogServiceConfig{Store: store})
+
+	_, err = service.SubmitLogs(context.Background(), &models.LogSubmissionRequest{
+		Source: "backend",
+		Logs: []models.LogEntry{
+			{Level: "error", Source: "backend", Message: "Database unreachable"},
+			{Level: "info", Source: "backend", Message: "Retrying"},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	reopened, err := NewSQLiteLogStore(path, 0)
+	require.NoError(t, err)
+	defer reopened.Close()
+	restarted := NewLogService(nil, nil, LogServiceConfig{Store: reopened})
+
+	assert.Equal(t, 2, restarted.GetLogCount())
+	filtered, _, err := restarted.filterLogs(&models.LogAnalysisRequest{Levels: []string{"error"}})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Database unreachable", filtered[0].Message)
+	assert.NotEmpty(t, filtered[0].ID)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/sashabaranov/go-openai"
+)
+
+// defaultAIModel serves requests when no model distribution is configured
+const defaultAIModel = openai.GPT3Dot5Turbo
+
+// ModelSelector samples an OpenAI model per request from a weighted distribution,
+// which lets suggestion quality and cost be compared across models
+type ModelSelector struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	weights []models.AIModelWeight
+	total   float64
+}
+
+// NewModelSelector creates a selector over weights. A nil source is seeded from the clock;
+// pass a fixed source for reproducible selection.
+func NewModelSelector(weights []models.AIModelWeight, source rand.Source) *ModelSelector {
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	selector := &ModelSelector{
+		rng:     rand.New(source),
+		weights: make([]models.AIModelWeight, 0, len(weights)),
+	}
+	for _, weight := range weights {
+		if weight.Model == "" || weight.Weight <= 0 {
+			continue
+		}
+		selector.weights = append(selector.weights, weight)
+		selector.total += weight.Weight
+	}
+
+	return selector
+}
+
+// Pick returns a model with probability proportional to its weight, or the default model
+// when the distribution is empty
+func (m *ModelSelector) Pick() string {
+	switch len(m.weights) {
+	case 0:
+		return defaultAIModel
+	case 1:
+		return m.weights[0].Model
+	}
+
+	m.mu.Lock()
+	target := m.rng.Float64() * m.total
+	m.mu.Unlock()
+
+	for _, weight := range m.weights {
+		if target < weight.Weight {
+			return weight.Model
+		}
+		target -= weight.Weight
+	}
+
+	// Guard against floating point rounding on the last bucket
+	return m.weights[len(m.weights)-1].Model
+}
+
+// Weights returns the configured distribution
+func (m *ModelSelector) Weights() []models.AIModelWeight {
+	return append([]models.AIModelWeight(nil), m.weights...)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelSelector_Pick(t *testing.T) {
+	t.Run("empty distribution uses the default model", func(t *testing.T) {
+		selector := NewModelSelector(nil, nil)
+		assert.Equal(t, defaultAIModel, selector.Pick())
+	})
+
+	t.Run("single model is always picked", func(t *testing.T) {
+		selector := NewModelSelector([]models.AIModelWeight{{Model: "gpt-4o-mini", Weight: 3}}, nil)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, "gpt-4o-mini", selector.Pick())
+		}
+	})
+
+	t.Run("invalid entries are ignored", func(t *testing.T) {
+		selector := NewModelSelector([]models.AIModelWeight{
+			{Model: "", Weight: 1},
+			{Model: "gpt-4o", Weight: 0},
+			{Model: "gpt-4o-mini", Weight: 1},
+		}, nil)
+		assert.Equal(t, []models.AIModelWeight{{Model: "gpt-4o-mini", Weight: 1}}, selector.Weights())
+	})
+
+	t.Run("picks follow the weights", func(t *testing.T) {
+		selector := NewModelSelector([]models.AIModelWeight{
+			{Model: "gpt-3.5-turbo", Weight: 75},
+			{Model: "gpt-4o-mini", Weight: 25},
+		}, rand.NewSource(42))
+
+		counts := make(map[string]int)
+		for i := 0; i < 10000; i++ {
+			counts[selector.Pick()]++
+		}
+
+		assert.InDelta(t, 7500, counts["gpt-3.5-turbo"], 300)
+		assert.InDelta(t, 2500, counts["gpt-4o-mini"], 300)
+	})
+
+	t.Run("same seed gives the same sequence", func(t *testing.T) {
+		weights := []models.AIModelWeight{
+			{Model: "gpt-3.5-turbo", Weight: 1},
+			{Model: "gpt-4o-mini", Weight: 1},
+			{Model: "gpt-4o", Weight: 1},
+		}
+		first := NewModelSelector(weights, rand.NewSource(7))
+		second := NewModelSelector(weights, rand.NewSource(7))
+
+		for i := 0; i < 50; i++ {
+			assert.Equal(t, first.Pick(), second.Pick())
+		}
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package services
+
+import (
+	"os/exec"
+)
+
+// configureProcessGroup only bounds the wait for output on platforms without process groups;
+// the default cancellation kills the direct child process
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.WaitDelay = processWaitDelay
+}
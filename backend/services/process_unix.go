@@ -0,0 +1,20 @@
+//go:build unix
+
+package services
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup starts cmd in its own process group and, when its context ends, kills
+// the whole group so child processes spawned by npx (browsers, workers) are reaped too
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// A negative PID signals every process in the group
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Stop waiting on output pipes still held open by stray processes
+	cmd.WaitDelay = processWaitDelay
+}
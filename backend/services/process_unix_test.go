@@ -0,0 +1,48 @@
+//go:build unix
+
+package services
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureProcessGroup_KillsChildren(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The background sleep inherits stdout, so it would keep the pipe open if only sh were killed
+	cmd := exec.CommandContext(ctx, "sh", "-c", "sleep 30 & echo $!; wait")
+	configureProcessGroup(cmd)
+
+	service := createTestService()
+	start := time.Now()
+	output, err := service.runStreamingCommand(&TestRun{ID: "run-1"}, cmd)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), processWaitDelay)
+
+	childPID, convErr := strconv.Atoi(strings.TrimSpace(string(output)))
+	require.NoError(t, convErr)
+
+	// The child is gone, or at most an unreaped zombie
+	assert.Eventually(t, func() bool {
+		if syscall.Kill(childPID, 0) != nil {
+			return true
+		}
+		stat, readErr := os.ReadFile("/proc/" + strconv.Itoa(childPID) + "/stat")
+		return readErr != nil || strings.Contains(string(stat), ") Z ")
+	}, 2*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ScheduleStore persists test schedules so they survive restarts
+type ScheduleStore interface {
+	// Save persists a schedule, replacing any previous one with the same ID
+	Save(schedule models.TestSchedule) error
+	// Delete removes a persisted schedule; deleting an unknown one is not an error
+	Delete(id string) error
+	// List returns every persisted schedule ordered by creation time
+	List() ([]models.TestSchedule, error)
+}
+
+// FileScheduleStore keeps every schedule in a single JSON file. Schedules hold run configuration,
+// which may include credentials, so the file is only readable by its owner.
+type FileScheduleStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileScheduleStore creates a file-backed schedule store, creating the file's directory if needed
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create test schedules directory: %w", err)
+	}
+	return &FileScheduleStore{path: path}, nil
+}
+
+// Save adds or replaces the schedule and rewrites the file
+func (f *FileScheduleStore) Save(schedule models.TestSchedule) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return err
+	}
+	stored[schedule.ID] = schedule
+	return f.write(stored)
+}
+
+// Delete removes the schedule and rewrites the file
+func (f *FileScheduleStore) Delete(id string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := stored[id]; !exists {
+		return nil
+	}
+	delete(stored, id)
+	return f.write(stored)
+}
+
+// List reads the file; a missing file means no schedules have been saved yet
+func (f *FileScheduleStore) List() ([]models.TestSchedule, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stored, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return sortedSchedules(stored), nil
+}
+
+// read decodes the file into schedules keyed by ID
+func (f *FileScheduleStore) read() (map[string]models.TestSchedule, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]models.TestSchedule), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test schedules: %w", err)
+	}
+
+	var list []models.TestSchedule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(f.path), err)
+	}
+
+	stored := make(map[string]models.TestSchedule, len(list))
+	for _, schedule := range list {
+		stored[schedule.ID] = schedule
+	}
+	return stored, nil
+}
+
+// write replaces the file with the given schedules, ordered by creation time
+func (f *FileScheduleStore) write(stored map[string]models.TestSchedule) error {
+	data, err := json.MarshalIndent(sortedSchedules(stored), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode test schedules: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file; CreateTemp
+	// creates it with 0600 permissions
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".schedules-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save test schedules: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save test schedules: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save test schedules: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to save test schedules: %w", err)
+	}
+
+	return nil
+}
+
+// sortedSchedules returns the schedules ordered by creation time, then ID
+func sortedSchedules(schedules map[string]models.TestSchedule) []models.TestSchedule {
+	list := make([]models.TestSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		list = append(list, schedule)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
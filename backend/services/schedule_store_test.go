@@ -0,0 +1,80 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileScheduleStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "schedules.json")
+	store, err := NewFileScheduleStore(path)
+	require.NoError(t, err)
+
+	schedules, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, schedules, "a missing file has no schedules")
+
+	created := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(models.TestSchedule{
+		ID:        "b",
+		Name:      "nightly",
+		Cron:      "0 2 * * *",
+		Overlap:   models.TestScheduleOverlapQueue,
+		Run:       models.TestRunRequest{Framework: "jest", TestSuite: "unit", Environment: "staging", Config: map[string]string{"token": "secret"}},
+		CreatedAt: created,
+		LastRunID: "run-1",
+	}))
+	require.NoError(t, store.Save(models.TestSchedule{ID: "a", Name: "hourly", Cron: "@hourly", CreatedAt: created.Add(time.Hour)}))
+
+	schedules, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "nightly", schedules[0].Name, "ordered by creation time")
+	assert.Equal(t, "hourly", schedules[1].Name)
+	assert.Equal(t, map[string]string{"token": "secret"}, schedules[0].Run.Config)
+	assert.Equal(t, "run-1", schedules[0].LastRunID)
+	assert.True(t, created.Equal(schedules[0].CreatedAt))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "run configuration may hold credentials")
+
+	t.Run("saving again replaces the schedule", func(t *testing.T) {
+		require.NoError(t, store.Save(models.TestSchedule{ID: "a", Name: "hourly", Cron: "@hourly", CreatedAt: created.Add(time.Hour), SkippedRuns: 2}))
+
+		schedules, err := store.List()
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		assert.Equal(t, 2, schedules[1].SkippedRuns)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete("a"))
+		require.NoError(t, store.Delete("unknown"))
+
+		// A fresh store over the same file sees the change
+		reopened, err := NewFileScheduleStore(path)
+		require.NoError(t, err)
+		schedules, err := reopened.List()
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, "b", schedules[0].ID)
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "schedules.json")
+		require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0o600))
+		store, err := NewFileScheduleStore(corrupt)
+		require.NoError(t, err)
+
+		_, err = store.List()
+		assert.Error(t, err)
+		assert.Error(t, store.Save(models.TestSchedule{ID: "a"}), "a corrupt file is not overwritten")
+	})
+}
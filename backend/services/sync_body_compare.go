@@ -0,0 +1,321 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// maxComparedBodyBytes caps how much of each response body is read when comparing bodies
+const maxComparedBodyBytes = 1 << 20
+
+// BodyComparisonLimits bound the structural comparison of JSON response bodies so a deeply
+// nested or huge payload can't stall a validation
+type BodyComparisonLimits struct {
+	MaxDepth  int // Deepest nesting level compared; the top-level body is depth 0
+	MaxFields int // Values visited before the comparison stops
+	MaxDiffs  int // Differences reported before the comparison stops
+}
+
+// DefaultBodyComparisonLimits returns the limits used when none are configured
+func DefaultBodyComparisonLimits() BodyComparisonLimits {
+	return BodyComparisonLimits{
+		MaxDepth:  32,
+		MaxFields: 10000,
+		MaxDiffs:  100,
+	}
+}
+
+// withDefaults fills unset limits from DefaultBodyComparisonLimits
+func (l BodyComparisonLimits) withDefaults() BodyComparisonLimits {
+	defaults := DefaultBodyComparisonLimits()
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = defaults.MaxDepth
+	}
+	if l.MaxFields <= 0 {
+		l.MaxFields = defaults.MaxFields
+	}
+	if l.MaxDiffs <= 0 {
+		l.MaxDiffs = defaults.MaxDiffs
+	}
+	return l
+}
+
+// bodyComparison walks two decoded JSON bodies, collecting structural differences until a limit is hit
+type bodyComparison struct {
+	limits    BodyComparisonLimits
+	fields    int
+	issues    []models.SyncCompatibilityIssue
+	truncated string // Limit that stopped the comparison; empty when it ran to completion
+}
+
+// responseBody is a response body read for comparison, along with its declared Content-Type
+type responseBody struct {
+	contentType string
+	data        []byte
+	truncated   bool // The body was longer than maxComparedBodyBytes; data holds the start of it
+}
+
+// readResponseBody reads up to maxComparedBodyBytes of a response body for comparison
+func readResponseBody(resp *http.Response) (responseBody, error) {
+	body := responseBody{contentType: resp.Header.Get("Content-Type")}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxComparedBodyBytes+1))
+	if err != nil {
+		return body, err
+	}
+	if len(data) > maxComparedBodyBytes {
+		data, body.truncated = data[:maxComparedBodyBytes], true
+	}
+	body.data = data
+	return body, nil
+}
+
+// decodeJSON decodes the body when it is declared as JSON and parses as JSON
+func (b responseBody) decodeJSON() (interface{}, bool) {
+	if !isJSONContentType(b.contentType) {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal(b.data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// describe names the body's format for issues, e.g. "text/html" or "empty"
+func (b responseBody) describe() string {
+	switch {
+	case len(b.data) == 0:
+		return "empty"
+	case isJSONContentType(b.contentType):
+		return "invalid JSON"
+	case b.contentType == "":
+		return "non-JSON"
+	}
+	mediaType, _, _ := strings.Cut(b.contentType, ";")
+	return strings.TrimSpace(mediaType)
+}
+
+// compareBodies compares the frontend and backend response bodies. When both are JSON, every
+// field missing from the backend or with a different type there is a critical issue; values
+// themselves are not compared. When only one side is JSON, the format mismatch is reported.
+// When neither is, the bodies are only compared by length.
+func (s *SyncService) compareBodies(frontend, backend responseBody, response *models.SyncValidationResponse) {
+	if frontend.truncated || backend.truncated {
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "comparison_truncated",
+			Field:       "body",
+			Expected:    fmt.Sprintf("max body %d bytes", maxComparedBodyBytes),
+			Actual:      "exceeded",
+			Severity:    "info",
+			Description: "A response body is too large to compare; the bodies were not compared",
+		})
+		return
+	}
+
+	expected, frontendJSON := frontend.decodeJSON()
+	actual, backendJSON := backend.decodeJSON()
+
+	switch {
+	case frontendJSON && backendJSON:
+		s.compareJSONBodies(expected, actual, response)
+	case frontendJSON:
+		response.IsCompatible = false
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "body_format_mismatch",
+			Field:       "body",
+			Expected:    "json",
+			Actual:      backend.describe(),
+			Severity:    "critical",
+			Description: fmt.Sprintf("Backend response body is %s, but the frontend response body is JSON", backend.describe()),
+		})
+		response.Suggestions = append(response.Suggestions, "Return a JSON body from the backend endpoint")
+	case backendJSON:
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "body_format_mismatch",
+			Field:       "body",
+			Expected:    frontend.describe(),
+			Actual:      "json",
+			Severity:    "warning",
+			Description: fmt.Sprintf("Backend response body is JSON, but the frontend response body is %s", frontend.describe()),
+		})
+	default:
+		compareBodyLengths(frontend, backend, response)
+	}
+}
+
+// compareBodyLengths reports bodies of different lengths. Non-JSON content naturally varies
+// between environments, so this is informational unless one side is empty.
+func compareBodyLengths(frontend, backend responseBody, response *models.SyncValidationResponse) {
+	if len(frontend.data) == len(backend.data) {
+		return
+	}
+
+	severity := "info"
+	if len(frontend.data) == 0 || len(backend.data) == 0 {
+		severity = "warning"
+	}
+	response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+		Type:        "body_length_mismatch",
+		Field:       "body",
+		Expected:    fmt.Sprintf("%d bytes", len(frontend.data)),
+		Actual:      fmt.Sprintf("%d bytes", len(backend.data)),
+		Severity:    severity,
+		Description: "Response bodies are not JSON and differ in length",
+	})
+}
+
+// compareJSONBodies adds an issue for every structural difference between two decoded JSON bodies
+func (s *SyncService) compareJSONBodies(expected, actual interface{}, response *models.SyncValidationResponse) {
+	comparison := &bodyComparison{limits: s.bodyLimits}
+	comparison.compare("", expected, actual, 0)
+
+	incompatible := false
+	for _, issue := range comparison.issues {
+		if issue.Severity == "critical" {
+			incompatible = true
+		}
+	}
+	response.Issues = append(response.Issues, comparison.issues...)
+	if incompatible {
+		response.IsCompatible = false
+		response.Suggestions = append(response.Suggestions, "Align the backend response body with the fields the frontend expects")
+	}
+
+	if comparison.truncated != "" {
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "comparison_truncated",
+			Field:       "body",
+			Expected:    comparison.truncated,
+			Actual:      "exceeded",
+			Severity:    "info",
+			Description: fmt.Sprintf("Body comparison stopped at %s; the remaining fields were not compared", comparison.truncated),
+		})
+		s.logger.Warn("Response body comparison truncated", map[string]interface{}{
+			"limit":  comparison.truncated,
+			"fields": comparison.fields,
+			"issues": len(comparison.issues),
+		})
+	}
+}
+
+// compare records the differences between expected and actual at path
+func (c *bodyComparison) compare(path string, expected, actual interface{}, depth int) {
+	if c.truncated != "" {
+		return
+	}
+	if depth > c.limits.MaxDepth {
+		c.truncated = fmt.Sprintf("max depth %d", c.limits.MaxDepth)
+		return
+	}
+	c.fields++
+	if c.fields > c.limits.MaxFields {
+		c.truncated = fmt.Sprintf("max fields %d", c.limits.MaxFields)
+		return
+	}
+
+	expectedType, actualType := jsonTypeOf(expected), jsonTypeOf(actual)
+	if expectedType != actualType {
+		c.add(models.SyncCompatibilityIssue{
+			Type:        "schema_mismatch",
+			Field:       bodyFieldName(path),
+			Expected:    expectedType,
+			Actual:      actualType,
+			Severity:    "critical",
+			Description: fmt.Sprintf("Field %s is %s in the backend response, expected %s", bodyFieldName(path), actualType, expectedType),
+		})
+		return
+	}
+
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue := actual.(map[string]interface{})
+		for _, key := range unionKeys(expectedValue, actualValue) {
+			if c.truncated != "" {
+				return
+			}
+
+			field := joinBodyPath(path, key)
+			expectedChild, inExpected := expectedValue[key]
+			actualChild, inActual := actualValue[key]
+			switch {
+			case !inActual:
+				c.add(models.SyncCompatibilityIssue{
+					Type:        "schema_mismatch",
+					Field:       field,
+					Expected:    jsonTypeOf(expectedChild),
+					Actual:      "missing",
+					Severity:    "critical",
+					Description: fmt.Sprintf("Field %s is missing from the backend response", field),
+				})
+			case !inExpected:
+				c.add(models.SyncCompatibilityIssue{
+					Type:        "schema_mismatch",
+					Field:       field,
+					Expected:    "missing",
+					Actual:      jsonTypeOf(actualChild),
+					Severity:    "info",
+					Description: fmt.Sprintf("Field %s is only present in the backend response", field),
+				})
+			default:
+				c.compare(field, expectedChild, actualChild, depth+1)
+			}
+		}
+	case []interface{}:
+		// Elements are compared pairwise; differing lengths are expected between environments
+		actualValue := actual.([]interface{})
+		for i := 0; i < len(expectedValue) && i < len(actualValue); i++ {
+			c.compare(fmt.Sprintf("%s[%d]", path, i), expectedValue[i], actualValue[i], depth+1)
+		}
+	}
+}
+
+// add records an issue, stopping the comparison once MaxDiffs have been recorded
+func (c *bodyComparison) add(issue models.SyncCompatibilityIssue) {
+	if len(c.issues) >= c.limits.MaxDiffs {
+		c.truncated = fmt.Sprintf("max diffs %d", c.limits.MaxDiffs)
+		return
+	}
+	c.issues = append(c.issues, issue)
+}
+
+// unionKeys returns the keys of both objects, sorted so issues are reported in a stable order
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinBodyPath appends an object key to a field path, in the dot notation used by contracts
+func joinBodyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// bodyFieldName names a field path for issues, using "body" for the top-level value
+func bodyFieldName(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+// isJSONContentType reports whether a Content-Type header denotes a JSON body
+func isJSONContentType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
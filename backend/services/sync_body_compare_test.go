@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compareBodyStrings runs the body comparison of a service over two JSON documents
+func compareBodyStrings(service *SyncService, frontend, backend string) *models.SyncValidationResponse {
+	return compareTypedBodies(service, "application/json", frontend, "application/json", backend)
+}
+
+// compareTypedBodies runs the body comparison of a service over two bodies with content types
+func compareTypedBodies(service *SyncService, frontendType, frontend, backendType, backend string) *models.SyncValidationResponse {
+	response := &models.SyncValidationResponse{IsCompatible: true}
+	service.compareBodies(
+		responseBody{contentType: frontendType, data: []byte(frontend)},
+		responseBody{contentType: backendType, data: []byte(backend)},
+		response)
+	return response
+}
+
+// nestedJSON builds an object nested depth levels deep, e.g. {"a":{"a":1}} for depth 2
+func nestedJSON(depth int) string {
+	return strings.Repeat(`{"a":`, depth) + "1" + strings.Repeat("}", depth)
+}
+
+func TestSyncService_compareBodies(t *testing.T) {
+	service := NewSyncService(nil)
+
+	t.Run("structural differences", func(t *testing.T) {
+		response := compareBodyStrings(service,
+			`{"id": 1, "name": "Ada", "profile": {"verified": true}, "tags": [{"label": "x"}]}`,
+			`{"id": "1", "name": "Grace", "profile": {}, "tags": [{"label": 2}], "extra": null}`)
+
+		assert.False(t, response.IsCompatible)
+		assert.Equal(t, []models.SyncCompatibilityIssue{
+			{Type: "schema_mismatch", Field: "extra", Expected: "missing", Actual: "null", Severity: "info",
+				Description: "Field extra is only present in the backend response"},
+			{Type: "schema_mismatch", Field: "id", Expected: "number", Actual: "string", Severity: "critical",
+				Description: "Field id is string in the backend response, expected number"},
+			{Type: "schema_mismatch", Field: "profile.verified", Expected: "boolean", Actual: "missing", Severity: "critical",
+				Description: "Field profile.verified is missing from the backend response"},
+			{Type: "schema_mismatch", Field: "tags[0].label", Expected: "string", Actual: "number", Severity: "critical",
+				Description: "Field tags[0].label is number in the backend response, expected string"},
+		}, response.Issues)
+		assert.Len(t, response.Suggestions, 1)
+	})
+
+	t.Run("matching structure with different values", func(t *testing.T) {
+		response := compareBodyStrings(service, `{"id": 1, "items": [1, 2, 3]}`, `{"id": 2, "items": [4]}`)
+		assert.True(t, response.IsCompatible)
+		assert.Empty(t, response.Issues)
+	})
+
+	t.Run("extra fields alone stay compatible", func(t *testing.T) {
+		response := compareBodyStrings(service, `{"id": 1}`, `{"id": 1, "created_at": "2024-01-15"}`)
+		assert.True(t, response.IsCompatible)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "info", response.Issues[0].Severity)
+	})
+
+}
+
+func TestSyncService_compareBodies_NonJSON(t *testing.T) {
+	service := NewSyncService(nil)
+
+	t.Run("backend not JSON", func(t *testing.T) {
+		response := compareTypedBodies(service, "application/json", `{"id": 1}`, "text/html; charset=utf-8", `<html>Bad Gateway</html>`)
+		assert.False(t, response.IsCompatible)
+		assert.Equal(t, []models.SyncCompatibilityIssue{
+			{Type: "body_format_mismatch", Field: "body", Expected: "json", Actual: "text/html", Severity: "critical",
+				Description: "Backend response body is text/html, but the frontend response body is JSON"},
+		}, response.Issues)
+		assert.Len(t, response.Suggestions, 1)
+	})
+
+	t.Run("invalid or empty JSON is not JSON", func(t *testing.T) {
+		response := compareTypedBodies(service, "application/json", `{"id": 1}`, "application/json", `{"id": `)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "invalid JSON", response.Issues[0].Actual)
+
+		response = compareTypedBodies(service, "application/json", `{"id": 1}`, "application/json", ``)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "empty", response.Issues[0].Actual)
+	})
+
+	t.Run("only the backend is JSON", func(t *testing.T) {
+		response := compareTypedBodies(service, "text/plain", `ok`, "application/json", `{"status": "ok"}`)
+		assert.True(t, response.IsCompatible)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "body_format_mismatch", response.Issues[0].Type)
+		assert.Equal(t, "text/plain", response.Issues[0].Expected)
+		assert.Equal(t, "warning", response.Issues[0].Severity)
+	})
+
+	t.Run("neither JSON falls back to length", func(t *testing.T) {
+		response := compareTypedBodies(service, "text/html", `<p>Hello</p>`, "text/html", `<p>Hello there</p>`)
+		assert.True(t, response.IsCompatible)
+		assert.Equal(t, []models.SyncCompatibilityIssue{
+			{Type: "body_length_mismatch", Field: "body", Expected: "12 bytes", Actual: "18 bytes", Severity: "info",
+				Description: "Response bodies are not JSON and differ in length"},
+		}, response.Issues)
+
+		response = compareTypedBodies(service, "text/plain", `pong`, "text/plain", ``)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "warning", response.Issues[0].Severity)
+
+		response = compareTypedBodies(service, "text/plain", `pong`, "text/plain", `PONG`)
+		assert.Empty(t, response.Issues)
+	})
+
+	t.Run("oversized bodies are not compared", func(t *testing.T) {
+		response := &models.SyncValidationResponse{IsCompatible: true}
+		service.compareBodies(
+			responseBody{contentType: "application/json", data: []byte(`{"id": 1}`)},
+			responseBody{contentType: "application/json", truncated: true},
+			response)
+		assert.True(t, response.IsCompatible)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "comparison_truncated", response.Issues[0].Type)
+	})
+}
+
+func TestSyncService_compareBodies_Limits(t *testing.T) {
+	truncation := func(response *models.SyncValidationResponse) *models.SyncCompatibilityIssue {
+		for i := range response.Issues {
+			if response.Issues[i].Type == "comparison_truncated" {
+				return &response.Issues[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("max depth", func(t *testing.T) {
+		service := NewSyncService(nil, SyncServiceConfig{BodyComparison: BodyComparisonLimits{MaxDepth: 3}})
+
+		response := compareBodyStrings(service, nestedJSON(3), nestedJSON(3))
+		assert.Nil(t, truncation(response))
+
+		response = compareBodyStrings(service, nestedJSON(500), nestedJSON(500))
+		issue := truncation(response)
+		require.NotNil(t, issue)
+		assert.Equal(t, "max depth 3", issue.Expected)
+		assert.Equal(t, "info", issue.Severity)
+		assert.True(t, response.IsCompatible)
+	})
+
+	t.Run("max fields", func(t *testing.T) {
+		service := NewSyncService(nil, SyncServiceConfig{BodyComparison: BodyComparisonLimits{MaxFields: 10}})
+
+		items := "[" + strings.TrimSuffix(strings.Repeat(`{"id": 1},`, 1000), ",") + "]"
+		response := compareBodyStrings(service, items, items)
+		issue := truncation(response)
+		require.NotNil(t, issue)
+		assert.Equal(t, "max fields 10", issue.Expected)
+	})
+
+	t.Run("max diffs", func(t *testing.T) {
+		service := NewSyncService(nil, SyncServiceConfig{BodyComparison: BodyComparisonLimits{MaxDiffs: 5}})
+
+		fields := make([]string, 50)
+		for i := range fields {
+			fields[i] = fmt.Sprintf(`"field%02d": 1`, i)
+		}
+		response := compareBodyStrings(service, "{"+strings.Join(fields, ",")+"}", `{}`)
+
+		// Five differences plus the truncation notice
+		require.Len(t, response.Issues, 6)
+		assert.Equal(t, "field00", response.Issues[0].Field)
+		assert.Equal(t, "max diffs 5", truncation(response).Expected)
+		assert.False(t, response.IsCompatible)
+	})
+
+	t.Run("unset limits use the defaults", func(t *testing.T) {
+		service := NewSyncService(nil, SyncServiceConfig{BodyComparison: BodyComparisonLimits{MaxDepth: 3}})
+		assert.Equal(t, BodyComparisonLimits{
+			MaxDepth:  3,
+			MaxFields: DefaultBodyComparisonLimits().MaxFields,
+			MaxDiffs:  DefaultBodyComparisonLimits().MaxDiffs,
+		}, service.bodyLimits)
+	})
+}
+
+func TestSyncService_ValidateEndpoint_ComparesBodies(t *testing.T) {
+	respond := func(contentType, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write([]byte(body))
+		}))
+	}
+
+	frontend := respond("application/json", `{"user": {"id": 1, "email": "ada@example.com"}}`)
+	defer frontend.Close()
+	backend := respond("application/json; charset=utf-8", `{"user": {"id": 1}}`)
+	defer backend.Close()
+	text := respond("text/plain", `{"user": {}}`)
+	defer text.Close()
+
+	service := NewSyncService(nil)
+
+	response, err := service.ValidateEndpoint(&models.SyncValidationRequest{
+		FrontendEndpoint: frontend.URL,
+		BackendEndpoint:  backend.URL,
+		Method:           "GET",
+	})
+	require.NoError(t, err)
+	assert.False(t, response.IsCompatible)
+
+	var fields []string
+	for _, issue := range response.Issues {
+		if issue.Type == "schema_mismatch" {
+			fields = append(fields, issue.Field)
+		}
+	}
+	assert.Equal(t, []string{"user.email"}, fields)
+
+	t.Run("a non-JSON backend body is a format mismatch", func(t *testing.T) {
+		response, err := service.ValidateEndpoint(&models.SyncValidationRequest{
+			FrontendEndpoint: frontend.URL,
+			BackendEndpoint:  text.URL,
+			Method:           "GET",
+		})
+		require.NoError(t, err)
+		assert.False(t, response.IsCompatible)
+		var types []string
+		for _, issue := range response.Issues {
+			types = append(types, issue.Type)
+		}
+		assert.ElementsMatch(t, []string{"header_mismatch", "body_format_mismatch"}, types)
+	})
+
+	t.Run("oversized bodies are read up to the limit", func(t *testing.T) {
+		large := respond("application/json", `{"data": "`+strings.Repeat("x", maxComparedBodyBytes)+`"}`)
+		defer large.Close()
+
+		response, err := service.ValidateEndpoint(&models.SyncValidationRequest{
+			FrontendEndpoint: frontend.URL,
+			BackendEndpoint:  large.URL,
+			Method:           "GET",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, response.Issues)
+		assert.Equal(t, "comparison_truncated", response.Issues[len(response.Issues)-1].Type)
+	})
+}
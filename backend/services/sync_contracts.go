@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrContractNotFound is returned when validating against a contract that was never stored
+var ErrContractNotFound = errors.New("endpoint contract not found")
+
+// ErrInvalidContract is returned when a contract has no fields or declares an unknown type
+var ErrInvalidContract = errors.New("invalid endpoint contract")
+
+// maxContractResponseBytes caps how much of a response body is read when checking a contract
+const maxContractResponseBytes = 1 << 20
+
+// StoreContract saves a contract, replacing any stored contract with the same name
+func (s *SyncService) StoreContract(contract models.EndpointContract) (*models.EndpointContract, error) {
+	contract, err := normalizeContract(contract)
+	if err != nil {
+		return nil, err
+	}
+	contract.UpdatedAt = time.Now()
+
+	s.contractsMu.Lock()
+	s.contracts[contract.Name] = &contract
+	s.contractsMu.Unlock()
+
+	s.logger.Info("Endpoint contract stored", map[string]interface{}{
+		"contract": contract.Name,
+		"method":   contract.Method,
+		"fields":   len(contract.Fields),
+	})
+
+	stored := contract
+	return &stored, nil
+}
+
+// ReplaceContracts replaces every stored contract with contracts. Nothing changes if any contract
+// is invalid. A later contract with the same name replaces an earlier one, as with StoreContract.
+func (s *SyncService) ReplaceContracts(contracts []models.EndpointContract) error {
+	now := time.Now()
+	replaced := make(map[string]*models.EndpointContract, len(contracts))
+	for _, contract := range contracts {
+		contract, err := normalizeContract(contract)
+		if err != nil {
+			return fmt.Errorf("contract %q: %w", contract.Name, err)
+		}
+		contract.UpdatedAt = now
+		replaced[contract.Name] = &contract
+	}
+
+	s.contractsMu.Lock()
+	s.contracts = replaced
+	s.contractsMu.Unlock()
+
+	s.logger.Info("Endpoint contracts replaced", map[string]interface{}{
+		"contracts": len(replaced),
+	})
+	return nil
+}
+
+// ListContracts returns every stored contract, ordered by name
+func (s *SyncService) ListContracts() []models.EndpointContract {
+	s.contractsMu.RLock()
+	contracts := make([]models.EndpointContract, 0, len(s.contracts))
+	for _, contract := range s.contracts {
+		contracts = append(contracts, *contract)
+	}
+	s.contractsMu.RUnlock()
+
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].Name < contracts[j].Name })
+	return contracts
+}
+
+// normalizeContract checks that a contract declares at least one field and only known field
+// types, trimming its field paths
+func normalizeContract(contract models.EndpointContract) (models.EndpointContract, error) {
+	if len(contract.Fields) == 0 {
+		return contract, fmt.Errorf("%w: at least one field is required", ErrInvalidContract)
+	}
+
+	fields := make(map[string]string, len(contract.Fields))
+	for path, fieldType := range contract.Fields {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return contract, fmt.Errorf("%w: field paths must not be empty", ErrInvalidContract)
+		}
+		if !slices.Contains(models.ContractFieldTypes, fieldType) {
+			return contract, fmt.Errorf("%w: field %q has unknown type %q (expected one of %s)",
+				ErrInvalidContract, path, fieldType, strings.Join(models.ContractFieldTypes, ", "))
+		}
+		fields[path] = fieldType
+	}
+	contract.Fields = fields
+	return contract, nil
+}
+
+// GetContract returns the stored contract with the given name
+func (s *SyncService) GetContract(name string) (*models.EndpointContract, error) {
+	s.contractsMu.RLock()
+	defer s.contractsMu.RUnlock()
+
+	contract, exists := s.contracts[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrContractNotFound, name)
+	}
+	copied := *contract
+	return &copied, nil
+}
+
+// ValidateContract calls a live endpoint and checks its response against a stored contract
+func (s *SyncService) ValidateContract(req *models.ContractValidationRequest) (*models.SyncValidationResponse, error) {
+	contract, err := s.GetContract(req.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Validating endpoint against contract", map[string]interface{}{
+		"contract": contract.Name,
+		"endpoint": req.Endpoint,
+		"method":   contract.Method,
+	})
+
+	release, err := s.limiter.Acquire(context.Background(), req.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	response := &models.SyncValidationResponse{
+		IsCompatible: true,
+		Issues:       []models.SyncCompatibilityIssue{},
+		Suggestions:  []string{},
+		ValidatedAt:  time.Now(),
+	}
+
+	resp, err := s.makeTestRequest(req.Endpoint, contract.Method, s.requestHeaders(req.Endpoint, req.Headers), req.Payload)
+	if err != nil {
+		response.IsCompatible = false
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "timeout",
+			Field:       "endpoint",
+			Expected:    "accessible",
+			Actual:      "error",
+			Severity:    "critical",
+			Description: fmt.Sprintf("Endpoint error: %v", err),
+		})
+		response.Suggestions = append(response.Suggestions, "Check if the server is running and accessible")
+		return response, nil
+	}
+	defer resp.Body.Close()
+
+	statusMatches := resp.StatusCode >= 200 && resp.StatusCode < 300
+	expectedStatus := "2xx"
+	if contract.StatusCode != 0 {
+		statusMatches = resp.StatusCode == contract.StatusCode
+		expectedStatus = fmt.Sprintf("%d", contract.StatusCode)
+	}
+	if !statusMatches {
+		response.IsCompatible = false
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "status_code_mismatch",
+			Field:       "status_code",
+			Expected:    expectedStatus,
+			Actual:      fmt.Sprintf("%d", resp.StatusCode),
+			Severity:    "warning",
+			Description: "Endpoint returned a different status code than the contract expects",
+		})
+		response.Suggestions = append(response.Suggestions, "Ensure the endpoint returns the status code agreed in the contract")
+	}
+
+	var body interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxContractResponseBytes)).Decode(&body); err != nil {
+		response.IsCompatible = false
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "schema_mismatch",
+			Field:       "body",
+			Expected:    "JSON",
+			Actual:      "invalid JSON",
+			Severity:    "critical",
+			Description: fmt.Sprintf("Response body could not be decoded: %v", err),
+		})
+		return response, nil
+	}
+
+	if checkContractFields(contract, body, response) {
+		response.Suggestions = append(response.Suggestions, "Update the endpoint or the contract so the response matches the agreed schema")
+	}
+
+	s.logger.Info("Contract validation completed", map[string]interface{}{
+		"contract":      contract.Name,
+		"is_compatible": response.IsCompatible,
+		"issues_count":  len(response.Issues),
+	})
+
+	return response, nil
+}
+
+// checkContractFields adds an issue for every contract field that is missing or has the wrong
+// type, and reports whether any were found
+func checkContractFields(contract *models.EndpointContract, body interface{}, response *models.SyncValidationResponse) bool {
+	paths := make([]string, 0, len(contract.Fields))
+	for path := range contract.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	found := false
+	for _, path := range paths {
+		expected := contract.Fields[path]
+
+		values, exists := contractValues(body, strings.Split(path, "."))
+		if !exists {
+			found = true
+			response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+				Type:        "schema_mismatch",
+				Field:       path,
+				Expected:    expected,
+				Actual:      "missing",
+				Severity:    "critical",
+				Description: fmt.Sprintf("Field %s is missing from the response", path),
+			})
+			continue
+		}
+
+		for _, value := range values {
+			if actual := jsonTypeOf(value); actual != expected {
+				found = true
+				response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+					Type:        "schema_mismatch",
+					Field:       path,
+					Expected:    expected,
+					Actual:      actual,
+					Severity:    "critical",
+					Description: fmt.Sprintf("Field %s is %s, expected %s", path, actual, expected),
+				})
+				break
+			}
+		}
+	}
+
+	if found {
+		response.IsCompatible = false
+	}
+	return found
+}
+
+// contractValues returns the values at a field path, descending into every element for
+// segments ending in []. The bool is false when the path does not exist.
+func contractValues(value interface{}, segments []string) ([]interface{}, bool) {
+	if len(segments) == 0 {
+		return []interface{}{value}, true
+	}
+
+	key, isArray := strings.CutSuffix(segments[0], "[]")
+	if key != "" {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	if !isArray {
+		return contractValues(value, segments[1:])
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		found, ok := contractValues(item, segments[1:])
+		if !ok {
+			return nil, false
+		}
+		values = append(values, found...)
+	}
+	return values, true
+}
+
+// jsonTypeOf names the JSON type of a decoded value using the ContractFieldTypes vocabulary
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
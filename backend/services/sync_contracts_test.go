@@ -0,0 +1,166 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncService_StoreContract(t *testing.T) {
+	service := NewSyncService(nil)
+
+	stored, err := service.StoreContract(models.EndpointContract{
+		Name:   "get-user",
+		Method: "GET",
+		Fields: map[string]string{" id ": "number", "email": "string"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"id": "number", "email": "string"}, stored.Fields)
+	assert.False(t, stored.UpdatedAt.IsZero())
+
+	fetched, err := service.GetContract("get-user")
+	require.NoError(t, err)
+	assert.Equal(t, stored.Fields, fetched.Fields)
+
+	// Storing again under the same name replaces the contract
+	_, err = service.StoreContract(models.EndpointContract{Name: "get-user", Method: "GET", Fields: map[string]string{"id": "string"}})
+	require.NoError(t, err)
+	fetched, err = service.GetContract("get-user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"id": "string"}, fetched.Fields)
+
+	t.Run("invalid contracts are rejected", func(t *testing.T) {
+		for _, fields := range []map[string]string{nil, {"id": "int"}, {"": "string"}} {
+			_, err := service.StoreContract(models.EndpointContract{Name: "bad", Method: "GET", Fields: fields})
+			assert.ErrorIs(t, err, ErrInvalidContract)
+		}
+		_, err := service.GetContract("bad")
+		assert.ErrorIs(t, err, ErrContractNotFound)
+	})
+}
+
+func TestSyncService_ValidateContract(t *testing.T) {
+	body := `{"id": 1, "email": "ada@example.com", "profile": {"verified": true}, "roles": [{"name": "admin"}, {"name": 7}], "tags": []}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/1":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		case "/created":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 2}`))
+		case "/text":
+			w.Write([]byte("not json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		contract       models.EndpointContract
+		path           string
+		expectedIssues []models.SyncCompatibilityIssue
+	}{
+		{
+			name: "matching response",
+			contract: models.EndpointContract{Fields: map[string]string{
+				"id": "number", "email": "string", "profile": "object", "profile.verified": "boolean", "roles": "array", "tags[]": "string",
+			}},
+			path: "/users/1",
+		},
+		{
+			name:     "missing field",
+			contract: models.EndpointContract{Fields: map[string]string{"id": "number", "name": "string"}},
+			path:     "/users/1",
+			expectedIssues: []models.SyncCompatibilityIssue{
+				{Type: "schema_mismatch", Field: "name", Expected: "string", Actual: "missing", Severity: "critical"},
+			},
+		},
+		{
+			name:     "wrong type",
+			contract: models.EndpointContract{Fields: map[string]string{"id": "string", "profile.verified": "boolean"}},
+			path:     "/users/1",
+			expectedIssues: []models.SyncCompatibilityIssue{
+				{Type: "schema_mismatch", Field: "id", Expected: "string", Actual: "number", Severity: "critical"},
+			},
+		},
+		{
+			name:     "array elements are checked",
+			contract: models.EndpointContract{Fields: map[string]string{"roles[].name": "string", "roles[].id": "number"}},
+			path:     "/users/1",
+			expectedIssues: []models.SyncCompatibilityIssue{
+				{Type: "schema_mismatch", Field: "roles[].id", Expected: "number", Actual: "missing", Severity: "critical"},
+				{Type: "schema_mismatch", Field: "roles[].name", Expected: "string", Actual: "number", Severity: "critical"},
+			},
+		},
+		{
+			name:     "expected status code",
+			contract: models.EndpointContract{StatusCode: http.StatusCreated, Fields: map[string]string{"id": "number"}},
+			path:     "/created",
+		},
+		{
+			name:     "unexpected status code",
+			contract: models.EndpointContract{StatusCode: http.StatusOK, Fields: map[string]string{"id": "number"}},
+			path:     "/created",
+			expectedIssues: []models.SyncCompatibilityIssue{
+				{Type: "status_code_mismatch", Field: "status_code", Expected: "200", Actual: "201", Severity: "warning"},
+			},
+		},
+		{
+			name:     "non-JSON body",
+			contract: models.EndpointContract{Fields: map[string]string{"id": "number"}},
+			path:     "/text",
+			expectedIssues: []models.SyncCompatibilityIssue{
+				{Type: "schema_mismatch", Field: "body", Expected: "JSON", Actual: "invalid JSON", Severity: "critical"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewSyncService(nil)
+			tt.contract.Name = "contract"
+			tt.contract.Method = "GET"
+			_, err := service.StoreContract(tt.contract)
+			require.NoError(t, err)
+
+			response, err := service.ValidateContract(&models.ContractValidationRequest{
+				Contract: "contract",
+				Endpoint: server.URL + tt.path,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, len(tt.expectedIssues) == 0, response.IsCompatible)
+			require.Len(t, response.Issues, len(tt.expectedIssues))
+			for i, expected := range tt.expectedIssues {
+				issue := response.Issues[i]
+				issue.Description = ""
+				assert.Equal(t, expected, issue)
+			}
+		})
+	}
+
+	t.Run("unknown contract", func(t *testing.T) {
+		service := NewSyncService(nil)
+		_, err := service.ValidateContract(&models.ContractValidationRequest{Contract: "missing", Endpoint: server.URL})
+		assert.ErrorIs(t, err, ErrContractNotFound)
+	})
+
+	t.Run("unreachable endpoint", func(t *testing.T) {
+		service := NewSyncService(nil)
+		_, err := service.StoreContract(models.EndpointContract{Name: "contract", Method: "GET", Fields: map[string]string{"id": "number"}})
+		require.NoError(t, err)
+
+		response, err := service.ValidateContract(&models.ContractValidationRequest{Contract: "contract", Endpoint: "http://127.0.0.1:1/users"})
+		require.NoError(t, err)
+		assert.False(t, response.IsCompatible)
+		require.Len(t, response.Issues, 1)
+		assert.Equal(t, "timeout", response.Issues[0].Type)
+	})
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// Sides of a sync validation a header assertion can target
+const (
+	syncTargetFrontend = "frontend"
+	syncTargetBackend  = "backend"
+	syncTargetBoth     = "both"
+)
+
+// compareHeaders checks the request's header assertions against both responses and compares the
+// headers listed in CompareHeaders between them. Every failure is a header_mismatch issue that
+// makes the endpoints incompatible, so contract drift such as a dropped X-RateLimit-Limit
+// header is caught alongside status and body differences.
+func (s *SyncService) compareHeaders(req *models.SyncValidationRequest, frontend, backend http.Header, response *models.SyncValidationResponse) {
+	issues := len(response.Issues)
+
+	for _, assertion := range req.HeaderAssertions {
+		target := assertion.Target
+		if target == "" {
+			target = syncTargetBoth
+		}
+		if target == syncTargetFrontend || target == syncTargetBoth {
+			s.checkHeaderAssertion(assertion, "Frontend", frontend, response)
+		}
+		if target == syncTargetBackend || target == syncTargetBoth {
+			s.checkHeaderAssertion(assertion, "Backend", backend, response)
+		}
+	}
+
+	for _, name := range req.CompareHeaders {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		frontendValue, frontendSent := headerValue(frontend, name)
+		backendValue, backendSent := headerValue(backend, name)
+		if frontendSent == backendSent && frontendValue == backendValue {
+			continue
+		}
+
+		response.IsCompatible = false
+		response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+			Type:        "header_mismatch",
+			Field:       "headers." + name,
+			Expected:    describeHeaderValue(frontendValue, frontendSent),
+			Actual:      describeHeaderValue(backendValue, backendSent),
+			Severity:    "warning",
+			Description: fmt.Sprintf("Frontend and backend returned different %s headers", name),
+		})
+	}
+
+	if len(response.Issues) > issues {
+		response.Suggestions = append(response.Suggestions, "Ensure both endpoints send the expected response headers")
+	}
+}
+
+// checkHeaderAssertion reports a header_mismatch issue when header fails assertion; side names
+// the response being checked
+func (s *SyncService) checkHeaderAssertion(assertion models.SyncHeaderAssertion, side string, header http.Header, response *models.SyncValidationResponse) {
+	name := http.CanonicalHeaderKey(assertion.Name)
+	value, sent := headerValue(header, name)
+
+	var expected, problem string
+	switch {
+	case assertion.Absent:
+		expected = "absent"
+		if sent {
+			problem = "is sent but should be absent"
+		}
+	case !sent:
+		expected, problem = "present", "is missing"
+		if assertion.Equals != "" {
+			expected = assertion.Equals
+		} else if assertion.Contains != "" {
+			expected = "contains " + assertion.Contains
+		}
+	case assertion.Equals != "" && value != assertion.Equals:
+		expected, problem = assertion.Equals, "has an unexpected value"
+	case assertion.Contains != "" && !strings.Contains(value, assertion.Contains):
+		expected, problem = "contains "+assertion.Contains, "has an unexpected value"
+	}
+	if problem == "" {
+		return
+	}
+
+	response.IsCompatible = false
+	response.Issues = append(response.Issues, models.SyncCompatibilityIssue{
+		Type:        "header_mismatch",
+		Field:       "headers." + name,
+		Expected:    expected,
+		Actual:      describeHeaderValue(value, sent),
+		Severity:    "warning",
+		Description: fmt.Sprintf("%s response header %s %s", side, name, problem),
+	})
+}
+
+// headerValue returns every value of the named header joined with ", ", and whether it was sent
+func headerValue(header http.Header, name string) (string, bool) {
+	values := header.Values(name)
+	return strings.Join(values, ", "), len(values) > 0
+}
+
+// describeHeaderValue is how a header value is reported in an issue
+func describeHeaderValue(value string, sent bool) string {
+	if !sent {
+		return "missing"
+	}
+	return value
+}
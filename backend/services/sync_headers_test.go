@@ -0,0 +1,132 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncService_ValidateEndpoint_ResponseHeaders(t *testing.T) {
+	// Both servers return JSON, negotiating the API version from the Accept header
+	headerServer := func(headers map[string]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if r.Header.Get("Accept") == "application/vnd.api.v2+json" {
+				w.Header().Set("Api-Version", "2")
+			} else {
+				w.Header().Set("Api-Version", "1")
+			}
+			w.Write([]byte(`{"status": "ok"}`))
+		}))
+	}
+	frontendServer := headerServer(map[string]string{"X-RateLimit-Limit": "100", "Cache-Control": "no-store"})
+	defer frontendServer.Close()
+	backendServer := headerServer(map[string]string{"Cache-Control": "max-age=60", "X-Powered-By": "Express"})
+	defer backendServer.Close()
+
+	service := NewSyncService(nil)
+	validate := func(req models.SyncValidationRequest) *models.SyncValidationResponse {
+		req.FrontendEndpoint = frontendServer.URL + "/api/users"
+		req.BackendEndpoint = backendServer.URL + "/api/users"
+		req.Method = "GET"
+		response, err := service.ValidateEndpoint(&req)
+		require.NoError(t, err)
+		return response
+	}
+	headerIssues := func(response *models.SyncValidationResponse) []models.SyncCompatibilityIssue {
+		var issues []models.SyncCompatibilityIssue
+		for _, issue := range response.Issues {
+			if issue.Type == "header_mismatch" {
+				issues = append(issues, issue)
+			}
+		}
+		return issues
+	}
+
+	t.Run("passing assertions", func(t *testing.T) {
+		response := validate(models.SyncValidationRequest{
+			Headers: map[string]string{"Accept": "application/vnd.api.v2+json"},
+			HeaderAssertions: []models.SyncHeaderAssertion{
+				{Name: "content-type", Contains: "application/json"},
+				{Name: "API-Version", Equals: "2"},
+				{Name: "X-RateLimit-Limit", Target: "frontend"},
+				{Name: "X-Powered-By", Target: "frontend", Absent: true},
+			},
+			CompareHeaders: []string{"api-version", "Content-Type"},
+		})
+		assert.True(t, response.IsCompatible)
+		assert.Empty(t, headerIssues(response))
+	})
+
+	t.Run("failing assertions", func(t *testing.T) {
+		response := validate(models.SyncValidationRequest{
+			HeaderAssertions: []models.SyncHeaderAssertion{
+				{Name: "X-RateLimit-Limit"},
+				{Name: "Api-Version", Equals: "2", Target: "backend"},
+				{Name: "X-Powered-By", Absent: true},
+				{Name: "Content-Type", Contains: "text/html", Target: "frontend"},
+			},
+		})
+		assert.False(t, response.IsCompatible)
+		assert.Contains(t, response.Suggestions, "Ensure both endpoints send the expected response headers")
+		assert.Equal(t, []models.SyncCompatibilityIssue{
+			{Type: "header_mismatch", Field: "headers.X-Ratelimit-Limit", Expected: "present", Actual: "missing", Severity: "warning",
+				Description: "Backend response header X-Ratelimit-Limit is missing"},
+			{Type: "header_mismatch", Field: "headers.Api-Version", Expected: "2", Actual: "1", Severity: "warning",
+				Description: "Backend response header Api-Version has an unexpected value"},
+			{Type: "header_mismatch", Field: "headers.X-Powered-By", Expected: "absent", Actual: "Express", Severity: "warning",
+				Description: "Backend response header X-Powered-By is sent but should be absent"},
+			{Type: "header_mismatch", Field: "headers.Content-Type", Expected: "contains text/html", Actual: "application/json", Severity: "warning",
+				Description: "Frontend response header Content-Type has an unexpected value"},
+		}, headerIssues(response))
+	})
+
+	t.Run("compared headers", func(t *testing.T) {
+		response := validate(models.SyncValidationRequest{
+			CompareHeaders: []string{"Cache-Control", "X-RateLimit-Limit", "Api-Version", ""},
+		})
+		assert.False(t, response.IsCompatible)
+		issues := headerIssues(response)
+		require.Len(t, issues, 2)
+		assert.Equal(t, "headers.Cache-Control", issues[0].Field)
+		assert.Equal(t, "no-store", issues[0].Expected)
+		assert.Equal(t, "max-age=60", issues[0].Actual)
+		assert.Equal(t, "headers.X-Ratelimit-Limit", issues[1].Field)
+		assert.Equal(t, "100", issues[1].Expected)
+		assert.Equal(t, "missing", issues[1].Actual)
+	})
+
+	t.Run("without header checks", func(t *testing.T) {
+		response := validate(models.SyncValidationRequest{})
+		assert.True(t, response.IsCompatible)
+		assert.Empty(t, headerIssues(response))
+	})
+}
+
+func TestSyncHeaderAssertion_Validation(t *testing.T) {
+	req := models.SyncValidationRequest{
+		FrontendEndpoint: "http://localhost:3000/api/users",
+		BackendEndpoint:  "http://localhost:8080/api/users",
+		Method:           "GET",
+		HeaderAssertions: []models.SyncHeaderAssertion{{Name: "Api-Version", Target: "both"}},
+	}
+	assert.NoError(t, utils.ValidateStruct(&req))
+
+	req.HeaderAssertions = []models.SyncHeaderAssertion{{Target: "both"}}
+	err := utils.ValidateStruct(&req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "header_assertions[0].name")
+
+	req.HeaderAssertions = []models.SyncHeaderAssertion{{Name: "Api-Version", Target: "sideways"}}
+	err = utils.ValidateStruct(&req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "header_assertions[0].target")
+}
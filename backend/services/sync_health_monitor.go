@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// environmentHealth is the outcome of health-checking both sides of an environment
+type environmentHealth struct {
+	frontendHealthy, backendHealthy bool
+	frontendPath, backendPath       string
+	frontendErr, backendErr         error
+	checkedAt                       time.Time
+}
+
+// checkEnvironmentHealth health-checks the frontend and backend of an environment
+func (s *SyncService) checkEnvironmentHealth(frontendURL, backendURL string) environmentHealth {
+	var health environmentHealth
+	health.frontendHealthy, health.frontendPath, health.frontendErr = s.checkURLHealth(frontendURL)
+	health.backendHealthy, health.backendPath, health.backendErr = s.checkURLHealth(backendURL)
+	health.checkedAt = time.Now()
+	return health
+}
+
+// apply sets the status, check time and health metadata of env from the check outcome
+func (h environmentHealth) apply(env *models.SyncEnvironment) {
+	env.LastChecked = h.checkedAt
+	env.Metadata = make(map[string]string)
+
+	// Record which health paths answered so operators can see the convention each side uses
+	if h.frontendHealthy {
+		env.Metadata["frontend_health_path"] = h.frontendPath
+	}
+	if h.backendHealthy {
+		env.Metadata["backend_health_path"] = h.backendPath
+	}
+
+	// Determine environment status
+	if h.frontendHealthy && h.backendHealthy {
+		env.Status = "active"
+		env.Metadata["connection_status"] = "healthy"
+	} else {
+		env.Status = "error"
+		if h.frontendErr != nil {
+			env.Metadata["frontend_error"] = h.frontendErr.Error()
+		}
+		if h.backendErr != nil {
+			env.Metadata["backend_error"] = h.backendErr.Error()
+		}
+	}
+}
+
+// StartHealthMonitor re-checks every connected environment each HealthCheckInterval until ctx
+// is done or Stop is called. It does nothing when the interval is 0 or the monitor is running.
+func (s *SyncService) StartHealthMonitor(ctx context.Context) {
+	if s.healthInterval <= 0 {
+		return
+	}
+
+	s.monitorMu.Lock()
+	defer s.monitorMu.Unlock()
+	if s.monitorCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.monitorCancel = cancel
+	s.monitorDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.healthInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.CheckEnvironments()
+			}
+		}
+	}()
+
+	s.logger.Info("Sync environment health monitor started", map[string]interface{}{
+		"interval": s.healthInterval.String(),
+	})
+}
+
+// Stop stops the health monitor, waiting for a pass in progress to finish
+func (s *SyncService) Stop() {
+	s.monitorMu.Lock()
+	cancel, done := s.monitorCancel, s.monitorDone
+	s.monitorCancel, s.monitorDone = nil, nil
+	s.monitorMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// CheckEnvironments runs one health-check pass over every connected environment, updating
+// their status and check time. A sync_status_update is broadcast for each environment that
+// turned healthy or unhealthy.
+func (s *SyncService) CheckEnvironments() {
+	s.mutex.RLock()
+	snapshot := make([]models.SyncEnvironment, 0, len(s.environments))
+	for _, env := range s.environments {
+		snapshot = append(snapshot, *env)
+	}
+	s.mutex.RUnlock()
+
+	// Check without holding the lock; a slow environment shouldn't block status reads
+	results := make([]environmentHealth, len(snapshot))
+	var wg sync.WaitGroup
+	for i := range snapshot {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = s.checkEnvironmentHealth(snapshot[i].FrontendURL, snapshot[i].BackendURL)
+		}(i)
+	}
+	wg.Wait()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, checked := range snapshot {
+		current, exists := s.environments[checked.Name]
+		// Skip environments removed or reconnected to other URLs during the pass
+		if !exists || current.FrontendURL != checked.FrontendURL || current.BackendURL != checked.BackendURL {
+			continue
+		}
+
+		// Replace rather than mutate, since GetEnvironments hands out the stored pointers
+		updated := *current
+		results[i].apply(&updated)
+		s.environments[checked.Name] = &updated
+
+		if updated.Status != current.Status {
+			s.logger.Warn("Sync environment health changed", map[string]interface{}{
+				"environment":     updated.Name,
+				"status":          updated.Status,
+				"previous_status": current.Status,
+			})
+			s.broadcastEnvironmentHealth("environment_health_change", &updated, current.Status, results[i])
+		}
+	}
+}
+
+// HealthCheckCooldownError is returned by CheckEnvironment for an environment checked less than
+// HealthCheckCooldown ago
+type HealthCheckCooldownError struct {
+	Environment string
+	RetryAfter  time.Duration // Until the environment may be checked again
+}
+
+func (e *HealthCheckCooldownError) Error() string {
+	return fmt.Sprintf("environment %q was checked recently; retry in %s", e.Environment, e.RetryAfter.Round(time.Second))
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds, for a Retry-After header
+func (e *HealthCheckCooldownError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// CheckEnvironment health-checks one environment right away instead of waiting for the health
+// monitor, updating its status and broadcasting the result whether or not the status changed.
+// Unlike reconnecting, it leaves the environment's URLs and default headers as they are.
+func (s *SyncService) CheckEnvironment(name string) (*models.SyncEnvironmentCheckResponse, error) {
+	s.mutex.RLock()
+	env, exists := s.environments[name]
+	var checked models.SyncEnvironment
+	if exists {
+		checked = *env
+	}
+	s.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrEnvironmentNotFound, name)
+	}
+	if s.healthCooldown > 0 && !checked.LastChecked.IsZero() {
+		if wait := s.healthCooldown - time.Since(checked.LastChecked); wait > 0 {
+			return nil, &HealthCheckCooldownError{Environment: name, RetryAfter: wait}
+		}
+	}
+
+	// Check without holding the lock, as CheckEnvironments does
+	health := s.checkEnvironmentHealth(checked.FrontendURL, checked.BackendURL)
+
+	s.mutex.Lock()
+	current, exists := s.environments[name]
+	if !exists {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrEnvironmentNotFound, name)
+	}
+	if current.FrontendURL != checked.FrontendURL || current.BackendURL != checked.BackendURL {
+		// Reconnected to other URLs during the check, which checked the new ones itself
+		result := *current
+		s.mutex.Unlock()
+		return &models.SyncEnvironmentCheckResponse{
+			Environment:    result,
+			PreviousStatus: checked.Status,
+			Health: s.healthStatus(
+				result.Metadata["frontend_health_path"] != "",
+				result.Metadata["backend_health_path"] != "",
+			),
+		}, nil
+	}
+
+	updated := *current
+	health.apply(&updated)
+	s.environments[name] = &updated
+	s.mutex.Unlock()
+
+	s.logger.Info("Sync environment checked on demand", map[string]interface{}{
+		"environment":     name,
+		"status":          updated.Status,
+		"previous_status": current.Status,
+	})
+	s.broadcastEnvironmentHealth("environment_health_check", &updated, current.Status, health)
+
+	return &models.SyncEnvironmentCheckResponse{
+		Environment:    updated,
+		PreviousStatus: current.Status,
+		Health:         s.healthStatus(health.frontendHealthy, health.backendHealthy),
+	}, nil
+}
+
+// MarkEnvironmentUnhealthy sets an environment's status to error as if both of its sides had
+// failed a health check with reason, and broadcasts the change like the health monitor does. The
+// environment isn't contacted; its next health check reports its real status again.
+func (s *SyncService) MarkEnvironmentUnhealthy(name, reason string) (*models.SyncEnvironment, error) {
+	failure := errors.New(reason)
+	health := environmentHealth{frontendErr: failure, backendErr: failure, checkedAt: time.Now()}
+
+	s.mutex.Lock()
+	current, exists := s.environments[name]
+	if !exists {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrEnvironmentNotFound, name)
+	}
+	updated := *current
+	health.apply(&updated)
+	s.environments[name] = &updated
+	s.mutex.Unlock()
+
+	s.logger.Warn("Sync environment marked unhealthy", map[string]interface{}{
+		"environment":     name,
+		"reason":          reason,
+		"previous_status": current.Status,
+	})
+	s.broadcastEnvironmentHealth("environment_health_change", &updated, current.Status, health)
+
+	return &updated, nil
+}
+
+// broadcastEnvironmentHealth announces the outcome of a health check: updateType is
+// environment_health_change when the monitor saw an environment turn healthy or unhealthy, and
+// environment_health_check for on-demand checks
+func (s *SyncService) broadcastEnvironmentHealth(updateType string, env *models.SyncEnvironment, previousStatus string, health environmentHealth) {
+	if s.wsHub == nil {
+		return
+	}
+
+	s.wsHub.BroadcastToAll("sync_status_update", map[string]interface{}{
+		"type":            updateType,
+		"environment":     env.Name,
+		"status":          env.Status,
+		"previous_status": previousStatus,
+		"last_checked":    env.LastChecked,
+		"health":          s.healthStatus(health.frontendHealthy, health.backendHealthy),
+		"timestamp":       time.Now(),
+	})
+}
+
+// healthStatus summarizes the health of an environment's two sides
+func (s *SyncService) healthStatus(frontendHealthy, backendHealthy bool) models.HealthStatus {
+	return models.HealthStatus{
+		Frontend: frontendHealthy,
+		Backend:  backendHealthy,
+		Database: true,
+		Message:  s.getHealthMessage(frontendHealthy, backendHealthy),
+	}
+}
+
+// HealthCheck fails while any connected environment failed its last health check. It reports
+// what the monitor last saw rather than checking the environments again.
+func (s *SyncService) HealthCheck(ctx context.Context) error {
+	s.mutex.RLock()
+	var failing []string
+	for name, env := range s.environments {
+		if env.Status == "error" {
+			failing = append(failing, name)
+		}
+	}
+	s.mutex.RUnlock()
+
+	if len(failing) == 0 {
+		return nil
+	}
+	sort.Strings(failing)
+	return fmt.Errorf("sync environments unhealthy: %s", strings.Join(failing, ", "))
+}
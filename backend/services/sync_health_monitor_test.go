@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// toggleHealthServer answers its health path with 200 while healthy is set and 503 otherwise
+func toggleHealthServer(healthy *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+}
+
+func TestSyncService_CheckEnvironments(t *testing.T) {
+	var backendHealthy atomic.Bool
+	backendHealthy.Store(true)
+	frontend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer frontend.Close()
+	backend := toggleHealthServer(&backendHealthy)
+	defer backend.Close()
+
+	var mu sync.Mutex
+	var changes []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "sync_status_update", mock.Anything).Run(func(args mock.Arguments) {
+		data := args.Get(1).(map[string]interface{})
+		if data["type"] == "environment_health_change" {
+			mu.Lock()
+			changes = append(changes, data)
+			mu.Unlock()
+		}
+	}).Return()
+
+	service := NewSyncService(mockHub, SyncServiceConfig{HealthPaths: []string{"/health"}})
+	_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+		Environment: "staging",
+		FrontendURL: frontend.URL,
+		BackendURL:  backend.URL,
+	})
+	require.NoError(t, err)
+	connected := service.GetEnvironments()["staging"]
+	require.Equal(t, "active", connected.Status)
+
+	t.Run("a backend going down is noticed", func(t *testing.T) {
+		backendHealthy.Store(false)
+		service.CheckEnvironments()
+
+		env := service.GetEnvironments()["staging"]
+		assert.Equal(t, "error", env.Status)
+		assert.True(t, env.LastChecked.After(connected.LastChecked))
+		assert.Contains(t, env.Metadata["backend_error"], "503")
+		// The environment handed out earlier is not modified in place
+		assert.Equal(t, "active", connected.Status)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, "staging", changes[0]["environment"])
+		assert.Equal(t, "error", changes[0]["status"])
+		assert.Equal(t, "active", changes[0]["previous_status"])
+		health := changes[0]["health"].(models.HealthStatus)
+		assert.True(t, health.Frontend)
+		assert.False(t, health.Backend)
+	})
+
+	t.Run("an unchanged status is not broadcast again", func(t *testing.T) {
+		before := service.GetEnvironments()["staging"].LastChecked
+		service.CheckEnvironments()
+
+		assert.True(t, service.GetEnvironments()["staging"].LastChecked.After(before))
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("recovery is noticed", func(t *testing.T) {
+		backendHealthy.Store(true)
+		service.CheckEnvironments()
+
+		env := service.GetEnvironments()["staging"]
+		assert.Equal(t, "active", env.Status)
+		assert.Equal(t, "/health", env.Metadata["backend_health_path"])
+		assert.NotContains(t, env.Metadata, "backend_error")
+
+		require.Len(t, changes, 2)
+		assert.Equal(t, "active", changes[1]["status"])
+		assert.Equal(t, "error", changes[1]["previous_status"])
+	})
+
+	t.Run("no environments", func(t *testing.T) {
+		require.NoError(t, service.RemoveEnvironment("staging"))
+		service.CheckEnvironments()
+		assert.Empty(t, service.GetEnvironments())
+	})
+}
+
+func TestSyncService_CheckEnvironment(t *testing.T) {
+	var backendHealthy atomic.Bool
+	backendHealthy.Store(true)
+	frontend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer frontend.Close()
+	backend := toggleHealthServer(&backendHealthy)
+	defer backend.Close()
+
+	var mu sync.Mutex
+	var checks []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "sync_status_update", mock.Anything).Run(func(args mock.Arguments) {
+		data := args.Get(1).(map[string]interface{})
+		if data["type"] == "environment_health_check" {
+			mu.Lock()
+			checks = append(checks, data)
+			mu.Unlock()
+		}
+	}).Return()
+
+	service := NewSyncService(mockHub, SyncServiceConfig{HealthPaths: []string{"/health"}})
+	_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+		Environment:    "staging",
+		FrontendURL:    frontend.URL,
+		BackendURL:     backend.URL,
+		DefaultHeaders: map[string]string{"Authorization": "Bearer staging"},
+	})
+	require.NoError(t, err)
+
+	t.Run("returns and broadcasts the fresh status", func(t *testing.T) {
+		backendHealthy.Store(false)
+		result, err := service.CheckEnvironment("staging")
+		require.NoError(t, err)
+
+		assert.Equal(t, "error", result.Environment.Status)
+		assert.Equal(t, "active", result.PreviousStatus)
+		assert.True(t, result.Health.Frontend)
+		assert.False(t, result.Health.Backend)
+		assert.Equal(t, "error", service.GetEnvironments()["staging"].Status)
+		// Other state of the environment is kept, unlike reconnecting
+		assert.Equal(t, "Bearer staging", service.GetEnvironments()["staging"].DefaultHeaders["Authorization"])
+
+		require.Len(t, checks, 1)
+		assert.Equal(t, "staging", checks[0]["environment"])
+		assert.Equal(t, "error", checks[0]["status"])
+	})
+
+	t.Run("an unchanged status is broadcast too", func(t *testing.T) {
+		result, err := service.CheckEnvironment("staging")
+		require.NoError(t, err)
+		assert.Equal(t, "error", result.PreviousStatus)
+		assert.Len(t, checks, 2)
+	})
+
+	t.Run("unknown environments", func(t *testing.T) {
+		_, err := service.CheckEnvironment("missing")
+		assert.ErrorIs(t, err, ErrEnvironmentNotFound)
+	})
+
+	t.Run("checks within the cooldown are refused", func(t *testing.T) {
+		service.healthCooldown = time.Minute
+		defer func() { service.healthCooldown = 0 }()
+
+		_, err := service.CheckEnvironment("staging")
+		var cooldownErr *HealthCheckCooldownError
+		require.ErrorAs(t, err, &cooldownErr)
+		assert.Equal(t, "staging", cooldownErr.Environment)
+		assert.InDelta(t, time.Minute.Seconds(), cooldownErr.RetryAfter.Seconds(), 5)
+		assert.Equal(t, 60, cooldownErr.RetryAfterSeconds())
+		assert.Len(t, checks, 2)
+	})
+}
+
+func TestSyncService_HealthMonitor(t *testing.T) {
+	var healthy atomic.Bool
+	server := toggleHealthServer(&healthy)
+	defer server.Close()
+
+	service := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}, HealthCheckInterval: 10 * time.Millisecond})
+	_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+		Environment: "staging",
+		FrontendURL: server.URL,
+		BackendURL:  server.URL,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "error", service.GetEnvironments()["staging"].Status)
+
+	service.StartHealthMonitor(context.Background())
+	service.StartHealthMonitor(context.Background()) // Already running; no second monitor
+
+	healthy.Store(true)
+	assert.Eventually(t, func() bool {
+		return service.GetEnvironments()["staging"].Status == "active"
+	}, time.Second, 5*time.Millisecond)
+
+	service.Stop()
+	service.Stop()
+
+	// Nothing re-checks the environment once stopped
+	healthy.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "active", service.GetEnvironments()["staging"].Status)
+
+	t.Run("cancelling the context stops the monitor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		service.StartHealthMonitor(ctx)
+		cancel()
+		service.Stop()
+	})
+
+	t.Run("disabled without an interval", func(t *testing.T) {
+		disabled := NewSyncService(nil)
+		disabled.StartHealthMonitor(context.Background())
+		assert.Nil(t, disabled.monitorCancel)
+		disabled.Stop()
+	})
+}
+
+func TestSyncService_HealthCheck(t *testing.T) {
+	service := NewSyncService(nil)
+	assert.NoError(t, service.HealthCheck(context.Background()), "no environments")
+
+	service.environments["staging"] = &models.SyncEnvironment{Name: "staging", Status: "error"}
+	service.environments["production"] = &models.SyncEnvironment{Name: "production", Status: "active"}
+	service.environments["dev"] = &models.SyncEnvironment{Name: "dev", Status: "error"}
+	assert.EqualError(t, service.HealthCheck(context.Background()), "sync environments unhealthy: dev, staging")
+
+	delete(service.environments, "staging")
+	delete(service.environments, "dev")
+	assert.NoError(t, service.HealthCheck(context.Background()))
+}
@@ -1,78 +1,218 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 )
 
+// ErrEnvironmentLimitReached is returned when connecting a new environment would exceed MaxEnvironments
+var ErrEnvironmentLimitReached = errors.New("sync environment limit reached")
+
+// ErrEnvironmentNotFound is returned for environment names that aren't connected
+var ErrEnvironmentNotFound = errors.New("sync environment not found")
+
+// ErrHealthConnectionRefused is returned when nothing listens at a health-checked URL
+var ErrHealthConnectionRefused = errors.New("connection refused")
+
+// ErrHealthUnhealthyStatus is returned when every health path answered with a 4xx or 5xx status
+var ErrHealthUnhealthyStatus = errors.New("unhealthy status code")
+
 // SyncService handles environment synchronization and connection management
 type SyncService struct {
-	environments map[string]*models.SyncEnvironment
-	mutex        sync.RWMutex
-	logger       *utils.Logger
-	httpClient   *http.Client
-	wsHub        WebSocketBroadcaster
+	environments    map[string]*models.SyncEnvironment
+	maxEnvironments int // 0 means unlimited
+	mutex           sync.RWMutex
+	logger          *utils.Logger
+	httpClient      *http.Client
+	wsHub           WebSocketBroadcaster
+	healthPaths     []string
+	healthRetry     *utils.RetryExecutor
+	limiter         *ValidationLimiter
+	bodyLimits      BodyComparisonLimits
+	store           EnvironmentStore // Persists environments across restarts; nil keeps them in memory
+
+	contracts   map[string]*models.EndpointContract // Keyed by contract name
+	contractsMu sync.RWMutex
+
+	// Background and on-demand re-checks of connected environments, see sync_health_monitor.go
+	healthInterval time.Duration
+	healthCooldown time.Duration
+	monitorMu      sync.Mutex
+	monitorCancel  context.CancelFunc
+	monitorDone    chan struct{}
+}
+
+// SyncServiceConfig holds optional settings for the sync service
+type SyncServiceConfig struct {
+	// HealthPaths are tried in order against each environment URL; the first healthy one wins
+	HealthPaths []string
+
+	// HealthCheckRetry retries health checks that failed to connect or got a 5xx status before
+	// an environment is marked unhealthy; nil uses DefaultSyncHealthRetryConfig
+	HealthCheckRetry *utils.RetryConfig
+
+	// ValidationLimiter caps concurrent endpoint validations per environment; nil is unlimited
+	ValidationLimiter *ValidationLimiter
+
+	// BodyComparison bounds the JSON body comparison; zero fields use DefaultBodyComparisonLimits
+	BodyComparison BodyComparisonLimits
+
+	// HealthCheckInterval is how often StartHealthMonitor re-checks connected environments; 0 disables it
+	HealthCheckInterval time.Duration
+
+	// HealthCheckCooldown is how long after an environment's last check CheckEnvironment refuses
+	// to check it again; 0 allows on-demand checks at any time
+	HealthCheckCooldown time.Duration
+
+	// MaxEnvironments caps how many environments may be connected at once; 0 is unlimited.
+	// Reconnecting an existing environment never counts against it.
+	MaxEnvironments int
+
+	// Store persists connected environments; NewSyncService reloads them and re-checks their
+	// health in the background. Nil keeps environments in memory only.
+	Store EnvironmentStore
+}
+
+// DefaultSyncServiceConfig returns the default sync service configuration
+func DefaultSyncServiceConfig() SyncServiceConfig {
+	return SyncServiceConfig{
+		HealthPaths:    []string{"/health", "/healthz", "/api/health", "/"},
+		BodyComparison: DefaultBodyComparisonLimits(),
+	}
 }
 
 // NewSyncService creates a new sync service instance
-func NewSyncService(wsHub WebSocketBroadcaster) *SyncService {
-	return &SyncService{
-		environments: make(map[string]*models.SyncEnvironment),
-		logger:       utils.GetLogger(),
+func NewSyncService(wsHub WebSocketBroadcaster, config ...SyncServiceConfig) *SyncService {
+	cfg := DefaultSyncServiceConfig()
+	if len(config) > 0 {
+		if len(config[0].HealthPaths) > 0 {
+			cfg.HealthPaths = config[0].HealthPaths
+		}
+		cfg.HealthCheckRetry = config[0].HealthCheckRetry
+		cfg.ValidationLimiter = config[0].ValidationLimiter
+		cfg.BodyComparison = config[0].BodyComparison.withDefaults()
+		cfg.HealthCheckInterval = config[0].HealthCheckInterval
+		cfg.HealthCheckCooldown = config[0].HealthCheckCooldown
+		cfg.MaxEnvironments = config[0].MaxEnvironments
+		cfg.Store = config[0].Store
+	}
+
+	healthRetry := cfg.HealthCheckRetry
+	if healthRetry == nil {
+		healthRetry = DefaultSyncHealthRetryConfig()
+	}
+
+	s := &SyncService{
+		environments:    make(map[string]*models.SyncEnvironment),
+		maxEnvironments: cfg.MaxEnvironments,
+		logger:          utils.GetLogger(),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		wsHub: wsHub,
+		wsHub:       wsHub,
+		healthPaths: cfg.HealthPaths,
+		healthRetry: utils.NewRetryExecutor(healthRetry, nil),
+		limiter:     cfg.ValidationLimiter,
+		bodyLimits:  cfg.BodyComparison,
+		store:       cfg.Store,
+		contracts:   make(map[string]*models.EndpointContract),
+
+		healthInterval: cfg.HealthCheckInterval,
+		healthCooldown: cfg.HealthCheckCooldown,
+	}
+
+	if s.store != nil && s.loadEnvironments() > 0 {
+		go s.CheckEnvironments()
 	}
+
+	return s
+}
+
+// loadEnvironments restores persisted environments as inactive until their health is re-checked,
+// returning how many were loaded. They are restored even past MaxEnvironments, which only limits
+// new connections.
+func (s *SyncService) loadEnvironments() int {
+	environments, err := s.store.List()
+	if err != nil {
+		s.logger.Error("Failed to load persisted sync environments", err)
+		return 0
+	}
+
+	for i := range environments {
+		env := environments[i]
+		env.Status = "inactive"
+		s.environments[env.Name] = &env
+		s.limiter.RegisterEnvironment(env.Name, env.FrontendURL, env.BackendURL)
+	}
+
+	if len(environments) > 0 {
+		s.logger.Info("Restored persisted sync environments", map[string]interface{}{
+			"count": len(environments),
+		})
+	}
+	return len(environments)
 }
 
 // ConnectEnvironment establishes a connection to a sync environment
 func (s *SyncService) ConnectEnvironment(req *models.SyncConnectionRequest) (*models.SyncStatusResponse, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	s.logger.Info("Attempting to connect to sync environment", map[string]interface{}{
 		"environment":  req.Environment,
 		"frontend_url": req.FrontendURL,
 		"backend_url":  req.BackendURL,
 	})
 
-	// Validate URLs by making health check requests
-	frontendHealthy, frontendErr := s.checkURLHealth(req.FrontendURL)
-	backendHealthy, backendErr := s.checkURLHealth(req.BackendURL)
+	// Reject before the health checks when the environment can't be added anyway
+	s.mutex.RLock()
+	err := s.checkEnvironmentLimit(req.Environment)
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
-	// Create or update environment
-	env := &models.SyncEnvironment{
-		Name:        req.Environment,
-		FrontendURL: req.FrontendURL,
-		BackendURL:  req.BackendURL,
-		LastChecked: time.Now(),
-		Metadata:    make(map[string]string),
+	// Validate URLs by making health check requests, without holding the lock as
+	// CheckEnvironment does
+	health := s.checkEnvironmentHealth(req.FrontendURL, req.BackendURL)
+
+	s.mutex.Lock()
+	// Other environments may have been connected during the checks
+	if err := s.checkEnvironmentLimit(req.Environment); err != nil {
+		s.mutex.Unlock()
+		return nil, err
 	}
 
-	// Determine environment status
-	if frontendHealthy && backendHealthy {
-		env.Status = "active"
-		env.Metadata["connection_status"] = "healthy"
-	} else {
-		env.Status = "error"
-		if frontendErr != nil {
-			env.Metadata["frontend_error"] = frontendErr.Error()
-		}
-		if backendErr != nil {
-			env.Metadata["backend_error"] = backendErr.Error()
-		}
+	// Create or update environment
+	env := &models.SyncEnvironment{
+		Name:           req.Environment,
+		FrontendURL:    req.FrontendURL,
+		BackendURL:     req.BackendURL,
+		DefaultHeaders: mergeHeaders(req.DefaultHeaders),
 	}
+	health.apply(env)
 
 	// Store environment
 	s.environments[req.Environment] = env
 
+	// Validations against either URL now count toward this environment's cap
+	s.limiter.RegisterEnvironment(req.Environment, req.FrontendURL, req.BackendURL)
+
+	if s.store != nil {
+		if err := s.store.Save(*env); err != nil {
+			s.logger.Error("Failed to persist sync environment", err, map[string]interface{}{
+				"environment": req.Environment,
+			})
+		}
+	}
+
 	// Create response
 	response := &models.SyncStatusResponse{
 		Status:    env.Status,
@@ -82,12 +222,15 @@ func (s *SyncService) ConnectEnvironment(req *models.SyncConnectionRequest) (*mo
 			req.Environment: env.Status,
 		},
 		Health: models.HealthStatus{
-			Frontend: frontendHealthy,
-			Backend:  backendHealthy,
+			Frontend: health.frontendHealthy,
+			Backend:  health.backendHealthy,
 			Database: true, // Assuming database is always healthy for now
-			Message:  s.getHealthMessage(frontendHealthy, backendHealthy),
+			Message:  s.getHealthMessage(health.frontendHealthy, health.backendHealthy),
 		},
+		EnvironmentCount: len(s.environments),
+		MaxEnvironments:  s.maxEnvironments,
 	}
+	s.mutex.Unlock()
 
 	s.logger.Info("Sync environment connection completed", map[string]interface{}{
 		"environment": req.Environment,
@@ -101,6 +244,22 @@ func (s *SyncService) ConnectEnvironment(req *models.SyncConnectionRequest) (*mo
 	return response, nil
 }
 
+// checkEnvironmentLimit returns ErrEnvironmentLimitReached when connecting the named environment
+// would exceed MaxEnvironments. Replacing an existing environment is always allowed; only new
+// ones count toward the cap. Must be called with mutex held.
+func (s *SyncService) checkEnvironmentLimit(name string) error {
+	if _, exists := s.environments[name]; exists || s.maxEnvironments <= 0 || len(s.environments) < s.maxEnvironments {
+		return nil
+	}
+
+	s.logger.Warn("Rejected sync environment connection over the environment limit", map[string]interface{}{
+		"environment":      name,
+		"max_environments": s.maxEnvironments,
+	})
+	return fmt.Errorf("%w: %d of %d environments connected; remove one before connecting %q",
+		ErrEnvironmentLimitReached, len(s.environments), s.maxEnvironments, name)
+}
+
 // GetSyncStatus returns the current sync status for all environments
 func (s *SyncService) GetSyncStatus() (*models.SyncStatusResponse, error) {
 	s.mutex.RLock()
@@ -145,6 +304,8 @@ func (s *SyncService) GetSyncStatus() (*models.SyncStatusResponse, error) {
 			Database: true,
 			Message:  s.getHealthMessage(frontendHealthy, backendHealthy),
 		},
+		EnvironmentCount: len(s.environments),
+		MaxEnvironments:  s.maxEnvironments,
 	}
 
 	s.logger.Debug("Retrieved sync status", map[string]interface{}{
@@ -164,6 +325,12 @@ func (s *SyncService) ValidateEndpoint(req *models.SyncValidationRequest) (*mode
 		"method":            req.Method,
 	})
 
+	release, err := s.limiter.Acquire(context.Background(), req.FrontendEndpoint, req.BackendEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	response := &models.SyncValidationResponse{
 		IsCompatible: true,
 		Issues:       []models.SyncCompatibilityIssue{},
@@ -172,10 +339,10 @@ func (s *SyncService) ValidateEndpoint(req *models.SyncValidationRequest) (*mode
 	}
 
 	// Validate frontend endpoint
-	frontendResp, frontendErr := s.makeTestRequest(req.FrontendEndpoint, req.Method, req.Headers, req.Payload)
+	frontendResp, frontendErr := s.makeTestRequest(req.FrontendEndpoint, req.Method, s.requestHeaders(req.FrontendEndpoint, req.Headers), req.Payload)
 
 	// Validate backend endpoint
-	backendResp, backendErr := s.makeTestRequest(req.BackendEndpoint, req.Method, req.Headers, req.Payload)
+	backendResp, backendErr := s.makeTestRequest(req.BackendEndpoint, req.Method, s.requestHeaders(req.BackendEndpoint, req.Headers), req.Payload)
 
 	// Compare responses and identify issues
 	if frontendErr != nil {
@@ -207,6 +374,7 @@ func (s *SyncService) ValidateEndpoint(req *models.SyncValidationRequest) (*mode
 	// If both endpoints are accessible, compare responses
 	if frontendErr == nil && backendErr == nil {
 		s.compareResponses(frontendResp, backendResp, response)
+		s.compareHeaders(req, frontendResp.Header, backendResp.Header, response)
 	}
 
 	s.logger.Info("Endpoint validation completed", map[string]interface{}{
@@ -217,21 +385,163 @@ func (s *SyncService) ValidateEndpoint(req *models.SyncValidationRequest) (*mode
 	return response, nil
 }
 
-// checkURLHealth performs a health check on a given URL
-func (s *SyncService) checkURLHealth(url string) (bool, error) {
-	// Try to make a GET request to the URL
-	resp, err := s.httpClient.Get(url)
-	if err != nil {
-		return false, fmt.Errorf("failed to connect to %s: %w", url, err)
+// DefaultSyncHealthRetryConfig returns the retry settings for environment health checks. Failed
+// connections and 5xx statuses are retried, since they are often a passing network blip or
+// restart; a URL answering 4xx on every health path is unhealthy straight away.
+func DefaultSyncHealthRetryConfig() *utils.RetryConfig {
+	return &utils.RetryConfig{
+		MaxAttempts:       3,
+		InitialDelay:      200 * time.Millisecond,
+		MaxDelay:          2 * time.Second,
+		BackoffMultiplier: 2.0,
+		Jitter:            true,
+		RetryCondition:    isTransientHealthError,
+	}
+}
+
+// healthStatusError is the unhealthy status a health path answered with
+type healthStatusError struct {
+	statusCode int
+	target     string
+}
+
+func (e *healthStatusError) Error() string {
+	return fmt.Sprintf("%s %d from %s", ErrHealthUnhealthyStatus, e.statusCode, e.target)
+}
+
+func (e *healthStatusError) Unwrap() error {
+	return ErrHealthUnhealthyStatus
+}
+
+// healthConnectError is a health check request that failed before getting a response
+type healthConnectError struct {
+	target string
+	err    error
+}
+
+func (e *healthConnectError) Error() string {
+	return fmt.Sprintf("failed to connect to %s: %v", e.target, e.err)
+}
+
+func (e *healthConnectError) Unwrap() error {
+	return e.err
+}
+
+// isTransientHealthError reports whether a failed health check may pass when retried: the URL
+// couldn't be reached, or the last health path answered with a 5xx status
+func isTransientHealthError(err error) bool {
+	var connectErr *healthConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var statusErr *healthStatusError
+	return errors.As(err, &statusErr) && statusErr.statusCode >= 500
+}
+
+// checkURLHealth performs a health check on a given URL, trying each configured health path in
+// order and returning the path that answered healthy. Transient failures are retried with
+// backoff per healthRetry, each attempt bounded by the HTTP client timeout. The error wraps
+// ErrHealthConnectionRefused or ErrHealthUnhealthyStatus, telling a server that is down from
+// one that is up but failing.
+func (s *SyncService) checkURLHealth(url string) (bool, string, error) {
+	var healthyPath string
+	err := s.healthRetry.Execute(context.Background(), func(ctx context.Context) error {
+		path, err := s.checkHealthPaths(ctx, url)
+		healthyPath = path
+		return err
+	})
+	if err == nil {
+		return true, healthyPath, nil
+	}
+
+	var retryErr *utils.RetryableError
+	if errors.As(err, &retryErr) {
+		if retryErr.Retryable && retryErr.Attempt > 1 {
+			return false, "", fmt.Errorf("%w (after %d attempts)", retryErr.Err, retryErr.Attempt)
+		}
+		err = retryErr.Err
+	}
+	return false, "", err
+}
+
+// checkHealthPaths makes one pass over the health paths of url, returning the first that
+// answers with a 2xx or 3xx status
+func (s *SyncService) checkHealthPaths(ctx context.Context, url string) (string, error) {
+	baseURL := strings.TrimRight(url, "/")
+
+	var lastErr error
+	for _, path := range s.healthPaths {
+		target := baseURL + path
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return "", fmt.Errorf("invalid health check URL %s: %w", target, err)
+		}
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			// A transport failure means the host itself is unreachable, so other paths won't help
+			if errors.Is(err, syscall.ECONNREFUSED) {
+				err = ErrHealthConnectionRefused
+			}
+			return "", &healthConnectError{target: target, err: err}
+		}
+		resp.Body.Close()
+
+		// Consider 2xx and 3xx status codes as healthy
+		if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+			return path, nil
+		}
+
+		lastErr = &healthStatusError{statusCode: resp.StatusCode, target: target}
 	}
-	defer resp.Body.Close()
 
-	// Consider 2xx and 3xx status codes as healthy
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return true, nil
+	if lastErr == nil {
+		return "", fmt.Errorf("no health paths configured for %s", url)
+	}
+
+	return "", fmt.Errorf("no healthy path for %s (tried %s): %w", url, strings.Join(s.healthPaths, ", "), lastErr)
+}
+
+// requestHeaders returns the headers to send to endpoint: the default headers of the connected
+// environment whose frontend or backend host it belongs to, overridden by the given headers
+func (s *SyncService) requestHeaders(endpoint string, headers map[string]string) map[string]string {
+	host := targetHost(endpoint)
+
+	s.mutex.RLock()
+	var env *models.SyncEnvironment
+	if host != "" {
+		for _, candidate := range s.environments {
+			if targetHost(candidate.FrontendURL) != host && targetHost(candidate.BackendURL) != host {
+				continue
+			}
+			// Several environments may share a host; pick one consistently
+			if env == nil || candidate.Name < env.Name {
+				env = candidate
+			}
+		}
+	}
+	var defaults map[string]string
+	if env != nil {
+		defaults = env.DefaultHeaders
 	}
+	s.mutex.RUnlock()
+
+	return mergeHeaders(defaults, headers)
+}
 
-	return false, fmt.Errorf("unhealthy status code %d from %s", resp.StatusCode, url)
+// mergeHeaders combines header maps, later maps overriding earlier ones. Names are canonicalized
+// so "authorization" overrides "Authorization". Returns nil when there are no headers.
+func mergeHeaders(headerSets ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, headers := range headerSets {
+		for key, value := range headers {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[http.CanonicalHeaderKey(key)] = value
+		}
+	}
+	return merged
 }
 
 // makeTestRequest makes a test request to an endpoint
@@ -311,6 +621,18 @@ func (s *SyncService) compareResponses(frontendResp, backendResp *http.Response,
 		response.Suggestions = append(response.Suggestions, "Consider standardizing Content-Type headers")
 	}
 
+	// Compare the bodies, structurally when both are JSON
+	frontendBody, frontendErr := readResponseBody(frontendResp)
+	backendBody, backendErr := readResponseBody(backendResp)
+	if frontendErr == nil && backendErr == nil {
+		s.compareBodies(frontendBody, backendBody, response)
+	} else {
+		s.logger.Warn("Failed to read response bodies for comparison", map[string]interface{}{
+			"frontend_error": fmt.Sprint(frontendErr),
+			"backend_error":  fmt.Sprint(backendErr),
+		})
+	}
+
 	// Close response bodies
 	frontendResp.Body.Close()
 	backendResp.Body.Close()
@@ -349,10 +671,19 @@ func (s *SyncService) RemoveEnvironment(environmentName string) error {
 	defer s.mutex.Unlock()
 
 	if _, exists := s.environments[environmentName]; !exists {
-		return fmt.Errorf("environment '%s' not found", environmentName)
+		return fmt.Errorf("%w: %s", ErrEnvironmentNotFound, environmentName)
 	}
 
 	delete(s.environments, environmentName)
+	s.limiter.UnregisterEnvironment(environmentName)
+
+	if s.store != nil {
+		if err := s.store.Delete(environmentName); err != nil {
+			s.logger.Error("Failed to remove persisted sync environment", err, map[string]interface{}{
+				"environment": environmentName,
+			})
+		}
+	}
 
 	s.logger.Info("Environment removed", map[string]interface{}{
 		"environment": environmentName,
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -122,6 +125,185 @@ func TestSyncService_ConnectEnvironment(t *testing.T) {
 	}
 }
 
+func TestSyncService_ConnectEnvironment_RecordsHealthPaths(t *testing.T) {
+	frontendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer frontendServer.Close()
+
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer backendServer.Close()
+
+	service := NewSyncService(nil)
+	response, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+		Environment: "mixed",
+		FrontendURL: frontendServer.URL,
+		BackendURL:  backendServer.URL,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.Connected)
+
+	env := service.GetEnvironments()["mixed"]
+	require.NotNil(t, env)
+	assert.Equal(t, "/", env.Metadata["frontend_health_path"])
+	assert.Equal(t, "/healthz", env.Metadata["backend_health_path"])
+}
+
+func TestSyncService_ConnectEnvironment_ChecksWithoutLock(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		<-release
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	service := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}, MaxEnvironments: 1})
+	connect := func(name, url string) error {
+		_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{Environment: name, FrontendURL: url, BackendURL: url})
+		return err
+	}
+
+	slowErr := make(chan error, 1)
+	go func() { slowErr <- connect("slow", slow.URL) }()
+	select {
+	case <-requested:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow environment was never checked")
+	}
+
+	// Status reads and other connections don't wait for the slow check
+	_, err := service.GetSyncStatus()
+	require.NoError(t, err)
+	require.NoError(t, connect("fast", fast.URL))
+
+	close(release)
+	select {
+	case err := <-slowErr:
+		assert.ErrorIs(t, err, ErrEnvironmentLimitReached, "the limit is checked again after the health checks")
+	case <-time.After(5 * time.Second):
+		t.Fatal("ConnectEnvironment did not return")
+	}
+	assert.NotContains(t, service.GetEnvironments(), "slow")
+}
+
+func TestSyncService_MaxEnvironments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	service := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}, MaxEnvironments: 2})
+	connect := func(name string) (*models.SyncStatusResponse, error) {
+		return service.ConnectEnvironment(&models.SyncConnectionRequest{
+			Environment: name,
+			FrontendURL: server.URL,
+			BackendURL:  server.URL,
+		})
+	}
+
+	_, err := connect("staging")
+	require.NoError(t, err)
+	response, err := connect("qa")
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.EnvironmentCount)
+	assert.Equal(t, 2, response.MaxEnvironments)
+
+	t.Run("new environments over the limit are rejected", func(t *testing.T) {
+		_, err := connect("preview-123")
+		assert.ErrorIs(t, err, ErrEnvironmentLimitReached)
+		assert.ErrorContains(t, err, `2 of 2 environments connected; remove one before connecting "preview-123"`)
+		assert.NotContains(t, service.GetEnvironments(), "preview-123")
+	})
+
+	t.Run("existing environments can be replaced", func(t *testing.T) {
+		_, err := connect("staging")
+		assert.NoError(t, err)
+	})
+
+	t.Run("status reports current and max", func(t *testing.T) {
+		status, err := service.GetSyncStatus()
+		require.NoError(t, err)
+		assert.Equal(t, 2, status.EnvironmentCount)
+		assert.Equal(t, 2, status.MaxEnvironments)
+	})
+
+	t.Run("removing an environment frees a slot", func(t *testing.T) {
+		require.NoError(t, service.RemoveEnvironment("qa"))
+		_, err := connect("preview-123")
+		assert.NoError(t, err)
+	})
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		unlimited := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}})
+		for _, name := range []string{"a", "b", "c"} {
+			_, err := unlimited.ConnectEnvironment(&models.SyncConnectionRequest{Environment: name, FrontendURL: server.URL, BackendURL: server.URL})
+			require.NoError(t, err)
+		}
+		status, err := unlimited.GetSyncStatus()
+		require.NoError(t, err)
+		assert.Equal(t, 3, status.EnvironmentCount)
+		assert.Equal(t, 0, status.MaxEnvironments)
+	})
+}
+
+func TestSyncService_EnvironmentStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	store, err := NewFileEnvironmentStore(filepath.Join(t.TempDir(), "environments.json"))
+	require.NoError(t, err)
+	config := SyncServiceConfig{HealthPaths: []string{"/health"}, Store: store}
+
+	service := NewSyncService(nil, config)
+	for _, name := range []string{"staging", "qa"} {
+		_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+			Environment:    name,
+			FrontendURL:    server.URL,
+			BackendURL:     server.URL,
+			DefaultHeaders: map[string]string{"authorization": "Bearer " + name},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, service.RemoveEnvironment("qa"))
+
+	persisted, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, persisted, 1)
+	assert.Equal(t, "staging", persisted[0].Name)
+
+	t.Run("environments are restored and re-checked on startup", func(t *testing.T) {
+		restarted := NewSyncService(nil, config)
+
+		environments := restarted.GetEnvironments()
+		require.Len(t, environments, 1)
+		assert.Equal(t, server.URL, environments["staging"].BackendURL)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer staging"}, environments["staging"].DefaultHeaders)
+
+		assert.Eventually(t, func() bool {
+			return restarted.GetEnvironments()["staging"].Status == "active"
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("no store keeps environments in memory", func(t *testing.T) {
+		assert.Empty(t, NewSyncService(nil).GetEnvironments())
+	})
+}
+
 func TestSyncService_GetSyncStatus(t *testing.T) {
 	service := NewSyncService(nil)
 
@@ -279,6 +461,40 @@ func TestSyncService_ValidateEndpoint(t *testing.T) {
 	}
 }
 
+func TestSyncService_ValidateEndpoint_ConcurrencyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1})
+	service := NewSyncService(nil, SyncServiceConfig{ValidationLimiter: limiter})
+	assert.Equal(t, DefaultSyncServiceConfig().HealthPaths, service.healthPaths)
+
+	req := &models.SyncValidationRequest{
+		FrontendEndpoint: server.URL + "/api/users",
+		BackendEndpoint:  server.URL + "/api/users",
+		Method:           "GET",
+	}
+
+	// Another validation (e.g. from the testing API) holds the only slot for this host
+	release, err := limiter.Acquire(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	_, err = service.ValidateEndpoint(req)
+	assert.ErrorIs(t, err, ErrValidationLimitReached)
+
+	release()
+	response, err := service.ValidateEndpoint(req)
+	require.NoError(t, err)
+	assert.True(t, response.IsCompatible)
+
+	// The slot was returned after validating
+	release, err = limiter.Acquire(context.Background(), server.URL)
+	require.NoError(t, err)
+	release()
+}
+
 func TestSyncService_ValidateEndpoint_Methods(t *testing.T) {
 	methods := []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
 
@@ -314,6 +530,90 @@ func TestSyncService_ValidateEndpoint_Methods(t *testing.T) {
 	}
 }
 
+func TestSyncService_ValidateEndpoint_Headers(t *testing.T) {
+	// Both servers answer their health path openly but require a bearer token for the API
+	bearerServer := func(token string, received *http.Header) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				return
+			}
+			*received = r.Header.Clone()
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+		}))
+	}
+	var frontendHeaders, backendHeaders http.Header
+	frontendServer := bearerServer("frontend-token", &frontendHeaders)
+	defer frontendServer.Close()
+	backendServer := bearerServer("backend-token", &backendHeaders)
+	defer backendServer.Close()
+
+	service := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}})
+	validate := func(headers map[string]string) *models.SyncValidationResponse {
+		response, err := service.ValidateEndpoint(&models.SyncValidationRequest{
+			FrontendEndpoint: frontendServer.URL + "/api/users",
+			BackendEndpoint:  backendServer.URL + "/api/users",
+			Method:           "GET",
+			Headers:          headers,
+		})
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("request headers are sent to both sides", func(t *testing.T) {
+		response := validate(map[string]string{"Authorization": "Bearer backend-token", "X-Api-Key": "key-1"})
+		// Only the backend accepts this token, so the frontend's 401 is reported
+		assert.False(t, response.IsCompatible)
+
+		assert.Equal(t, "Bearer backend-token", frontendHeaders.Get("Authorization"))
+		assert.Equal(t, "Bearer backend-token", backendHeaders.Get("Authorization"))
+		assert.Equal(t, "key-1", frontendHeaders.Get("X-Api-Key"))
+		assert.Equal(t, "key-1", backendHeaders.Get("X-Api-Key"))
+	})
+
+	t.Run("environment default headers are merged in", func(t *testing.T) {
+		_, err := service.ConnectEnvironment(&models.SyncConnectionRequest{
+			Environment:    "staging",
+			FrontendURL:    frontendServer.URL,
+			BackendURL:     frontendServer.URL,
+			DefaultHeaders: map[string]string{"authorization": "Bearer frontend-token", "X-Tenant": "acme"},
+		})
+		require.NoError(t, err)
+		_, err = service.ConnectEnvironment(&models.SyncConnectionRequest{
+			Environment:    "staging-api",
+			FrontendURL:    backendServer.URL,
+			BackendURL:     backendServer.URL,
+			DefaultHeaders: map[string]string{"Authorization": "Bearer backend-token"},
+		})
+		require.NoError(t, err)
+
+		response := validate(map[string]string{"X-Request-Source": "test"})
+		assert.True(t, response.IsCompatible, "issues: %+v", response.Issues)
+		assert.Equal(t, "acme", frontendHeaders.Get("X-Tenant"))
+		assert.Empty(t, backendHeaders.Get("X-Tenant"))
+		assert.Equal(t, "test", backendHeaders.Get("X-Request-Source"))
+	})
+
+	t.Run("request headers override environment defaults", func(t *testing.T) {
+		response := validate(map[string]string{"authorization": "Bearer expired"})
+
+		assert.Equal(t, "Bearer expired", frontendHeaders.Get("Authorization"))
+		assert.Equal(t, "Bearer expired", backendHeaders.Get("Authorization"))
+		assert.Equal(t, "acme", frontendHeaders.Get("X-Tenant"))
+		assert.True(t, response.IsCompatible) // Both sides reject it alike
+	})
+
+	t.Run("default headers are never serialized", func(t *testing.T) {
+		body, err := json.Marshal(service.GetEnvironments())
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "frontend-token")
+	})
+}
+
 func TestSyncService_ValidateEndpoint_UnsupportedMethod(t *testing.T) {
 	service := NewSyncService(nil)
 
@@ -402,9 +702,10 @@ func TestSyncService_checkURLHealth(t *testing.T) {
 		}))
 		defer server.Close()
 
-		healthy, err := service.checkURLHealth(server.URL)
+		healthy, path, err := service.checkURLHealth(server.URL)
 
 		assert.True(t, healthy)
+		assert.Equal(t, "/health", path)
 		assert.NoError(t, err)
 	})
 
@@ -414,15 +715,17 @@ func TestSyncService_checkURLHealth(t *testing.T) {
 		}))
 		defer server.Close()
 
-		healthy, err := service.checkURLHealth(server.URL)
+		healthy, path, err := service.checkURLHealth(server.URL)
 
 		assert.False(t, healthy)
+		assert.Empty(t, path)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "unhealthy status code")
+		assert.Contains(t, err.Error(), "no healthy path")
 	})
 
 	t.Run("unreachable URL", func(t *testing.T) {
-		healthy, err := service.checkURLHealth("http://invalid.test")
+		healthy, _, err := service.checkURLHealth("http://invalid.test")
 
 		assert.False(t, healthy)
 		assert.Error(t, err)
@@ -435,11 +738,147 @@ func TestSyncService_checkURLHealth(t *testing.T) {
 		}))
 		defer server.Close()
 
-		healthy, err := service.checkURLHealth(server.URL)
+		healthy, _, err := service.checkURLHealth(server.URL)
 
 		assert.True(t, healthy)
 		assert.NoError(t, err)
 	})
+
+	t.Run("falls back to later health paths", func(t *testing.T) {
+		var requested []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			if r.URL.Path == "/api/health" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		healthy, path, err := service.checkURLHealth(server.URL + "/")
+
+		assert.True(t, healthy)
+		assert.NoError(t, err)
+		assert.Equal(t, "/api/health", path)
+		assert.Equal(t, []string{"/health", "/healthz", "/api/health"}, requested)
+	})
+
+	t.Run("custom health paths", func(t *testing.T) {
+		custom := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/status", "/ping"}})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/ping" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		healthy, path, err := custom.checkURLHealth(server.URL)
+
+		assert.True(t, healthy)
+		assert.NoError(t, err)
+		assert.Equal(t, "/ping", path)
+	})
+}
+
+func TestSyncService_checkURLHealthRetries(t *testing.T) {
+	retry := DefaultSyncHealthRetryConfig()
+	retry.InitialDelay = time.Millisecond
+	service := NewSyncService(nil, SyncServiceConfig{HealthPaths: []string{"/health"}, HealthCheckRetry: retry})
+
+	t.Run("a server failing once then succeeding is healthy", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		healthy, path, err := service.checkURLHealth(server.URL)
+
+		require.NoError(t, err)
+		assert.True(t, healthy)
+		assert.Equal(t, "/health", path)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("a dropped connection is retried", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				conn.Close()
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		healthy, _, err := service.checkURLHealth(server.URL)
+
+		require.NoError(t, err)
+		assert.True(t, healthy)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("a persistently failing server reports its status", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		healthy, _, err := service.checkURLHealth(server.URL)
+
+		assert.False(t, healthy)
+		assert.ErrorIs(t, err, ErrHealthUnhealthyStatus)
+		assert.NotErrorIs(t, err, ErrHealthConnectionRefused)
+		assert.Contains(t, err.Error(), "unhealthy status code 502")
+		assert.Contains(t, err.Error(), "after 3 attempts")
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("4xx statuses are not retried", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		healthy, _, err := service.checkURLHealth(server.URL)
+
+		assert.False(t, healthy)
+		assert.ErrorIs(t, err, ErrHealthUnhealthyStatus)
+		assert.NotContains(t, err.Error(), "attempts")
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		healthy, _, err := service.checkURLHealth(url)
+
+		assert.False(t, healthy)
+		assert.ErrorIs(t, err, ErrHealthConnectionRefused)
+		assert.NotErrorIs(t, err, ErrHealthUnhealthyStatus)
+		assert.Equal(t, "failed to connect to "+url+"/health: connection refused (after 3 attempts)", err.Error())
+	})
+}
+
+func TestNewSyncService_HealthPathDefaults(t *testing.T) {
+	service := NewSyncService(nil, SyncServiceConfig{})
+
+	assert.Equal(t, DefaultSyncServiceConfig().HealthPaths, service.healthPaths)
 }
 
 func TestSyncService_getHealthMessage(t *testing.T) {
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrTestArtifactNotFound is returned by OpenTestArtifact for a name the run has no artifact for,
+// or whose file is gone
+var ErrTestArtifactNotFound = errors.New("test artifact not found")
+
+// maxTestArtifacts caps the artifacts kept per run, so a misconfigured suite can't fill the disk
+const maxTestArtifacts = 100
+
+// Directories, relative to a run's working directory, each framework writes artifacts to
+var (
+	cypressArtifactDirs    = []string{"cypress/screenshots", "cypress/videos"}
+	playwrightArtifactDirs = []string{"test-results"}
+)
+
+// artifactTypes maps the extension of an artifact file to its kind and content type. Other
+// files in the artifact directories, e.g. logs, are ignored.
+var artifactTypes = map[string]struct{ kind, contentType string }{
+	".png":  {models.ArtifactKindScreenshot, "image/png"},
+	".jpg":  {models.ArtifactKindScreenshot, "image/jpeg"},
+	".jpeg": {models.ArtifactKindScreenshot, "image/jpeg"},
+	".mp4":  {models.ArtifactKindVideo, "video/mp4"},
+	".webm": {models.ArtifactKindVideo, "video/webm"},
+	".zip":  {models.ArtifactKindTrace, "application/zip"},
+}
+
+// artifactsDir returns TEST_ARTIFACTS_DIR; empty means artifacts aren't kept
+func (s *TestService) artifactsDir() string {
+	if s.config == nil {
+		return ""
+	}
+	return s.config.TestArtifactsDir
+}
+
+// collectArtifacts copies the screenshots, videos and traces written to dirs since the run
+// started into the run's artifact directory and records them on its results. dirs are relative
+// to the run's working directory. Symlinks are skipped, as are files beyond maxTestArtifacts.
+func (s *TestService) collectArtifacts(run *TestRun, dirs []string) {
+	root := s.artifactsDir()
+	if root == "" {
+		return
+	}
+
+	workDir := run.Request.Config[testConfigWorkDirKey]
+	if workDir == "" {
+		workDir = "."
+	}
+	// Coarse file system timestamps may round a file's time down to the second
+	since := run.StartTime.Truncate(time.Second)
+	runDir := filepath.Join(root, run.ID)
+
+	var artifacts []models.Artifact
+	names := make(map[string]bool)
+	dropped := 0
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(workDir, dir), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			fileType, known := artifactTypes[strings.ToLower(filepath.Ext(path))]
+			if !known {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil || info.ModTime().Before(since) {
+				return nil
+			}
+			if len(artifacts) >= maxTestArtifacts {
+				dropped++
+				return nil
+			}
+
+			relative, err := filepath.Rel(workDir, path)
+			if err != nil {
+				return nil
+			}
+			name := uniqueArtifactName(relative, names)
+			if err := copyArtifact(path, filepath.Join(runDir, name)); err != nil {
+				log.Printf("Failed to keep artifact %s of test run %s: %v", relative, run.ID, err)
+				return nil
+			}
+
+			names[name] = true
+			artifacts = append(artifacts, models.Artifact{
+				Name:        name,
+				Kind:        fileType.kind,
+				Path:        filepath.ToSlash(relative),
+				ContentType: fileType.contentType,
+				Size:        info.Size(),
+				CreatedAt:   info.ModTime(),
+				URL:         fmt.Sprintf("/api/testing/results/%s/artifacts/%s", run.ID, name),
+			})
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to collect artifacts of test run %s from %s: %v", run.ID, dir, err)
+		}
+	}
+
+	if dropped > 0 {
+		log.Printf("Test run %s produced more than %d artifacts; %d were not kept", run.ID, maxTestArtifacts, dropped)
+	}
+	run.Results.Artifacts = artifacts
+}
+
+// uniqueArtifactName turns an artifact's relative path into a file name safe to use in a URL,
+// e.g. "cypress/screenshots/login.cy.js/fails (failed).png" into
+// "cypress_screenshots_login.cy.js_fails__failed_.png", numbered when taken
+func uniqueArtifactName(relative string, taken map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, filepath.ToSlash(relative))
+	name = strings.TrimLeft(name, ".")
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; taken[name]; i++ {
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+	return name
+}
+
+// copyArtifact copies the file at src to dst, creating dst's directory
+func copyArtifact(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// OpenTestArtifact returns the artifact name of the run runID, active or in history, and opens
+// its file. The caller closes the file.
+func (s *TestService) OpenTestArtifact(runID, name string) (*models.Artifact, *os.File, error) {
+	results, err := s.GetTestResults(runID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, artifact := range results.Artifacts {
+		if artifact.Name != name {
+			continue
+		}
+		root := s.artifactsDir()
+		if root == "" {
+			break
+		}
+		file, err := os.Open(filepath.Join(root, runID, artifact.Name))
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return &artifact, file, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w: %s of run %s", ErrTestArtifactNotFound, name, runID)
+}
+
+// removeArtifacts deletes the artifacts kept for the run runID
+func (s *TestService) removeArtifacts(runID string) {
+	root := s.artifactsDir()
+	if root == "" || runID == "" {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(root, runID)); err != nil {
+		log.Printf("Failed to remove artifacts of test run %s: %v", runID, err)
+	}
+}
+
+// pruneArtifacts deletes the artifacts of runs no longer in history, e.g. those not persisted
+// at all. With a history store, runs it still has keep their artifacts even when they are
+// beyond the in-memory history, since GetTestResults still serves them.
+func (s *TestService) pruneArtifacts() {
+	root := s.artifactsDir()
+	if root == "" {
+		return
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to read test artifact directory: %v", err)
+		}
+		return
+	}
+
+	kept := make(map[string]bool, len(s.runHistory))
+	for _, result := range s.runHistory {
+		kept[result.RunID] = true
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || kept[entry.Name()] {
+			continue
+		}
+		if s.historyStore != nil {
+			if _, err := s.historyStore.Load(entry.Name()); !errors.Is(err, ErrRunNotInHistory) {
+				continue
+			}
+		}
+		s.removeArtifacts(entry.Name())
+	}
+}
+
+// limitArtifacts deletes the artifacts of all but the newest maxArtifactRuns runs, newest by when
+// their artifacts were last written. Unlike pruneArtifacts it applies whether or not a history
+// store still has the older runs. Active runs' artifacts are never counted or deleted.
+func (s *TestService) limitArtifacts() {
+	root := s.artifactsDir()
+	if root == "" || s.maxArtifactRuns <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to read test artifact directory: %v", err)
+		}
+		return
+	}
+
+	s.mu.RLock()
+	active := make(map[string]bool, len(s.activeRuns))
+	for runID := range s.activeRuns {
+		active[runID] = true
+	}
+	s.mu.RUnlock()
+
+	type runArtifacts struct {
+		runID   string
+		modTime time.Time
+	}
+	runs := make([]runArtifacts, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || active[entry.Name()] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		runs = append(runs, runArtifacts{runID: entry.Name(), modTime: info.ModTime()})
+	}
+	if len(runs) <= s.maxArtifactRuns {
+		return
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].modTime.Equal(runs[j].modTime) {
+			return runs[i].modTime.Before(runs[j].modTime)
+		}
+		return runs[i].runID < runs[j].runID
+	})
+	for _, run := range runs[:len(runs)-s.maxArtifactRuns] {
+		s.removeArtifacts(run.runID)
+	}
+}
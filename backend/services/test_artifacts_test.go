@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeArtifact writes a file under workDir, creating its directory
+func writeArtifact(t *testing.T, workDir, relative, content string) string {
+	path := filepath.Join(workDir, relative)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// artifactRun registers an active run working in workDir
+func artifactRun(service *TestService, workDir string) *TestRun {
+	run := &TestRun{
+		ID:        "run-with-artifacts",
+		StartTime: time.Now(),
+		Request:   &models.TestRunRequest{Framework: "cypress", Config: map[string]string{"workDir": workDir}},
+		Results:   &models.TestResults{RunID: "run-with-artifacts", Status: "running"},
+	}
+	service.mu.Lock()
+	service.activeRuns[run.ID] = run
+	service.mu.Unlock()
+	return run
+}
+
+func TestTestService_CollectArtifacts(t *testing.T) {
+	root := t.TempDir()
+	workDir := t.TempDir()
+	service := NewTestService(&config.Config{TestArtifactsDir: root}, nil)
+
+	old := writeArtifact(t, workDir, "cypress/screenshots/old.png", "stale")
+	require.NoError(t, os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	run := artifactRun(service, workDir)
+	writeArtifact(t, workDir, "cypress/screenshots/login.cy.js/fails (failed).png", "png bytes")
+	writeArtifact(t, workDir, "cypress/screenshots/login.cy.js/fails [failed].png", "other png")
+	writeArtifact(t, workDir, "cypress/videos/login.cy.js.mp4", "mp4 bytes")
+	writeArtifact(t, workDir, "cypress/videos/ffmpeg.log", "not an artifact")
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(workDir, "cypress/screenshots/link.png")))
+
+	service.collectArtifacts(run, cypressArtifactDirs)
+
+	artifacts := run.Results.Artifacts
+	require.Len(t, artifacts, 3)
+	assert.Equal(t, models.Artifact{
+		Name:        "cypress_screenshots_login.cy.js_fails__failed_.png",
+		Kind:        models.ArtifactKindScreenshot,
+		Path:        "cypress/screenshots/login.cy.js/fails (failed).png",
+		ContentType: "image/png",
+		Size:        int64(len("png bytes")),
+		CreatedAt:   artifacts[0].CreatedAt,
+		URL:         "/api/testing/results/run-with-artifacts/artifacts/cypress_screenshots_login.cy.js_fails__failed_.png",
+	}, artifacts[0])
+	assert.Equal(t, "cypress_screenshots_login.cy.js_fails__failed_-2.png", artifacts[1].Name, "clashing names are numbered")
+	assert.Equal(t, models.ArtifactKindVideo, artifacts[2].Kind)
+	assert.Equal(t, "video/mp4", artifacts[2].ContentType)
+
+	t.Run("open", func(t *testing.T) {
+		artifact, file, err := service.OpenTestArtifact(run.ID, artifacts[2].Name)
+		require.NoError(t, err)
+		defer file.Close()
+		assert.Equal(t, "video/mp4", artifact.ContentType)
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "mp4 bytes", string(content))
+
+		// The copy outlives the workDir's cleanup
+		require.NoError(t, os.RemoveAll(filepath.Join(workDir, "cypress")))
+		_, file, err = service.OpenTestArtifact(run.ID, artifacts[0].Name)
+		require.NoError(t, err)
+		file.Close()
+
+		_, _, err = service.OpenTestArtifact(run.ID, "../../etc/passwd")
+		assert.ErrorIs(t, err, ErrTestArtifactNotFound)
+		_, _, err = service.OpenTestArtifact("missing-run", artifacts[0].Name)
+		assert.ErrorIs(t, err, ErrTestRunNotFound)
+	})
+
+	t.Run("kept while the history store has the run", func(t *testing.T) {
+		persistedRoot := t.TempDir()
+		persisted := NewTestService(&config.Config{TestArtifactsDir: persistedRoot}, nil, TestServiceConfig{HistoryStore: &fakeHistoryStore{}})
+		persisted.maxHistory = 1
+		for _, id := range []string{"persisted-run", "next-persisted-run"} {
+			writeArtifact(t, persistedRoot, id+"/screenshot.png", "png")
+			run := &TestRun{ID: id, Results: &models.TestResults{RunID: id, Status: "completed", Artifacts: []models.Artifact{{Name: "screenshot.png"}}}}
+			persisted.mu.Lock()
+			persisted.activeRuns[id] = run
+			persisted.mu.Unlock()
+			persisted.moveToHistory(run)
+		}
+
+		_, file, err := persisted.OpenTestArtifact("persisted-run", "screenshot.png")
+		require.NoError(t, err, "the run is trimmed from memory but still served from the store")
+		file.Close()
+	})
+
+	t.Run("removed when trimmed from history", func(t *testing.T) {
+		service.maxHistory = 1
+		service.moveToHistory(run)
+		assert.DirExists(t, filepath.Join(root, run.ID))
+
+		next := &TestRun{ID: "next-run", Results: &models.TestResults{RunID: "next-run", Status: "completed"}}
+		service.mu.Lock()
+		service.activeRuns[next.ID] = next
+		service.mu.Unlock()
+		service.moveToHistory(next)
+
+		assert.NoDirExists(t, filepath.Join(root, run.ID))
+		_, _, err := service.OpenTestArtifact(run.ID, artifacts[0].Name)
+		assert.ErrorIs(t, err, ErrTestRunNotFound)
+	})
+}
+
+func TestTestService_CollectArtifacts_Limits(t *testing.T) {
+	workDir := t.TempDir()
+
+	t.Run("not kept without an artifact directory", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil)
+		run := artifactRun(service, workDir)
+		writeArtifact(t, workDir, "test-results/trace.zip", "zip bytes")
+
+		service.collectArtifacts(run, playwrightArtifactDirs)
+		assert.Empty(t, run.Results.Artifacts)
+	})
+
+	t.Run("capped per run", func(t *testing.T) {
+		service := NewTestService(&config.Config{TestArtifactsDir: t.TempDir()}, nil)
+		run := artifactRun(service, workDir)
+		for i := 0; i < maxTestArtifacts+5; i++ {
+			writeArtifact(t, workDir, filepath.Join("test-results", time.Duration(i).String()+".png"), "png")
+		}
+
+		service.collectArtifacts(run, playwrightArtifactDirs)
+		assert.Len(t, run.Results.Artifacts, maxTestArtifacts)
+	})
+
+	t.Run("missing directories", func(t *testing.T) {
+		service := NewTestService(&config.Config{TestArtifactsDir: t.TempDir()}, nil)
+		run := artifactRun(service, t.TempDir())
+
+		service.collectArtifacts(run, cypressArtifactDirs)
+		assert.Empty(t, run.Results.Artifacts)
+	})
+}
+
+func TestTestService_PruneArtifacts(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(models.TestResults{RunID: "kept-run", Status: "completed"}))
+
+	writeArtifact(t, root, "kept-run/screenshot.png", "png")
+	writeArtifact(t, root, "trimmed-run/screenshot.png", "png")
+
+	NewTestService(&config.Config{TestArtifactsDir: root}, nil, TestServiceConfig{HistoryStore: store})
+
+	assert.FileExists(t, filepath.Join(root, "kept-run", "screenshot.png"))
+	assert.NoDirExists(t, filepath.Join(root, "trimmed-run"))
+
+	t.Run("kept for persisted runs beyond the in-memory history", func(t *testing.T) {
+		root := t.TempDir()
+		store := &fakeHistoryStore{}
+		for i := 0; i <= 100; i++ {
+			require.NoError(t, store.Save(models.TestResults{RunID: fmt.Sprintf("run-%d", i), Status: "completed"}))
+		}
+		writeArtifact(t, root, "run-0/screenshot.png", "png")
+
+		service := NewTestService(&config.Config{TestArtifactsDir: root}, nil, TestServiceConfig{HistoryStore: store})
+		require.Len(t, service.runHistory, 100, "run-0 is beyond the in-memory history")
+		assert.FileExists(t, filepath.Join(root, "run-0", "screenshot.png"))
+	})
+
+	t.Run("skipped when the history can't be read", func(t *testing.T) {
+		root := t.TempDir()
+		writeArtifact(t, root, "some-run/screenshot.png", "png")
+
+		NewTestService(&config.Config{TestArtifactsDir: root}, nil, TestServiceConfig{HistoryStore: unreadableHistoryStore{&fakeHistoryStore{}}})
+		assert.FileExists(t, filepath.Join(root, "some-run", "screenshot.png"))
+	})
+}
+
+func TestTestService_LimitArtifacts(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileHistoryStore(t.TempDir())
+	require.NoError(t, err)
+	service := NewTestService(&config.Config{TestArtifactsDir: root}, nil, TestServiceConfig{HistoryStore: store, MaxArtifactRuns: 2})
+
+	active := artifactRun(service, t.TempDir())
+	writeArtifact(t, root, active.ID+"/screenshot.png", "png")
+	require.NoError(t, os.Chtimes(filepath.Join(root, active.ID), time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	runIDs := []string{"run-1", "run-2", "run-3"}
+	for i, id := range runIDs {
+		writeArtifact(t, root, id+"/screenshot.png", "png")
+		written := time.Now().Add(time.Duration(i-len(runIDs)) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(root, id), written, written))
+
+		run := &TestRun{ID: id, Results: &models.TestResults{RunID: id, Status: "completed", Artifacts: []models.Artifact{{Name: "screenshot.png"}}}}
+		service.mu.Lock()
+		service.activeRuns[id] = run
+		service.mu.Unlock()
+		service.moveToHistory(run)
+	}
+
+	assert.NoDirExists(t, filepath.Join(root, "run-1"), "the oldest run is beyond the limit")
+	assert.DirExists(t, filepath.Join(root, "run-2"))
+	assert.DirExists(t, filepath.Join(root, "run-3"))
+	assert.DirExists(t, filepath.Join(root, active.ID), "active runs are not counted")
+
+	_, err = store.Load("run-1")
+	require.NoError(t, err, "the run itself stays in history")
+	_, _, err = service.OpenTestArtifact("run-1", "screenshot.png")
+	assert.ErrorIs(t, err, ErrTestArtifactNotFound)
+
+	t.Run("applied at startup", func(t *testing.T) {
+		NewTestService(&config.Config{TestArtifactsDir: root}, nil, TestServiceConfig{HistoryStore: store, MaxArtifactRuns: 1})
+		assert.NoDirExists(t, filepath.Join(root, "run-2"))
+		assert.DirExists(t, filepath.Join(root, "run-3"))
+	})
+}
+
+// unreadableHistoryStore is a HistoryStore whose runs can't be listed
+type unreadableHistoryStore struct {
+	*fakeHistoryStore
+}
+
+func (unreadableHistoryStore) List(int) ([]models.TestResults, error) {
+	return nil, errors.New("permission denied")
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+)
+
+// testCallbackTimeout bounds one attempt to deliver a test run callback
+const testCallbackTimeout = 10 * time.Second
+
+// TestCallbackSignatureHeader carries the HMAC-SHA256 of a callback body, as "sha256=<hex>"
+const TestCallbackSignatureHeader = "X-Signature-256"
+
+// ErrInvalidTestCallback is returned when starting a run whose CallbackURL may not be called
+var ErrInvalidTestCallback = errors.New("invalid test run callback_url")
+
+// errCallbackRejected is a callback answered with a status retrying won't change
+var errCallbackRejected = errors.New("callback rejected")
+
+// DefaultTestCallbackRetryConfig returns the retry settings for test run callbacks. Failed
+// connections, 429 and 5xx responses are retried; other 4xx responses give up straight away.
+func DefaultTestCallbackRetryConfig() *utils.RetryConfig {
+	return &utils.RetryConfig{
+		MaxAttempts:       3,
+		InitialDelay:      time.Second,
+		MaxDelay:          30 * time.Second,
+		BackoffMultiplier: 2.0,
+		Jitter:            true,
+		RetryCondition:    func(err error) bool { return !errors.Is(err, errCallbackRejected) },
+	}
+}
+
+// SignTestCallback returns the signature header value of a callback body signed with secret
+func SignTestCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newCallbackClient returns the client delivering callbacks. Redirects aren't followed, so a
+// callback can't be sent on to a host outside TEST_CALLBACK_ALLOWED_HOSTS; a 3xx answer is
+// treated like any other rejection.
+func newCallbackClient() *http.Client {
+	return &http.Client{
+		Timeout: testCallbackTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkCallbackURL returns an ErrInvalidTestCallback error unless callbacks to rawURL may be
+// sent: it must be an http(s) URL whose host matches one of the TEST_CALLBACK_ALLOWED_HOSTS
+// patterns, and TEST_CALLBACK_SECRET must be set so receivers can verify the results. An empty
+// rawURL needs neither.
+func (s *TestService) checkCallbackURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	if s.callbackSecret == "" {
+		return fmt.Errorf("%w: callbacks require TEST_CALLBACK_SECRET", ErrInvalidTestCallback)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidTestCallback, rawURL)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if s.config != nil {
+		for _, pattern := range s.config.TestCallbackAllowedHosts {
+			if matched, _ := path.Match(strings.ToLower(pattern), host); matched {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: host %s is not in TEST_CALLBACK_ALLOWED_HOSTS", ErrInvalidTestCallback, host)
+}
+
+// sendRunCallback POSTs a finished run's results to callbackURL in the background, retrying per
+// callbackRetry. Delivery failures are logged, without the URL's credentials and query; they
+// never affect the run.
+func (s *TestService) sendRunCallback(callbackURL string, results models.TestResults) {
+	body, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("Failed to encode callback for test run %s: %v", results.RunID, err)
+		return
+	}
+
+	s.callbacks.Add(1)
+	go func() {
+		defer s.callbacks.Done()
+
+		err := s.callbackRetry.Execute(context.Background(), func(ctx context.Context) error {
+			return s.postRunCallback(ctx, callbackURL, results.RunID, body)
+		})
+		if err != nil {
+			log.Printf("Giving up on callback for test run %s to %s: %v", results.RunID, redactCallbackURL(callbackURL), err)
+		}
+	}()
+}
+
+// postRunCallback makes one attempt to deliver a callback body
+func (s *TestService) postRunCallback(ctx context.Context, callbackURL, runID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errCallbackRejected, withoutURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Run-ID", runID)
+	if s.callbackSecret != "" {
+		req.Header.Set(TestCallbackSignatureHeader, SignTestCallback(s.callbackSecret, body))
+	}
+
+	resp, err := s.callbackClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback failed: %w", withoutURL(err))
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("callback answered %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: callback answered %d", errCallbackRejected, resp.StatusCode)
+	}
+}
+
+// redactCallbackURL strips a callback URL of its credentials, query and fragment, which may hold
+// tokens, so it can be logged or returned
+func redactCallbackURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return redactedValue
+	}
+	parsed.User, parsed.RawQuery, parsed.Fragment = nil, "", ""
+	return parsed.String()
+}
+
+// withoutURL drops the request URL the HTTP client repeats in its errors
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidTestConfig is returned when starting a run whose Config holds a key or value that
+// can't be passed to the test process safely
+var ErrInvalidTestConfig = errors.New("invalid test run config")
+
+// Limits on a test run Config entry
+const (
+	maxTestConfigKeyLength   = 64
+	maxTestConfigValueLength = 4096
+)
+
+// testConfigWorkDirKey is the Config key holding the run's working directory. The service reads it
+// itself, so it is always allowed; its value is checked by checkTestWorkDir instead.
+const testConfigWorkDirKey = "workDir"
+
+// testConfigKeyPattern matches a Config key usable in an environment variable name
+var testConfigKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// reservedTestConfigKeys are variables changing how the test process or its runtime is loaded.
+// Config keys are prefixed before they reach the environment, but naming one of these is still
+// rejected, since nothing legitimate needs to.
+var reservedTestConfigKeys = map[string]bool{
+	"PATH":                  true,
+	"HOME":                  true,
+	"SHELL":                 true,
+	"ENV":                   true,
+	"BASH_ENV":              true,
+	"IFS":                   true,
+	"NODE_OPTIONS":          true,
+	"NODE_PATH":             true,
+	"LD_PRELOAD":            true,
+	"LD_LIBRARY_PATH":       true,
+	"DYLD_INSERT_LIBRARIES": true,
+	"DYLD_LIBRARY_PATH":     true,
+	"PYTHONPATH":            true,
+	"PYTHONSTARTUP":         true,
+}
+
+// sanitizeTestConfig checks a run's Config before it is used. A key that isn't a plain
+// identifier, names a reserved variable, or has a value with control characters is rejected with
+// ErrInvalidTestConfig, as is a workDir outside TEST_WORKDIR_ROOT. A well-formed key not matching
+// TEST_CONFIG_ALLOWED_KEYS is dropped and returned in ignored. With TEST_WORKDIR_ROOT set, workDir
+// is replaced by its resolved path, so the process runs in the directory that was checked.
+// Config is returned unchanged when nothing is dropped or replaced.
+func (s *TestService) sanitizeTestConfig(config map[string]string) (map[string]string, []string, error) {
+	var ignored []string
+	for key, value := range config {
+		if err := validateTestConfigEntry(key, value); err != nil {
+			return nil, nil, err
+		}
+		if !s.testConfigKeyAllowed(key) {
+			ignored = append(ignored, key)
+		}
+	}
+
+	workDir, err := s.checkTestWorkDir(config[testConfigWorkDirKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ignored) == 0 && workDir == config[testConfigWorkDirKey] {
+		return config, nil, nil
+	}
+
+	sort.Strings(ignored)
+	allowed := make(map[string]string, len(config)-len(ignored))
+	for key, value := range config {
+		if s.testConfigKeyAllowed(key) {
+			allowed[key] = value
+		}
+	}
+	if workDir != "" {
+		allowed[testConfigWorkDirKey] = workDir
+	}
+	return allowed, ignored, nil
+}
+
+// checkTestWorkDir returns workDir resolved against TEST_WORKDIR_ROOT, or an ErrInvalidTestConfig
+// error when it lies outside of it. Without a root it is returned as is; such runs are never
+// cleaned up.
+func (s *TestService) checkTestWorkDir(workDir string) (string, error) {
+	if workDir == "" || s.config == nil || s.config.TestWorkDirRoot == "" {
+		return workDir, nil
+	}
+	resolved, err := resolveWorkDir(s.config.TestWorkDirRoot, workDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTestConfig, err)
+	}
+	return resolved, nil
+}
+
+// testConfigKeyAllowed reports whether key matches one of the TEST_CONFIG_ALLOWED_KEYS patterns.
+// Without patterns every key is allowed.
+func (s *TestService) testConfigKeyAllowed(key string) bool {
+	if key == testConfigWorkDirKey || s.config == nil || len(s.config.TestConfigAllowedKeys) == 0 {
+		return true
+	}
+	for _, pattern := range s.config.TestConfigAllowedKeys {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTestConfigEntry returns an ErrInvalidTestConfig error if key or value can't be passed
+// to the test process. Processes are started without a shell, so values only need to be kept
+// from spanning lines or being cut short.
+func validateTestConfigEntry(key, value string) error {
+	if len(key) > maxTestConfigKeyLength || !testConfigKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key %q must start with a letter and contain only letters, digits and underscores (at most %d)",
+			ErrInvalidTestConfig, key, maxTestConfigKeyLength)
+	}
+	if reservedTestConfigKeys[strings.ToUpper(key)] {
+		return fmt.Errorf("%w: key %q names a reserved environment variable", ErrInvalidTestConfig, key)
+	}
+	if len(value) > maxTestConfigValueLength {
+		return fmt.Errorf("%w: value of %q is longer than %d bytes", ErrInvalidTestConfig, key, maxTestConfigValueLength)
+	}
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: value of %q contains control characters", ErrInvalidTestConfig, key)
+	}
+	return nil
+}
+
+// testConfigEnv returns the Config entries as environment variables named prefix plus the key,
+// upper-cased with upper, sorted by key. Entries failing validation are skipped, so a request
+// that didn't go through startTestRun still can't set arbitrary variables.
+func testConfigEnv(prefix string, config map[string]string, upper bool) []string {
+	keys := make([]string, 0, len(config))
+	for key, value := range config {
+		if validateTestConfigEntry(key, value) == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name := key
+		if upper {
+			name = strings.ToUpper(key)
+		}
+		env = append(env, prefix+name+"="+config[key])
+	}
+	return env
+}
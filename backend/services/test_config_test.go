@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTestConfigEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		valid bool
+	}{
+		{"plain key", "apiUrl", "http://localhost:8080", true},
+		{"shell characters are kept as text", "grep", "$(rm -rf /); `id` | tee", true},
+		{"reserved key", "PATH", "/tmp/evil", false},
+		{"reserved key in lower case", "node_options", "--require /tmp/evil.js", false},
+		{"key setting another variable", "x=1 NODE_OPTIONS", "--inspect", false},
+		{"key with newline", "a\nNODE_OPTIONS", "x", false},
+		{"key starting with a digit", "1key", "x", false},
+		{"empty key", "", "x", false},
+		{"overlong key", strings.Repeat("k", maxTestConfigKeyLength+1), "x", false},
+		{"value with newline", "apiUrl", "ok\nNODE_OPTIONS=--inspect", false},
+		{"value with NUL", "apiUrl", "ok\x00", false},
+		{"overlong value", "apiUrl", strings.Repeat("v", maxTestConfigValueLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTestConfigEntry(tt.key, tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidTestConfig)
+			}
+		})
+	}
+}
+
+func TestTestConfigEnv(t *testing.T) {
+	env := testConfigEnv("PLAYWRIGHT_", map[string]string{
+		"timeout":      "30",
+		"apiUrl":       "http://localhost:8080",
+		"NODE_OPTIONS": "--require /tmp/evil.js",
+		"a=b":          "c",
+	}, true)
+	assert.Equal(t, []string{"PLAYWRIGHT_APIURL=http://localhost:8080", "PLAYWRIGHT_TIMEOUT=30"}, env)
+
+	env = testConfigEnv("CYPRESS_", map[string]string{"apiUrl": "http://localhost:8080"}, false)
+	assert.Equal(t, []string{"CYPRESS_apiUrl=http://localhost:8080"}, env)
+}
+
+func TestTestService_StartTestRun_Config(t *testing.T) {
+	service := NewTestService(&config.Config{TestConfigAllowedKeys: []string{"apiUrl", "feature_*"}}, nil)
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	t.Run("rejects a malicious key", func(t *testing.T) {
+		for _, key := range []string{"NODE_OPTIONS", "path", "x=1 PATH"} {
+			_, err := service.StartTestRun(context.Background(), &models.TestRunRequest{
+				Framework:   "playwright",
+				Environment: "staging",
+				Config:      map[string]string{key: "/tmp/evil"},
+			})
+			assert.ErrorIs(t, err, ErrInvalidTestConfig, key)
+		}
+		assert.Empty(t, service.GetActiveRuns())
+	})
+
+	t.Run("rejects a value with control characters", func(t *testing.T) {
+		_, err := service.StartTestRun(context.Background(), &models.TestRunRequest{
+			Framework:   "cypress",
+			Environment: "staging",
+			Config:      map[string]string{"apiUrl": "http://localhost\nNODE_OPTIONS=--inspect"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTestConfig)
+	})
+
+	t.Run("drops keys outside the allow-list", func(t *testing.T) {
+		req := &models.TestRunRequest{
+			Framework:   "cypress",
+			Environment: "staging",
+			Config: map[string]string{
+				"apiUrl":       "http://localhost:8080",
+				"feature_flag": "on",
+				"workDir":      t.TempDir(),
+				"video":        "false",
+				"retries":      "2",
+			},
+		}
+		response, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"retries", "video"}, response.IgnoredConfigKeys)
+		assert.Len(t, req.Config, 5, "the caller's request is left alone")
+
+		_, err = service.WaitForTestRun(context.Background(), response.RunID, time.Second)
+		require.NoError(t, err)
+		results, err := service.lookupTestResults(response.RunID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"apiUrl":       "http://localhost:8080",
+			"feature_flag": "on",
+			"workDir":      req.Config["workDir"],
+		}, results.Request.Config)
+	})
+
+	t.Run("schedules and workflows are checked up front", func(t *testing.T) {
+		req := scheduleRequest("")
+		req.Run.Config["LD_PRELOAD"] = "/tmp/evil.so"
+		_, err := service.CreateSchedule(req)
+		assert.ErrorIs(t, err, ErrInvalidTestSchedule)
+
+		_, err = service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name: "smoke",
+			Steps: []models.TestWorkflowStep{{
+				Name: "unit",
+				Run:  models.TestRunRequest{Framework: "jest", Environment: "staging", Config: map[string]string{"BASH_ENV": "/tmp/evil"}},
+			}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTestConfig)
+	})
+}
+
+func TestTestService_StartTestRun_ConfigAllowsAnyKeyByDefault(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	response, err := service.StartTestRun(context.Background(), &models.TestRunRequest{
+		Framework:   "playwright",
+		Environment: "staging",
+		Config:      map[string]string{"retries": "2", "video": "off"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, response.IgnoredConfigKeys)
+}
+
+func TestTestService_SanitizeTestConfig_WorkDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "e2e"), 0o755))
+	outside := t.TempDir()
+	service := NewTestService(&config.Config{TestWorkDirRoot: root, TestConfigAllowedKeys: []string{"apiUrl"}}, nil)
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	t.Run("replaced by the resolved path", func(t *testing.T) {
+		config, ignored, err := service.sanitizeTestConfig(map[string]string{"workDir": filepath.Join(root, "e2e", "..", "e2e")})
+		require.NoError(t, err)
+		assert.Empty(t, ignored)
+		resolved, err := filepath.EvalSymlinks(filepath.Join(root, "e2e"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"workDir": resolved}, config)
+	})
+
+	t.Run("rejected outside the root", func(t *testing.T) {
+		_, _, err := service.sanitizeTestConfig(map[string]string{"workDir": outside})
+		assert.ErrorIs(t, err, ErrInvalidTestConfig)
+	})
+
+	t.Run("schedules and workflows are checked up front", func(t *testing.T) {
+		req := scheduleRequest("")
+		req.Run.Config["workDir"] = outside
+		_, err := service.CreateSchedule(req)
+		assert.ErrorIs(t, err, ErrInvalidTestSchedule)
+
+		_, err = service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name: "smoke",
+			Steps: []models.TestWorkflowStep{{
+				Name: "unit",
+				Run:  models.TestRunRequest{Framework: "jest", Environment: "staging", Config: map[string]string{"workDir": outside}},
+			}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTestConfig)
+	})
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// TestRunExportFilter selects the finished runs included in a history export
+type TestRunExportFilter struct {
+	From       time.Time // Leave out runs started before From; zero means no lower bound
+	To         time.Time // Leave out runs started after To; zero means no upper bound
+	WorkflowID string    // Only include the runs started by this workflow's steps; empty means every run
+}
+
+// ExportRuns returns the finished runs in history matching filter, oldest first.
+// An unknown WorkflowID returns ErrWorkflowNotFound.
+func (s *TestService) ExportRuns(filter TestRunExportFilter) ([]models.TestResults, error) {
+	var workflowRuns map[string]bool
+	if filter.WorkflowID != "" {
+		workflow, err := s.GetWorkflow(filter.WorkflowID)
+		if err != nil {
+			return nil, err
+		}
+		workflowRuns = make(map[string]bool, len(workflow.Steps))
+		for _, step := range workflow.Steps {
+			if step.RunID != "" {
+				workflowRuns[step.RunID] = true
+			}
+		}
+	}
+
+	runs := make([]models.TestResults, 0)
+	for _, run := range s.GetRunHistory(0) {
+		if run.Status == "running" || run.Status == "queued" {
+			continue
+		}
+		if !filter.From.IsZero() && run.StartTime.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && run.StartTime.After(filter.To) {
+			continue
+		}
+		if workflowRuns != nil && !workflowRuns[run.RunID] {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestService_ExportRuns(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	service := NewTestService(&config.Config{}, nil)
+	for i, runID := range []string{"run-1", "run-2", "run-3", "run-4"} {
+		start := base.Add(time.Duration(i) * time.Hour)
+		service.runHistory = append(service.runHistory, models.TestResults{
+			RunID:     runID,
+			Status:    "completed",
+			StartTime: start,
+			EndTime:   start.Add(time.Minute),
+		})
+	}
+	service.runHistory = append(service.runHistory, models.TestResults{RunID: "run-busy", Status: "running", StartTime: base})
+	service.workflows["wf-1"] = &testWorkflow{result: models.TestWorkflowResult{
+		WorkflowID: "wf-1",
+		Steps: []models.TestWorkflowStepResult{
+			{Name: "seed", RunID: "run-2"},
+			{Name: "api", RunID: "run-4"},
+			{Name: "ui"},
+		},
+	}}
+
+	runIDs := func(runs []models.TestResults) []string {
+		ids := make([]string, len(runs))
+		for i, run := range runs {
+			ids[i] = run.RunID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		filter   TestRunExportFilter
+		expected []string
+	}{
+		{name: "every run", filter: TestRunExportFilter{}, expected: []string{"run-1", "run-2", "run-3", "run-4"}},
+		{name: "bounds are inclusive", filter: TestRunExportFilter{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)}, expected: []string{"run-2", "run-3"}},
+		{name: "open ended range", filter: TestRunExportFilter{From: base.Add(90 * time.Minute)}, expected: []string{"run-3", "run-4"}},
+		{name: "workflow runs", filter: TestRunExportFilter{WorkflowID: "wf-1"}, expected: []string{"run-2", "run-4"}},
+		{name: "workflow runs in range", filter: TestRunExportFilter{To: base.Add(2 * time.Hour), WorkflowID: "wf-1"}, expected: []string{"run-2"}},
+		{name: "nothing in range", filter: TestRunExportFilter{From: base.Add(24 * time.Hour)}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := service.ExportRuns(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, runIDs(runs))
+		})
+	}
+
+	t.Run("unknown workflow", func(t *testing.T) {
+		_, err := service.ExportRuns(TestRunExportFilter{WorkflowID: "missing"})
+		assert.True(t, errors.Is(err, ErrWorkflowNotFound))
+	})
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrTestRunNotRerunnable is returned by RerunTestRun for a run whose request wasn't recorded,
+// e.g. one persisted before requests were kept with results
+var ErrTestRunNotRerunnable = errors.New("test run request was not recorded")
+
+// ErrNoFailedTests is returned by RerunTestRun with failedOnly for a run without failed test cases
+var ErrNoFailedTests = errors.New("test run has no failed test cases")
+
+// goTestFuncPattern matches the name of a top-level Go test, example or fuzz function
+var goTestFuncPattern = regexp.MustCompile(`^(Test|Example|Fuzz)\w*$`)
+
+// RerunTestRun starts a new run with the request the run runID was started with, whether it is
+// still active or in history. The new run's results point back to runID with RetryOf. With
+// failedOnly, the new run is limited to the test cases that failed.
+func (s *TestService) RerunTestRun(ctx context.Context, runID string, failedOnly bool) (*models.TestRunResponse, error) {
+	original, err := s.lookupTestResults(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := original.Request
+	if request == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTestRunNotRerunnable, runID)
+	}
+
+	req := cloneTestRunRequest(request)
+
+	if failedOnly {
+		var failed []string
+		for _, testCase := range original.Results {
+			if testCase.Status == "failed" {
+				failed = append(failed, testCase.Name)
+			}
+		}
+		if len(failed) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoFailedTests, runID)
+		}
+		req.TestNames = failed
+	}
+
+	return s.startTestRun(ctx, &req, runID)
+}
+
+// cloneTestRunRequest returns a copy of req sharing none of its maps or slices
+func cloneTestRunRequest(req *models.TestRunRequest) models.TestRunRequest {
+	clone := *req
+	clone.Config = make(map[string]string, len(req.Config))
+	for key, value := range req.Config {
+		clone.Config[key] = value
+	}
+	clone.Tags = append([]string(nil), req.Tags...)
+	clone.TestNames = append([]string(nil), req.TestNames...)
+	return clone
+}
+
+// redactedValue replaces the parts of a run's request that may carry credentials
+const redactedValue = "[REDACTED]"
+
+// redactTestResults returns a copy of results whose request has its Config values replaced
+// with redactedValue, and its CallbackURL stripped of credentials and query. Results leave the
+// service redacted; the full request stays with the run and its persisted history, so
+// RerunTestRun still has it.
+func redactTestResults(results models.TestResults) *models.TestResults {
+	if results.Request == nil {
+		return &results
+	}
+
+	req := cloneTestRunRequest(results.Request)
+	for key := range req.Config {
+		req.Config[key] = redactedValue
+	}
+	if req.CallbackURL != "" {
+		req.CallbackURL = redactCallbackURL(req.CallbackURL)
+	}
+	results.Request = &req
+	return &results
+}
+
+// redactRunHistory redacts every run in history, in place, and returns it
+func redactRunHistory(history []models.TestResults) []models.TestResults {
+	for i := range history {
+		history[i] = *redactTestResults(history[i])
+	}
+	return history
+}
+
+// testNameFilterArgs returns the command line arguments limiting a run to req.TestNames. Cypress
+// and pytest are filtered by cypressGrep and pytestTargets instead.
+func testNameFilterArgs(req *models.TestRunRequest) []string {
+	if len(req.TestNames) == 0 {
+		return nil
+	}
+
+	switch req.Framework {
+	case "playwright", "mocha":
+		return []string{"--grep", testNamePattern(req.TestNames)}
+	case "jest":
+		return []string{"--testNamePattern", testNamePattern(req.TestNames)}
+	case "vitest":
+		// Vitest matches the name without the file vitestTestCase puts in front of it
+		names := make([]string, len(req.TestNames))
+		for i, name := range req.TestNames {
+			if _, testName, found := strings.Cut(name, " > "); found {
+				name = testName
+			}
+			names[i] = name
+		}
+		return []string{"--testNamePattern", testNamePattern(names)}
+	case "go":
+		// -run matches per subtest level, so select the top-level tests the named ones belong to
+		var tests []string
+		seen := make(map[string]bool)
+		for _, name := range req.TestNames {
+			if test := goTopLevelTest(name); test != "" && !seen[test] {
+				seen[test] = true
+				tests = append(tests, test)
+			}
+		}
+		if len(tests) == 0 {
+			return nil
+		}
+		return []string{"-run", "^(" + strings.Join(tests, "|") + ")$"}
+	}
+	return nil
+}
+
+// testNamePattern returns a regular expression matching any name containing one of names
+func testNamePattern(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// goTopLevelTest returns the top-level test function of a Go test case named "<package>.<test>",
+// e.g. TestCheckout for "example.com/shop.TestCheckout/empty_cart", or "" for a package entry
+func goTopLevelTest(name string) string {
+	for i := strings.Index(name, "."); i >= 0; {
+		test, _, _ := strings.Cut(name[i+1:], "/")
+		if goTestFuncPattern.MatchString(test) {
+			return test
+		}
+
+		next := strings.Index(name[i+1:], ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return ""
+}
+
+// cypressGrep returns the grep expression for the @cypress/grep plugin selecting req.TestNames,
+// or "" when the run isn't filtered
+func cypressGrep(req *models.TestRunRequest) string {
+	return strings.Join(req.TestNames, ";")
+}
+
+// pytestTargets returns what pytest is asked to run: the node IDs in req.TestNames when set,
+// otherwise the test suite. They follow "--", so none is read as an option.
+func pytestTargets(req *models.TestRunRequest) []string {
+	if len(req.TestNames) > 0 {
+		return append([]string{"--"}, req.TestNames...)
+	}
+	if req.TestSuite != "" {
+		return []string{"--", req.TestSuite}
+	}
+	return nil
+}
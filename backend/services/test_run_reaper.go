@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// terminalRunStatuses are the statuses of runs that have finished and belong in history
+var terminalRunStatuses = map[string]bool{
+	"completed": true,
+	"failed":    true,
+	"cancelled": true,
+}
+
+// StartRunReaper looks for finished runs left in the active runs every ReaperInterval until ctx
+// is done or Stop is called. It does nothing when the interval is 0 or the reaper is running.
+func (s *TestService) StartRunReaper(ctx context.Context) {
+	if s.reaperInterval <= 0 {
+		return
+	}
+
+	s.reaperMu.Lock()
+	defer s.reaperMu.Unlock()
+	if s.reaperCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.reaperCancel = cancel
+	s.reaperDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.reaperInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.ReapFinishedRuns()
+			}
+		}
+	}()
+
+	log.Printf("Test run reaper started, checking every %s", s.reaperInterval)
+}
+
+// Stop stops the run reaper, the run watchdog and the scheduler, waiting for a pass in progress to finish
+func (s *TestService) Stop() {
+	s.reaperMu.Lock()
+	cancels := []context.CancelFunc{s.reaperCancel, s.watchdogCancel, s.schedulerCancel}
+	dones := []chan struct{}{s.reaperDone, s.watchdogDone, s.schedulerDone}
+	s.reaperCancel, s.reaperDone = nil, nil
+	s.watchdogCancel, s.watchdogDone = nil, nil
+	s.schedulerCancel, s.schedulerDone = nil, nil
+	s.reaperMu.Unlock()
+
+	for i, cancel := range cancels {
+		if cancel == nil {
+			continue
+		}
+		cancel()
+		<-dones[i]
+	}
+}
+
+// ReapFinishedRuns moves runs that have finished but are still active to history, releasing
+// any run slot they hold, and returns how many it moved. Runs normally leave the active runs
+// as soon as their results are collected; one that stays means that step was skipped, e.g. by a
+// panic. Runs whose executeTestRun hasn't returned are never reaped, even when cancelled, since
+// it still owns them. Other runs are only reaped once seen finished on two passes in a row, so
+// runs that are finishing as the pass runs are left to complete normally.
+func (s *TestService) ReapFinishedRuns() int {
+	s.mu.Lock()
+	if s.reaperSeen == nil {
+		s.reaperSeen = make(map[string]bool)
+	}
+
+	var reaped []*TestRun
+	seen := make(map[string]bool)
+	for id, run := range s.activeRuns {
+		if run.executing || !terminalRunStatuses[run.Status] {
+			continue
+		}
+		if !s.reaperSeen[id] {
+			seen[id] = true
+			continue
+		}
+
+		if run.EndTime.IsZero() {
+			run.EndTime = time.Now()
+		}
+		if run.Results.EndTime.IsZero() {
+			run.Results.EndTime = run.EndTime
+			run.Results.Duration = run.EndTime.Sub(run.StartTime)
+		}
+		reaped = append(reaped, run)
+	}
+	s.reaperSeen = seen
+	s.reapedRuns += len(reaped)
+	s.mu.Unlock()
+
+	for _, run := range reaped {
+		log.Printf("Reaping test run %s: status is %s but it was still active", run.ID, run.Status)
+
+		s.moveToHistory(run)
+		s.releaseRunSlot(run)
+	}
+
+	return len(reaped)
+}
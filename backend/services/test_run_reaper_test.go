@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestService_ReapFinishedRuns(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxConcurrentRuns: 1})
+	release := make(chan struct{})
+	service.runExecutor = func(run *TestRun) error {
+		<-release
+		return nil
+	}
+
+	// A finished run whose results were never moved to history, still holding the only slot
+	leaked := &TestRun{
+		ID:        "leaked-run",
+		Request:   &models.TestRunRequest{Framework: "jest"},
+		Status:    "completed",
+		StartTime: time.Now().Add(-time.Minute),
+		Results:   &models.TestResults{RunID: "leaked-run", Status: "completed"},
+		done:      make(chan struct{}),
+		holdsSlot: true,
+	}
+	service.mu.Lock()
+	service.activeRuns[leaked.ID] = leaked
+	service.runningRuns = 1
+	service.mu.Unlock()
+
+	running, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", TestSuite: "api", Environment: "development"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, service.GetStatus()["queued_runs"], "the leaked run holds the only slot")
+
+	assert.Zero(t, service.ReapFinishedRuns(), "runs are left alone the first time they are seen finished")
+	assert.Equal(t, 1, service.ReapFinishedRuns())
+
+	_, active := service.GetActiveRuns()["leaked-run"]
+	assert.False(t, active)
+	results, err := service.GetTestResults("leaked-run")
+	require.NoError(t, err)
+	assert.False(t, results.EndTime.IsZero())
+
+	select {
+	case <-leaked.done:
+	default:
+		t.Fatal("waiters on the reaped run were not released")
+	}
+
+	// The freed slot lets the queued run start
+	require.Eventually(t, func() bool {
+		results, err := service.GetTestResults(running.RunID)
+		return err == nil && results.Status == "running"
+	}, time.Second, 10*time.Millisecond)
+
+	status := service.GetStatus()
+	assert.Equal(t, 1, status["reaped_runs"])
+	assert.Equal(t, 1, status["running_runs"])
+
+	// Active runs aren't reaped, and a run that finishes normally isn't moved twice
+	assert.Zero(t, service.ReapFinishedRuns())
+	assert.Zero(t, service.ReapFinishedRuns())
+	release <- struct{}{}
+	_, err = service.WaitForTestRun(context.Background(), running.RunID, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, service.GetRunHistory(10), 2)
+}
+
+func TestTestService_ReapFinishedRuns_Executing(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	release := make(chan struct{})
+	service.runExecutor = func(run *TestRun) error {
+		<-release
+		return nil
+	}
+
+	response, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", TestSuite: "api", Environment: "development"})
+	require.NoError(t, err)
+	require.NoError(t, service.CancelTestRun(response.RunID))
+
+	// The executor still owns the cancelled run until it returns
+	assert.Zero(t, service.ReapFinishedRuns())
+	assert.Zero(t, service.ReapFinishedRuns())
+	_, active := service.GetActiveRuns()[response.RunID]
+	assert.True(t, active)
+
+	close(release)
+	_, err = service.WaitForTestRun(context.Background(), response.RunID, time.Second)
+	require.NoError(t, err)
+	assert.Zero(t, service.GetStatus()["reaped_runs"])
+	assert.Len(t, service.GetRunHistory(10), 1)
+}
+
+func TestTestService_RunReaper(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{ReaperInterval: 10 * time.Millisecond})
+	leaked := &TestRun{
+		ID:      "leaked-run",
+		Request: &models.TestRunRequest{Framework: "jest"},
+		Status:  "cancelled",
+		Results: &models.TestResults{RunID: "leaked-run", Status: "cancelled"},
+	}
+	service.mu.Lock()
+	service.activeRuns[leaked.ID] = leaked
+	service.mu.Unlock()
+
+	service.StartRunReaper(context.Background())
+	defer service.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	t.Run("disabled without an interval", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil)
+		service.StartRunReaper(context.Background())
+		assert.Nil(t, service.reaperCancel)
+		service.Stop()
+	})
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/google/uuid"
+)
+
+// ErrTestScheduleNotFound is returned for schedule IDs that are unknown or have been deleted
+var ErrTestScheduleNotFound = errors.New("test schedule not found")
+
+// ErrInvalidTestSchedule is returned by CreateSchedule for a schedule that could never run
+var ErrInvalidTestSchedule = errors.New("invalid test schedule")
+
+// ErrTestScheduleLimitReached is returned when creating a schedule would exceed MaxSchedules
+var ErrTestScheduleLimitReached = errors.New("test schedule limit reached")
+
+// testSchedulerInterval is how often the scheduler looks for due schedules. Cron expressions
+// have minute resolution, so runs start at most this late.
+const testSchedulerInterval = time.Second
+
+// testSchedule is a schedule with its parsed expression. Guarded by TestService.scheduleMu.
+type testSchedule struct {
+	schedule   models.TestSchedule
+	cron       *utils.CronSchedule
+	location   *time.Location
+	pendingFor time.Time // When the run waiting for the last one to finish was due
+}
+
+// CreateSchedule stores a schedule that starts a run of req.Run whenever req.Cron is due, and
+// persists it when a schedule store is configured
+func (s *TestService) CreateSchedule(req *models.TestScheduleRequest) (*models.TestSchedule, error) {
+	schedule, err := s.newSchedule(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.scheduleMu.Lock()
+	if err := s.checkScheduleLimit(len(s.schedules) + 1); err != nil {
+		s.scheduleMu.Unlock()
+		return nil, err
+	}
+	s.schedules[schedule.schedule.ID] = schedule
+	created := schedule.schedule
+	s.scheduleMu.Unlock()
+
+	s.persistSchedules(created.ID)
+	return &created, nil
+}
+
+// ReplaceSchedules replaces every schedule with ones built from reqs, e.g. when importing a
+// configuration snapshot. Nothing changes when any request is invalid or there are too many.
+// Runs the replaced schedules started keep going.
+func (s *TestService) ReplaceSchedules(reqs []models.TestScheduleRequest) error {
+	if err := s.checkScheduleLimit(len(reqs)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	replaced := make(map[string]*testSchedule, len(reqs))
+	ids := make([]string, 0, len(reqs))
+	for i := range reqs {
+		schedule, err := s.newSchedule(&reqs[i], now)
+		if err != nil {
+			return fmt.Errorf("schedule %q: %w", reqs[i].Name, err)
+		}
+		replaced[schedule.schedule.ID] = schedule
+		ids = append(ids, schedule.schedule.ID)
+	}
+
+	s.scheduleMu.Lock()
+	for id := range s.schedules {
+		ids = append(ids, id)
+	}
+	s.schedules = replaced
+	s.scheduleMu.Unlock()
+
+	s.persistSchedules(ids...)
+	log.Printf("Test schedules replaced: %d schedules", len(replaced))
+	return nil
+}
+
+// ScheduleRequests returns the request each schedule was created from, ordered by creation time
+func (s *TestService) ScheduleRequests() []models.TestScheduleRequest {
+	schedules := s.ListSchedules()
+	reqs := make([]models.TestScheduleRequest, 0, len(schedules))
+	for _, schedule := range schedules {
+		reqs = append(reqs, models.TestScheduleRequest{
+			Name:     schedule.Name,
+			Cron:     schedule.Cron,
+			Timezone: schedule.Timezone,
+			Overlap:  schedule.Overlap,
+			Run:      cloneTestRunRequest(&schedule.Run),
+		})
+	}
+	return reqs
+}
+
+// newSchedule validates req and returns the schedule it describes, due next after now
+func (s *TestService) newSchedule(req *models.TestScheduleRequest, now time.Time) (*testSchedule, error) {
+	if !s.isFrameworkSupported(req.Run.Framework) {
+		return nil, fmt.Errorf("%w: unsupported test framework: %s", ErrInvalidTestSchedule, req.Run.Framework)
+	}
+	if _, _, err := s.sanitizeTestConfig(req.Run.Config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTestSchedule, err)
+	}
+	if err := s.checkCallbackURL(req.Run.CallbackURL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTestSchedule, err)
+	}
+
+	cron, err := utils.ParseCron(req.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTestSchedule, err)
+	}
+	if interval := cron.MinInterval(); interval < s.minScheduleInterval {
+		return nil, fmt.Errorf("%w: %q may run %s apart, more often than every %s",
+			ErrInvalidTestSchedule, req.Cron, interval, s.minScheduleInterval)
+	}
+	location, err := scheduleLocation(req.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTestSchedule, err)
+	}
+
+	next := cron.Next(now.In(location))
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: %q matches no time in the next five years", ErrInvalidTestSchedule, req.Cron)
+	}
+
+	overlap := req.Overlap
+	if overlap == "" {
+		overlap = models.TestScheduleOverlapSkip
+	}
+
+	return &testSchedule{
+		cron:     cron,
+		location: location,
+		schedule: models.TestSchedule{
+			ID:        uuid.New().String(),
+			Name:      req.Name,
+			Cron:      cron.String(),
+			Timezone:  location.String(),
+			Overlap:   overlap,
+			Run:       cloneTestRunRequest(&req.Run),
+			CreatedAt: now,
+			NextRun:   next,
+		},
+	}, nil
+}
+
+// checkScheduleLimit returns ErrTestScheduleLimitReached when count schedules would be too many
+func (s *TestService) checkScheduleLimit(count int) error {
+	if s.maxSchedules > 0 && count > s.maxSchedules {
+		return fmt.Errorf("%w: at most %d test schedules may exist", ErrTestScheduleLimitReached, s.maxSchedules)
+	}
+	return nil
+}
+
+// ListSchedules returns every schedule ordered by creation time
+func (s *TestService) ListSchedules() []models.TestSchedule {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	schedules := make(map[string]models.TestSchedule, len(s.schedules))
+	for id, schedule := range s.schedules {
+		schedules[id] = schedule.schedule
+	}
+	return sortedSchedules(schedules)
+}
+
+// DeleteSchedule removes a schedule so it starts no more runs. A run it already started keeps going.
+func (s *TestService) DeleteSchedule(id string) error {
+	s.scheduleMu.Lock()
+	_, exists := s.schedules[id]
+	delete(s.schedules, id)
+	s.scheduleMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTestScheduleNotFound, id)
+	}
+	s.persistSchedules(id)
+	return nil
+}
+
+// StartScheduler starts the runs of due schedules until ctx is done or Stop is called. It does
+// nothing when the scheduler is running.
+func (s *TestService) StartScheduler(ctx context.Context) {
+	s.reaperMu.Lock()
+	defer s.reaperMu.Unlock()
+	if s.schedulerCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.schedulerCancel = cancel
+	s.schedulerDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(testSchedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.RunDueSchedules(now)
+			}
+		}
+	}()
+
+	s.scheduleMu.Lock()
+	count := len(s.schedules)
+	s.scheduleMu.Unlock()
+	log.Printf("Test scheduler started with %d schedules", count)
+}
+
+// RunDueSchedules starts a run of every schedule due at now and returns how many it started. A
+// schedule whose last run is still going either skips this run or, with the queue overlap
+// policy, starts it once the last run finishes; several due runs queue up as one. Runs missed
+// while the service was down are not caught up.
+func (s *TestService) RunDueSchedules(now time.Time) int {
+	due, changed := s.collectDueSchedules(now)
+
+	// Runs are started, and schedules persisted, without holding scheduleMu
+	started := 0
+	for _, run := range due {
+		if s.triggerSchedule(run) {
+			started++
+		}
+	}
+	s.persistSchedules(changed...)
+	return started
+}
+
+// dueSchedule is a run of a schedule that RunDueSchedules is to start
+type dueSchedule struct {
+	schedule     models.TestSchedule // The schedule's state when the run was found due
+	scheduledFor time.Time
+}
+
+// collectDueSchedules moves every schedule due at now on to its next run, and returns the runs to
+// start and the IDs of the schedules whose state changed
+func (s *TestService) collectDueSchedules(now time.Time) ([]dueSchedule, []string) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	var (
+		due     []dueSchedule
+		changed []string
+	)
+	for _, schedule := range s.schedules {
+		state := &schedule.schedule
+		isDue := !state.NextRun.IsZero() && !now.Before(state.NextRun)
+		if !isDue && !state.Pending {
+			continue
+		}
+
+		scheduledFor := schedule.pendingFor
+		if isDue {
+			scheduledFor = state.NextRun
+			state.NextRun = schedule.cron.Next(now.In(schedule.location))
+
+			if s.isRunActive(state.LastRunID) {
+				if state.Overlap == models.TestScheduleOverlapQueue && !state.Pending {
+					state.Pending = true
+					schedule.pendingFor = scheduledFor
+				} else {
+					state.SkippedRuns++
+					log.Printf("Skipping scheduled test run of %s (%s): run %s is still going", state.Name, state.ID, state.LastRunID)
+				}
+				changed = append(changed, state.ID)
+				continue
+			}
+		} else if s.isRunActive(state.LastRunID) {
+			continue
+		}
+
+		state.Pending = false
+		schedule.pendingFor = time.Time{}
+		due = append(due, dueSchedule{schedule: *state, scheduledFor: scheduledFor})
+		changed = append(changed, state.ID)
+	}
+	return due, changed
+}
+
+// triggerSchedule starts a run of the schedule, reporting whether it did
+func (s *TestService) triggerSchedule(run dueSchedule) bool {
+	state := run.schedule
+	req := cloneTestRunRequest(&state.Run)
+
+	response, err := s.StartTestRun(context.Background(), &req)
+	if err != nil {
+		log.Printf("Failed to start scheduled test run of %s (%s): %v", state.Name, state.ID, err)
+		return false
+	}
+
+	// The schedule may have been deleted meanwhile; the run it started keeps going
+	s.scheduleMu.Lock()
+	if schedule, exists := s.schedules[state.ID]; exists {
+		schedule.schedule.LastRunID = response.RunID
+		schedule.schedule.LastTriggered = response.StartTime
+	}
+	s.scheduleMu.Unlock()
+	log.Printf("Started scheduled test run %s of %s (%s)", response.RunID, state.Name, state.ID)
+
+	if s.wsHub != nil {
+		s.wsHub.BroadcastToAll("test_scheduled_triggered", map[string]interface{}{
+			"schedule_id":   state.ID,
+			"name":          state.Name,
+			"run_id":        response.RunID,
+			"scheduled_for": run.scheduledFor,
+			"next_run":      state.NextRun,
+			"timestamp":     time.Now(),
+		})
+	}
+	return true
+}
+
+// isRunActive reports whether the run is queued or running
+func (s *TestService) isRunActive(runID string) bool {
+	if runID == "" {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, active := s.activeRuns[runID]
+	return active
+}
+
+// persistSchedules saves the current state of the schedules with the given IDs, or deletes those
+// that no longer exist, when a schedule store is configured. Must be called without scheduleMu
+// held. Writes are ordered by schedulePersistMu and read the state under it, so a schedule deleted
+// meanwhile is never saved again.
+func (s *TestService) persistSchedules(ids ...string) {
+	if s.scheduleStore == nil || len(ids) == 0 {
+		return
+	}
+
+	s.schedulePersistMu.Lock()
+	defer s.schedulePersistMu.Unlock()
+
+	for _, id := range ids {
+		s.scheduleMu.Lock()
+		schedule, exists := s.schedules[id]
+		var state models.TestSchedule
+		if exists {
+			state = schedule.schedule
+		}
+		s.scheduleMu.Unlock()
+
+		if !exists {
+			if err := s.scheduleStore.Delete(id); err != nil {
+				log.Printf("Failed to delete persisted test schedule %s: %v", id, err)
+			}
+			continue
+		}
+		if err := s.scheduleStore.Save(state); err != nil {
+			log.Printf("Failed to persist test schedule %s: %v", id, err)
+		}
+	}
+}
+
+// loadSchedules re-arms the persisted schedules from the next time each is due after now
+func (s *TestService) loadSchedules(now time.Time) {
+	stored, err := s.scheduleStore.List()
+	if err != nil {
+		log.Printf("Failed to load test schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range stored {
+		cron, err := utils.ParseCron(schedule.Cron)
+		var location *time.Location
+		if err == nil {
+			location, err = scheduleLocation(schedule.Timezone)
+		}
+		if err != nil {
+			log.Printf("Ignoring persisted test schedule %s (%s): %v", schedule.Name, schedule.ID, err)
+			continue
+		}
+
+		// Runs from before the restart are gone, so a queued run has nothing to wait for
+		schedule.Pending = false
+		schedule.NextRun = cron.Next(now.In(location))
+		s.schedules[schedule.ID] = &testSchedule{schedule: schedule, cron: cron, location: location}
+	}
+}
+
+// scheduleLocation returns the time zone a schedule's expression is read in; "" is UTC
+func scheduleLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", timezone)
+	}
+	return location, nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blockedRuns makes the service's runs wait until the returned channel is closed
+func blockedRuns(service *TestService) chan struct{} {
+	release := make(chan struct{})
+	service.runExecutor = func(run *TestRun) error {
+		<-release
+		return nil
+	}
+	return release
+}
+
+func scheduleRequest(overlap string) *models.TestScheduleRequest {
+	return &models.TestScheduleRequest{
+		Name:    "nightly",
+		Cron:    "0 2 * * *",
+		Overlap: overlap,
+		Run: models.TestRunRequest{
+			Framework:   "jest",
+			TestSuite:   "unit",
+			Environment: "staging",
+			Config:      map[string]string{"workers": "2"},
+		},
+	}
+}
+
+func TestTestService_CreateSchedule(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+
+	req := scheduleRequest("")
+	req.Timezone = "Europe/Madrid"
+	schedule, err := service.CreateSchedule(req)
+	require.NoError(t, err)
+	assert.NotEmpty(t, schedule.ID)
+	assert.Equal(t, models.TestScheduleOverlapSkip, schedule.Overlap, "skip is the default")
+	assert.Equal(t, "Europe/Madrid", schedule.Timezone)
+	assert.True(t, schedule.NextRun.After(time.Now()))
+	assert.Equal(t, 2, schedule.NextRun.Hour())
+	assert.Zero(t, schedule.NextRun.Minute())
+
+	req.Run.Config["workers"] = "4"
+	assert.Equal(t, "2", service.ListSchedules()[0].Run.Config["workers"], "the request is copied")
+
+	schedule, err = service.CreateSchedule(&models.TestScheduleRequest{Name: "hourly", Cron: "@hourly", Run: req.Run})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", schedule.Timezone)
+
+	schedules := service.ListSchedules()
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "nightly", schedules[0].Name)
+	assert.Equal(t, "hourly", schedules[1].Name)
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, mutate := range map[string]func(req *models.TestScheduleRequest){
+			"framework":   func(req *models.TestScheduleRequest) { req.Run.Framework = "unknown" },
+			"cron":        func(req *models.TestScheduleRequest) { req.Cron = "0 2 * *" },
+			"timezone":    func(req *models.TestScheduleRequest) { req.Timezone = "Mars/Olympus" },
+			"never match": func(req *models.TestScheduleRequest) { req.Cron = "0 0 30 2 *" },
+		} {
+			req := scheduleRequest("")
+			mutate(req)
+			_, err := service.CreateSchedule(req)
+			assert.ErrorIs(t, err, ErrInvalidTestSchedule, name)
+		}
+		assert.Len(t, service.ListSchedules(), 2)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, service.DeleteSchedule(schedule.ID))
+		assert.ErrorIs(t, service.DeleteSchedule(schedule.ID), ErrTestScheduleNotFound)
+		assert.Len(t, service.ListSchedules(), 1)
+	})
+}
+
+func TestTestService_RunDueSchedules(t *testing.T) {
+	hub := &MockWebSocketHub{}
+	hub.On("BroadcastToAll", mock.Anything, mock.Anything).Return()
+	service := NewTestService(&config.Config{}, hub)
+	release := blockedRuns(service)
+
+	created, err := service.CreateSchedule(scheduleRequest(models.TestScheduleOverlapSkip))
+	require.NoError(t, err)
+	due := created.NextRun
+
+	assert.Zero(t, service.RunDueSchedules(due.Add(-time.Second)), "not due yet")
+	assert.Equal(t, 1, service.RunDueSchedules(due.Add(time.Second)))
+
+	schedule := service.ListSchedules()[0]
+	require.NotEmpty(t, schedule.LastRunID)
+	assert.False(t, schedule.LastTriggered.IsZero())
+	assert.Equal(t, due.Add(24*time.Hour), schedule.NextRun)
+
+	results, err := service.lookupTestResults(schedule.LastRunID)
+	require.NoError(t, err)
+	assert.Equal(t, "jest", results.Request.Framework)
+	assert.Equal(t, map[string]string{"workers": "2"}, results.Request.Config)
+
+	hub.AssertCalled(t, "BroadcastToAll", "test_scheduled_triggered", mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data["schedule_id"] == created.ID && data["run_id"] == schedule.LastRunID &&
+			data["scheduled_for"] == due && data["name"] == "nightly"
+	}))
+
+	// The next day's run is skipped while the first one is still going
+	assert.Zero(t, service.RunDueSchedules(schedule.NextRun))
+	schedule = service.ListSchedules()[0]
+	assert.Equal(t, 1, schedule.SkippedRuns)
+	assert.False(t, schedule.Pending)
+	assert.Equal(t, due.Add(48*time.Hour), schedule.NextRun)
+
+	close(release)
+	_, err = service.WaitForTestRun(context.Background(), schedule.LastRunID, time.Second)
+	require.NoError(t, err)
+	assert.Zero(t, service.RunDueSchedules(schedule.NextRun.Add(-time.Minute)), "a skipped run is not made up")
+	assert.Equal(t, 1, service.RunDueSchedules(schedule.NextRun))
+}
+
+func TestTestService_RunDueSchedules_Queue(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	release := blockedRuns(service)
+
+	_, err := service.CreateSchedule(scheduleRequest(models.TestScheduleOverlapQueue))
+	require.NoError(t, err)
+	schedule := service.ListSchedules()[0]
+	require.Equal(t, 1, service.RunDueSchedules(schedule.NextRun))
+	first := service.ListSchedules()[0]
+
+	// Due twice while the first run is going: one run is queued, the other skipped
+	assert.Zero(t, service.RunDueSchedules(first.NextRun))
+	schedule = service.ListSchedules()[0]
+	assert.True(t, schedule.Pending)
+	assert.Zero(t, schedule.SkippedRuns)
+
+	assert.Zero(t, service.RunDueSchedules(schedule.NextRun))
+	schedule = service.ListSchedules()[0]
+	assert.True(t, schedule.Pending)
+	assert.Equal(t, 1, schedule.SkippedRuns)
+
+	now := schedule.NextRun.Add(-time.Hour)
+	assert.Zero(t, service.RunDueSchedules(now), "the first run is still going")
+
+	close(release)
+	_, err = service.WaitForTestRun(context.Background(), first.LastRunID, time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, service.RunDueSchedules(now), "the queued run starts once the first one finishes")
+	schedule = service.ListSchedules()[0]
+	assert.False(t, schedule.Pending)
+	assert.NotEqual(t, first.LastRunID, schedule.LastRunID)
+	assert.Zero(t, service.RunDueSchedules(now))
+}
+
+func TestTestService_SchedulesPersisted(t *testing.T) {
+	store, err := NewFileScheduleStore(filepath.Join(t.TempDir(), "schedules.json"))
+	require.NoError(t, err)
+
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{ScheduleStore: store})
+	defer close(blockedRuns(service))
+	created, err := service.CreateSchedule(scheduleRequest(models.TestScheduleOverlapQueue))
+	require.NoError(t, err)
+	removed, err := service.CreateSchedule(scheduleRequest(models.TestScheduleOverlapSkip))
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteSchedule(removed.ID))
+
+	require.Equal(t, 1, service.RunDueSchedules(created.NextRun))
+	require.Zero(t, service.RunDueSchedules(created.NextRun.Add(24*time.Hour)))
+
+	stored, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.True(t, stored[0].Pending)
+	assert.NotEmpty(t, stored[0].LastRunID)
+
+	// After a restart the schedule is re-armed from now, and the run it queued is dropped
+	restarted := NewTestService(&config.Config{}, nil, TestServiceConfig{ScheduleStore: store})
+	schedules := restarted.ListSchedules()
+	require.Len(t, schedules, 1)
+	schedule := schedules[0]
+	assert.Equal(t, created.ID, schedule.ID)
+	assert.Equal(t, stored[0].LastRunID, schedule.LastRunID)
+	assert.False(t, schedule.Pending)
+	assert.True(t, schedule.NextRun.After(time.Now()))
+	assert.Equal(t, 2, schedule.NextRun.Hour())
+	assert.Zero(t, restarted.RunDueSchedules(time.Now()))
+
+	require.NoError(t, restarted.DeleteSchedule(created.ID))
+	stored, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestTestService_StartScheduler(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	created, err := service.CreateSchedule(scheduleRequest(""))
+	require.NoError(t, err)
+
+	// Make the schedule due straight away
+	service.scheduleMu.Lock()
+	service.schedules[created.ID].schedule.NextRun = time.Now().Add(-time.Minute)
+	service.scheduleMu.Unlock()
+
+	service.StartScheduler(context.Background())
+	service.StartScheduler(context.Background())
+	defer service.Stop()
+
+	assert.Eventually(t, func() bool {
+		return service.ListSchedules()[0].LastRunID != ""
+	}, 3*time.Second, 50*time.Millisecond)
+
+	service.Stop()
+	service.reaperMu.Lock()
+	defer service.reaperMu.Unlock()
+	assert.Nil(t, service.schedulerCancel)
+}
+
+func TestTestService_ScheduleLimits(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxSchedules: 2, MinScheduleInterval: 15 * time.Minute})
+
+	for _, cron := range []string{"* * * * *", "*/5 * * * *", "0,10 * * * *"} {
+		req := scheduleRequest("")
+		req.Cron = cron
+		_, err := service.CreateSchedule(req)
+		assert.ErrorIs(t, err, ErrInvalidTestSchedule, cron)
+	}
+
+	for _, cron := range []string{"*/15 * * * *", "@hourly"} {
+		req := scheduleRequest("")
+		req.Cron = cron
+		_, err := service.CreateSchedule(req)
+		require.NoError(t, err, cron)
+	}
+
+	_, err := service.CreateSchedule(scheduleRequest(""))
+	assert.ErrorIs(t, err, ErrTestScheduleLimitReached)
+	assert.Len(t, service.ListSchedules(), 2)
+
+	assert.ErrorIs(t, service.ReplaceSchedules(make([]models.TestScheduleRequest, 3)), ErrTestScheduleLimitReached)
+	assert.Len(t, service.ListSchedules(), 2)
+}
+
+func TestTestService_ReplaceSchedules(t *testing.T) {
+	store, err := NewFileScheduleStore(filepath.Join(t.TempDir(), "schedules.json"))
+	require.NoError(t, err)
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{ScheduleStore: store})
+
+	old, err := service.CreateSchedule(scheduleRequest(""))
+	require.NoError(t, err)
+
+	nightly := *scheduleRequest(models.TestScheduleOverlapQueue)
+	nightly.Timezone = "Europe/Madrid"
+	hourly := models.TestScheduleRequest{Name: "hourly", Cron: "@hourly", Run: nightly.Run}
+
+	invalid := hourly
+	invalid.Cron = "0 0 30 2 *"
+	assert.ErrorIs(t, service.ReplaceSchedules([]models.TestScheduleRequest{nightly, invalid}), ErrInvalidTestSchedule)
+	require.Len(t, service.ListSchedules(), 1, "nothing changes")
+
+	require.NoError(t, service.ReplaceSchedules([]models.TestScheduleRequest{nightly, hourly}))
+	schedules := service.ListSchedules()
+	require.Len(t, schedules, 2)
+	for _, schedule := range schedules {
+		assert.NotEqual(t, old.ID, schedule.ID)
+	}
+
+	hourly.Overlap, hourly.Timezone = models.TestScheduleOverlapSkip, "UTC"
+	assert.ElementsMatch(t, []models.TestScheduleRequest{nightly, hourly}, service.ScheduleRequests())
+
+	stored, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, stored, 2, "the replaced schedule is deleted from the store")
+}
+
+func TestTestService_RunDueSchedules_WithoutScheduleLock(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	hub := &MockWebSocketHub{}
+
+	// A broadcast handler reading the schedules would deadlock if they were locked meanwhile
+	hub.On("BroadcastToAll", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		service.ListSchedules()
+	}).Return()
+	service.wsHub = hub
+	defer close(blockedRuns(service))
+
+	created, err := service.CreateSchedule(scheduleRequest(""))
+	require.NoError(t, err)
+
+	done := make(chan int)
+	go func() { done <- service.RunDueSchedules(created.NextRun) }()
+	select {
+	case started := <-done:
+		assert.Equal(t, 1, started)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunDueSchedules did not return")
+	}
+	hub.AssertCalled(t, "BroadcastToAll", "test_scheduled_triggered", mock.Anything)
+}
@@ -1,19 +1,29 @@
 package services
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 )
 
 // TestService handles test orchestration for end-to-end testing
@@ -24,6 +34,154 @@ type TestService struct {
 	runHistory []models.TestResults
 	maxHistory int
 	wsHub      WebSocketBroadcaster // For real-time updates
+
+	// historyStore persists completed runs; nil keeps history in memory only
+	historyStore HistoryStore
+
+	// maxArtifactRuns caps the runs whose artifacts are kept, see limitArtifacts; 0 is unlimited
+	maxArtifactRuns int
+
+	// Framework availability cache
+	frameworkMu       sync.Mutex
+	frameworkCache    []models.FrameworkInfo
+	frameworkCachedAt time.Time
+	frameworkCacheTTL time.Duration
+	versionDetector   func(ctx context.Context, framework string) (string, error)
+
+	// validationLimiter caps concurrent sync validations per environment; nil is unlimited
+	validationLimiter *ValidationLimiter
+
+	// assertionRunner executes a single sync assertion; replaceable in tests
+	assertionRunner func(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion) (*models.SyncAssertionResult, error)
+
+	// Run queue: at most maxConcurrentRuns execute at once, the rest wait in queuedRuns. Guarded by mu.
+	maxConcurrentRuns int
+	runningRuns       int
+	queuedRuns        []*TestRun
+
+	// runExecutor runs the framework for a dispatched run; replaceable in tests
+	runExecutor func(run *TestRun) error
+
+	// Run reaper: moves runs left in activeRuns after finishing to history. reaperSeen holds
+	// the runs seen finished on the previous pass; guarded by mu.
+	reaperInterval time.Duration
+	reaperSeen     map[string]bool
+	reapedRuns     int
+	reaperMu       sync.Mutex
+	reaperCancel   context.CancelFunc
+	reaperDone     chan struct{}
+
+	// Run watchdog: alerts when no run starts within watchdogInterval, see test_watchdog.go.
+	// lastRunStarted, watchdogAlerting and watchdogAlerts are guarded by mu; watchdogCancel and
+	// watchdogDone by reaperMu.
+	watchdogInterval time.Duration
+	lastRunStarted   time.Time
+	watchdogAlerting bool
+	watchdogAlerts   int
+	watchdogCancel   context.CancelFunc
+	watchdogDone     chan struct{}
+
+	// Set by Shutdown, after which no run is started; guarded by mu
+	shuttingDown bool
+
+	// Bounds on streaming test output, see TestServiceConfig
+	logStreamRate   int
+	logStreamBuffer int
+
+	// Result callbacks of runs started with a CallbackURL, see test_callback.go
+	callbackSecret string
+	callbackRetry  *utils.RetryExecutor
+	callbackClient *http.Client
+	callbacks      sync.WaitGroup // Callbacks being delivered, waited for by Shutdown
+
+	// Workflows being run or kept for GetWorkflow, oldest first in workflowOrder
+	workflowMu    sync.Mutex
+	workflows     map[string]*testWorkflow
+	workflowOrder []string
+
+	// Cron schedules starting runs, see test_schedule.go. schedulerCancel and schedulerDone are
+	// guarded by reaperMu.
+	scheduleMu          sync.Mutex
+	schedules           map[string]*testSchedule
+	scheduleStore       ScheduleStore // Persists schedules; nil keeps them in memory only
+	schedulePersistMu   sync.Mutex    // Orders writes to scheduleStore; taken before scheduleMu
+	maxSchedules        int           // 0 means unlimited
+	minScheduleInterval time.Duration // Shortest gap allowed between a schedule's runs; 0 allows any
+	schedulerCancel     context.CancelFunc
+	schedulerDone       chan struct{}
+}
+
+// defaultAssertionTimeout applies when neither the request nor the configuration sets one
+const defaultAssertionTimeout = 10 * time.Second
+
+// defaultAssertionWorkers applies when the configuration doesn't set how many assertions run at once
+const defaultAssertionWorkers = 4
+
+// maxStreamedLineLength caps a single output line sent over WebSocket; the full line is still parsed
+const maxStreamedLineLength = 4096
+
+// defaultLogStreamBuffer is how many output lines per run wait to be streamed when unconfigured
+const defaultLogStreamBuffer = 100
+
+// processWaitDelay bounds how long Wait keeps reading output after a killed test process exits
+const processWaitDelay = 5 * time.Second
+
+// defaultTimeoutMultiplier scales a framework's estimated duration into its default run timeout
+const defaultTimeoutMultiplier = 3
+
+// defaultMaxConcurrentRuns applies when neither the service config nor the configuration sets a limit
+const defaultMaxConcurrentRuns = 3
+
+// ErrTestRunWaitTimeout is returned by WaitForTestRun when the run hasn't finished in time
+var ErrTestRunWaitTimeout = errors.New("test run did not finish in time")
+
+// ErrTestRunNotFound is returned for a run ID that is neither active nor in history
+var ErrTestRunNotFound = errors.New("test run not found")
+
+// frameworkPackages maps each supported framework to the npm package that provides it
+var frameworkPackages = map[string]string{
+	"cypress":    "cypress",
+	"playwright": "@playwright/test",
+	"jest":       "jest",
+	"vitest":     "vitest",
+	"mocha":      "mocha",
+}
+
+// frameworkVersionCommands reports the version of frameworks that aren't installed through npm
+var frameworkVersionCommands = map[string][]string{
+	"pytest": {"pytest", "--version"},
+	"go":     {"go", "version"},
+}
+
+// versionPattern matches semantic version strings
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+[0-9A-Za-z.+-]*`)
+
+// TestServiceConfig holds optional dependencies for the test service
+type TestServiceConfig struct {
+	HistoryStore      HistoryStore       // Persists completed runs; nil keeps history in memory only
+	MaxConcurrentRuns int                // Runs executed at once; 0 uses MAX_CONCURRENT_TEST_RUNS
+	ValidationLimiter *ValidationLimiter // Caps concurrent sync validations per environment; nil is unlimited
+	ReaperInterval    time.Duration      // How often StartRunReaper looks for finished runs left active; 0 disables it
+
+	// Longest expected gap between test runs before StartRunWatchdog alerts; 0 disables the watchdog
+	ExpectedRunInterval time.Duration
+
+	// Output lines streamed per second and run as test_log_line messages; 0 is unlimited. Lines
+	// beyond LogStreamBuffer waiting to be sent are dropped and counted.
+	LogStreamRate   int
+	LogStreamBuffer int // 0 uses defaultLogStreamBuffer
+
+	// Runs with a CallbackURL POST their final results there, signed with CallbackSecret, see
+	// test_callback.go. Without a secret, runs with a CallbackURL are rejected. CallbackRetry nil
+	// uses DefaultTestCallbackRetryConfig.
+	CallbackSecret string
+	CallbackRetry  *utils.RetryConfig
+
+	ScheduleStore       ScheduleStore // Persists test schedules; nil keeps them in memory only
+	MaxSchedules        int           // Test schedules that may exist at once; 0 is unlimited
+	MinScheduleInterval time.Duration // Shortest gap allowed between a schedule's runs; 0 allows any
+
+	MaxArtifactRuns int // Runs whose artifacts are kept, whether or not HistoryStore has older ones; 0 is unlimited
 }
 
 // TestRun represents an active test run
@@ -37,22 +195,138 @@ type TestRun struct {
 	Context    context.Context
 	Cancel     context.CancelFunc
 	Results    *models.TestResults
-	LogChannel chan string
+	LogChannel chan string // Output lines waiting to be broadcast as test_log_line messages
+	TraceID    string      // Trace ID of the request that started the run
+	Owner      string      // Authenticated user that started the run; only they may stream its output
+
+	droppedLogLines atomic.Int64 // Output lines not streamed because LogChannel was full
+	shutdown        atomic.Bool  // Set when Shutdown cancelled the run
+	holdsSlot       bool         // Set while the run counts toward runningRuns; guarded by TestService.mu
+	executing       bool         // Set while executeTestRun runs for the run; guarded by TestService.mu
+
+	done     chan struct{} // Closed once the run's final results are in history
+	doneOnce sync.Once
+}
+
+// finish signals anyone waiting on the run that its results are final
+func (r *TestRun) finish() {
+	r.doneOnce.Do(func() {
+		if r.done != nil {
+			close(r.done)
+		}
+	})
+}
+
+// sendLogLine queues an output line for streaming without ever blocking the test process.
+// Lines are dropped and counted when the consumer falls behind.
+func (r *TestRun) sendLogLine(line string) {
+	if r.LogChannel == nil {
+		return
+	}
+
+	if len(line) > maxStreamedLineLength {
+		line = strings.ToValidUTF8(line[:maxStreamedLineLength], "") + "…"
+	}
+
+	select {
+	case r.LogChannel <- line:
+	default:
+		r.droppedLogLines.Add(1)
+	}
 }
 
 // NewTestService creates a new test service instance
-func NewTestService(cfg *config.Config, wsHub WebSocketBroadcaster) *TestService {
-	return &TestService{
-		config:     cfg,
-		activeRuns: make(map[string]*TestRun),
-		runHistory: make([]models.TestResults, 0),
-		maxHistory: 100, // Keep last 100 test runs
-		wsHub:      wsHub,
+func NewTestService(cfg *config.Config, wsHub WebSocketBroadcaster, serviceConfig ...TestServiceConfig) *TestService {
+	s := &TestService{
+		config:            cfg,
+		activeRuns:        make(map[string]*TestRun),
+		runHistory:        make([]models.TestResults, 0),
+		maxHistory:        100, // Keep last 100 test runs
+		wsHub:             wsHub,
+		frameworkCacheTTL: 5 * time.Minute,
+		workflows:         make(map[string]*testWorkflow),
+		schedules:         make(map[string]*testSchedule),
+		lastRunStarted:    time.Now(),
+		logStreamBuffer:   defaultLogStreamBuffer,
+	}
+	s.versionDetector = s.detectFrameworkVersion
+	s.assertionRunner = s.executeAssertion
+	s.runExecutor = s.executeFramework
+	s.callbackClient = newCallbackClient()
+	var callbackRetry *utils.RetryConfig
+
+	s.maxConcurrentRuns = defaultMaxConcurrentRuns
+	if cfg != nil && cfg.MaxConcurrentTestRuns > 0 {
+		s.maxConcurrentRuns = cfg.MaxConcurrentTestRuns
+	}
+	if len(serviceConfig) > 0 && serviceConfig[0].MaxConcurrentRuns > 0 {
+		s.maxConcurrentRuns = serviceConfig[0].MaxConcurrentRuns
+	}
+
+	if len(serviceConfig) > 0 {
+		s.validationLimiter = serviceConfig[0].ValidationLimiter
+		s.reaperInterval = serviceConfig[0].ReaperInterval
+		s.watchdogInterval = serviceConfig[0].ExpectedRunInterval
+		s.logStreamRate = serviceConfig[0].LogStreamRate
+		if serviceConfig[0].LogStreamBuffer > 0 {
+			s.logStreamBuffer = serviceConfig[0].LogStreamBuffer
+		}
+		s.callbackSecret = serviceConfig[0].CallbackSecret
+		callbackRetry = serviceConfig[0].CallbackRetry
+		s.maxSchedules = serviceConfig[0].MaxSchedules
+		s.minScheduleInterval = serviceConfig[0].MinScheduleInterval
+		s.maxArtifactRuns = serviceConfig[0].MaxArtifactRuns
+	}
+	if callbackRetry == nil {
+		callbackRetry = DefaultTestCallbackRetryConfig()
+	}
+	s.callbackRetry = utils.NewRetryExecutor(callbackRetry, nil)
+
+	historyLoaded := true
+	if len(serviceConfig) > 0 && serviceConfig[0].HistoryStore != nil {
+		s.historyStore = serviceConfig[0].HistoryStore
+
+		// Restore the most recent runs so history survives restarts
+		history, err := s.historyStore.List(s.maxHistory)
+		if err != nil {
+			log.Printf("Failed to load test run history: %v", err)
+			historyLoaded = false
+		} else {
+			s.runHistory = history
+		}
+
+		// The watchdog measures silence from the last run before the restart
+		if len(s.runHistory) > 0 {
+			s.lastRunStarted = time.Time{}
+			for _, run := range s.runHistory {
+				if run.StartTime.After(s.lastRunStarted) {
+					s.lastRunStarted = run.StartTime
+				}
+			}
+		}
+	}
+
+	if len(serviceConfig) > 0 && serviceConfig[0].ScheduleStore != nil {
+		s.scheduleStore = serviceConfig[0].ScheduleStore
+		s.loadSchedules(time.Now())
+	}
+
+	// Without the history, every run's artifacts would look orphaned
+	if historyLoaded {
+		s.pruneArtifacts()
 	}
+	s.limitArtifacts()
+
+	return s
 }
 
 // StartTestRun initiates a new test run
 func (s *TestService) StartTestRun(ctx context.Context, req *models.TestRunRequest) (*models.TestRunResponse, error) {
+	return s.startTestRun(ctx, req, "")
+}
+
+// startTestRun queues a new run of req; retryOf is the ID of the run it re-runs, if any
+func (s *TestService) startTestRun(ctx context.Context, req *models.TestRunRequest, retryOf string) (*models.TestRunResponse, error) {
 	runID := uuid.New().String()
 
 	// Validate framework support
@@ -60,6 +334,24 @@ func (s *TestService) StartTestRun(ctx context.Context, req *models.TestRunReque
 		return nil, fmt.Errorf("unsupported test framework: %s", req.Framework)
 	}
 
+	if err := s.checkCallbackURL(req.CallbackURL); err != nil {
+		return nil, err
+	}
+
+	// Reject unsafe Config entries and drop keys outside TEST_CONFIG_ALLOWED_KEYS
+	runConfig, ignoredKeys, err := s.sanitizeTestConfig(req.Config)
+	if err != nil {
+		return nil, err
+	}
+	if len(ignoredKeys) > 0 {
+		log.Printf("Ignoring test run %s config keys not in TEST_CONFIG_ALLOWED_KEYS: %s", runID, strings.Join(ignoredKeys, ", "))
+	}
+	if len(ignoredKeys) > 0 || runConfig[testConfigWorkDirKey] != req.Config[testConfigWorkDirKey] {
+		clone := cloneTestRunRequest(req)
+		clone.Config = runConfig
+		req = &clone
+	}
+
 	// Create test run context with cancellation
 	runCtx, cancel := context.WithCancel(ctx)
 
@@ -70,55 +362,174 @@ func (s *TestService) StartTestRun(ctx context.Context, req *models.TestRunReque
 		StartTime:  time.Now(),
 		Context:    runCtx,
 		Cancel:     cancel,
-		LogChannel: make(chan string, 100),
+		LogChannel: make(chan string, s.logStreamBuffer),
+		TraceID:    utils.TraceIDFromContext(ctx),
+		Owner:      utils.UserIDFromContext(ctx),
+		done:       make(chan struct{}),
 		Results: &models.TestResults{
 			RunID:      runID,
 			Status:     "queued",
 			StartTime:  time.Now(),
 			Results:    make([]models.TestCase, 0),
 			SyncIssues: make([]models.SyncIssue, 0),
+			Request:    req,
+			RetryOf:    retryOf,
 		},
 	}
 
-	// Store the active run
+	response := &models.TestRunResponse{
+		RunID:             runID,
+		Status:            "queued",
+		StartTime:         testRun.StartTime,
+		Framework:         req.Framework,
+		Environment:       req.Environment,
+		EstimatedDuration: s.getEstimatedDuration(req.Framework),
+		IgnoredConfigKeys: ignoredKeys,
+	}
+
+	// Store the active run and queue it for execution
 	s.mu.Lock()
+	if s.shuttingDown {
+		s.mu.Unlock()
+		cancel()
+		return nil, ErrTestServiceShuttingDown
+	}
 	s.activeRuns[runID] = testRun
+	s.queuedRuns = append(s.queuedRuns, testRun)
+	previousRun, recovered := s.recordRunStarted(testRun.StartTime)
 	s.mu.Unlock()
 
-	// Start the test execution in a goroutine
-	go s.executeTestRun(testRun)
+	if recovered {
+		s.broadcastWatchdog(WatchdogStatusRecovered, previousRun, testRun.StartTime.Sub(previousRun))
+	}
 
 	// Send WebSocket notification
 	s.broadcastTestUpdate(runID, "queued", "Test run queued for execution")
 
-	return &models.TestRunResponse{
-		RunID:             runID,
-		Status:            "queued",
-		StartTime:         testRun.StartTime,
-		Framework:         req.Framework,
-		Environment:       req.Environment,
-		EstimatedDuration: s.getEstimatedDuration(req.Framework),
-	}, nil
+	// Start the run now if a slot is free
+	s.dispatchQueuedRuns()
+
+	return response, nil
+}
+
+// dispatchQueuedRuns starts queued runs, oldest first, while execution slots are free
+func (s *TestService) dispatchQueuedRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.runningRuns < s.maxConcurrentRuns && len(s.queuedRuns) > 0 {
+		run := s.queuedRuns[0]
+		s.queuedRuns = s.queuedRuns[1:]
+		s.runningRuns++
+		run.holdsSlot = true
+		run.executing = true
+
+		// Execution time excludes time spent waiting in the queue
+		run.Status = "running"
+		run.Results.Status = "running"
+		run.StartTime = time.Now()
+		run.Results.StartTime = run.StartTime
+
+		go s.executeTestRun(run)
+	}
+}
+
+// releaseRunSlot frees the slot held by a finished run and starts the next queued run
+func (s *TestService) releaseRunSlot(run *TestRun) {
+	s.mu.Lock()
+	if run.holdsSlot {
+		run.holdsSlot = false
+		s.runningRuns--
+	}
+	s.mu.Unlock()
+
+	s.dispatchQueuedRuns()
+}
+
+// removeQueuedRun drops a run that hasn't started from the queue. Callers must hold mu.
+func (s *TestService) removeQueuedRun(runID string) bool {
+	for i, run := range s.queuedRuns {
+		if run.ID == runID {
+			s.queuedRuns = append(s.queuedRuns[:i], s.queuedRuns[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
 
-// GetTestResults retrieves results for a specific test run
+// GetTestResults retrieves results for a specific test run, with its request redacted
 func (s *TestService) GetTestResults(runID string) (*models.TestResults, error) {
+	results, err := s.lookupTestResults(runID)
+	if err != nil {
+		return nil, err
+	}
+	return redactTestResults(*results), nil
+}
+
+// lookupTestResults retrieves results for a specific test run, including its full request
+func (s *TestService) lookupTestResults(runID string) (*models.TestResults, error) {
+	if results := s.lookupRunInMemory(runID); results != nil {
+		return results, nil
+	}
+
+	// Fall back to persisted runs that have aged out of memory, outside the lock
+	if s.historyStore != nil {
+		result, err := s.historyStore.Load(runID)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrRunNotInHistory) {
+			log.Printf("Failed to load test run %s from history store: %v", runID, err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrTestRunNotFound, runID)
+}
+
+// lookupRunInMemory returns a copy of an active or remembered run's results, or nil
+func (s *TestService) lookupRunInMemory(runID string) *models.TestResults {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	// Check active runs first
 	if run, exists := s.activeRuns[runID]; exists {
-		return run.Results, nil
+		results := *run.Results
+		return &results
 	}
 
 	// Check history
 	for _, result := range s.runHistory {
 		if result.RunID == runID {
-			return &result, nil
+			return &result
 		}
 	}
 
-	return nil, fmt.Errorf("test run not found: %s", runID)
+	return nil
+}
+
+// WaitForTestRun blocks until the run finishes and returns its results. It gives up with
+// ErrTestRunWaitTimeout after timeout, leaving the run going so its results can be polled.
+func (s *TestService) WaitForTestRun(ctx context.Context, runID string, timeout time.Duration) (*models.TestResults, error) {
+	s.mu.RLock()
+	run, active := s.activeRuns[runID]
+	s.mu.RUnlock()
+
+	// Finished and unknown runs are answered from history
+	if !active {
+		return s.GetTestResults(runID)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-run.done:
+		return s.GetTestResults(runID)
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: run %s still in progress after %s", ErrTestRunWaitTimeout, runID, timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // CancelTestRun cancels an active test run
@@ -133,6 +544,19 @@ func (s *TestService) CancelTestRun(runID string) error {
 	// Cancel the context
 	run.Cancel()
 
+	// A run still in the queue has no process; drop it and record it as cancelled
+	if s.removeQueuedRun(runID) {
+		run.Status = "cancelled"
+		run.Results.Status = "cancelled"
+		run.EndTime = time.Now()
+		run.Results.EndTime = run.EndTime
+		s.mu.Unlock()
+
+		s.broadcastTestUpdate(runID, "cancelled", "Queued test run cancelled by user")
+		s.moveToHistory(run)
+		return nil
+	}
+
 	// Kill the process if it's running
 	if run.Process != nil && run.Process.Process != nil {
 		if err := run.Process.Process.Kill(); err != nil {
@@ -157,6 +581,17 @@ func (s *TestService) ValidateSync(ctx context.Context, req *models.TestSyncVali
 	validationID := uuid.New().String()
 	log.Printf("Starting sync validation %s for endpoint: %s", validationID, req.APIEndpoint)
 
+	timeout, err := s.assertionTimeout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.validationLimiter.Acquire(ctx, req.APIEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	response := &models.TestSyncValidationResponse{
 		IsValid:     true,
 		Results:     make([]models.SyncAssertionResult, 0),
@@ -164,9 +599,10 @@ func (s *TestService) ValidateSync(ctx context.Context, req *models.TestSyncVali
 		ValidatedAt: time.Now(),
 	}
 
-	// Execute each assertion
-	for _, assertion := range req.Assertions {
-		result, err := s.executeAssertion(ctx, req, assertion)
+	// Execute the assertions concurrently, then aggregate their outcomes in request order
+	outcomes := s.runAssertions(ctx, req, timeout)
+	for i, assertion := range req.Assertions {
+		result, err := outcomes[i].result, outcomes[i].err
 		if err != nil {
 			log.Printf("Error executing assertion: %v", err)
 			response.Issues = append(response.Issues, models.SyncIssue{
@@ -198,22 +634,43 @@ func (s *TestService) GetActiveRuns() map[string]*models.TestRunResponse {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	queuePositions := make(map[string]int, len(s.queuedRuns))
+	for i, run := range s.queuedRuns {
+		queuePositions[run.ID] = i + 1
+	}
+
 	active := make(map[string]*models.TestRunResponse)
 	for id, run := range s.activeRuns {
 		active[id] = &models.TestRunResponse{
-			RunID:       id,
-			Status:      run.Status,
-			StartTime:   run.StartTime,
-			Framework:   run.Request.Framework,
-			Environment: run.Request.Environment,
+			RunID:         id,
+			Status:        run.Status,
+			StartTime:     run.StartTime,
+			Framework:     run.Request.Framework,
+			Environment:   run.Request.Environment,
+			QueuePosition: queuePositions[id],
 		}
 	}
 
 	return active
 }
 
-// GetRunHistory returns the test run history
+// GetRunHistory returns the test run history, with each run's request redacted
 func (s *TestService) GetRunHistory(limit int) []models.TestResults {
+	s.mu.RLock()
+	inMemory := len(s.runHistory)
+	s.mu.RUnlock()
+
+	// Read older runs from the store when memory doesn't hold enough, without holding the lock
+	if s.historyStore != nil && (limit <= 0 || limit > inMemory) {
+		history, err := s.historyStore.List(limit)
+		if err == nil && len(history) >= inMemory {
+			return redactRunHistory(history)
+		}
+		if err != nil {
+			log.Printf("Failed to list test run history: %v", err)
+		}
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -229,7 +686,111 @@ func (s *TestService) GetRunHistory(limit int) []models.TestResults {
 
 	history := make([]models.TestResults, limit)
 	copy(history, s.runHistory[start:])
-	return history
+	return redactRunHistory(history)
+}
+
+// GetFrameworkVersions returns the installation status and version of each supported framework.
+// Results are cached for frameworkCacheTTL unless refresh is set.
+func (s *TestService) GetFrameworkVersions(ctx context.Context, refresh bool) []models.FrameworkInfo {
+	s.frameworkMu.Lock()
+	defer s.frameworkMu.Unlock()
+
+	if !refresh && s.frameworkCache != nil && time.Since(s.frameworkCachedAt) < s.frameworkCacheTTL {
+		frameworks := make([]models.FrameworkInfo, len(s.frameworkCache))
+		copy(frameworks, s.frameworkCache)
+		return frameworks
+	}
+
+	frameworks := make([]models.FrameworkInfo, 0, len(models.SupportedFrameworks))
+
+	for _, framework := range models.SupportedFrameworks {
+		info := models.FrameworkInfo{
+			Name:      framework,
+			CheckedAt: time.Now(),
+		}
+
+		version, err := s.versionDetector(ctx, framework)
+		if err != nil {
+			info.Error = err.Error()
+		} else {
+			info.Installed = true
+			info.Version = version
+		}
+
+		frameworks = append(frameworks, info)
+	}
+
+	s.frameworkCache = frameworks
+	s.frameworkCachedAt = time.Now()
+
+	result := make([]models.FrameworkInfo, len(frameworks))
+	copy(result, frameworks)
+	return result
+}
+
+// detectFrameworkVersion resolves the framework package from node_modules, walking up
+// from the current working directory the same way npx resolves local binaries
+func (s *TestService) detectFrameworkVersion(ctx context.Context, framework string) (string, error) {
+	if command, ok := frameworkVersionCommands[framework]; ok {
+		return detectCommandVersion(ctx, framework, command)
+	}
+
+	pkg, ok := frameworkPackages[framework]
+	if !ok {
+		return "", fmt.Errorf("unsupported framework: %s", framework)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "node_modules", pkg, "package.json"))
+		if err == nil {
+			var manifest struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return "", fmt.Errorf("failed to parse %s package.json: %w", pkg, err)
+			}
+
+			version := parseFrameworkVersion(manifest.Version)
+			if version == "" {
+				return "", fmt.Errorf("unable to determine %s version", framework)
+			}
+			return version, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%s is not installed", framework)
+		}
+		dir = parent
+	}
+}
+
+// detectCommandVersion runs a framework's version command and extracts the version from its output
+func detectCommandVersion(ctx context.Context, framework string, command []string) (string, error) {
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", framework, err)
+	}
+
+	version := parseFrameworkVersion(string(output))
+	if version == "" {
+		return "", fmt.Errorf("unable to determine %s version", framework)
+	}
+	return version, nil
+}
+
+// parseFrameworkVersion extracts the first version number from a version string
+func parseFrameworkVersion(output string) string {
+	return versionPattern.FindString(output)
 }
 
 // executeTestRun executes a test run based on the framework
@@ -237,51 +798,372 @@ func (s *TestService) executeTestRun(run *TestRun) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("Test run %s panicked: %v", run.ID, r)
+			s.mu.Lock()
 			run.Status = "failed"
 			run.Results.Status = "failed"
 			run.EndTime = time.Now()
+			s.mu.Unlock()
 		}
 
+		s.mu.Lock()
+		run.executing = false
+		s.mu.Unlock()
+
 		// Move to history and clean up
 		s.moveToHistory(run)
+		s.releaseRunSlot(run)
 	}()
 
-	// Update status to running
-	run.Status = "running"
-	run.Results.Status = "running"
+	// The run was marked running when it left the queue
 	s.broadcastTestUpdate(run.ID, "running", "Test execution started")
 
-	var err error
+	// Kill the test process if it runs past its timeout
+	timeout := s.runTimeout(run.Request)
+	timeoutCtx, cancelTimeout := context.WithTimeout(run.Context, timeout)
+	defer cancelTimeout()
+	run.Context = timeoutCtx
+
+	err := s.executeWithLogStreaming(run)
+
+	var status, reason, event, message string
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		log.Printf("Test run %s timed out after %s", run.ID, timeout)
+		status, reason = "failed", models.TestRunReasonTimeout
+		event, message = "timeout", fmt.Sprintf("Test execution exceeded its %s timeout", timeout)
+	} else if run.shutdown.Load() {
+		log.Printf("Test run %s cancelled by server shutdown", run.ID)
+		status, reason = "cancelled", models.TestRunReasonShutdown
+		event, message = "cancelled", "Test run cancelled by server shutdown"
+	} else if err != nil {
+		log.Printf("Test run %s failed: %v", run.ID, err)
+		status = "failed"
+		event, message = "failed", fmt.Sprintf("Test execution failed: %v", err)
+	} else {
+		status = "completed"
+		event, message = "completed", "Test execution completed successfully"
+	}
+
+	// GetTestResults, WaitForTestRun and CancelTestRun read the final state under the lock
+	s.mu.Lock()
+	run.EndTime = time.Now()
+	run.Results.EndTime = run.EndTime
+	run.Results.Duration = run.EndTime.Sub(run.StartTime)
+	run.Status = status
+	run.Results.Status = status
+	if reason != "" {
+		run.Results.Reason = reason
+	}
+	s.mu.Unlock()
+	s.broadcastTestUpdate(run.ID, event, message)
+
+	// Analyze results for sync issues
+	s.analyzeSyncIssues(run)
+
+	// Remove leftover artifacts once results have been collected
+	s.cleanupWorkDir(run)
+}
+
+// runTimeout returns the request's timeout, or the framework's estimated duration scaled by
+// TEST_RUN_TIMEOUT_MULTIPLIER
+func (s *TestService) runTimeout(req *models.TestRunRequest) time.Duration {
+	if req.TimeoutSeconds > 0 {
+		return time.Duration(req.TimeoutSeconds) * time.Second
+	}
+
+	multiplier := defaultTimeoutMultiplier
+	if s.config != nil && s.config.TestRunTimeoutMultiplier > 0 {
+		multiplier = s.config.TestRunTimeoutMultiplier
+	}
+
+	return s.getEstimatedDuration(req.Framework) * time.Duration(multiplier)
+}
+
+// executeWithLogStreaming runs the executor while a consumer broadcasts the run's output lines.
+// All streamed lines are sent before it returns, so they precede the final status update.
+func (s *TestService) executeWithLogStreaming(run *TestRun) error {
+	if run.LogChannel == nil {
+		return s.runExecutor(run)
+	}
+
+	logsDone := make(chan struct{})
+	go s.streamLogLines(run, logsDone)
+	defer func() {
+		close(run.LogChannel)
+		<-logsDone
+	}()
+
+	return s.runExecutor(run)
+}
+
+// testRunLogTopicPrefix starts the WebSocket topic of every run's test_log_line messages
+const testRunLogTopicPrefix = "test_logs:"
+
+// TestRunLogTopic is the WebSocket topic a run's test_log_line messages are sent to
+func TestRunLogTopic(runID string) string {
+	return testRunLogTopicPrefix + runID
+}
+
+// CanSubscribe reports whether the WebSocket connections of the user userID may subscribe to
+// topic. A run's log topic is open while the run is queued or running, to its owner only when
+// it has one; runs started without an authenticated user, e.g. scheduled runs, have none. Other
+// topics are open to every connection.
+func (s *TestService) CanSubscribe(userID, topic string) bool {
+	runID, isLogTopic := strings.CutPrefix(topic, testRunLogTopicPrefix)
+	if !isLogTopic {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	run, active := s.activeRuns[runID]
+	return active && (run.Owner == "" || run.Owner == userID)
+}
+
+// streamLogLines broadcasts each line from the run's LogChannel as a test_log_line message until
+// the channel is closed, at most LogStreamRate lines per second. Hubs supporting topics only send
+// them to the run's TestRunLogTopic subscribers.
+func (s *TestService) streamLogLines(run *TestRun, done chan<- struct{}) {
+	defer close(done)
+
+	var limiter *rate.Limiter
+	if s.logStreamRate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(s.logStreamRate), s.logStreamRate)
+	}
+	topics, _ := s.wsHub.(TopicBroadcaster)
+	topic := TestRunLogTopic(run.ID)
+
+	sequence := 0
+	for line := range run.LogChannel {
+		sequence++
+		if s.wsHub == nil {
+			continue
+		}
+		if limiter != nil {
+			// Waiting lets LogChannel fill, so the test process drops lines rather than blocking
+			limiter.Wait(context.Background())
+		}
+
+		data := map[string]interface{}{
+			"run_id":        run.ID,
+			"line":          line,
+			"sequence":      sequence,
+			"dropped_lines": run.droppedLogLines.Load(),
+			"timestamp":     time.Now(),
+		}
+		if s.config != nil && s.config.EnableWSCorrelationID && run.TraceID != "" {
+			data["trace_id"] = run.TraceID
+		}
+
+		if topics != nil {
+			topics.BroadcastToTopic(topic, "test_log_line", data)
+		} else {
+			s.wsHub.BroadcastToAll("test_log_line", data)
+		}
+	}
+}
+
+// runStreamingCommand runs cmd, streaming each stdout and stderr line into the run's LogChannel
+// while collecting the combined output for result parsing
+func (s *TestService) runStreamingCommand(run *TestRun, cmd *exec.Cmd) ([]byte, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture stderr: %w", err)
+	}
+
+	// CancelTestRun and Shutdown kill the run's process under the lock
+	s.mu.Lock()
+	err = cmd.Start()
+	if err == nil {
+		run.Process = cmd
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		outputMu sync.Mutex
+		output   bytes.Buffer
+		readers  sync.WaitGroup
+	)
+	readLines := func(pipe io.Reader) {
+		defer readers.Done()
+
+		reader := bufio.NewReader(pipe)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				outputMu.Lock()
+				output.WriteString(line)
+				outputMu.Unlock()
+
+				run.sendLogLine(strings.TrimRight(line, "\r\n"))
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	readers.Add(2)
+	go readLines(stdout)
+	go readLines(stderr)
+
+	// Wait must only be called once both pipes are drained
+	readers.Wait()
+	err = cmd.Wait()
+
+	return output.Bytes(), err
+}
+
+// executeFramework runs the tests with the run's framework
+func (s *TestService) executeFramework(run *TestRun) error {
 	switch strings.ToLower(run.Request.Framework) {
 	case "cypress":
-		err = s.executeCypressTests(run)
+		return s.executeCypressTests(run)
 	case "playwright":
-		err = s.executePlaywrightTests(run)
+		return s.executePlaywrightTests(run)
 	case "jest":
-		err = s.executeJestTests(run)
+		return s.executeJestTests(run)
 	case "vitest":
-		err = s.executeVitestTests(run)
+		return s.executeVitestTests(run)
+	case "mocha":
+		return s.executeMochaTests(run)
+	case "pytest":
+		return s.executePytestTests(run)
+	case "go":
+		return s.executeGoTests(run)
 	default:
-		err = fmt.Errorf("unsupported framework: %s", run.Request.Framework)
+		return fmt.Errorf("unsupported framework: %s", run.Request.Framework)
 	}
+}
 
-	run.EndTime = time.Now()
-	run.Results.EndTime = run.EndTime
-	run.Results.Duration = run.EndTime.Sub(run.StartTime)
+// cleanupWorkDir removes configured artifact patterns from the run's workDir.
+// Runs without an explicit workDir are never cleaned, since that would target
+// the server's own working directory. Nothing is cleaned without TEST_WORKDIR_ROOT,
+// or when the workDir resolves outside of it, since workDir comes from the caller.
+func (s *TestService) cleanupWorkDir(run *TestRun) {
+	if s.config == nil || !s.config.EnableTestCleanup || run.Request.SkipCleanup {
+		return
+	}
+
+	workDir := run.Request.Config["workDir"]
+	if workDir == "" || s.config.TestWorkDirRoot == "" {
+		return
+	}
 
+	resolved, err := resolveWorkDir(s.config.TestWorkDirRoot, workDir)
 	if err != nil {
-		log.Printf("Test run %s failed: %v", run.ID, err)
-		run.Status = "failed"
-		run.Results.Status = "failed"
-		s.broadcastTestUpdate(run.ID, "failed", fmt.Sprintf("Test execution failed: %v", err))
-	} else {
-		run.Status = "completed"
-		run.Results.Status = "completed"
-		s.broadcastTestUpdate(run.ID, "completed", "Test execution completed successfully")
+		log.Printf("Skipping cleanup for test run %s: %v", run.ID, err)
+		return
 	}
 
-	// Analyze results for sync issues
-	s.analyzeSyncIssues(run)
+	targets, err := resolveCleanupTargets(resolved, s.config.TestCleanupPatterns)
+	if err != nil {
+		log.Printf("Skipping cleanup for test run %s: %v", run.ID, err)
+		return
+	}
+
+	removed := 0
+	for _, target := range targets {
+		if err := os.RemoveAll(target); err != nil {
+			log.Printf("Failed to remove %s after test run %s: %v", target, run.ID, err)
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		log.Printf("Cleaned up %d artifact(s) from %s after test run %s", removed, workDir, run.ID)
+	}
+}
+
+// resolveCleanupTargets expands cleanup patterns within workDir, dropping any
+// match that would resolve outside of it
+func resolveCleanupTargets(workDir string, patterns []string) ([]string, error) {
+	root, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workDir: %w", err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workDir: %w", err)
+	}
+	if filepath.Dir(root) == root {
+		return nil, fmt.Errorf("refusing to clean filesystem root")
+	}
+
+	targets := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		if pattern == "" || filepath.IsAbs(pattern) {
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			continue
+		}
+
+		for _, match := range matches {
+			if !isWithinDir(root, match) {
+				continue
+			}
+
+			// The match itself may be a symlink (removing it is safe), but its
+			// parent must not lead outside the workDir
+			parent, err := filepath.EvalSymlinks(filepath.Dir(match))
+			if err != nil || (parent != root && !isWithinDir(root, parent)) {
+				continue
+			}
+
+			if !seen[match] {
+				seen[match] = true
+				targets = append(targets, match)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// resolveWorkDir returns the absolute, symlink-free path of workDir, or an error when it isn't
+// root or a directory inside it
+func resolveWorkDir(root, workDir string) (string, error) {
+	resolvedRoot, err := filepath.Abs(root)
+	if err == nil {
+		resolvedRoot, err = filepath.EvalSymlinks(resolvedRoot)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve TEST_WORKDIR_ROOT: %w", err)
+	}
+
+	resolved, err := filepath.Abs(workDir)
+	if err == nil {
+		resolved, err = filepath.EvalSymlinks(resolved)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workDir: %w", err)
+	}
+
+	if resolved != resolvedRoot && !isWithinDir(resolvedRoot, resolved) {
+		return "", fmt.Errorf("workDir %s is outside TEST_WORKDIR_ROOT", workDir)
+	}
+	return resolved, nil
+}
+
+// isWithinDir reports whether path is strictly inside dir
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 // executeCypressTests executes Cypress tests
@@ -296,17 +1178,32 @@ func (s *TestService) executeCypressTests(run *TestRun) error {
 		args = append(args, "--spec", run.Request.TestSuite)
 	}
 
+	// Write the Mocha JSON report to its own file so it isn't mixed into Cypress's console output
+	reportFile, err := os.CreateTemp("", "cypress-report-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create cypress report file: %w", err)
+	}
+	reportPath := reportFile.Name()
+	reportFile.Close()
+	defer os.Remove(reportPath)
+
+	args = append(args, "--reporter", "json", "--reporter-options", "output="+reportPath)
+
 	// Add environment variables
 	env := os.Environ()
 	env = append(env, fmt.Sprintf("CYPRESS_baseUrl=%s", s.config.CypressBaseURL))
 
 	// Add custom config
-	for key, value := range run.Request.Config {
-		env = append(env, fmt.Sprintf("CYPRESS_%s=%s", key, value))
+	env = append(env, testConfigEnv("CYPRESS_", run.Request.Config, false)...)
+
+	// Filtering by test name needs the @cypress/grep plugin
+	if grep := cypressGrep(run.Request); grep != "" {
+		env = append(env, "CYPRESS_grep="+grep)
 	}
 
 	// Create command
 	cmd := exec.CommandContext(run.Context, "npx", append([]string{"cypress"}, args...)...)
+	configureProcessGroup(cmd)
 	cmd.Env = env
 
 	// Set working directory (assuming tests are in a cypress directory)
@@ -314,16 +1211,27 @@ func (s *TestService) executeCypressTests(run *TestRun) error {
 		cmd.Dir = workDir
 	}
 
-	run.Process = cmd
+	// Execute and stream output. Cypress exits non-zero when specs fail, so read the report either way.
+	output, runErr := s.runStreamingCommand(run, cmd)
+	s.collectArtifacts(run, cypressArtifactDirs)
+	report, _ := os.ReadFile(reportPath)
 
-	// Execute and capture output
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("cypress execution failed: %w, output: %s", err, string(output))
+	if parseErr := s.parseMochaReport(run, report, string(output)); parseErr != nil {
+		log.Printf("Falling back to console parsing for Cypress run %s: %v", run.ID, parseErr)
+		if runErr != nil {
+			return fmt.Errorf("cypress execution failed: %w, output: %s", runErr, string(output))
+		}
+		return s.parseCypressResults(run, string(output))
+	}
+
+	if runErr != nil {
+		if run.Results.FailedTests > 0 {
+			return fmt.Errorf("cypress reported %d failing test(s)", run.Results.FailedTests)
+		}
+		return fmt.Errorf("cypress execution failed: %w, output: %s", runErr, string(output))
 	}
 
-	// Parse Cypress results
-	return s.parseCypressResults(run, string(output))
+	return nil
 }
 
 // executePlaywrightTests executes Playwright tests
@@ -336,20 +1244,20 @@ func (s *TestService) executePlaywrightTests(run *TestRun) error {
 	if run.Request.TestSuite != "" {
 		args = append(args, run.Request.TestSuite)
 	}
+	args = append(args, testNameFilterArgs(run.Request)...)
 
 	// Add reporter for JSON output
 	args = append(args, "--reporter=json")
 
 	// Create command
 	cmd := exec.CommandContext(run.Context, "npx", append([]string{"playwright"}, args...)...)
+	configureProcessGroup(cmd)
 
 	// Set environment variables
 	env := os.Environ()
 	env = append(env, fmt.Sprintf("PLAYWRIGHT_BASE_URL=%s", s.config.PlaywrightBaseURL))
 
-	for key, value := range run.Request.Config {
-		env = append(env, fmt.Sprintf("PLAYWRIGHT_%s=%s", strings.ToUpper(key), value))
-	}
+	env = append(env, testConfigEnv("PLAYWRIGHT_", run.Request.Config, true)...)
 	cmd.Env = env
 
 	// Set working directory
@@ -357,10 +1265,9 @@ func (s *TestService) executePlaywrightTests(run *TestRun) error {
 		cmd.Dir = workDir
 	}
 
-	run.Process = cmd
-
-	// Execute and capture output
-	output, err := cmd.CombinedOutput()
+	// Execute and stream output
+	output, err := s.runStreamingCommand(run, cmd)
+	s.collectArtifacts(run, playwrightArtifactDirs)
 	if err != nil {
 		return fmt.Errorf("playwright execution failed: %w, output: %s", err, string(output))
 	}
@@ -378,17 +1285,17 @@ func (s *TestService) executeJestTests(run *TestRun) error {
 	if run.Request.TestSuite != "" {
 		args = append(args, run.Request.TestSuite)
 	}
+	args = append(args, testNameFilterArgs(run.Request)...)
 
 	cmd := exec.CommandContext(run.Context, "npx", append([]string{"jest"}, args...)...)
+	configureProcessGroup(cmd)
 
 	// Set working directory
 	if workDir := run.Request.Config["workDir"]; workDir != "" {
 		cmd.Dir = workDir
 	}
 
-	run.Process = cmd
-
-	output, err := cmd.CombinedOutput()
+	output, err := s.runStreamingCommand(run, cmd)
 	if err != nil {
 		return fmt.Errorf("jest execution failed: %w, output: %s", err, string(output))
 	}
@@ -400,32 +1307,277 @@ func (s *TestService) executeJestTests(run *TestRun) error {
 func (s *TestService) executeVitestTests(run *TestRun) error {
 	log.Printf("Executing Vitest tests for run %s", run.ID)
 
-	args := []string{"run", "--reporter=json"}
+	// Write the JSON report to its own file so console output from tests can't corrupt it
+	reportPath, err := createReportFile("vitest")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(reportPath)
+
+	args := []string{"run", "--reporter=json", "--outputFile=" + reportPath}
 
 	if run.Request.TestSuite != "" {
 		args = append(args, run.Request.TestSuite)
 	}
+	args = append(args, testNameFilterArgs(run.Request)...)
 
 	cmd := exec.CommandContext(run.Context, "npx", append([]string{"vitest"}, args...)...)
+	configureProcessGroup(cmd)
+
+	if workDir := run.Request.Config["workDir"]; workDir != "" {
+		cmd.Dir = workDir
+	}
+
+	// Vitest exits non-zero when tests fail, so read the report either way
+	output, runErr := s.runStreamingCommand(run, cmd)
+	report, _ := os.ReadFile(reportPath)
+
+	parseErr := s.parseVitestResults(run, report, string(output))
+
+	if runErr != nil {
+		if run.Results.FailedTests > 0 {
+			return fmt.Errorf("vitest reported %d failing test(s)", run.Results.FailedTests)
+		}
+		return fmt.Errorf("vitest execution failed: %w, output: %s", runErr, string(output))
+	}
+	if parseErr != nil {
+		return fmt.Errorf("failed to parse vitest output: %w", parseErr)
+	}
+
+	return nil
+}
+
+// createReportFile reserves a temporary file for a framework to write its JSON report to.
+// The caller removes the file once the report has been read.
+func createReportFile(framework string) (string, error) {
+	reportFile, err := os.CreateTemp("", framework+"-report-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s report file: %w", framework, err)
+	}
+	reportPath := reportFile.Name()
+	reportFile.Close()
+	return reportPath, nil
+}
+
+// executeMochaTests executes Mocha tests
+func (s *TestService) executeMochaTests(run *TestRun) error {
+	log.Printf("Executing Mocha tests for run %s", run.ID)
+
+	// Write the JSON report to its own file so console.log output from tests can't corrupt it
+	reportPath, err := createReportFile("mocha")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(reportPath)
+
+	args := []string{"--reporter", "json", "--reporter-option", "output=" + reportPath}
+
+	if run.Request.TestSuite != "" {
+		args = append(args, run.Request.TestSuite)
+	}
+	args = append(args, testNameFilterArgs(run.Request)...)
+
+	cmd := exec.CommandContext(run.Context, "npx", append([]string{"mocha"}, args...)...)
+	configureProcessGroup(cmd)
 
 	if workDir := run.Request.Config["workDir"]; workDir != "" {
 		cmd.Dir = workDir
 	}
 
-	run.Process = cmd
+	// Mocha exits non-zero when tests fail, so read the report either way
+	output, runErr := s.runStreamingCommand(run, cmd)
+	report, _ := os.ReadFile(reportPath)
+
+	if parseErr := s.parseMochaReport(run, report, string(output)); parseErr != nil {
+		if runErr != nil {
+			return fmt.Errorf("mocha execution failed: %w, output: %s", runErr, string(output))
+		}
+		return fmt.Errorf("failed to parse mocha report: %w", parseErr)
+	}
+
+	if runErr != nil {
+		if run.Results.FailedTests > 0 {
+			return fmt.Errorf("mocha reported %d failing test(s)", run.Results.FailedTests)
+		}
+		return fmt.Errorf("mocha execution failed: %w, output: %s", runErr, string(output))
+	}
+
+	return nil
+}
+
+// executePytestTests executes pytest tests. Requires the pytest-json-report plugin.
+func (s *TestService) executePytestTests(run *TestRun) error {
+	log.Printf("Executing pytest tests for run %s", run.ID)
 
-	output, err := cmd.CombinedOutput()
+	reportPath, err := createReportFile("pytest")
 	if err != nil {
-		return fmt.Errorf("vitest execution failed: %w, output: %s", err, string(output))
+		return err
+	}
+	defer os.Remove(reportPath)
+
+	args := []string{"--json-report", "--json-report-file=" + reportPath}
+	args = append(args, pytestTargets(run.Request)...)
+
+	cmd := exec.CommandContext(run.Context, "pytest", args...)
+	configureProcessGroup(cmd)
+
+	if workDir := run.Request.Config["workDir"]; workDir != "" {
+		cmd.Dir = workDir
+	}
+
+	// pytest exits 1 when tests fail, so read the report either way
+	output, runErr := s.runStreamingCommand(run, cmd)
+	report, _ := os.ReadFile(reportPath)
+
+	if parseErr := s.parsePytestReport(run, report); parseErr != nil {
+		if runErr != nil {
+			return fmt.Errorf("pytest execution failed: %w, output: %s", runErr, string(output))
+		}
+		return fmt.Errorf("failed to parse pytest report: %w", parseErr)
+	}
+
+	if runErr != nil {
+		if run.Results.FailedTests > 0 {
+			return fmt.Errorf("pytest reported %d failing test(s)", run.Results.FailedTests)
+		}
+		return fmt.Errorf("pytest execution failed: %w, output: %s", runErr, string(output))
+	}
+
+	return nil
+}
+
+// executeGoTests executes Go tests, treating the test suite as a package pattern
+func (s *TestService) executeGoTests(run *TestRun) error {
+	log.Printf("Executing Go tests for run %s", run.ID)
+
+	args := []string{"test", "-json"}
+	args = append(args, testNameFilterArgs(run.Request)...)
+
+	if run.Request.TestSuite != "" {
+		args = append(args, run.Request.TestSuite)
+	}
+
+	cmd := exec.CommandContext(run.Context, "go", args...)
+	configureProcessGroup(cmd)
+
+	if workDir := run.Request.Config["workDir"]; workDir != "" {
+		cmd.Dir = workDir
+	}
+
+	// go test exits non-zero when tests fail, so parse the events either way
+	output, runErr := s.runStreamingCommand(run, cmd)
+
+	if parseErr := s.parseGoTestOutput(run, string(output)); parseErr != nil {
+		if runErr != nil {
+			return fmt.Errorf("go test execution failed: %w, output: %s", runErr, string(output))
+		}
+		return fmt.Errorf("failed to parse go test output: %w", parseErr)
+	}
+
+	if runErr != nil {
+		if run.Results.FailedTests > 0 {
+			return fmt.Errorf("go test reported %d failing test(s)", run.Results.FailedTests)
+		}
+		return fmt.Errorf("go test execution failed: %w, output: %s", runErr, string(output))
+	}
+
+	return nil
+}
+
+// mochaReportStart finds the start of a Mocha JSON report printed to the console
+var mochaReportStart = regexp.MustCompile(`\{\s*"stats"\s*:`)
+
+// mochaTest is a single test entry in a Mocha JSON report, as produced by Mocha's and Cypress's json reporter
+type mochaTest struct {
+	Title     string  `json:"title"`
+	FullTitle string  `json:"fullTitle"`
+	Duration  float64 `json:"duration"` // milliseconds
+	Err       struct {
+		Message string `json:"message"`
+		Stack   string `json:"stack"`
+	} `json:"err"`
+}
+
+// parseMochaReport fills the run's results from a Mocha JSON report. If report is empty, a
+// report printed to the console output is used instead. Results are only modified on success.
+func (s *TestService) parseMochaReport(run *TestRun, report []byte, output string) error {
+	if len(bytes.TrimSpace(report)) == 0 {
+		loc := mochaReportStart.FindStringIndex(output)
+		if loc == nil {
+			return fmt.Errorf("no JSON report found")
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(output[loc[0]:])).Decode(&raw); err != nil {
+			return fmt.Errorf("invalid JSON report in output: %w", err)
+		}
+		report = raw
+	}
+
+	var mochaResult struct {
+		Stats    *struct{}   `json:"stats"`
+		Tests    []mochaTest `json:"tests"`
+		Pending  []mochaTest `json:"pending"`
+		Failures []mochaTest `json:"failures"`
+	}
+	if err := json.Unmarshal(report, &mochaResult); err != nil {
+		return fmt.Errorf("invalid JSON report: %w", err)
+	}
+	if mochaResult.Stats == nil {
+		return fmt.Errorf("JSON report has no stats")
+	}
+
+	pending := make(map[string]bool, len(mochaResult.Pending))
+	for _, test := range mochaResult.Pending {
+		pending[test.FullTitle] = true
+	}
+
+	cases := make([]models.TestCase, 0, len(mochaResult.Tests))
+	seen := make(map[string]bool, len(mochaResult.Tests))
+	for _, test := range mochaResult.Tests {
+		seen[test.FullTitle] = true
+		cases = append(cases, mochaTestCase(test, pending[test.FullTitle]))
+	}
+
+	// Hook failures (e.g. "before all") are reported as failures without a matching test
+	for _, test := range mochaResult.Failures {
+		if !seen[test.FullTitle] {
+			cases = append(cases, mochaTestCase(test, false))
+		}
+	}
+
+	setResultCases(run, cases)
+	return nil
+}
+
+// mochaTestCase converts a Mocha test entry to a test case
+func mochaTestCase(test mochaTest, isPending bool) models.TestCase {
+	name := test.FullTitle
+	if name == "" {
+		name = test.Title
+	}
+
+	testCase := models.TestCase{
+		Name:     name,
+		Status:   "passed",
+		Duration: time.Duration(test.Duration * float64(time.Millisecond)),
+	}
+
+	switch {
+	case test.Err.Message != "":
+		testCase.Status = "failed"
+		testCase.ErrorMsg = test.Err.Message
+		testCase.StackTrace = test.Err.Stack
+	case isPending:
+		testCase.Status = "skipped"
 	}
 
-	return s.parseVitestResults(run, string(output))
+	return testCase
 }
 
 // Helper methods for parsing test results
+
+// parseCypressResults estimates results from Cypress console output; used only when no JSON report is available
 func (s *TestService) parseCypressResults(run *TestRun, output string) error {
-	// Simplified Cypress result parsing
-	// In production, you'd parse the actual Cypress JSON output
 	lines := strings.Split(output, "\n")
 
 	totalTests := 0
@@ -556,9 +1708,325 @@ func (s *TestService) parseJestResults(run *TestRun, output string) error {
 	return nil
 }
 
-func (s *TestService) parseVitestResults(run *TestRun, output string) error {
-	// Similar to Jest parsing but for Vitest format
-	return s.parseSimpleTestOutput(run, output)
+// vitestReportStart finds the start of a Vitest JSON report printed to the console
+var vitestReportStart = regexp.MustCompile(`\{\s*"numTotalTestSuites"\s*:`)
+
+// vitestAssertion is one test in a Vitest JSON report
+type vitestAssertion struct {
+	AncestorTitles  []string `json:"ancestorTitles"`
+	FullName        string   `json:"fullName"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`   // passed, failed, skipped, pending or todo
+	Duration        float64  `json:"duration"` // milliseconds
+	FailureMessages []string `json:"failureMessages"`
+}
+
+// parseVitestResults fills the run's results from a Vitest JSON report. If report is empty, a
+// report printed to the console output is used instead. Without a valid report, results are
+// estimated from the console output.
+func (s *TestService) parseVitestResults(run *TestRun, report []byte, output string) error {
+	if err := s.parseVitestReport(run, report, output); err != nil {
+		log.Printf("No usable Vitest JSON report for run %s, falling back to console output: %v", run.ID, err)
+		return s.parseSimpleTestOutput(run, output)
+	}
+	return nil
+}
+
+// parseVitestReport fills the run's results from a Vitest JSON report. Results are only modified on success.
+func (s *TestService) parseVitestReport(run *TestRun, report []byte, output string) error {
+	if len(bytes.TrimSpace(report)) == 0 {
+		loc := vitestReportStart.FindStringIndex(output)
+		if loc == nil {
+			return fmt.Errorf("no JSON report found")
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(output[loc[0]:])).Decode(&raw); err != nil {
+			return fmt.Errorf("invalid JSON report in output: %w", err)
+		}
+		report = raw
+	}
+
+	var vitestResult struct {
+		NumTotalTests *int `json:"numTotalTests"`
+		TestResults   []struct {
+			Name             string            `json:"name"` // Absolute path of the test file
+			Status           string            `json:"status"`
+			Message          string            `json:"message"`
+			AssertionResults []vitestAssertion `json:"assertionResults"`
+		} `json:"testResults"`
+	}
+	if err := json.Unmarshal(report, &vitestResult); err != nil {
+		return fmt.Errorf("invalid JSON report: %w", err)
+	}
+	if vitestResult.NumTotalTests == nil {
+		return fmt.Errorf("JSON report has no test counts")
+	}
+
+	cases := make([]models.TestCase, 0, *vitestResult.NumTotalTests)
+	for _, file := range vitestResult.TestResults {
+		fileName := vitestFileName(file.Name, run.Request.Config["workDir"])
+		for _, test := range file.AssertionResults {
+			cases = append(cases, vitestTestCase(fileName, test))
+		}
+
+		// A file that fails to load (e.g. a syntax error or a failing beforeAll) has no tests to report it
+		if file.Status == "failed" && file.Message != "" && len(file.AssertionResults) == 0 {
+			errorMsg, _, _ := strings.Cut(strings.TrimSpace(file.Message), "\n")
+			cases = append(cases, models.TestCase{
+				Name:       fileName,
+				Status:     "failed",
+				ErrorMsg:   errorMsg,
+				StackTrace: file.Message,
+			})
+		}
+	}
+
+	setResultCases(run, cases)
+	return nil
+}
+
+// vitestTestCase converts a Vitest test to a test case named like Vitest's own output, e.g.
+// "src/cart.test.ts > Cart > adds items"
+func vitestTestCase(fileName string, test vitestAssertion) models.TestCase {
+	name := test.FullName
+	if name == "" {
+		name = strings.Join(append(append([]string(nil), test.AncestorTitles...), test.Title), " > ")
+	}
+	if fileName != "" {
+		name = fileName + " > " + name
+	}
+
+	testCase := models.TestCase{
+		Name:     name,
+		Status:   "skipped",
+		Duration: time.Duration(test.Duration * float64(time.Millisecond)),
+	}
+
+	switch test.Status {
+	case "passed":
+		testCase.Status = "passed"
+	case "failed":
+		testCase.Status = "failed"
+		if len(test.FailureMessages) > 0 {
+			testCase.ErrorMsg, _, _ = strings.Cut(strings.TrimSpace(test.FailureMessages[0]), "\n")
+			testCase.StackTrace = strings.Join(test.FailureMessages, "\n\n")
+		}
+	}
+
+	return testCase
+}
+
+// vitestFileName shortens a test file's absolute path to be relative to the directory the tests
+// ran in, falling back to the path as reported
+func vitestFileName(path, workDir string) string {
+	if path == "" {
+		return ""
+	}
+	if workDir == "" {
+		var err error
+		if workDir, err = os.Getwd(); err != nil {
+			return path
+		}
+	}
+	if absWorkDir, err := filepath.Abs(workDir); err == nil {
+		if rel, err := filepath.Rel(absWorkDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// pytestStage is one phase (setup, call or teardown) of a test in a pytest-json-report report
+type pytestStage struct {
+	Duration float64 `json:"duration"` // seconds
+	Outcome  string  `json:"outcome"`
+	Longrepr string  `json:"longrepr"`
+	Crash    struct {
+		Message string `json:"message"`
+	} `json:"crash"`
+}
+
+// parsePytestReport fills the run's results from a pytest-json-report report. Results are only modified on success.
+func (s *TestService) parsePytestReport(run *TestRun, report []byte) error {
+	var pytestResult struct {
+		Summary *struct{} `json:"summary"`
+		Tests   []struct {
+			NodeID   string       `json:"nodeid"`
+			Outcome  string       `json:"outcome"`
+			Setup    *pytestStage `json:"setup"`
+			Call     *pytestStage `json:"call"`
+			Teardown *pytestStage `json:"teardown"`
+		} `json:"tests"`
+	}
+	if err := json.Unmarshal(report, &pytestResult); err != nil {
+		return fmt.Errorf("invalid JSON report: %w", err)
+	}
+	if pytestResult.Summary == nil {
+		return fmt.Errorf("JSON report has no summary")
+	}
+
+	cases := make([]models.TestCase, 0, len(pytestResult.Tests))
+	for _, test := range pytestResult.Tests {
+		testCase := models.TestCase{Name: test.NodeID}
+
+		// Expected failures count as skipped, unexpected passes as passed, and errors in fixtures as failed
+		switch test.Outcome {
+		case "passed", "xpassed":
+			testCase.Status = "passed"
+		case "skipped", "xfailed":
+			testCase.Status = "skipped"
+		default:
+			testCase.Status = "failed"
+		}
+
+		for _, stage := range []*pytestStage{test.Setup, test.Call, test.Teardown} {
+			if stage == nil {
+				continue
+			}
+			testCase.Duration += time.Duration(stage.Duration * float64(time.Second))
+			if testCase.Status == "failed" && testCase.ErrorMsg == "" && stage.Outcome == "failed" {
+				testCase.ErrorMsg = stage.Crash.Message
+				testCase.StackTrace = stage.Longrepr
+				if testCase.ErrorMsg == "" {
+					testCase.ErrorMsg = stage.Longrepr
+				}
+			}
+		}
+
+		cases = append(cases, testCase)
+	}
+
+	setResultCases(run, cases)
+	return nil
+}
+
+// goTestEvent is a single line of `go test -json` output
+type goTestEvent struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+	Elapsed float64 `json:"Elapsed"` // seconds
+	Output  string  `json:"Output"`
+}
+
+// parseGoTestOutput fills the run's results from `go test -json` events. Lines that aren't events,
+// such as build errors on stderr, are ignored. Results are only modified on success.
+func (s *TestService) parseGoTestOutput(run *TestRun, output string) error {
+	type goTestState struct {
+		testCase models.TestCase
+		output   strings.Builder
+		done     bool
+	}
+
+	var (
+		tests        = make(map[string]*goTestState)
+		order        []string
+		failedPkgs   []string
+		pkgHasFailed = make(map[string]bool)
+		pkgOutput    = make(map[string]*strings.Builder)
+		sawEvent     bool
+	)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var event goTestEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.Action == "" {
+			continue
+		}
+		sawEvent = true
+
+		// Package-level events only matter when the package fails without a failing test, e.g. a build error
+		if event.Test == "" {
+			switch event.Action {
+			case "output":
+				if pkgOutput[event.Package] == nil {
+					pkgOutput[event.Package] = &strings.Builder{}
+				}
+				pkgOutput[event.Package].WriteString(event.Output)
+			case "fail":
+				failedPkgs = append(failedPkgs, event.Package)
+			}
+			continue
+		}
+
+		key := event.Package + "." + event.Test
+		state := tests[key]
+		if state == nil {
+			state = &goTestState{testCase: models.TestCase{Name: key}}
+			tests[key] = state
+			order = append(order, key)
+		}
+
+		switch event.Action {
+		case "output":
+			state.output.WriteString(event.Output)
+		case "pass":
+			state.done = true
+			state.testCase.Status = "passed"
+			state.testCase.Duration = time.Duration(event.Elapsed * float64(time.Second))
+		case "skip":
+			state.done = true
+			state.testCase.Status = "skipped"
+			state.testCase.Duration = time.Duration(event.Elapsed * float64(time.Second))
+		case "fail":
+			state.done = true
+			state.testCase.Status = "failed"
+			state.testCase.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			state.testCase.ErrorMsg = strings.TrimSpace(state.output.String())
+			pkgHasFailed[event.Package] = true
+		}
+	}
+
+	if !sawEvent {
+		return fmt.Errorf("no go test events found")
+	}
+
+	cases := make([]models.TestCase, 0, len(order)+len(failedPkgs))
+	for _, key := range order {
+		state := tests[key]
+		// Tests still running when the binary exited (panic or timeout) are failures
+		if !state.done {
+			state.testCase.Status = "failed"
+			state.testCase.ErrorMsg = strings.TrimSpace(state.output.String())
+		}
+		cases = append(cases, state.testCase)
+	}
+	for _, pkg := range failedPkgs {
+		if pkgHasFailed[pkg] {
+			continue
+		}
+		testCase := models.TestCase{Name: pkg, Status: "failed"}
+		if out := pkgOutput[pkg]; out != nil {
+			testCase.ErrorMsg = strings.TrimSpace(out.String())
+		}
+		cases = append(cases, testCase)
+	}
+
+	setResultCases(run, cases)
+	return nil
+}
+
+// setResultCases records parsed test cases on the run and recounts its totals
+func setResultCases(run *TestRun, cases []models.TestCase) {
+	run.Results.TotalTests = len(cases)
+	run.Results.PassedTests = 0
+	run.Results.FailedTests = 0
+	run.Results.SkippedTests = 0
+	for _, testCase := range cases {
+		switch testCase.Status {
+		case "passed":
+			run.Results.PassedTests++
+		case "failed":
+			run.Results.FailedTests++
+		case "skipped":
+			run.Results.SkippedTests++
+		}
+	}
+	run.Results.Results = append(run.Results.Results, cases...)
 }
 
 func (s *TestService) parseSimpleTestOutput(run *TestRun, output string) error {
@@ -587,6 +2055,88 @@ func (s *TestService) parseSimpleTestOutput(run *TestRun, output string) error {
 	return nil
 }
 
+// assertionOutcome is the result of running one sync assertion, or why it couldn't run
+type assertionOutcome struct {
+	result *models.SyncAssertionResult
+	err    error
+}
+
+// runAssertions runs the request's assertions on up to SyncAssertionWorkers goroutines and returns
+// their outcomes indexed like req.Assertions
+func (s *TestService) runAssertions(ctx context.Context, req *models.TestSyncValidationRequest, timeout time.Duration) []assertionOutcome {
+	outcomes := make([]assertionOutcome, len(req.Assertions))
+
+	workers := defaultAssertionWorkers
+	if s.config != nil && s.config.SyncAssertionWorkers > 0 {
+		workers = s.config.SyncAssertionWorkers
+	}
+	workers = min(workers, len(req.Assertions))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := s.runAssertionWithTimeout(ctx, req, req.Assertions[i], timeout)
+				outcomes[i] = assertionOutcome{result: result, err: err}
+			}
+		}()
+	}
+
+	for i := range req.Assertions {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return outcomes
+}
+
+// assertionTimeout returns the per-assertion timeout from the request config, falling back to the service configuration
+func (s *TestService) assertionTimeout(req *models.TestSyncValidationRequest) (time.Duration, error) {
+	if value, ok := req.Config[models.SyncTimeoutConfigKey]; ok && value != "" {
+		return models.ParseSyncTimeout(value)
+	}
+
+	if s.config != nil && s.config.SyncAssertionTimeout > 0 {
+		return time.Duration(s.config.SyncAssertionTimeout) * time.Second, nil
+	}
+
+	return defaultAssertionTimeout, nil
+}
+
+// runAssertionWithTimeout executes an assertion, marking it as failed if it does not finish within timeout
+func (s *TestService) runAssertionWithTimeout(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion, timeout time.Duration) (*models.SyncAssertionResult, error) {
+	assertionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Buffered so the runner can finish and exit even after we stop waiting for it
+	done := make(chan assertionOutcome, 1)
+	go func() {
+		result, err := s.assertionRunner(assertionCtx, req, assertion)
+		done <- assertionOutcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-assertionCtx.Done():
+		// The caller went away; report that rather than blaming the assertion
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("sync validation cancelled: %w", ctx.Err())
+		}
+
+		return &models.SyncAssertionResult{
+			Assertion: assertion,
+			Passed:    false,
+			Message:   fmt.Sprintf("Assertion did not complete within %s", timeout),
+			Reason:    models.SyncAssertionReasonTimeout,
+		}, nil
+	}
+}
+
 // executeAssertion executes a single sync assertion
 func (s *TestService) executeAssertion(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion) (*models.SyncAssertionResult, error) {
 	// This is a simplified implementation
@@ -623,10 +2173,9 @@ func (s *TestService) executeAssertion(ctx context.Context, req *models.TestSync
 
 // Helper methods
 func (s *TestService) isFrameworkSupported(framework string) bool {
-	supported := []string{"cypress", "playwright", "jest", "vitest"}
 	framework = strings.ToLower(framework)
 
-	for _, f := range supported {
+	for _, f := range models.SupportedFrameworks {
 		if f == framework {
 			return true
 		}
@@ -645,6 +2194,12 @@ func (s *TestService) getEstimatedDuration(framework string) time.Duration {
 		return time.Minute * 2
 	case "vitest":
 		return time.Minute * 1
+	case "mocha":
+		return time.Minute * 2
+	case "pytest":
+		return time.Minute * 3
+	case "go":
+		return time.Minute * 3
 	default:
 		return time.Minute * 5
 	}
@@ -667,6 +2222,10 @@ func (s *TestService) getSeverityFromAssertion(assertion models.SyncAssertion) s
 }
 
 func (s *TestService) getSuggestionFromAssertion(assertion models.SyncAssertion, result *models.SyncAssertionResult) string {
+	if result != nil && result.Reason == models.SyncAssertionReasonTimeout {
+		return "Check that the target endpoint is responsive, or raise the timeout in the request config"
+	}
+
 	switch assertion.Type {
 	case "data_match":
 		return "Check API response format and UI data binding"
@@ -728,10 +2287,18 @@ func (s *TestService) broadcastTestUpdate(runID, status, message string) {
 	}
 
 	if exists && run != nil {
+		if s.config != nil && s.config.EnableWSCorrelationID && run.TraceID != "" {
+			data["trace_id"] = run.TraceID
+		}
+
 		data["framework"] = run.Request.Framework
 		data["environment"] = run.Request.Environment
 		data["start_time"] = run.StartTime
 
+		if dropped := run.droppedLogLines.Load(); dropped > 0 {
+			data["dropped_log_lines"] = dropped
+		}
+
 		if run.Results != nil {
 			data["total_tests"] = run.Results.TotalTests
 			data["passed_tests"] = run.Results.PassedTests
@@ -751,7 +2318,13 @@ func (s *TestService) broadcastTestUpdate(runID, status, message string) {
 
 func (s *TestService) moveToHistory(run *TestRun) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+
+	// The reaper may have moved the run already
+	if _, active := s.activeRuns[run.ID]; !active {
+		s.mu.Unlock()
+		run.finish()
+		return
+	}
 
 	// Remove from active runs
 	delete(s.activeRuns, run.ID)
@@ -759,10 +2332,32 @@ func (s *TestService) moveToHistory(run *TestRun) {
 	// Add to history
 	s.runHistory = append(s.runHistory, *run.Results)
 
-	// Trim history if it exceeds max size
+	// Trim history if it exceeds max size. The trimmed run's artifacts go with it unless the
+	// history store still serves the run; limitArtifacts bounds those once the run is saved.
 	if len(s.runHistory) > s.maxHistory {
+		if s.historyStore == nil {
+			s.removeArtifacts(s.runHistory[0].RunID)
+		}
 		s.runHistory = s.runHistory[1:]
 	}
+	results := *run.Results
+	s.mu.Unlock()
+
+	// Persisting happens outside the lock so slow disks don't stall other requests. The run is
+	// already served from memory, and waiters are released once it is saved.
+	if s.historyStore != nil {
+		if err := s.historyStore.Save(results); err != nil {
+			log.Printf("Failed to persist test run %s: %v", run.ID, err)
+		}
+	}
+
+	s.limitArtifacts()
+
+	if run.Request != nil && run.Request.CallbackURL != "" {
+		s.sendRunCallback(run.Request.CallbackURL, *redactTestResults(results))
+	}
+
+	run.finish()
 }
 
 // GetStatus returns the current status of the test service
@@ -772,7 +2367,12 @@ func (s *TestService) GetStatus() map[string]interface{} {
 
 	return map[string]interface{}{
 		"active_runs":          len(s.activeRuns),
+		"running_runs":         s.runningRuns,
+		"queued_runs":          len(s.queuedRuns),
+		"max_concurrent_runs":  s.maxConcurrentRuns,
 		"history_count":        len(s.runHistory),
-		"supported_frameworks": []string{"cypress", "playwright", "jest", "vitest"},
+		"reaped_runs":          s.reapedRuns,
+		"watchdog":             s.watchdogStatus(),
+		"supported_frameworks": append([]string(nil), models.SupportedFrameworks...),
 	}
 }
@@ -2,11 +2,18 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -51,7 +58,9 @@ func TestTestService_IsFrameworkSupported(t *testing.T) {
 		{"vitest", true},
 		{"CYPRESS", true}, // Case insensitive
 		{"Playwright", true},
-		{"mocha", false},
+		{"mocha", true},
+		{"pytest", true},
+		{"go", true},
 		{"jasmine", false},
 		{"", false},
 	}
@@ -75,6 +84,9 @@ func TestTestService_GetEstimatedDuration(t *testing.T) {
 		{"playwright", time.Minute * 3},
 		{"jest", time.Minute * 2},
 		{"vitest", time.Minute * 1},
+		{"mocha", time.Minute * 2},
+		{"pytest", time.Minute * 3},
+		{"go", time.Minute * 3},
 		{"unknown", time.Minute * 5},
 	}
 
@@ -130,12 +142,76 @@ func TestTestService_StartTestRun(t *testing.T) {
 	assert.Contains(t, []string{"queued", "running"}, run.Status)
 }
 
+func TestTestService_BroadcastTestUpdate_TraceID(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		expectTraceID bool
+	}{
+		{"propagation enabled", true, true},
+		{"propagation disabled", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{EnableWSCorrelationID: tt.enabled}
+
+			var broadcast map[string]interface{}
+			mockHub := &MockWebSocketHub{}
+			mockHub.On("BroadcastToAll", "test_progress", mock.Anything).Run(func(args mock.Arguments) {
+				broadcast = args.Get(1).(map[string]interface{})
+			}).Return()
+
+			service := NewTestService(cfg, mockHub)
+			service.activeRuns["run-1"] = &TestRun{
+				ID:        "run-1",
+				Request:   &models.TestRunRequest{Framework: "jest", Environment: "test"},
+				StartTime: time.Now(),
+				TraceID:   "trace-123",
+			}
+
+			service.broadcastTestUpdate("run-1", "running", "Test execution started")
+
+			require.NotNil(t, broadcast)
+			if tt.expectTraceID {
+				assert.Equal(t, "trace-123", broadcast["trace_id"])
+			} else {
+				assert.NotContains(t, broadcast, "trace_id")
+			}
+		})
+	}
+}
+
+func TestTestService_StartTestRun_CapturesTraceID(t *testing.T) {
+	service := createTestService()
+
+	ctx := utils.ContextWithTraceID(context.Background(), "trace-abc")
+	req := &models.TestRunRequest{
+		Framework:   "jest",
+		TestSuite:   "unit",
+		Environment: "test",
+	}
+
+	response, err := service.StartTestRun(ctx, req)
+	require.NoError(t, err)
+
+	service.mu.RLock()
+	run, exists := service.activeRuns[response.RunID]
+	service.mu.RUnlock()
+
+	if exists {
+		assert.Equal(t, "trace-abc", run.TraceID)
+	}
+
+	service.CancelTestRun(response.RunID)
+}
+
 func TestTestService_StartTestRun_UnsupportedFramework(t *testing.T) {
 	service := createTestService()
 	ctx := context.Background()
 
 	req := &models.TestRunRequest{
-		Framework:   "mocha",
+		Framework:   "jasmine",
 		TestSuite:   "test.spec.js",
 		Environment: "development",
 	}
@@ -144,7 +220,7 @@ func TestTestService_StartTestRun_UnsupportedFramework(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Nil(t, response)
-	assert.Contains(t, err.Error(), "unsupported test framework: mocha")
+	assert.Contains(t, err.Error(), "unsupported test framework: jasmine")
 }
 
 func TestTestService_GetTestResults(t *testing.T) {
@@ -212,17 +288,469 @@ func TestTestService_CancelTestRun(t *testing.T) {
 	err = service.CancelTestRun(response.RunID)
 	require.NoError(t, err)
 
-	// Verify the run is cancelled
+	// Verify the run is cancelled. The run's state is read under the lock its executor writes it under.
 	service.mu.RLock()
+	defer service.mu.RUnlock()
 	run, exists := service.activeRuns[response.RunID]
-	service.mu.RUnlock()
 
-	assert.True(t, exists)
+	require.True(t, exists)
 	assert.Equal(t, "cancelled", run.Status)
 	assert.Equal(t, "cancelled", run.Results.Status)
 	assert.False(t, run.EndTime.IsZero())
 }
 
+func TestTestService_RunQueue(t *testing.T) {
+	cfg := &config.Config{CypressBaseURL: "http://localhost:3000", MaxConcurrentTestRuns: 5}
+
+	var broadcastMu sync.Mutex
+	var broadcasts []string
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "test_progress", mock.Anything).Run(func(args mock.Arguments) {
+		data := args.Get(1).(map[string]interface{})
+		broadcastMu.Lock()
+		broadcasts = append(broadcasts, fmt.Sprintf("%s:%s", data["run_id"], data["status"]))
+		broadcastMu.Unlock()
+	}).Return()
+
+	// The service config overrides MAX_CONCURRENT_TEST_RUNS
+	service := NewTestService(cfg, mockHub, TestServiceConfig{MaxConcurrentRuns: 1})
+	assert.Equal(t, 1, service.maxConcurrentRuns)
+
+	release := make(chan struct{})
+	var executedMu sync.Mutex
+	var executed []string
+	service.runExecutor = func(run *TestRun) error {
+		executedMu.Lock()
+		executed = append(executed, run.ID)
+		executedMu.Unlock()
+		<-release
+		return nil
+	}
+
+	req := &models.TestRunRequest{Framework: "cypress", Environment: "development"}
+	first, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+	second, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", second.Status)
+
+	// Only the first run executes; the second waits its turn
+	active := service.GetActiveRuns()
+	require.Len(t, active, 2)
+	assert.Equal(t, "running", active[first.RunID].Status)
+	assert.Zero(t, active[first.RunID].QueuePosition)
+	assert.Equal(t, "queued", active[second.RunID].Status)
+	assert.Equal(t, 1, active[second.RunID].QueuePosition)
+
+	status := service.GetStatus()
+	assert.Equal(t, 1, status["running_runs"])
+	assert.Equal(t, 1, status["queued_runs"])
+	assert.Equal(t, 1, status["max_concurrent_runs"])
+
+	broadcastMu.Lock()
+	assert.NotContains(t, broadcasts, second.RunID+":running")
+	broadcastMu.Unlock()
+
+	// Finishing the first run frees the slot for the second
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		executedMu.Lock()
+		defer executedMu.Unlock()
+		return len(executed) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	active = service.GetActiveRuns()
+	require.Len(t, active, 1)
+	assert.Equal(t, "running", active[second.RunID].Status)
+
+	broadcastMu.Lock()
+	assert.Contains(t, broadcasts, second.RunID+":running")
+	broadcastMu.Unlock()
+
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	status = service.GetStatus()
+	assert.Equal(t, 0, status["running_runs"])
+	assert.Equal(t, 2, status["history_count"])
+}
+
+func TestTestService_WaitForTestRun(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxConcurrentRuns: 1})
+	release := make(chan struct{})
+	service.runExecutor = func(run *TestRun) error {
+		<-release
+		return nil
+	}
+
+	req := &models.TestRunRequest{Framework: "cypress", Environment: "development"}
+	running, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+
+	t.Run("times out while the run is going", func(t *testing.T) {
+		_, err := service.WaitForTestRun(context.Background(), running.RunID, 20*time.Millisecond)
+		assert.ErrorIs(t, err, ErrTestRunWaitTimeout)
+		assert.Len(t, service.GetActiveRuns(), 1)
+	})
+
+	t.Run("returns the results once the run finishes", func(t *testing.T) {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			release <- struct{}{}
+		}()
+		results, err := service.WaitForTestRun(context.Background(), running.RunID, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, running.RunID, results.RunID)
+		assert.Equal(t, "completed", results.Status)
+		assert.False(t, results.EndTime.IsZero())
+	})
+
+	t.Run("finished runs are answered from history", func(t *testing.T) {
+		results, err := service.WaitForTestRun(context.Background(), running.RunID, time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, "completed", results.Status)
+	})
+
+	t.Run("cancelled queued runs", func(t *testing.T) {
+		blocking, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+		queued, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			service.CancelTestRun(queued.RunID)
+		}()
+		results, err := service.WaitForTestRun(context.Background(), queued.RunID, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", results.Status)
+
+		release <- struct{}{}
+		_, err = service.WaitForTestRun(context.Background(), blocking.RunID, time.Second)
+		require.NoError(t, err)
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		pending, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = service.WaitForTestRun(ctx, pending.RunID, time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		close(release)
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		_, err := service.WaitForTestRun(context.Background(), "missing", time.Second)
+		assert.Error(t, err)
+	})
+}
+
+func TestTestService_CancelQueuedRun(t *testing.T) {
+	service := createTestService()
+	service.maxConcurrentRuns = 1
+
+	release := make(chan struct{})
+	var executedMu sync.Mutex
+	var executed []string
+	service.runExecutor = func(run *TestRun) error {
+		executedMu.Lock()
+		executed = append(executed, run.ID)
+		executedMu.Unlock()
+		<-release
+		return nil
+	}
+
+	req := &models.TestRunRequest{Framework: "jest", Environment: "development"}
+	first, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+	queued, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+
+	require.NoError(t, service.CancelTestRun(queued.RunID))
+
+	// The cancelled run leaves the queue and goes straight to history
+	active := service.GetActiveRuns()
+	assert.Len(t, active, 1)
+	assert.NotContains(t, active, queued.RunID)
+	assert.Equal(t, 0, service.GetStatus()["queued_runs"])
+
+	result, err := service.GetTestResults(queued.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", result.Status)
+	assert.False(t, result.EndTime.IsZero())
+
+	// Cancelling it again reports it as gone
+	assert.Error(t, service.CancelTestRun(queued.RunID))
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	executedMu.Lock()
+	defer executedMu.Unlock()
+	assert.Equal(t, []string{first.RunID}, executed)
+}
+
+func TestTestRun_SendLogLine(t *testing.T) {
+	run := &TestRun{LogChannel: make(chan string, 2)}
+
+	run.sendLogLine("first")
+	run.sendLogLine("second")
+	// The channel is full, so further lines are dropped rather than blocking
+	run.sendLogLine("third")
+	run.sendLogLine("fourth")
+
+	assert.Equal(t, int64(2), run.droppedLogLines.Load())
+	assert.Equal(t, "first", <-run.LogChannel)
+	assert.Equal(t, "second", <-run.LogChannel)
+
+	run.sendLogLine(strings.Repeat("x", maxStreamedLineLength+10))
+	assert.Equal(t, strings.Repeat("x", maxStreamedLineLength)+"…", <-run.LogChannel)
+
+	// Runs without a channel ignore output
+	(&TestRun{}).sendLogLine("ignored")
+}
+
+func TestTestService_RunStreamingCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	service := createTestService()
+	run := &TestRun{ID: "run-1", LogChannel: make(chan string, 10)}
+
+	cmd := exec.Command("sh", "-c", "echo one; echo two 1>&2; printf three")
+	output, err := service.runStreamingCommand(run, cmd)
+	require.NoError(t, err)
+
+	close(run.LogChannel)
+	var lines []string
+	for line := range run.LogChannel {
+		lines = append(lines, line)
+	}
+	assert.ElementsMatch(t, []string{"one", "two", "three"}, lines)
+	assert.Contains(t, string(output), "one\n")
+	assert.Contains(t, string(output), "two\n")
+	assert.Contains(t, string(output), "three")
+
+	// A failing command still returns its output
+	cmd = exec.Command("sh", "-c", "echo broken; exit 3")
+	output, err = service.runStreamingCommand(&TestRun{ID: "run-2"}, cmd)
+	assert.Error(t, err)
+	assert.Equal(t, "broken\n", string(output))
+}
+
+func TestTestService_StreamsLogLines(t *testing.T) {
+	cfg := &config.Config{CypressBaseURL: "http://localhost:3000", EnableWSCorrelationID: true}
+
+	var messagesMu sync.Mutex
+	var messages []map[string]interface{}
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		data := args.Get(1).(map[string]interface{})
+		data["type"] = args.String(0)
+		messagesMu.Lock()
+		messages = append(messages, data)
+		messagesMu.Unlock()
+	}).Return()
+
+	service := NewTestService(cfg, mockHub)
+	service.runExecutor = func(run *TestRun) error {
+		run.sendLogLine("Running: login.cy.js")
+		run.sendLogLine("  ✓ logs in")
+		return nil
+	}
+
+	ctx := utils.ContextWithTraceID(context.Background(), "trace-123")
+	response, err := service.StartTestRun(ctx, &models.TestRunRequest{Framework: "cypress", Environment: "development"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	var sequence []string
+	for _, message := range messages {
+		if message["type"] == "test_log_line" {
+			assert.Equal(t, response.RunID, message["run_id"])
+			assert.Equal(t, "trace-123", message["trace_id"])
+			assert.Equal(t, int64(0), message["dropped_lines"])
+			sequence = append(sequence, message["line"].(string))
+		} else {
+			sequence = append(sequence, "status:"+message["status"].(string))
+		}
+	}
+
+	// Output lines arrive after the run starts and before its final status
+	assert.Equal(t, []string{
+		"status:queued",
+		"status:running",
+		"Running: login.cy.js",
+		"  ✓ logs in",
+		"status:completed",
+	}, sequence)
+}
+
+// topicHub records the messages sent to each topic, like a WebSocket hub with subscriptions
+type topicHub struct {
+	MockWebSocketHub
+	mu     sync.Mutex
+	topics map[string][]map[string]interface{}
+}
+
+func (h *topicHub) BroadcastToTopic(topic, msgType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topics[topic] = append(h.topics[topic], data.(map[string]interface{}))
+}
+
+func (h *topicHub) sentTo(topic string) []map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]map[string]interface{}(nil), h.topics[topic]...)
+}
+
+func TestTestService_StreamsLogLinesToTopic(t *testing.T) {
+	hub := &topicHub{topics: make(map[string][]map[string]interface{})}
+	hub.On("BroadcastToAll", "test_progress", mock.Anything).Return()
+
+	service := NewTestService(&config.Config{}, hub)
+	service.runExecutor = func(run *TestRun) error {
+		run.sendLogLine("PASS src/sum.test.js")
+		return nil
+	}
+
+	response, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", Environment: "development"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	lines := hub.sentTo(TestRunLogTopic(response.RunID))
+	require.Len(t, lines, 1)
+	assert.Equal(t, "PASS src/sum.test.js", lines[0]["line"])
+	hub.AssertNotCalled(t, "BroadcastToAll", "test_log_line", mock.Anything)
+}
+
+func TestTestService_CanSubscribe(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	release := make(chan struct{})
+	service.runExecutor = func(run *TestRun) error {
+		<-release
+		return nil
+	}
+	req := &models.TestRunRequest{Framework: "jest", Environment: "development"}
+
+	owned, err := service.StartTestRun(utils.ContextWithUserID(context.Background(), "alice"), req)
+	require.NoError(t, err)
+	unowned, err := service.StartTestRun(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.True(t, service.CanSubscribe("alice", TestRunLogTopic(owned.RunID)))
+	assert.False(t, service.CanSubscribe("bob", TestRunLogTopic(owned.RunID)), "only the owner may stream a run's output")
+	assert.True(t, service.CanSubscribe("bob", TestRunLogTopic(unowned.RunID)), "runs without an owner are open")
+	assert.False(t, service.CanSubscribe("alice", TestRunLogTopic("unknown-run")))
+	assert.True(t, service.CanSubscribe("bob", "other-topic"))
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, service.CanSubscribe("alice", TestRunLogTopic(owned.RunID)), "finished runs stream nothing")
+}
+
+func TestTestService_LogStreamRate(t *testing.T) {
+	hub := &topicHub{topics: make(map[string][]map[string]interface{})}
+	hub.On("BroadcastToAll", "test_progress", mock.Anything).Return()
+
+	const produced = 30
+	service := NewTestService(&config.Config{}, hub, TestServiceConfig{LogStreamRate: 10, LogStreamBuffer: 5})
+	service.runExecutor = func(run *TestRun) error {
+		for i := 0; i < produced; i++ {
+			run.sendLogLine(fmt.Sprintf("line %d", i))
+		}
+		return nil
+	}
+
+	response, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", Environment: "development"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A burst beyond the rate fills the buffer, and the lines after that are dropped
+	lines := hub.sentTo(TestRunLogTopic(response.RunID))
+	require.NotEmpty(t, lines)
+	dropped := lines[len(lines)-1]["dropped_lines"].(int64)
+	assert.Positive(t, dropped)
+	assert.Equal(t, produced, len(lines)+int(dropped))
+}
+
+func TestTestService_RunTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		multiplier int
+		request    *models.TestRunRequest
+		expected   time.Duration
+	}{
+		{name: "explicit timeout", multiplier: 3, request: &models.TestRunRequest{Framework: "cypress", TimeoutSeconds: 90}, expected: 90 * time.Second},
+		{name: "configured multiplier", multiplier: 2, request: &models.TestRunRequest{Framework: "cypress"}, expected: 10 * time.Minute},
+		{name: "default multiplier", multiplier: 0, request: &models.TestRunRequest{Framework: "jest"}, expected: 6 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTestService(&config.Config{TestRunTimeoutMultiplier: tt.multiplier}, nil)
+			assert.Equal(t, tt.expected, service.runTimeout(tt.request))
+		})
+	}
+}
+
+func TestTestService_RunTimesOut(t *testing.T) {
+	var statusesMu sync.Mutex
+	var statuses []string
+	mockHub := &MockWebSocketHub{}
+	mockHub.On("BroadcastToAll", "test_progress", mock.Anything).Run(func(args mock.Arguments) {
+		statusesMu.Lock()
+		statuses = append(statuses, args.Get(1).(map[string]interface{})["status"].(string))
+		statusesMu.Unlock()
+	}).Return()
+
+	service := NewTestService(&config.Config{}, mockHub)
+	// Simulate a hung framework that only stops when its context is cancelled
+	service.runExecutor = func(run *TestRun) error {
+		<-run.Context.Done()
+		return run.Context.Err()
+	}
+
+	response, err := service.StartTestRun(context.Background(), &models.TestRunRequest{
+		Framework: "cypress", Environment: "development", TimeoutSeconds: 1,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(service.GetActiveRuns()) == 0
+	}, 3*time.Second, 20*time.Millisecond)
+
+	result, err := service.GetTestResults(response.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, models.TestRunReasonTimeout, result.Reason)
+
+	statusesMu.Lock()
+	defer statusesMu.Unlock()
+	assert.Equal(t, []string{"queued", "running", "timeout"}, statuses)
+}
+
 func TestTestService_ValidateSync(t *testing.T) {
 	service := createTestService()
 	ctx := context.Background()
@@ -263,6 +791,198 @@ func TestTestService_ValidateSync(t *testing.T) {
 	}
 }
 
+func TestTestService_ValidateSync_AssertionTimeout(t *testing.T) {
+	service := createTestService()
+
+	// The first assertion hangs until its context is cancelled; the second returns immediately
+	service.assertionRunner = func(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion) (*models.SyncAssertionResult, error) {
+		if assertion.Type == "timing_match" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return service.executeAssertion(ctx, req, assertion)
+	}
+
+	req := &models.TestSyncValidationRequest{
+		APIEndpoint: "http://localhost:8080/api/test",
+		UIComponent: "TestComponent",
+		Assertions: []models.SyncAssertion{
+			{Type: "timing_match", Field: "response.time", Expected: 100, Operator: "less_than"},
+			{Type: "status_match", Field: "response.status", Expected: 200, Operator: "equals"},
+		},
+		Config: map[string]string{"timeout": "50ms"},
+	}
+
+	start := time.Now()
+	response, err := service.ValidateSync(context.Background(), req)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.Less(t, elapsed, time.Second)
+	assert.False(t, response.IsValid)
+	require.Len(t, response.Results, 2)
+
+	timedOut := response.Results[0]
+	assert.False(t, timedOut.Passed)
+	assert.Equal(t, models.SyncAssertionReasonTimeout, timedOut.Reason)
+	assert.Contains(t, timedOut.Message, "50ms")
+
+	assert.True(t, response.Results[1].Passed)
+	assert.Empty(t, response.Results[1].Reason)
+
+	require.Len(t, response.Issues, 1)
+	assert.Equal(t, "assertion_failed", response.Issues[0].Type)
+	assert.Contains(t, response.Issues[0].Suggestion, "timeout")
+}
+
+func TestTestService_ValidateSync_ConcurrentAssertions(t *testing.T) {
+	service := NewTestService(&config.Config{SyncAssertionWorkers: 3}, nil)
+
+	var (
+		mu               sync.Mutex
+		inFlight, peak   int
+		assertionDelay   = 20 * time.Millisecond
+		assertionsToSend = 9
+	)
+	// Later assertions finish sooner, so completion order differs from request order. Every
+	// third assertion fails, and the last one can't be executed at all.
+	service.assertionRunner = func(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion) (*models.SyncAssertionResult, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		index := assertion.Expected.(int)
+		time.Sleep(time.Duration(assertionsToSend-index) * assertionDelay / 3)
+		if index == assertionsToSend-1 {
+			return nil, fmt.Errorf("endpoint unreachable")
+		}
+		return &models.SyncAssertionResult{
+			Assertion: assertion,
+			Passed:    index%3 != 0,
+			Message:   fmt.Sprintf("assertion %d", index),
+		}, nil
+	}
+
+	req := &models.TestSyncValidationRequest{
+		APIEndpoint: "http://localhost:8080/api/test",
+		UIComponent: "TestComponent",
+		Config:      map[string]string{"timeout": "5s"},
+	}
+	for i := 0; i < assertionsToSend; i++ {
+		req.Assertions = append(req.Assertions, models.SyncAssertion{Type: "data_match", Field: "id", Expected: i, Operator: "equals"})
+	}
+
+	start := time.Now()
+	response, err := service.ValidateSync(context.Background(), req)
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, peak)
+	assert.Less(t, elapsed, time.Duration(assertionsToSend*(assertionsToSend+1)/2)*assertionDelay/3, "assertions ran one at a time")
+
+	assert.False(t, response.IsValid)
+	require.Len(t, response.Results, assertionsToSend-1)
+	for i, result := range response.Results {
+		assert.Equal(t, fmt.Sprintf("assertion %d", i), result.Message)
+	}
+
+	var issues []string
+	for _, issue := range response.Issues {
+		issues = append(issues, issue.Type+": "+issue.Description)
+	}
+	assert.Equal(t, []string{
+		"assertion_failed: assertion 0",
+		"assertion_failed: assertion 3",
+		"assertion_failed: assertion 6",
+		"assertion_error: Failed to execute assertion: endpoint unreachable",
+	}, issues)
+}
+
+func TestTestService_ValidateSync_ConcurrencyLimit(t *testing.T) {
+	limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1})
+	limiter.RegisterEnvironment("staging", "http://web.staging.test", "http://api.staging.test")
+	service := NewTestService(createTestService().config, nil, TestServiceConfig{ValidationLimiter: limiter})
+
+	req := &models.TestSyncValidationRequest{
+		APIEndpoint: "http://api.staging.test/users",
+		UIComponent: "UserList",
+		Assertions:  []models.SyncAssertion{{Type: "status_match", Field: "response.status", Expected: 200}},
+	}
+
+	// An endpoint validation against the environment's frontend holds its only slot
+	release, err := limiter.Acquire(context.Background(), "http://web.staging.test/users")
+	require.NoError(t, err)
+
+	_, err = service.ValidateSync(context.Background(), req)
+	assert.ErrorIs(t, err, ErrValidationLimitReached)
+
+	release()
+	response, err := service.ValidateSync(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, response.IsValid)
+}
+
+func TestTestService_AssertionTimeout(t *testing.T) {
+	t.Run("request config overrides service default", func(t *testing.T) {
+		service := NewTestService(&config.Config{SyncAssertionTimeout: 30}, nil)
+		timeout, err := service.assertionTimeout(&models.TestSyncValidationRequest{
+			Config: map[string]string{"timeout": "2s"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, timeout)
+	})
+
+	t.Run("configured default", func(t *testing.T) {
+		service := NewTestService(&config.Config{SyncAssertionTimeout: 30}, nil)
+		timeout, err := service.assertionTimeout(&models.TestSyncValidationRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, timeout)
+	})
+
+	t.Run("built-in default without config", func(t *testing.T) {
+		service := NewTestService(nil, nil)
+		timeout, err := service.assertionTimeout(&models.TestSyncValidationRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, defaultAssertionTimeout, timeout)
+	})
+
+	t.Run("invalid request timeout", func(t *testing.T) {
+		service := createTestService()
+		_, err := service.ValidateSync(context.Background(), &models.TestSyncValidationRequest{
+			Config: map[string]string{"timeout": "-5s"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTestService_ValidateSync_Cancelled(t *testing.T) {
+	service := createTestService()
+	service.assertionRunner = func(ctx context.Context, req *models.TestSyncValidationRequest, assertion models.SyncAssertion) (*models.SyncAssertionResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response, err := service.ValidateSync(ctx, &models.TestSyncValidationRequest{
+		Assertions: []models.SyncAssertion{{Type: "data_match", Operator: "equals"}},
+		Config:     map[string]string{"timeout": "5s"},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, response.IsValid)
+	assert.Empty(t, response.Results)
+	require.Len(t, response.Issues, 1)
+	assert.Equal(t, "assertion_error", response.Issues[0].Type)
+}
+
 func TestTestService_GetActiveRuns(t *testing.T) {
 	service := createTestService()
 	ctx := context.Background()
@@ -464,6 +1184,242 @@ func TestTestService_GetStatus(t *testing.T) {
 	assert.Contains(t, frameworks, "playwright")
 	assert.Contains(t, frameworks, "jest")
 	assert.Contains(t, frameworks, "vitest")
+	assert.Contains(t, frameworks, "mocha")
+	assert.Contains(t, frameworks, "pytest")
+	assert.Contains(t, frameworks, "go")
+}
+
+func TestTestService_GetFrameworkVersions(t *testing.T) {
+	service := createTestService()
+
+	calls := 0
+	service.versionDetector = func(ctx context.Context, framework string) (string, error) {
+		calls++
+		if framework == "jest" {
+			return "29.7.0", nil
+		}
+		return "", fmt.Errorf("%s is not installed", framework)
+	}
+
+	count := len(models.SupportedFrameworks)
+	frameworks := service.GetFrameworkVersions(context.Background(), false)
+	assert.Len(t, frameworks, count)
+	assert.Equal(t, count, calls)
+
+	for _, fw := range frameworks {
+		if fw.Name == "jest" {
+			assert.True(t, fw.Installed)
+			assert.Equal(t, "29.7.0", fw.Version)
+			assert.Empty(t, fw.Error)
+		} else {
+			assert.False(t, fw.Installed)
+			assert.Empty(t, fw.Version)
+			assert.NotEmpty(t, fw.Error)
+		}
+	}
+
+	// Second call should be served from cache
+	service.GetFrameworkVersions(context.Background(), false)
+	assert.Equal(t, count, calls)
+
+	// Refresh bypasses the cache
+	service.GetFrameworkVersions(context.Background(), true)
+	assert.Equal(t, 2*count, calls)
+
+	// Expired cache triggers detection again
+	service.frameworkCacheTTL = 0
+	service.GetFrameworkVersions(context.Background(), false)
+	assert.Equal(t, 3*count, calls)
+}
+
+func TestTestService_DetectFrameworkVersion(t *testing.T) {
+	service := createTestService()
+
+	tmpDir := t.TempDir()
+	pkgDir := filepath.Join(tmpDir, "node_modules", "@playwright", "test")
+	assert.NoError(t, os.MkdirAll(pkgDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgDir, "package.json"), []byte(`{"name":"@playwright/test","version":"1.40.1"}`), 0644))
+
+	nestedDir := filepath.Join(tmpDir, "e2e")
+	assert.NoError(t, os.MkdirAll(nestedDir, 0755))
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(nestedDir))
+	defer os.Chdir(originalDir)
+
+	version, err := service.detectFrameworkVersion(context.Background(), "playwright")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.40.1", version)
+
+	_, err = service.detectFrameworkVersion(context.Background(), "vitest")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not installed")
+}
+
+func TestTestService_DetectFrameworkVersion_Command(t *testing.T) {
+	service := createTestService()
+
+	// The go toolchain running these tests reports its own version
+	version, err := service.detectFrameworkVersion(context.Background(), "go")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d+\.\d+`, version)
+
+	_, err = detectCommandVersion(context.Background(), "missing", []string{"definitely-not-a-test-framework", "--version"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not installed")
+}
+
+func TestTestService_CleanupWorkDir(t *testing.T) {
+	setup := func(t *testing.T) string {
+		workDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, "cypress", "videos"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "cypress", "videos", "run.mp4"), []byte("video"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "scratch.tmp"), []byte("tmp"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "package.json"), []byte("{}"), 0644))
+		return workDir
+	}
+
+	newRun := func(workDir string, skip bool) *TestRun {
+		return &TestRun{
+			ID: "run-1",
+			Request: &models.TestRunRequest{
+				Framework:   "cypress",
+				Config:      map[string]string{"workDir": workDir},
+				SkipCleanup: skip,
+			},
+		}
+	}
+
+	t.Run("removes configured patterns", func(t *testing.T) {
+		workDir := setup(t)
+		service := createTestService()
+		service.config.EnableTestCleanup = true
+		service.config.TestWorkDirRoot = filepath.Dir(workDir)
+		service.config.TestCleanupPatterns = []string{"cypress/videos", "*.tmp"}
+
+		service.cleanupWorkDir(newRun(workDir, false))
+
+		assert.NoDirExists(t, filepath.Join(workDir, "cypress", "videos"))
+		assert.NoFileExists(t, filepath.Join(workDir, "scratch.tmp"))
+		assert.FileExists(t, filepath.Join(workDir, "package.json"))
+		assert.DirExists(t, filepath.Join(workDir, "cypress"))
+	})
+
+	t.Run("request opt-out keeps artifacts", func(t *testing.T) {
+		workDir := setup(t)
+		service := createTestService()
+		service.config.EnableTestCleanup = true
+		service.config.TestWorkDirRoot = workDir
+		service.config.TestCleanupPatterns = []string{"*.tmp"}
+
+		service.cleanupWorkDir(newRun(workDir, true))
+
+		assert.FileExists(t, filepath.Join(workDir, "scratch.tmp"))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		workDir := setup(t)
+		service := createTestService()
+		service.config.TestWorkDirRoot = workDir
+		service.config.TestCleanupPatterns = []string{"*.tmp"}
+
+		service.cleanupWorkDir(newRun(workDir, false))
+
+		assert.FileExists(t, filepath.Join(workDir, "scratch.tmp"))
+	})
+
+	t.Run("never without a workDir root", func(t *testing.T) {
+		workDir := setup(t)
+		service := createTestService()
+		service.config.EnableTestCleanup = true
+		service.config.TestCleanupPatterns = []string{"*.tmp"}
+
+		service.cleanupWorkDir(newRun(workDir, false))
+
+		assert.FileExists(t, filepath.Join(workDir, "scratch.tmp"))
+	})
+
+	t.Run("never outside the workDir root", func(t *testing.T) {
+		workDir := setup(t)
+		service := createTestService()
+		service.config.EnableTestCleanup = true
+		service.config.TestWorkDirRoot = t.TempDir()
+		service.config.TestCleanupPatterns = []string{"*.tmp"}
+
+		service.cleanupWorkDir(newRun(workDir, false))
+		assert.FileExists(t, filepath.Join(workDir, "scratch.tmp"))
+
+		// Nor through a symlink inside the root pointing out of it
+		link := filepath.Join(service.config.TestWorkDirRoot, "project")
+		require.NoError(t, os.Symlink(workDir, link))
+		service.cleanupWorkDir(newRun(link, false))
+		assert.FileExists(t, filepath.Join(workDir, "scratch.tmp"))
+	})
+}
+
+func TestTestService_StartTestRun_WorkDirRoot(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "e2e")
+	require.NoError(t, os.MkdirAll(inside, 0755))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	service := NewTestService(&config.Config{TestWorkDirRoot: root}, nil)
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	start := func(workDir string) error {
+		_, err := service.StartTestRun(context.Background(), &models.TestRunRequest{
+			Framework:   "cypress",
+			Environment: "staging",
+			Config:      map[string]string{"workDir": workDir},
+		})
+		return err
+	}
+
+	assert.NoError(t, start(inside))
+	assert.NoError(t, start(root))
+	for _, workDir := range []string{outside, filepath.Join(root, "escape"), filepath.Join(inside, "..", ".."), "/", filepath.Join(root, "missing")} {
+		assert.ErrorIs(t, start(workDir), ErrInvalidTestConfig, workDir)
+	}
+}
+
+func TestResolveCleanupTargets_StaysInsideWorkDir(t *testing.T) {
+	base := t.TempDir()
+	workDir := filepath.Join(base, "project")
+	outside := filepath.Join(base, "outside")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	require.NoError(t, os.MkdirAll(outside, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "keep.tmp"), []byte("keep"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "remove.tmp"), []byte("remove"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(workDir, "linked")))
+
+	targets, err := resolveCleanupTargets(workDir, []string{"../outside/*.tmp", "linked/*.tmp", "*.tmp", "/etc", "."})
+	require.NoError(t, err)
+
+	resolvedWorkDir, err := filepath.EvalSymlinks(workDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(resolvedWorkDir, "remove.tmp")}, targets)
+
+	_, err = resolveCleanupTargets("/", []string{"*"})
+	assert.Error(t, err)
+}
+
+func TestParseFrameworkVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"13.6.0", "13.6.0"},
+		{"Version 1.40.1\n", "1.40.1"},
+		{"v1.0.0-beta.3", "1.0.0-beta.3"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseFrameworkVersion(tt.input))
+		})
+	}
 }
 
 func TestTestService_ParseSimpleTestOutput(t *testing.T) {
@@ -491,6 +1447,328 @@ func TestTestService_ParseSimpleTestOutput(t *testing.T) {
 	assert.Equal(t, 1, run.Results.FailedTests)
 }
 
+func TestTestService_ParseMochaReport(t *testing.T) {
+	service := createTestService()
+
+	report := `{
+		"stats": {"suites": 2, "tests": 3, "passes": 1, "pending": 1, "failures": 2, "duration": 1520},
+		"tests": [
+			{"title": "logs in", "fullTitle": "Auth logs in", "duration": 1200, "err": {}},
+			{"title": "logs out", "fullTitle": "Auth logs out", "duration": 320, "err": {"message": "expected button to be visible", "stack": "AssertionError: expected button to be visible\n    at Context.eval"}},
+			{"title": "resets password", "fullTitle": "Auth resets password", "err": {}}
+		],
+		"pending": [
+			{"title": "resets password", "fullTitle": "Auth resets password", "err": {}}
+		],
+		"failures": [
+			{"title": "logs out", "fullTitle": "Auth logs out", "duration": 320, "err": {"message": "expected button to be visible"}},
+			{"title": "\"before all\" hook", "fullTitle": "Checkout \"before all\" hook", "err": {"message": "cy.visit() failed"}}
+		],
+		"passes": [
+			{"title": "logs in", "fullTitle": "Auth logs in", "duration": 1200, "err": {}}
+		]
+	}`
+
+	tests := []struct {
+		name   string
+		report string
+		output string
+	}{
+		{name: "report file", report: report, output: "Running: auth.cy.js"},
+		{name: "report in console output", output: "Running: auth.cy.js\n" + report + "\n  (Run Finished)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+
+			err := service.parseMochaReport(run, []byte(tt.report), tt.output)
+			require.NoError(t, err)
+
+			assert.Equal(t, 4, run.Results.TotalTests)
+			assert.Equal(t, 1, run.Results.PassedTests)
+			assert.Equal(t, 2, run.Results.FailedTests)
+			assert.Equal(t, 1, run.Results.SkippedTests)
+			require.Len(t, run.Results.Results, 4)
+
+			passed := run.Results.Results[0]
+			assert.Equal(t, "Auth logs in", passed.Name)
+			assert.Equal(t, "passed", passed.Status)
+			assert.Equal(t, 1200*time.Millisecond, passed.Duration)
+
+			failed := run.Results.Results[1]
+			assert.Equal(t, "failed", failed.Status)
+			assert.Equal(t, "expected button to be visible", failed.ErrorMsg)
+			assert.Contains(t, failed.StackTrace, "Context.eval")
+
+			assert.Equal(t, "skipped", run.Results.Results[2].Status)
+
+			hook := run.Results.Results[3]
+			assert.Equal(t, `Checkout "before all" hook`, hook.Name)
+			assert.Equal(t, "failed", hook.Status)
+			assert.Equal(t, "cy.visit() failed", hook.ErrorMsg)
+		})
+	}
+}
+
+func TestTestService_ParseMochaReport_Invalid(t *testing.T) {
+	service := createTestService()
+
+	tests := []struct {
+		name   string
+		report string
+		output string
+	}{
+		{name: "no report", output: "  3 passing\n  1 failing\n"},
+		{name: "truncated report", report: `{"stats": {"tests": 1}, "tests": [`},
+		{name: "truncated console report", output: `{"stats": {"tests": 1}, "tests": [`},
+		{name: "not a mocha report", report: `{"results": []}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+
+			err := service.parseMochaReport(run, []byte(tt.report), tt.output)
+			assert.Error(t, err)
+			assert.Equal(t, 0, run.Results.TotalTests)
+			assert.Empty(t, run.Results.Results)
+		})
+	}
+}
+
+func TestTestService_ParseVitestResults(t *testing.T) {
+	service := createTestService()
+
+	report := `{
+		"numTotalTestSuites": 3, "numPassedTestSuites": 1, "numFailedTestSuites": 2,
+		"numTotalTests": 4, "numPassedTests": 1, "numFailedTests": 1, "numPendingTests": 1, "numTodoTests": 1,
+		"success": false,
+		"testResults": [
+			{
+				"name": "/srv/app/src/cart.test.ts",
+				"status": "failed",
+				"message": "",
+				"assertionResults": [
+					{"ancestorTitles": ["Cart"], "fullName": "Cart adds items", "title": "adds items", "status": "passed", "duration": 12.5, "failureMessages": []},
+					{"ancestorTitles": ["Cart"], "fullName": "Cart removes items", "title": "removes items", "status": "failed", "duration": 3,
+						"failureMessages": ["AssertionError: expected 2 to be 1\n    at src/cart.test.ts:14:22"]},
+					{"ancestorTitles": ["Cart"], "fullName": "Cart applies coupons", "title": "applies coupons", "status": "skipped", "failureMessages": []},
+					{"ancestorTitles": [], "fullName": "checkout", "title": "checkout", "status": "todo", "failureMessages": []}
+				]
+			},
+			{
+				"name": "/srv/app/src/broken.test.ts",
+				"status": "failed",
+				"message": "SyntaxError: Unexpected token '}'\n    at src/broken.test.ts:3:1",
+				"assertionResults": []
+			}
+		]
+	}`
+
+	tests := []struct {
+		name   string
+		report string
+		output string
+	}{
+		{name: "report file", report: report, output: " RUN  v1.6.0 /srv/app"},
+		{name: "report in console output", output: " RUN  v1.6.0 /srv/app\n" + report + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &TestRun{
+				Request: &models.TestRunRequest{Config: map[string]string{"workDir": "/srv/app"}},
+				Results: &models.TestResults{Results: make([]models.TestCase, 0)},
+			}
+
+			err := service.parseVitestResults(run, []byte(tt.report), tt.output)
+			require.NoError(t, err)
+
+			assert.Equal(t, 5, run.Results.TotalTests)
+			assert.Equal(t, 1, run.Results.PassedTests)
+			assert.Equal(t, 2, run.Results.FailedTests)
+			assert.Equal(t, 2, run.Results.SkippedTests)
+			require.Len(t, run.Results.Results, 5)
+
+			passed := run.Results.Results[0]
+			assert.Equal(t, "src/cart.test.ts > Cart adds items", passed.Name)
+			assert.Equal(t, "passed", passed.Status)
+			assert.Equal(t, 12500*time.Microsecond, passed.Duration)
+
+			failed := run.Results.Results[1]
+			assert.Equal(t, "failed", failed.Status)
+			assert.Equal(t, "AssertionError: expected 2 to be 1", failed.ErrorMsg)
+			assert.Contains(t, failed.StackTrace, "src/cart.test.ts:14:22")
+
+			assert.Equal(t, "skipped", run.Results.Results[2].Status)
+			assert.Equal(t, "skipped", run.Results.Results[3].Status)
+
+			broken := run.Results.Results[4]
+			assert.Equal(t, "src/broken.test.ts", broken.Name)
+			assert.Equal(t, "failed", broken.Status)
+			assert.Equal(t, "SyntaxError: Unexpected token '}'", broken.ErrorMsg)
+		})
+	}
+}
+
+func TestTestService_ParseVitestResults_Fallback(t *testing.T) {
+	service := createTestService()
+
+	tests := []struct {
+		name   string
+		report string
+		output string
+	}{
+		{name: "no report", output: " ✓ src/cart.test.ts (2)\n ✗ src/checkout.test.ts (1)\n"},
+		{name: "truncated report", report: `{"numTotalTestSuites": 1, "testResults": [`, output: " ✓ src/cart.test.ts (2)\n ✗ src/checkout.test.ts (1)\n"},
+		{name: "not a vitest report", report: `{"results": []}`, output: " ✓ src/cart.test.ts (2)\n ✗ src/checkout.test.ts (1)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &TestRun{
+				Request: &models.TestRunRequest{},
+				Results: &models.TestResults{Results: make([]models.TestCase, 0)},
+			}
+
+			err := service.parseVitestResults(run, []byte(tt.report), tt.output)
+			require.NoError(t, err)
+
+			// Counted from the console output instead
+			assert.Equal(t, 2, run.Results.TotalTests)
+			assert.Equal(t, 1, run.Results.PassedTests)
+			assert.Equal(t, 1, run.Results.FailedTests)
+		})
+	}
+}
+
+func TestTestService_ParsePytestReport(t *testing.T) {
+	service := createTestService()
+
+	report := `{
+		"created": 1705312200.0,
+		"exitcode": 1,
+		"summary": {"passed": 2, "failed": 1, "skipped": 1, "xfailed": 1, "error": 1, "total": 6, "collected": 6},
+		"tests": [
+			{"nodeid": "tests/test_api.py::test_health", "outcome": "passed",
+				"setup": {"duration": 0.001, "outcome": "passed"},
+				"call": {"duration": 0.25, "outcome": "passed"},
+				"teardown": {"duration": 0.001, "outcome": "passed"}},
+			{"nodeid": "tests/test_api.py::test_login", "outcome": "failed",
+				"setup": {"duration": 0.001, "outcome": "passed"},
+				"call": {"duration": 0.5, "outcome": "failed", "crash": {"path": "tests/test_api.py", "lineno": 12, "message": "AssertionError: assert 401 == 200"}, "longrepr": "def test_login():\n>       assert resp.status == 200\nE       AssertionError: assert 401 == 200"},
+				"teardown": {"duration": 0.001, "outcome": "passed"}},
+			{"nodeid": "tests/test_api.py::test_legacy", "outcome": "skipped",
+				"setup": {"duration": 0.0, "outcome": "skipped", "longrepr": "skipped: legacy API removed"}},
+			{"nodeid": "tests/test_api.py::test_known_bug", "outcome": "xfailed",
+				"call": {"duration": 0.1, "outcome": "skipped"}},
+			{"nodeid": "tests/test_api.py::test_flaky_fixed", "outcome": "xpassed",
+				"call": {"duration": 0.1, "outcome": "passed"}},
+			{"nodeid": "tests/test_db.py::test_migrations", "outcome": "error",
+				"setup": {"duration": 0.01, "outcome": "failed", "longrepr": "fixture 'database' not found"}}
+		]
+	}`
+
+	run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+	require.NoError(t, service.parsePytestReport(run, []byte(report)))
+
+	assert.Equal(t, 6, run.Results.TotalTests)
+	assert.Equal(t, 2, run.Results.PassedTests)
+	assert.Equal(t, 2, run.Results.FailedTests)
+	assert.Equal(t, 2, run.Results.SkippedTests)
+	require.Len(t, run.Results.Results, 6)
+
+	passed := run.Results.Results[0]
+	assert.Equal(t, "tests/test_api.py::test_health", passed.Name)
+	assert.Equal(t, "passed", passed.Status)
+	assert.Equal(t, 252*time.Millisecond, passed.Duration.Round(time.Millisecond))
+
+	failed := run.Results.Results[1]
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "AssertionError: assert 401 == 200", failed.ErrorMsg)
+	assert.Contains(t, failed.StackTrace, "assert resp.status == 200")
+
+	assert.Equal(t, "skipped", run.Results.Results[2].Status)
+	assert.Equal(t, "skipped", run.Results.Results[3].Status)
+	assert.Equal(t, "passed", run.Results.Results[4].Status)
+
+	setupError := run.Results.Results[5]
+	assert.Equal(t, "failed", setupError.Status)
+	assert.Equal(t, "fixture 'database' not found", setupError.ErrorMsg)
+
+	for _, invalid := range []string{"", `{"tests": [`, `{"tests": []}`} {
+		run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+		assert.Error(t, service.parsePytestReport(run, []byte(invalid)), "report %q", invalid)
+		assert.Empty(t, run.Results.Results)
+	}
+}
+
+func TestTestService_ParseGoTestOutput(t *testing.T) {
+	service := createTestService()
+
+	output := strings.Join([]string{
+		`{"Action":"start","Package":"example.com/api"}`,
+		`{"Action":"run","Package":"example.com/api","Test":"TestHealth"}`,
+		`{"Action":"output","Package":"example.com/api","Test":"TestHealth","Output":"=== RUN   TestHealth\n"}`,
+		`{"Action":"pass","Package":"example.com/api","Test":"TestHealth","Elapsed":0.25}`,
+		`{"Action":"run","Package":"example.com/api","Test":"TestLogin"}`,
+		`{"Action":"output","Package":"example.com/api","Test":"TestLogin","Output":"=== RUN   TestLogin\n"}`,
+		`{"Action":"output","Package":"example.com/api","Test":"TestLogin","Output":"    api_test.go:42: expected 200, got 401\n"}`,
+		`{"Action":"fail","Package":"example.com/api","Test":"TestLogin","Elapsed":0.5}`,
+		`{"Action":"run","Package":"example.com/api","Test":"TestLegacy"}`,
+		`{"Action":"skip","Package":"example.com/api","Test":"TestLegacy","Elapsed":0}`,
+		`{"Action":"fail","Package":"example.com/api","Elapsed":0.8}`,
+		`# example.com/broken`,
+		`broken/broken.go:3:1: syntax error: non-declaration statement outside function body`,
+		`{"Action":"output","Package":"example.com/broken","Output":"FAIL\texample.com/broken [build failed]\n"}`,
+		`{"Action":"fail","Package":"example.com/broken","Elapsed":0}`,
+	}, "\n")
+
+	run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+	require.NoError(t, service.parseGoTestOutput(run, output))
+
+	assert.Equal(t, 4, run.Results.TotalTests)
+	assert.Equal(t, 1, run.Results.PassedTests)
+	assert.Equal(t, 2, run.Results.FailedTests)
+	assert.Equal(t, 1, run.Results.SkippedTests)
+	require.Len(t, run.Results.Results, 4)
+
+	passed := run.Results.Results[0]
+	assert.Equal(t, "example.com/api.TestHealth", passed.Name)
+	assert.Equal(t, "passed", passed.Status)
+	assert.Equal(t, 250*time.Millisecond, passed.Duration)
+
+	failed := run.Results.Results[1]
+	assert.Equal(t, "failed", failed.Status)
+	assert.Contains(t, failed.ErrorMsg, "expected 200, got 401")
+
+	assert.Equal(t, "skipped", run.Results.Results[2].Status)
+
+	// A package that fails without a failing test, such as a build failure, is reported on its own
+	build := run.Results.Results[3]
+	assert.Equal(t, "example.com/broken", build.Name)
+	assert.Equal(t, "failed", build.Status)
+	assert.Contains(t, build.ErrorMsg, "build failed")
+
+	t.Run("unfinished test counts as failed", func(t *testing.T) {
+		run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+		output := `{"Action":"run","Package":"example.com/api","Test":"TestHangs"}
+{"Action":"output","Package":"example.com/api","Test":"TestHangs","Output":"panic: test timed out after 10m0s\n"}`
+
+		require.NoError(t, service.parseGoTestOutput(run, output))
+		require.Len(t, run.Results.Results, 1)
+		assert.Equal(t, "failed", run.Results.Results[0].Status)
+		assert.Contains(t, run.Results.Results[0].ErrorMsg, "timed out")
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		run := &TestRun{Results: &models.TestResults{Results: make([]models.TestCase, 0)}}
+		assert.Error(t, service.parseGoTestOutput(run, "go: command not found\n"))
+		assert.Empty(t, run.Results.Results)
+	})
+}
+
 func TestTestService_MoveToHistory(t *testing.T) {
 	service := createTestService()
 
@@ -560,6 +1838,142 @@ func TestTestService_MoveToHistory_MaxHistoryLimit(t *testing.T) {
 	assert.Equal(t, "new-run", service.runHistory[1].RunID)
 }
 
+// fakeHistoryStore is an in-memory HistoryStore for testing persistence
+type fakeHistoryStore struct {
+	mu    sync.Mutex
+	saved []models.TestResults
+}
+
+func (f *fakeHistoryStore) Save(result models.TestResults) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saved = append(f.saved, result)
+	return nil
+}
+
+func (f *fakeHistoryStore) Load(runID string) (*models.TestResults, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, result := range f.saved {
+		if result.RunID == runID {
+			r := result
+			return &r, nil
+		}
+	}
+	return nil, ErrRunNotInHistory
+}
+
+func (f *fakeHistoryStore) List(limit int) ([]models.TestResults, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	start := 0
+	if limit > 0 && len(f.saved) > limit {
+		start = len(f.saved) - limit
+	}
+	return append([]models.TestResults(nil), f.saved[start:]...), nil
+}
+
+// blockingHistoryStore signals saving and waits for release on each Save
+type blockingHistoryStore struct {
+	*fakeHistoryStore
+	saving  chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingHistoryStore) Save(result models.TestResults) error {
+	b.saving <- struct{}{}
+	<-b.release
+	return b.fakeHistoryStore.Save(result)
+}
+
+func TestTestService_HistoryStore(t *testing.T) {
+	t.Run("completed runs are persisted", func(t *testing.T) {
+		store := &fakeHistoryStore{}
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{HistoryStore: store})
+
+		run := &TestRun{ID: "run-1", Results: &models.TestResults{RunID: "run-1", Status: "completed"}}
+		service.activeRuns["run-1"] = run
+		service.moveToHistory(run)
+
+		require.Len(t, store.saved, 1)
+		assert.Equal(t, "run-1", store.saved[0].RunID)
+	})
+
+	t.Run("history is restored on startup", func(t *testing.T) {
+		store := &fakeHistoryStore{saved: []models.TestResults{
+			{RunID: "run-1", Status: "completed"},
+			{RunID: "run-2", Status: "failed"},
+		}}
+
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{HistoryStore: store})
+
+		history := service.GetRunHistory(10)
+		require.Len(t, history, 2)
+		assert.Equal(t, "run-2", history[1].RunID)
+
+		result, err := service.GetTestResults("run-1")
+		require.NoError(t, err)
+		assert.Equal(t, "completed", result.Status)
+	})
+
+	t.Run("runs aged out of memory are read from the store", func(t *testing.T) {
+		store := &fakeHistoryStore{}
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{HistoryStore: store})
+		service.maxHistory = 1
+
+		for _, id := range []string{"run-1", "run-2", "run-3"} {
+			run := &TestRun{ID: id, Results: &models.TestResults{RunID: id, Status: "completed"}}
+			service.activeRuns[id] = run
+			service.moveToHistory(run)
+		}
+
+		assert.Len(t, service.runHistory, 1)
+
+		result, err := service.GetTestResults("run-1")
+		require.NoError(t, err)
+		assert.Equal(t, "run-1", result.RunID)
+
+		history := service.GetRunHistory(3)
+		require.Len(t, history, 3)
+		assert.Equal(t, "run-1", history[0].RunID)
+		assert.Equal(t, "run-3", history[2].RunID)
+
+		_, err = service.GetTestResults("missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("saving doesn't hold the service lock", func(t *testing.T) {
+		store := &blockingHistoryStore{fakeHistoryStore: &fakeHistoryStore{}, saving: make(chan struct{}), release: make(chan struct{})}
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{HistoryStore: store})
+
+		run := &TestRun{ID: "run-1", Results: &models.TestResults{RunID: "run-1", Status: "completed"}, done: make(chan struct{})}
+		service.activeRuns["run-1"] = run
+		go service.moveToHistory(run)
+		<-store.saving
+
+		// The run is served from memory while it is being persisted, and waiters aren't released yet
+		result, err := service.GetTestResults("run-1")
+		require.NoError(t, err)
+		assert.Equal(t, "completed", result.Status)
+		assert.Equal(t, 1, service.GetStatus()["history_count"])
+		select {
+		case <-run.done:
+			t.Fatal("waiters were released before the run was persisted")
+		default:
+		}
+
+		close(store.release)
+		<-run.done
+		assert.Len(t, store.saved, 1)
+	})
+
+	t.Run("without a store history stays in memory", func(t *testing.T) {
+		service := createTestService()
+		assert.Nil(t, service.historyStore)
+		assert.Empty(t, service.GetRunHistory(10))
+	})
+}
+
 // Helper function to create a test service
 func createTestService() *TestService {
 	cfg := &config.Config{
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// ErrTestServiceShuttingDown is returned by StartTestRun once Shutdown has been called
+var ErrTestServiceShuttingDown = errors.New("test service is shutting down")
+
+// Shutdown stops the run reaper and watchdog, then cancels every queued and running test run,
+// killing running test processes so a server stopping mid-run doesn't leave them orphaned. It
+// waits until the cancelled runs are in history and pending result callbacks are delivered, or
+// returns an error once ctx is done. No run is started after Shutdown.
+func (s *TestService) Shutdown(ctx context.Context) error {
+	s.Stop()
+
+	s.mu.Lock()
+	s.shuttingDown = true
+	queued := s.queuedRuns
+	s.queuedRuns = nil
+
+	running := make([]*TestRun, 0, len(s.activeRuns))
+	for _, run := range s.activeRuns {
+		run.shutdown.Store(true)
+		run.Cancel()
+		if containsRun(queued, run) {
+			run.Status = "cancelled"
+			run.Results.Status = "cancelled"
+			run.Results.Reason = models.TestRunReasonShutdown
+			run.EndTime = time.Now()
+			run.Results.EndTime = run.EndTime
+			continue
+		}
+
+		if run.Process != nil && run.Process.Process != nil {
+			if err := run.Process.Process.Kill(); err != nil {
+				log.Printf("Error killing test process for run %s: %v", run.ID, err)
+			}
+		}
+		running = append(running, run)
+	}
+	s.mu.Unlock()
+
+	if len(queued)+len(running) > 0 {
+		log.Printf("Cancelling %d running and %d queued test runs for shutdown", len(running), len(queued))
+	}
+
+	// Queued runs have no process to wait for
+	for _, run := range queued {
+		s.broadcastTestUpdate(run.ID, "cancelled", "Queued test run cancelled by server shutdown")
+		s.moveToHistory(run)
+	}
+
+	for _, run := range running {
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			active := 0
+			for _, run := range running {
+				select {
+				case <-run.done:
+				default:
+					active++
+				}
+			}
+			return fmt.Errorf("%d test runs still active at shutdown: %w", active, ctx.Err())
+		}
+	}
+
+	callbacksDone := make(chan struct{})
+	go func() {
+		s.callbacks.Wait()
+		close(callbacksDone)
+	}()
+	select {
+	case <-callbacksDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("test run callbacks still being delivered at shutdown: %w", ctx.Err())
+	}
+}
+
+// containsRun reports whether run is one of runs
+func containsRun(runs []*TestRun, run *TestRun) bool {
+	for _, candidate := range runs {
+		if candidate == run {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthCheck fails once Shutdown has been called, since no test run can be started
+func (s *TestService) HealthCheck(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown {
+		return ErrTestServiceShuttingDown
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestService_Shutdown(t *testing.T) {
+	req := &models.TestRunRequest{Framework: "cypress", Environment: "development"}
+
+	t.Run("cancels running and queued runs", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxConcurrentRuns: 1})
+		service.runExecutor = func(run *TestRun) error {
+			<-run.Context.Done()
+			return run.Context.Err()
+		}
+
+		running, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+		queued, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, service.Shutdown(ctx))
+		assert.Empty(t, service.GetActiveRuns())
+
+		for _, runID := range []string{running.RunID, queued.RunID} {
+			results, err := service.GetTestResults(runID)
+			require.NoError(t, err)
+			assert.Equal(t, "cancelled", results.Status)
+			assert.Equal(t, models.TestRunReasonShutdown, results.Reason)
+			assert.False(t, results.EndTime.IsZero())
+		}
+
+		_, err = service.StartTestRun(context.Background(), req)
+		assert.ErrorIs(t, err, ErrTestServiceShuttingDown)
+		assert.Empty(t, service.GetActiveRuns())
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxConcurrentRuns: 1})
+		release := make(chan struct{})
+		defer close(release)
+		service.runExecutor = func(run *TestRun) error {
+			<-release
+			return nil
+		}
+
+		_, err := service.StartTestRun(context.Background(), req)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err = service.Shutdown(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "1 test runs still active")
+	})
+
+	t.Run("nothing to cancel", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil)
+		assert.NoError(t, service.Shutdown(context.Background()))
+	})
+}
+
+func TestTestService_HealthCheck(t *testing.T) {
+	service := NewTestService(&config.Config{}, nil)
+	assert.NoError(t, service.HealthCheck(context.Background()))
+
+	require.NoError(t, service.Shutdown(context.Background()))
+	assert.ErrorIs(t, service.HealthCheck(context.Background()), ErrTestServiceShuttingDown)
+}
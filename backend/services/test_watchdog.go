@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// minWatchdogCheckInterval is the most often the run watchdog checks for activity
+const minWatchdogCheckInterval = time.Second
+
+// Statuses of test_watchdog WebSocket events
+const (
+	WatchdogStatusSilent    = "silent"    // No run has started within the expected interval
+	WatchdogStatusRecovered = "recovered" // A run started after a silent alert
+)
+
+// StartRunWatchdog checks that a test run starts at least every ExpectedRunInterval until ctx is
+// done or Stop is called. When none has, a test_watchdog event is broadcast once per silence, so a
+// scheduler that stopped triggering runs is noticed. It does nothing when the interval is 0 or the
+// watchdog is running.
+func (s *TestService) StartRunWatchdog(ctx context.Context) {
+	if s.watchdogInterval <= 0 {
+		return
+	}
+
+	s.reaperMu.Lock()
+	defer s.reaperMu.Unlock()
+	if s.watchdogCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.watchdogCancel = cancel
+	s.watchdogDone = done
+
+	// Checking four times per interval alerts at most a quarter interval late
+	checkInterval := s.watchdogInterval / 4
+	if checkInterval < minWatchdogCheckInterval {
+		checkInterval = minWatchdogCheckInterval
+	}
+
+	go func() {
+		defer close(done)
+
+		// Check straight away, so a restart into a silence that has already run long alerts
+		s.CheckRunActivity()
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.CheckRunActivity()
+			}
+		}
+	}()
+
+	log.Printf("Test run watchdog started, expecting a run every %s", s.watchdogInterval)
+}
+
+// CheckRunActivity alerts when no run has started within ExpectedRunInterval, reporting whether it
+// did. Only the first check of a silence alerts; the next run to start re-arms the watchdog.
+func (s *TestService) CheckRunActivity() bool {
+	if s.watchdogInterval <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	lastRun := s.lastRunStarted
+	silentFor := time.Since(lastRun)
+	if silentFor < s.watchdogInterval || s.watchdogAlerting {
+		s.mu.Unlock()
+		return false
+	}
+	s.watchdogAlerting = true
+	s.watchdogAlerts++
+	s.mu.Unlock()
+
+	log.Printf("No test run has started for %s, expected one every %s", silentFor.Round(time.Second), s.watchdogInterval)
+	s.broadcastWatchdog(WatchdogStatusSilent, lastRun, silentFor)
+	return true
+}
+
+// recordRunStarted notes that a run started and re-arms the watchdog. It returns when the previous
+// run started and whether the watchdog had alerted, in which case the caller broadcasts the
+// recovery once it has released s.mu, which it holds.
+func (s *TestService) recordRunStarted(startTime time.Time) (time.Time, bool) {
+	lastRun := s.lastRunStarted
+	s.lastRunStarted = startTime
+	recovered := s.watchdogAlerting
+	s.watchdogAlerting = false
+	return lastRun, recovered
+}
+
+// broadcastWatchdog sends a test_watchdog event to every WebSocket client
+func (s *TestService) broadcastWatchdog(status string, lastRun time.Time, silentFor time.Duration) {
+	if s.wsHub == nil {
+		return
+	}
+
+	s.wsHub.BroadcastToAll("test_watchdog", map[string]interface{}{
+		"status":            status,
+		"last_run_started":  lastRun,
+		"silent_for":        silentFor.Round(time.Second).String(),
+		"expected_interval": s.watchdogInterval.String(),
+		"timestamp":         time.Now(),
+	})
+}
+
+// watchdogStatus reports the watchdog state for GetStatus; the caller holds s.mu
+func (s *TestService) watchdogStatus() map[string]interface{} {
+	status := map[string]interface{}{
+		"enabled":          s.watchdogInterval > 0,
+		"last_run_started": s.lastRunStarted,
+		"alerting":         s.watchdogAlerting,
+		"alerts":           s.watchdogAlerts,
+	}
+	if s.watchdogInterval > 0 {
+		status["expected_interval"] = s.watchdogInterval.String()
+	}
+	return status
+}
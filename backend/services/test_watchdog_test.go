@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// watchdogEvent matches a test_watchdog broadcast with the given status
+func watchdogEvent(status string) interface{} {
+	return mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data["status"] == status && data["expected_interval"] == "1h0m0s"
+	})
+}
+
+func TestTestService_CheckRunActivity(t *testing.T) {
+	hub := &MockWebSocketHub{}
+	hub.On("BroadcastToAll", "test_progress", mock.Anything).Return()
+	service := NewTestService(&config.Config{}, hub, TestServiceConfig{ExpectedRunInterval: time.Hour})
+	service.runExecutor = func(run *TestRun) error { return nil }
+
+	assert.False(t, service.CheckRunActivity(), "the service just started")
+	hub.AssertNotCalled(t, "BroadcastToAll", "test_watchdog", mock.Anything)
+
+	// No run for longer than the interval
+	service.mu.Lock()
+	service.lastRunStarted = time.Now().Add(-2 * time.Hour)
+	service.mu.Unlock()
+
+	hub.On("BroadcastToAll", "test_watchdog", watchdogEvent(WatchdogStatusSilent)).Return().Once()
+	assert.True(t, service.CheckRunActivity())
+	assert.False(t, service.CheckRunActivity(), "one alert per silence")
+	hub.AssertNumberOfCalls(t, "BroadcastToAll", 1)
+
+	status := service.GetStatus()["watchdog"].(map[string]interface{})
+	assert.Equal(t, true, status["enabled"])
+	assert.Equal(t, true, status["alerting"])
+	assert.Equal(t, 1, status["alerts"])
+	assert.Equal(t, "1h0m0s", status["expected_interval"])
+
+	// A new run recovers and re-arms the watchdog
+	hub.On("BroadcastToAll", "test_watchdog", watchdogEvent(WatchdogStatusRecovered)).Return().Once()
+	_, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", TestSuite: "api", Environment: "development"})
+	require.NoError(t, err)
+	hub.AssertExpectations(t)
+
+	status = service.GetStatus()["watchdog"].(map[string]interface{})
+	assert.Equal(t, false, status["alerting"])
+	assert.False(t, service.CheckRunActivity())
+
+	service.mu.Lock()
+	service.lastRunStarted = time.Now().Add(-2 * time.Hour)
+	service.mu.Unlock()
+	hub.On("BroadcastToAll", "test_watchdog", watchdogEvent(WatchdogStatusSilent)).Return().Once()
+	assert.True(t, service.CheckRunActivity(), "the next silence alerts again")
+	assert.Equal(t, 2, service.GetStatus()["watchdog"].(map[string]interface{})["alerts"])
+}
+
+func TestTestService_RunWatchdog(t *testing.T) {
+	store := &fakeHistoryStore{saved: []models.TestResults{
+		{RunID: "run-old", Status: "completed", StartTime: time.Now().Add(-3 * time.Hour)},
+		{RunID: "run-last", Status: "completed", StartTime: time.Now().Add(-2 * time.Hour)},
+	}}
+	hub := &MockWebSocketHub{}
+	alerted := make(chan map[string]interface{}, 1)
+	hub.On("BroadcastToAll", "test_watchdog", mock.Anything).Run(func(args mock.Arguments) {
+		alerted <- args.Get(1).(map[string]interface{})
+	}).Return()
+
+	service := NewTestService(&config.Config{}, hub, TestServiceConfig{HistoryStore: store, ExpectedRunInterval: time.Hour})
+	service.StartRunWatchdog(context.Background())
+	defer service.Stop()
+
+	// Silence is measured from the last run before the restart, and checked on start
+	select {
+	case event := <-alerted:
+		assert.Equal(t, WatchdogStatusSilent, event["status"])
+		assert.WithinDuration(t, store.saved[1].StartTime, event["last_run_started"].(time.Time), time.Second)
+	case <-time.After(time.Second):
+		t.Fatal("the watchdog didn't alert")
+	}
+
+	t.Run("disabled without an interval", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil)
+		service.StartRunWatchdog(context.Background())
+		assert.Nil(t, service.watchdogCancel)
+		assert.False(t, service.CheckRunActivity())
+		assert.Equal(t, false, service.GetStatus()["watchdog"].(map[string]interface{})["enabled"])
+		service.Stop()
+	})
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
+	"github.com/google/uuid"
+)
+
+// ErrWorkflowNotFound is returned for workflow IDs that are unknown or have aged out
+var ErrWorkflowNotFound = errors.New("test workflow not found")
+
+// maxWorkflowHistory is how many workflows are kept for GetWorkflow, oldest dropped first
+const maxWorkflowHistory = 100
+
+// testWorkflow is a workflow being run or kept for GetWorkflow. Guarded by TestService.workflowMu.
+type testWorkflow struct {
+	request *models.TestWorkflowRequest
+	result  models.TestWorkflowResult
+	traceID string
+	owner   string // Authenticated user that started the workflow, owning its runs
+}
+
+// StartWorkflow validates a workflow and runs its steps one after another in the background.
+// Each step is a regular test run, so it is queued, broadcast and kept in history like any other.
+// A failed step skips the remaining steps unless it has ContinueOnFailure.
+func (s *TestService) StartWorkflow(ctx context.Context, req *models.TestWorkflowRequest) (*models.TestWorkflowResult, error) {
+	if len(req.Steps) == 0 {
+		return nil, errors.New("workflow has no steps")
+	}
+	for i, step := range req.Steps {
+		if !s.isFrameworkSupported(step.Run.Framework) {
+			return nil, fmt.Errorf("step %d (%s): unsupported test framework: %s", i+1, step.Name, step.Run.Framework)
+		}
+		if _, _, err := s.sanitizeTestConfig(step.Run.Config); err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i+1, step.Name, err)
+		}
+		if err := s.checkCallbackURL(step.Run.CallbackURL); err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i+1, step.Name, err)
+		}
+	}
+
+	workflow := &testWorkflow{
+		request: req,
+		traceID: utils.TraceIDFromContext(ctx),
+		owner:   utils.UserIDFromContext(ctx),
+		result: models.TestWorkflowResult{
+			WorkflowID:  uuid.New().String(),
+			Name:        req.Name,
+			Status:      "running",
+			CurrentStep: 1,
+			StartTime:   time.Now(),
+			Steps:       make([]models.TestWorkflowStepResult, len(req.Steps)),
+		},
+	}
+	for i, step := range req.Steps {
+		workflow.result.Steps[i] = models.TestWorkflowStepResult{
+			Name:              step.Name,
+			Status:            "pending",
+			ContinueOnFailure: step.ContinueOnFailure,
+		}
+	}
+
+	s.workflowMu.Lock()
+	s.workflows[workflow.result.WorkflowID] = workflow
+	s.workflowOrder = append(s.workflowOrder, workflow.result.WorkflowID)
+	if len(s.workflowOrder) > maxWorkflowHistory {
+		delete(s.workflows, s.workflowOrder[0])
+		s.workflowOrder = s.workflowOrder[1:]
+	}
+	snapshot := workflow.snapshot()
+	s.workflowMu.Unlock()
+
+	// The request's context ends when the response is sent; keep only its trace ID and user
+	runCtx := utils.ContextWithUserID(utils.ContextWithTraceID(context.Background(), workflow.traceID), workflow.owner)
+	go s.runWorkflow(runCtx, workflow)
+
+	return snapshot, nil
+}
+
+// GetWorkflow returns the progress, or the final aggregated results, of a workflow
+func (s *TestService) GetWorkflow(workflowID string) (*models.TestWorkflowResult, error) {
+	s.workflowMu.Lock()
+	defer s.workflowMu.Unlock()
+
+	workflow, exists := s.workflows[workflowID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+	}
+	return workflow.snapshot(), nil
+}
+
+// runWorkflow runs each step to completion before starting the next
+func (s *TestService) runWorkflow(ctx context.Context, workflow *testWorkflow) {
+	id := workflow.result.WorkflowID
+	steps := workflow.request.Steps
+	s.broadcastWorkflowUpdate(workflow, 0, "running", fmt.Sprintf("Workflow started with %d steps", len(steps)))
+
+	status := "completed"
+	for i, step := range steps {
+		if status != "completed" {
+			s.updateWorkflowStep(workflow, i, func(result *models.TestWorkflowStepResult) {
+				result.Status = "skipped"
+			})
+			s.broadcastWorkflowUpdate(workflow, i+1, "skipped", "Step skipped because an earlier step did not complete")
+			continue
+		}
+
+		run := step.Run
+		response, err := s.StartTestRun(ctx, &run)
+		if err != nil {
+			log.Printf("Workflow %s step %d failed to start: %v", id, i+1, err)
+			s.updateWorkflowStep(workflow, i, func(result *models.TestWorkflowStepResult) {
+				result.Status = "failed"
+				result.Error = err.Error()
+			})
+			s.broadcastWorkflowUpdate(workflow, i+1, "failed", fmt.Sprintf("Step failed to start: %v", err))
+			if !step.ContinueOnFailure {
+				status = "failed"
+			}
+			continue
+		}
+
+		s.updateWorkflowStep(workflow, i, func(result *models.TestWorkflowStepResult) {
+			result.Status = "running"
+			result.RunID = response.RunID
+		})
+		s.broadcastWorkflowUpdate(workflow, i+1, "running", "Step started")
+
+		// A step gets as long as its run may take, counted from when it was queued
+		results, err := s.WaitForTestRun(ctx, response.RunID, s.runTimeout(&run))
+		if errors.Is(err, ErrTestRunWaitTimeout) {
+			if cancelErr := s.CancelTestRun(response.RunID); cancelErr != nil {
+				log.Printf("Workflow %s failed to cancel timed out step %d: %v", id, i+1, cancelErr)
+			}
+		}
+		if err != nil {
+			log.Printf("Workflow %s lost track of step %d: %v", id, i+1, err)
+			s.updateWorkflowStep(workflow, i, func(result *models.TestWorkflowStepResult) {
+				result.Status = "failed"
+				result.Error = err.Error()
+			})
+			s.broadcastWorkflowUpdate(workflow, i+1, "failed", fmt.Sprintf("Step results unavailable: %v", err))
+			status = "failed"
+			continue
+		}
+
+		s.updateWorkflowStep(workflow, i, func(result *models.TestWorkflowStepResult) {
+			result.Status = results.Status
+			result.Results = results
+		})
+		s.broadcastWorkflowUpdate(workflow, i+1, results.Status, fmt.Sprintf("Step %s", results.Status))
+
+		switch {
+		case results.Status == "cancelled":
+			status = "cancelled"
+		case results.Status != "completed" && !step.ContinueOnFailure:
+			status = "failed"
+		}
+	}
+
+	s.workflowMu.Lock()
+	workflow.result.Status = status
+	workflow.result.CurrentStep = 0
+	workflow.result.EndTime = time.Now()
+	workflow.result.Duration = workflow.result.EndTime.Sub(workflow.result.StartTime)
+	s.workflowMu.Unlock()
+
+	s.broadcastWorkflowUpdate(workflow, 0, status, fmt.Sprintf("Workflow %s", status))
+}
+
+// updateWorkflowStep changes a step's result, moves the workflow on to that step and
+// re-aggregates the test counts
+func (s *TestService) updateWorkflowStep(workflow *testWorkflow, index int, update func(result *models.TestWorkflowStepResult)) {
+	s.workflowMu.Lock()
+	defer s.workflowMu.Unlock()
+
+	update(&workflow.result.Steps[index])
+	workflow.result.CurrentStep = index + 1
+
+	workflow.result.TotalTests = 0
+	workflow.result.PassedTests = 0
+	workflow.result.FailedTests = 0
+	workflow.result.SkippedTests = 0
+	for _, step := range workflow.result.Steps {
+		if step.Results == nil {
+			continue
+		}
+		workflow.result.TotalTests += step.Results.TotalTests
+		workflow.result.PassedTests += step.Results.PassedTests
+		workflow.result.FailedTests += step.Results.FailedTests
+		workflow.result.SkippedTests += step.Results.SkippedTests
+	}
+}
+
+// snapshot copies the workflow's result so it can be read without the lock. Callers must hold workflowMu.
+func (w *testWorkflow) snapshot() *models.TestWorkflowResult {
+	result := w.result
+	result.Steps = append([]models.TestWorkflowStepResult(nil), w.result.Steps...)
+	return &result
+}
+
+// broadcastWorkflowUpdate sends a test_workflow_progress message; step is 1-based, or 0 for
+// updates about the whole workflow
+func (s *TestService) broadcastWorkflowUpdate(workflow *testWorkflow, step int, status, message string) {
+	if s.wsHub == nil {
+		return
+	}
+
+	s.workflowMu.Lock()
+	data := map[string]interface{}{
+		"workflow_id":   workflow.result.WorkflowID,
+		"name":          workflow.result.Name,
+		"status":        status,
+		"message":       message,
+		"total_steps":   len(workflow.result.Steps),
+		"total_tests":   workflow.result.TotalTests,
+		"passed_tests":  workflow.result.PassedTests,
+		"failed_tests":  workflow.result.FailedTests,
+		"skipped_tests": workflow.result.SkippedTests,
+		"timestamp":     time.Now(),
+	}
+	if step > 0 {
+		stepResult := workflow.result.Steps[step-1]
+		data["step"] = step
+		data["step_name"] = stepResult.Name
+		if stepResult.RunID != "" {
+			data["run_id"] = stepResult.RunID
+		}
+	}
+	s.workflowMu.Unlock()
+
+	if s.config != nil && s.config.EnableWSCorrelationID && workflow.traceID != "" {
+		data["trace_id"] = workflow.traceID
+	}
+
+	s.wsHub.BroadcastToAll("test_workflow_progress", data)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/config"
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newWorkflowTestService returns a service whose runs fail when their suite is "fail" and
+// otherwise pass with one test, recording the order suites were executed in
+func newWorkflowTestService(hub WebSocketBroadcaster) (*TestService, func() []string) {
+	service := NewTestService(&config.Config{}, hub)
+
+	var mu sync.Mutex
+	var executed []string
+	service.runExecutor = func(run *TestRun) error {
+		mu.Lock()
+		executed = append(executed, run.Request.TestSuite)
+		mu.Unlock()
+
+		if run.Request.TestSuite == "fail" {
+			return errors.New("1 test failed")
+		}
+		run.Results.TotalTests = 1
+		run.Results.PassedTests = 1
+		return nil
+	}
+
+	return service, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), executed...)
+	}
+}
+
+func workflowStep(name, suite string, continueOnFailure bool) models.TestWorkflowStep {
+	return models.TestWorkflowStep{
+		Name:              name,
+		Run:               models.TestRunRequest{Framework: "jest", TestSuite: suite, Environment: "development"},
+		ContinueOnFailure: continueOnFailure,
+	}
+}
+
+// waitForWorkflow polls until the workflow has finished
+func waitForWorkflow(t *testing.T, service *TestService, workflowID string) *models.TestWorkflowResult {
+	t.Helper()
+
+	var result *models.TestWorkflowResult
+	require.Eventually(t, func() bool {
+		var err error
+		result, err = service.GetWorkflow(workflowID)
+		require.NoError(t, err)
+		return result.Status != "running"
+	}, 2*time.Second, 10*time.Millisecond)
+	return result
+}
+
+func TestTestService_Workflow(t *testing.T) {
+	t.Run("runs every step in order and aggregates the results", func(t *testing.T) {
+		service, executed := newWorkflowTestService(nil)
+		started, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{workflowStep("seed", "seed", false), workflowStep("api", "api", false), workflowStep("ui", "ui", false)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "running", started.Status)
+		assert.Len(t, started.Steps, 3)
+
+		result := waitForWorkflow(t, service, started.WorkflowID)
+		assert.Equal(t, "completed", result.Status)
+		assert.Equal(t, []string{"seed", "api", "ui"}, executed())
+		assert.Equal(t, 3, result.TotalTests)
+		assert.Equal(t, 3, result.PassedTests)
+		assert.Zero(t, result.CurrentStep)
+		assert.False(t, result.EndTime.IsZero())
+		for _, step := range result.Steps {
+			assert.Equal(t, "completed", step.Status)
+			require.NotNil(t, step.Results)
+			assert.Equal(t, step.RunID, step.Results.RunID)
+		}
+	})
+
+	t.Run("a failed step skips the rest", func(t *testing.T) {
+		service, executed := newWorkflowTestService(nil)
+		started, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{workflowStep("seed", "fail", false), workflowStep("api", "api", false)},
+		})
+		require.NoError(t, err)
+
+		result := waitForWorkflow(t, service, started.WorkflowID)
+		assert.Equal(t, "failed", result.Status)
+		assert.Equal(t, []string{"fail"}, executed())
+		assert.Equal(t, "failed", result.Steps[0].Status)
+		assert.Equal(t, "skipped", result.Steps[1].Status)
+		assert.Empty(t, result.Steps[1].RunID)
+	})
+
+	t.Run("continue on failure runs the next steps", func(t *testing.T) {
+		service, executed := newWorkflowTestService(nil)
+		started, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{workflowStep("lint", "fail", true), workflowStep("api", "api", false)},
+		})
+		require.NoError(t, err)
+
+		result := waitForWorkflow(t, service, started.WorkflowID)
+		assert.Equal(t, "completed", result.Status)
+		assert.Equal(t, []string{"fail", "api"}, executed())
+		assert.Equal(t, "failed", result.Steps[0].Status)
+		assert.Equal(t, "completed", result.Steps[1].Status)
+	})
+
+	t.Run("unsupported frameworks are rejected up front", func(t *testing.T) {
+		service, executed := newWorkflowTestService(nil)
+		step := workflowStep("api", "api", false)
+		step.Run.Framework = "karma"
+
+		_, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{workflowStep("seed", "seed", false), step},
+		})
+		assert.ErrorContains(t, err, "step 2 (api)")
+		assert.Empty(t, executed())
+	})
+
+	t.Run("a step that outlasts its run timeout is cancelled", func(t *testing.T) {
+		service := NewTestService(&config.Config{}, nil, TestServiceConfig{MaxConcurrentRuns: 1})
+		defer close(blockedRuns(service))
+
+		// Occupy the only slot so the step stays queued
+		_, err := service.StartTestRun(context.Background(), &models.TestRunRequest{Framework: "jest", TestSuite: "blocker", Environment: "development"})
+		require.NoError(t, err)
+
+		step := workflowStep("api", "api", false)
+		step.Run.TimeoutSeconds = 1
+		started, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+			Name:  "checkout",
+			Steps: []models.TestWorkflowStep{step, workflowStep("ui", "ui", false)},
+		})
+		require.NoError(t, err)
+
+		result := waitForWorkflow(t, service, started.WorkflowID)
+		assert.Equal(t, "failed", result.Status)
+		assert.Equal(t, "failed", result.Steps[0].Status)
+		assert.Contains(t, result.Steps[0].Error, ErrTestRunWaitTimeout.Error())
+		assert.Equal(t, "skipped", result.Steps[1].Status)
+
+		results, err := service.GetTestResults(result.Steps[0].RunID)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", results.Status)
+	})
+
+	t.Run("unknown workflows", func(t *testing.T) {
+		service, _ := newWorkflowTestService(nil)
+		_, err := service.GetWorkflow("missing")
+		assert.ErrorIs(t, err, ErrWorkflowNotFound)
+	})
+}
+
+func TestTestService_WorkflowBroadcasts(t *testing.T) {
+	mockHub := &MockWebSocketHub{}
+	var mu sync.Mutex
+	var updates []map[string]interface{}
+	mockHub.On("BroadcastToAll", "test_progress", mock.Anything).Return()
+	mockHub.On("BroadcastToAll", "test_workflow_progress", mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, args.Get(1).(map[string]interface{}))
+	}).Return()
+
+	service, _ := newWorkflowTestService(mockHub)
+	started, err := service.StartWorkflow(context.Background(), &models.TestWorkflowRequest{
+		Name:  "checkout",
+		Steps: []models.TestWorkflowStep{workflowStep("seed", "fail", false), workflowStep("api", "api", false)},
+	})
+	require.NoError(t, err)
+	waitForWorkflow(t, service, started.WorkflowID)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(updates) > 0 && updates[len(updates)-1]["step"] == nil && updates[len(updates)-1]["status"] == "failed"
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var statuses []string
+	for _, update := range updates {
+		assert.Equal(t, started.WorkflowID, update["workflow_id"])
+		statuses = append(statuses, update["status"].(string))
+	}
+	assert.Equal(t, []string{"running", "running", "failed", "skipped", "failed"}, statuses)
+	assert.Equal(t, 1, updates[1]["step"])
+	assert.Equal(t, "seed", updates[1]["step_name"])
+	assert.NotEmpty(t, updates[1]["run_id"])
+	assert.Equal(t, 2, updates[3]["step"])
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrValidationLimitReached is returned when an environment already has its maximum number of validations in flight
+var ErrValidationLimitReached = errors.New("too many concurrent validations for environment")
+
+// ValidationLimiterConfig holds the concurrency caps applied to sync validations
+type ValidationLimiterConfig struct {
+	DefaultLimit      int            // Validations in flight per environment; 0 means unlimited
+	EnvironmentLimits map[string]int // Overrides keyed by connected environment name or target host
+	QueueTimeout      time.Duration  // How long a validation waits for a slot; 0 rejects immediately
+}
+
+// ValidationLimiter caps concurrent sync validations per target environment so validation
+// tooling can't overwhelm the environments it checks. Targets are grouped by connected
+// environment when their host belongs to one, and by host otherwise.
+type ValidationLimiter struct {
+	mu               sync.Mutex
+	config           ValidationLimiterConfig
+	hostEnvironments map[string]string        // host -> connected environment name
+	slots            map[string]chan struct{} // environment key -> semaphore
+}
+
+// NewValidationLimiter creates a limiter with the given caps
+func NewValidationLimiter(config ValidationLimiterConfig) *ValidationLimiter {
+	return &ValidationLimiter{
+		config:           config,
+		hostEnvironments: make(map[string]string),
+		slots:            make(map[string]chan struct{}),
+	}
+}
+
+// RegisterEnvironment groups validations against the environment's URLs under its name
+func (l *ValidationLimiter) RegisterEnvironment(name string, urls ...string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for host, env := range l.hostEnvironments {
+		if env == name {
+			delete(l.hostEnvironments, host)
+		}
+	}
+	for _, rawURL := range urls {
+		if host := targetHost(rawURL); host != "" {
+			l.hostEnvironments[host] = name
+		}
+	}
+}
+
+// UnregisterEnvironment stops grouping the environment's URLs under its name
+func (l *ValidationLimiter) UnregisterEnvironment(name string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for host, env := range l.hostEnvironments {
+		if env == name {
+			delete(l.hostEnvironments, host)
+		}
+	}
+}
+
+// Acquire reserves a validation slot in every environment the targets belong to, waiting up to
+// the queue timeout for busy environments. The returned release func must be called when the
+// validation finishes. A nil limiter never blocks.
+func (l *ValidationLimiter) Acquire(ctx context.Context, targets ...string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	// Acquire in a fixed order so validations spanning several environments can't deadlock
+	keys := l.environmentKeys(targets)
+	sort.Strings(keys)
+
+	acquired := make([]chan struct{}, 0, len(keys))
+	release := func() {
+		for _, slots := range acquired {
+			<-slots
+		}
+	}
+
+	for _, key := range keys {
+		slots := l.environmentSlots(key)
+		if slots == nil {
+			continue
+		}
+		if err := l.wait(ctx, slots); err != nil {
+			release()
+			if errors.Is(err, ErrValidationLimitReached) {
+				return nil, fmt.Errorf("%w %s (limit %d)", err, key, cap(slots))
+			}
+			return nil, err
+		}
+		acquired = append(acquired, slots)
+	}
+
+	return release, nil
+}
+
+// wait blocks until a slot is free, the queue timeout elapses or ctx is done
+func (l *ValidationLimiter) wait(ctx context.Context, slots chan struct{}) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if l.config.QueueTimeout <= 0 {
+		return ErrValidationLimitReached
+	}
+
+	timer := time.NewTimer(l.config.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrValidationLimitReached
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// environmentKeys resolves targets to their distinct environment keys
+func (l *ValidationLimiter) environmentKeys(targets []string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := make(map[string]bool, len(targets))
+	keys := make([]string, 0, len(targets))
+	for _, target := range targets {
+		host := targetHost(target)
+		if host == "" {
+			continue
+		}
+
+		key := host
+		if env, ok := l.hostEnvironments[host]; ok {
+			key = env
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// environmentSlots returns the semaphore for an environment key, or nil when it is unlimited
+func (l *ValidationLimiter) environmentSlots(key string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if slots, ok := l.slots[key]; ok {
+		return slots
+	}
+
+	limit := l.config.DefaultLimit
+	if override, ok := l.config.EnvironmentLimits[key]; ok {
+		limit = override
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	slots := make(chan struct{}, limit)
+	l.slots[key] = slots
+	return slots
+}
+
+// targetHost returns the lowercased host[:port] of a URL, or "" if it has none
+func targetHost(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationLimiter_Acquire(t *testing.T) {
+	t.Run("rejects beyond the default limit", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 2})
+
+		first, err := limiter.Acquire(context.Background(), "http://staging.example.com/api/users")
+		require.NoError(t, err)
+		second, err := limiter.Acquire(context.Background(), "http://staging.example.com/api/orders")
+		require.NoError(t, err)
+
+		_, err = limiter.Acquire(context.Background(), "http://staging.example.com/api/items")
+		assert.ErrorIs(t, err, ErrValidationLimitReached)
+		assert.Contains(t, err.Error(), "staging.example.com (limit 2)")
+
+		// Other hosts have their own slots
+		other, err := limiter.Acquire(context.Background(), "http://qa.example.com/api/users")
+		require.NoError(t, err)
+		other()
+
+		// Releasing frees a slot
+		first()
+		third, err := limiter.Acquire(context.Background(), "http://staging.example.com/api/items")
+		require.NoError(t, err)
+		second()
+		third()
+	})
+
+	t.Run("queued validation gets a released slot", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1, QueueTimeout: time.Second})
+
+		release, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+		require.NoError(t, err)
+		time.AfterFunc(20*time.Millisecond, release)
+
+		start := time.Now()
+		queued, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+		queued()
+	})
+
+	t.Run("queue timeout rejects", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1, QueueTimeout: 20 * time.Millisecond})
+
+		release, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = limiter.Acquire(context.Background(), "http://staging.example.com")
+		assert.ErrorIs(t, err, ErrValidationLimitReached)
+	})
+
+	t.Run("cancelled context stops waiting", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1, QueueTimeout: time.Minute})
+
+		release, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = limiter.Acquire(ctx, "http://staging.example.com")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrValidationLimitReached)
+	})
+
+	t.Run("environment overrides and unlimited", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{
+			DefaultLimit:      1,
+			EnvironmentLimits: map[string]int{"staging": 3, "localhost:3000": 0},
+		})
+		limiter.RegisterEnvironment("staging", "http://web.staging.example.com", "http://api.staging.example.com")
+
+		// Both staging hosts share the environment's three slots
+		for i := 0; i < 3; i++ {
+			target := "http://web.staging.example.com"
+			if i%2 == 1 {
+				target = "http://api.staging.example.com"
+			}
+			_, err := limiter.Acquire(context.Background(), target)
+			require.NoError(t, err)
+		}
+		_, err := limiter.Acquire(context.Background(), "http://API.staging.example.com/health")
+		assert.ErrorIs(t, err, ErrValidationLimitReached)
+		assert.Contains(t, err.Error(), "staging (limit 3)")
+
+		// A limit of 0 leaves the host unlimited
+		for i := 0; i < 10; i++ {
+			_, err := limiter.Acquire(context.Background(), "http://localhost:3000/api")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("unregistered environment falls back to per-host caps", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1})
+		limiter.RegisterEnvironment("staging", "http://web.staging.example.com", "http://api.staging.example.com")
+		limiter.UnregisterEnvironment("staging")
+
+		web, err := limiter.Acquire(context.Background(), "http://web.staging.example.com")
+		require.NoError(t, err)
+		defer web()
+		api, err := limiter.Acquire(context.Background(), "http://api.staging.example.com")
+		require.NoError(t, err)
+		defer api()
+	})
+
+	t.Run("multiple targets are all or nothing", func(t *testing.T) {
+		limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 1})
+
+		busy, err := limiter.Acquire(context.Background(), "http://api.example.com")
+		require.NoError(t, err)
+
+		_, err = limiter.Acquire(context.Background(), "http://web.example.com", "http://api.example.com")
+		assert.ErrorIs(t, err, ErrValidationLimitReached)
+
+		// The web slot taken before the failure was returned
+		web, err := limiter.Acquire(context.Background(), "http://web.example.com")
+		require.NoError(t, err)
+		web()
+		busy()
+
+		// Targets on the same host take a single slot
+		both, err := limiter.Acquire(context.Background(), "http://api.example.com/a", "http://api.example.com/b")
+		require.NoError(t, err)
+		both()
+	})
+
+	t.Run("nil limiter never blocks", func(t *testing.T) {
+		var limiter *ValidationLimiter
+		limiter.RegisterEnvironment("staging", "http://staging.example.com")
+
+		release, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+		require.NoError(t, err)
+		release()
+	})
+}
+
+func TestValidationLimiter_ConcurrencyNeverExceedsLimit(t *testing.T) {
+	limiter := NewValidationLimiter(ValidationLimiterConfig{DefaultLimit: 3, QueueTimeout: 5 * time.Second})
+
+	var inFlight, peak atomic.Int32
+	done := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+
+			release, err := limiter.Acquire(context.Background(), "http://staging.example.com")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer release()
+
+			current := inFlight.Add(1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		<-done
+	}
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Equal(t, int32(3), peak.Load())
+}
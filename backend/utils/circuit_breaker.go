@@ -71,6 +71,25 @@ type CircuitBreaker struct {
 	stateChangedTime time.Time
 	mu               sync.RWMutex
 	logger           *Logger
+
+	// Lifetime counts, kept across state changes and Reset
+	totalSuccesses int64
+	totalFailures  int64
+	totalRejected  int64
+}
+
+// CircuitBreakerMetrics is a snapshot of a circuit breaker's state and counters
+type CircuitBreakerMetrics struct {
+	Name           string     `json:"name"`
+	State          string     `json:"state"`
+	Failures       int        `json:"failures"`         // Failures counting towards MaxFailures
+	Successes      int        `json:"successes"`        // Half-open successes counting towards SuccessThreshold
+	TotalSuccesses int64      `json:"total_successes"`  // Requests that succeeded since the breaker was created
+	TotalFailures  int64      `json:"total_failures"`   // Requests that failed since the breaker was created
+	TotalRejected  int64      `json:"total_rejected"`   // Requests rejected without being attempted
+	StateChangedAt time.Time  `json:"state_changed_at"` // When the breaker last changed state
+	LastFailureAt  *time.Time `json:"last_failure_at,omitempty"`
+	NextRetryAt    *time.Time `json:"next_retry_at,omitempty"` // When an open breaker lets a trial request through
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -94,6 +113,9 @@ func NewCircuitBreaker(config *CircuitBreakerConfig, logger *Logger) *CircuitBre
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
 	// Check if request is allowed
 	if !cb.allowRequest() {
+		cb.mu.Lock()
+		cb.totalRejected++
+		cb.mu.Unlock()
 		return &CircuitBreakerError{
 			State:   cb.GetState(),
 			Message: fmt.Sprintf("circuit breaker %s is %s", cb.config.Name, cb.GetState()),
@@ -143,6 +165,7 @@ func (cb *CircuitBreaker) recordSuccess() {
 	defer cb.mu.Unlock()
 
 	cb.lastSuccessTime = time.Now()
+	cb.totalSuccesses++
 
 	switch cb.state {
 	case StateClosed:
@@ -175,6 +198,7 @@ func (cb *CircuitBreaker) recordFailure() {
 	defer cb.mu.Unlock()
 
 	cb.failures++
+	cb.totalFailures++
 	cb.lastFailureTime = time.Now()
 
 	switch cb.state {
@@ -222,6 +246,14 @@ func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	return cb.state
 }
 
+// IsOpen reports whether the circuit breaker is rejecting requests: it is open and its timeout
+// hasn't passed yet. Unlike Execute, it doesn't move an expired open circuit to half-open.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.state == StateOpen && time.Since(cb.stateChangedTime) < cb.config.Timeout
+}
+
 // GetStats returns statistics about the circuit breaker
 func (cb *CircuitBreaker) GetStats() map[string]interface{} {
 	cb.mu.RLock()
@@ -243,6 +275,48 @@ func (cb *CircuitBreaker) GetStats() map[string]interface{} {
 	}
 }
 
+// Metrics returns the breaker's state and counters. NextRetryAt is set while the breaker is open.
+func (cb *CircuitBreaker) Metrics() CircuitBreakerMetrics {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	metrics := CircuitBreakerMetrics{
+		Name:           cb.config.Name,
+		State:          cb.state.String(),
+		Failures:       cb.failures,
+		Successes:      cb.successes,
+		TotalSuccesses: cb.totalSuccesses,
+		TotalFailures:  cb.totalFailures,
+		TotalRejected:  cb.totalRejected,
+		StateChangedAt: cb.stateChangedTime,
+	}
+	if !cb.lastFailureTime.IsZero() {
+		lastFailure := cb.lastFailureTime
+		metrics.LastFailureAt = &lastFailure
+	}
+	if cb.state == StateOpen {
+		nextRetry := cb.stateChangedTime.Add(cb.config.Timeout)
+		metrics.NextRetryAt = &nextRetry
+	}
+	return metrics
+}
+
+// Trip opens the circuit breaker as if MaxFailures requests had just failed. Like any open
+// breaker, it lets a trial request through once Timeout has passed.
+func (cb *CircuitBreaker) Trip() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.setState(StateOpen)
+	cb.stateChangedTime = time.Now() // Restart the timeout of a breaker that was already open
+	cb.successes = 0
+	cb.requests = 0
+
+	cb.logger.WithSource("circuit_breaker").Warn("Circuit breaker tripped", map[string]interface{}{
+		"circuit_breaker": cb.config.Name,
+	})
+}
+
 // Reset resets the circuit breaker to its initial state
 func (cb *CircuitBreaker) Reset() {
 	cb.mu.Lock()
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCircuitBreaker_InitialState(t *testing.T) {
@@ -109,6 +110,78 @@ func TestCircuitBreaker_HalfOpenTransition(t *testing.T) {
 	assert.Equal(t, StateClosed, cb.GetState()) // Should close after successful request
 }
 
+func TestCircuitBreaker_IsOpen(t *testing.T) {
+	config := &CircuitBreakerConfig{
+		MaxFailures:      1,
+		Timeout:          50 * time.Millisecond,
+		MaxRequests:      1,
+		SuccessThreshold: 1,
+		Name:             "test",
+	}
+	cb := NewCircuitBreaker(config, nil)
+	assert.False(t, cb.IsOpen())
+
+	cb.Execute(context.Background(), func(ctx context.Context) error {
+		return errors.New("test error")
+	})
+	assert.True(t, cb.IsOpen())
+
+	// Once the timeout has passed the next request is let through, though the state is still open
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, cb.IsOpen())
+	assert.Equal(t, StateOpen, cb.GetState())
+}
+
+func TestCircuitBreaker_Metrics(t *testing.T) {
+	config := &CircuitBreakerConfig{
+		MaxFailures:      2,
+		Timeout:          time.Minute,
+		MaxRequests:      1,
+		SuccessThreshold: 1,
+		Name:             "metrics",
+	}
+	cb := NewCircuitBreaker(config, nil)
+	succeed := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("test error") }
+
+	metrics := cb.Metrics()
+	assert.Equal(t, "metrics", metrics.Name)
+	assert.Equal(t, "CLOSED", metrics.State)
+	assert.Nil(t, metrics.LastFailureAt)
+	assert.Nil(t, metrics.NextRetryAt)
+
+	cb.Execute(context.Background(), succeed)
+	cb.Execute(context.Background(), fail)
+	metrics = cb.Metrics()
+	assert.Equal(t, "CLOSED", metrics.State)
+	assert.Equal(t, 1, metrics.Failures)
+	assert.Equal(t, int64(1), metrics.TotalSuccesses)
+	assert.Equal(t, int64(1), metrics.TotalFailures)
+	require.NotNil(t, metrics.LastFailureAt)
+	assert.Nil(t, metrics.NextRetryAt)
+
+	// The second failure opens the circuit, which then rejects requests until the timeout
+	cb.Execute(context.Background(), fail)
+	err := cb.Execute(context.Background(), succeed)
+	assert.True(t, IsCircuitBreakerError(err))
+
+	metrics = cb.Metrics()
+	assert.Equal(t, "OPEN", metrics.State)
+	assert.Equal(t, 2, metrics.Failures)
+	assert.Equal(t, int64(2), metrics.TotalFailures)
+	assert.Equal(t, int64(1), metrics.TotalRejected)
+	require.NotNil(t, metrics.NextRetryAt)
+	assert.Equal(t, metrics.StateChangedAt.Add(time.Minute), *metrics.NextRetryAt)
+
+	// Lifetime counts survive a reset
+	cb.Reset()
+	metrics = cb.Metrics()
+	assert.Equal(t, "CLOSED", metrics.State)
+	assert.Zero(t, metrics.Failures)
+	assert.Equal(t, int64(2), metrics.TotalFailures)
+	assert.Nil(t, metrics.NextRetryAt)
+}
+
 func TestCircuitBreaker_HalfOpenFailure(t *testing.T) {
 	config := &CircuitBreakerConfig{
 		MaxFailures:      1,
@@ -174,6 +247,35 @@ func TestCircuitBreaker_Reset(t *testing.T) {
 	assert.True(t, executed)
 }
 
+func TestCircuitBreaker_Trip(t *testing.T) {
+	config := &CircuitBreakerConfig{
+		MaxFailures:      5,
+		Timeout:          50 * time.Millisecond,
+		MaxRequests:      1,
+		SuccessThreshold: 1,
+		Name:             "test",
+	}
+	cb := NewCircuitBreaker(config, nil)
+	ctx := context.Background()
+
+	cb.Trip()
+	assert.True(t, cb.IsOpen())
+
+	executed := false
+	err := cb.Execute(ctx, func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+	assert.True(t, IsCircuitBreakerError(err))
+	assert.False(t, executed)
+
+	// A tripped breaker recovers like one opened by failures
+	time.Sleep(60 * time.Millisecond)
+	err = cb.Execute(ctx, func(ctx context.Context) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, StateClosed, cb.GetState())
+}
+
 func TestCircuitBreakerManager(t *testing.T) {
 	manager := NewCircuitBreakerManager(nil)
 
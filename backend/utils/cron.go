@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCronExpression is returned by ParseCron for expressions it can't read
+var ErrInvalidCronExpression = errors.New("invalid cron expression")
+
+// maxCronSearch bounds how far ahead CronSchedule.Next looks, so expressions that never match,
+// e.g. February 30th, give up instead of looping forever
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// cronDescriptors are the @-shorthands accepted in place of the five fields
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the values one field of an expression may take
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names of the values from min on, e.g. months and weekdays
+}
+
+var (
+	cronMinute  = cronField{name: "minute", min: 0, max: 59}
+	cronHour    = cronField{name: "hour", min: 0, max: 23}
+	cronDay     = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth   = cronField{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// CronSchedule is a parsed standard five-field cron expression: minute, hour, day of month, month
+// and day of week. Each field is a bit set of the values it matches.
+type CronSchedule struct {
+	expr                                   string
+	minutes, hours, days, months, weekdays uint64
+
+	// Like cron, when both day fields are restricted a day matching either one matches
+	anyDay, anyWeekday bool
+}
+
+// ParseCron parses a five-field cron expression such as "30 2 * * 1-5". Fields take "*", values,
+// ranges ("1-5"), steps ("*/15", "0-30/10") and comma-separated lists of these. Months and weekdays
+// may be given by their three-letter English names, and both 0 and 7 are Sunday. The descriptors
+// @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are accepted too.
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(descriptor)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields, got %d", ErrInvalidCronExpression, expr, len(fields))
+	}
+
+	schedule := &CronSchedule{expr: expr}
+	targets := []*uint64{&schedule.minutes, &schedule.hours, &schedule.days, &schedule.months, &schedule.weekdays}
+	for i, field := range []cronField{cronMinute, cronHour, cronDay, cronMonth, cronWeekday} {
+		bits, err := field.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidCronExpression, expr, err)
+		}
+		*targets[i] = bits
+	}
+
+	// 7 is another name for Sunday
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays = schedule.weekdays&^(1<<7) | 1
+	}
+	schedule.anyDay = strings.HasPrefix(fields[2], "*")
+	schedule.anyWeekday = strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Next returns the first time after the given one the schedule matches, in the given time's
+// location, or the zero time when it matches none within the next five years
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		var next time.Time
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			next = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			next = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hours&(1<<uint(t.Hour())) == 0:
+			next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			next = t.Add(time.Minute)
+		default:
+			return t
+		}
+
+		// A local time skipped by a daylight saving change can normalise to one before t
+		if !next.After(t) {
+			next = t.Add(time.Hour)
+		}
+		t = next
+	}
+	return time.Time{}
+}
+
+// MinInterval returns the shortest gap between two consecutive times the schedule matches,
+// assuming it matches every day, so the real gap may be longer. Daylight saving changes are ignored.
+func (c *CronSchedule) MinInterval() time.Duration {
+	var times []int // Minutes of the day
+	for hour := 0; hour < 24; hour++ {
+		if c.hours&(1<<uint(hour)) == 0 {
+			continue
+		}
+		for minute := 0; minute < 60; minute++ {
+			if c.minutes&(1<<uint(minute)) != 0 {
+				times = append(times, hour*60+minute)
+			}
+		}
+	}
+	if len(times) == 0 {
+		return 0
+	}
+
+	// The last time of one day is followed by the first of the next
+	shortest := times[0] + 24*60 - times[len(times)-1]
+	for i := 1; i < len(times); i++ {
+		if gap := times[i] - times[i-1]; gap < shortest {
+			shortest = gap
+		}
+	}
+	return time.Duration(shortest) * time.Minute
+}
+
+// matchesDay reports whether t's day matches the day of month and day of week fields
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// parse returns the bit set of the values a field matches
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = f.value(lowPart); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highPart); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end in steps of 15
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q in %s field ends before it starts", rangePart, f.name)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// value reads a single value of the field, by number or by name
+func (f cronField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return f.min + i, nil
+		}
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", f.name, text)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", f.name, value, f.min, f.max)
+	}
+	return value, nil
+}
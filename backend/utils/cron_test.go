@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidCronExpression, expr)
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2026, time.January, 15, 10, 7, 30, 0, time.UTC) // A Thursday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"5/30 * * * *", time.Date(2026, 1, 15, 10, 35, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 1, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,20 * *", time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * 1", time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC)}, // Either day field matches
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@WEEKLY", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, schedule.Next(from), tt.expr)
+	}
+}
+
+func TestCronSchedule_NextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	schedule, err := ParseCron("0 9 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, "0 9 * * *", schedule.String())
+
+	next := schedule.Next(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2026, 1, 15, 14, 0, 0, 0, time.UTC), next.UTC())
+
+	// 2:30 doesn't exist on the day clocks go forward, so that day has no run
+	schedule, err = ParseCron("30 2 * * *")
+	require.NoError(t, err)
+	next = schedule.Next(time.Date(2026, 3, 7, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 3, 9, 2, 30, 0, 0, loc), next)
+}
+
+func TestCronSchedule_MinInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"* * * * *":       time.Minute,
+		"*/15 * * * *":    15 * time.Minute,
+		"0,50 * * * *":    10 * time.Minute,
+		"@hourly":         time.Hour,
+		"0 */6 * * *":     6 * time.Hour,
+		"55 23,0 * * *":   time.Hour,
+		"30 2 * * 1-5":    24 * time.Hour,
+		"@monthly":        24 * time.Hour,
+		"0 9,17 * * 1-5":  8 * time.Hour,
+		"0-4 12 1 jan *":  time.Minute,
+		"10 0 * * *":      24 * time.Hour,
+		"0 0,23 * * *":    time.Hour,
+		"0 1,3,4 * * sun": time.Hour,
+	}
+	for expr, want := range tests {
+		schedule, err := ParseCron(expr)
+		require.NoError(t, err)
+		assert.Equal(t, want, schedule.MinInterval(), expr)
+	}
+}
@@ -241,8 +241,10 @@ type ErrorRecoveryService struct {
 	gracefulShutdown *GracefulShutdown
 	circuitBreakers  *CircuitBreakerManager
 	logger           *Logger
-	healthChecks     map[string]func(context.Context) error
+	healthChecks     map[string]registeredHealthCheck
 	healthCheckMu    sync.RWMutex
+
+	healthCheckTimeout time.Duration // Bounds each check run by RunHealthChecks
 }
 
 // NewErrorRecoveryService creates a new error recovery service
@@ -256,7 +258,9 @@ func NewErrorRecoveryService(logger *Logger) *ErrorRecoveryService {
 		gracefulShutdown: NewGracefulShutdown(30*time.Second, logger),
 		circuitBreakers:  NewCircuitBreakerManager(logger),
 		logger:           logger,
-		healthChecks:     make(map[string]func(context.Context) error),
+		healthChecks:     make(map[string]registeredHealthCheck),
+
+		healthCheckTimeout: DefaultHealthCheckTimeout,
 	}
 }
 
@@ -270,11 +274,21 @@ func (ers *ErrorRecoveryService) RegisterShutdown(shutdownFunc func(context.Cont
 	ers.gracefulShutdown.RegisterShutdown(shutdownFunc)
 }
 
-// RegisterHealthCheck registers a health check function
+// RegisterHealthCheck registers a critical health check function; the server is unhealthy while it fails
 func (ers *ErrorRecoveryService) RegisterHealthCheck(name string, healthCheck func(context.Context) error) {
+	ers.registerHealthCheck(name, healthCheck, true)
+}
+
+// RegisterNonCriticalHealthCheck registers a health check function for an optional dependency;
+// the server is only degraded while it fails
+func (ers *ErrorRecoveryService) RegisterNonCriticalHealthCheck(name string, healthCheck func(context.Context) error) {
+	ers.registerHealthCheck(name, healthCheck, false)
+}
+
+func (ers *ErrorRecoveryService) registerHealthCheck(name string, healthCheck func(context.Context) error, critical bool) {
 	ers.healthCheckMu.Lock()
 	defer ers.healthCheckMu.Unlock()
-	ers.healthChecks[name] = healthCheck
+	ers.healthChecks[name] = registeredHealthCheck{check: healthCheck, critical: critical}
 }
 
 // GetCircuitBreaker gets or creates a circuit breaker
@@ -312,7 +326,7 @@ func (ers *ErrorRecoveryService) PerformHealthChecks(ctx context.Context) map[st
 				}
 			}()
 
-			results[name] = healthCheck(ctx)
+			results[name] = healthCheck.check(ctx)
 		}()
 	}
 
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckTimeout bounds each check run by RunHealthChecks, so one hung dependency
+// can't hold up the health endpoint
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// Overall statuses of a HealthReport
+const (
+	HealthStatusHealthy   = "healthy"   // Every check passed
+	HealthStatusDegraded  = "degraded"  // Only non-critical checks failed
+	HealthStatusUnhealthy = "unhealthy" // At least one critical check failed
+)
+
+// Statuses of a HealthCheckResult
+const (
+	HealthCheckOK     = "ok"
+	HealthCheckFailed = "failed"
+)
+
+// registeredHealthCheck is a health check and whether its failure makes the server unhealthy
+type registeredHealthCheck struct {
+	check    func(context.Context) error
+	critical bool
+}
+
+// HealthCheckResult is the outcome of one registered health check
+type HealthCheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReport aggregates every registered health check into an overall status
+type HealthReport struct {
+	Status    string                       `json:"status"`
+	Checks    map[string]HealthCheckResult `json:"checks"`
+	CheckedAt time.Time                    `json:"checked_at"`
+}
+
+// SetHealthCheckTimeout changes how long each check run by RunHealthChecks may take; a
+// non-positive timeout restores DefaultHealthCheckTimeout
+func (ers *ErrorRecoveryService) SetHealthCheckTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ers.healthCheckMu.Lock()
+	defer ers.healthCheckMu.Unlock()
+	ers.healthCheckTimeout = timeout
+}
+
+// Healthy reports whether every critical check passed
+func (r HealthReport) Healthy() bool {
+	return r.Status != HealthStatusUnhealthy
+}
+
+// RunHealthChecks runs every registered health check concurrently, each bounded by the health
+// check timeout, and aggregates them. A check that panics or times out fails.
+func (ers *ErrorRecoveryService) RunHealthChecks(ctx context.Context) HealthReport {
+	ers.healthCheckMu.RLock()
+	timeout := ers.healthCheckTimeout
+	checks := make(map[string]registeredHealthCheck, len(ers.healthChecks))
+	for name, healthCheck := range ers.healthChecks {
+		checks[name] = healthCheck
+	}
+	ers.healthCheckMu.RUnlock()
+
+	report := HealthReport{
+		Status:    HealthStatusHealthy,
+		Checks:    make(map[string]HealthCheckResult, len(checks)),
+		CheckedAt: time.Now(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, healthCheck := range checks {
+		wg.Add(1)
+		go func(name string, healthCheck registeredHealthCheck) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := runHealthCheck(ctx, healthCheck.check, timeout)
+			result := HealthCheckResult{
+				Status:   HealthCheckOK,
+				Critical: healthCheck.critical,
+				Duration: time.Since(start).String(),
+			}
+			if err != nil {
+				result.Status = HealthCheckFailed
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, healthCheck)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != HealthCheckFailed {
+			continue
+		}
+		if result.Critical {
+			report.Status = HealthStatusUnhealthy
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+
+	return report
+}
+
+// runHealthCheck runs check with a timeout, returning once it times out even if check ignores
+// its context
+func runHealthCheck(ctx context.Context, check func(context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("health check panicked: %v", r)
+			}
+		}()
+		result <- check(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("health check timed out: %w", ctx.Err())
+	}
+}
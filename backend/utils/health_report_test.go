@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorRecoveryService_RunHealthChecks(t *testing.T) {
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("dependency down") }
+
+	t.Run("every check passes", func(t *testing.T) {
+		service := NewErrorRecoveryService(nil)
+		service.RegisterHealthCheck("server", passing)
+		service.RegisterNonCriticalHealthCheck("ai", passing)
+
+		report := service.RunHealthChecks(context.Background())
+		assert.Equal(t, HealthStatusHealthy, report.Status)
+		assert.True(t, report.Healthy())
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, HealthCheckOK, report.Checks["server"].Status)
+		assert.True(t, report.Checks["server"].Critical)
+		assert.False(t, report.Checks["ai"].Critical)
+		assert.Empty(t, report.Checks["ai"].Error)
+		assert.WithinDuration(t, time.Now(), report.CheckedAt, time.Second)
+	})
+
+	t.Run("failing non-critical check degrades", func(t *testing.T) {
+		service := NewErrorRecoveryService(nil)
+		service.RegisterHealthCheck("server", passing)
+		service.RegisterNonCriticalHealthCheck("ai", failing)
+
+		report := service.RunHealthChecks(context.Background())
+		assert.Equal(t, HealthStatusDegraded, report.Status)
+		assert.True(t, report.Healthy())
+		assert.Equal(t, HealthCheckFailed, report.Checks["ai"].Status)
+		assert.Equal(t, "dependency down", report.Checks["ai"].Error)
+	})
+
+	t.Run("failing critical check makes the server unhealthy", func(t *testing.T) {
+		service := NewErrorRecoveryService(nil)
+		service.RegisterHealthCheck("websocket", failing)
+		service.RegisterNonCriticalHealthCheck("ai", failing)
+
+		report := service.RunHealthChecks(context.Background())
+		assert.Equal(t, HealthStatusUnhealthy, report.Status)
+		assert.False(t, report.Healthy())
+	})
+
+	t.Run("panicking and hung checks fail", func(t *testing.T) {
+		service := NewErrorRecoveryService(nil)
+		service.SetHealthCheckTimeout(20 * time.Millisecond)
+		service.RegisterHealthCheck("panic_check", func(ctx context.Context) error {
+			panic("health check panic")
+		})
+		release := make(chan struct{})
+		defer close(release)
+		service.RegisterNonCriticalHealthCheck("hung_check", func(ctx context.Context) error {
+			<-release // Ignores ctx
+			return nil
+		})
+
+		start := time.Now()
+		report := service.RunHealthChecks(context.Background())
+		assert.Less(t, time.Since(start), time.Second, "a hung check doesn't hold up the report")
+		assert.Equal(t, HealthStatusUnhealthy, report.Status)
+		assert.Contains(t, report.Checks["panic_check"].Error, "health check panicked")
+		assert.Contains(t, report.Checks["hung_check"].Error, "health check timed out")
+	})
+}
+
+func TestErrorRecoveryService_RegisterHealthCheckReplaces(t *testing.T) {
+	service := NewErrorRecoveryService(nil)
+	service.RegisterHealthCheck("ai", func(ctx context.Context) error { return errors.New("down") })
+	service.RegisterNonCriticalHealthCheck("ai", func(ctx context.Context) error { return errors.New("down") })
+
+	report := service.RunHealthChecks(context.Background())
+	require.Len(t, report.Checks, 1)
+	assert.False(t, report.Checks["ai"].Critical)
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+}
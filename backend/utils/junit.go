@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// JUnitRun is a completed test run in the shape needed for JUnit XML. It mirrors the
+// fields of models.TestResults, which utils can't import without creating a cycle.
+type JUnitRun struct {
+	Name      string
+	Status    string
+	Reason    string
+	Timestamp time.Time
+	Duration  time.Duration
+	Cases     []JUnitCase
+}
+
+// JUnitCase is a single test case of a JUnitRun
+type JUnitCase struct {
+	Name       string
+	Status     string // passed, failed or skipped
+	Duration   time.Duration
+	ErrorMsg   string
+	StackTrace string
+}
+
+// junitTestSuites is the <testsuites> root element
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a <testsuite> element
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty is a <property> element
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase is a <testcase> element
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+// junitFailure is a <failure> element; the stack trace, if any, is its text
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// MarshalJUnit renders a test run as JUnit XML with a single <testsuite> named after the run
+func MarshalJUnit(run JUnitRun) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:  run.Name,
+		Tests: len(run.Cases),
+		Time:  junitSeconds(run.Duration),
+		Cases: make([]junitTestCase, 0, len(run.Cases)),
+	}
+	if !run.Timestamp.IsZero() {
+		suite.Timestamp = run.Timestamp.UTC().Format("2006-01-02T15:04:05")
+	}
+	if run.Status != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "status", Value: run.Status})
+	}
+	if run.Reason != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "reason", Value: run.Reason})
+	}
+
+	for _, testCase := range run.Cases {
+		element := junitTestCase{
+			Name:      testCase.Name,
+			ClassName: run.Name,
+			Time:      junitSeconds(testCase.Duration),
+		}
+
+		switch testCase.Status {
+		case "failed":
+			suite.Failures++
+			message := testCase.ErrorMsg
+			if message == "" {
+				message = "Test failed"
+			}
+			element.Failure = &junitFailure{Message: message, Text: testCase.StackTrace}
+		case "skipped":
+			suite.Skipped++
+			element.Skipped = &struct{}{}
+		}
+
+		suite.Cases = append(suite.Cases, element)
+	}
+
+	root := junitTestSuites{
+		Name:     run.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	body, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// junitSeconds formats a duration as the seconds value JUnit expects
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
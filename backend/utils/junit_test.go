@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJUnit(t *testing.T) {
+	run := JUnitRun{
+		Name:      "run-1",
+		Status:    "failed",
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Duration:  12345 * time.Millisecond,
+		Cases: []JUnitCase{
+			{Name: "Auth logs in", Status: "passed", Duration: 1200 * time.Millisecond},
+			{Name: "Auth logs out", Status: "failed", Duration: 320 * time.Millisecond, ErrorMsg: `expected <button> to be "visible"`, StackTrace: "AssertionError\n    at Context.eval"},
+			{Name: "Auth resets password", Status: "skipped"},
+			{Name: "Checkout hook", Status: "failed"},
+		},
+	}
+
+	body, err := MarshalJUnit(run)
+	require.NoError(t, err)
+
+	output := string(body)
+	assert.True(t, strings.HasPrefix(output, xml.Header))
+
+	var parsed struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Name       string `xml:"name,attr"`
+			Tests      int    `xml:"tests,attr"`
+			Failures   int    `xml:"failures,attr"`
+			Skipped    int    `xml:"skipped,attr"`
+			Time       string `xml:"time,attr"`
+			Timestamp  string `xml:"timestamp,attr"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"properties>property"`
+			Cases []struct {
+				Name      string `xml:"name,attr"`
+				ClassName string `xml:"classname,attr"`
+				Time      string `xml:"time,attr"`
+				Failure   *struct {
+					Message string `xml:"message,attr"`
+					Text    string `xml:",chardata"`
+				} `xml:"failure"`
+				Skipped *struct{} `xml:"skipped"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &parsed))
+
+	assert.Equal(t, 4, parsed.Tests)
+	assert.Equal(t, 2, parsed.Failures)
+	assert.Equal(t, 1, parsed.Skipped)
+	require.Len(t, parsed.Suites, 1)
+
+	suite := parsed.Suites[0]
+	assert.Equal(t, "run-1", suite.Name)
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	assert.Equal(t, "12.345", suite.Time)
+	assert.Equal(t, "2024-01-15T10:30:00", suite.Timestamp)
+	require.Len(t, suite.Properties, 1)
+	assert.Equal(t, "status", suite.Properties[0].Name)
+	assert.Equal(t, "failed", suite.Properties[0].Value)
+	require.Len(t, suite.Cases, 4)
+
+	passed := suite.Cases[0]
+	assert.Equal(t, "Auth logs in", passed.Name)
+	assert.Equal(t, "run-1", passed.ClassName)
+	assert.Equal(t, "1.200", passed.Time)
+	assert.Nil(t, passed.Failure)
+	assert.Nil(t, passed.Skipped)
+
+	// Messages are escaped and survive a round trip
+	failed := suite.Cases[1]
+	require.NotNil(t, failed.Failure)
+	assert.Equal(t, `expected <button> to be "visible"`, failed.Failure.Message)
+	assert.Contains(t, failed.Failure.Text, "Context.eval")
+
+	assert.NotNil(t, suite.Cases[2].Skipped)
+
+	// A failure without a message still gets one
+	require.NotNil(t, suite.Cases[3].Failure)
+	assert.Equal(t, "Test failed", suite.Cases[3].Failure.Message)
+}
+
+func TestMarshalJUnit_EmptyRun(t *testing.T) {
+	body, err := MarshalJUnit(JUnitRun{Name: "run-2", Status: "failed", Reason: "timeout"})
+	require.NoError(t, err)
+
+	output := string(body)
+	assert.Contains(t, output, `<testsuites name="run-2" tests="0" failures="0" errors="0" skipped="0" time="0.000">`)
+	assert.Contains(t, output, `<property name="reason" value="timeout"></property>`)
+	assert.NotContains(t, output, "timestamp=")
+}
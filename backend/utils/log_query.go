@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// logFilterShortcuts are query parameters that set the custom filter of the same name, as
+// filter=name:value would
+var logFilterShortcuts = []string{"user_id", "session_id", "geo_country", "asn"}
+
+// LogAnalysisQuery holds the log analysis parameters of a query string. A field is left at its zero
+// value (nil for the integers) when its parameter is absent, so callers can tell which parameters
+// were given and let them override values from elsewhere.
+type LogAnalysisQuery struct {
+	StartTime   time.Time         // start_time, RFC3339
+	EndTime     time.Time         // end_time, RFC3339
+	Levels      []string          // levels, comma separated
+	Sources     []string          // sources, comma separated
+	Components  []string          // components, comma separated
+	SearchQuery string            // search_query, or its alias search
+	SearchMode  string            // search_mode
+	Filters     map[string]string // Repeated filter=key:value, plus the user_id, session_id, geo_country and asn shortcuts
+	GroupBy     string            // group_by
+
+	Limit              *int // limit
+	Offset             *int // offset
+	SpikeThreshold     *int // spike_threshold
+	SpikeWindowMinutes *int // spike_window_minutes
+}
+
+// ParseLogAnalysisQuery reads the log analysis parameters from c's query string. Unparseable values
+// are reported by parameter name in the returned map, which is nil when every parameter is valid.
+// Range checks are left to the caller's request validation.
+func ParseLogAnalysisQuery(c *fiber.Ctx) (*LogAnalysisQuery, map[string]string) {
+	query := &LogAnalysisQuery{Filters: make(map[string]string)}
+	validationErrors := make(map[string]string)
+
+	parseTime := func(param string, target *time.Time) {
+		value := c.Query(param)
+		if value == "" {
+			return
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			validationErrors[param] = "must be an RFC3339 timestamp"
+			return
+		}
+		*target = parsed
+	}
+	parseTime("start_time", &query.StartTime)
+	parseTime("end_time", &query.EndTime)
+
+	parseList := func(param string) []string {
+		if value := c.Query(param); value != "" {
+			return SplitAndTrim(value, ",")
+		}
+		return nil
+	}
+	query.Levels = parseList("levels")
+	query.Sources = parseList("sources")
+	query.Components = parseList("components")
+
+	query.SearchQuery = c.Query("search_query", c.Query("search"))
+	query.SearchMode = c.Query("search_mode")
+	query.GroupBy = c.Query("group_by")
+
+	parseInt := func(param string) *int {
+		value := c.Query(param)
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			validationErrors[param] = "must be an integer"
+			return nil
+		}
+		return &parsed
+	}
+	query.Limit = parseInt("limit")
+	query.Offset = parseInt("offset")
+	query.SpikeThreshold = parseInt("spike_threshold")
+	query.SpikeWindowMinutes = parseInt("spike_window_minutes")
+
+	for _, key := range logFilterShortcuts {
+		if value := c.Query(key); value != "" {
+			query.Filters[key] = value
+		}
+	}
+	for _, filter := range c.Context().QueryArgs().PeekMulti("filter") {
+		key, value, found := strings.Cut(string(filter), ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			validationErrors["filter"] = "must be key:value, got " + strconv.Quote(string(filter))
+			continue
+		}
+		query.Filters[key] = value
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, validationErrors
+	}
+	return query, nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseLogAnalysisQuery runs ParseLogAnalysisQuery against a request with the given query string
+func parseLogAnalysisQuery(t *testing.T, rawQuery string) (*LogAnalysisQuery, map[string]string) {
+	t.Helper()
+
+	var query *LogAnalysisQuery
+	var validationErrors map[string]string
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		query, validationErrors = ParseLogAnalysisQuery(c)
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/?"+rawQuery, nil))
+	require.NoError(t, err)
+	return query, validationErrors
+}
+
+func TestParseLogAnalysisQuery(t *testing.T) {
+	t.Run("every parameter", func(t *testing.T) {
+		query, validationErrors := parseLogAnalysisQuery(t,
+			"start_time=2024-01-15T10:00:00Z&end_time=2024-01-15T12:00:00Z"+
+				"&levels=error,+warn&sources=frontend&components=auth,payments"+
+				"&search_query=timeout&search_mode=regex&group_by=component&limit=50&offset=10"+
+				"&spike_threshold=5&spike_window_minutes=2"+
+				"&filter=region:eu&filter=url:https://app.example.com/a&user_id=u1")
+		require.Nil(t, validationErrors)
+
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), query.StartTime)
+		assert.Equal(t, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), query.EndTime)
+		assert.Equal(t, []string{"error", "warn"}, query.Levels)
+		assert.Equal(t, []string{"frontend"}, query.Sources)
+		assert.Equal(t, []string{"auth", "payments"}, query.Components)
+		assert.Equal(t, "timeout", query.SearchQuery)
+		assert.Equal(t, "regex", query.SearchMode)
+		assert.Equal(t, "component", query.GroupBy)
+		assert.Equal(t, map[string]string{
+			"region":  "eu",
+			"url":     "https://app.example.com/a",
+			"user_id": "u1",
+		}, query.Filters)
+		require.NotNil(t, query.Limit)
+		assert.Equal(t, 50, *query.Limit)
+		require.NotNil(t, query.Offset)
+		assert.Equal(t, 10, *query.Offset)
+		require.NotNil(t, query.SpikeThreshold)
+		assert.Equal(t, 5, *query.SpikeThreshold)
+		require.NotNil(t, query.SpikeWindowMinutes)
+		assert.Equal(t, 2, *query.SpikeWindowMinutes)
+	})
+
+	t.Run("absent parameters stay unset", func(t *testing.T) {
+		query, validationErrors := parseLogAnalysisQuery(t, "")
+		require.Nil(t, validationErrors)
+
+		assert.True(t, query.StartTime.IsZero())
+		assert.Nil(t, query.Levels)
+		assert.Empty(t, query.SearchQuery)
+		assert.Empty(t, query.Filters)
+		assert.Nil(t, query.Limit)
+		assert.Nil(t, query.Offset)
+	})
+
+	t.Run("search alias", func(t *testing.T) {
+		query, _ := parseLogAnalysisQuery(t, "search=component:auth")
+		assert.Equal(t, "component:auth", query.SearchQuery)
+
+		query, _ = parseLogAnalysisQuery(t, "search=ignored&search_query=used")
+		assert.Equal(t, "used", query.SearchQuery)
+	})
+
+	t.Run("filter parameters override shortcuts", func(t *testing.T) {
+		query, _ := parseLogAnalysisQuery(t, "user_id=u1&filter=user_id:u2")
+		assert.Equal(t, map[string]string{"user_id": "u2"}, query.Filters)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		query, validationErrors := parseLogAnalysisQuery(t, "start_time=yesterday&limit=ten&filter=region&filter=:eu&offset=5")
+		assert.Nil(t, query)
+		assert.Equal(t, map[string]string{
+			"start_time": "must be an RFC3339 timestamp",
+			"limit":      "must be an integer",
+			"filter":     `must be key:value, got ":eu"`,
+		}, validationErrors)
+	})
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated log files; it sorts in the order the files were rotated
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotatingFileWriter is an io.Writer appending to a log file. Once the file would grow past
+// maxSize it is renamed to <path>.<timestamp> and a new file is started. Only the newest maxFiles
+// rotated files younger than maxAge are kept. A zero limit disables it.
+type RotatingFileWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	maxAge   time.Duration
+	now      func() time.Time
+
+	file *os.File
+	size int64
+}
+
+// NewRotatingFileWriter opens path for appending, creating it and its directory if needed
+func NewRotatingFileWriter(path string, maxSizeMB, maxFiles, maxAgeDays int) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		path:     path,
+		maxSize:  int64(maxSizeMB) * 1024 * 1024,
+		maxFiles: maxFiles,
+		maxAge:   time.Duration(maxAgeDays) * 24 * time.Hour,
+		now:      time.Now,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the log file, first rotating it if p would take it past the size limit
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, fmt.Errorf("log file %s is closed", w.path)
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file; later writes fail
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the log file for appending, picking up the size of an existing file
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate renames the current file aside, starts a new one and removes backups past the limits;
+// the caller holds w.mu
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	backup := w.path + "." + w.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(w.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.removeOldBackups()
+	return nil
+}
+
+// removeOldBackups deletes rotated files beyond maxFiles or older than maxAge. Failures are
+// ignored, since a leftover backup shouldn't stop logging.
+func (w *RotatingFileWriter) removeOldBackups() {
+	backups := w.Backups()
+	cutoff := w.now().Add(-w.maxAge)
+
+	// Backups are oldest first, so the newest maxFiles are at the end
+	for i, backup := range backups {
+		expired := w.maxFiles > 0 && i < len(backups)-w.maxFiles
+		if !expired && w.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && info.ModTime().Before(cutoff) {
+				expired = true
+			}
+		}
+		if expired {
+			os.Remove(backup)
+		}
+	}
+}
+
+// Backups returns the paths of the rotated log files, oldest first
+func (w *RotatingFileWriter) Backups() []string {
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return nil
+	}
+
+	backups := make([]string, 0, len(matches))
+	prefix := w.path + "."
+	for _, match := range matches {
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(match, prefix)); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	return backups
+}
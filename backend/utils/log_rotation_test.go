@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRotatingWriter returns a writer rotating path past maxSize bytes, with a clock advanced a
+// second per rotation so backups get distinct names
+func newTestRotatingWriter(t *testing.T, path string, maxSize int64, maxFiles int) *RotatingFileWriter {
+	w, err := NewRotatingFileWriter(path, 0, maxFiles, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { w.Close() })
+
+	w.maxSize = maxSize
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return w
+}
+
+func TestRotatingFileWriter_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	w := newTestRotatingWriter(t, path, 10, 0)
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	backups := w.Backups()
+	require.Len(t, backups, 2)
+	assert.True(t, strings.HasPrefix(backups[0], path+".20240115T100001"))
+	for i, expected := range []string{"first\n", "second\n"} {
+		data, err := os.ReadFile(backups[i])
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(data))
+}
+
+func TestRotatingFileWriter_OversizedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	w := newTestRotatingWriter(t, path, 4, 0)
+
+	// A write larger than the limit still goes to an empty file whole
+	_, err := w.Write([]byte("much too long\n"))
+	require.NoError(t, err)
+	assert.Empty(t, w.Backups())
+}
+
+func TestRotatingFileWriter_MaxFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	w := newTestRotatingWriter(t, path, 5, 2)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	backups := w.Backups()
+	require.Len(t, backups, 2, "only the newest backups are kept")
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", string(data))
+}
+
+func TestRotatingFileWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	stale := path + ".20230101T000000.000000000"
+	require.NoError(t, os.WriteFile(stale, []byte("old\n"), 0o644))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(stale, old, old))
+	unrelated := filepath.Join(dir, "server.log.bak")
+	require.NoError(t, os.WriteFile(unrelated, nil, 0o644))
+
+	w := newTestRotatingWriter(t, path, 5, 0)
+	w.maxAge = 24 * time.Hour
+	w.now = time.Now
+	_, err := w.Write([]byte("one\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("two\n"))
+	require.NoError(t, err)
+
+	backups := w.Backups()
+	require.Len(t, backups, 1)
+	assert.NotEqual(t, stale, backups[0])
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, unrelated, "files not named like backups are left alone")
+}
+
+func TestRotatingFileWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o644))
+
+	// The size of an existing file counts towards the limit
+	w := newTestRotatingWriter(t, path, 12, 0)
+	_, err := w.Write([]byte("next\n"))
+	require.NoError(t, err)
+	require.Len(t, w.Backups(), 1)
+
+	require.NoError(t, w.Close())
+	_, err = w.Write([]byte("closed\n"))
+	assert.Error(t, err)
+}
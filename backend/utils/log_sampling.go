@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSamplingInterval is the sampling period used when a policy leaves Interval unset
+const defaultSamplingInterval = time.Second
+
+// LogSamplingPolicy throttles repeated log entries. Each interval, the first First entries of a
+// level and source are logged, then every Thereafter-th one. Errors are never sampled.
+type LogSamplingPolicy struct {
+	First      int           // Entries logged per level and source each interval before sampling starts
+	Thereafter int           // After First, every Thereafter-th entry is logged; 0 drops the rest
+	Interval   time.Duration // Period after which the counts start over; 0 uses one second
+}
+
+// logSampler counts the entries of each level and source in the current interval
+type logSampler struct {
+	policy LogSamplingPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	counters map[logSampleKey]*logSampleCounter
+
+	dropped atomic.Int64
+}
+
+type logSampleKey struct {
+	level  LogLevel
+	source string
+}
+
+type logSampleCounter struct {
+	intervalStart time.Time
+	count         int
+}
+
+// WithSampling makes the logger sample entries by policy and returns it, so it can be chained onto
+// NewLogger. Loggers derived with WithTraceID or WithSource share the sampling.
+func (l *Logger) WithSampling(policy LogSamplingPolicy) *Logger {
+	if policy.Interval <= 0 {
+		policy.Interval = defaultSamplingInterval
+	}
+	l.sampler.Store(&logSampler{
+		policy:   policy,
+		now:      time.Now,
+		counters: make(map[logSampleKey]*logSampleCounter),
+	})
+	return l
+}
+
+// SampledEntries returns how many entries sampling has dropped
+func (l *Logger) SampledEntries() int64 {
+	if sampler := l.sampler.Load(); sampler != nil {
+		return sampler.dropped.Load()
+	}
+	return 0
+}
+
+// sample reports whether an entry of level from source is logged
+func (l *Logger) sample(level LogLevel, source string) bool {
+	sampler := l.sampler.Load()
+	if sampler == nil || level >= ERROR {
+		return true
+	}
+	if sampler.allow(logSampleKey{level: level, source: source}) {
+		return true
+	}
+	sampler.dropped.Add(1)
+	return false
+}
+
+// allow counts an entry for key and reports whether the policy logs it
+func (s *logSampler) allow(key logSampleKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	counter, exists := s.counters[key]
+	if !exists || now.Sub(counter.intervalStart) >= s.policy.Interval {
+		counter = &logSampleCounter{intervalStart: now}
+		s.counters[key] = counter
+	}
+	counter.count++
+
+	if counter.count <= s.policy.First {
+		return true
+	}
+	return s.policy.Thereafter > 0 && (counter.count-s.policy.First)%s.policy.Thereafter == 0
+}
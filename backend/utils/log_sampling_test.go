@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSampledLogger returns a debug logger sampling by policy into buf, with a clock set by the caller
+func newSampledLogger(policy LogSamplingPolicy, buf *bytes.Buffer, now *time.Time) *Logger {
+	logger := NewLogger("debug", "text").WithSampling(policy)
+	logger.SetOutput(buf)
+	logger.sampler.Load().now = func() time.Time { return *now }
+	return logger
+}
+
+func TestLogger_WithSampling(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	logger := newSampledLogger(LogSamplingPolicy{First: 10, Thereafter: 5, Interval: time.Second}, &buf, &now)
+	count := func(message string) int { return strings.Count(buf.String(), message) }
+
+	// Flood one level and source: the first 10 pass, then 1 in 5 of the remaining 90
+	for i := 0; i < 100; i++ {
+		logger.WithSource("http").Info("Request completed")
+	}
+	assert.Equal(t, 28, count("Request completed"))
+	assert.Equal(t, int64(72), logger.SampledEntries())
+
+	// Other levels and sources are counted separately
+	for i := 0; i < 10; i++ {
+		logger.WithSource("http").Debug("Cache hit")
+		logger.WithSource("websocket").Info("Message sent")
+		logger.Info("Unsourced")
+	}
+	assert.Equal(t, 10, count("Cache hit"))
+	assert.Equal(t, 10, count("Message sent"))
+	assert.Equal(t, 10, count("Unsourced"))
+
+	// Errors always pass
+	for i := 0; i < 100; i++ {
+		logger.WithSource("http").Error("Request failed", nil)
+	}
+	assert.Equal(t, 100, count("Request failed"))
+
+	// The counts start over each interval
+	now = now.Add(time.Second)
+	buf.Reset()
+	for i := 0; i < 10; i++ {
+		logger.WithSource("http").Info("Request completed")
+	}
+	assert.Equal(t, 10, count("Request completed"))
+}
+
+func TestLogger_WithSampling_DropAfterFirst(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	logger := newSampledLogger(LogSamplingPolicy{First: 3}, &buf, &now)
+
+	for i := 0; i < 50; i++ {
+		logger.Warn("Slow query")
+	}
+	assert.Equal(t, 3, strings.Count(buf.String(), "Slow query"))
+	assert.Equal(t, int64(47), logger.SampledEntries())
+
+	// An unset interval defaults to a second
+	now = now.Add(999 * time.Millisecond)
+	logger.Warn("Slow query")
+	assert.Equal(t, 3, strings.Count(buf.String(), "Slow query"))
+	now = now.Add(time.Millisecond)
+	logger.Warn("Slow query")
+	assert.Equal(t, 4, strings.Count(buf.String(), "Slow query"))
+}
+
+func TestLogger_WithoutSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger("info", "text")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 500; i++ {
+		logger.Info("Request completed")
+	}
+	assert.Equal(t, 500, strings.Count(buf.String(), "Request completed"))
+	assert.Zero(t, logger.SampledEntries())
+}
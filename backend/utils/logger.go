@@ -2,10 +2,15 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -37,6 +42,40 @@ func (l LogLevel) String() string {
 	}
 }
 
+// Output formats supported by Logger
+const (
+	LogFormatJSON    = "json"
+	LogFormatText    = "text"
+	LogFormatConsole = "console" // Text with colorized levels, for interactive debugging
+)
+
+// Log output targets other than a file path
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+)
+
+// LogOutputConfig selects where a Logger writes and how a log file is rotated
+type LogOutputConfig struct {
+	Output     string // stdout, stderr or a file path; empty writes to stdout
+	MaxSizeMB  int    // Size at which the log file is rotated; 0 never rotates it
+	MaxFiles   int    // Rotated files kept; 0 keeps every file
+	MaxAgeDays int    // Days rotated files are kept; 0 keeps them regardless of age
+}
+
+// ErrUnknownLogFormat is returned when switching to a format Logger doesn't support
+var ErrUnknownLogFormat = errors.New("unknown log format")
+
+// levelColors are the ANSI colors used for each level in console output
+var levelColors = map[string]string{
+	"DEBUG": "\033[90m", // Gray
+	"INFO":  "\033[36m", // Cyan
+	"WARN":  "\033[33m", // Yellow
+	"ERROR": "\033[31m", // Red
+}
+
+const colorReset = "\033[0m"
+
 // LogEntry represents a structured log entry
 type LogEntry struct {
 	Timestamp time.Time              `json:"timestamp"`
@@ -53,20 +92,95 @@ type LogEntry struct {
 // Logger represents a structured logger
 type Logger struct {
 	level  LogLevel
-	format string // "json" or "text"
+	format atomic.Value // "json", "text" or "console"; may be switched while logging
+
+	mu  sync.Mutex // Serializes writes, so entries are never interleaved
+	out io.Writer  // Where entries are written; nil writes to os.Stdout
+
+	sampler atomic.Pointer[logSampler] // Throttles repeated entries; nil logs every entry, see log_sampling.go
+}
+
+// NewLogger creates a new logger instance writing to stdout, or to the output given. When the
+// output file can't be opened, the logger writes to stdout and logs a warning.
+func NewLogger(level, format string, output ...LogOutputConfig) *Logger {
+	logger := &Logger{
+		level: parseLogLevel(level),
+	}
+	if logger.SetFormat(format) != nil {
+		logger.format.Store(LogFormatJSON)
+	}
+
+	if len(output) > 0 {
+		out, err := OpenLogOutput(output[0])
+		if err != nil {
+			logger.Warn("Log output unavailable, logging to stdout", map[string]interface{}{
+				"output": output[0].Output,
+				"error":  err.Error(),
+			})
+		} else {
+			logger.out = out
+		}
+	}
+	return logger
+}
+
+// OpenLogOutput returns the writer for an output: os.Stdout, os.Stderr or a RotatingFileWriter
+func OpenLogOutput(cfg LogOutputConfig) (io.Writer, error) {
+	switch cfg.Output {
+	case "", LogOutputStdout:
+		return os.Stdout, nil
+	case LogOutputStderr:
+		return os.Stderr, nil
+	default:
+		return NewRotatingFileWriter(cfg.Output, cfg.MaxSizeMB, cfg.MaxFiles, cfg.MaxAgeDays)
+	}
+}
+
+// SetOutput replaces the writer entries are written to, e.g. with a buffer in tests. The previous
+// writer is not closed.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = w
+}
+
+// Close closes the logger's log file, if it writes to one. Later entries go to stdout.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	closer, ok := l.out.(io.Closer)
+	if !ok || l.out == os.Stdout || l.out == os.Stderr {
+		return nil
+	}
+	l.out = nil
+	return closer.Close()
+}
+
+// Format returns the current output format
+func (l *Logger) Format() string {
+	return l.format.Load().(string)
 }
 
-// NewLogger creates a new logger instance
-func NewLogger(level, format string) *Logger {
-	logLevel := parseLogLevel(level)
-	if format != "json" && format != "text" {
-		format = "json"
+// SetFormat switches the output format; it takes effect for the next entry logged
+func (l *Logger) SetFormat(format string) error {
+	switch format {
+	case LogFormatJSON, LogFormatText, LogFormatConsole:
+		l.format.Store(format)
+		return nil
+	default:
+		return fmt.Errorf("%w %q: expected json, text or console", ErrUnknownLogFormat, format)
 	}
+}
 
-	return &Logger{
-		level:  logLevel,
-		format: format,
+// ToggleFormat switches between JSON and colorized console output and returns the new format
+func (l *Logger) ToggleFormat() string {
+	format := LogFormatConsole
+	if l.Format() != LogFormatJSON {
+		format = LogFormatJSON
 	}
+	l.format.Store(format)
+	return format
 }
 
 // parseLogLevel parses string log level to LogLevel enum
@@ -112,7 +226,7 @@ func (l *Logger) Error(message string, err error, context ...map[string]interfac
 // log performs the actual logging
 func (l *Logger) log(level LogLevel, message, errorMsg string, context ...map[string]interface{}) {
 	// Skip if log level is below configured level
-	if level < l.level {
+	if level < l.level || !l.sample(level, "") {
 		return
 	}
 
@@ -147,12 +261,15 @@ func (l *Logger) log(level LogLevel, message, errorMsg string, context ...map[st
 	l.output(entry)
 }
 
-// output writes the log entry to stdout
+// output writes the log entry to the logger's output
 func (l *Logger) output(entry LogEntry) {
-	if l.format == "json" {
+	switch l.Format() {
+	case LogFormatJSON:
 		l.outputJSON(entry)
-	} else {
-		l.outputText(entry)
+	case LogFormatConsole:
+		l.write(formatText(entry, true))
+	default:
+		l.write(formatText(entry, false))
 	}
 }
 
@@ -163,15 +280,34 @@ func (l *Logger) outputJSON(entry LogEntry) {
 		log.Printf("Error marshaling log entry: %v", err)
 		return
 	}
-	fmt.Println(string(jsonData))
+	l.write(string(jsonData))
+}
+
+// write writes one line to the logger's output
+func (l *Logger) write(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := l.out
+	if out == nil {
+		out = os.Stdout
+	}
+	if _, err := fmt.Fprintln(out, line); err != nil {
+		log.Printf("Error writing log entry: %v", err)
+	}
 }
 
-// outputText outputs log entry in human-readable text format
-func (l *Logger) outputText(entry LogEntry) {
+// formatText renders a log entry in human-readable text format, optionally coloring the level
+func formatText(entry LogEntry, colorize bool) string {
 	timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
 
+	level := entry.Level
+	if color, ok := levelColors[level]; ok && colorize {
+		level = color + level + colorReset
+	}
+
 	var output strings.Builder
-	output.WriteString(fmt.Sprintf("[%s] %s: %s", timestamp, entry.Level, entry.Message))
+	output.WriteString(fmt.Sprintf("[%s] %s: %s", timestamp, level, entry.Message))
 
 	if entry.TraceID != "" {
 		output.WriteString(fmt.Sprintf(" [trace_id=%s]", entry.TraceID))
@@ -194,7 +330,7 @@ func (l *Logger) outputText(entry LogEntry) {
 		output.WriteString(fmt.Sprintf(" [context=%s]", string(contextStr)))
 	}
 
-	fmt.Println(output.String())
+	return output.String()
 }
 
 // WithTraceID adds trace ID to log entry
@@ -290,7 +426,7 @@ func (lwc *LoggerWithContext) Error(message string, err error, context ...map[st
 // logWithContext performs logging with additional context
 func (lwc *LoggerWithContext) logWithContext(level LogLevel, message, errorMsg string, context ...map[string]interface{}) {
 	// Skip if log level is below configured level
-	if level < lwc.logger.level {
+	if level < lwc.logger.level || !lwc.logger.sample(level, lwc.source) {
 		return
 	}
 
@@ -336,9 +472,9 @@ func (lwc *LoggerWithContext) logWithContext(level LogLevel, message, errorMsg s
 // Global logger instance
 var globalLogger *Logger
 
-// InitLogger initializes the global logger
-func InitLogger(level, format string) {
-	globalLogger = NewLogger(level, format)
+// InitLogger initializes the global logger, writing to stdout or to the output given
+func InitLogger(level, format string, output ...LogOutputConfig) {
+	globalLogger = NewLogger(level, format, output...)
 }
 
 // GetLogger returns the global logger instance
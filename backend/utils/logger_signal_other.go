@@ -0,0 +1,6 @@
+//go:build !unix
+
+package utils
+
+// WatchFormatSignal is a no-op on platforms without SIGUSR1; use PUT /debug/log-format instead
+func WatchFormatSignal(logger *Logger) {}
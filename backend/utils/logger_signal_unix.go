@@ -0,0 +1,26 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchFormatSignal toggles the logger between JSON and colorized console output each time
+// the process receives SIGUSR1, e.g. `kill -USR1 <pid>` during a debugging session
+func WatchFormatSignal(logger *Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+
+	go func() {
+		for range signals {
+			format := logger.ToggleFormat()
+			logger.Info("Log format switched", map[string]interface{}{
+				"format": format,
+				"signal": "SIGUSR1",
+			})
+		}
+	}()
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package utils
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFormatSignal(t *testing.T) {
+	logger := NewLogger("error", "json")
+	WatchFormatSignal(logger)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return logger.Format() == LogFormatConsole
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return logger.Format() == LogFormatJSON
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger_Format(t *testing.T) {
+	assert.Equal(t, LogFormatText, NewLogger("info", "text").Format())
+	assert.Equal(t, LogFormatConsole, NewLogger("info", "console").Format())
+	// Unknown formats fall back to JSON
+	assert.Equal(t, LogFormatJSON, NewLogger("info", "xml").Format())
+}
+
+func TestLogger_SetFormat(t *testing.T) {
+	logger := NewLogger("info", "json")
+
+	assert.NoError(t, logger.SetFormat(LogFormatConsole))
+	assert.Equal(t, LogFormatConsole, logger.Format())
+
+	err := logger.SetFormat("yaml")
+	assert.ErrorIs(t, err, ErrUnknownLogFormat)
+	assert.Equal(t, LogFormatConsole, logger.Format())
+}
+
+func TestLogger_ToggleFormat(t *testing.T) {
+	logger := NewLogger("info", "json")
+
+	assert.Equal(t, LogFormatConsole, logger.ToggleFormat())
+	assert.Equal(t, LogFormatJSON, logger.ToggleFormat())
+
+	// Plain text toggles back to JSON
+	logger.SetFormat(LogFormatText)
+	assert.Equal(t, LogFormatJSON, logger.ToggleFormat())
+}
+
+func TestFormatText(t *testing.T) {
+	entry := LogEntry{
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Level:     "WARN",
+		Message:   "Slow query",
+		TraceID:   "trace-1",
+		Context:   map[string]interface{}{"duration_ms": 1200},
+	}
+
+	assert.Equal(t, `[2024-01-15 10:30:00] WARN: Slow query [trace_id=trace-1] [context={"duration_ms":1200}]`, formatText(entry, false))
+	assert.Equal(t, "[2024-01-15 10:30:00] \033[33mWARN\033[0m: Slow query [trace_id=trace-1] [context={\"duration_ms\":1200}]", formatText(entry, true))
+
+	entry.Level = "ERROR"
+	assert.Contains(t, formatText(entry, true), "\033[31mERROR\033[0m")
+}
+
+func TestLogger_SetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger("info", "json")
+	logger.SetOutput(&buf)
+
+	logger.Debug("below the level")
+	logger.WithTraceID("trace-1").WithSource("api").Info("Request handled", map[string]interface{}{"status": 200})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	var entry LogEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "Request handled", entry.Message)
+	assert.Equal(t, "trace-1", entry.TraceID)
+	assert.Equal(t, "api", entry.Source)
+	assert.Equal(t, float64(200), entry.Context["status"])
+
+	buf.Reset()
+	logger.SetFormat(LogFormatText)
+	logger.Warn("Disk filling up")
+	assert.Contains(t, buf.String(), "WARN: Disk filling up")
+}
+
+func TestNewLogger_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.log")
+	logger := NewLogger("info", "json", LogOutputConfig{Output: path, MaxSizeMB: 1, MaxFiles: 2})
+	logger.Info("Written to the file")
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"message":"Written to the file"`)
+	assert.NoError(t, logger.Close(), "closing twice is harmless")
+}
+
+func TestOpenLogOutput(t *testing.T) {
+	out, err := OpenLogOutput(LogOutputConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, os.Stdout, out)
+
+	out, err = OpenLogOutput(LogOutputConfig{Output: LogOutputStderr})
+	require.NoError(t, err)
+	assert.Equal(t, os.Stderr, out)
+
+	// A path under a regular file can't be created
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	_, err = OpenLogOutput(LogOutputConfig{Output: filepath.Join(file, "server.log")})
+	assert.Error(t, err)
+}
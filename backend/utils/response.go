@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -68,6 +69,23 @@ func ErrorResponse(c *fiber.Ctx, statusCode int, code, message string, details m
 	return c.Status(statusCode).JSON(response)
 }
 
+// ErrorResponseWithData creates an error response that also carries a data payload, for
+// failures the client needs details of to correct (e.g. per-item errors of a batch)
+func ErrorResponseWithData(c *fiber.Ctx, statusCode int, code, message string, data interface{}) error {
+	response := StandardResponse{
+		Success: false,
+		Message: "Request failed",
+		Data:    data,
+		Error: &ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Timestamp: time.Now(),
+		TraceID:   getTraceID(c),
+	}
+	return c.Status(statusCode).JSON(response)
+}
+
 // ValidationErrorResponse creates a validation error response
 func ValidationErrorResponse(c *fiber.Ctx, errors map[string]string) error {
 	return ErrorResponse(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Validation failed", errors)
@@ -184,3 +202,41 @@ func SetTraceID(c *fiber.Ctx, traceID string) {
 func GetTraceID(c *fiber.Ctx) string {
 	return getTraceID(c)
 }
+
+// traceIDKey is the context key used to carry trace IDs into services
+type traceIDKey struct{}
+
+// ContextWithTraceID returns a copy of ctx carrying the given trace ID
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID carried by ctx, or an empty string
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return traceID
+	}
+	return ""
+}
+
+// userIDKey is the context key used to carry the authenticated user ID into services
+type userIDKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying the ID of the authenticated user
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user ID carried by ctx, or an empty string
+func UserIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if userID, ok := ctx.Value(userIDKey{}).(string); ok {
+		return userID
+	}
+	return ""
+}
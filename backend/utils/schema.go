@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of generated schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document, or a subschema of one
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinProperties        *int                   `json:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty"`
+}
+
+// GenerateJSONSchema describes the JSON encoding of v's type, a struct or pointer to one. Property
+// names come from json tags and constraints from the validate tags understood by Validator:
+// required, min, max, len, gte, lte, oneof, url and email. Other rules are left out, as Validator
+// skips them too.
+func GenerateJSONSchema(title string, v interface{}) *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+	schema.Schema = JSONSchemaDraft
+	schema.Title = title
+	return schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaForType returns the schema of a type; inProgress holds the structs being described, so a
+// recursive struct refers back to itself as a plain object
+func schemaForType(t reflect.Type, inProgress map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer", Minimum: floatPtr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"} // encoding/json writes []byte as base64
+		}
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem(), inProgress)}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), inProgress)}
+	case reflect.Struct:
+		if inProgress[t] {
+			return &JSONSchema{Type: "object"}
+		}
+		inProgress[t] = true
+		defer delete(inProgress, t)
+
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		addStructProperties(schema, t, inProgress)
+		return schema
+	default:
+		// interface{} and anything else without a fixed JSON type accepts any value
+		return &JSONSchema{}
+	}
+}
+
+// addStructProperties adds the exported fields of struct type t to schema, flattening embedded
+// structs the way encoding/json does
+func addStructProperties(schema *JSONSchema, t reflect.Type, inProgress map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			addStructProperties(schema, fieldType, inProgress)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaForType(field.Type, inProgress)
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyValidateTag adds the constraints of a validate tag to a property's schema, reporting
+// whether the tag makes the property required
+func applyValidateTag(property *JSONSchema, tag string) bool {
+	required := false
+	omitEmpty := false
+
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "dive":
+			// Rules after dive apply to each element, not to the collection itself
+			if property.Items != nil {
+				applyValidateTag(property.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "omitempty":
+			omitEmpty = true
+		case "required":
+			required = true
+			switch property.Type {
+			case "string":
+				if property.Format == "" {
+					property.Pattern = `\S` // Validator rejects blank strings
+				}
+			case "array":
+				property.MinItems = intPtr(1)
+			case "object":
+				if property.AdditionalProperties != nil {
+					property.MinProperties = intPtr(1)
+				}
+			}
+		case "min", "max", "len":
+			bound, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			// An omitted value skips validation, so a minimum length can't apply to ""
+			if name != "max" && !(omitEmpty && property.Type == "string") {
+				property.setLowerBound(bound)
+			}
+			if name != "min" {
+				property.setUpperBound(bound)
+			}
+		case "gte", "lte":
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			if name == "gte" {
+				property.Minimum = floatPtr(bound)
+			} else {
+				property.Maximum = floatPtr(bound)
+			}
+		case "oneof":
+			property.Enum = strings.Fields(param)
+			if omitEmpty {
+				property.Enum = append(property.Enum, "")
+			}
+		case "enum":
+			if values, ok := lookupEnum(param); ok {
+				property.Enum = values
+				if omitEmpty {
+					property.Enum = append(property.Enum, "")
+				}
+			}
+		case "url":
+			property.Format = "uri"
+		case "email":
+			property.Format = "email"
+		case "regexp":
+			if pattern, ok := lookupPattern(param); ok {
+				property.Pattern = pattern.String()
+			}
+		}
+	}
+
+	return required
+}
+
+// setLowerBound applies a min rule, which bounds a string's length, a number's value or a
+// collection's size
+func (s *JSONSchema) setLowerBound(bound int) {
+	switch s.Type {
+	case "string":
+		s.MinLength = intPtr(bound)
+	case "integer", "number":
+		s.Minimum = floatPtr(float64(bound))
+	case "array":
+		s.MinItems = intPtr(bound)
+	case "object":
+		s.MinProperties = intPtr(bound)
+	}
+}
+
+// setUpperBound applies a max rule, see setLowerBound
+func (s *JSONSchema) setUpperBound(bound int) {
+	switch s.Type {
+	case "string":
+		s.MaxLength = intPtr(bound)
+	case "integer", "number":
+		s.Maximum = floatPtr(float64(bound))
+	case "array":
+		s.MaxItems = intPtr(bound)
+	case "object":
+		s.MaxProperties = intPtr(bound)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
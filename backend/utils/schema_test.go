@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaTestBase struct {
+	ID        string    `json:"id" validate:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type schemaTestNode struct {
+	Name     string            `json:"name"`
+	Children []*schemaTestNode `json:"children"`
+}
+
+type schemaTestRequest struct {
+	schemaTestBase
+	Name     string                 `json:"name" validate:"required,min=3,max=50"`
+	Level    string                 `json:"level" validate:"omitempty,oneof=low high"`
+	Severity string                 `json:"severity" validate:"required,enum=loglevel"`
+	Code     string                 `json:"code" validate:"omitempty,len=4"`
+	Homepage string                 `json:"homepage" validate:"omitempty,url"`
+	Env      string                 `json:"env" validate:"omitempty,regexp=envname"`
+	Email    string                 `json:"email" validate:"email"`
+	Retries  int                    `json:"retries" validate:"min=0,max=5"`
+	Ratio    float64                `json:"ratio" validate:"gte=0,lte=1"`
+	Count    uint                   `json:"count"`
+	Enabled  *bool                  `json:"enabled"`
+	Tags     []string               `json:"tags" validate:"required,max=10"`
+	Labels   map[string]string      `json:"labels" validate:"required"`
+	Aliases  []string               `json:"aliases" validate:"omitempty,max=5,dive,required,max=20"`
+	Extra    map[string]interface{} `json:"extra"`
+	Payload  []byte                 `json:"payload"`
+	Tree     schemaTestNode         `json:"tree"`
+	Untagged string
+	Secret   string `json:"-"`
+	internal string
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema := GenerateJSONSchema("schemaTestRequest", &schemaTestRequest{})
+
+	assert.Equal(t, JSONSchemaDraft, schema.Schema)
+	assert.Equal(t, "schemaTestRequest", schema.Title)
+	assert.Equal(t, "object", schema.Type)
+	assert.ElementsMatch(t, []string{"id", "name", "severity", "tags", "labels"}, schema.Required)
+
+	t.Run("embedded structs are flattened", func(t *testing.T) {
+		assert.Equal(t, &JSONSchema{Type: "string", Pattern: `\S`}, schema.Properties["id"])
+		assert.Equal(t, &JSONSchema{Type: "string", Format: "date-time"}, schema.Properties["created_at"])
+	})
+
+	t.Run("skipped fields", func(t *testing.T) {
+		assert.Contains(t, schema.Properties, "Untagged")
+		assert.NotContains(t, schema.Properties, "Secret")
+		assert.NotContains(t, schema.Properties, "internal")
+		assert.Len(t, schema.Properties, 20)
+	})
+
+	tests := []struct {
+		property string
+		expected *JSONSchema
+	}{
+		{property: "name", expected: &JSONSchema{Type: "string", Pattern: `\S`, MinLength: intPtr(3), MaxLength: intPtr(50)}},
+		{property: "level", expected: &JSONSchema{Type: "string", Enum: []string{"low", "high", ""}}},
+		{property: "severity", expected: &JSONSchema{Type: "string", Pattern: `\S`, Enum: []string{"error", "warn", "info", "debug", "trace"}}},
+		{property: "code", expected: &JSONSchema{Type: "string", MaxLength: intPtr(4)}},
+		{property: "homepage", expected: &JSONSchema{Type: "string", Format: "uri"}},
+		{property: "env", expected: &JSONSchema{Type: "string", Pattern: "^[a-z0-9-]+$"}},
+		{property: "email", expected: &JSONSchema{Type: "string", Format: "email"}},
+		{property: "retries", expected: &JSONSchema{Type: "integer", Minimum: floatPtr(0), Maximum: floatPtr(5)}},
+		{property: "ratio", expected: &JSONSchema{Type: "number", Minimum: floatPtr(0), Maximum: floatPtr(1)}},
+		{property: "count", expected: &JSONSchema{Type: "integer", Minimum: floatPtr(0)}},
+		{property: "enabled", expected: &JSONSchema{Type: "boolean"}},
+		{property: "tags", expected: &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}, MinItems: intPtr(1), MaxItems: intPtr(10)}},
+		{property: "aliases", expected: &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string", Pattern: `\S`, MaxLength: intPtr(20)}, MaxItems: intPtr(5)}},
+		{property: "labels", expected: &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}, MinProperties: intPtr(1)}},
+		{property: "extra", expected: &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{}}},
+		{property: "payload", expected: &JSONSchema{Type: "string", Format: "byte"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			assert.Equal(t, tt.expected, schema.Properties[tt.property])
+		})
+	}
+
+	t.Run("recursive structs", func(t *testing.T) {
+		tree := schema.Properties["tree"]
+		require.NotNil(t, tree)
+		children := tree.Properties["children"]
+		require.NotNil(t, children)
+		assert.Equal(t, &JSONSchema{Type: "object"}, children.Items)
+	})
+}
+
+func TestGenerateJSONSchema_Encoding(t *testing.T) {
+	encoded, err := json.Marshal(GenerateJSONSchema("schemaTestNode", schemaTestNode{}))
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, JSONSchemaDraft, decoded["$schema"])
+	assert.Equal(t, "object", decoded["type"])
+	assert.NotContains(t, decoded, "required", "empty keywords are omitted")
+	assert.NotContains(t, decoded, "enum")
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -31,6 +32,73 @@ type Validator struct {
 	errors map[string]ValidationError
 }
 
+// Patterns used by the regexp rule, keyed by name. Tags name a pattern rather than spelling it out
+// because rules are split on "," and "=", and so each pattern is compiled once.
+var (
+	patterns = map[string]*regexp.Regexp{
+		"envname": regexp.MustCompile(`^[a-z0-9-]+$`),
+	}
+	patternsMu sync.RWMutex
+)
+
+// Value sets used by the enum rule, keyed by name. Unlike oneof, a set can be replaced at startup,
+// so values taken from configuration are validated, and published in schemas, as configured.
+var (
+	enums = map[string][]string{
+		"loglevel": {"error", "warn", "info", "debug", "trace"},
+	}
+	enumsMu sync.RWMutex
+)
+
+// RegisterPattern compiles expr and registers it for the regexp rule under name, so a field tagged
+// validate:"regexp=<name>" must match it. Registering an existing name replaces its pattern.
+func RegisterPattern(name, expr string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, ",=") {
+		return fmt.Errorf("invalid pattern name %q", name)
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", name, err)
+	}
+
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	patterns[name] = pattern
+	return nil
+}
+
+// lookupPattern returns the pattern registered under name
+func lookupPattern(name string) (*regexp.Regexp, bool) {
+	patternsMu.RLock()
+	defer patternsMu.RUnlock()
+	pattern, ok := patterns[name]
+	return pattern, ok
+}
+
+// RegisterEnum registers values for the enum rule under name, so a field tagged
+// validate:"enum=<name>" must be one of them. Registering an existing name replaces its values.
+func RegisterEnum(name string, values []string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, ",=") {
+		return fmt.Errorf("invalid enum name %q", name)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("enum %q has no values", name)
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[name] = append([]string(nil), values...)
+	return nil
+}
+
+// lookupEnum returns a copy of the values registered under name
+func lookupEnum(name string) ([]string, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	values, ok := enums[name]
+	return append([]string(nil), values...), ok
+}
+
 // NewValidator creates a new validator instance
 func NewValidator() *Validator {
 	return &Validator{
@@ -38,24 +106,26 @@ func NewValidator() *Validator {
 	}
 }
 
-// ValidateStruct validates a struct using reflection and validation tags
+// ValidateStruct validates a struct using reflection and validation tags. Nested structs are
+// validated too, as are the struct elements of slices tagged with dive; their errors are keyed by
+// dotted paths such as assertions[0].type.
 func (v *Validator) ValidateStruct(s interface{}) *ValidationResult {
 	v.errors = make(map[string]ValidationError)
 
-	val := reflect.ValueOf(s)
-	typ := reflect.TypeOf(s)
-
-	// Handle pointer to struct
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
-		typ = typ.Elem()
-	}
-
+	val := reflect.Indirect(reflect.ValueOf(s))
 	if val.Kind() != reflect.Struct {
 		v.addError("_root", "Value must be a struct", "")
 		return v.getResult()
 	}
 
+	v.validateStructFields("", val)
+	return v.getResult()
+}
+
+// validateStructFields validates the fields of a struct value, prefixing error field names with path
+func (v *Validator) validateStructFields(path string, val reflect.Value) {
+	typ := val.Type()
+
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
 		fieldType := typ.Field(i)
@@ -77,29 +147,60 @@ func (v *Validator) ValidateStruct(s interface{}) *ValidationResult {
 			}
 		}
 
-		// Get validation tag
+		// A "-" validation tag skips the field and anything nested in it
 		validateTag := fieldType.Tag.Get("validate")
-		if validateTag == "" {
+		if validateTag == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, as encoding/json does
+		nested := reflect.Indirect(field)
+		if fieldType.Anonymous && jsonTag == "" && nested.Kind() == reflect.Struct {
+			v.validateStructFields(path, nested)
 			continue
 		}
 
 		// Validate field
-		v.validateField(fieldName, field.Interface(), validateTag)
+		if validateTag != "" {
+			v.validateField(path+fieldName, field.Interface(), validateTag)
+		}
+
+		// Validate the fields of nested structs. A zero struct counts as left out unless it is required.
+		if nested.Kind() == reflect.Struct && (!field.IsZero() || hasRule(validateTag, "required")) {
+			v.validateStructFields(path+fieldName+".", nested)
+		}
 	}
+}
 
-	return v.getResult()
+// hasRule reports whether a validation tag contains the named rule
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if ruleName, _, _ := strings.Cut(strings.TrimSpace(rule), "="); ruleName == name {
+			return true
+		}
+	}
+	return false
 }
 
 // validateField validates a single field based on validation rules
 func (v *Validator) validateField(fieldName string, value interface{}, rules string) {
 	ruleList := strings.Split(rules, ",")
+	dives := hasRule(rules, "dive")
 
-	for _, rule := range ruleList {
+	for i, rule := range ruleList {
 		rule = strings.TrimSpace(rule)
 		if rule == "" {
 			continue
 		}
 
+		// omitempty skips the remaining rules for zero values
+		if rule == "omitempty" {
+			if value == nil || reflect.ValueOf(value).IsZero() {
+				return
+			}
+			continue
+		}
+
 		// Parse rule and parameters
 		parts := strings.Split(rule, "=")
 		ruleName := parts[0]
@@ -108,6 +209,26 @@ func (v *Validator) validateField(fieldName string, value interface{}, rules str
 			ruleParam = parts[1]
 		}
 
+		// Rules after dive apply to each element rather than to the slice itself
+		if ruleName == "dive" {
+			elementRules := ruleList[i+1:]
+			if ruleParam != "" {
+				elementRules = append([]string{ruleParam}, elementRules...)
+			}
+			v.validateDive(fieldName, value, strings.Join(elementRules, ","))
+			return
+		}
+
+		// Before a dive, min and max bound the number of elements, as the schema's minItems and maxItems do
+		if dives && (ruleName == "min" || ruleName == "max") {
+			if count, ok := collectionLen(value); ok {
+				if !v.validateCount(fieldName, count, ruleName, ruleParam) {
+					break
+				}
+				continue
+			}
+		}
+
 		// Apply validation rule
 		if !v.applyRule(fieldName, value, ruleName, ruleParam) {
 			break // Stop on first error for this field
@@ -115,6 +236,34 @@ func (v *Validator) validateField(fieldName string, value interface{}, rules str
 	}
 }
 
+// collectionLen returns the number of elements in a slice, array or map value
+func collectionLen(value interface{}) (int, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	return 0, false
+}
+
+// validateCount validates a min or max bound on the number of elements of a collection
+func (v *Validator) validateCount(fieldName string, count int, ruleName, param string) bool {
+	bound, err := strconv.Atoi(param)
+	if err != nil {
+		return true
+	}
+
+	if ruleName == "min" && count < bound {
+		v.addError(fieldName, fmt.Sprintf("Field must have at least %d items", bound), strconv.Itoa(count))
+		return false
+	}
+	if ruleName == "max" && count > bound {
+		v.addError(fieldName, fmt.Sprintf("Field must have at most %d items", bound), strconv.Itoa(count))
+		return false
+	}
+	return true
+}
+
 // applyRule applies a specific validation rule
 func (v *Validator) applyRule(fieldName string, value interface{}, ruleName, param string) bool {
 	switch ruleName {
@@ -138,6 +287,12 @@ func (v *Validator) applyRule(fieldName string, value interface{}, ruleName, par
 		return v.validateAlphaNumeric(fieldName, value)
 	case "oneof":
 		return v.validateOneOf(fieldName, value, param)
+	case "enum":
+		return v.validateEnum(fieldName, value, param)
+	case "regexp":
+		return v.validateRegexp(fieldName, value, param)
+	case "startsnotwith":
+		return v.validateStartsNotWith(fieldName, value, param)
 	case "dive":
 		return v.validateDive(fieldName, value, param)
 	default:
@@ -376,7 +531,71 @@ func (v *Validator) validateOneOf(fieldName string, value interface{}, param str
 	return false
 }
 
-// validateDive validates each element in a slice
+// validateEnum validates that field is one of the values registered under param
+func (v *Validator) validateEnum(fieldName string, value interface{}, param string) bool {
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "Field must be a string", fmt.Sprintf("%v", value))
+		return false
+	}
+
+	values, ok := lookupEnum(param)
+	if !ok {
+		v.addError(fieldName, fmt.Sprintf("Unknown validation enum: %s", param), str)
+		return false
+	}
+
+	for _, option := range values {
+		if str == option {
+			return true
+		}
+	}
+
+	v.addError(fieldName, fmt.Sprintf("Field must be one of: %s", strings.Join(values, ", ")), str)
+	return false
+}
+
+// validateRegexp validates that field matches the pattern registered under param
+func (v *Validator) validateRegexp(fieldName string, value interface{}, param string) bool {
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "Field must be a string", fmt.Sprintf("%v", value))
+		return false
+	}
+
+	pattern, ok := lookupPattern(param)
+	if !ok {
+		v.addError(fieldName, fmt.Sprintf("Unknown validation pattern: %s", param), str)
+		return false
+	}
+
+	if !pattern.MatchString(str) {
+		v.addError(fieldName, fmt.Sprintf("Field must match pattern %s: %s", param, pattern.String()), str)
+		return false
+	}
+
+	return true
+}
+
+// validateStartsNotWith validates that a string doesn't start with param
+func (v *Validator) validateStartsNotWith(fieldName string, value interface{}, param string) bool {
+	str, ok := value.(string)
+	if !ok {
+		v.addError(fieldName, "Field must be a string", fmt.Sprintf("%v", value))
+		return false
+	}
+
+	if strings.HasPrefix(str, param) {
+		v.addError(fieldName, fmt.Sprintf("Field must not start with %q", param), str)
+		return false
+	}
+
+	return true
+}
+
+// validateDive validates each element in a slice. Struct elements have their fields validated;
+// other elements must pass the comma-separated rules given as param. Errors are recorded for
+// every invalid element.
 func (v *Validator) validateDive(fieldName string, value interface{}, param string) bool {
 	rv := reflect.ValueOf(value)
 	if rv.Kind() != reflect.Slice {
@@ -384,17 +603,26 @@ func (v *Validator) validateDive(fieldName string, value interface{}, param stri
 		return false
 	}
 
+	valid := true
 	for i := 0; i < rv.Len(); i++ {
-		element := rv.Index(i).Interface()
 		elementFieldName := fmt.Sprintf("%s[%d]", fieldName, i)
 
-		// Apply the parameter validation rule to each element
-		if !v.applyRule(elementFieldName, element, param, "") {
-			return false
+		if element := reflect.Indirect(rv.Index(i)); element.Kind() == reflect.Struct {
+			errorCount := len(v.errors)
+			v.validateStructFields(elementFieldName+".", element)
+			valid = valid && len(v.errors) == errorCount
+			continue
+		}
+
+		// Apply the parameter validation rules to each element
+		if param != "" {
+			errorCount := len(v.errors)
+			v.validateField(elementFieldName, rv.Index(i).Interface(), param)
+			valid = valid && len(v.errors) == errorCount
 		}
 	}
 
-	return true
+	return valid
 }
 
 // addError adds a validation error
@@ -414,6 +642,13 @@ func (v *Validator) getResult() *ValidationResult {
 	}
 }
 
+// ValidateValue validates a single value against the given validation rules
+func (v *Validator) ValidateValue(fieldName string, value interface{}, rules string) *ValidationResult {
+	v.errors = make(map[string]ValidationError)
+	v.validateField(fieldName, value, rules)
+	return v.getResult()
+}
+
 // ValidateJSON validates JSON request body against a struct
 func ValidateJSON(c *fiber.Ctx, target interface{}) *ValidationResult {
 	// Parse JSON body
@@ -452,7 +687,8 @@ func ValidateParams(c *fiber.Ctx, rules map[string]string) *ValidationResult {
 	return validator.getResult()
 }
 
-// HandleValidationErrors handles validation errors and returns appropriate response
+// HandleValidationErrors handles validation errors and returns appropriate response. Rejected
+// input values are left out, since they may contain submitted credentials.
 func HandleValidationErrors(c *fiber.Ctx, result *ValidationResult) error {
 	if result.IsValid {
 		return nil
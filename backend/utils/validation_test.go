@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validationTestAddress struct {
+	City    string `json:"city" validate:"required"`
+	Country string `json:"country" validate:"omitempty,len=2"`
+}
+
+type validationTestItem struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+// ValidationTestMeta is exported since the fields of unexported embedded structs can't be read
+type ValidationTestMeta struct {
+	Source string `json:"source" validate:"required"`
+}
+
+type validationTestOrder struct {
+	ValidationTestMeta
+	ID        string                 `json:"id" validate:"required"`
+	Billing   validationTestAddress  `json:"billing" validate:"required"`
+	Shipping  *validationTestAddress `json:"shipping"`
+	Gift      validationTestAddress  `json:"gift"`
+	Items     []validationTestItem   `json:"items" validate:"required,dive"`
+	Backorder []*validationTestItem  `json:"backorder" validate:"dive"`
+	Unchecked []validationTestItem   `json:"unchecked"`
+	Skipped   validationTestAddress  `json:"skipped" validate:"-"`
+	Tags      []string               `json:"tags" validate:"dive=alpha"`
+	Aliases   []string               `json:"aliases" validate:"omitempty,max=3,dive,required,max=10,startsnotwith=-"`
+	Notes     []string               `json:"notes" validate:"max=1"`
+	PlacedAt  time.Time              `json:"placed_at"`
+}
+
+// validOrder returns an order that passes validation
+func validOrder() validationTestOrder {
+	return validationTestOrder{
+		ValidationTestMeta: ValidationTestMeta{Source: "web"},
+		ID:                 "order-1",
+		Billing:            validationTestAddress{City: "Lisbon", Country: "PT"},
+		Items:              []validationTestItem{{SKU: "a", Quantity: 1}},
+	}
+}
+
+func TestValidator_ValidateStruct_Nested(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(order *validationTestOrder)
+		expected []string
+	}{
+		{name: "valid order", modify: func(order *validationTestOrder) {}},
+		{
+			name:     "embedded struct fields are flattened",
+			modify:   func(order *validationTestOrder) { order.Source = "" },
+			expected: []string{"source"},
+		},
+		{
+			name:     "nested struct",
+			modify:   func(order *validationTestOrder) { order.Billing.Country = "Portugal" },
+			expected: []string{"billing.country"},
+		},
+		{
+			name:     "required nested struct left out",
+			modify:   func(order *validationTestOrder) { order.Billing = validationTestAddress{} },
+			expected: []string{"billing.city"},
+		},
+		{
+			name:   "optional nested structs left out",
+			modify: func(order *validationTestOrder) { order.Shipping = nil; order.Gift = validationTestAddress{} },
+		},
+		{
+			name: "nested pointer and optional struct given",
+			modify: func(order *validationTestOrder) {
+				order.Shipping = &validationTestAddress{Country: "PT"}
+				order.Gift.Country = "PT"
+			},
+			expected: []string{"shipping.city", "gift.city"},
+		},
+		{
+			name: "every invalid slice element is reported",
+			modify: func(order *validationTestOrder) {
+				order.Items = []validationTestItem{{Quantity: 1}, {SKU: "b", Quantity: 1}, {SKU: "c"}}
+			},
+			expected: []string{"items[0].sku", "items[2].quantity"},
+		},
+		{
+			name: "pointer elements",
+			modify: func(order *validationTestOrder) {
+				order.Backorder = []*validationTestItem{nil, {Quantity: 2}}
+			},
+			expected: []string{"backorder[1].sku"},
+		},
+		{
+			name:   "slices without dive are not checked",
+			modify: func(order *validationTestOrder) { order.Unchecked = []validationTestItem{{}} },
+		},
+		{
+			name:   "fields tagged - are not checked",
+			modify: func(order *validationTestOrder) { order.Skipped = validationTestAddress{Country: "Portugal"} },
+		},
+		{
+			name:     "scalar elements still use the dive rule",
+			modify:   func(order *validationTestOrder) { order.Tags = []string{"sale", "2024"} },
+			expected: []string{"tags[1]"},
+		},
+		{
+			name:     "rules after dive apply to each element",
+			modify:   func(order *validationTestOrder) { order.Aliases = []string{"rush", "", "-x", "a-very-long-alias"} },
+			expected: []string{"aliases"},
+		},
+		{
+			name:   "min and max only count elements before a dive",
+			modify: func(order *validationTestOrder) { order.Notes = []string{"fragile", "gift"} },
+		},
+		{
+			name:     "element errors are keyed by index",
+			modify:   func(order *validationTestOrder) { order.Aliases = []string{"rush", "", "-x"} },
+			expected: []string{"aliases[1]", "aliases[2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.modify(&order)
+
+			result := NewValidator().ValidateStruct(&order)
+
+			fields := make([]string, 0, len(result.Errors))
+			for field, validationError := range result.Errors {
+				assert.Equal(t, field, validationError.Field)
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tt.expected, fields)
+			assert.Equal(t, len(tt.expected) == 0, result.IsValid)
+		})
+	}
+}
+
+func TestValidateStruct_NestedErrorPath(t *testing.T) {
+	order := validOrder()
+	order.Items[0].SKU = ""
+
+	err := ValidateStruct(order)
+	assert.EqualError(t, err, "validation failed for field 'items[0].sku': Field is required")
+}
+
+func TestValidator_Regexp(t *testing.T) {
+	assert.NoError(t, RegisterPattern("validation_test_semver", `^v\d+\.\d+\.\d+$`))
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		rules   string
+		valid   bool
+		message string
+	}{
+		{name: "built-in envname", value: "staging-2", rules: "regexp=envname", valid: true},
+		{name: "envname rejects capitals", value: "Staging", rules: "regexp=envname", message: "Field must match pattern envname: ^[a-z0-9-]+$"},
+		{name: "envname rejects spaces", value: "qa env", rules: "regexp=envname", message: "Field must match pattern envname: ^[a-z0-9-]+$"},
+		{name: "registered pattern", value: "v1.2.3", rules: "regexp=validation_test_semver", valid: true},
+		{name: "registered pattern mismatch", value: "1.2", rules: "regexp=validation_test_semver", message: `Field must match pattern validation_test_semver: ^v\d+\.\d+\.\d+$`},
+		{name: "omitted value", value: "", rules: "omitempty,regexp=envname", valid: true},
+		{name: "unknown pattern", value: "prod", rules: "regexp=missing", message: "Unknown validation pattern: missing"},
+		{name: "non-string value", value: 42, rules: "regexp=envname", message: "Field must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator().ValidateValue("environment", tt.value, tt.rules)
+			assert.Equal(t, tt.valid, result.IsValid)
+			if !tt.valid {
+				assert.Equal(t, tt.message, result.Errors["environment"].Message)
+			}
+		})
+	}
+}
+
+func TestValidator_Regexp_StructTag(t *testing.T) {
+	type deployment struct {
+		Environment string `json:"environment" validate:"required,regexp=envname"`
+	}
+
+	result := NewValidator().ValidateStruct(&deployment{Environment: "prod-eu"})
+	assert.True(t, result.IsValid)
+
+	result = NewValidator().ValidateStruct(&deployment{Environment: "Prod_EU"})
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "Prod_EU", result.Errors["environment"].Value)
+}
+
+func TestRegisterPattern(t *testing.T) {
+	assert.Error(t, RegisterPattern("", `^a$`))
+	assert.Error(t, RegisterPattern("a=b", `^a$`), "names can't contain rule separators")
+	err := RegisterPattern("validation_test_broken", `^(unclosed$`)
+	assert.ErrorContains(t, err, "validation_test_broken")
+
+	result := NewValidator().ValidateValue("field", "x", "regexp=validation_test_broken")
+	assert.Equal(t, "Unknown validation pattern: validation_test_broken", result.Errors["field"].Message,
+		"a pattern that fails to compile isn't registered")
+
+	// Registering a name again replaces its pattern
+	assert.NoError(t, RegisterPattern("validation_test_replaced", `^a$`))
+	assert.NoError(t, RegisterPattern("validation_test_replaced", `^b$`))
+	assert.True(t, NewValidator().ValidateValue("field", "b", "regexp=validation_test_replaced").IsValid)
+	assert.False(t, NewValidator().ValidateValue("field", "a", "regexp=validation_test_replaced").IsValid)
+}
+
+func TestValidator_Enum(t *testing.T) {
+	assert.NoError(t, RegisterEnum("validation_test_sizes", []string{"small", "large"}))
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		rules   string
+		valid   bool
+		message string
+	}{
+		{name: "built-in loglevel", value: "warn", rules: "enum=loglevel", valid: true},
+		{name: "registered value", value: "large", rules: "enum=validation_test_sizes", valid: true},
+		{name: "unregistered value", value: "medium", rules: "enum=validation_test_sizes", message: "Field must be one of: small, large"},
+		{name: "omitted value", value: "", rules: "omitempty,enum=validation_test_sizes", valid: true},
+		{name: "unknown enum", value: "small", rules: "enum=missing", message: "Unknown validation enum: missing"},
+		{name: "non-string value", value: 42, rules: "enum=loglevel", message: "Field must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewValidator().ValidateValue("size", tt.value, tt.rules)
+			assert.Equal(t, tt.valid, result.IsValid)
+			if !tt.valid {
+				assert.Equal(t, tt.message, result.Errors["size"].Message)
+			}
+		})
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+	assert.Error(t, RegisterEnum("", []string{"a"}))
+	assert.Error(t, RegisterEnum("a,b", []string{"a"}), "names can't contain rule separators")
+	assert.Error(t, RegisterEnum("validation_test_empty", nil))
+
+	// Registering a name again replaces its values, and later changes to the slice don't leak in
+	values := []string{"a"}
+	assert.NoError(t, RegisterEnum("validation_test_replaced", values))
+	assert.NoError(t, RegisterEnum("validation_test_replaced", []string{"b"}))
+	values[0] = "b"
+	assert.True(t, NewValidator().ValidateValue("field", "b", "enum=validation_test_replaced").IsValid)
+	assert.False(t, NewValidator().ValidateValue("field", "a", "enum=validation_test_replaced").IsValid)
+}
@@ -3,6 +3,8 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
@@ -15,34 +17,57 @@ const (
 	// Time allowed to write a message to the peer
 	writeWait = 10 * time.Second
 
-	// Time allowed to read the next pong message from the peer
+	// Default time allowed to read the next pong message from the peer; see HubConfig.PongTimeout
 	pongWait = 60 * time.Second
 
-	// Send pings to peer with this period. Must be less than pongWait
+	// Default period of pings sent to the peer. Must be less than pongWait
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
 )
 
+// connection is the subset of *websocket.Conn used by Client
+type connection interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	SetReadLimit(limit int64)
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
+
 // Client represents a WebSocket client connection
 type Client struct {
 	ID       string
-	conn     *websocket.Conn
+	conn     connection
 	send     chan models.WSMessage
 	hub      *Hub
 	UserID   string
-	LastSeen time.Time
+	LastSeen time.Time // Last pong or message; read and written through lastSeen and touch
+	seenMu   sync.Mutex
+
+	dead     atomic.Bool // Set once a write to the connection has failed
+	deadOnce sync.Once
+
+	consecutiveDrops int             // Hub messages dropped in a row because send was full; guarded by the hub's mu
+	topics           map[string]bool // Topics subscribed to for BroadcastToTopic; guarded by the hub's mu
 }
 
 // NewClient creates a new WebSocket client
 func NewClient(conn *websocket.Conn, hub *Hub, userID string) *Client {
 	clientID := uuid.New().String()
 
+	bufferSize := defaultSendBufferSize
+	if hub != nil {
+		bufferSize = hub.sendBufferSize
+	}
+
 	return &Client{
 		ID:       clientID,
 		conn:     conn,
-		send:     make(chan models.WSMessage, 256),
+		send:     make(chan models.WSMessage, bufferSize),
 		hub:      hub,
 		UserID:   userID,
 		LastSeen: time.Now(),
@@ -59,11 +84,12 @@ func (c *Client) ReadPump() {
 	}()
 
 	// Set read deadline and message size limit
+	_, pongTimeout := c.hub.keepalive()
 	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
-		c.LastSeen = time.Now()
+		c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+		c.touch()
 		return nil
 	})
 
@@ -93,7 +119,8 @@ func (c *Client) ReadPump() {
 		// Set client ID and timestamp
 		message.ClientID = c.ID
 		message.Timestamp = time.Now()
-		c.LastSeen = time.Now()
+		c.touch()
+		c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
 
 		// Validate message type
 		if !isValidMessageType(message.Type) {
@@ -112,7 +139,8 @@ func (c *Client) ReadPump() {
 // WritePump pumps messages from the hub to the WebSocket connection
 func (c *Client) WritePump() {
 	logger := utils.GetLogger()
-	ticker := time.NewTicker(pingPeriod)
+	pingInterval, _ := c.hub.keepalive()
+	ticker := time.NewTicker(pingInterval)
 
 	defer func() {
 		ticker.Stop()
@@ -122,15 +150,18 @@ func (c *Client) WritePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The hub closed the channel
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
-			// Send the message
+			// Encode the message in a hub write slot. The slot is released before the write, so a
+			// slow connection only holds up its own queue.
+			release := c.hub.acquireWrite()
 			messageBytes, err := json.Marshal(message)
+			release()
 			if err != nil {
 				logger.Error("Failed to marshal WebSocket message", err, map[string]interface{}{
 					"client_id":    c.ID,
@@ -139,16 +170,21 @@ func (c *Client) WritePump() {
 				continue
 			}
 
-			if err := c.conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err = c.conn.WriteMessage(websocket.TextMessage, messageBytes)
+			if err != nil {
 				logger.Error("Failed to write WebSocket message", err, map[string]interface{}{
-					"client_id": c.ID,
+					"client_id":    c.ID,
+					"message_type": message.Type,
 				})
+				c.markDead()
 				return
 			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.markDead()
 				return
 			}
 		}
@@ -184,6 +220,9 @@ func (c *Client) handleMessage(message models.WSMessage) {
 			"user_id":   c.UserID,
 		})
 
+	case "subscribe", "unsubscribe":
+		c.handleSubscription(message)
+
 	case "disconnect":
 		// Handle graceful disconnect
 		logger.Info("WebSocket client requested disconnect", map[string]interface{}{
@@ -205,13 +244,18 @@ func (c *Client) handleMessage(message models.WSMessage) {
 // isValidMessageType checks if the message type is valid
 func isValidMessageType(msgType string) bool {
 	validTypes := map[string]bool{
-		"sync_status_update":  true,
-		"test_progress":       true,
-		"log_alert":           true,
-		"ai_suggestion_ready": true,
-		"connect":             true,
-		"disconnect":          true,
-		"heartbeat":           true,
+		"sync_status_update":     true,
+		"test_progress":          true,
+		"test_log_line":          true,
+		"test_workflow_progress": true,
+		"log_alert":              true,
+		"ai_suggestion_ready":    true,
+		"ai_request_cancelled":   true,
+		"connect":                true,
+		"disconnect":             true,
+		"heartbeat":              true,
+		"subscribe":              true,
+		"unsubscribe":            true,
 	}
 
 	return validTypes[msgType]
@@ -233,7 +277,34 @@ func (c *Client) SendMessage(msgType string, data interface{}) {
 	}
 }
 
-// IsAlive checks if the client connection is still alive
+// IsAlive checks if the client connection is still alive: writes to it succeed and it has
+// answered a ping or sent a message within the hub's pong timeout
 func (c *Client) IsAlive() bool {
-	return time.Since(c.LastSeen) < pongWait
+	_, pongTimeout := c.hub.keepalive()
+	return !c.dead.Load() && time.Since(c.lastSeen()) < pongTimeout
+}
+
+// touch records that the client has just been heard from
+func (c *Client) touch() {
+	c.seenMu.Lock()
+	c.LastSeen = time.Now()
+	c.seenMu.Unlock()
+}
+
+// lastSeen returns when the client was last heard from
+func (c *Client) lastSeen() time.Time {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	return c.LastSeen
+}
+
+// markDead flags the client as unusable after a failed write and removes it from the hub,
+// so connection counts don't include clients that can no longer receive messages
+func (c *Client) markDead() {
+	c.deadOnce.Do(func() {
+		c.dead.Store(true)
+		if c.hub != nil {
+			c.hub.UnregisterClient(c)
+		}
+	})
 }
@@ -237,8 +237,11 @@ func TestIsValidMessageType(t *testing.T) {
 	validTypes := []string{
 		"sync_status_update",
 		"test_progress",
+		"test_log_line",
+		"test_workflow_progress",
 		"log_alert",
 		"ai_suggestion_ready",
+		"ai_request_cancelled",
 		"connect",
 		"disconnect",
 		"heartbeat",
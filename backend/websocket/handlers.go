@@ -1,6 +1,9 @@
 package websocket
 
 import (
+	"crypto/subtle"
+	"strings"
+
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
@@ -10,8 +13,8 @@ import (
 var GlobalHub *Hub
 
 // InitializeHub initializes the global WebSocket hub
-func InitializeHub() {
-	GlobalHub = NewHub()
+func InitializeHub(config ...HubConfig) {
+	GlobalHub = NewHub(config...)
 	go GlobalHub.Run()
 
 	logger := utils.GetLogger()
@@ -20,23 +23,87 @@ func InitializeHub() {
 	})
 }
 
-// WebSocketUpgrade handles WebSocket upgrade requests
-func WebSocketUpgrade(c *fiber.Ctx) error {
-	// Check if the request is a WebSocket upgrade
-	if websocket.IsWebSocketUpgrade(c) {
+// TokenSubprotocol is offered alongside the token by browser clients, which can't set headers
+// on WebSocket requests: new WebSocket(url, ["bearer", token]). The server selects it.
+const TokenSubprotocol = "bearer"
+
+// userIDLocal is the Fiber local the authenticated user ID is passed to WebSocketHandler under
+const userIDLocal = "ws_user_id"
+
+// UpgradeConfig holds authentication settings for WebSocket upgrades
+type UpgradeConfig struct {
+	// Tokens maps each accepted token to the user ID its connections are registered under
+	Tokens map[string]string
+	// AllowAnonymous accepts connections without a token, registered under the user_id query
+	// parameter or "anonymous". A token that is presented must still be valid. Development only.
+	AllowAnonymous bool
+}
+
+// WebSocketUpgrade creates the handler that accepts WebSocket upgrade requests. The token is
+// read from the Sec-WebSocket-Protocol header and checked before the connection is accepted;
+// requests without a valid one are rejected with 401.
+func WebSocketUpgrade(config UpgradeConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Check if the request is a WebSocket upgrade
+		if !websocket.IsWebSocketUpgrade(c) {
+			return utils.ErrorResponse(c, fiber.StatusUpgradeRequired, "WEBSOCKET_REQUIRED", "WebSocket upgrade required", nil)
+		}
+
+		token := requestToken(c)
+		userID, authenticated := config.authenticate(token)
+		if !authenticated && (token != "" || !config.AllowAnonymous) {
+			utils.GetLogger().WithTraceID(utils.GetTraceID(c)).Warn("Rejected unauthenticated WebSocket connection", map[string]interface{}{
+				"ip":            c.IP(),
+				"token_present": token != "",
+			})
+			return utils.ErrorResponse(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "A valid WebSocket token is required", nil)
+		}
+		if !authenticated {
+			userID = c.Query("user_id", "anonymous")
+		}
+
 		c.Locals("allowed", true)
+		c.Locals(userIDLocal, userID)
 		return c.Next()
 	}
+}
+
+// authenticate returns the user ID of token, comparing against every configured token in constant time
+func (config UpgradeConfig) authenticate(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	var userID string
+	found := false
+	for candidate, user := range config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			userID, found = user, true
+		}
+	}
+	return userID, found
+}
 
-	return utils.ErrorResponse(c, fiber.StatusUpgradeRequired, "WEBSOCKET_REQUIRED", "WebSocket upgrade required", nil)
+// requestToken reads the token from the subprotocols offered as "bearer, <token>". A token query
+// parameter is ignored, since URLs end up in proxy and access logs.
+func requestToken(c *fiber.Ctx) string {
+	protocols := utils.SplitAndTrim(c.Get(fiber.HeaderSecWebSocketProtocol), ",")
+	for i, protocol := range protocols {
+		if strings.EqualFold(protocol, TokenSubprotocol) && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
 }
 
-// WebSocketHandler handles WebSocket connections
+// WebSocketHandler handles WebSocket connections accepted by WebSocketUpgrade
 func WebSocketHandler(c *websocket.Conn) {
 	logger := utils.GetLogger()
 
-	// Get user ID from query parameters or headers (for now, use a default)
-	userID := c.Query("user_id", "anonymous")
+	userID, _ := c.Locals(userIDLocal).(string)
+	if userID == "" {
+		userID = "anonymous"
+	}
 
 	// Create new client
 	client := NewClient(c, GlobalHub, userID)
@@ -68,6 +135,11 @@ func GetWebSocketStats() map[string]interface{} {
 		"status":            "running",
 		"connected_clients": GlobalHub.GetConnectedClients(),
 		"client_ids":        GlobalHub.GetClientIDs(),
+		"writes":            GlobalHub.WriteStats(),
+		"retained_messages": GlobalHub.RetainedMessages(),
+		"pruned_clients":    GlobalHub.PrunedClients(),
+		"dropped_messages":  GlobalHub.DroppedMessages(),
+		"evicted_clients":   GlobalHub.EvictedClients(),
 	}
 }
 
@@ -99,6 +171,13 @@ func BroadcastAISuggestionReady(data interface{}) {
 	}
 }
 
+// BroadcastToUser sends a message to every connection of an authenticated user
+func BroadcastToUser(userID, msgType string, data interface{}) {
+	if GlobalHub != nil {
+		GlobalHub.BroadcastToUser(userID, msgType, data)
+	}
+}
+
 // BroadcastToClient sends a message to a specific client
 func BroadcastToClient(clientID, msgType string, data interface{}) {
 	if GlobalHub != nil {
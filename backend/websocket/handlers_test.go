@@ -1,11 +1,16 @@
 package websocket
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInitializeHub(t *testing.T) {
@@ -42,6 +47,8 @@ func TestGetWebSocketStats_Running(t *testing.T) {
 	assert.Equal(t, "running", stats["status"])
 	assert.Equal(t, 0, stats["connected_clients"])
 	assert.NotNil(t, stats["client_ids"])
+	assert.Equal(t, int64(0), stats["dropped_messages"])
+	assert.Equal(t, int64(0), stats["evicted_clients"])
 }
 
 func TestBroadcastSyncUpdate(t *testing.T) {
@@ -194,3 +201,70 @@ func TestBroadcastFunctions_NilHub(t *testing.T) {
 
 	// No assertions needed - just testing that no panic occurs
 }
+
+func TestWebSocketUpgrade(t *testing.T) {
+	newApp := func(config UpgradeConfig) *fiber.App {
+		app := fiber.New()
+		app.Use("/ws", WebSocketUpgrade(config))
+		app.Get("/ws", func(c *fiber.Ctx) error {
+			return c.SendString(c.Locals(userIDLocal).(string))
+		})
+		return app
+	}
+	upgrade := func(app *fiber.App, target string, protocols string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		if protocols != "" {
+			req.Header.Set("Sec-WebSocket-Protocol", protocols)
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	config := UpgradeConfig{Tokens: map[string]string{"dashboard-secret": "dashboard"}}
+	app := newApp(config)
+
+	t.Run("token subprotocol", func(t *testing.T) {
+		status, userID := upgrade(app, "/ws?user_id=someone-else", "bearer, dashboard-secret")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "dashboard", userID, "the token decides the user")
+	})
+
+	t.Run("missing or wrong token", func(t *testing.T) {
+		for _, protocols := range []string{"", "bearer, wrong", "bearer"} {
+			status, body := upgrade(app, "/ws", protocols)
+			assert.Equal(t, http.StatusUnauthorized, status, protocols)
+			assert.Contains(t, body, "UNAUTHORIZED")
+		}
+	})
+
+	t.Run("token query parameter ignored", func(t *testing.T) {
+		status, _ := upgrade(app, "/ws?token=dashboard-secret", "")
+		assert.Equal(t, http.StatusUnauthorized, status, "tokens in URLs leak into logs")
+	})
+
+	t.Run("not an upgrade", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "bearer, dashboard-secret")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	})
+
+	t.Run("anonymous development mode", func(t *testing.T) {
+		dev := newApp(UpgradeConfig{Tokens: config.Tokens, AllowAnonymous: true})
+
+		status, userID := upgrade(dev, "/ws?user_id=alice", "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "alice", userID)
+
+		_, userID = upgrade(dev, "/ws", "")
+		assert.Equal(t, "anonymous", userID)
+
+		status, _ = upgrade(dev, "/ws", "bearer, wrong")
+		assert.Equal(t, http.StatusUnauthorized, status, "a presented token must still be valid")
+	})
+}
@@ -1,7 +1,11 @@
 package websocket
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
@@ -10,30 +14,150 @@ import (
 
 // Hub manages WebSocket connections and message broadcasting
 type Hub struct {
+	mu         sync.RWMutex // Guards clients, which is read outside the Run loop
 	clients    map[*Client]bool
 	broadcast  chan models.WSMessage
 	register   chan *Client
 	unregister chan *Client
+	probes     chan struct{} // Received by the Run loop, so HealthCheck can tell it's alive
+
+	// Caps client writes in flight across the hub; nil when unlimited
+	writeSlots    chan struct{}
+	writesActive  atomic.Int64
+	writesWaiting atomic.Int64
+	writesPeak    atomic.Int64
+	writesTotal   atomic.Int64
+	writesQueued  atomic.Int64
+
+	recorder BroadcastRecorder // Sees every BroadcastToAll message; nil when nothing is recorded
+
+	// Decides which topics a client may subscribe to; nil allows any. Guarded by mu.
+	topicAuthorizer TopicAuthorizer
+
+	// Latest broadcast per (type, key), replayed to clients when they connect; nil when disabled
+	retained *retainedMessages
+
+	// Keepalive: clients are pinged every pingInterval and removed once they have been silent
+	// for pongTimeout
+	pingInterval  time.Duration
+	pongTimeout   time.Duration
+	prunedClients atomic.Int64 // Clients removed for not answering pings since startup
+
+	// Slow consumers: a message that doesn't fit in a client's send buffer is dropped for that
+	// client, and a client that drops maxConsecutiveDrops messages in a row is evicted
+	sendBufferSize      int
+	maxConsecutiveDrops int
+	droppedMessages     atomic.Int64
+	evictedClients      atomic.Int64
+}
+
+// HubConfig holds optional settings for the hub
+type HubConfig struct {
+	MaxConcurrentWrites int               // Client messages encoded at once across all clients; 0 means unlimited
+	Recorder            BroadcastRecorder // Optional hook handed every message broadcast to all clients
+
+	// RetainKeys maps the message types to retain to the data field keying them, e.g.
+	// "test_progress" to "run_id"; an empty field keeps only the latest message of the type.
+	// New clients are sent every retained message after the welcome message.
+	RetainKeys map[string]string
+	// RetainMaxMessages caps the retained messages across all types; 0 disables retention
+	RetainMaxMessages int
+
+	// PingInterval is how often clients are pinged; 0 uses pingPeriod
+	PingInterval time.Duration
+	// PongTimeout is how long a client may go without a pong or message before it is removed;
+	// 0 uses pongWait. Must be longer than PingInterval.
+	PongTimeout time.Duration
+
+	// SendBufferSize is how many messages each client can have waiting to be written; 0 uses
+	// defaultSendBufferSize
+	SendBufferSize int
+	// MaxConsecutiveDrops is how many messages in a row a client with a full buffer may miss
+	// before it is evicted; 0 uses defaultMaxConsecutiveDrops
+	MaxConsecutiveDrops int
+}
+
+const (
+	// defaultSendBufferSize applies when HubConfig.SendBufferSize is not set
+	defaultSendBufferSize = 256
+
+	// defaultMaxConsecutiveDrops applies when HubConfig.MaxConsecutiveDrops is not set
+	defaultMaxConsecutiveDrops = 50
+)
+
+// BroadcastRecorder is handed each message broadcast to all clients, whether or not any client
+// receives it. RecordBroadcast is called on the broadcaster's goroutine and must not block.
+type BroadcastRecorder interface {
+	RecordBroadcast(message models.WSMessage)
+}
+
+// WriteStats reports how busy the hub's write slots are. A write holds a slot while its message
+// is encoded; pings and the network write itself don't use one.
+type WriteStats struct {
+	MaxConcurrent int     `json:"max_concurrent"` // 0 when unlimited
+	InFlight      int64   `json:"in_flight"`
+	Waiting       int64   `json:"waiting"`        // Writes currently queued for a free slot
+	PeakInFlight  int64   `json:"peak_in_flight"` // Highest InFlight seen since startup
+	TotalWrites   int64   `json:"total_writes"`
+	QueuedWrites  int64   `json:"queued_writes"` // Writes that had to wait for a slot since startup
+	Saturation    float64 `json:"saturation"`    // InFlight as a percentage of MaxConcurrent
 }
 
 // NewHub creates a new WebSocket hub
-func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan models.WSMessage, 256),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+func NewHub(config ...HubConfig) *Hub {
+	hub := &Hub{
+		clients:      make(map[*Client]bool),
+		broadcast:    make(chan models.WSMessage, 256),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		probes:       make(chan struct{}),
+		pingInterval: pingPeriod,
+		pongTimeout:  pongWait,
+
+		sendBufferSize:      defaultSendBufferSize,
+		maxConsecutiveDrops: defaultMaxConsecutiveDrops,
+	}
+	if len(config) > 0 {
+		if config[0].MaxConcurrentWrites > 0 {
+			hub.writeSlots = make(chan struct{}, config[0].MaxConcurrentWrites)
+		}
+		hub.recorder = config[0].Recorder
+		hub.retained = newRetainedMessages(config[0].RetainKeys, config[0].RetainMaxMessages)
+		if config[0].PingInterval > 0 {
+			hub.pingInterval = config[0].PingInterval
+		}
+		if config[0].PongTimeout > 0 {
+			hub.pongTimeout = config[0].PongTimeout
+		}
+		if config[0].SendBufferSize > 0 {
+			hub.sendBufferSize = config[0].SendBufferSize
+		}
+		if config[0].MaxConsecutiveDrops > 0 {
+			hub.maxConsecutiveDrops = config[0].MaxConsecutiveDrops
+		}
 	}
+	return hub
 }
 
 // Run starts the WebSocket hub and handles client connections and messages
 func (h *Hub) Run() {
 	logger := utils.GetLogger()
 
+	// Clients that stop answering pings are pruned here as well as by their read deadline, so
+	// connections that never return from a read still leave the hub
+	pruneTicker := time.NewTicker(h.pingInterval)
+	defer pruneTicker.Stop()
+
 	for {
 		select {
+		case <-pruneTicker.C:
+			h.pruneDeadClients()
+
+		case <-h.probes:
+
 		case client := <-h.register:
 			// Register new client
+			h.mu.Lock()
 			h.clients[client] = true
 			logger.Info("WebSocket client connected", map[string]interface{}{
 				"client_id":     client.ID,
@@ -51,13 +175,16 @@ func (h *Hub) Run() {
 
 			select {
 			case client.send <- welcomeMsg:
+				h.replayRetained(client)
 			default:
 				close(client.send)
 				delete(h.clients, client)
 			}
+			h.mu.Unlock()
 
 		case client := <-h.unregister:
 			// Unregister client
+			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
@@ -67,9 +194,14 @@ func (h *Hub) Run() {
 					"total_clients": len(h.clients),
 				})
 			}
+			h.mu.Unlock()
 
 		case message := <-h.broadcast:
+			// Retained here rather than in BroadcastToAll so replays and broadcasts stay in order
+			h.retained.retain(message)
+
 			// Broadcast message to all clients
+			h.mu.Lock()
 			logger.Debug("Broadcasting WebSocket message", map[string]interface{}{
 				"type":       message.Type,
 				"client_id":  message.ClientID,
@@ -78,19 +210,112 @@ func (h *Hub) Run() {
 
 			// Send message to all connected clients
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					// Client's send channel is blocked, remove the client
-					close(client.send)
-					delete(h.clients, client)
-					logger.Warn("Removed unresponsive WebSocket client", map[string]interface{}{
-						"client_id": client.ID,
-					})
-				}
+				h.deliverLocked(client, message)
 			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// deliverLocked queues message for client without blocking. When the client's buffer is full the
+// message is dropped for that client, and after maxConsecutiveDrops drops in a row the client is
+// evicted so a stuck consumer doesn't keep costing every broadcast. Callers must hold mu.
+func (h *Hub) deliverLocked(client *Client, message models.WSMessage) {
+	select {
+	case client.send <- message:
+		client.consecutiveDrops = 0
+		return
+	default:
+	}
+
+	client.consecutiveDrops++
+	h.droppedMessages.Add(1)
+	if client.consecutiveDrops < h.maxConsecutiveDrops {
+		return
+	}
+
+	close(client.send)
+	delete(h.clients, client)
+	h.evictedClients.Add(1)
+	utils.GetLogger().Warn("Evicted slow WebSocket client", map[string]interface{}{
+		"client_id":     client.ID,
+		"user_id":       client.UserID,
+		"dropped":       client.consecutiveDrops,
+		"message_type":  message.Type,
+		"total_clients": len(h.clients),
+	})
+}
+
+// DroppedMessages returns how many messages were dropped for clients whose buffer was full
+func (h *Hub) DroppedMessages() int64 {
+	return h.droppedMessages.Load()
+}
+
+// EvictedClients returns how many clients were evicted for dropping too many messages in a row
+func (h *Hub) EvictedClients() int64 {
+	return h.evictedClients.Load()
+}
+
+// pruneDeadClients removes and disconnects clients that haven't sent a pong or message within
+// pongTimeout, e.g. browsers that went away without closing their connection
+func (h *Hub) pruneDeadClients() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		if client.IsAlive() {
+			continue
 		}
+
+		delete(h.clients, client)
+		close(client.send)
+		if client.conn != nil {
+			client.conn.Close()
+		}
+		h.prunedClients.Add(1)
+
+		utils.GetLogger().Warn("Removed WebSocket client that stopped responding to pings", map[string]interface{}{
+			"client_id":     client.ID,
+			"user_id":       client.UserID,
+			"last_seen":     client.lastSeen(),
+			"total_clients": len(h.clients),
+		})
+	}
+}
+
+// PrunedClients returns how many clients have been removed for not answering pings
+func (h *Hub) PrunedClients() int64 {
+	return h.prunedClients.Load()
+}
+
+// keepalive returns the ping interval and pong timeout, or the defaults for clients without a hub
+func (h *Hub) keepalive() (time.Duration, time.Duration) {
+	if h == nil {
+		return pingPeriod, pongWait
 	}
+	return h.pingInterval, h.pongTimeout
+}
+
+// replayRetained sends a newly registered client the retained messages, so it starts from the
+// current state instead of waiting for the next broadcast. Replay stops if the client's buffer fills.
+func (h *Hub) replayRetained(client *Client) {
+	messages := h.retained.snapshot()
+	for i, message := range messages {
+		select {
+		case client.send <- message:
+		default:
+			utils.GetLogger().Warn("Stopped replaying retained messages to a full WebSocket client", map[string]interface{}{
+				"client_id": client.ID,
+				"skipped":   len(messages) - i,
+			})
+			return
+		}
+	}
+}
+
+// RetainedMessages returns how many messages are retained for replay to new clients
+func (h *Hub) RetainedMessages() int {
+	return h.retained.len()
 }
 
 // BroadcastToAll sends a message to all connected clients
@@ -102,6 +327,11 @@ func (h *Hub) BroadcastToAll(msgType string, data interface{}) {
 		ClientID:  "server",
 	}
 
+	// Record before sending so a message dropped on a full channel is still kept
+	if h.recorder != nil {
+		h.recorder.RecordBroadcast(message)
+	}
+
 	select {
 	case h.broadcast <- message:
 	default:
@@ -118,32 +348,102 @@ func (h *Hub) BroadcastToClient(clientID string, msgType string, data interface{
 		ClientID:  clientID,
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	// Find the specific client and send the message
 	for client := range h.clients {
 		if client.ID == clientID {
-			select {
-			case client.send <- message:
-				return
-			default:
-				// Client's send channel is blocked, remove the client
-				close(client.send)
-				delete(h.clients, client)
-				utils.GetLogger().Warn("Removed unresponsive WebSocket client during targeted broadcast", map[string]interface{}{
-					"client_id": clientID,
-				})
-			}
+			h.deliverLocked(client, message)
 			return
 		}
 	}
 }
 
+// BroadcastToUser sends a message to every client connected as userID
+func (h *Hub) BroadcastToUser(userID string, msgType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		if client.UserID != userID {
+			continue
+		}
+
+		message := models.WSMessage{
+			Type:      msgType,
+			Data:      data,
+			Timestamp: time.Now(),
+			ClientID:  client.ID,
+		}
+		h.deliverLocked(client, message)
+	}
+}
+
+// acquireWrite waits for a free write slot and returns the func that releases it. Slots cover
+// encoding a message, not the network write, so a slow client can't hold one while it blocks.
+// When the pool is saturated, encodes queue here instead of all running at once.
+func (h *Hub) acquireWrite() func() {
+	if h == nil {
+		return func() {}
+	}
+
+	h.writesTotal.Add(1)
+	if h.writeSlots != nil {
+		select {
+		case h.writeSlots <- struct{}{}:
+		default:
+			h.writesQueued.Add(1)
+			h.writesWaiting.Add(1)
+			h.writeSlots <- struct{}{}
+			h.writesWaiting.Add(-1)
+		}
+	}
+
+	active := h.writesActive.Add(1)
+	for {
+		peak := h.writesPeak.Load()
+		if active <= peak || h.writesPeak.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+
+	return func() {
+		h.writesActive.Add(-1)
+		if h.writeSlots != nil {
+			<-h.writeSlots
+		}
+	}
+}
+
+// WriteStats returns a snapshot of the hub's write pool usage
+func (h *Hub) WriteStats() WriteStats {
+	stats := WriteStats{
+		MaxConcurrent: cap(h.writeSlots),
+		InFlight:      h.writesActive.Load(),
+		Waiting:       h.writesWaiting.Load(),
+		PeakInFlight:  h.writesPeak.Load(),
+		TotalWrites:   h.writesTotal.Load(),
+		QueuedWrites:  h.writesQueued.Load(),
+	}
+	if stats.MaxConcurrent > 0 {
+		stats.Saturation = float64(stats.InFlight) / float64(stats.MaxConcurrent) * 100
+	}
+	return stats
+}
+
 // GetConnectedClients returns the number of connected clients
 func (h *Hub) GetConnectedClients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	return len(h.clients)
 }
 
 // GetClientIDs returns a list of all connected client IDs
 func (h *Hub) GetClientIDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
 	clientIDs := make([]string, 0, len(h.clients))
 	for client := range h.clients {
 		clientIDs = append(clientIDs, client.ID)
@@ -161,8 +461,65 @@ func (h *Hub) UnregisterClient(client *Client) {
 	h.unregister <- client
 }
 
+// flushPollInterval is how often Flush checks whether the hub's buffers have drained
+const flushPollInterval = 10 * time.Millisecond
+
+// Flush waits until every broadcast has been handed to the clients and every live client has
+// written its buffered messages, so a Shutdown that follows doesn't drop them. It returns an
+// error with the messages still pending once ctx is done.
+func (h *Hub) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := h.pendingMessages()
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d WebSocket messages not flushed: %w", pending, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingMessages counts broadcasts not yet handed to clients plus the messages buffered for
+// live clients. Clients whose writes have failed never drain, so they aren't counted.
+func (h *Hub) pendingMessages() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	pending := len(h.broadcast)
+	for client := range h.clients {
+		if !client.dead.Load() {
+			pending += len(client.send)
+		}
+	}
+	return pending
+}
+
+// HealthCheck fails when the Run loop doesn't take a probe before ctx is done, since a hub that isn't
+// running silently drops every broadcast, or when the broadcast queue is full
+func (h *Hub) HealthCheck(ctx context.Context) error {
+	select {
+	case h.probes <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("WebSocket hub is not responding: %w", ctx.Err())
+	}
+
+	if queued := len(h.broadcast); queued == cap(h.broadcast) {
+		return fmt.Errorf("WebSocket broadcast queue is full (%d messages)", queued)
+	}
+	return nil
+}
+
 // Shutdown gracefully shuts down the WebSocket hub
 func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	logger := utils.GetLogger()
 	logger.Info("Shutting down WebSocket hub", map[string]interface{}{
 		"connected_clients": len(h.clients),
@@ -1,11 +1,17 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/gofiber/websocket/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewHub(t *testing.T) {
@@ -259,7 +265,7 @@ func TestHub_GetClientIDs(t *testing.T) {
 }
 
 func TestHub_HandleUnresponsiveClient(t *testing.T) {
-	hub := NewHub()
+	hub := NewHub(HubConfig{MaxConsecutiveDrops: 1})
 	go hub.Run()
 
 	// Create a client with a small send buffer to simulate blocking
@@ -290,3 +296,561 @@ func TestHub_HandleUnresponsiveClient(t *testing.T) {
 	// The unresponsive client should have been removed
 	assert.Equal(t, 0, hub.GetConnectedClients())
 }
+
+func TestHub_DropsMessagesForSlowClients(t *testing.T) {
+	hub := NewHub(HubConfig{MaxConsecutiveDrops: 3})
+	slow := &Client{ID: "slow-client", send: make(chan models.WSMessage, 1), hub: hub, LastSeen: time.Now()}
+	fast := &Client{ID: "fast-client", send: make(chan models.WSMessage, 10), hub: hub, LastSeen: time.Now()}
+	hub.clients[slow] = true
+	hub.clients[fast] = true
+
+	broadcast := func(n int) {
+		for i := 0; i < n; i++ {
+			hub.mu.Lock()
+			for client := range hub.clients {
+				hub.deliverLocked(client, models.WSMessage{Type: "test_progress", Data: i})
+			}
+			hub.mu.Unlock()
+		}
+	}
+
+	// The first message fills the slow client's buffer; the next two are dropped for it only
+	broadcast(3)
+	assert.Equal(t, 2, hub.GetConnectedClients())
+	assert.Equal(t, int64(2), hub.DroppedMessages())
+	assert.Len(t, fast.send, 3)
+
+	// Reading from the buffer resets the count of drops in a row
+	<-slow.send
+	broadcast(1)
+	assert.Zero(t, slow.consecutiveDrops)
+
+	broadcast(3)
+	assert.Equal(t, []string{"fast-client"}, hub.GetClientIDs())
+	assert.Equal(t, int64(5), hub.DroppedMessages())
+	assert.Equal(t, int64(1), hub.EvictedClients())
+
+	_, open := <-slow.send
+	assert.True(t, open, "buffered message is still readable")
+	_, open = <-slow.send
+	assert.False(t, open, "the evicted client's send channel is closed")
+}
+
+func TestNewClient_UsesHubBufferSize(t *testing.T) {
+	assert.Equal(t, 8, cap(NewClient(nil, NewHub(HubConfig{SendBufferSize: 8}), "user").send))
+	assert.Equal(t, defaultSendBufferSize, cap(NewClient(nil, NewHub(), "user").send))
+}
+
+// failingConn is a connection whose writes always fail, simulating a peer that went away
+type failingConn struct {
+	mu     sync.Mutex
+	writes int
+	closed bool
+}
+
+func (f *failingConn) ReadMessage() (int, []byte, error) {
+	return 0, nil, errors.New("read on failing conn")
+}
+
+func (f *failingConn) SetReadLimit(limit int64) {}
+
+func (f *failingConn) SetReadDeadline(t time.Time) error { return nil }
+
+func (f *failingConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (f *failingConn) SetPongHandler(h func(appData string) error) {}
+
+func (f *failingConn) WriteMessage(messageType int, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes++
+	return errors.New("broken pipe")
+}
+
+func (f *failingConn) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func TestHub_RemovesClientAfterWriteFailure(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	conn := &failingConn{}
+	failing := &Client{
+		ID:       "failing-client",
+		conn:     conn,
+		send:     make(chan models.WSMessage, 256),
+		hub:      hub,
+		UserID:   "test-user",
+		LastSeen: time.Now(),
+	}
+	healthy := &Client{
+		ID:       "healthy-client",
+		send:     make(chan models.WSMessage, 256),
+		hub:      hub,
+		UserID:   "test-user",
+		LastSeen: time.Now(),
+	}
+
+	hub.RegisterClient(failing)
+	hub.RegisterClient(healthy)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 2, hub.GetConnectedClients())
+
+	// Writing the welcome message fails, which should drop the client from the hub
+	done := make(chan struct{})
+	go func() {
+		failing.WritePump()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WritePump did not return after a write failure")
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 1, hub.GetConnectedClients())
+	assert.Equal(t, []string{"healthy-client"}, hub.GetClientIDs())
+	assert.False(t, failing.IsAlive())
+	assert.True(t, healthy.IsAlive())
+
+	conn.mu.Lock()
+	assert.Equal(t, 1, conn.writes)
+	assert.True(t, conn.closed)
+	conn.mu.Unlock()
+
+	// The hub closed the dead client's send channel
+	_, open := <-failing.send
+	assert.False(t, open)
+
+	// Later broadcasts still reach the remaining client
+	<-healthy.send
+	hub.BroadcastToAll("test_message", map[string]interface{}{"test": "data"})
+
+	select {
+	case msg := <-healthy.send:
+		assert.Equal(t, "test_message", msg.Type)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Healthy client did not receive broadcast")
+	}
+	assert.Equal(t, 1, hub.GetConnectedClients())
+}
+
+// slowConn accepts writes after a short delay and tracks how many overlap across connections
+type slowConn struct {
+	failingConn
+	inFlight *writeCounter
+	messages int
+}
+
+// writeCounter tracks concurrent writes and the highest concurrency seen
+type writeCounter struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (s *slowConn) WriteMessage(messageType int, data []byte) error {
+	s.inFlight.mu.Lock()
+	s.inFlight.current++
+	if s.inFlight.current > s.inFlight.peak {
+		s.inFlight.peak = s.inFlight.current
+	}
+	s.inFlight.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.inFlight.mu.Lock()
+	s.inFlight.current--
+	s.inFlight.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if messageType == 1 { // websocket.TextMessage
+		s.messages++
+	}
+	return nil
+}
+
+// countedPayload tracks how many messages are being encoded at once across clients
+type countedPayload struct {
+	encoding *writeCounter
+	Sequence int `json:"sequence"`
+}
+
+func (p countedPayload) MarshalJSON() ([]byte, error) {
+	p.encoding.mu.Lock()
+	p.encoding.current++
+	if p.encoding.current > p.encoding.peak {
+		p.encoding.peak = p.encoding.current
+	}
+	p.encoding.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.encoding.mu.Lock()
+	p.encoding.current--
+	p.encoding.mu.Unlock()
+	return json.Marshal(map[string]int{"sequence": p.Sequence})
+}
+
+func TestHub_CapsConcurrentWrites(t *testing.T) {
+	hub := NewHub(HubConfig{MaxConcurrentWrites: 2})
+	go hub.Run()
+
+	encoding := &writeCounter{}
+	conns := make([]*slowConn, 6)
+	done := make(chan struct{}, len(conns))
+	for i := range conns {
+		conns[i] = &slowConn{inFlight: &writeCounter{}}
+		client := &Client{
+			ID:       "client-" + string(rune('a'+i)),
+			conn:     conns[i],
+			send:     make(chan models.WSMessage, 256),
+			hub:      hub,
+			UserID:   "test-user",
+			LastSeen: time.Now(),
+		}
+		hub.RegisterClient(client)
+		go func() {
+			client.WritePump()
+			done <- struct{}{}
+		}()
+	}
+
+	for i := 0; i < 3; i++ {
+		hub.BroadcastToAll("test_progress", countedPayload{encoding: encoding, Sequence: i})
+	}
+
+	// Each client gets the welcome message plus three broadcasts
+	assert.Eventually(t, func() bool {
+		for _, conn := range conns {
+			conn.mu.Lock()
+			messages := conn.messages
+			conn.mu.Unlock()
+			if messages < 4 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	encoding.mu.Lock()
+	assert.Equal(t, 2, encoding.peak)
+	encoding.mu.Unlock()
+
+	stats := hub.WriteStats()
+	assert.Equal(t, 2, stats.MaxConcurrent)
+	assert.Equal(t, int64(2), stats.PeakInFlight)
+	assert.Equal(t, int64(24), stats.TotalWrites)
+	assert.Greater(t, stats.QueuedWrites, int64(0))
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Equal(t, int64(0), stats.Waiting)
+	assert.Equal(t, float64(0), stats.Saturation)
+
+	hub.Shutdown()
+	for range conns {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("WritePump did not return after shutdown")
+		}
+	}
+}
+
+// blockedConn blocks every write until unblocked is closed
+type blockedConn struct {
+	failingConn
+	unblocked chan struct{}
+}
+
+func (b *blockedConn) WriteMessage(messageType int, data []byte) error {
+	<-b.unblocked
+	return nil
+}
+
+func TestHub_SlowClientDoesNotHoldWriteSlot(t *testing.T) {
+	hub := NewHub(HubConfig{MaxConcurrentWrites: 1})
+	go hub.Run()
+
+	stuck := &blockedConn{unblocked: make(chan struct{})}
+	healthy := &slowConn{inFlight: &writeCounter{}}
+	done := make(chan struct{}, 2)
+	for i, conn := range []connection{stuck, healthy} {
+		client := &Client{
+			ID:       "client-" + string(rune('a'+i)),
+			conn:     conn,
+			send:     make(chan models.WSMessage, 256),
+			hub:      hub,
+			UserID:   "test-user",
+			LastSeen: time.Now(),
+		}
+		hub.RegisterClient(client)
+		go func() {
+			client.WritePump()
+			done <- struct{}{}
+		}()
+	}
+
+	hub.BroadcastToAll("test_progress", map[string]interface{}{"sequence": 1})
+
+	// The healthy client gets the welcome message and broadcast while the other write blocks
+	assert.Eventually(t, func() bool {
+		healthy.mu.Lock()
+		defer healthy.mu.Unlock()
+		return healthy.messages == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(0), hub.WriteStats().InFlight)
+
+	close(stuck.unblocked)
+	hub.Shutdown()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("WritePump did not return after shutdown")
+		}
+	}
+}
+
+func TestHub_WriteStats_Unlimited(t *testing.T) {
+	hub := NewHub()
+
+	release := hub.acquireWrite()
+	stats := hub.WriteStats()
+	assert.Equal(t, 0, stats.MaxConcurrent)
+	assert.Equal(t, int64(1), stats.InFlight)
+	assert.Equal(t, float64(0), stats.Saturation)
+
+	release()
+	assert.Equal(t, int64(0), hub.WriteStats().InFlight)
+}
+
+// recordingBroadcaster keeps every message handed to RecordBroadcast
+type recordingBroadcaster struct {
+	mu       sync.Mutex
+	messages []models.WSMessage
+}
+
+func (r *recordingBroadcaster) RecordBroadcast(message models.WSMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+func TestHub_BroadcastRecorder(t *testing.T) {
+	recorder := &recordingBroadcaster{}
+	hub := NewHub(HubConfig{Recorder: recorder})
+
+	hub.BroadcastToAll("log_alert", map[string]interface{}{"level": "critical"})
+	hub.BroadcastToClient("client-1", "test_progress", map[string]interface{}{})
+
+	// Only messages for all clients are recorded, even with nobody connected to receive them
+	assert.Len(t, recorder.messages, 1)
+	assert.Equal(t, "log_alert", recorder.messages[0].Type)
+	assert.Equal(t, "server", recorder.messages[0].ClientID)
+	assert.False(t, recorder.messages[0].Timestamp.IsZero())
+
+	t.Run("messages dropped on a full channel are still recorded", func(t *testing.T) {
+		for i := 0; i < cap(hub.broadcast)+1; i++ {
+			hub.BroadcastToAll("test_progress", i)
+		}
+		assert.Len(t, recorder.messages, cap(hub.broadcast)+2)
+	})
+}
+
+func TestHub_BroadcastToUser(t *testing.T) {
+	hub := NewHub(HubConfig{MaxConsecutiveDrops: 1})
+	alice1 := &Client{ID: "alice-1", UserID: "alice", send: make(chan models.WSMessage, 1), hub: hub}
+	alice2 := &Client{ID: "alice-2", UserID: "alice", send: make(chan models.WSMessage, 1), hub: hub}
+	bob := &Client{ID: "bob-1", UserID: "bob", send: make(chan models.WSMessage, 1), hub: hub}
+	for _, client := range []*Client{alice1, alice2, bob} {
+		hub.clients[client] = true
+	}
+
+	hub.BroadcastToUser("alice", "test_progress", map[string]interface{}{"status": "running"})
+
+	for _, client := range []*Client{alice1, alice2} {
+		require.Len(t, client.send, 1)
+		message := <-client.send
+		assert.Equal(t, "test_progress", message.Type)
+		assert.Equal(t, client.ID, message.ClientID)
+	}
+	assert.Empty(t, bob.send)
+
+	t.Run("blocked clients are removed", func(t *testing.T) {
+		hub.BroadcastToUser("bob", "test_progress", 1)
+		hub.BroadcastToUser("bob", "test_progress", 2)
+		assert.NotContains(t, hub.clients, bob)
+		assert.Equal(t, 2, hub.GetConnectedClients())
+	})
+}
+
+// keepaliveConn is a connection whose reads block until it is closed. When answering, each
+// ping is followed by a pong, like a browser; otherwise pings go unanswered, like a peer that
+// went away without closing the connection.
+type keepaliveConn struct {
+	mu          sync.Mutex
+	answering   bool
+	pongHandler func(appData string) error
+	pings       int
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+func newKeepaliveConn(answering bool) *keepaliveConn {
+	return &keepaliveConn{answering: answering, closed: make(chan struct{})}
+}
+
+func (k *keepaliveConn) ReadMessage() (int, []byte, error) {
+	<-k.closed
+	return 0, nil, errors.New("read on closed conn")
+}
+
+func (k *keepaliveConn) SetReadLimit(limit int64) {}
+
+func (k *keepaliveConn) SetReadDeadline(t time.Time) error { return nil }
+
+func (k *keepaliveConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func (k *keepaliveConn) SetPongHandler(h func(appData string) error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.pongHandler = h
+}
+
+func (k *keepaliveConn) WriteMessage(messageType int, data []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if messageType != websocket.PingMessage {
+		return nil
+	}
+	k.pings++
+	if k.answering && k.pongHandler != nil {
+		return k.pongHandler("")
+	}
+	return nil
+}
+
+func (k *keepaliveConn) Close() error {
+	k.closeOnce.Do(func() { close(k.closed) })
+	return nil
+}
+
+func (k *keepaliveConn) isClosed() bool {
+	select {
+	case <-k.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_PrunesClientsThatStopAnsweringPings(t *testing.T) {
+	hub := NewHub(HubConfig{PingInterval: 20 * time.Millisecond, PongTimeout: 100 * time.Millisecond})
+	go hub.Run()
+
+	silentConn := newKeepaliveConn(false)
+	answeringConn := newKeepaliveConn(true)
+	silent := &Client{ID: "silent-client", conn: silentConn, send: make(chan models.WSMessage, 256), hub: hub, LastSeen: time.Now()}
+	answering := &Client{ID: "answering-client", conn: answeringConn, send: make(chan models.WSMessage, 256), hub: hub, LastSeen: time.Now()}
+
+	for _, client := range []*Client{silent, answering} {
+		hub.RegisterClient(client)
+		go client.WritePump()
+		go client.ReadPump()
+	}
+	require.Eventually(t, func() bool {
+		return hub.GetConnectedClients() == 2
+	}, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return hub.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond, "the silent client should be pruned after the pong timeout")
+
+	assert.Equal(t, []string{"answering-client"}, hub.GetClientIDs())
+	assert.Equal(t, int64(1), hub.PrunedClients())
+	assert.True(t, silentConn.isClosed())
+	assert.False(t, answeringConn.isClosed())
+	assert.True(t, answering.IsAlive())
+
+	silentConn.mu.Lock()
+	assert.Positive(t, silentConn.pings, "the silent client was pinged before being pruned")
+	silentConn.mu.Unlock()
+
+	// The answering client stays connected well past the timeout
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, 1, hub.GetConnectedClients())
+	answeringConn.Close()
+}
+
+func TestHub_KeepaliveDefaults(t *testing.T) {
+	pingInterval, pongTimeout := NewHub().keepalive()
+	assert.Equal(t, pingPeriod, pingInterval)
+	assert.Equal(t, pongWait, pongTimeout)
+
+	pingInterval, pongTimeout = NewHub(HubConfig{PingInterval: time.Second, PongTimeout: 3 * time.Second}).keepalive()
+	assert.Equal(t, time.Second, pingInterval)
+	assert.Equal(t, 3*time.Second, pongTimeout)
+}
+
+func TestHub_Flush(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	client := &Client{
+		ID:       "flush-client",
+		send:     make(chan models.WSMessage, 8),
+		hub:      hub,
+		LastSeen: time.Now(),
+	}
+	hub.RegisterClient(client)
+	<-client.send // Welcome message
+
+	assert.NoError(t, hub.Flush(context.Background()), "nothing buffered")
+
+	hub.BroadcastToAll("test_progress", map[string]interface{}{"run_id": "1"})
+	hub.BroadcastToAll("test_progress", map[string]interface{}{"run_id": "2"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := hub.Flush(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "2 WebSocket messages not flushed")
+
+	// A writer draining the buffer lets Flush return
+	go func() {
+		for range client.send {
+		}
+	}()
+	assert.NoError(t, hub.Flush(context.Background()))
+
+	// Clients whose writes failed are skipped
+	dead := &Client{ID: "dead-client", send: make(chan models.WSMessage, 8), hub: hub, LastSeen: time.Now()}
+	dead.dead.Store(true)
+	hub.mu.Lock()
+	hub.clients[dead] = true
+	hub.mu.Unlock()
+	dead.send <- models.WSMessage{Type: "test_progress"}
+	assert.NoError(t, hub.Flush(context.Background()))
+
+	hub.Shutdown()
+}
+
+func TestHub_HealthCheck(t *testing.T) {
+	hub := NewHub()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := hub.HealthCheck(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Run hasn't been started")
+	assert.Contains(t, err.Error(), "not responding")
+
+	go hub.Run()
+	assert.NoError(t, hub.HealthCheck(context.Background()))
+}
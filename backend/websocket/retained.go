@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// retainedKey identifies a retained message: its type and the value of its key field
+type retainedKey struct {
+	msgType string
+	key     string
+}
+
+// retainedMessages keeps the latest broadcast per (type, key), e.g. the latest test_progress
+// per run ID, so clients that connect later can be sent the current state. It is bounded by
+// maxEntries, evicting the message that was updated longest ago.
+type retainedMessages struct {
+	mu         sync.Mutex
+	keyFields  map[string]string // Message type -> data field keying it; "" keeps one message per type
+	maxEntries int
+	entries    map[retainedKey]*list.Element
+	order      *list.List // Front is the most recently updated
+}
+
+// retainedEntry is one retained message; the element value of retainedMessages.order
+type retainedEntry struct {
+	key     retainedKey
+	message models.WSMessage
+}
+
+// newRetainedMessages creates a cache, or returns nil when no types are retained or maxEntries is 0
+func newRetainedMessages(keyFields map[string]string, maxEntries int) *retainedMessages {
+	if len(keyFields) == 0 || maxEntries <= 0 {
+		return nil
+	}
+	return &retainedMessages{
+		keyFields:  keyFields,
+		maxEntries: maxEntries,
+		entries:    make(map[retainedKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// retain keeps message if its type is retained, replacing the previous message with the same key.
+// Messages without a value for their type's key field are not retained.
+func (r *retainedMessages) retain(message models.WSMessage) {
+	if r == nil {
+		return
+	}
+	field, retained := r.keyFields[message.Type]
+	if !retained {
+		return
+	}
+	value, ok := retainedKeyValue(message.Data, field)
+	if !ok {
+		return
+	}
+	key := retainedKey{msgType: message.Type, key: value}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, exists := r.entries[key]; exists {
+		element.Value.(*retainedEntry).message = message
+		r.order.MoveToFront(element)
+		return
+	}
+
+	r.entries[key] = r.order.PushFront(&retainedEntry{key: key, message: message})
+
+	for r.order.Len() > r.maxEntries {
+		oldest := r.order.Back()
+		delete(r.entries, oldest.Value.(*retainedEntry).key)
+		r.order.Remove(oldest)
+	}
+}
+
+// snapshot returns the retained messages, least recently updated first so they replay in order
+func (r *retainedMessages) snapshot() []models.WSMessage {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]models.WSMessage, 0, r.order.Len())
+	for element := r.order.Back(); element != nil; element = element.Prev() {
+		messages = append(messages, element.Value.(*retainedEntry).message)
+	}
+	return messages
+}
+
+// len returns the number of retained messages
+func (r *retainedMessages) len() int {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
+
+// retainedKeyValue reads field from a broadcast payload. Maps are read directly; other payloads
+// are read through their JSON encoding.
+func retainedKeyValue(data interface{}, field string) (string, bool) {
+	if field == "" {
+		return "", true
+	}
+
+	var fields map[string]interface{}
+	switch payload := data.(type) {
+	case map[string]interface{}:
+		fields = payload
+	case map[string]string:
+		value, ok := payload[field]
+		return value, ok && value != ""
+	default:
+		encoded, err := json.Marshal(data)
+		if err != nil || json.Unmarshal(encoded, &fields) != nil {
+			return "", false
+		}
+	}
+
+	value, ok := fields[field]
+	if !ok || value == nil {
+		return "", false
+	}
+	formatted := fmt.Sprint(value)
+	return formatted, formatted != ""
+}
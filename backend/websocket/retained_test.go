@@ -0,0 +1,128 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetainedMessages(t *testing.T) {
+	retained := newRetainedMessages(map[string]string{"test_progress": "run_id", "sync_status_update": ""}, 3)
+	progress := func(runID, status string) models.WSMessage {
+		return models.WSMessage{Type: "test_progress", Data: map[string]interface{}{"run_id": runID, "status": status}}
+	}
+	statuses := func() []string {
+		var result []string
+		for _, message := range retained.snapshot() {
+			if data, ok := message.Data.(map[string]interface{}); ok {
+				result = append(result, data["run_id"].(string)+":"+data["status"].(string))
+			} else {
+				result = append(result, message.Type)
+			}
+		}
+		return result
+	}
+
+	retained.retain(progress("run-1", "running"))
+	retained.retain(progress("run-2", "running"))
+	retained.retain(progress("run-1", "completed"))
+	assert.Equal(t, []string{"run-2:running", "run-1:completed"}, statuses(), "latest per run, oldest update first")
+
+	t.Run("unretained types and messages without a key are ignored", func(t *testing.T) {
+		retained.retain(models.WSMessage{Type: "log_alert", Data: map[string]interface{}{"run_id": "run-3"}})
+		retained.retain(models.WSMessage{Type: "test_progress", Data: map[string]interface{}{"status": "running"}})
+		assert.Equal(t, 2, retained.len())
+	})
+
+	t.Run("types without a key field keep their latest message", func(t *testing.T) {
+		retained.retain(models.WSMessage{Type: "sync_status_update", Data: "first"})
+		retained.retain(models.WSMessage{Type: "sync_status_update", Data: "second"})
+		require.Equal(t, 3, retained.len())
+		assert.Equal(t, "second", retained.snapshot()[2].Data)
+	})
+
+	t.Run("least recently updated is evicted", func(t *testing.T) {
+		retained.retain(progress("run-3", "running"))
+		assert.Equal(t, []string{"run-1:completed", "sync_status_update", "run-3:running"}, statuses())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Nil(t, newRetainedMessages(nil, 10))
+		assert.Nil(t, newRetainedMessages(map[string]string{"test_progress": "run_id"}, 0))
+
+		var disabled *retainedMessages
+		disabled.retain(progress("run-1", "running"))
+		assert.Empty(t, disabled.snapshot())
+		assert.Zero(t, disabled.len())
+	})
+}
+
+func TestRetainedKeyValue(t *testing.T) {
+	type progress struct {
+		RunID  string `json:"run_id"`
+		Status string `json:"status"`
+	}
+
+	tests := []struct {
+		name  string
+		data  interface{}
+		want  string
+		found bool
+	}{
+		{name: "map", data: map[string]interface{}{"run_id": "run-1"}, want: "run-1", found: true},
+		{name: "string map", data: map[string]string{"run_id": "run-1"}, want: "run-1", found: true},
+		{name: "struct", data: progress{RunID: "run-1"}, want: "run-1", found: true},
+		{name: "number", data: map[string]interface{}{"run_id": 42}, want: "42", found: true},
+		{name: "missing", data: map[string]interface{}{"status": "running"}},
+		{name: "empty", data: progress{}},
+		{name: "not an object", data: "running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := retainedKeyValue(tt.data, "run_id")
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestHub_ReplaysRetainedMessages(t *testing.T) {
+	hub := NewHub(HubConfig{RetainKeys: map[string]string{"test_progress": "run_id"}, RetainMaxMessages: 10})
+	go hub.Run()
+
+	hub.BroadcastToAll("test_progress", map[string]interface{}{"run_id": "run-1", "status": "running"})
+	hub.BroadcastToAll("log_alert", map[string]interface{}{"level": "critical"})
+	assert.Eventually(t, func() bool { return hub.RetainedMessages() == 1 }, time.Second, 5*time.Millisecond)
+
+	client := &Client{ID: "late-client", send: make(chan models.WSMessage, 256), hub: hub, LastSeen: time.Now()}
+	hub.RegisterClient(client)
+
+	welcome := <-client.send
+	assert.Equal(t, "connect", welcome.Type)
+
+	select {
+	case replayed := <-client.send:
+		assert.Equal(t, "test_progress", replayed.Type)
+		assert.Equal(t, "running", replayed.Data.(map[string]interface{})["status"])
+		assert.Equal(t, "server", replayed.ClientID)
+	case <-time.After(time.Second):
+		t.Fatal("retained message was not replayed")
+	}
+
+	select {
+	case extra := <-client.send:
+		t.Fatalf("unexpected message %s", extra.Type)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	t.Run("replay stops when the client buffer is full", func(t *testing.T) {
+		small := &Client{ID: "small-client", send: make(chan models.WSMessage, 1), hub: hub, LastSeen: time.Now()}
+		hub.RegisterClient(small)
+		assert.Eventually(t, func() bool { return hub.GetConnectedClients() == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, "connect", (<-small.send).Type)
+	})
+}
@@ -0,0 +1,118 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+)
+
+// Topic subscription limits
+const (
+	maxTopicsPerClient = 50
+	maxTopicLength     = 200
+)
+
+// ErrTooManyTopics is returned when a client subscribes to more than maxTopicsPerClient topics
+var ErrTooManyTopics = errors.New("too many topic subscriptions")
+
+// ErrInvalidTopic is returned for an empty or overlong topic
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// ErrTopicForbidden is returned when the hub's TopicAuthorizer denies a client a topic
+var ErrTopicForbidden = errors.New("topic not allowed")
+
+// TopicAuthorizer reports whether the connections of the user userID may subscribe to topic.
+// It is called without the hub's lock held.
+type TopicAuthorizer func(userID, topic string) bool
+
+// SetTopicAuthorizer makes Subscribe check every topic with authorize; nil allows any topic.
+// Existing subscriptions are kept.
+func (h *Hub) SetTopicAuthorizer(authorize TopicAuthorizer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topicAuthorizer = authorize
+}
+
+// Subscribe adds topic to the client's subscriptions, so it receives BroadcastToTopic messages
+// for it. Subscribing twice is a no-op.
+func (h *Hub) Subscribe(client *Client, topic string) error {
+	topic = strings.TrimSpace(topic)
+	if topic == "" || len(topic) > maxTopicLength {
+		return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+	}
+
+	h.mu.RLock()
+	authorize := h.topicAuthorizer
+	h.mu.RUnlock()
+	if authorize != nil && !authorize(client.UserID, topic) {
+		return fmt.Errorf("%w: %q", ErrTopicForbidden, topic)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if client.topics[topic] {
+		return nil
+	}
+	if len(client.topics) >= maxTopicsPerClient {
+		return fmt.Errorf("%w: at most %d", ErrTooManyTopics, maxTopicsPerClient)
+	}
+	if client.topics == nil {
+		client.topics = make(map[string]bool)
+	}
+	client.topics[topic] = true
+	return nil
+}
+
+// Unsubscribe removes topic from the client's subscriptions
+func (h *Hub) Unsubscribe(client *Client, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(client.topics, strings.TrimSpace(topic))
+}
+
+// BroadcastToTopic sends a message to every client subscribed to topic. Unlike BroadcastToAll it
+// isn't recorded or retained, since it is meant for high-volume streams such as test output.
+func (h *Hub) BroadcastToTopic(topic, msgType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		if !client.topics[topic] {
+			continue
+		}
+
+		message := models.WSMessage{
+			Type:      msgType,
+			Data:      data,
+			Timestamp: time.Now(),
+			ClientID:  client.ID,
+		}
+		h.deliverLocked(client, message)
+	}
+}
+
+// handleSubscription subscribes or unsubscribes the client from the topic in a subscribe or
+// unsubscribe message, answering with the outcome as a message of the same type
+func (c *Client) handleSubscription(message models.WSMessage) {
+	data, _ := message.Data.(map[string]interface{})
+	topic, _ := data["topic"].(string)
+
+	response := map[string]interface{}{"topic": topic}
+	if message.Type == "subscribe" {
+		if err := c.hub.Subscribe(c, topic); err != nil {
+			response["status"] = "error"
+			response["error"] = err.Error()
+		} else {
+			response["status"] = "subscribed"
+		}
+	} else {
+		c.hub.Unsubscribe(c, topic)
+		response["status"] = "unsubscribed"
+	}
+
+	c.SendMessage(message.Type, response)
+}
@@ -0,0 +1,107 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KBesada24/Full-Stack-Master-Sync.git/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTopicTestClient(hub *Hub, id string) *Client {
+	client := &Client{
+		ID:       id,
+		send:     make(chan models.WSMessage, 16),
+		hub:      hub,
+		LastSeen: time.Now(),
+	}
+	hub.mu.Lock()
+	hub.clients[client] = true
+	hub.mu.Unlock()
+	return client
+}
+
+func TestHub_BroadcastToTopic(t *testing.T) {
+	hub := NewHub()
+	subscriber := newTopicTestClient(hub, "subscriber")
+	other := newTopicTestClient(hub, "other")
+
+	require.NoError(t, hub.Subscribe(subscriber, "test_logs:run-1"))
+	require.NoError(t, hub.Subscribe(subscriber, "test_logs:run-1"), "subscribing twice is a no-op")
+	require.NoError(t, hub.Subscribe(other, "test_logs:run-2"))
+
+	hub.BroadcastToTopic("test_logs:run-1", "test_log_line", map[string]interface{}{"line": "PASS"})
+
+	require.Len(t, subscriber.send, 1)
+	msg := <-subscriber.send
+	assert.Equal(t, "test_log_line", msg.Type)
+	assert.Equal(t, "subscriber", msg.ClientID)
+	assert.Equal(t, map[string]interface{}{"line": "PASS"}, msg.Data)
+	assert.Empty(t, other.send)
+
+	hub.Unsubscribe(subscriber, "test_logs:run-1")
+	hub.BroadcastToTopic("test_logs:run-1", "test_log_line", map[string]interface{}{"line": "FAIL"})
+	assert.Empty(t, subscriber.send)
+}
+
+func TestHub_SubscribeLimits(t *testing.T) {
+	hub := NewHub()
+	client := newTopicTestClient(hub, "client")
+
+	assert.ErrorIs(t, hub.Subscribe(client, "  "), ErrInvalidTopic)
+	assert.ErrorIs(t, hub.Subscribe(client, strings.Repeat("a", maxTopicLength+1)), ErrInvalidTopic)
+
+	for i := 0; i < maxTopicsPerClient; i++ {
+		require.NoError(t, hub.Subscribe(client, fmt.Sprintf("topic-%d", i)))
+	}
+	assert.ErrorIs(t, hub.Subscribe(client, "one-too-many"), ErrTooManyTopics)
+}
+
+func TestHub_SubscribeAuthorizer(t *testing.T) {
+	hub := NewHub()
+	alice := newTopicTestClient(hub, "alice-client")
+	alice.UserID = "alice"
+	bob := newTopicTestClient(hub, "bob-client")
+	bob.UserID = "bob"
+
+	hub.SetTopicAuthorizer(func(userID, topic string) bool {
+		return topic != "test_logs:alice-run" || userID == "alice"
+	})
+
+	require.NoError(t, hub.Subscribe(alice, "test_logs:alice-run"))
+	assert.ErrorIs(t, hub.Subscribe(bob, "test_logs:alice-run"), ErrTopicForbidden)
+	require.NoError(t, hub.Subscribe(bob, "test_logs:bob-run"))
+
+	hub.BroadcastToTopic("test_logs:alice-run", "test_log_line", map[string]interface{}{"line": "PASS"})
+	assert.Len(t, alice.send, 1)
+	assert.Empty(t, bob.send)
+
+	hub.SetTopicAuthorizer(nil)
+	require.NoError(t, hub.Subscribe(bob, "test_logs:alice-run"), "nil allows any topic")
+}
+
+func TestClient_HandleMessage_Subscribe(t *testing.T) {
+	hub := NewHub()
+	client := newTopicTestClient(hub, "client")
+
+	client.handleMessage(models.WSMessage{Type: "subscribe", Data: map[string]interface{}{"topic": "test_logs:run-1"}})
+	reply := <-client.send
+	assert.Equal(t, "subscribe", reply.Type)
+	assert.Equal(t, map[string]interface{}{"topic": "test_logs:run-1", "status": "subscribed"}, reply.Data)
+
+	client.handleMessage(models.WSMessage{Type: "subscribe", Data: map[string]interface{}{}})
+	reply = <-client.send
+	data := reply.Data.(map[string]interface{})
+	assert.Equal(t, "error", data["status"])
+	assert.Contains(t, data["error"], "invalid topic")
+
+	client.handleMessage(models.WSMessage{Type: "unsubscribe", Data: map[string]interface{}{"topic": "test_logs:run-1"}})
+	reply = <-client.send
+	assert.Equal(t, map[string]interface{}{"topic": "test_logs:run-1", "status": "unsubscribed"}, reply.Data)
+
+	hub.BroadcastToTopic("test_logs:run-1", "test_log_line", map[string]interface{}{"line": "PASS"})
+	assert.Empty(t, client.send)
+}